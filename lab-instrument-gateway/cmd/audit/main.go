@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/pkg/config"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/fieldcrypt"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+func main() {
+	var (
+		action    = flag.String("action", "verify", "Audit action: verify")
+		batchSize = flag.Int("batch-size", audit.DefaultVerifyBatchSize, "Entries read per query while verifying")
+		anchor    = flag.String("anchor", "", "Sequence and hash reported by an earlier verification, as sequence:hash")
+		timeout   = flag.Duration("timeout", 10*time.Minute, "Verification timeout")
+	)
+	flag.Parse()
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	logger := logger.NewDefaultLogger()
+
+	// Read secrets from the configured backend, refusing insecure defaults
+	if _, err := cfg.ResolveSecrets(context.Background()); err != nil {
+		logger.Fatalf("Failed to resolve secrets: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatalf("Refusing to start: %v", err)
+	}
+
+	// Entries are hashed in plaintext, so encrypted fields must be readable
+	cipher, err := fieldcrypt.Load(fieldcrypt.Config{
+		KeyFile:    cfg.Security.FieldEncryptionKeyFile,
+		Fields:     fieldcrypt.ParseFields(cfg.Security.FieldEncryptionFields),
+		DataKeyTTL: cfg.Security.FieldEncryptionKeyTTL,
+	})
+	if err != nil {
+		logger.Fatalf("Invalid field encryption configuration: %v", err)
+	}
+	repository.SetFieldCipher(cipher)
+
+	// Create connection manager
+	cm, err := db.NewConnectionManager(&cfg.Database, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create connection manager: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := cm.WaitForConnection(ctx, 30*time.Second); err != nil {
+		logger.Fatalf("Database not ready: %v", err)
+	}
+
+	repo := repository.NewAuditRepository(cm, logger)
+
+	switch *action {
+	case "verify":
+		result, err := audit.Verify(ctx, repo, *batchSize)
+		var chainErr *audit.ChainError
+		if errors.As(err, &chainErr) {
+			logger.Fatalf("Audit trail verification FAILED after %d valid entries: %v", result.Entries, chainErr)
+		}
+		if err != nil {
+			logger.Fatalf("Audit trail verification could not complete: %v", err)
+		}
+
+		// Entries removed from the end of the chain leave a valid chain, so
+		// an entry reported by an earlier run must still be present
+		if *anchor != "" {
+			if err := checkAnchor(ctx, repo, *anchor); err != nil {
+				logger.Fatalf("Audit trail verification FAILED: %v", err)
+			}
+		}
+
+		fmt.Printf("Audit trail verified:\n")
+		fmt.Printf("  Entries: %d\n", result.Entries)
+		fmt.Printf("  Last Sequence: %d\n", result.LastSequence)
+		fmt.Printf("  Last Hash: %s\n", result.LastHash)
+
+	default:
+		logger.Fatalf("Unknown action: %s", *action)
+	}
+}
+
+// checkAnchor checks that the entry at a sequence number has the given hash
+func checkAnchor(ctx context.Context, repo repository.AuditRepository, anchor string) error {
+	sequenceText, hash, ok := strings.Cut(anchor, ":")
+	sequence, err := strconv.ParseInt(sequenceText, 10, 64)
+	if !ok || err != nil || sequence <= 0 {
+		return fmt.Errorf("invalid anchor %q: expected sequence:hash", anchor)
+	}
+
+	entries, err := repo.ListAfter(ctx, sequence-1, 1)
+	if err != nil {
+		return fmt.Errorf("failed to read anchor entry: %w", err)
+	}
+	if len(entries) == 0 || entries[0].Sequence != sequence {
+		return fmt.Errorf("anchor entry %d is missing", sequence)
+	}
+	if entries[0].Hash != hash {
+		return fmt.Errorf("anchor entry %d has hash %s, expected %s", sequence, entries[0].Hash, hash)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/config"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/fieldcrypt"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+func main() {
+	var (
+		action    = flag.String("action", "reencrypt", "Field encryption action: generate-key, reencrypt")
+		keyID     = flag.String("key-id", time.Now().UTC().Format("2006-01-02"), "ID of the generated key")
+		batchSize = flag.Int("batch-size", repository.DefaultReencryptBatchSize, "Rows read per query while re-encrypting")
+		timeout   = flag.Duration("timeout", time.Hour, "Re-encryption timeout")
+	)
+	flag.Parse()
+
+	// Initialize logger
+	logger := logger.NewDefaultLogger()
+
+	switch *action {
+	case "generate-key":
+		key, err := fieldcrypt.GenerateKey()
+		if err != nil {
+			logger.Fatalf("Failed to generate key: %v", err)
+		}
+
+		entry, err := json.Marshal(map[string]string{
+			"id":  *keyID,
+			"key": base64.StdEncoding.EncodeToString(key),
+		})
+		if err != nil {
+			logger.Fatalf("Failed to encode key: %v", err)
+		}
+
+		// Printed for adding to the keyfile; make it primary to start using it
+		fmt.Println(string(entry))
+
+	case "reencrypt":
+		// Load configuration
+		cfg := config.Load()
+
+		// Read secrets from the configured backend, refusing insecure defaults
+		if _, err := cfg.ResolveSecrets(context.Background()); err != nil {
+			logger.Fatalf("Failed to resolve secrets: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			logger.Fatalf("Refusing to start: %v", err)
+		}
+
+		cipher, err := fieldcrypt.Load(fieldcrypt.Config{
+			KeyFile:    cfg.Security.FieldEncryptionKeyFile,
+			Fields:     fieldcrypt.ParseFields(cfg.Security.FieldEncryptionFields),
+			DataKeyTTL: cfg.Security.FieldEncryptionKeyTTL,
+		})
+		if err != nil {
+			logger.Fatalf("Invalid field encryption configuration: %v", err)
+		}
+		if cipher == nil {
+			logger.Fatalf("FIELD_ENCRYPTION_FIELDS is empty, nothing to encrypt")
+		}
+		repository.SetFieldCipher(cipher)
+
+		// Create connection manager
+		cm, err := db.NewConnectionManager(&cfg.Database, logger)
+		if err != nil {
+			logger.Fatalf("Failed to create connection manager: %v", err)
+		}
+		defer cm.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		if err := cm.WaitForConnection(ctx, 30*time.Second); err != nil {
+			logger.Fatalf("Database not ready: %v", err)
+		}
+
+		result, err := repository.ReencryptFields(ctx, cm, logger, *batchSize)
+		if err != nil {
+			logger.Fatalf("Re-encryption failed: %v", err)
+		}
+
+		fmt.Printf("Metadata re-encrypted:\n")
+		for table, scanned := range result.Scanned {
+			fmt.Printf("  %s: %d rewritten of %d scanned\n", table, result.Rewritten[table], scanned)
+		}
+
+	default:
+		logger.Fatalf("Unknown action: %s", *action)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/yourorg/lab-gateway/internal/server"
+	"github.com/yourorg/lab-gateway/pkg/config"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/fieldcrypt"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+	"github.com/yourorg/lab-gateway/pkg/repository/sqlite"
+	"github.com/yourorg/lab-gateway/pkg/tracing"
+)
+
+func main() {
+	var (
+		migrate = flag.Bool("migrate", false, "Apply pending migrations before serving, in addition to DB_AUTO_MIGRATE")
+	)
+	flag.Parse()
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	log, err := logger.NewLogger(logger.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+	})
+	if err != nil {
+		logger.NewDefaultLogger().Fatalf("Invalid logging configuration: %v", err)
+	}
+
+	// Exit only once run has returned, so that its deferred cleanup runs
+	if err := run(cfg, log, *migrate); err != nil {
+		log.Fatalf("Lab instrument gateway failed: %v", err)
+	}
+}
+
+// run starts the gateway and serves until SIGINT or SIGTERM, releasing
+// everything it set up before returning
+func run(cfg *config.Config, log *logger.Logger, migrate bool) error {
+	// Stop on SIGINT or SIGTERM, also while still starting up
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Read secrets from the configured backend, refusing insecure defaults
+	provider, err := cfg.ResolveSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("refusing to start: %w", err)
+	}
+
+	// Field encryption must be set before any repository reads or writes
+	cipher, err := fieldcrypt.Load(fieldcrypt.Config{
+		KeyFile:    cfg.Security.FieldEncryptionKeyFile,
+		Fields:     fieldcrypt.ParseFields(cfg.Security.FieldEncryptionFields),
+		DataKeyTTL: cfg.Security.FieldEncryptionKeyTTL,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid field encryption configuration: %w", err)
+	}
+	if cipher != nil {
+		repository.SetFieldCipher(cipher)
+	}
+
+	// Trace context is propagated even when traces are not exported
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	defer func() {
+		// Export the spans recorded so far, including those of drained calls
+		flushCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.WithError(err).Warn("Failed to flush traces")
+		}
+	}()
+
+	// Parse the server configuration before connecting, so that mistakes
+	// fail fast
+	serverConfig, err := newServerConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid server configuration: %w", err)
+	}
+
+	// Create repositories and the gRPC server
+	var repos repository.RepositoryManager
+	var cm *db.ConnectionManager
+	switch cfg.Database.Driver {
+	case config.DriverMemory:
+		log.Warn("Using in-memory repositories; data is lost when the gateway stops")
+		repos = memory.NewRepositoryManager()
+	case config.DriverPostgres:
+		// Create connection manager
+		cm, err = db.NewConnectionManager(&cfg.Database, log)
+		if err != nil {
+			return fmt.Errorf("failed to create connection manager: %w", err)
+		}
+		defer cm.Close()
+
+		// Wait for database to be ready
+		if err := cm.WaitForConnection(ctx, cfg.Database.WaitTimeout); err != nil {
+			return fmt.Errorf("database not ready: %w", err)
+		}
+		cm.RefreshCredentials(db.ProviderCredentials(provider), cfg.Database.CredentialsRefreshInterval)
+
+		// Apply pending migrations
+		if cfg.Database.AutoMigrate || migrate {
+			migrator := db.NewMigrationRunner(cm.GetDB(), cfg.Database.MigrationsPath, log)
+			if err := migrator.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize migrations: %w", err)
+			}
+			if err := migrator.Up(ctx); err != nil {
+				return fmt.Errorf("migration up failed: %w", err)
+			}
+		}
+
+		repos = repository.NewRepositoryManager(cm, log)
+	case config.DriverSQLite:
+		cm, err = db.NewConnectionManager(&cfg.Database, log)
+		if err != nil {
+			return fmt.Errorf("failed to create connection manager: %w", err)
+		}
+		defer cm.Close()
+
+		// Apply pending migrations, kept apart from the PostgreSQL ones
+		if cfg.Database.AutoMigrate || migrate {
+			migrator := db.NewSQLiteMigrationRunner(cm.GetDB(), filepath.Join(cfg.Database.MigrationsPath, "sqlite"), log)
+			if err := migrator.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize migrations: %w", err)
+			}
+			if err := migrator.Up(ctx); err != nil {
+				return fmt.Errorf("migration up failed: %w", err)
+			}
+		}
+
+		repos = sqlite.NewRepositoryManager(cm, log)
+	default:
+		return fmt.Errorf("unknown database driver %q", cfg.Database.Driver)
+	}
+
+	grpcServer, err := server.NewGRPCServer(serverConfig, repos, log)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC server: %w", err)
+	}
+	if cm != nil {
+		grpcServer.ReportDatabaseStats(cm.GetStats)
+	}
+	if err := grpcServer.Start(); err != nil {
+		return fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+
+	log.WithFields(map[string]interface{}{
+		"environment": cfg.Environment,
+		"grpc_port":   serverConfig.Port,
+	}).Info("Lab instrument gateway started")
+
+	<-ctx.Done()
+
+	// A second signal terminates immediately
+	stop()
+
+	// Drain open calls and streams, cutting them off at the deadline
+	log.WithFields(map[string]interface{}{
+		"timeout": cfg.Server.ShutdownTimeout.String(),
+	}).Info("Shutting down lab instrument gateway")
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := grpcServer.Stop(drainCtx); err != nil {
+		log.WithError(err).Error("Failed to stop gRPC server")
+	}
+
+	log.Info("Lab instrument gateway stopped")
+	return nil
+}
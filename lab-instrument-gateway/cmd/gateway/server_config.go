@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/yourorg/lab-gateway/internal/anomaly"
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/internal/esign"
+	"github.com/yourorg/lab-gateway/internal/health"
+	"github.com/yourorg/lab-gateway/internal/ingest"
+	"github.com/yourorg/lab-gateway/internal/provisioning"
+	"github.com/yourorg/lab-gateway/internal/ratelimit"
+	"github.com/yourorg/lab-gateway/internal/rbac"
+	"github.com/yourorg/lab-gateway/internal/server"
+	"github.com/yourorg/lab-gateway/pkg/config"
+)
+
+// newServerConfig maps the application configuration to the gRPC server's,
+// parsing the settings written as lists
+func newServerConfig(cfg *config.Config) (server.Config, error) {
+	methodLimits, err := ratelimit.ParseMethodLimits(cfg.Security.RateLimitMethods)
+	if err != nil {
+		return server.Config{}, fmt.Errorf("invalid RATE_LIMIT_METHODS: %w", err)
+	}
+
+	rules, err := anomaly.ParseRules(cfg.Ingest.AnomalyRules)
+	if err != nil {
+		return server.Config{}, fmt.Errorf("invalid ANOMALY_RULES: %w", err)
+	}
+
+	requirements, err := esign.ParseRequirements(cfg.Security.SignatureRequirements)
+	if err != nil {
+		return server.Config{}, fmt.Errorf("invalid SIGNATURE_REQUIREMENTS: %w", err)
+	}
+
+	return server.Config{
+		Port:           cfg.Server.GRPCPort,
+		MaxMessageSize: cfg.Performance.MaxMessageSize,
+		MaxConcurrent:  cfg.Performance.MaxConcurrentStreams,
+
+		EscalationInterval:     cfg.Alerting.EscalationInterval,
+		HeartbeatTimeout:       cfg.Device.HeartbeatTimeout,
+		HeartbeatCheckInterval: cfg.Device.HeartbeatCheckInterval,
+
+		Ingest: ingest.Config{
+			BatchSize:        cfg.Ingest.BatchSize,
+			FlushInterval:    cfg.Ingest.FlushInterval,
+			MaxBuffered:      cfg.Ingest.MaxBuffered,
+			AnomalyDetection: cfg.Ingest.AnomalyDetection,
+			Anomaly: anomaly.Config{
+				WindowSize:      cfg.Ingest.AnomalyWindowSize,
+				WarmupSamples:   cfg.Ingest.AnomalyWarmupSamples,
+				ZScoreThreshold: cfg.Ingest.AnomalyZScoreThreshold,
+				EWMALambda:      cfg.Ingest.AnomalyEWMALambda,
+				EWMAWidth:       cfg.Ingest.AnomalyEWMAWidth,
+				Rules:           rules,
+			},
+			AlertCooldown: cfg.Ingest.AnomalyAlertCooldown,
+		},
+
+		TLS: server.TLSConfig{
+			Enabled:        cfg.Security.TLSEnabled,
+			CertFile:       cfg.Server.TLSCert,
+			KeyFile:        cfg.Server.TLSKey,
+			CAFile:         cfg.Server.TLSCA,
+			ClientAuth:     cfg.Security.TLSClientAuth,
+			ReloadInterval: cfg.Security.TLSReloadInterval,
+		},
+
+		Auth: server.AuthConfig{
+			Enabled: cfg.Security.AuthEnabled,
+			JWT: auth.JWTConfig{
+				Secret:   cfg.Security.JWTSecret,
+				JWKSFile: cfg.Security.JWTJWKSFile,
+				Issuer:   cfg.Security.JWTIssuer,
+				Audience: cfg.Security.JWTAudience,
+			},
+			RBAC: rbac.Config{
+				Enabled:  cfg.Security.RBACEnabled,
+				CacheTTL: cfg.Security.RBACCacheTTL,
+			},
+		},
+		AuditFailClosed: cfg.Security.AuditFailClosed,
+
+		Provisioning: provisioning.Config{
+			CACertFile:          cfg.Provisioning.CACertFile,
+			CAKeyFile:           cfg.Provisioning.CAKeyFile,
+			TokenTTL:            cfg.Provisioning.EnrollmentTokenTTL,
+			CertificateValidity: cfg.Provisioning.CertificateValidity,
+			APIKeyValidity:      cfg.Provisioning.APIKeyValidity,
+		},
+
+		RateLimit: ratelimit.Config{
+			Enabled: cfg.Security.RateLimitEnabled,
+			Principal: ratelimit.Limit{
+				Requests: cfg.Security.RateLimitRequests,
+				Window:   cfg.Security.RateLimitWindow,
+				Burst:    cfg.Security.RateLimitBurst,
+			},
+			Device: ratelimit.Limit{
+				Requests: cfg.Security.RateLimitDeviceRequests,
+				Window:   cfg.Security.RateLimitDeviceWindow,
+			},
+			Methods: methodLimits,
+			StreamMessages: ratelimit.Limit{
+				Requests: cfg.Security.StreamRateLimitMessages,
+				Window:   cfg.Security.StreamRateLimitWindow,
+			},
+			StreamMaxDelay: cfg.Security.StreamRateLimitMaxDelay,
+		},
+
+		Signatures: esign.Config{
+			Requirements:     requirements,
+			MaxCredentialAge: cfg.Security.SignatureMaxTokenAge,
+		},
+
+		Admin: server.AdminConfig{
+			Enabled:         cfg.Server.AdminEnabled,
+			Port:            cfg.Metrics.Port,
+			Metrics:         cfg.Metrics.Enabled,
+			MetricsPath:     cfg.Metrics.Path,
+			MetricsInterval: cfg.Metrics.PublishInterval,
+		},
+
+		Health: health.Config{
+			Interval: cfg.Server.HealthCheckInterval,
+			Timeout:  cfg.Server.HealthCheckTimeout,
+		},
+
+		Registry: server.RegistryConfig{
+			Backend:   cfg.Device.Registry,
+			ReplicaID: cfg.Device.ReplicaID,
+			Redis: device.RedisRegistryConfig{
+				Addr:      net.JoinHostPort(cfg.Redis.Host, strconv.Itoa(cfg.Redis.Port)),
+				Password:  cfg.Redis.Password,
+				DB:        cfg.Redis.DB,
+				KeyPrefix: cfg.Redis.KeyPrefix,
+				TTL:       cfg.Device.RegistryTTL,
+			},
+		},
+	}, nil
+}
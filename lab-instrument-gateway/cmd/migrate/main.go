@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/yourorg/lab-gateway/pkg/config"
@@ -24,6 +25,14 @@ func main() {
 	
 	// Initialize logger
 	logger := logger.NewDefaultLogger()
+
+	// Read secrets from the configured backend, refusing insecure defaults
+	if _, err := cfg.ResolveSecrets(context.Background()); err != nil {
+		logger.Fatalf("Failed to resolve secrets: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatalf("Refusing to start: %v", err)
+	}
 	
 	// Create connection manager
 	cm, err := db.NewConnectionManager(&cfg.Database, logger)
@@ -40,8 +49,11 @@ func main() {
 		logger.Fatalf("Database not ready: %v", err)
 	}
 	
-	// Create migration runner
+	// Create migration runner, SQLite migrations live in their own directory
 	migrator := db.NewMigrationRunner(cm.GetDB(), *migrationsPath, logger)
+	if cfg.Database.Driver == config.DriverSQLite {
+		migrator = db.NewSQLiteMigrationRunner(cm.GetDB(), filepath.Join(*migrationsPath, "sqlite"), logger)
+	}
 	
 	// Initialize migrations table
 	if err := migrator.Initialize(ctx); err != nil {
@@ -0,0 +1,325 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// Escalator periodically scans unacknowledged alerts and notifies the next
+// step of the escalation policy attached to the first matching alert route.
+// Each escalation is recorded in the audit trail.
+type Escalator struct {
+	repos    repository.RepositoryManager
+	notifier Notifier
+	logger   *logger.Logger
+
+	// Configuration
+	interval time.Duration
+
+	// Channels for lifecycle management
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewEscalator creates a new escalator. Call Start to begin scanning.
+func NewEscalator(repos repository.RepositoryManager, notifier Notifier, interval time.Duration, logger *logger.Logger) *Escalator {
+	if notifier == nil {
+		notifier = NewLogNotifier(logger)
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &Escalator{
+		repos:    repos,
+		notifier: notifier,
+		logger:   logger,
+		interval: interval,
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// Start launches the background escalation routine
+func (e *Escalator) Start() {
+	go e.escalationRoutine()
+}
+
+// Close stops the background escalation routine
+func (e *Escalator) Close() error {
+	close(e.stopChan)
+
+	select {
+	case <-e.doneChan:
+		e.logger.Info("Alert escalation routine stopped")
+	case <-time.After(5 * time.Second):
+		e.logger.Warn("Alert escalation routine did not stop within timeout")
+	}
+
+	return nil
+}
+
+// escalationRoutine runs periodic escalation sweeps
+func (e *Escalator) escalationRoutine() {
+	defer close(e.doneChan)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case now := <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+			if err := e.Sweep(ctx, now); err != nil {
+				e.logger.WithError(err).Error("Alert escalation sweep failed")
+			}
+			cancel()
+		}
+	}
+}
+
+// Sweep escalates every unacknowledged alert whose next step is due at the
+// given time. Failures for individual alerts are logged and retried on the
+// next sweep.
+func (e *Escalator) Sweep(ctx context.Context, now time.Time) error {
+	alerts, err := e.repos.Alert().GetUnacknowledged(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load unacknowledged alerts: %w", err)
+	}
+
+	pending := make([]*models.Alert, 0, len(alerts))
+	alertIDs := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.IsResolved() || alert.Silenced {
+			continue
+		}
+		pending = append(pending, alert)
+		alertIDs = append(alertIDs, alert.ID)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	routes, err := e.repos.Escalation().ListRoutes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load alert routes: %w", err)
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	escalations, err := e.repos.Escalation().GetEscalations(ctx, alertIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load alert escalations: %w", err)
+	}
+
+	sweep := &escalationSweep{
+		escalator:   e,
+		now:         now,
+		deviceTypes: make(map[string]string),
+		policies:    make(map[string]*models.EscalationPolicy),
+		schedules:   make(map[string]*models.OnCallSchedule),
+	}
+
+	escalated := 0
+	for _, alert := range pending {
+		level := 0
+		if escalation, exists := escalations[alert.ID]; exists {
+			level = escalation.Level
+		}
+
+		ok, err := sweep.escalate(ctx, alert, routes, level)
+		if err != nil {
+			e.logger.WithError(err).WithField("alert_id", alert.ID).Error("Failed to escalate alert")
+			continue
+		}
+		if ok {
+			escalated++
+		}
+	}
+
+	if escalated > 0 {
+		e.logger.WithFields(map[string]interface{}{
+			"escalated": escalated,
+			"pending":   len(pending),
+		}).Info("Alert escalation sweep completed")
+	}
+
+	return nil
+}
+
+// escalationSweep caches lookups shared by all alerts within a single sweep
+type escalationSweep struct {
+	escalator   *Escalator
+	now         time.Time
+	deviceTypes map[string]string
+	policies    map[string]*models.EscalationPolicy
+	schedules   map[string]*models.OnCallSchedule
+}
+
+// escalate records progress to the next due step for the alert, then
+// notifies its recipients. It returns false if no step was due.
+func (s *escalationSweep) escalate(ctx context.Context, alert *models.Alert, routes []*models.AlertRoute, level int) (bool, error) {
+	deviceType := s.deviceType(ctx, alert)
+
+	var route *models.AlertRoute
+	for _, candidate := range routes {
+		if candidate.Matches(alert, deviceType) {
+			route = candidate
+			break
+		}
+	}
+	if route == nil {
+		return false, nil
+	}
+
+	policy, err := s.policy(ctx, route.PolicyID)
+	if err != nil {
+		return false, err
+	}
+
+	step := policy.NextStep(level, s.now.Sub(alert.CreatedAt))
+	if step == nil {
+		return false, nil
+	}
+
+	recipients, err := s.recipients(ctx, step)
+	if err != nil {
+		return false, err
+	}
+
+	escalation := &models.AlertEscalation{
+		AlertID:         alert.ID,
+		PolicyID:        policy.ID,
+		Level:           level + 1,
+		LastEscalatedAt: s.now,
+	}
+	entry := &models.AuditEntry{
+		Actor:        models.AuditActorSystem,
+		Action:       models.AuditActionAlertEscalated,
+		ResourceType: "alert",
+		ResourceID:   alert.ID,
+		Reason:       fmt.Sprintf("escalation policy %s", policy.Name),
+		Outcome:      models.AuditOutcomeSuccess,
+		Before:       map[string]interface{}{"escalation_level": level},
+		After: map[string]interface{}{
+			"escalation_level": escalation.Level,
+			"policy_id":        policy.ID,
+			"recipients":       recipients,
+		},
+	}
+
+	// The escalation is recorded only along with its audit entry, and before
+	// anyone is paged: a sweep retrying a step that failed to be recorded
+	// must not page its recipients twice. A step whose delivery fails is not
+	// retried; the next step of the policy still follows.
+	err = s.escalator.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if err := repos.Escalation().RecordEscalation(ctx, escalation); err != nil {
+			return err
+		}
+		return audit.NewRecorder(repos.Audit(), s.escalator.logger).Record(ctx, entry)
+	})
+	if err != nil {
+		return false, err
+	}
+	alertEscalationsTotal.WithLabelValues(strconv.Itoa(escalation.Level)).Inc()
+
+	notification := Notification{
+		Alert:           alert,
+		Recipients:      recipients,
+		EscalationLevel: escalation.Level,
+	}
+	if err := s.escalator.notifier.Notify(ctx, notification); err != nil {
+		alertNotificationsTotal.WithLabelValues("error").Inc()
+		return false, fmt.Errorf("failed to deliver escalation to level %d: %w", escalation.Level, err)
+	}
+	alertNotificationsTotal.WithLabelValues("success").Inc()
+
+	s.escalator.logger.WithFields(map[string]interface{}{
+		"alert_id":   alert.ID,
+		"policy_id":  policy.ID,
+		"level":      escalation.Level,
+		"recipients": recipients,
+	}).Info("Alert escalated")
+
+	return true, nil
+}
+
+// deviceType returns the type of the alert's device, or "" if unknown
+func (s *escalationSweep) deviceType(ctx context.Context, alert *models.Alert) string {
+	if alert.DeviceID == nil {
+		return ""
+	}
+
+	if deviceType, exists := s.deviceTypes[*alert.DeviceID]; exists {
+		return deviceType
+	}
+
+	deviceType := ""
+	device, err := s.escalator.repos.Device().GetByID(ctx, *alert.DeviceID)
+	if err != nil {
+		s.escalator.logger.WithError(err).WithField("device_id", *alert.DeviceID).Debug("Alert device lookup failed")
+	} else {
+		deviceType = device.Type
+	}
+
+	s.deviceTypes[*alert.DeviceID] = deviceType
+	return deviceType
+}
+
+// policy returns the escalation policy with the given ID
+func (s *escalationSweep) policy(ctx context.Context, id string) (*models.EscalationPolicy, error) {
+	if policy, exists := s.policies[id]; exists {
+		return policy, nil
+	}
+
+	policy, err := s.escalator.repos.Escalation().GetPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.policies[id] = policy
+	return policy, nil
+}
+
+// recipients resolves the direct recipients of a step plus whoever is on
+// call for its schedule, without duplicates
+func (s *escalationSweep) recipients(ctx context.Context, step *models.EscalationStep) ([]string, error) {
+	seen := make(map[string]bool)
+	recipients := make([]string, 0, len(step.Recipients)+1)
+
+	add := func(recipient string) {
+		if recipient != "" && !seen[recipient] {
+			seen[recipient] = true
+			recipients = append(recipients, recipient)
+		}
+	}
+
+	for _, recipient := range step.Recipients {
+		add(recipient)
+	}
+
+	if step.ScheduleID != nil {
+		schedule, exists := s.schedules[*step.ScheduleID]
+		if !exists {
+			var err error
+			schedule, err = s.escalator.repos.Escalation().GetSchedule(ctx, *step.ScheduleID)
+			if err != nil {
+				return nil, err
+			}
+			s.schedules[*step.ScheduleID] = schedule
+		}
+		add(schedule.OnCall(s.now))
+	}
+
+	return recipients, nil
+}
@@ -0,0 +1,210 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// newEscalationFixture sets up a critical-alert route with a three step
+// policy: the on-call bench tech immediately, the lab manager after 15
+// minutes and facilities after an hour
+func newEscalationFixture(t *testing.T, rotationStart time.Time) repository.RepositoryManager {
+	repos := newTestRepos(t, "device-1", "sensor", models.DeviceStatusOffline)
+	ctx := context.Background()
+
+	schedule := &models.OnCallSchedule{
+		Name:          "Bench techs",
+		RotationStart: rotationStart,
+		Participants:  []string{"alice", "bob"},
+	}
+	require.NoError(t, repos.Escalation().CreateSchedule(ctx, schedule))
+
+	policy := &models.EscalationPolicy{
+		Name: "Critical",
+		Steps: []models.EscalationStep{
+			{Delay: 0, ScheduleID: &schedule.ID},
+			{Delay: 15 * time.Minute, Recipients: []string{"lab-manager"}},
+			{Delay: time.Hour, Recipients: []string{"facilities"}},
+		},
+	}
+	require.NoError(t, repos.Escalation().CreatePolicy(ctx, policy))
+
+	critical := models.AlertSeverityCritical
+	require.NoError(t, repos.Escalation().CreateRoute(ctx, &models.AlertRoute{
+		Name:        "Critical alerts",
+		MinSeverity: &critical,
+		PolicyID:    policy.ID,
+	}))
+
+	return repos
+}
+
+func TestEscalator_Sweep(t *testing.T) {
+	createdAt := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	repos := newEscalationFixture(t, createdAt.Add(-8*24*time.Hour))
+	ctx := context.Background()
+
+	deviceID := "device-1"
+	alert := &models.Alert{
+		ID:        uuid.New().String(),
+		DeviceID:  &deviceID,
+		Type:      models.AlertTypeDeviceOffline,
+		Severity:  models.AlertSeverityCritical,
+		Message:   "device offline",
+		CreatedAt: createdAt,
+	}
+	require.NoError(t, repos.Alert().Create(ctx, alert))
+
+	notifier := &recordingNotifier{}
+	escalator := NewEscalator(repos, notifier, time.Minute, logger.NewDefaultLogger())
+
+	// Immediately: bench tech on call (second week of the rotation)
+	require.NoError(t, escalator.Sweep(ctx, createdAt.Add(time.Minute)))
+	require.Len(t, notifier.notifications, 1)
+	assert.Equal(t, []string{"bob"}, notifier.notifications[0].Recipients)
+	assert.Equal(t, 1, notifier.notifications[0].EscalationLevel)
+
+	// Before 15 minutes nothing further happens
+	require.NoError(t, escalator.Sweep(ctx, createdAt.Add(10*time.Minute)))
+	assert.Len(t, notifier.notifications, 1)
+
+	// After 15 minutes: lab manager
+	require.NoError(t, escalator.Sweep(ctx, createdAt.Add(16*time.Minute)))
+	require.Len(t, notifier.notifications, 2)
+	assert.Equal(t, []string{"lab-manager"}, notifier.notifications[1].Recipients)
+
+	// After an hour: facilities, then the policy is exhausted
+	require.NoError(t, escalator.Sweep(ctx, createdAt.Add(61*time.Minute)))
+	require.NoError(t, escalator.Sweep(ctx, createdAt.Add(2*time.Hour)))
+	require.Len(t, notifier.notifications, 3)
+	assert.Equal(t, []string{"facilities"}, notifier.notifications[2].Recipients)
+
+	escalations, err := repos.Escalation().GetEscalations(ctx, []string{alert.ID})
+	require.NoError(t, err)
+	require.Contains(t, escalations, alert.ID)
+	assert.Equal(t, 3, escalations[alert.ID].Level)
+
+	// Each escalation is in the audit trail
+	entries, err := repos.Audit().List(ctx, repository.AuditFilter{ResourceID: alert.ID})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, models.AuditActorSystem, entries[0].Actor)
+	assert.Equal(t, models.AuditActionAlertEscalated, entries[0].Action)
+	assert.EqualValues(t, 3, entries[0].After["escalation_level"])
+}
+
+func TestEscalator_Sweep_SkipsUnroutedAndAcknowledged(t *testing.T) {
+	createdAt := time.Now().Add(-2 * time.Hour)
+	repos := newEscalationFixture(t, createdAt)
+	ctx := context.Background()
+
+	acknowledged := &models.Alert{Type: models.AlertTypeDeviceError, Severity: models.AlertSeverityCritical, Message: "ack", CreatedAt: createdAt}
+	warning := &models.Alert{Type: models.AlertTypeDeviceError, Severity: models.AlertSeverityWarning, Message: "warn", CreatedAt: createdAt}
+	silenced := &models.Alert{Type: models.AlertTypeDeviceError, Severity: models.AlertSeverityCritical, Message: "muted", CreatedAt: createdAt}
+	silenced.Silence(models.MaintenanceSilence)
+
+	var alertIDs []string
+	for _, alert := range []*models.Alert{acknowledged, warning, silenced} {
+		alert.ID = uuid.New().String()
+		require.NoError(t, repos.Alert().Create(ctx, alert))
+		alertIDs = append(alertIDs, alert.ID)
+	}
+	require.NoError(t, repos.Alert().Acknowledge(ctx, acknowledged.ID, "alice"))
+
+	notifier := &recordingNotifier{}
+	escalator := NewEscalator(repos, notifier, time.Minute, logger.NewDefaultLogger())
+
+	require.NoError(t, escalator.Sweep(ctx, time.Now()))
+	assert.Empty(t, notifier.notifications)
+
+	escalations, err := repos.Escalation().GetEscalations(ctx, alertIDs)
+	require.NoError(t, err)
+	assert.Empty(t, escalations)
+}
+
+// unavailableAudit fails every audit append, including those made within
+// transactions
+type unavailableAudit struct {
+	repository.RepositoryManager
+}
+
+func (u unavailableAudit) Audit() repository.AuditRepository {
+	return unavailableAuditRepo{u.RepositoryManager.Audit()}
+}
+
+func (u unavailableAudit) WithTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.RepositoryManager) error) error {
+	return u.RepositoryManager.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		return fn(ctx, unavailableAudit{repos})
+	})
+}
+
+type unavailableAuditRepo struct {
+	repository.AuditRepository
+}
+
+func (unavailableAuditRepo) Append(ctx context.Context, entry *models.AuditEntry) error {
+	return errors.New("audit trail unavailable")
+}
+
+// failingNotifier fails every delivery, counting the attempts
+type failingNotifier struct {
+	attempts []Notification
+}
+
+func (n *failingNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.attempts = append(n.attempts, notification)
+	return errors.New("pager unavailable")
+}
+
+func TestEscalator_Sweep_RecordsBeforeNotifying(t *testing.T) {
+	createdAt := time.Now().Add(-time.Minute)
+	repos := newEscalationFixture(t, createdAt)
+	ctx := context.Background()
+
+	alert := &models.Alert{ID: uuid.New().String(), Type: models.AlertTypeDeviceError, Severity: models.AlertSeverityCritical, Message: "down", CreatedAt: createdAt}
+	require.NoError(t, repos.Alert().Create(ctx, alert))
+
+	// Nobody is paged for a step that could not be recorded
+	notifier := &recordingNotifier{}
+	require.NoError(t, NewEscalator(unavailableAudit{repos}, notifier, time.Minute, logger.NewDefaultLogger()).Sweep(ctx, time.Now()))
+	assert.Empty(t, notifier.notifications)
+	escalations, err := repos.Escalation().GetEscalations(ctx, []string{alert.ID})
+	require.NoError(t, err)
+	assert.Empty(t, escalations)
+
+	// A step whose delivery fails stays recorded and is not paged again
+	failing := &failingNotifier{}
+	escalator := NewEscalator(repos, failing, time.Minute, logger.NewDefaultLogger())
+	require.NoError(t, escalator.Sweep(ctx, time.Now()))
+	require.NoError(t, escalator.Sweep(ctx, time.Now()))
+	require.Len(t, failing.attempts, 1)
+	assert.Equal(t, 1, failing.attempts[0].EscalationLevel)
+
+	escalations, err = repos.Escalation().GetEscalations(ctx, []string{alert.ID})
+	require.NoError(t, err)
+	require.Contains(t, escalations, alert.ID)
+	assert.Equal(t, 1, escalations[alert.ID].Level)
+}
+
+func TestOnCallSchedule_OnCall(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := &models.OnCallSchedule{RotationStart: start, Participants: []string{"alice", "bob", "carol"}}
+	week := 7 * 24 * time.Hour
+
+	assert.Equal(t, "alice", schedule.OnCall(start))
+	assert.Equal(t, "alice", schedule.OnCall(start.Add(week-time.Second)))
+	assert.Equal(t, "bob", schedule.OnCall(start.Add(week)))
+	assert.Equal(t, "alice", schedule.OnCall(start.Add(3*week)))
+	assert.Equal(t, "carol", schedule.OnCall(start.Add(-time.Hour)))
+	assert.Equal(t, "carol", schedule.OnCall(start.Add(-week)))
+}
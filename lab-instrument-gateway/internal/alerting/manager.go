@@ -0,0 +1,148 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// Manager raises alerts, applying silences and maintenance muting before
+// notification. Muted alerts are still persisted, flagged as silenced.
+type Manager struct {
+	repos    repository.RepositoryManager
+	notifier Notifier
+	logger   *logger.Logger
+}
+
+// NewManager creates a new alert manager
+func NewManager(repos repository.RepositoryManager, notifier Notifier, logger *logger.Logger) *Manager {
+	if notifier == nil {
+		notifier = NewLogNotifier(logger)
+	}
+
+	return &Manager{
+		repos:    repos,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// Raise persists an alert and notifies about it unless it is silenced
+func (m *Manager) Raise(ctx context.Context, alert *models.Alert) error {
+	if alert.ID == "" {
+		alert.ID = uuid.New().String()
+	}
+	alert.SetDefaults()
+
+	if err := alert.Validate(); err != nil {
+		return fmt.Errorf("alert validation failed: %w", err)
+	}
+
+	silencedBy, err := m.findSilencer(ctx, alert)
+	if err != nil {
+		// Fail open: a missed silence is noisy, a missed alert is dangerous
+		m.logger.WithError(err).WithField("alert_id", alert.ID).Warn("Failed to evaluate alert silences")
+	}
+	if silencedBy != "" {
+		alert.Silence(silencedBy)
+	}
+
+	if err := m.repos.Alert().Create(ctx, alert); err != nil {
+		return fmt.Errorf("failed to store alert: %w", err)
+	}
+	alertsRaisedTotal.WithLabelValues(string(alert.Type), string(alert.Severity), strconv.FormatBool(alert.Silenced)).Inc()
+
+	if alert.Silenced {
+		m.logger.WithFields(map[string]interface{}{
+			"alert_id":    alert.ID,
+			"type":        alert.Type,
+			"silenced_by": silencedBy,
+		}).Debug("Alert silenced, skipping notification")
+		return nil
+	}
+
+	m.notify(ctx, Notification{Alert: alert})
+	return nil
+}
+
+// ResolveActive resolves every unresolved alert of the given type raised
+// for the device and returns how many were resolved. The alerts are read
+// from the primary database, so that one raised moments ago is resolved too.
+func (m *Manager) ResolveActive(ctx context.Context, deviceID string, alertType models.AlertType) (int, error) {
+	resolved := false
+	alerts, err := m.repos.Alert().List(db.WithReadYourWrites(ctx), repository.AlertFilter{
+		DeviceIDs: []string{deviceID},
+		Types:     []models.AlertType{alertType},
+		Resolved:  &resolved,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load active alerts: %w", err)
+	}
+
+	count := 0
+	for _, alert := range alerts {
+		if err := m.repos.Alert().Resolve(ctx, alert.ID); err != nil {
+			m.logger.WithError(err).WithField("alert_id", alert.ID).Warn("Failed to resolve alert")
+			continue
+		}
+		count++
+	}
+
+	if count > 0 {
+		m.logger.WithFields(map[string]interface{}{
+			"device_id": deviceID,
+			"type":      alertType,
+			"resolved":  count,
+		}).Info("Alerts auto-resolved")
+	}
+
+	return count, nil
+}
+
+// findSilencer returns the ID of the first silence matching the alert, the
+// maintenance marker if the alert's device is in maintenance, or "" if the
+// alert should be delivered
+func (m *Manager) findSilencer(ctx context.Context, alert *models.Alert) (string, error) {
+	deviceType := ""
+	if alert.DeviceID != nil {
+		device, err := m.repos.Device().GetByID(ctx, *alert.DeviceID)
+		if err != nil {
+			m.logger.WithError(err).WithField("device_id", *alert.DeviceID).Debug("Alert device lookup failed")
+		} else {
+			if device.Status == models.DeviceStatusMaintenance {
+				return models.MaintenanceSilence, nil
+			}
+			deviceType = device.Type
+		}
+	}
+
+	silences, err := m.repos.Silence().GetActive(ctx, alert.CreatedAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to load active silences: %w", err)
+	}
+
+	for _, silence := range silences {
+		if silence.Matches(alert, deviceType) {
+			return silence.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// notify delivers a notification, logging rather than returning failures so
+// that delivery problems never prevent an alert from being recorded
+func (m *Manager) notify(ctx context.Context, notification Notification) {
+	if err := m.notifier.Notify(ctx, notification); err != nil {
+		alertNotificationsTotal.WithLabelValues("error").Inc()
+		m.logger.WithError(err).WithField("alert_id", notification.Alert.ID).Error("Failed to deliver alert notification")
+		return
+	}
+	alertNotificationsTotal.WithLabelValues("success").Inc()
+}
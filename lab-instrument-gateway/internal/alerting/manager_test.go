@@ -0,0 +1,175 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+)
+
+// newTestRepos returns in-memory repositories holding a device of the given
+// type and status
+func newTestRepos(t *testing.T, deviceID, deviceType string, status models.DeviceStatus) repository.RepositoryManager {
+	repos := memory.NewRepositoryManager()
+	require.NoError(t, repos.Device().Create(context.Background(), &models.Device{
+		ID:     deviceID,
+		Name:   deviceID,
+		Type:   deviceType,
+		Status: status,
+	}))
+	return repos
+}
+
+// recordingNotifier records every notification it receives
+type recordingNotifier struct {
+	notifications []Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func newTestAlert(deviceID string, alertType models.AlertType) *models.Alert {
+	return &models.Alert{
+		DeviceID: &deviceID,
+		Type:     alertType,
+		Severity: models.AlertSeverityError,
+		Message:  "test alert",
+	}
+}
+
+func TestManager_Raise(t *testing.T) {
+	alertType := models.AlertTypeDeviceError
+	otherType := models.AlertTypeDataQuality
+	sensor := "sensor"
+
+	tests := []struct {
+		name         string
+		deviceStatus models.DeviceStatus
+		silence      *models.Silence
+		maintenance  bool
+		silenced     bool
+	}{
+		{
+			name:         "no silences - notifies",
+			deviceStatus: models.DeviceStatusOnline,
+		},
+		{
+			name:         "device in maintenance - muted",
+			deviceStatus: models.DeviceStatusMaintenance,
+			maintenance:  true,
+			silenced:     true,
+		},
+		{
+			name:         "matching silence by device type and alert type - muted",
+			deviceStatus: models.DeviceStatusOnline,
+			silence: &models.Silence{
+				DeviceType: &sensor,
+				AlertType:  &alertType,
+				StartsAt:   time.Now().Add(-time.Hour),
+				EndsAt:     time.Now().Add(time.Hour),
+				CreatedBy:  "alice",
+			},
+			silenced: true,
+		},
+		{
+			name:         "silence for another alert type - notifies",
+			deviceStatus: models.DeviceStatusOnline,
+			silence: &models.Silence{
+				AlertType: &otherType,
+				StartsAt:  time.Now().Add(-time.Hour),
+				EndsAt:    time.Now().Add(time.Hour),
+				CreatedBy: "alice",
+			},
+		},
+		{
+			name:         "expired silence - notifies",
+			deviceStatus: models.DeviceStatusOnline,
+			silence: &models.Silence{
+				AlertType: &alertType,
+				StartsAt:  time.Now().Add(-2 * time.Hour),
+				EndsAt:    time.Now().Add(-time.Hour),
+				CreatedBy: "alice",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := newTestRepos(t, "device-1", sensor, tt.deviceStatus)
+			if tt.silence != nil {
+				require.NoError(t, repos.Silence().Create(ctx, tt.silence))
+			}
+			notifier := &recordingNotifier{}
+
+			manager := NewManager(repos, notifier, logger.NewDefaultLogger())
+			alert := newTestAlert("device-1", alertType)
+
+			require.NoError(t, manager.Raise(ctx, alert))
+			assert.NotEmpty(t, alert.ID)
+
+			stored, err := repos.Alert().GetByID(ctx, alert.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.silenced, stored.Silenced)
+
+			if tt.silenced {
+				wantSilencedBy := models.MaintenanceSilence
+				if !tt.maintenance {
+					wantSilencedBy = tt.silence.ID
+				}
+				require.NotNil(t, stored.SilencedBy)
+				assert.Equal(t, wantSilencedBy, *stored.SilencedBy)
+				assert.Empty(t, notifier.notifications)
+			} else {
+				assert.Len(t, notifier.notifications, 1)
+			}
+		})
+	}
+}
+
+func TestManager_Raise_InvalidAlert(t *testing.T) {
+	repos := memory.NewRepositoryManager()
+	manager := NewManager(repos, &recordingNotifier{}, logger.NewDefaultLogger())
+
+	err := manager.Raise(context.Background(), &models.Alert{Type: "bogus", Severity: models.AlertSeverityInfo, Message: "x"})
+	assert.Error(t, err)
+
+	count, err := repos.Alert().Count(context.Background(), repository.AlertFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestManager_ResolveActive(t *testing.T) {
+	repos := newTestRepos(t, "device-1", "sensor", models.DeviceStatusOffline)
+	manager := NewManager(repos, &recordingNotifier{}, logger.NewDefaultLogger())
+	ctx := context.Background()
+
+	offline := newTestAlert("device-1", models.AlertTypeDeviceOffline)
+	other := newTestAlert("device-1", models.AlertTypeDeviceError)
+	require.NoError(t, manager.Raise(ctx, offline))
+	require.NoError(t, manager.Raise(ctx, other))
+
+	count, err := manager.ResolveActive(ctx, "device-1", models.AlertTypeDeviceOffline)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	stored, err := repos.Alert().GetByID(ctx, offline.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsResolved())
+	stored, err = repos.Alert().GetByID(ctx, other.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.IsResolved())
+
+	count, err = manager.ResolveActive(ctx, "device-1", models.AlertTypeDeviceOffline)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
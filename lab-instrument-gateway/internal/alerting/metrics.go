@@ -0,0 +1,35 @@
+package alerting
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Alerts raised, including silenced ones
+	alertsRaisedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alerts_raised_total",
+			Help: "Total number of alerts raised",
+		},
+		[]string{"type", "severity", "silenced"},
+	)
+
+	// Notification deliveries
+	alertNotificationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alert_notifications_total",
+			Help: "Total number of alert notifications delivered, by result",
+		},
+		[]string{"result"},
+	)
+
+	// Escalations by the level reached
+	alertEscalationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alert_escalations_total",
+			Help: "Total number of alert escalations",
+		},
+		[]string{"level"},
+	)
+)
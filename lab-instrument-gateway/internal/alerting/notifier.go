@@ -0,0 +1,68 @@
+package alerting
+
+import (
+	"context"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// Notification represents an alert delivered to a set of recipients.
+// EscalationLevel is 0 for the initial notification and n for the n-th
+// escalation step.
+type Notification struct {
+	Alert           *models.Alert
+	Recipients      []string
+	EscalationLevel int
+}
+
+// Notifier delivers alert notifications to people or external systems
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// HealthChecker is implemented by notifiers that can tell whether they are
+// able to deliver notifications, e.g. by reaching an external system
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// CheckNotifier reports whether the notifier is able to deliver
+// notifications. Notifiers that do not implement HealthChecker are assumed
+// to be healthy.
+func CheckNotifier(ctx context.Context, notifier Notifier) error {
+	checker, ok := notifier.(HealthChecker)
+	if !ok {
+		return nil
+	}
+	return checker.HealthCheck(ctx)
+}
+
+// LogNotifier is a Notifier that writes notifications to the application log
+type LogNotifier struct {
+	logger *logger.Logger
+}
+
+// NewLogNotifier creates a new log notifier
+func NewLogNotifier(logger *logger.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+// Notify logs the notification
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	alert := notification.Alert
+
+	fields := map[string]interface{}{
+		"alert_id":   alert.ID,
+		"type":       alert.Type,
+		"severity":   alert.Severity,
+		"recipients": notification.Recipients,
+		"escalation": notification.EscalationLevel,
+	}
+	if alert.DeviceID != nil {
+		fields["device_id"] = *alert.DeviceID
+	}
+
+	n.logger.WithFields(fields).Warn(alert.Message)
+	return nil
+}
@@ -0,0 +1,348 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// CalibrationMetadataKey is the measurement metadata key carrying the
+// identifier of the calibration a reading was taken under. A change of value
+// resets the series, as readings before and after a calibration are not
+// comparable.
+const CalibrationMetadataKey = "calibration_id"
+
+// Method identifies the technique that flagged a measurement
+type Method string
+
+const (
+	MethodZScore Method = "zscore"
+	MethodEWMA   Method = "ewma"
+	MethodRule   Method = "rule"
+)
+
+// Default detector settings
+const (
+	DefaultWindowSize      = 50
+	DefaultWarmupSamples   = 30
+	DefaultZScoreThreshold = 4.0
+	DefaultEWMALambda      = 0.2
+	DefaultEWMAWidth       = 3.0
+)
+
+// Config represents the anomaly detector configuration
+type Config struct {
+	// WindowSize is the number of recent points the rolling z-score is
+	// computed over
+	WindowSize int
+
+	// WarmupSamples is the number of points after a reset used to establish
+	// the baseline mean and standard deviation. Nothing is flagged while
+	// warming up.
+	WarmupSamples int
+
+	// ZScoreThreshold is the rolling z-score above which a point is flagged
+	ZScoreThreshold float64
+
+	// EWMALambda is the EWMA smoothing weight in (0, 1] and EWMAWidth the
+	// width of its control limits in standard deviations
+	EWMALambda float64
+	EWMAWidth  float64
+
+	// Rules are the Nelson rules to evaluate, WesternElectricRules if empty
+	Rules []Rule
+}
+
+// SetDefaults fills in unset configuration values
+func (c *Config) SetDefaults() {
+	if c.WindowSize <= 1 {
+		c.WindowSize = DefaultWindowSize
+	}
+	if c.WarmupSamples <= 1 {
+		c.WarmupSamples = DefaultWarmupSamples
+	}
+	if c.ZScoreThreshold <= 0 {
+		c.ZScoreThreshold = DefaultZScoreThreshold
+	}
+	if c.EWMALambda <= 0 || c.EWMALambda > 1 {
+		c.EWMALambda = DefaultEWMALambda
+	}
+	if c.EWMAWidth <= 0 {
+		c.EWMAWidth = DefaultEWMAWidth
+	}
+	if len(c.Rules) == 0 {
+		c.Rules = WesternElectricRules
+	}
+}
+
+// ParseRules parses a comma-separated list of Nelson rule numbers. The
+// names "western_electric" and "all" select the predefined rule sets.
+func ParseRules(value string) ([]Rule, error) {
+	switch strings.TrimSpace(value) {
+	case "":
+		return nil, nil
+	case "western_electric":
+		return WesternElectricRules, nil
+	case "all":
+		return AllRules, nil
+	}
+
+	var rules []Rule
+	for _, part := range strings.Split(value, ",") {
+		var number int
+		if _, err := fmt.Sscanf(strings.TrimSpace(part), "%d", &number); err != nil {
+			return nil, fmt.Errorf("invalid rule %q", part)
+		}
+		rule := Rule(number)
+		if !rule.IsValid() {
+			return nil, fmt.Errorf("invalid rule %d, must be between 1 and 8", number)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SeriesKey identifies a measurement series
+type SeriesKey struct {
+	DeviceID string
+	Type     string
+}
+
+// Finding describes why a measurement was flagged
+type Finding struct {
+	Method Method
+	Rule   Rule    // set for MethodRule
+	Score  float64 // distance from the expected value in standard deviations
+	Detail string
+}
+
+// String returns a short description of the finding
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Method, f.Detail)
+}
+
+// series holds the model state of a single device/type series
+type series struct {
+	calibrationID string
+
+	// Rolling window of recent unflagged values for the z-score
+	window []float64
+	next   int
+
+	// Baseline established after each reset (Welford's algorithm)
+	baselineCount int
+	baselineMean  float64
+	baselineM2    float64
+	baselineSD    float64
+
+	// EWMA statistic and the number of points it has absorbed
+	ewma      float64
+	ewmaSteps int
+
+	// Recent values in baseline standard deviations, for the rules
+	history []float64
+
+	lastSeen time.Time
+}
+
+// Detector flags anomalous measurements using a rolling z-score, an EWMA
+// control chart and Nelson rules. State is kept per device and measurement
+// type, and is safe for concurrent use.
+type Detector struct {
+	config Config
+	series map[SeriesKey]*series
+	mutex  sync.Mutex
+}
+
+// NewDetector creates a new anomaly detector
+func NewDetector(config Config) *Detector {
+	config.SetDefaults()
+
+	return &Detector{
+		config: config,
+		series: make(map[SeriesKey]*series),
+	}
+}
+
+// Observe adds a measurement to its series and returns the reasons it is
+// considered anomalous, if any. Bad quality measurements are ignored so they
+// do not distort the model.
+func (d *Detector) Observe(measurement *models.Measurement) []Finding {
+	if measurement.Quality == models.QualityBad || math.IsNaN(measurement.Value) || math.IsInf(measurement.Value, 0) {
+		return nil
+	}
+
+	key := SeriesKey{DeviceID: measurement.DeviceID, Type: measurement.Type}
+	calibrationID := ""
+	if value, ok := measurement.Metadata[CalibrationMetadataKey]; ok {
+		calibrationID = fmt.Sprintf("%v", value)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	s, exists := d.series[key]
+	if !exists || (calibrationID != "" && calibrationID != s.calibrationID) {
+		s = &series{calibrationID: calibrationID}
+		d.series[key] = s
+	}
+	s.lastSeen = time.Now()
+
+	return d.observe(s, measurement.Value)
+}
+
+// Reset discards the model state of a series, for example after the
+// instrument has been calibrated
+func (d *Detector) Reset(deviceID, measurementType string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.series, SeriesKey{DeviceID: deviceID, Type: measurementType})
+}
+
+// ResetDevice discards the model state of every series of a device and
+// returns how many were reset
+func (d *Detector) ResetDevice(deviceID string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	count := 0
+	for key := range d.series {
+		if key.DeviceID == deviceID {
+			delete(d.series, key)
+			count++
+		}
+	}
+	return count
+}
+
+// Prune discards series that have not been observed since the given time
+// and returns how many were discarded
+func (d *Detector) Prune(idleSince time.Time) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	count := 0
+	for key, s := range d.series {
+		if s.lastSeen.Before(idleSince) {
+			delete(d.series, key)
+			count++
+		}
+	}
+	return count
+}
+
+// SeriesCount returns the number of series being modelled
+func (d *Detector) SeriesCount() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return len(d.series)
+}
+
+// observe runs every check against value and updates the series state.
+// Must be called with the mutex held.
+func (d *Detector) observe(s *series, value float64) []Finding {
+	var findings []Finding
+
+	// Rolling z-score against the recent window, excluding the point itself
+	zscoreFlagged := false
+	if len(s.window) >= min(d.config.WarmupSamples, d.config.WindowSize) {
+		mean, sd := meanAndSD(s.window)
+		if sd > 0 {
+			z := (value - mean) / sd
+			if math.Abs(z) > d.config.ZScoreThreshold {
+				zscoreFlagged = true
+				findings = append(findings, Finding{
+					Method: MethodZScore,
+					Score:  z,
+					Detail: fmt.Sprintf("rolling z-score %.2f exceeds %.2f", z, d.config.ZScoreThreshold),
+				})
+			}
+		}
+	}
+
+	// Outliers are kept out of the window so they do not mask each other
+	if !zscoreFlagged {
+		if len(s.window) < d.config.WindowSize {
+			s.window = append(s.window, value)
+		} else {
+			s.window[s.next] = value
+			s.next = (s.next + 1) % d.config.WindowSize
+		}
+	}
+
+	// The EWMA chart and the rules compare against a baseline that is frozen
+	// once established, so slow drift is not absorbed into it
+	if s.baselineCount < d.config.WarmupSamples {
+		s.baselineCount++
+		delta := value - s.baselineMean
+		s.baselineMean += delta / float64(s.baselineCount)
+		s.baselineM2 += delta * (value - s.baselineMean)
+		if s.baselineCount == d.config.WarmupSamples {
+			s.baselineSD = math.Sqrt(s.baselineM2 / float64(s.baselineCount-1))
+			s.ewma = s.baselineMean
+		}
+		return findings
+	}
+
+	if s.baselineSD == 0 {
+		// A constant baseline has no spread to measure deviations against
+		return findings
+	}
+
+	lambda := d.config.EWMALambda
+	s.ewma = lambda*value + (1-lambda)*s.ewma
+	s.ewmaSteps++
+	limit := d.config.EWMAWidth * s.baselineSD *
+		math.Sqrt(lambda/(2-lambda)*(1-math.Pow(1-lambda, float64(2*s.ewmaSteps))))
+	if deviation := s.ewma - s.baselineMean; math.Abs(deviation) > limit {
+		findings = append(findings, Finding{
+			Method: MethodEWMA,
+			Score:  deviation / s.baselineSD,
+			Detail: fmt.Sprintf("EWMA %.4g outside control limits %.4g ± %.4g", s.ewma, s.baselineMean, limit),
+		})
+	}
+
+	z := (value - s.baselineMean) / s.baselineSD
+	s.history = append(s.history, z)
+	if len(s.history) > maxRuleHistory {
+		s.history = s.history[len(s.history)-maxRuleHistory:]
+	}
+	for _, rule := range d.config.Rules {
+		if rule.Evaluate(s.history) {
+			findings = append(findings, Finding{
+				Method: MethodRule,
+				Rule:   rule,
+				Score:  z,
+				Detail: fmt.Sprintf("Nelson rule %d (%s)", int(rule), rule),
+			})
+		}
+	}
+
+	return findings
+}
+
+// meanAndSD returns the mean and sample standard deviation of values
+func meanAndSD(values []float64) (float64, float64) {
+	if len(values) < 2 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	squares := 0.0
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
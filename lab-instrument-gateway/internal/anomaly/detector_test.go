@@ -0,0 +1,185 @@
+package anomaly
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+func newMeasurement(value float64) *models.Measurement {
+	return &models.Measurement{
+		DeviceID:  "hplc-1",
+		Type:      "absorbance",
+		Value:     value,
+		Quality:   models.QualityGood,
+		Timestamp: time.Now(),
+		Metadata:  map[string]interface{}{},
+	}
+}
+
+// observeNoise feeds n normally distributed points and returns how many
+// were flagged
+func observeNoise(d *Detector, rng *rand.Rand, n int, mean, sd float64) int {
+	flagged := 0
+	for i := 0; i < n; i++ {
+		if len(d.Observe(newMeasurement(mean+rng.NormFloat64()*sd))) > 0 {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+func hasMethod(findings []Finding, method Method) bool {
+	for _, finding := range findings {
+		if finding.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDetector_StableSeriesIsQuiet(t *testing.T) {
+	d := NewDetector(Config{Rules: []Rule{RuleBeyond3Sigma}})
+	rng := rand.New(rand.NewSource(1))
+
+	flagged := observeNoise(d, rng, 500, 10, 0.1)
+
+	// A 3 sigma rule on gaussian noise fires about 0.3% of the time
+	assert.LessOrEqual(t, flagged, 5)
+}
+
+func TestDetector_Spike(t *testing.T) {
+	d := NewDetector(Config{})
+	rng := rand.New(rand.NewSource(2))
+	observeNoise(d, rng, 100, 10, 0.1)
+
+	findings := d.Observe(newMeasurement(12))
+	assert.True(t, hasMethod(findings, MethodZScore))
+	assert.True(t, hasMethod(findings, MethodRule))
+
+	// The spike is kept out of the rolling window, so a second one is caught too
+	findings = d.Observe(newMeasurement(12))
+	assert.True(t, hasMethod(findings, MethodZScore))
+}
+
+func TestDetector_SlowDrift(t *testing.T) {
+	d := NewDetector(Config{})
+	rng := rand.New(rand.NewSource(3))
+	observeNoise(d, rng, 50, 10, 0.1)
+
+	// Drift of a fifth of a standard deviation every ten points stays within
+	// the rolling z-score but not the EWMA chart against the frozen baseline
+	var ewmaAt = -1
+	for i := 0; i < 200 && ewmaAt < 0; i++ {
+		value := 10 + float64(i/10)*0.02 + rng.NormFloat64()*0.1
+		findings := d.Observe(newMeasurement(value))
+		assert.False(t, hasMethod(findings, MethodZScore), "drift should not trip the z-score")
+		if hasMethod(findings, MethodEWMA) {
+			ewmaAt = i
+		}
+	}
+
+	require.GreaterOrEqual(t, ewmaAt, 0, "EWMA chart should detect the drift")
+}
+
+func TestDetector_WarmupAndConstantSeries(t *testing.T) {
+	d := NewDetector(Config{WarmupSamples: 10})
+
+	for i := 0; i < 10; i++ {
+		assert.Empty(t, d.Observe(newMeasurement(float64(i*100))), "nothing is flagged while warming up")
+	}
+
+	constant := NewDetector(Config{WarmupSamples: 10})
+	for i := 0; i < 20; i++ {
+		assert.Empty(t, constant.Observe(newMeasurement(5)))
+	}
+}
+
+func TestDetector_BadQualityIgnored(t *testing.T) {
+	d := NewDetector(Config{})
+	measurement := newMeasurement(1e9)
+	measurement.Quality = models.QualityBad
+
+	assert.Empty(t, d.Observe(measurement))
+	assert.Equal(t, 0, d.SeriesCount())
+}
+
+func TestDetector_CalibrationReset(t *testing.T) {
+	d := NewDetector(Config{})
+	rng := rand.New(rand.NewSource(4))
+	observeNoise(d, rng, 100, 10, 0.1)
+
+	// After recalibration the instrument reads on a new level; the series
+	// starts over instead of flagging every point
+	for i := 0; i < 50; i++ {
+		measurement := newMeasurement(20 + rng.NormFloat64()*0.1)
+		measurement.Metadata[CalibrationMetadataKey] = "cal-2"
+		assert.Empty(t, d.Observe(measurement))
+	}
+
+	other := newMeasurement(1)
+	other.Type = "pressure"
+	d.Observe(other)
+	assert.Equal(t, 2, d.SeriesCount())
+
+	assert.Equal(t, 2, d.ResetDevice("hplc-1"))
+	assert.Equal(t, 0, d.SeriesCount())
+}
+
+func TestDetector_Prune(t *testing.T) {
+	d := NewDetector(Config{})
+	d.Observe(newMeasurement(1))
+
+	assert.Equal(t, 0, d.Prune(time.Now().Add(-time.Minute)))
+	assert.Equal(t, 1, d.Prune(time.Now().Add(time.Minute)))
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("1, 5,6")
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{RuleBeyond3Sigma, RuleTwoOfThreeBeyond2Sigma, RuleFourOfFiveBeyond1Sigma}, rules)
+
+	rules, err = ParseRules("all")
+	require.NoError(t, err)
+	assert.Len(t, rules, 8)
+
+	_, err = ParseRules("9")
+	assert.Error(t, err)
+	_, err = ParseRules("one")
+	assert.Error(t, err)
+}
+
+func TestRule_Evaluate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		zs   []float64
+		want bool
+	}{
+		{"beyond 3 sigma", RuleBeyond3Sigma, []float64{0, -3.5}, true},
+		{"within 3 sigma", RuleBeyond3Sigma, []float64{0, 2.9}, false},
+		{"nine above", RuleNineSameSide, []float64{0.1, 0.2, 0.1, 0.3, 0.5, 0.1, 0.2, 0.4, 0.1}, true},
+		{"eight above", RuleNineSameSide, []float64{-0.1, 0.2, 0.1, 0.3, 0.5, 0.1, 0.2, 0.4, 0.1}, false},
+		{"six increasing", RuleSixTrending, []float64{-1, -0.5, 0, 0.2, 0.3, 0.9}, true},
+		{"six with a plateau", RuleSixTrending, []float64{-1, -0.5, 0, 0, 0.3, 0.9}, false},
+		{"fourteen alternating", RuleFourteenAlternating, []float64{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1}, true},
+		{"two of three beyond 2 sigma", RuleTwoOfThreeBeyond2Sigma, []float64{2.1, 0, 2.5}, true},
+		{"two of three on opposite sides", RuleTwoOfThreeBeyond2Sigma, []float64{-2.1, 0, 2.5}, false},
+		{"latest point not beyond 2 sigma", RuleTwoOfThreeBeyond2Sigma, []float64{2.1, 2.5, 0}, false},
+		{"four of five beyond 1 sigma", RuleFourOfFiveBeyond1Sigma, []float64{-1.5, -1.2, 0, -1.1, -1.3}, true},
+		{"fifteen within 1 sigma", RuleFifteenWithin1Sigma, make([]float64, 15), true},
+		{"eight outside 1 sigma", RuleEightOutside1Sigma, []float64{1.5, -1.5, 1.2, -1.2, 1.1, -1.1, 1.3, -1.3}, true},
+		{"eight outside on one side", RuleEightOutside1Sigma, []float64{1.5, 1.5, 1.2, 1.2, 1.1, 1.1, 1.3, 1.3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Evaluate(tt.zs))
+		})
+	}
+}
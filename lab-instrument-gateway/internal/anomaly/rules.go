@@ -0,0 +1,215 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rule identifies a Nelson control chart rule. Rules are evaluated against
+// the baseline mean and standard deviation of a series.
+type Rule int
+
+const (
+	// RuleBeyond3Sigma: one point more than 3 standard deviations from the mean
+	RuleBeyond3Sigma Rule = 1
+	// RuleNineSameSide: nine points in a row on the same side of the mean
+	RuleNineSameSide Rule = 2
+	// RuleSixTrending: six points in a row steadily increasing or decreasing
+	RuleSixTrending Rule = 3
+	// RuleFourteenAlternating: fourteen points in a row alternating up and down
+	RuleFourteenAlternating Rule = 4
+	// RuleTwoOfThreeBeyond2Sigma: two of three points more than 2 standard
+	// deviations from the mean on the same side
+	RuleTwoOfThreeBeyond2Sigma Rule = 5
+	// RuleFourOfFiveBeyond1Sigma: four of five points more than 1 standard
+	// deviation from the mean on the same side
+	RuleFourOfFiveBeyond1Sigma Rule = 6
+	// RuleFifteenWithin1Sigma: fifteen points in a row within 1 standard
+	// deviation of the mean
+	RuleFifteenWithin1Sigma Rule = 7
+	// RuleEightOutside1Sigma: eight points in a row more than 1 standard
+	// deviation from the mean, on both sides
+	RuleEightOutside1Sigma Rule = 8
+)
+
+// maxRuleHistory is the longest run of points any rule looks at
+const maxRuleHistory = 15
+
+// WesternElectricRules are the Nelson rules equivalent to the Western
+// Electric zone rules, which is the default rule set
+var WesternElectricRules = []Rule{
+	RuleBeyond3Sigma,
+	RuleTwoOfThreeBeyond2Sigma,
+	RuleFourOfFiveBeyond1Sigma,
+	RuleNineSameSide,
+}
+
+// AllRules are all eight Nelson rules
+var AllRules = []Rule{
+	RuleBeyond3Sigma,
+	RuleNineSameSide,
+	RuleSixTrending,
+	RuleFourteenAlternating,
+	RuleTwoOfThreeBeyond2Sigma,
+	RuleFourOfFiveBeyond1Sigma,
+	RuleFifteenWithin1Sigma,
+	RuleEightOutside1Sigma,
+}
+
+// IsValid returns true if the rule is one of the eight Nelson rules
+func (r Rule) IsValid() bool {
+	return r >= RuleBeyond3Sigma && r <= RuleEightOutside1Sigma
+}
+
+// String returns a short description of the rule
+func (r Rule) String() string {
+	switch r {
+	case RuleBeyond3Sigma:
+		return "1 point beyond 3 sigma"
+	case RuleNineSameSide:
+		return "9 points on the same side of the mean"
+	case RuleSixTrending:
+		return "6 points trending"
+	case RuleFourteenAlternating:
+		return "14 points alternating"
+	case RuleTwoOfThreeBeyond2Sigma:
+		return "2 of 3 points beyond 2 sigma"
+	case RuleFourOfFiveBeyond1Sigma:
+		return "4 of 5 points beyond 1 sigma"
+	case RuleFifteenWithin1Sigma:
+		return "15 points within 1 sigma"
+	case RuleEightOutside1Sigma:
+		return "8 points outside 1 sigma"
+	default:
+		return fmt.Sprintf("rule %d", int(r))
+	}
+}
+
+// Evaluate reports whether the rule is violated by the most recent point of
+// zs, the series history expressed in standard deviations from the baseline
+// mean, oldest first. Rules that look at a subset of a run only fire when
+// the most recent point is part of that subset, so a point is never flagged
+// for the behaviour of its predecessors alone.
+func (r Rule) Evaluate(zs []float64) bool {
+	n := len(zs)
+	if n == 0 {
+		return false
+	}
+	last := zs[n-1]
+
+	switch r {
+	case RuleBeyond3Sigma:
+		return math.Abs(last) > 3
+
+	case RuleNineSameSide:
+		return sameSide(tail(zs, 9), 9)
+
+	case RuleSixTrending:
+		run := tail(zs, 6)
+		if len(run) < 6 {
+			return false
+		}
+		increasing, decreasing := true, true
+		for i := 1; i < len(run); i++ {
+			if run[i] <= run[i-1] {
+				increasing = false
+			}
+			if run[i] >= run[i-1] {
+				decreasing = false
+			}
+		}
+		return increasing || decreasing
+
+	case RuleFourteenAlternating:
+		run := tail(zs, 14)
+		if len(run) < 14 {
+			return false
+		}
+		for i := 2; i < len(run); i++ {
+			previous := run[i-1] - run[i-2]
+			current := run[i] - run[i-1]
+			if previous*current >= 0 {
+				return false
+			}
+		}
+		return true
+
+	case RuleTwoOfThreeBeyond2Sigma:
+		return countBeyond(tail(zs, 3), last, 2) >= 2 && len(zs) >= 3
+
+	case RuleFourOfFiveBeyond1Sigma:
+		return countBeyond(tail(zs, 5), last, 1) >= 4 && len(zs) >= 5
+
+	case RuleFifteenWithin1Sigma:
+		run := tail(zs, 15)
+		if len(run) < 15 {
+			return false
+		}
+		for _, z := range run {
+			if math.Abs(z) >= 1 {
+				return false
+			}
+		}
+		return true
+
+	case RuleEightOutside1Sigma:
+		run := tail(zs, 8)
+		if len(run) < 8 {
+			return false
+		}
+		above, below := false, false
+		for _, z := range run {
+			switch {
+			case z > 1:
+				above = true
+			case z < -1:
+				below = true
+			default:
+				return false
+			}
+		}
+		return above && below
+	}
+
+	return false
+}
+
+// tail returns the last n values of zs, or all of them if there are fewer
+func tail(zs []float64, n int) []float64 {
+	if len(zs) <= n {
+		return zs
+	}
+	return zs[len(zs)-n:]
+}
+
+// sameSide reports whether run holds n points all strictly on one side of zero
+func sameSide(run []float64, n int) bool {
+	if len(run) < n {
+		return false
+	}
+	above, below := true, true
+	for _, z := range run {
+		if z <= 0 {
+			above = false
+		}
+		if z >= 0 {
+			below = false
+		}
+	}
+	return above || below
+}
+
+// countBeyond counts the points of run beyond limit on the same side as
+// last, returning 0 if last itself is not beyond the limit
+func countBeyond(run []float64, last, limit float64) int {
+	if math.Abs(last) <= limit {
+		return 0
+	}
+	count := 0
+	for _, z := range run {
+		if (last > 0 && z > limit) || (last < 0 && z < -limit) {
+			count++
+		}
+	}
+	return count
+}
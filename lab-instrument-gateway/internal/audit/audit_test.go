@@ -0,0 +1,201 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// memoryAuditRepository chains entries like the database repository and
+// stores them as the database would return them, through JSON
+type memoryAuditRepository struct {
+	repository.AuditRepository
+	entries []*models.AuditEntry
+}
+
+func (r *memoryAuditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	entry.SetDefaults()
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	entry.Sequence = int64(len(r.entries)) + 1
+	if len(r.entries) > 0 {
+		entry.PreviousHash = r.entries[len(r.entries)-1].Hash
+	}
+	hash, err := entry.ComputeHash()
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	stored := &models.AuditEntry{}
+	if err := json.Unmarshal(data, stored); err != nil {
+		return err
+	}
+	stored.OccurredAt = stored.OccurredAt.In(time.FixedZone("CEST", 2*60*60))
+	r.entries = append(r.entries, stored)
+	return nil
+}
+
+func (r *memoryAuditRepository) ListAfter(ctx context.Context, sequence int64, limit int) ([]*models.AuditEntry, error) {
+	var entries []*models.AuditEntry
+	for _, entry := range r.entries {
+		if entry.Sequence > sequence && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func recordEntries(t *testing.T, n int) *memoryAuditRepository {
+	repo := &memoryAuditRepository{}
+	recorder := NewRecorder(repo, logger.NewDefaultLogger())
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "ada", Method: auth.MethodJWT})
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, recorder.Record(ctx, &models.AuditEntry{
+			Action:     pb.LabInstrumentGateway_RegisterDevice_FullMethodName,
+			ResourceID: "hplc-01",
+			Outcome:    models.AuditOutcomeSuccess,
+			Before:     map[string]interface{}{"version": "1.0", "count": i},
+			After:      map[string]interface{}{"version": "1.1", "metadata": map[string]interface{}{}},
+		}))
+	}
+	return repo
+}
+
+func TestRecorder_ChainsEntries(t *testing.T) {
+	repo := recordEntries(t, 3)
+
+	require.Len(t, repo.entries, 3)
+	assert.Equal(t, "ada", repo.entries[0].Actor)
+	assert.Equal(t, "jwt", repo.entries[0].AuthMethod)
+	assert.Empty(t, repo.entries[0].PreviousHash)
+	assert.Equal(t, repo.entries[0].Hash, repo.entries[1].PreviousHash)
+	assert.Equal(t, repo.entries[1].Hash, repo.entries[2].PreviousHash)
+}
+
+func TestVerify(t *testing.T) {
+	repo := recordEntries(t, 7)
+
+	result, err := Verify(context.Background(), repo, 3)
+	require.NoError(t, err, "entries read back from storage must verify")
+	assert.Equal(t, int64(7), result.Entries)
+	assert.Equal(t, int64(7), result.LastSequence)
+	assert.Equal(t, repo.entries[6].Hash, result.LastHash)
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(entries []*models.AuditEntry) []*models.AuditEntry
+		broken int64
+	}{
+		{"changed value", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			entries[3].After["version"] = "9.9"
+			return entries
+		}, 4},
+		{"changed actor", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			entries[1].Actor = "mallory"
+			return entries
+		}, 2},
+		{"rehashed entry", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			entries[2].Reason = "routine"
+			entries[2].Hash, _ = entries[2].ComputeHash()
+			return entries
+		}, 4},
+		{"removed entry", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			return append(entries[:2], entries[3:]...)
+		}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := recordEntries(t, 5)
+			repo.entries = tt.tamper(repo.entries)
+
+			_, err := Verify(context.Background(), repo, 2)
+			var chainErr *ChainError
+			require.True(t, errors.As(err, &chainErr), "got %v", err)
+			assert.Equal(t, tt.broken, chainErr.Sequence)
+		})
+	}
+}
+
+func TestRecordChange(t *testing.T) {
+	ctx, change := WithChange(context.Background(), models.AuditEntry{})
+	device := &models.Device{ID: "hplc-01", Version: "1.0"}
+	before := Snapshot(device)
+	device.Version = "1.1"
+
+	RecordChange(ctx, device.ID, before, device)
+
+	resourceID, recordedBefore, after, recorded := change.Values()
+	assert.True(t, recorded)
+	assert.Equal(t, "hplc-01", resourceID)
+	assert.Equal(t, "1.0", recordedBefore["version"])
+	assert.Equal(t, "1.1", after["version"])
+
+	// Without an audited action there is nothing to record into
+	RecordChange(context.Background(), "hplc-01", nil, device)
+}
+
+func TestCommitChange(t *testing.T) {
+	repo := &memoryAuditRepository{}
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "ada", Method: auth.MethodJWT})
+	ctx, change := WithChange(ctx, models.AuditEntry{
+		Action:       pb.LabInstrumentGateway_RegisterDevice_FullMethodName,
+		ResourceType: "device",
+		Reason:       "column replaced",
+	})
+
+	require.NoError(t, CommitChange(ctx, repo, "hplc-01", nil, &models.Device{ID: "hplc-01", Version: "1.1"}))
+	assert.True(t, change.Committed())
+
+	require.Len(t, repo.entries, 1)
+	entry := repo.entries[0]
+	assert.Equal(t, "ada", entry.Actor)
+	assert.Equal(t, "hplc-01", entry.ResourceID)
+	assert.Equal(t, "column replaced", entry.Reason)
+	assert.Equal(t, models.AuditOutcomeSuccess, entry.Outcome)
+	assert.Equal(t, "1.1", entry.After["version"])
+
+	// Without an audited action there is nothing to commit
+	require.NoError(t, CommitChange(context.Background(), repo, "hplc-01", nil, nil))
+	assert.Len(t, repo.entries, 1)
+}
+
+func TestSnapshot_OmitsCredentialHashes(t *testing.T) {
+	snapshot := Snapshot(&models.APIKey{ID: "key-1", KeyHash: "secret-hash"})
+	assert.Equal(t, "key-1", snapshot["id"])
+	assert.NotContains(t, snapshot, "key_hash")
+	assert.Nil(t, Snapshot(nil))
+}
+
+func TestRequestSnapshot_RedactsSecrets(t *testing.T) {
+	snapshot := RequestSnapshot(&pb.EnrollDeviceRequest{
+		EnrollmentToken: "let-me-in",
+		DeviceId:        "hplc-01",
+		Csr:             []byte("csr"),
+	})
+
+	assert.Equal(t, "hplc-01", snapshot["device_id"])
+	assert.Equal(t, "[REDACTED]", snapshot["enrollment_token"])
+	assert.Equal(t, "[REDACTED]", snapshot["csr"])
+}
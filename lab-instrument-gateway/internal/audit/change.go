@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// Change describes what an audited action changed. Handlers fill it in
+// through RecordChange or CommitChange; actions that record no change are
+// audited with their request as the after value.
+type Change struct {
+	mu         sync.Mutex
+	entry      models.AuditEntry
+	resourceID string
+	before     map[string]interface{}
+	after      map[string]interface{}
+	recorded   bool
+	committed  bool
+}
+
+type changeKey struct{}
+
+// WithChange returns a context in which handlers can record a change. The
+// entry describes the action; the entry of a committed change is a copy of
+// it.
+func WithChange(ctx context.Context, entry models.AuditEntry) (context.Context, *Change) {
+	change := &Change{entry: entry}
+	return context.WithValue(ctx, changeKey{}, change), change
+}
+
+// RecordChange records the resource an audited action changed and its state
+// before and after the change. Before is nil for creations. It does nothing
+// if the action is not being audited.
+func RecordChange(ctx context.Context, resourceID string, before, after interface{}) {
+	change, ok := ctx.Value(changeKey{}).(*Change)
+	if !ok {
+		return
+	}
+
+	change.mu.Lock()
+	defer change.mu.Unlock()
+
+	change.resourceID = resourceID
+	change.before = Snapshot(before)
+	change.after = Snapshot(after)
+	change.recorded = true
+}
+
+// CommitChange records a change like RecordChange and appends its entry to
+// repo, so that a handler writing the change in a transaction writes its
+// entry in the same one: the change is stored only if its entry is. The
+// entry of a committed change is not written again after the action
+// succeeds. It does nothing if the action is not being audited.
+func CommitChange(ctx context.Context, repo repository.AuditRepository, resourceID string, before, after interface{}) error {
+	change, ok := ctx.Value(changeKey{}).(*Change)
+	if !ok {
+		return nil
+	}
+
+	RecordChange(ctx, resourceID, before, after)
+
+	change.mu.Lock()
+	defer change.mu.Unlock()
+
+	entry := change.entry
+	entry.ResourceID = resourceID
+	entry.Outcome = models.AuditOutcomeSuccess
+	entry.Before = change.before
+	entry.After = change.after
+	setActor(ctx, &entry)
+
+	if err := repo.Append(ctx, &entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	change.committed = true
+	return nil
+}
+
+// Committed reports whether the entry of the change was written by
+// CommitChange
+func (c *Change) Committed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.committed
+}
+
+// Values returns the recorded change, and false if none was recorded
+func (c *Change) Values() (string, map[string]interface{}, map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resourceID, c.before, c.after, c.recorded
+}
+
+// Snapshot returns the JSON representation of a value as a map, so that
+// later changes to the value do not alter the record. Fields excluded from
+// JSON, such as credential hashes, are left out.
+func Snapshot(value interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return map[string]interface{}{"error": "value could not be recorded: " + err.Error()}
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return map[string]interface{}{"value": string(data)}
+	}
+
+	return snapshot
+}
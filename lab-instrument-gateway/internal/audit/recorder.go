@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// ReasonHeader is the request metadata key carrying the reason for an
+// audited action
+const ReasonHeader = "x-audit-reason"
+
+// redactedFields are request fields holding secrets, which are never written
+// to the audit trail
+var redactedFields = map[string]bool{
+	"enrollment_token": true,
+	"token":            true,
+	"api_key":          true,
+	"password":         true,
+	"csr":              true,
+	"credential":       true,
+}
+
+// Recorder appends entries to the audit trail
+type Recorder struct {
+	repo   repository.AuditRepository
+	logger *logger.Logger
+}
+
+// NewRecorder creates a new audit recorder
+func NewRecorder(repo repository.AuditRepository, logger *logger.Logger) *Recorder {
+	return &Recorder{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record appends an entry to the audit trail. The actor defaults to the
+// caller identity in the context.
+func (r *Recorder) Record(ctx context.Context, entry *models.AuditEntry) error {
+	setActor(ctx, entry)
+
+	if err := r.repo.Append(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"sequence":    entry.Sequence,
+		"actor":       entry.Actor,
+		"action":      entry.Action,
+		"resource_id": entry.ResourceID,
+		"outcome":     entry.Outcome,
+	}).Debug("Audit entry recorded")
+
+	return nil
+}
+
+// setActor sets the actor of an entry that names none to the caller
+// identity in the context
+func setActor(ctx context.Context, entry *models.AuditEntry) {
+	if entry.Actor != "" {
+		return
+	}
+
+	entry.Actor = "anonymous"
+	if identity, ok := auth.FromContext(ctx); ok {
+		entry.Actor = identity.Subject
+		entry.AuthMethod = string(identity.Method)
+	}
+}
+
+// RequestSnapshot returns the fields of a request as a map, with secrets
+// removed
+func RequestSnapshot(req proto.Message) map[string]interface{} {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(req)
+	if err != nil {
+		return map[string]interface{}{"error": "request could not be recorded: " + err.Error()}
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return map[string]interface{}{"error": "request could not be recorded: " + err.Error()}
+	}
+
+	redact(snapshot)
+	return snapshot
+}
+
+// redact removes secret fields from a request snapshot, at any depth
+func redact(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactedFields[key] {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			redact(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			redact(item)
+		}
+	}
+}
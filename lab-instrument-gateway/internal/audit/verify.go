@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// DefaultVerifyBatchSize is the number of entries read at a time when
+// verifying the audit chain
+const DefaultVerifyBatchSize = 1000
+
+// ChainError reports the first audit entry at which the hash chain is broken
+type ChainError struct {
+	Sequence int64
+	Reason   string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit chain broken at sequence %d: %s", e.Sequence, e.Reason)
+}
+
+// VerifyResult summarizes a verified audit chain
+type VerifyResult struct {
+	Entries      int64
+	LastSequence int64
+	LastHash     string
+}
+
+// Verify walks the whole audit trail in sequence order and checks that every
+// entry's hash matches its contents and links to the previous entry. It
+// returns a *ChainError for the first entry that fails.
+func Verify(ctx context.Context, repo repository.AuditRepository, batchSize int) (*VerifyResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultVerifyBatchSize
+	}
+
+	result := &VerifyResult{}
+	for {
+		entries, err := repo.ListAfter(ctx, result.LastSequence, batchSize)
+		if err != nil {
+			return result, err
+		}
+
+		for _, entry := range entries {
+			if entry.Sequence != result.LastSequence+1 {
+				return result, &ChainError{
+					Sequence: entry.Sequence,
+					Reason:   fmt.Sprintf("expected sequence %d", result.LastSequence+1),
+				}
+			}
+
+			if entry.PreviousHash != result.LastHash {
+				return result, &ChainError{Sequence: entry.Sequence, Reason: "previous hash does not match the preceding entry"}
+			}
+
+			hash, err := entry.ComputeHash()
+			if err != nil {
+				return result, &ChainError{Sequence: entry.Sequence, Reason: err.Error()}
+			}
+			if hash != entry.Hash {
+				return result, &ChainError{Sequence: entry.Sequence, Reason: "hash does not match the entry contents"}
+			}
+
+			result.Entries++
+			result.LastSequence = entry.Sequence
+			result.LastHash = entry.Hash
+		}
+
+		if len(entries) < batchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// APIKeyHeader is the metadata key carrying API keys
+const APIKeyHeader = "x-api-key"
+
+// apiKeyScheme is the fixed leading part of every API key, which makes keys
+// easy to recognise in logs and secret scanners
+const apiKeyScheme = "lgw"
+
+// lastUsedResolution bounds how often a key's last use is written, so that
+// busy integrations do not cause a database write per request
+const lastUsedResolution = time.Minute
+
+// GenerateAPIKey creates a new random API key. It returns the key, which is
+// shown to its owner once, together with the prefix and hash that are stored.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	prefixBytes := make([]byte, 8)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyScheme + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	return key, prefix, HashSecret(key), nil
+}
+
+// HashSecret returns the hex SHA-256 digest under which a high-entropy secret
+// such as an API key or enrollment token is stored
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseAPIKey returns the prefix of a well-formed API key
+func parseAPIKey(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// APIKeyVerifier validates API keys against the stored key hashes and tracks
+// when each key was last used
+type APIKeyVerifier struct {
+	keys   repository.APIKeyRepository
+	logger *logger.Logger
+}
+
+// NewAPIKeyVerifier creates a new API key verifier
+func NewAPIKeyVerifier(keys repository.APIKeyRepository, logger *logger.Logger) *APIKeyVerifier {
+	return &APIKeyVerifier{keys: keys, logger: logger}
+}
+
+// Verify validates an API key and returns the identity it belongs to
+func (v *APIKeyVerifier) Verify(ctx context.Context, key string) (*Identity, error) {
+	prefix, ok := parseAPIKey(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid API key: malformed")
+	}
+
+	stored, err := v.keys.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("invalid API key: unknown key")
+		}
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashSecret(key)), []byte(stored.KeyHash)) != 1 {
+		return nil, fmt.Errorf("invalid API key: hash mismatch")
+	}
+
+	now := time.Now()
+	if !stored.IsActive(now) {
+		return nil, fmt.Errorf("invalid API key: revoked or expired")
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= lastUsedResolution {
+		// A failed update must not reject an otherwise valid key
+		if err := v.keys.TouchLastUsed(ctx, stored.ID, now); err != nil {
+			v.logger.WithError(err).WithField("key_id", stored.ID).Warn("Failed to record API key use")
+		}
+	}
+
+	identity := &Identity{
+		Subject:      stored.Subject,
+		Method:       MethodAPIKey,
+		Roles:        stored.Roles,
+		DeviceGroups: stored.DeviceGroups,
+	}
+	if stored.DeviceID != nil {
+		identity.DeviceIDs = []string{*stored.DeviceID}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// fakeAPIKeyRepo is an in-memory APIKeyRepository
+type fakeAPIKeyRepo struct {
+	repository.APIKeyRepository
+	keys    map[string]*models.APIKey
+	touches int
+}
+
+func (f *fakeAPIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	key, ok := f.keys[prefix]
+	if !ok {
+		return nil, fmt.Errorf("%w: API key %s", repository.ErrNotFound, prefix)
+	}
+	return key, nil
+}
+
+func (f *fakeAPIKeyRepo) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	f.touches++
+	for _, key := range f.keys {
+		if key.ID == id {
+			key.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+func (f *fakeAPIKeyRepo) add(t *testing.T, subject string, deviceID *string, roles ...string) (string, *models.APIKey) {
+	key, prefix, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+	stored := &models.APIKey{Prefix: prefix, KeyHash: hash, Name: "test", Subject: subject, Roles: roles, DeviceID: deviceID, CreatedBy: "admin"}
+	stored.SetDefaults()
+	f.keys[prefix] = stored
+	return key, stored
+}
+
+func TestAPIKeyVerifier(t *testing.T) {
+	repo := &fakeAPIKeyRepo{keys: map[string]*models.APIKey{}}
+	verifier := NewAPIKeyVerifier(repo, logger.NewDefaultLogger())
+	ctx := context.Background()
+
+	deviceID := "hplc-01"
+	deviceKey, _ := repo.add(t, "hplc-01", &deviceID)
+	serviceKey, service := repo.add(t, "lims-service", nil, "viewer")
+	service.DeviceGroups = []string{"lab-a"}
+
+	identity, err := verifier.Verify(ctx, deviceKey)
+	require.NoError(t, err)
+	assert.Equal(t, MethodAPIKey, identity.Method)
+	assert.Equal(t, []string{"hplc-01"}, identity.DeviceIDs)
+
+	identity, err = verifier.Verify(ctx, serviceKey)
+	require.NoError(t, err)
+	assert.Equal(t, "lims-service", identity.Subject)
+	assert.Equal(t, []string{"viewer"}, identity.Roles)
+	assert.Equal(t, []string{"lab-a"}, identity.DeviceGroups)
+	assert.False(t, identity.IsDevice())
+
+	// Last use is recorded at most once per resolution interval
+	require.NotNil(t, service.LastUsedAt)
+	_, err = verifier.Verify(ctx, serviceKey)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.touches)
+
+	// A key with a known prefix but a different secret
+	prefix, _ := parseAPIKey(serviceKey)
+	_, err = verifier.Verify(ctx, apiKeyScheme+"_"+prefix+"_forged")
+	assert.Error(t, err)
+
+	_, err = verifier.Verify(ctx, "not-an-api-key")
+	assert.Error(t, err)
+
+	revokedKey, revoked := repo.add(t, "lims-service", nil)
+	revokedAt := time.Now().Add(-time.Minute)
+	revoked.RevokedAt = &revokedAt
+	_, err = verifier.Verify(ctx, revokedKey)
+	assert.Error(t, err)
+
+	expiredKey, expired := repo.add(t, "lims-service", nil)
+	expiresAt := time.Now().Add(-time.Minute)
+	expired.ExpiresAt = &expiresAt
+	_, err = verifier.Verify(ctx, expiredKey)
+	assert.Error(t, err)
+
+	// The authenticator accepts keys from the x-api-key header
+	authenticator := NewAuthenticator(nil, verifier, false)
+	identity, err = authenticator.Authenticate(metadata.NewIncomingContext(ctx, metadata.Pairs(APIKeyHeader, serviceKey)))
+	require.NoError(t, err)
+	assert.Equal(t, "lims-service", identity.Subject)
+}
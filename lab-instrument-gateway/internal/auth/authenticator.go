@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yourorg/lab-gateway/internal/tlsutil"
+)
+
+// ErrUnauthenticated is returned when a request carries no usable credentials
+var ErrUnauthenticated = errors.New("no credentials provided")
+
+// AuthorizationHeader is the metadata key carrying bearer tokens
+const AuthorizationHeader = "authorization"
+
+// Authenticator establishes the identity of a caller from its request
+// credentials: a bearer token or API key if one is sent, otherwise a verified
+// client certificate
+type Authenticator struct {
+	jwt          *JWTVerifier
+	apiKeys      *APIKeyVerifier
+	certificates bool
+}
+
+// NewAuthenticator creates a new authenticator. jwt and apiKeys may be nil if
+// bearer tokens or API keys are not accepted; trustCertificates accepts
+// verified client certificates as device identities.
+func NewAuthenticator(jwt *JWTVerifier, apiKeys *APIKeyVerifier, trustCertificates bool) *Authenticator {
+	return &Authenticator{
+		jwt:          jwt,
+		apiKeys:      apiKeys,
+		certificates: trustCertificates,
+	}
+}
+
+// Authenticate returns the caller identity for the request context. A
+// presented token or key that fails verification is an error even if the
+// caller also has a client certificate.
+func (a *Authenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	if token, ok := bearerToken(ctx); ok {
+		if a.jwt == nil {
+			return nil, fmt.Errorf("bearer tokens are not accepted")
+		}
+		return a.jwt.Verify(token)
+	}
+
+	if key, ok := apiKey(ctx); ok {
+		if a.apiKeys == nil {
+			return nil, fmt.Errorf("API keys are not accepted")
+		}
+		return a.apiKeys.Verify(ctx, key)
+	}
+
+	if a.certificates {
+		if cert := tlsutil.PeerCertificate(ctx); cert != nil {
+			return &Identity{
+				Subject:   cert.Subject.CommonName,
+				Method:    MethodCertificate,
+				DeviceIDs: tlsutil.DeviceIDs(cert),
+			}, nil
+		}
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+// Reauthenticate verifies a bearer token presented to confirm the identity
+// of a person at a critical step, such as signing a command. Only tokens
+// issued within maxAge are accepted; API keys and certificates identify
+// machines rather than people and cannot be used.
+func (a *Authenticator) Reauthenticate(token string, maxAge time.Duration) (*Identity, error) {
+	if a.jwt == nil {
+		return nil, fmt.Errorf("bearer tokens are not accepted")
+	}
+	return a.jwt.VerifyRecent(strings.TrimSpace(token), maxAge)
+}
+
+// apiKey extracts the API key from the request metadata
+func apiKey(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, value := range md.Get(APIKeyHeader) {
+		if key := strings.TrimSpace(value); key != "" {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// bearerToken extracts the bearer token from the request metadata
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, value := range md.Get(AuthorizationHeader) {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+			return strings.TrimSpace(token), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Method identifies how a caller was authenticated
+type Method string
+
+const (
+	MethodJWT         Method = "jwt"
+	MethodCertificate Method = "certificate"
+	MethodAPIKey      Method = "api_key"
+)
+
+// Identity describes an authenticated caller
+type Identity struct {
+	// Subject is the stable identifier of the caller: the token subject, the
+	// certificate common name or the owner of the API key
+	Subject string `json:"subject"`
+
+	// Method is how the caller proved its identity
+	Method Method `json:"method"`
+
+	// Roles and Scopes are the authorizations asserted by the credential
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+
+	// DeviceGroups limits the credential's roles to these device groups;
+	// the roles apply to all devices when it is empty
+	DeviceGroups []string `json:"device_groups,omitempty"`
+
+	// DeviceIDs are the devices a caller authenticated by a device
+	// certificate or device API key may act as
+	DeviceIDs []string `json:"device_ids,omitempty"`
+
+	// TokenID identifies the bearer token the caller presented by its jti
+	// claim; it is empty for tokens without one
+	TokenID string `json:"-"`
+}
+
+// HasRole returns true if the identity holds the role
+func (i *Identity) HasRole(role string) bool {
+	return slices.Contains(i.Roles, role)
+}
+
+// HasScope returns true if the identity was granted the scope
+func (i *Identity) HasScope(scope string) bool {
+	return slices.Contains(i.Scopes, scope)
+}
+
+// IsDevice returns true if the identity authenticated as a device
+func (i *Identity) IsDevice() bool {
+	return len(i.DeviceIDs) > 0
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the caller identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the caller identity stored in the context
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Subject returns the subject of the caller identity in the context, or ""
+// for unauthenticated requests
+func Subject(ctx context.Context) string {
+	if identity, ok := FromContext(ctx); ok {
+		return identity.Subject
+	}
+	return ""
+}
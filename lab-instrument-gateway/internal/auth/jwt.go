@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking token times
+const DefaultLeeway = 30 * time.Second
+
+// minHMACSecretLength is the shortest accepted HS256 secret, in bytes
+const minHMACSecretLength = 32
+
+// JWTConfig represents the JWT verification configuration. HS256 tokens are
+// accepted when Secret is set, RS256 tokens when JWKSFile is set.
+type JWTConfig struct {
+	Secret   string
+	JWKSFile string
+
+	// Issuer and Audience, if set, must match the token's iss and aud claims
+	Issuer   string
+	Audience string
+
+	Leeway time.Duration
+}
+
+// Claims are the JWT claims understood by the gateway
+type Claims struct {
+	jwt.RegisteredClaims
+
+	// Roles granted to the caller
+	Roles []string `json:"roles,omitempty"`
+
+	// Scope is a space-separated list of OAuth scopes
+	Scope string `json:"scope,omitempty"`
+}
+
+// JWTVerifier validates bearer tokens
+type JWTVerifier struct {
+	secret  []byte
+	keys    map[string]*rsa.PublicKey
+	parser  *jwt.Parser
+	methods []string
+	leeway  time.Duration
+}
+
+// NewJWTVerifier creates a JWT verifier, loading the JWKS file if configured
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	verifier := &JWTVerifier{}
+
+	if config.Secret != "" {
+		if len(config.Secret) < minHMACSecretLength {
+			return nil, fmt.Errorf("JWT secret must be at least %d bytes", minHMACSecretLength)
+		}
+		verifier.secret = []byte(config.Secret)
+		verifier.methods = append(verifier.methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if config.JWKSFile != "" {
+		keys, err := LoadJWKS(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+		verifier.methods = append(verifier.methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	if len(verifier.methods) == 0 {
+		return nil, fmt.Errorf("a JWT secret or JWKS file is required")
+	}
+
+	if config.Leeway == 0 {
+		config.Leeway = DefaultLeeway
+	}
+	verifier.leeway = config.Leeway
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(verifier.methods),
+		jwt.WithLeeway(config.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	verifier.parser = jwt.NewParser(options...)
+
+	return verifier, nil
+}
+
+// Verify validates a token and returns the identity it asserts
+func (v *JWTVerifier) Verify(token string) (*Identity, error) {
+	claims, err := v.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims.identity(), nil
+}
+
+// VerifyRecent validates a token that was issued no more than maxAge ago,
+// as proof that the caller has just authenticated rather than reusing a
+// session token
+func (v *JWTVerifier) VerifyRecent(token string, maxAge time.Duration) (*Identity, error) {
+	claims, err := v.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.IssuedAt == nil {
+		return nil, fmt.Errorf("invalid token: missing issue time")
+	}
+	if age := time.Since(claims.IssuedAt.Time); age > maxAge+v.leeway {
+		return nil, fmt.Errorf("token was issued %s ago, authenticate again", age.Round(time.Second))
+	}
+
+	return claims.identity(), nil
+}
+
+// parse validates a token and returns its claims
+func (v *JWTVerifier) parse(token string) (*Claims, error) {
+	claims := &Claims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("invalid token: missing subject")
+	}
+
+	return claims, nil
+}
+
+// identity returns the identity asserted by the claims
+func (c *Claims) identity() *Identity {
+	return &Identity{
+		Subject: c.Subject,
+		Method:  MethodJWT,
+		Roles:   c.Roles,
+		Scopes:  strings.Fields(c.Scope),
+		TokenID: c.ID,
+	}
+}
+
+// keyFunc returns the verification key for a token's algorithm and key ID
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.secret, nil
+
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" && len(v.keys) == 1 {
+			for _, key := range v.keys {
+				return key, nil
+			}
+		}
+		key, ok := v.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// jsonWebKey is an RSA key in a JSON Web Key Set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// LoadJWKS reads the RSA signing keys of a JSON Web Key Set file, indexed by
+// key ID. Keys of other types or uses are skipped.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in JWKS file: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS file contains no RSA signing keys")
+	}
+
+	return keys, nil
+}
+
+// rsaPublicKey decodes the key's modulus and exponent
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("unsupported exponent")
+	}
+
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	if key.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func signHS256(t *testing.T, secret string, claims Claims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(subject string) Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    "lab-idp",
+			Audience:  jwt.ClaimStrings{"lab-gateway"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: []string{"operator"},
+		Scope: "devices:read commands:write",
+	}
+}
+
+// writeJWKS writes the public halves of the keys as a JWKS file
+func writeJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) string {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	verifier, err := NewJWTVerifier(JWTConfig{Secret: testSecret, Issuer: "lab-idp", Audience: "lab-gateway"})
+	require.NoError(t, err)
+
+	identity, err := verifier.Verify(signHS256(t, testSecret, validClaims("alice")))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.Subject)
+	assert.Equal(t, MethodJWT, identity.Method)
+	assert.True(t, identity.HasRole("operator"))
+	assert.True(t, identity.HasScope("commands:write"))
+	assert.False(t, identity.IsDevice())
+
+	expired := validClaims("alice")
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noExpiry := validClaims("alice")
+	noExpiry.ExpiresAt = nil
+	wrongAudience := validClaims("alice")
+	wrongAudience.Audience = jwt.ClaimStrings{"other-service"}
+	noSubject := validClaims("")
+
+	for name, token := range map[string]string{
+		"expired":        signHS256(t, testSecret, expired),
+		"no expiry":      signHS256(t, testSecret, noExpiry),
+		"wrong audience": signHS256(t, testSecret, wrongAudience),
+		"no subject":     signHS256(t, testSecret, noSubject),
+		"wrong secret":   signHS256(t, "fedcba9876543210fedcba9876543210", validClaims("alice")),
+		"garbage":        "not.a.token",
+	} {
+		_, err := verifier.Verify(token)
+		assert.Error(t, err, name)
+	}
+
+	_, err = NewJWTVerifier(JWTConfig{Secret: "short"})
+	assert.Error(t, err)
+	_, err = NewJWTVerifier(JWTConfig{})
+	assert.Error(t, err)
+}
+
+func TestJWTVerifier_VerifyRecent(t *testing.T) {
+	verifier, err := NewJWTVerifier(JWTConfig{Secret: testSecret})
+	require.NoError(t, err)
+
+	fresh := validClaims("alice")
+	fresh.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	identity, err := verifier.VerifyRecent(signHS256(t, testSecret, fresh), 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.Subject)
+
+	stale := validClaims("alice")
+	stale.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	_, err = verifier.VerifyRecent(signHS256(t, testSecret, stale), 5*time.Minute)
+	assert.Error(t, err)
+
+	_, err = verifier.VerifyRecent(signHS256(t, testSecret, validClaims("alice")), 5*time.Minute)
+	assert.Error(t, err, "tokens without an issue time are not recent")
+
+	// Session tokens remain valid for ordinary calls
+	_, err = verifier.Verify(signHS256(t, testSecret, stale))
+	assert.NoError(t, err)
+}
+
+func TestJWTVerifier_RS256WithJWKS(t *testing.T) {
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	previous, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	unknown, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := writeJWKS(t, map[string]*rsa.PrivateKey{"2026-10": current, "2026-09": previous})
+	verifier, err := NewJWTVerifier(JWTConfig{JWKSFile: path})
+	require.NoError(t, err)
+
+	identity, err := verifier.Verify(signRS256(t, current, "2026-10", validClaims("lims-service")))
+	require.NoError(t, err)
+	assert.Equal(t, "lims-service", identity.Subject)
+
+	_, err = verifier.Verify(signRS256(t, previous, "2026-09", validClaims("lims-service")))
+	assert.NoError(t, err)
+
+	_, err = verifier.Verify(signRS256(t, unknown, "2026-10", validClaims("lims-service")))
+	assert.Error(t, err, "signature from a key not in the set")
+
+	_, err = verifier.Verify(signRS256(t, current, "", validClaims("lims-service")))
+	assert.Error(t, err, "key id is required with several keys")
+
+	// HS256 is not accepted when only a JWKS is configured, so the public
+	// key can never be used as an HMAC secret
+	_, err = verifier.Verify(signHS256(t, testSecret, validClaims("lims-service")))
+	assert.Error(t, err)
+}
+
+func TestAuthenticator(t *testing.T) {
+	verifier, err := NewJWTVerifier(JWTConfig{Secret: testSecret})
+	require.NoError(t, err)
+	authenticator := NewAuthenticator(verifier, nil, true)
+
+	withToken := func(ctx context.Context, value string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(AuthorizationHeader, value))
+	}
+	withCert := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "hplc-01"}}}},
+		}},
+	})
+
+	identity, err := authenticator.Authenticate(withToken(context.Background(), "Bearer "+signHS256(t, testSecret, validClaims("alice"))))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.Subject)
+
+	identity, err = authenticator.Authenticate(withCert)
+	require.NoError(t, err)
+	assert.Equal(t, MethodCertificate, identity.Method)
+	assert.Equal(t, []string{"hplc-01"}, identity.DeviceIDs)
+
+	_, err = authenticator.Authenticate(withToken(withCert, "Bearer invalid"))
+	assert.Error(t, err, "a bad token is not rescued by a certificate")
+
+	_, err = authenticator.Authenticate(context.Background())
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = NewAuthenticator(verifier, nil, false).Authenticate(withCert)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	ctx := WithIdentity(context.Background(), identity)
+	assert.Equal(t, "hplc-01", Subject(ctx))
+	assert.Equal(t, "", Subject(context.Background()))
+}
@@ -31,12 +31,39 @@ type ConnectionStatus struct {
 	Metrics          map[string]interface{} `json:"metrics,omitempty"`
 }
 
+const (
+	// DefaultHeartbeatTimeout is how long a device may go without a heartbeat
+	// before it is considered offline
+	DefaultHeartbeatTimeout = 25 * time.Second
+	
+	// DefaultCleanupInterval is how often heartbeats are checked. Together with
+	// DefaultHeartbeatTimeout it bounds offline detection to 30 seconds.
+	DefaultCleanupInterval = 5 * time.Second
+)
+
+// Config represents the connection manager configuration
+type Config struct {
+	HeartbeatTimeout time.Duration
+	CleanupInterval  time.Duration
+	
+	// Observer, if set, is notified of connectivity transitions
+	Observer StatusObserver
+	
+	// Registry, if set, shares the connections that have a stream attached
+	// with the other replicas, as held by ReplicaID
+	Registry  Registry
+	ReplicaID string
+}
+
 // ConnectionManager manages active device connections
 type ConnectionManager struct {
 	connections map[string]*ConnectionStatus
 	sessions    map[string]*models.DeviceSession
 	mutex       sync.RWMutex
 	logger      *logger.Logger
+	observer    StatusObserver
+	registry    Registry
+	replicaID   string
 	
 	// Configuration
 	heartbeatTimeout time.Duration
@@ -48,13 +75,27 @@ type ConnectionManager struct {
 }
 
 // NewConnectionManager creates a new connection manager
-func NewConnectionManager(logger *logger.Logger) *ConnectionManager {
+func NewConnectionManager(config Config, logger *logger.Logger) *ConnectionManager {
+	// Set default configuration values
+	if config.HeartbeatTimeout == 0 {
+		config.HeartbeatTimeout = DefaultHeartbeatTimeout
+	}
+	if config.CleanupInterval == 0 {
+		config.CleanupInterval = DefaultCleanupInterval
+	}
+	if config.ReplicaID == "" {
+		config.ReplicaID = DefaultReplicaID()
+	}
+	
 	cm := &ConnectionManager{
 		connections:      make(map[string]*ConnectionStatus),
 		sessions:         make(map[string]*models.DeviceSession),
 		logger:           logger,
-		heartbeatTimeout: 2 * time.Minute,
-		cleanupInterval:  30 * time.Second,
+		observer:         config.Observer,
+		registry:         config.Registry,
+		replicaID:        config.ReplicaID,
+		heartbeatTimeout: config.HeartbeatTimeout,
+		cleanupInterval:  config.CleanupInterval,
 		stopChan:         make(chan struct{}),
 		doneChan:         make(chan struct{}),
 	}
@@ -72,8 +113,14 @@ func (cm *ConnectionManager) GenerateSessionID(deviceID string) string {
 
 // RegisterConnection registers a new device connection
 func (cm *ConnectionManager) RegisterConnection(ctx context.Context, session *models.DeviceSession) error {
+	cm.registerConnection(session, false)
+	return nil
+}
+
+// registerConnection registers a device connection, on a new session or on
+// one resumed from another replica
+func (cm *ConnectionManager) registerConnection(session *models.DeviceSession, resumed bool) {
 	cm.mutex.Lock()
-	defer cm.mutex.Unlock()
 	
 	connectionID := uuid.New().String()
 	now := time.Now()
@@ -100,28 +147,77 @@ func (cm *ConnectionManager) RegisterConnection(ctx context.Context, session *mo
 	cm.connections[session.DeviceID] = connStatus
 	cm.sessions[session.SessionID] = session
 	
+	sessionCopy := *session
+	cm.mutex.Unlock()
+	
 	cm.logger.WithFields(map[string]interface{}{
 		"device_id":     session.DeviceID,
 		"session_id":    session.SessionID,
 		"connection_id": connectionID,
+		"resumed":       resumed,
 	}).Info("Device connection registered")
 	
-	return nil
+	if cm.observer != nil {
+		cm.observer.DeviceConnected(&sessionCopy, resumed)
+	}
+}
+
+// ResumeSession returns a session by its ID. A session that is not held
+// locally is taken over from the replica that holds it in the registry, so
+// that a device handed off by a draining replica resumes its session here.
+func (cm *ConnectionManager) ResumeSession(ctx context.Context, deviceID, sessionID string) (*models.DeviceSession, error) {
+	if session := cm.GetSessionByID(sessionID); session != nil || cm.registry == nil {
+		return session, nil
+	}
+	
+	registration, err := cm.registry.Lookup(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up session: %w", err)
+	}
+	if registration == nil || registration.Connection.SessionID != sessionID {
+		return nil, nil
+	}
+	
+	now := time.Now()
+	session := &models.DeviceSession{
+		DeviceID:      deviceID,
+		SessionID:     sessionID,
+		ConnectedAt:   registration.Connection.ConnectedAt,
+		LastHeartbeat: now,
+		IsActive:      true,
+		Metadata:      make(map[string]interface{}),
+	}
+	cm.registerConnection(session, true)
+	
+	cm.logger.WithFields(map[string]interface{}{
+		"device_id":    deviceID,
+		"session_id":   sessionID,
+		"from_replica": registration.ReplicaID,
+	}).Info("Device session taken over from another replica")
+	
+	return cm.GetSessionByID(sessionID), nil
 }
 
 // UpdateHeartbeat updates the heartbeat timestamp for a device
 func (cm *ConnectionManager) UpdateHeartbeat(deviceID string, metrics map[string]interface{}) error {
 	cm.mutex.Lock()
-	defer cm.mutex.Unlock()
 	
 	connStatus, exists := cm.connections[deviceID]
 	if !exists {
+		cm.mutex.Unlock()
 		return fmt.Errorf("connection not found for device: %s", deviceID)
 	}
 	
 	now := time.Now()
+	reconnected := !connStatus.IsConnected
+	var resumedSession models.DeviceSession
+	if session, exists := cm.sessions[connStatus.SessionID]; exists {
+		session.LastHeartbeat = now
+		resumedSession = *session
+	}
 	connStatus.LastHeartbeat = now
 	connStatus.LastSeen = now
+	connStatus.IsConnected = true
 	connStatus.IsHealthy = true
 	
 	// Update metrics if provided
@@ -140,6 +236,13 @@ func (cm *ConnectionManager) UpdateHeartbeat(deviceID string, metrics map[string
 		"last_heartbeat": now,
 	}).Debug("Device heartbeat updated")
 	
+	cm.mutex.Unlock()
+	
+	// A heartbeat after a timeout means the device came back on the same session
+	if reconnected && cm.observer != nil && resumedSession.SessionID != "" {
+		cm.observer.DeviceConnected(&resumedSession, true)
+	}
+	
 	return nil
 }
 
@@ -189,13 +292,16 @@ func (cm *ConnectionManager) RecordConnectionError(deviceID string, errorMsg str
 // DisconnectDevice marks a device as disconnected
 func (cm *ConnectionManager) DisconnectDevice(deviceID string, reason string) error {
 	cm.mutex.Lock()
-	defer cm.mutex.Unlock()
 	
 	connStatus, exists := cm.connections[deviceID]
 	if !exists {
+		cm.mutex.Unlock()
 		return fmt.Errorf("connection not found for device: %s", deviceID)
 	}
 	
+	wasConnected := connStatus.IsConnected
+	sessionID := connStatus.SessionID
+	
 	connStatus.IsConnected = false
 	connStatus.IsHealthy = false
 	connStatus.LastSeen = time.Now()
@@ -217,6 +323,86 @@ func (cm *ConnectionManager) DisconnectDevice(deviceID string, reason string) er
 		"reason":        reason,
 	}).Info("Device disconnected")
 	
+	cm.mutex.Unlock()
+	
+	cm.unpublish(deviceID)
+	
+	if wasConnected && cm.observer != nil {
+		cm.observer.DeviceDisconnected(deviceID, sessionID, reason)
+	}
+	
+	return nil
+}
+
+// AttachStream records that a device opened a data stream on its connection
+func (cm *ConnectionManager) AttachStream(deviceID string, streamID string) error {
+	cm.mutex.Lock()
+	
+	connStatus, exists := cm.connections[deviceID]
+	if !exists {
+		cm.mutex.Unlock()
+		return fmt.Errorf("connection not found for device: %s", deviceID)
+	}
+	
+	connStatus.StreamID = &streamID
+	connStatus.LastSeen = time.Now()
+	if session, exists := cm.sessions[connStatus.SessionID]; exists {
+		session.StreamID = &streamID
+	}
+	sessionID := connStatus.SessionID
+	registration := cm.registration(connStatus)
+	
+	cm.mutex.Unlock()
+	
+	// The stream is held here now, even if another replica held it before
+	cm.publish(registration, true)
+	
+	cm.logger.WithFields(map[string]interface{}{
+		"device_id": deviceID,
+		"stream_id": streamID,
+	}).Info("Device stream attached")
+	
+	if cm.observer != nil {
+		cm.observer.StreamOpened(deviceID, sessionID, streamID)
+	}
+	
+	return nil
+}
+
+// DetachStream records that a device's data stream was closed
+func (cm *ConnectionManager) DetachStream(deviceID string, reason string) error {
+	cm.mutex.Lock()
+	
+	connStatus, exists := cm.connections[deviceID]
+	if !exists || connStatus.StreamID == nil {
+		cm.mutex.Unlock()
+		return fmt.Errorf("no stream attached for device: %s", deviceID)
+	}
+	
+	streamID := *connStatus.StreamID
+	connStatus.StreamID = nil
+	if session, exists := cm.sessions[connStatus.SessionID]; exists {
+		session.StreamID = nil
+	}
+	sessionID := connStatus.SessionID
+	registration := cm.registration(connStatus)
+	
+	cm.mutex.Unlock()
+	
+	// Keep the session registered, so that the device can resume it on
+	// another replica, unless one has taken it over already
+	cm.publish(registration, false)
+	
+	cm.logger.WithFields(map[string]interface{}{
+		"device_id": deviceID,
+		"stream_id": streamID,
+		"reason":    reason,
+	}).Info("Device stream detached")
+	
+	if cm.observer != nil {
+		cm.observer.StreamClosed(deviceID, sessionID, streamID, reason)
+	}
+	
 	return nil
 }
 
@@ -281,6 +467,14 @@ func (cm *ConnectionManager) GetConnectionCount() int {
 	return count
 }
 
+// GetSessionCount returns the number of sessions held
+func (cm *ConnectionManager) GetSessionCount() int {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	
+	return len(cm.sessions)
+}
+
 // GetSessionByID returns a session by its ID
 func (cm *ConnectionManager) GetSessionByID(sessionID string) *models.DeviceSession {
 	cm.mutex.RLock()
@@ -323,12 +517,167 @@ func (cm *ConnectionManager) cleanupRoutine() {
 
 // performCleanup removes stale connections and updates health status
 func (cm *ConnectionManager) performCleanup() {
+	timedOut := cm.sweepConnections()
+	
+	// Notify outside the lock; observers typically perform I/O
+	for _, connStatus := range timedOut {
+		// A device that moved to another replica stopped sending heartbeats
+		// here, but did not go offline
+		if cm.movedAway(connStatus.DeviceID) {
+			cm.forget(connStatus.DeviceID, connStatus.SessionID)
+			continue
+		}
+		cm.unpublish(connStatus.DeviceID)
+		
+		if cm.observer != nil {
+			cm.observer.DeviceTimedOut(connStatus.DeviceID, connStatus.SessionID, connStatus.LastHeartbeat)
+		}
+	}
+	
+	cm.refreshRegistrations()
+}
+
+// LookupConnection returns the connection of a device, whichever replica
+// holds it. A connection with a stream attached here is returned as held by
+// this replica; otherwise the registry is consulted, since the device may
+// have moved its stream to another replica.
+func (cm *ConnectionManager) LookupConnection(ctx context.Context, deviceID string) (*Registration, error) {
+	var local *Registration
+	if connStatus := cm.GetConnectionStatus(deviceID); connStatus != nil {
+		local = &Registration{ReplicaID: cm.replicaID, Connection: *connStatus}
+		if connStatus.IsConnected && connStatus.StreamID != nil {
+			return local, nil
+		}
+	}
+	if cm.registry == nil {
+		return local, nil
+	}
+	
+	registration, err := cm.registry.Lookup(ctx, deviceID)
+	if err != nil {
+		return local, fmt.Errorf("failed to look up connection: %w", err)
+	}
+	if registration == nil || registration.ReplicaID == cm.replicaID {
+		return local, nil
+	}
+	return registration, nil
+}
+
+// registration returns the registration of a connection held by this
+// replica (must be called with lock held)
+func (cm *ConnectionManager) registration(connStatus *ConnectionStatus) *Registration {
+	statusCopy := *connStatus
+	if connStatus.Metrics != nil {
+		statusCopy.Metrics = make(map[string]interface{}, len(connStatus.Metrics))
+		for k, v := range connStatus.Metrics {
+			statusCopy.Metrics[k] = v
+		}
+	}
+	return &Registration{ReplicaID: cm.replicaID, Connection: statusCopy}
+}
+
+// publish records a connection in the registry. Taking over registers it
+// as held by this replica even if another replica held it.
+func (cm *ConnectionManager) publish(registration *Registration, takeOver bool) {
+	if cm.registry == nil {
+		return
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+	
+	var err error
+	if takeOver {
+		err = cm.registry.Register(ctx, registration)
+	} else {
+		err = cm.registry.Refresh(ctx, registration)
+	}
+	if err != nil {
+		cm.logger.WithError(err).WithField("device_id", registration.Connection.DeviceID).Warn("Failed to publish connection to registry")
+	}
+}
+
+// unpublish removes a device from the registry if this replica holds it
+func (cm *ConnectionManager) unpublish(deviceID string) {
+	if cm.registry == nil {
+		return
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+	
+	if err := cm.registry.Remove(ctx, deviceID, cm.replicaID); err != nil {
+		cm.logger.WithError(err).WithField("device_id", deviceID).Warn("Failed to remove connection from registry")
+	}
+}
+
+// movedAway reports whether another replica holds the connection of a
+// device according to the registry
+func (cm *ConnectionManager) movedAway(deviceID string) bool {
+	if cm.registry == nil {
+		return false
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+	
+	registration, err := cm.registry.Lookup(ctx, deviceID)
+	if err != nil {
+		cm.logger.WithError(err).WithField("device_id", deviceID).Warn("Failed to look up connection in registry")
+		return false
+	}
+	return registration != nil && registration.ReplicaID != cm.replicaID
+}
+
+// forget drops the connection of a device that moved to another replica,
+// unless it registered a new session here since
+func (cm *ConnectionManager) forget(deviceID, sessionID string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+	
+	if connStatus, exists := cm.connections[deviceID]; exists && connStatus.SessionID == sessionID && !connStatus.IsConnected {
+		delete(cm.connections, deviceID)
+		delete(cm.sessions, sessionID)
+		
+		cm.logger.WithFields(map[string]interface{}{
+			"device_id":  deviceID,
+			"session_id": sessionID,
+		}).Info("Device connection moved to another replica")
+	}
+}
+
+// refreshRegistrations extends the registrations of the connected devices
+// with a stream attached, updating their heartbeats and statistics
+func (cm *ConnectionManager) refreshRegistrations() {
+	if cm.registry == nil {
+		return
+	}
+	
+	cm.mutex.RLock()
+	registrations := make([]*Registration, 0, len(cm.connections))
+	for _, connStatus := range cm.connections {
+		if connStatus.IsConnected && connStatus.StreamID != nil {
+			registrations = append(registrations, cm.registration(connStatus))
+		}
+	}
+	cm.mutex.RUnlock()
+	
+	for _, registration := range registrations {
+		cm.publish(registration, false)
+	}
+}
+
+// sweepConnections marks connections without a recent heartbeat as
+// disconnected and removes very old ones. It returns a copy of every
+// connection that timed out during this sweep.
+func (cm *ConnectionManager) sweepConnections() []ConnectionStatus {
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 	
 	now := time.Now()
 	staleConnections := make([]string, 0)
 	unhealthyConnections := make([]string, 0)
+	timedOut := make([]ConnectionStatus, 0)
 	
 	for deviceID, connStatus := range cm.connections {
 		// Check for stale connections (no heartbeat for too long)
@@ -337,6 +686,7 @@ func (cm *ConnectionManager) performCleanup() {
 				connStatus.IsConnected = false
 				connStatus.IsHealthy = false
 				unhealthyConnections = append(unhealthyConnections, deviceID)
+				timedOut = append(timedOut, *connStatus)
 			}
 			
 			// Remove very old connections (offline for more than 1 hour)
@@ -364,6 +714,8 @@ func (cm *ConnectionManager) performCleanup() {
 			"active_connections":    cm.getActiveConnectionCount(),
 		}).Debug("Connection cleanup completed")
 	}
+	
+	return timedOut
 }
 
 // getActiveConnectionCount returns the count of active connections (must be called with lock held)
@@ -409,6 +761,17 @@ func (cm *ConnectionManager) GetStats() map[string]interface{} {
 	}
 }
 
+// HealthCheck reports the connection manager unhealthy once its cleanup
+// routine has stopped, since heartbeats then no longer time out
+func (cm *ConnectionManager) HealthCheck(ctx context.Context) error {
+	select {
+	case <-cm.doneChan:
+		return fmt.Errorf("connection manager is closed")
+	default:
+		return nil
+	}
+}
+
 // Close shuts down the connection manager
 func (cm *ConnectionManager) Close() error {
 	cm.logger.Info("Shutting down connection manager")
@@ -423,6 +786,21 @@ func (cm *ConnectionManager) Close() error {
 		cm.logger.Warn("Connection manager cleanup routine did not stop within timeout")
 	}
 	
+	// Unregister the streams still attached. Sessions without a stream stay
+	// registered until they expire, so that handed off devices can resume
+	// them on another replica.
+	cm.mutex.RLock()
+	deviceIDs := make([]string, 0, len(cm.connections))
+	for deviceID, connStatus := range cm.connections {
+		if connStatus.StreamID != nil {
+			deviceIDs = append(deviceIDs, deviceID)
+		}
+	}
+	cm.mutex.RUnlock()
+	for _, deviceID := range deviceIDs {
+		cm.unpublish(deviceID)
+	}
+	
 	// Clear all connections and sessions
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
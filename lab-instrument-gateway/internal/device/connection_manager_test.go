@@ -0,0 +1,142 @@
+package device
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// recordingObserver records connectivity transitions
+type recordingObserver struct {
+	mutex        sync.Mutex
+	connected    []string
+	timedOut     []string
+	disconnected []string
+	resumed      []string
+	streams      []string
+}
+
+func (o *recordingObserver) DeviceConnected(session *models.DeviceSession, resumed bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.connected = append(o.connected, session.DeviceID)
+	if resumed {
+		o.resumed = append(o.resumed, session.SessionID)
+	}
+}
+
+func (o *recordingObserver) DeviceTimedOut(deviceID, sessionID string, lastHeartbeat time.Time) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.timedOut = append(o.timedOut, deviceID)
+}
+
+func (o *recordingObserver) DeviceDisconnected(deviceID, sessionID, reason string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.disconnected = append(o.disconnected, deviceID)
+}
+
+func (o *recordingObserver) StreamOpened(deviceID, sessionID, streamID string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.streams = append(o.streams, "open:"+streamID)
+}
+
+func (o *recordingObserver) StreamClosed(deviceID, sessionID, streamID, reason string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.streams = append(o.streams, "close:"+streamID)
+}
+
+func (o *recordingObserver) timedOutCount() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return len(o.timedOut)
+}
+
+func newTestSession(deviceID string) *models.DeviceSession {
+	return &models.DeviceSession{
+		DeviceID:      deviceID,
+		SessionID:     deviceID + "-session",
+		ConnectedAt:   time.Now(),
+		LastHeartbeat: time.Now(),
+		IsActive:      true,
+	}
+}
+
+func TestConnectionManager_Defaults(t *testing.T) {
+	cm := NewConnectionManager(Config{}, logger.NewDefaultLogger())
+	defer cm.Close()
+
+	stats := cm.GetStats()
+	assert.Equal(t, DefaultHeartbeatTimeout.String(), stats["heartbeat_timeout"])
+	assert.Equal(t, DefaultCleanupInterval.String(), stats["cleanup_interval"])
+	assert.LessOrEqual(t, DefaultHeartbeatTimeout+DefaultCleanupInterval, 30*time.Second)
+}
+
+func TestConnectionManager_HeartbeatTimeout(t *testing.T) {
+	observer := &recordingObserver{}
+	cm := NewConnectionManager(Config{
+		HeartbeatTimeout: 50 * time.Millisecond,
+		CleanupInterval:  10 * time.Millisecond,
+		Observer:         observer,
+	}, logger.NewDefaultLogger())
+	defer cm.Close()
+
+	require.NoError(t, cm.RegisterConnection(context.Background(), newTestSession("device-1")))
+	assert.Equal(t, []string{"device-1"}, observer.connected)
+
+	require.Eventually(t, func() bool { return observer.timedOutCount() == 1 }, time.Second, 5*time.Millisecond)
+	assert.False(t, cm.GetConnectionStatus("device-1").IsConnected)
+
+	// Further sweeps must not report the same timeout again
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, observer.timedOutCount())
+
+	// A heartbeat on the same session brings the device back
+	require.NoError(t, cm.UpdateHeartbeat("device-1", nil))
+	assert.True(t, cm.GetConnectionStatus("device-1").IsConnected)
+	observer.mutex.Lock()
+	assert.Equal(t, []string{"device-1", "device-1"}, observer.connected)
+	assert.Equal(t, []string{"device-1-session"}, observer.resumed)
+	observer.mutex.Unlock()
+}
+
+func TestConnectionManager_DisconnectDevice(t *testing.T) {
+	observer := &recordingObserver{}
+	cm := NewConnectionManager(Config{Observer: observer}, logger.NewDefaultLogger())
+	defer cm.Close()
+
+	require.NoError(t, cm.RegisterConnection(context.Background(), newTestSession("device-1")))
+	require.NoError(t, cm.DisconnectDevice("device-1", "client closed stream"))
+	require.NoError(t, cm.DisconnectDevice("device-1", "duplicate"))
+
+	assert.Equal(t, []string{"device-1"}, observer.disconnected)
+	assert.Empty(t, observer.timedOut)
+}
+
+func TestConnectionManager_Streams(t *testing.T) {
+	observer := &recordingObserver{}
+	cm := NewConnectionManager(Config{Observer: observer}, logger.NewDefaultLogger())
+	defer cm.Close()
+
+	assert.Error(t, cm.AttachStream("device-1", "stream-1"))
+
+	require.NoError(t, cm.RegisterConnection(context.Background(), newTestSession("device-1")))
+	require.NoError(t, cm.AttachStream("device-1", "stream-1"))
+	assert.Equal(t, "stream-1", *cm.GetConnectionStatus("device-1").StreamID)
+
+	require.NoError(t, cm.DetachStream("device-1", "client closed stream"))
+	assert.Nil(t, cm.GetConnectionStatus("device-1").StreamID)
+	assert.Error(t, cm.DetachStream("device-1", "again"))
+
+	assert.Equal(t, []string{"open:stream-1", "close:stream-1"}, observer.streams)
+}
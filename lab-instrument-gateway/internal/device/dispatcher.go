@@ -0,0 +1,352 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// forwardedDispatchTimeout bounds loading and dispatching a forwarded command
+const forwardedDispatchTimeout = 10 * time.Second
+
+// CommandSender delivers a command to a device over its data stream
+type CommandSender func(command *models.Command) error
+
+// streamSender is the command sender of one attached stream
+type streamSender struct {
+	streamID string
+	send     CommandSender
+
+	// sent lists the IDs of the commands sent over the stream, which
+	// devices do not acknowledge
+	sent []string
+}
+
+// Dispatcher delivers stored commands to devices over their data streams.
+// Commands for a device without an attached stream stay pending and are
+// delivered when it next attaches one. With a registry, commands for devices
+// streaming to another replica are forwarded to it.
+type Dispatcher struct {
+	commands repository.CommandRepository
+	logger   *logger.Logger
+
+	registry     Registry
+	replicaID    string
+	subscription Subscription
+	doneChan     chan struct{}
+
+	calibrated func(deviceID string)
+
+	mutex    sync.RWMutex
+	senders  map[string]*streamSender
+	draining bool
+
+	// inFlight counts the dispatches between claiming a command and
+	// sending it
+	inFlight sync.WaitGroup
+}
+
+// NewDispatcher creates a new command dispatcher
+func NewDispatcher(commands repository.CommandRepository, logger *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		commands: commands,
+		logger:   logger,
+		senders:  make(map[string]*streamSender),
+	}
+}
+
+// ForwardCommands shares the dispatcher with the other replicas through the
+// registry: commands for devices streaming to another replica are forwarded
+// to it, and the commands forwarded to this replica are delivered once Start
+// is called
+func (d *Dispatcher) ForwardCommands(registry Registry, replicaID string) {
+	d.registry = registry
+	d.replicaID = replicaID
+}
+
+// OnCalibrate registers a function called with the device of each calibrate
+// command delivered. Devices do not report command results, so delivery is
+// the point from which readings are taken under the new calibration.
+func (d *Dispatcher) OnCalibrate(calibrated func(deviceID string)) {
+	d.calibrated = calibrated
+}
+
+// Start subscribes to the commands forwarded to this replica
+func (d *Dispatcher) Start(ctx context.Context) error {
+	if d.registry == nil {
+		return nil
+	}
+
+	subscription, err := d.registry.Subscribe(ctx, d.replicaID)
+	if err != nil {
+		return err
+	}
+	d.subscription = subscription
+	d.doneChan = make(chan struct{})
+	go d.deliverForwarded()
+
+	d.logger.WithField("replica_id", d.replicaID).Info("Command forwarding started")
+	return nil
+}
+
+// Close stops receiving forwarded commands
+func (d *Dispatcher) Close() error {
+	if d.subscription == nil {
+		return nil
+	}
+
+	err := d.subscription.Close()
+
+	select {
+	case <-d.doneChan:
+	case <-time.After(5 * time.Second):
+		d.logger.Warn("Forwarded command delivery did not stop within timeout")
+	}
+
+	return err
+}
+
+// Attach registers the command sender of a device's stream, replacing the
+// sender of any earlier stream
+func (d *Dispatcher) Attach(deviceID, streamID string, send CommandSender) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.senders[deviceID] = &streamSender{streamID: streamID, send: send}
+}
+
+// Detach removes the command sender of a stream, unless a newer stream of
+// the device has replaced it
+func (d *Dispatcher) Detach(deviceID, streamID string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if sender, ok := d.senders[deviceID]; ok && sender.streamID == streamID {
+		delete(d.senders, deviceID)
+	}
+}
+
+// Dispatch delivers a pending command if its device has a stream attached
+// and the dispatcher is not draining. The command is claimed by marking it
+// executing before it is sent, so a command dispatched concurrently, e.g. on
+// reconnect while it is being forwarded, is delivered only once; a command
+// that cannot be sent is returned to pending. It returns whether the command
+// was delivered.
+func (d *Dispatcher) Dispatch(ctx context.Context, command *models.Command) (bool, error) {
+	// Commands stay pending while draining, for the next gateway to deliver
+	d.mutex.RLock()
+	sender, ok := d.senders[command.DeviceID]
+	ok = ok && !d.draining
+	if ok {
+		d.inFlight.Add(1)
+	}
+	d.mutex.RUnlock()
+
+	if !ok {
+		return false, nil
+	}
+	defer d.inFlight.Done()
+
+	if !command.CanExecute() {
+		return false, nil
+	}
+
+	claimed, err := d.commands.Claim(ctx, command)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim command: %w", err)
+	}
+	if !claimed {
+		commandsDispatchedTotal.WithLabelValues(command.Type, "already_claimed").Inc()
+		return false, nil
+	}
+
+	if err := sender.send(command); err != nil {
+		commandsDispatchedTotal.WithLabelValues(command.Type, "error").Inc()
+		if err := d.release(ctx, command); err != nil {
+			d.logger.WithError(err).WithField("command_id", command.CommandID).Error("Failed to return unsent command to pending")
+		}
+		return false, fmt.Errorf("failed to send command to device: %w", err)
+	}
+	commandsDispatchedTotal.WithLabelValues(command.Type, "delivered").Inc()
+
+	d.mutex.Lock()
+	sender.sent = append(sender.sent, command.ID)
+	d.mutex.Unlock()
+	if !command.CreatedAt.IsZero() {
+		commandDispatchLatency.WithLabelValues(command.Type).Observe(time.Since(command.CreatedAt).Seconds())
+	}
+	if command.Type == models.CommandTypeCalibrate && d.calibrated != nil {
+		d.calibrated(command.DeviceID)
+	}
+
+	d.logger.WithFields(map[string]interface{}{
+		"device_id":  command.DeviceID,
+		"command_id": command.CommandID,
+		"type":       command.Type,
+	}).Info("Command dispatched to device")
+
+	return true, nil
+}
+
+// release returns a claimed command to pending
+func (d *Dispatcher) release(ctx context.Context, command *models.Command) error {
+	command.Status = models.CommandStatusPending
+	command.ExecutedAt = nil
+	return d.commands.Update(ctx, command)
+}
+
+// Forward hands a pending command to the replica holding the stream of its
+// device, returning whether it was forwarded. Commands are not forwarded
+// when the device has no stream, or has it on this replica.
+func (d *Dispatcher) Forward(ctx context.Context, command *models.Command) (bool, error) {
+	if d.registry == nil || !command.CanExecute() {
+		return false, nil
+	}
+
+	registration, err := d.registry.Lookup(ctx, command.DeviceID)
+	if err != nil {
+		return false, err
+	}
+	if registration == nil || registration.ReplicaID == d.replicaID || registration.Connection.StreamID == nil {
+		return false, nil
+	}
+
+	if err := d.registry.Forward(ctx, registration.ReplicaID, command.CommandID); err != nil {
+		commandsDispatchedTotal.WithLabelValues(command.Type, "forward_error").Inc()
+		return false, err
+	}
+	commandsDispatchedTotal.WithLabelValues(command.Type, "forwarded").Inc()
+
+	d.logger.WithFields(map[string]interface{}{
+		"device_id":  command.DeviceID,
+		"command_id": command.CommandID,
+		"replica_id": registration.ReplicaID,
+	}).Info("Command forwarded to replica")
+
+	return true, nil
+}
+
+// deliverForwarded dispatches the commands forwarded to this replica until
+// the subscription is closed. Commands that cannot be delivered stay pending.
+func (d *Dispatcher) deliverForwarded() {
+	defer close(d.doneChan)
+
+	for commandID := range d.subscription.Commands() {
+		ctx, cancel := context.WithTimeout(context.Background(), forwardedDispatchTimeout)
+		d.deliverCommand(ctx, commandID)
+		cancel()
+	}
+}
+
+// deliverCommand loads a forwarded command and dispatches it
+func (d *Dispatcher) deliverCommand(ctx context.Context, commandID string) {
+	command, err := d.commands.GetByCommandID(ctx, commandID)
+	if err != nil {
+		d.logger.WithError(err).WithField("command_id", commandID).Warn("Failed to load forwarded command")
+		return
+	}
+
+	delivered, err := d.Dispatch(ctx, command)
+	if err != nil {
+		d.logger.WithError(err).WithField("command_id", commandID).Warn("Failed to dispatch forwarded command")
+		return
+	}
+	if !delivered {
+		d.logger.WithFields(map[string]interface{}{
+			"device_id":  command.DeviceID,
+			"command_id": commandID,
+		}).Debug("Forwarded command left pending, device has no stream here")
+	}
+}
+
+// Drain stops delivering commands, leaving them pending for the gateway the
+// devices reconnect to, and waits until the commands already claimed are
+// sent, or until ctx is done. The commands sent over the streams being
+// handed off are then returned to pending, as devices do not acknowledge
+// them, so that the gateway a device reconnects to delivers them again.
+// Devices recognise a command delivered twice by its command ID.
+func (d *Dispatcher) Drain(ctx context.Context) error {
+	d.mutex.Lock()
+	d.draining = true
+	d.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("commands still being dispatched: %w", ctx.Err())
+	}
+
+	requeued, err := d.requeueSent(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to return sent commands to pending: %w", err)
+	}
+
+	d.logger.WithField("requeued", requeued).Info("Command dispatcher drained")
+	return nil
+}
+
+// requeueSent returns the commands sent over the attached streams that are
+// still executing to pending, returning how many were
+func (d *Dispatcher) requeueSent(ctx context.Context) (int, error) {
+	d.mutex.RLock()
+	var sent []string
+	for _, sender := range d.senders {
+		sent = append(sent, sender.sent...)
+	}
+	d.mutex.RUnlock()
+
+	requeued := 0
+	for _, id := range sent {
+		command, err := d.commands.GetByID(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return requeued, err
+		}
+		if command.Status != models.CommandStatusExecuting {
+			continue
+		}
+
+		if err := d.release(ctx, command); err != nil {
+			return requeued, err
+		}
+		requeued++
+	}
+
+	return requeued, nil
+}
+
+// DeliverPending dispatches the pending commands of a device, highest
+// priority first, returning how many were delivered
+func (d *Dispatcher) DeliverPending(ctx context.Context, deviceID string) (int, error) {
+	commands, err := d.commands.GetPendingCommands(ctx, deviceID)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, command := range commands {
+		sent, err := d.Dispatch(ctx, command)
+		if sent {
+			delivered++
+		}
+		if err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
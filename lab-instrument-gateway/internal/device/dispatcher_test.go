@@ -0,0 +1,116 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+func TestDispatcher_DispatchDeliversOnce(t *testing.T) {
+	commands := newTestCommands(t)
+	ctx := context.Background()
+	log := logger.NewDefaultLogger()
+
+	// The same command dispatched at once, e.g. on submission and by the
+	// reconnecting device's pending delivery, is sent to only one stream
+	var sent atomic.Int32
+	dispatchers := []*Dispatcher{NewDispatcher(commands, log), NewDispatcher(commands, log)}
+	for i, dispatcher := range dispatchers {
+		dispatcher.Attach("hplc-01", fmt.Sprintf("stream-%d", i), func(*models.Command) error {
+			sent.Add(1)
+			return nil
+		})
+	}
+
+	command := testCommand(t, commands, "command-1")
+	var wg sync.WaitGroup
+	var delivered atomic.Int32
+	for _, dispatcher := range dispatchers {
+		wg.Add(1)
+		go func(dispatcher *Dispatcher) {
+			defer wg.Done()
+			copied := *command
+			ok, err := dispatcher.Dispatch(ctx, &copied)
+			assert.NoError(t, err)
+			if ok {
+				delivered.Add(1)
+			}
+		}(dispatcher)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, delivered.Load())
+	assert.EqualValues(t, 1, sent.Load())
+	assert.Equal(t, models.CommandStatusExecuting, commandStatus(t, commands, "command-1"))
+}
+
+func TestDispatcher_DispatchReleasesUnsentCommand(t *testing.T) {
+	commands := newTestCommands(t)
+	ctx := context.Background()
+
+	dispatcher := NewDispatcher(commands, logger.NewDefaultLogger())
+	dispatcher.Attach("hplc-01", "stream-1", func(*models.Command) error {
+		return errors.New("stream closed")
+	})
+
+	command := testCommand(t, commands, "command-1")
+	delivered, err := dispatcher.Dispatch(ctx, command)
+	assert.Error(t, err)
+	assert.False(t, delivered)
+	assert.Equal(t, models.CommandStatusPending, commandStatus(t, commands, "command-1"))
+
+	// The command is delivered once the device attaches a working stream
+	dispatcher.Attach("hplc-01", "stream-2", func(*models.Command) error { return nil })
+	count, err := dispatcher.DeliverPending(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, models.CommandStatusExecuting, commandStatus(t, commands, "command-1"))
+}
+
+func TestDispatcher_DrainRequeuesSentCommands(t *testing.T) {
+	commands := newTestCommands(t)
+	ctx := context.Background()
+	log := logger.NewDefaultLogger()
+
+	draining := NewDispatcher(commands, log)
+	draining.Attach("hplc-01", "stream-1", func(*models.Command) error { return nil })
+
+	sent := testCommand(t, commands, "command-1")
+	completed := testCommand(t, commands, "command-2")
+	for _, command := range []*models.Command{sent, completed} {
+		delivered, err := draining.Dispatch(ctx, command)
+		require.NoError(t, err)
+		require.True(t, delivered)
+	}
+	require.NoError(t, commands.UpdateStatus(ctx, "command-2", models.CommandStatusCompleted))
+
+	// The unacknowledged command goes back to pending with the hand-off
+	require.NoError(t, draining.Drain(ctx))
+	assert.Equal(t, models.CommandStatusPending, commandStatus(t, commands, "command-1"))
+	assert.Equal(t, models.CommandStatusCompleted, commandStatus(t, commands, "command-2"))
+
+	count, err := draining.DeliverPending(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Zero(t, count, "a draining dispatcher delivers nothing")
+
+	// The replica the device reconnects to delivers it again
+	var redelivered []string
+	replica := NewDispatcher(commands, log)
+	replica.Attach("hplc-01", "stream-2", func(command *models.Command) error {
+		redelivered = append(redelivered, command.CommandID)
+		return nil
+	})
+	count, err = replica.DeliverPending(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"command-1"}, redelivered)
+}
@@ -0,0 +1,35 @@
+package device
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Command dispatch attempts to attached streams
+	commandsDispatchedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "commands_dispatched_total",
+			Help: "Total number of commands dispatched to devices, by type and result",
+		},
+		[]string{"type", "result"},
+	)
+
+	// Device status transitions not persisted because the queue was full
+	statusUpdatesDroppedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "device_status_updates_dropped_total",
+			Help: "Total number of device status transitions dropped before being persisted",
+		},
+	)
+
+	// Time commands wait between being queued and delivered
+	commandDispatchLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "command_dispatch_latency_seconds",
+			Help:    "Time from queueing a command to delivering it to its device in seconds",
+			Buckets: []float64{.005, .01, .05, .1, .5, 1, 5, 30, 60, 300, 1800, 3600},
+		},
+		[]string{"type"},
+	)
+)
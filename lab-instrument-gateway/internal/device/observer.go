@@ -0,0 +1,30 @@
+package device
+
+import (
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// StatusObserver is notified of device connectivity transitions. Methods are
+// called outside the connection manager lock, on the goroutine that detected
+// the transition.
+type StatusObserver interface {
+	// DeviceConnected is called with a copy of the session when a device
+	// registers a connection, or with resumed set when it resumes heartbeats
+	// on an existing session after timing out
+	DeviceConnected(session *models.DeviceSession, resumed bool)
+
+	// DeviceTimedOut is called when a connected device misses its heartbeat
+	// deadline
+	DeviceTimedOut(deviceID, sessionID string, lastHeartbeat time.Time)
+
+	// DeviceDisconnected is called when a connected device is disconnected
+	// explicitly
+	DeviceDisconnected(deviceID, sessionID, reason string)
+
+	// StreamOpened and StreamClosed are called when a device attaches or
+	// detaches a data stream
+	StreamOpened(deviceID, sessionID, streamID string)
+	StreamClosed(deviceID, sessionID, streamID, reason string)
+}
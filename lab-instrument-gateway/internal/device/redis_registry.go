@@ -0,0 +1,247 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRegistryConfig represents the Redis connection registry configuration
+type RedisRegistryConfig struct {
+	Addr     string
+	Password string
+	DB       int
+
+	// KeyPrefix namespaces the keys and channels of the gateway, so that
+	// several deployments can share a Redis instance
+	KeyPrefix string
+
+	// TTL is how long a registration outlives the last refresh, bounding
+	// how long the connections of a replica that died stay registered
+	TTL time.Duration
+}
+
+// SetDefaults sets default values for the Redis registry configuration
+func (c *RedisRegistryConfig) SetDefaults() {
+	if c.Addr == "" {
+		c.Addr = "localhost:6379"
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = "lab-gateway:"
+	}
+	if c.TTL <= 0 {
+		c.TTL = time.Minute
+	}
+}
+
+// Registrations are hashes holding the owning replica and the connection,
+// so that ownership can be compared atomically
+const (
+	registrationReplicaField    = "replica"
+	registrationConnectionField = "connection"
+)
+
+var (
+	// refreshScript updates a registration unless another replica owns it
+	refreshScript = redis.NewScript(`
+local owner = redis.call('HGET', KEYS[1], ARGV[1])
+if owner and owner ~= ARGV[2] then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2], ARGV[3], ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+return 1
+`)
+
+	// removeScript deletes a registration if the replica owns it
+	removeScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], ARGV[1]) == ARGV[2] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+)
+
+// RedisRegistry is a connection registry kept in Redis. Registrations expire
+// unless refreshed, and commands are forwarded over a pub/sub channel per
+// replica.
+type RedisRegistry struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// NewRedisRegistry creates a Redis connection registry. Redis is connected
+// to lazily; HealthCheck reports whether it can be reached.
+func NewRedisRegistry(config RedisRegistryConfig) *RedisRegistry {
+	config.SetDefaults()
+
+	return &RedisRegistry{
+		client: redis.NewClient(&redis.Options{
+			Addr:     config.Addr,
+			Password: config.Password,
+			DB:       config.DB,
+		}),
+		keyPrefix: config.KeyPrefix,
+		ttl:       config.TTL,
+	}
+}
+
+// Register records the connection as held by the replica
+func (r *RedisRegistry) Register(ctx context.Context, registration *Registration) error {
+	connection, err := json.Marshal(registration.Connection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connection: %w", err)
+	}
+
+	key := r.deviceKey(registration.Connection.DeviceID)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			registrationReplicaField, registration.ReplicaID,
+			registrationConnectionField, connection,
+		)
+		pipe.PExpire(ctx, key, r.ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register connection: %w", err)
+	}
+	return nil
+}
+
+// Refresh updates the connection unless another replica has taken it over
+func (r *RedisRegistry) Refresh(ctx context.Context, registration *Registration) error {
+	connection, err := json.Marshal(registration.Connection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connection: %w", err)
+	}
+
+	err = refreshScript.Run(ctx, r.client, []string{r.deviceKey(registration.Connection.DeviceID)},
+		registrationReplicaField, registration.ReplicaID,
+		registrationConnectionField, connection,
+		r.ttl.Milliseconds(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to refresh connection: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the registration of a device if the replica holds it
+func (r *RedisRegistry) Remove(ctx context.Context, deviceID, replicaID string) error {
+	err := removeScript.Run(ctx, r.client, []string{r.deviceKey(deviceID)},
+		registrationReplicaField, replicaID,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to remove connection: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the registration of a device, or nil if there is none
+func (r *RedisRegistry) Lookup(ctx context.Context, deviceID string) (*Registration, error) {
+	values, err := r.client.HMGet(ctx, r.deviceKey(deviceID),
+		registrationReplicaField, registrationConnectionField,
+	).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up connection: %w", err)
+	}
+
+	replicaID, _ := values[0].(string)
+	connection, _ := values[1].(string)
+	if replicaID == "" || connection == "" {
+		return nil, nil
+	}
+
+	registration := &Registration{ReplicaID: replicaID}
+	if err := json.Unmarshal([]byte(connection), &registration.Connection); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal connection: %w", err)
+	}
+	return registration, nil
+}
+
+// Forward publishes a command id on the channel of a replica
+func (r *RedisRegistry) Forward(ctx context.Context, replicaID, commandID string) error {
+	receivers, err := r.client.Publish(ctx, r.commandChannel(replicaID), commandID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to forward command: %w", err)
+	}
+	if receivers == 0 {
+		return fmt.Errorf("replica %s is not subscribed to commands", replicaID)
+	}
+	return nil
+}
+
+// Subscribe receives the commands published on the channel of a replica.
+// The subscription is confirmed before it is returned, so that no command
+// forwarded afterwards is missed.
+func (r *RedisRegistry) Subscribe(ctx context.Context, replicaID string) (Subscription, error) {
+	pubsub := r.client.Subscribe(ctx, r.commandChannel(replicaID))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to commands: %w", err)
+	}
+
+	subscription := &redisSubscription{
+		pubsub:   pubsub,
+		commands: make(chan string),
+	}
+	go subscription.receive()
+	return subscription, nil
+}
+
+// HealthCheck pings Redis
+func (r *RedisRegistry) HealthCheck(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis unreachable: %w", err)
+	}
+	return nil
+}
+
+// Close closes the Redis connections
+func (r *RedisRegistry) Close() error {
+	return r.client.Close()
+}
+
+// deviceKey returns the key of a device's registration
+func (r *RedisRegistry) deviceKey(deviceID string) string {
+	return r.keyPrefix + "device:" + deviceID
+}
+
+// commandChannel returns the channel commands are forwarded to a replica on
+func (r *RedisRegistry) commandChannel(replicaID string) string {
+	return r.keyPrefix + "replica:" + replicaID + ":commands"
+}
+
+// redisSubscription passes on the messages of a Redis subscription
+type redisSubscription struct {
+	pubsub   *redis.PubSub
+	commands chan string
+}
+
+// Commands returns the channel of forwarded command ids
+func (s *redisSubscription) Commands() <-chan string {
+	return s.commands
+}
+
+// Close ends the subscription, closing the commands channel
+func (s *redisSubscription) Close() error {
+	if err := s.pubsub.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// receive passes on messages until the subscription is closed. The client
+// resubscribes by itself after connection failures.
+func (s *redisSubscription) receive() {
+	defer close(s.commands)
+
+	for message := range s.pubsub.Channel() {
+		s.commands <- message.Payload
+	}
+}
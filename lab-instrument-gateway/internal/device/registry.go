@@ -0,0 +1,72 @@
+package device
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// registryTimeout bounds a single registry call made while tracking a
+// connection
+const registryTimeout = 2 * time.Second
+
+// Registration records the replica holding a device's connection, with a
+// snapshot of the connection status as that replica last saw it
+type Registration struct {
+	ReplicaID  string           `json:"replica_id"`
+	Connection ConnectionStatus `json:"connection"`
+}
+
+// Registry is the connection registry shared by the gateway replicas. It
+// records which replica holds the stream of each device, and carries the
+// commands submitted on one replica to the replica that holds the stream.
+type Registry interface {
+	// Register records the connection as held by the replica, taking it
+	// over from any other replica
+	Register(ctx context.Context, registration *Registration) error
+
+	// Refresh updates the connection and extends its registration, unless
+	// another replica has taken it over since
+	Refresh(ctx context.Context, registration *Registration) error
+
+	// Remove deletes the registration of a device if the replica holds it
+	Remove(ctx context.Context, deviceID, replicaID string) error
+
+	// Lookup returns the registration of a device, or nil if no replica
+	// holds its connection
+	Lookup(ctx context.Context, deviceID string) (*Registration, error)
+
+	// Forward asks a replica to deliver a stored command. Delivery is best
+	// effort: a command that is not forwarded stays pending and is delivered
+	// when the device next attaches a stream.
+	Forward(ctx context.Context, replicaID, commandID string) error
+
+	// Subscribe receives the ids of the commands forwarded to a replica
+	Subscribe(ctx context.Context, replicaID string) (Subscription, error)
+
+	// HealthCheck reports whether the registry can be reached
+	HealthCheck(ctx context.Context) error
+
+	// Close releases the registry's connections
+	Close() error
+}
+
+// Subscription receives the commands forwarded to a replica
+type Subscription interface {
+	// Commands returns the channel of forwarded command ids, closed when the
+	// subscription is closed
+	Commands() <-chan string
+
+	// Close ends the subscription
+	Close() error
+}
+
+// DefaultReplicaID returns the host name, which is the pod name when the
+// gateway runs in Kubernetes
+func DefaultReplicaID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "lab-gateway"
+	}
+	return hostname
+}
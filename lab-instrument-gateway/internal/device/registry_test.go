@@ -0,0 +1,212 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+)
+
+func newTestRegistry(t *testing.T) (*RedisRegistry, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	registry := NewRedisRegistry(RedisRegistryConfig{Addr: server.Addr(), TTL: time.Minute})
+	t.Cleanup(func() { registry.Close() })
+	return registry, server
+}
+
+func registrationOf(replicaID, deviceID, sessionID string) *Registration {
+	return &Registration{
+		ReplicaID:  replicaID,
+		Connection: ConnectionStatus{DeviceID: deviceID, SessionID: sessionID, IsConnected: true},
+	}
+}
+
+func TestRedisRegistry_Ownership(t *testing.T) {
+	registry, server := newTestRegistry(t)
+	ctx := context.Background()
+
+	require.NoError(t, registry.HealthCheck(ctx))
+
+	registration, err := registry.Lookup(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Nil(t, registration)
+
+	require.NoError(t, registry.Register(ctx, registrationOf("replica-a", "hplc-01", "session-1")))
+	registration, err = registry.Lookup(ctx, "hplc-01")
+	require.NoError(t, err)
+	require.NotNil(t, registration)
+	assert.Equal(t, "replica-a", registration.ReplicaID)
+	assert.Equal(t, "session-1", registration.Connection.SessionID)
+
+	// Another replica cannot refresh or remove the registration
+	require.NoError(t, registry.Refresh(ctx, registrationOf("replica-b", "hplc-01", "session-2")))
+	require.NoError(t, registry.Remove(ctx, "hplc-01", "replica-b"))
+	registration, err = registry.Lookup(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, "replica-a", registration.ReplicaID)
+
+	// ...until it takes the connection over
+	require.NoError(t, registry.Register(ctx, registrationOf("replica-b", "hplc-01", "session-2")))
+	require.NoError(t, registry.Remove(ctx, "hplc-01", "replica-a"))
+	registration, err = registry.Lookup(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, "replica-b", registration.ReplicaID)
+
+	require.NoError(t, registry.Remove(ctx, "hplc-01", "replica-b"))
+	registration, err = registry.Lookup(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Nil(t, registration)
+
+	// Registrations that are not refreshed expire
+	require.NoError(t, registry.Refresh(ctx, registrationOf("replica-a", "hplc-02", "session-3")))
+	server.FastForward(2 * time.Minute)
+	registration, err = registry.Lookup(ctx, "hplc-02")
+	require.NoError(t, err)
+	assert.Nil(t, registration)
+}
+
+func TestConnectionManager_Registry(t *testing.T) {
+	registry, _ := newTestRegistry(t)
+	ctx := context.Background()
+	log := logger.NewDefaultLogger()
+
+	// Replica A times its devices out quickly
+	observerA := &recordingObserver{}
+	replicaA := NewConnectionManager(Config{
+		HeartbeatTimeout: 50 * time.Millisecond,
+		CleanupInterval:  10 * time.Millisecond,
+		Observer:         observerA,
+		Registry:         registry,
+		ReplicaID:        "replica-a",
+	}, log)
+	defer replicaA.Close()
+	observerB := &recordingObserver{}
+	replicaB := NewConnectionManager(Config{Observer: observerB, Registry: registry, ReplicaID: "replica-b"}, log)
+	defer replicaB.Close()
+
+	session := newTestSession("hplc-01")
+	require.NoError(t, replicaA.RegisterConnection(ctx, session))
+	require.NoError(t, replicaA.AttachStream("hplc-01", "stream-1"))
+
+	registration, err := replicaB.LookupConnection(ctx, "hplc-01")
+	require.NoError(t, err)
+	require.NotNil(t, registration)
+	assert.Equal(t, "replica-a", registration.ReplicaID)
+	require.NotNil(t, registration.Connection.StreamID)
+	assert.Equal(t, "stream-1", *registration.Connection.StreamID)
+
+	// The device is handed off and resumes its session on replica B
+	require.NoError(t, replicaA.DetachStream("hplc-01", "gateway shutting down"))
+	resumed, err := replicaB.ResumeSession(ctx, "hplc-01", session.SessionID)
+	require.NoError(t, err)
+	require.NotNil(t, resumed)
+	assert.Equal(t, []string{session.SessionID}, observerB.resumed)
+	require.NoError(t, replicaB.AttachStream("hplc-01", "stream-2"))
+
+	registration, err = replicaA.LookupConnection(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, "replica-b", registration.ReplicaID)
+
+	// Unknown sessions are not taken over
+	unknown, err := replicaB.ResumeSession(ctx, "hplc-01", "other-session")
+	require.NoError(t, err)
+	assert.Nil(t, unknown)
+
+	// Replica A forgets the device instead of reporting it offline
+	require.Eventually(t, func() bool {
+		return replicaA.GetConnectionStatus("hplc-01") == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Zero(t, observerA.timedOutCount())
+
+	registration, err = registry.Lookup(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, "replica-b", registration.ReplicaID)
+}
+
+// newTestCommands returns the command repository of an in-memory repository
+// manager holding device hplc-01, shared by the replicas of a test
+func newTestCommands(t *testing.T) repository.CommandRepository {
+	repos := memory.NewRepositoryManager()
+	require.NoError(t, repos.Device().Create(context.Background(), &models.Device{
+		ID:     "hplc-01",
+		Name:   "HPLC 1",
+		Type:   "hplc",
+		Status: models.DeviceStatusOnline,
+	}))
+	return repos.Command()
+}
+
+// testCommand stores a pending command for device hplc-01
+func testCommand(t *testing.T, commands repository.CommandRepository, commandID string) *models.Command {
+	command := &models.Command{ID: uuid.New().String(), CommandID: commandID, DeviceID: "hplc-01", Type: "calibrate", TimeoutSeconds: 60}
+	command.SetDefaults()
+	require.NoError(t, commands.Create(context.Background(), command))
+	return command
+}
+
+// commandStatus returns the stored status of a command
+func commandStatus(t *testing.T, commands repository.CommandRepository, commandID string) models.CommandStatus {
+	command, err := commands.GetByCommandID(context.Background(), commandID)
+	require.NoError(t, err)
+	return command.Status
+}
+
+func TestDispatcher_Forward(t *testing.T) {
+	registry, _ := newTestRegistry(t)
+	ctx := context.Background()
+	log := logger.NewDefaultLogger()
+
+	commands := newTestCommands(t)
+	command := testCommand(t, commands, "command-1")
+
+	replicaA := NewDispatcher(commands, log)
+	replicaA.ForwardCommands(registry, "replica-a")
+	replicaB := NewDispatcher(commands, log)
+	replicaB.ForwardCommands(registry, "replica-b")
+	require.NoError(t, replicaB.Start(ctx))
+	defer replicaB.Close()
+
+	// Without a stream anywhere the command stays pending
+	forwarded, err := replicaA.Forward(ctx, command)
+	require.NoError(t, err)
+	assert.False(t, forwarded)
+
+	sent := make(chan string, 1)
+	replicaB.Attach("hplc-01", "stream-1", func(command *models.Command) error {
+		sent <- command.CommandID
+		return nil
+	})
+	streamID := "stream-1"
+	require.NoError(t, registry.Register(ctx, &Registration{
+		ReplicaID:  "replica-b",
+		Connection: ConnectionStatus{DeviceID: "hplc-01", StreamID: &streamID, IsConnected: true},
+	}))
+
+	forwarded, err = replicaA.Forward(ctx, command)
+	require.NoError(t, err)
+	assert.True(t, forwarded)
+
+	select {
+	case commandID := <-sent:
+		assert.Equal(t, "command-1", commandID)
+	case <-time.After(time.Second):
+		t.Fatal("forwarded command was not delivered")
+	}
+	require.Eventually(t, func() bool {
+		return commandStatus(t, commands, "command-1") == models.CommandStatusExecuting
+	}, time.Second, 10*time.Millisecond)
+
+	// Replica B does not forward to itself
+	forwarded, err = replicaB.Forward(ctx, command)
+	require.NoError(t, err)
+	assert.False(t, forwarded)
+}
@@ -0,0 +1,281 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/internal/alerting"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// statusUpdateTimeout bounds the database work done for a single transition
+const statusUpdateTimeout = 10 * time.Second
+
+// statusQueueSize bounds the transitions waiting to be persisted
+const statusQueueSize = 1024
+
+// StatusTracker is a StatusObserver that persists device status transitions,
+// connection sessions and the device event log, and raises device-offline
+// alerts, resolving them when the device returns. Transitions are queued and
+// persisted in order by a single goroutine, so that a slow database does not
+// hold up the heartbeat sweep or the calls that report them; when the queue
+// is full, transitions are dropped.
+type StatusTracker struct {
+	repos            repository.RepositoryManager
+	alerts           *alerting.Manager
+	heartbeatTimeout time.Duration
+	logger           *logger.Logger
+
+	updates  chan func(ctx context.Context)
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewStatusTracker creates a new status tracker and starts persisting
+// transitions
+func NewStatusTracker(repos repository.RepositoryManager, alerts *alerting.Manager, heartbeatTimeout time.Duration, logger *logger.Logger) *StatusTracker {
+	tracker := &StatusTracker{
+		repos:            repos,
+		alerts:           alerts,
+		heartbeatTimeout: heartbeatTimeout,
+		logger:           logger,
+		updates:          make(chan func(ctx context.Context), statusQueueSize),
+		stopChan:         make(chan struct{}),
+		doneChan:         make(chan struct{}),
+	}
+
+	go tracker.updateRoutine()
+
+	return tracker
+}
+
+// Close persists the transitions already queued and stops the tracker;
+// later transitions are dropped
+func (t *StatusTracker) Close() error {
+	close(t.stopChan)
+
+	select {
+	case <-t.doneChan:
+		return nil
+	case <-time.After(statusUpdateTimeout):
+		return fmt.Errorf("%d device status updates were not persisted in time", len(t.updates))
+	}
+}
+
+// updateRoutine persists queued transitions until the tracker is closed,
+// then persists the ones still queued
+func (t *StatusTracker) updateRoutine() {
+	defer close(t.doneChan)
+
+	for {
+		select {
+		case update := <-t.updates:
+			t.apply(update)
+		case <-t.stopChan:
+			for {
+				select {
+				case update := <-t.updates:
+					t.apply(update)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// apply runs the database work for one transition
+func (t *StatusTracker) apply(update func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.Background(), statusUpdateTimeout)
+	defer cancel()
+
+	update(ctx)
+}
+
+// enqueue queues the database work for a transition without blocking
+func (t *StatusTracker) enqueue(deviceID string, update func(ctx context.Context)) {
+	select {
+	case <-t.stopChan:
+		statusUpdatesDroppedTotal.Inc()
+		t.logger.WithField("device_id", deviceID).Warn("Status tracker is closed, dropping device status update")
+		return
+	default:
+	}
+
+	select {
+	case t.updates <- update:
+	default:
+		statusUpdatesDroppedTotal.Inc()
+		t.logger.WithField("device_id", deviceID).Error("Device status update queue is full, dropping update")
+	}
+}
+
+// DeviceConnected persists the session, marks the device online and resolves
+// its offline alerts. A session that already has an ID was persisted by the
+// registration that opened it.
+func (t *StatusTracker) DeviceConnected(session *models.DeviceSession, resumed bool) {
+	connectedAt := time.Now()
+	t.enqueue(session.DeviceID, func(ctx context.Context) {
+		deviceID := session.DeviceID
+		if resumed {
+			if err := t.repos.DeviceSession().Resume(ctx, session.SessionID, session.LastHeartbeat); err != nil {
+				t.logger.WithError(err).WithField("session_id", session.SessionID).Warn("Failed to resume device session")
+			}
+			t.recordEvent(ctx, &models.DeviceEvent{
+				DeviceID:  deviceID,
+				Type:      models.DeviceEventHeartbeatResumed,
+				SessionID: &session.SessionID,
+				Message:   "Heartbeats resumed",
+			})
+		} else {
+			if session.ID == "" {
+				if err := t.repos.DeviceSession().Create(ctx, session); err != nil {
+					t.logger.WithError(err).WithField("session_id", session.SessionID).Warn("Failed to persist device session")
+				}
+			}
+			t.recordEvent(ctx, &models.DeviceEvent{
+				DeviceID:   deviceID,
+				Type:       models.DeviceEventRegistered,
+				SessionID:  &session.SessionID,
+				Message:    "Device registered",
+				OccurredAt: session.ConnectedAt,
+			})
+		}
+
+		t.updateStatus(ctx, deviceID, &session.SessionID, models.DeviceStatusOnline, connectedAt)
+
+		if _, err := t.alerts.ResolveActive(ctx, deviceID, models.AlertTypeDeviceOffline); err != nil {
+			t.logger.WithError(err).WithField("device_id", deviceID).Error("Failed to resolve device offline alerts")
+		}
+	})
+}
+
+// DeviceTimedOut ends the session, marks the device offline and raises an
+// offline alert
+func (t *StatusTracker) DeviceTimedOut(deviceID, sessionID string, lastHeartbeat time.Time) {
+	t.enqueue(deviceID, func(ctx context.Context) {
+		// The device was last known alive at its final heartbeat
+		if err := t.repos.DeviceSession().End(ctx, sessionID, lastHeartbeat, "heartbeat timeout"); err != nil {
+			t.logger.WithError(err).WithField("session_id", sessionID).Warn("Failed to end device session")
+		}
+		t.recordEvent(ctx, &models.DeviceEvent{
+			DeviceID:  deviceID,
+			Type:      models.DeviceEventHeartbeatLost,
+			SessionID: &sessionID,
+			Message:   fmt.Sprintf("No heartbeat for more than %s", t.heartbeatTimeout),
+			Metadata: map[string]interface{}{
+				"last_heartbeat": lastHeartbeat.UTC().Format(time.RFC3339),
+			},
+		})
+
+		t.updateStatus(ctx, deviceID, &sessionID, models.DeviceStatusOffline, lastHeartbeat)
+
+		alert := &models.Alert{
+			DeviceID: &deviceID,
+			Type:     models.AlertTypeDeviceOffline,
+			Severity: models.AlertSeverityError,
+			Message:  fmt.Sprintf("Device %s missed heartbeats for more than %s", deviceID, t.heartbeatTimeout),
+			Metadata: map[string]interface{}{
+				"last_heartbeat":    lastHeartbeat.UTC().Format(time.RFC3339),
+				"heartbeat_timeout": t.heartbeatTimeout.String(),
+			},
+		}
+
+		if err := t.alerts.Raise(ctx, alert); err != nil {
+			t.logger.WithError(err).WithField("device_id", deviceID).Error("Failed to raise device offline alert")
+		}
+	})
+}
+
+// DeviceDisconnected ends the session and marks the device offline. A
+// deliberate disconnect is not an incident, so no alert is raised.
+func (t *StatusTracker) DeviceDisconnected(deviceID, sessionID, reason string) {
+	disconnectedAt := time.Now()
+	t.enqueue(deviceID, func(ctx context.Context) {
+		if err := t.repos.DeviceSession().End(ctx, sessionID, disconnectedAt, reason); err != nil {
+			t.logger.WithError(err).WithField("session_id", sessionID).Warn("Failed to end device session")
+		}
+		t.recordEvent(ctx, &models.DeviceEvent{
+			DeviceID:  deviceID,
+			Type:      models.DeviceEventDisconnected,
+			SessionID: &sessionID,
+			Message:   reason,
+		})
+
+		t.updateStatus(ctx, deviceID, &sessionID, models.DeviceStatusOffline, disconnectedAt)
+	})
+}
+
+// StreamOpened records a stream open event
+func (t *StatusTracker) StreamOpened(deviceID, sessionID, streamID string) {
+	t.enqueue(deviceID, func(ctx context.Context) {
+		t.recordEvent(ctx, &models.DeviceEvent{
+			DeviceID:  deviceID,
+			Type:      models.DeviceEventStreamOpened,
+			SessionID: &sessionID,
+			Metadata:  map[string]interface{}{"stream_id": streamID},
+		})
+	})
+}
+
+// StreamClosed records a stream close event
+func (t *StatusTracker) StreamClosed(deviceID, sessionID, streamID, reason string) {
+	t.enqueue(deviceID, func(ctx context.Context) {
+		t.recordEvent(ctx, &models.DeviceEvent{
+			DeviceID:  deviceID,
+			Type:      models.DeviceEventStreamClosed,
+			SessionID: &sessionID,
+			Message:   reason,
+			Metadata:  map[string]interface{}{"stream_id": streamID},
+		})
+	})
+}
+
+// updateStatus writes the device status and records the change as of the
+// given time, unless the device is in maintenance, which is an
+// operator-controlled state that connectivity must not override
+func (t *StatusTracker) updateStatus(ctx context.Context, deviceID string, sessionID *string, status models.DeviceStatus, at time.Time) {
+	device, err := t.repos.Device().GetByID(ctx, deviceID)
+	if err != nil {
+		t.logger.WithError(err).WithField("device_id", deviceID).Warn("Failed to load device for status update")
+		return
+	}
+
+	if device.Status == status || device.Status == models.DeviceStatusMaintenance {
+		return
+	}
+
+	if err := t.repos.Device().UpdateStatus(ctx, deviceID, status); err != nil {
+		t.logger.WithError(err).WithField("device_id", deviceID).Error("Failed to update device status")
+		return
+	}
+
+	from := device.Status
+	t.recordEvent(ctx, &models.DeviceEvent{
+		DeviceID:   deviceID,
+		Type:       models.DeviceEventStatusChanged,
+		SessionID:  sessionID,
+		FromStatus: &from,
+		ToStatus:   &status,
+		OccurredAt: at,
+	})
+
+	t.logger.WithFields(map[string]interface{}{
+		"device_id": deviceID,
+		"from":      from,
+		"to":        status,
+	}).Info("Device status changed")
+}
+
+// recordEvent appends to the device event log, logging failures
+func (t *StatusTracker) recordEvent(ctx context.Context, event *models.DeviceEvent) {
+	if err := t.repos.DeviceEvent().Create(ctx, event); err != nil {
+		t.logger.WithError(err).WithFields(map[string]interface{}{
+			"device_id": event.DeviceID,
+			"type":      event.Type,
+		}).Warn("Failed to record device event")
+	}
+}
@@ -0,0 +1,78 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/internal/alerting"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+)
+
+// stalledDevices is a device repository whose status updates wait until
+// released, as with an overloaded database
+type stalledDevices struct {
+	repository.DeviceRepository
+	release chan struct{}
+}
+
+func (d *stalledDevices) UpdateStatus(ctx context.Context, id string, status models.DeviceStatus) error {
+	<-d.release
+	return d.DeviceRepository.UpdateStatus(ctx, id, status)
+}
+
+type stalledRepos struct {
+	repository.RepositoryManager
+	devices *stalledDevices
+}
+
+func (r *stalledRepos) Device() repository.DeviceRepository { return r.devices }
+
+func TestStatusTracker_SlowDatabaseDoesNotBlockObservers(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewDefaultLogger()
+	repos := memory.NewRepositoryManager()
+	for _, deviceID := range []string{"hplc-01", "hplc-02"} {
+		require.NoError(t, repos.Device().Create(ctx, &models.Device{
+			ID:     deviceID,
+			Name:   deviceID,
+			Type:   "analyzer",
+			Status: models.DeviceStatusOnline,
+		}))
+	}
+
+	stalled := &stalledRepos{RepositoryManager: repos, devices: &stalledDevices{DeviceRepository: repos.Device(), release: make(chan struct{})}}
+	tracker := NewStatusTracker(stalled, alerting.NewManager(repos, nil, log), time.Minute, log)
+
+	// The heartbeat sweep reports timeouts without waiting for the database
+	notified := make(chan struct{})
+	go func() {
+		defer close(notified)
+		tracker.DeviceTimedOut("hplc-01", "session-1", time.Now())
+		tracker.DeviceTimedOut("hplc-02", "session-2", time.Now())
+	}()
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("observer calls blocked on the database")
+	}
+
+	// Queued transitions are persisted, in order, before Close returns
+	close(stalled.devices.release)
+	require.NoError(t, tracker.Close())
+	for _, deviceID := range []string{"hplc-01", "hplc-02"} {
+		device, err := repos.Device().GetByID(ctx, deviceID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DeviceStatusOffline, device.Status, deviceID)
+	}
+
+	alerts, err := repos.Alert().Count(ctx, repository.AlertFilter{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, alerts)
+}
@@ -0,0 +1,211 @@
+package esign
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// signerToken returns a token for the subject issued age ago
+func signerToken(t *testing.T, subject string, age time.Duration) string {
+	issuedAt := time.Now().Add(-age)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+		},
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+// newTestService returns a signature service over an in-memory repository
+// manager holding the device the test commands are for
+func newTestService(t *testing.T, requirements string) (*Service, repository.RepositoryManager) {
+	parsed, err := ParseRequirements(requirements)
+	require.NoError(t, err)
+
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{Secret: testSecret})
+	require.NoError(t, err)
+
+	repos := memory.NewRepositoryManager()
+	require.NoError(t, repos.Device().Create(context.Background(), &models.Device{
+		ID:     "hplc-01",
+		Name:   "HPLC 1",
+		Type:   "hplc",
+		Status: models.DeviceStatusOnline,
+	}))
+
+	return NewService(Config{Requirements: parsed}, repos.Command(), auth.NewAuthenticator(verifier, nil, false), nil, logger.NewDefaultLogger()), repos
+}
+
+func testCommand(commandType string) *models.Command {
+	return &models.Command{
+		ID:         "7b0e2c1e-4d51-4f57-9a3c-0d6f3f0b9a10",
+		DeviceID:   "hplc-01",
+		CommandID:  "cmd-1",
+		Type:       commandType,
+		Parameters: map[string]interface{}{"method": "assay-42"},
+	}
+}
+
+func TestParseRequirements(t *testing.T) {
+	requirements, err := ParseRequirements(" hplc:start_run=1, *:release_batch=2 ")
+	require.NoError(t, err)
+	assert.Equal(t, []Requirement{
+		{DeviceType: "hplc", CommandType: "start_run", Signatures: 1},
+		{DeviceType: "*", CommandType: "release_batch", Signatures: 2},
+	}, requirements)
+
+	requirements, err = ParseRequirements("")
+	require.NoError(t, err)
+	assert.Empty(t, requirements)
+
+	for _, invalid := range []string{"start_run=1", "hplc:start_run", "hplc:start_run=0", "hplc:start_run=3", ":start_run=1", "hplc:=1"} {
+		_, err := ParseRequirements(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPolicy_Required(t *testing.T) {
+	policy := NewPolicy([]Requirement{
+		{DeviceType: "*", CommandType: "release_batch", Signatures: 2},
+		{DeviceType: "balance", CommandType: "release_batch", Signatures: 1},
+		{DeviceType: "hplc", CommandType: "start_run", Signatures: 1},
+	})
+
+	assert.Equal(t, 1, policy.Required("hplc", "start_run"))
+	assert.Equal(t, 0, policy.Required("balance", "start_run"))
+	assert.Equal(t, 2, policy.Required("hplc", "release_batch"))
+	assert.Equal(t, 1, policy.Required("balance", "release_batch"), "device type requirement overrides the wildcard")
+}
+
+func TestService_Sign(t *testing.T) {
+	service, _ := newTestService(t, "hplc:release_batch=2,hplc:start_run=1")
+	ctx := context.Background()
+
+	t.Run("no signatures needed", func(t *testing.T) {
+		signatures, err := service.Sign(ctx, "hplc", testCommand("calibrate"), nil)
+		require.NoError(t, err)
+		assert.Empty(t, signatures)
+	})
+
+	t.Run("two signers", func(t *testing.T) {
+		command := testCommand("release_batch")
+		signatures, err := service.Sign(ctx, "hplc", command, []Request{
+			{Credential: signerToken(t, "alice", time.Minute), Meaning: models.SignatureMeaningReviewed},
+			{Credential: signerToken(t, "bob", time.Minute), Meaning: models.SignatureMeaningApproved, Comment: " batch 17 "},
+		})
+		require.NoError(t, err)
+		require.Len(t, signatures, 2)
+
+		hash, err := command.ContentHash()
+		require.NoError(t, err)
+		assert.Equal(t, "alice", signatures[0].Signer)
+		assert.Equal(t, models.SignatureMeaningReviewed, signatures[0].Meaning)
+		assert.Equal(t, "bob", signatures[1].Signer)
+		assert.Equal(t, "batch 17", signatures[1].Comment)
+		for _, signature := range signatures {
+			assert.Equal(t, command.ID, signature.CommandID)
+			assert.Equal(t, string(auth.MethodJWT), signature.AuthMethod)
+			assert.Equal(t, hash, signature.ContentHash)
+		}
+	})
+
+	t.Run("too few signatures", func(t *testing.T) {
+		_, err := service.Sign(ctx, "hplc", testCommand("release_batch"), []Request{
+			{Credential: signerToken(t, "alice", time.Minute), Meaning: models.SignatureMeaningApproved},
+		})
+		assert.ErrorIs(t, err, ErrSignaturesRequired)
+	})
+
+	t.Run("same signer twice", func(t *testing.T) {
+		_, err := service.Sign(ctx, "hplc", testCommand("release_batch"), []Request{
+			{Credential: signerToken(t, "alice", time.Minute), Meaning: models.SignatureMeaningReviewed},
+			{Credential: signerToken(t, "alice", 0), Meaning: models.SignatureMeaningApproved},
+		})
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("stale credential", func(t *testing.T) {
+		_, err := service.Sign(ctx, "hplc", testCommand("start_run"), []Request{
+			{Credential: signerToken(t, "alice", 30*time.Minute), Meaning: models.SignatureMeaningApproved},
+		})
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("unknown meaning", func(t *testing.T) {
+		_, err := service.Sign(ctx, "hplc", testCommand("start_run"), []Request{
+			{Credential: signerToken(t, "alice", time.Minute), Meaning: "witnessed"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestService_SignWithoutAuthentication(t *testing.T) {
+	parsed, err := ParseRequirements("*:start_run=1")
+	require.NoError(t, err)
+	service := NewService(Config{Requirements: parsed}, memory.NewRepositoryManager().Command(), nil, nil, logger.NewDefaultLogger())
+
+	_, err = service.Sign(context.Background(), "hplc", testCommand("start_run"), []Request{
+		{Credential: signerToken(t, "alice", time.Minute), Meaning: models.SignatureMeaningApproved},
+	})
+	assert.ErrorIs(t, err, ErrSigningUnavailable)
+
+	_, err = service.Sign(context.Background(), "hplc", testCommand("calibrate"), nil)
+	assert.NoError(t, err)
+}
+
+func TestService_SignRejectsReusedCredential(t *testing.T) {
+	service, repos := newTestService(t, "hplc:start_run=1")
+	ctx := context.Background()
+	credential := signerToken(t, "alice", time.Minute)
+
+	first := testCommand("start_run")
+	first.SetDefaults()
+	signatures, err := service.Sign(ctx, "hplc", first, []Request{{Credential: credential, Meaning: models.SignatureMeaningApproved}})
+	require.NoError(t, err)
+	first.Signatures = signatures
+	require.NoError(t, repos.Command().Create(ctx, first))
+
+	second := testCommand("start_run")
+	second.ID = "0d3c9b1a-6f2e-4a8b-9c7d-5e4f3a2b1c0d"
+	second.CommandID = "cmd-2"
+	_, err = service.Sign(ctx, "hplc", second, []Request{{Credential: credential, Meaning: models.SignatureMeaningApproved}})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// A signer who authenticates again may sign
+	_, err = service.Sign(ctx, "hplc", second, []Request{{Credential: signerToken(t, "alice", 0), Meaning: models.SignatureMeaningApproved}})
+	assert.NoError(t, err)
+
+	// The store refuses a credential that raced past the check
+	second.Signatures = signatures
+	assert.Error(t, repos.Command().Create(ctx, second))
+
+	// Tokens without an ID cannot be checked for reuse
+	anonymous, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "bob",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	_, err = service.Sign(ctx, "hplc", second, []Request{{Credential: anonymous, Meaning: models.SignatureMeaningApproved}})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
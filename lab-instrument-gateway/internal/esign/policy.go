@@ -0,0 +1,85 @@
+package esign
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AnyDeviceType matches every device type in a signature requirement
+const AnyDeviceType = "*"
+
+// MaxSignatures is the most signatures a requirement may ask for
+const MaxSignatures = 2
+
+// Requirement is the number of signatures a command type needs before it is
+// dispatched to devices of a type
+type Requirement struct {
+	DeviceType  string
+	CommandType string
+	Signatures  int
+}
+
+// ParseRequirements parses signature requirements written as a comma
+// separated list of deviceType:commandType=signatures, such as
+// hplc:start_run=1,*:release_batch=2. A device type of * applies to every
+// device type without a requirement of its own for the command.
+func ParseRequirements(value string) ([]Requirement, error) {
+	var requirements []Requirement
+	if strings.TrimSpace(value) == "" {
+		return requirements, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		target, count, ok := strings.Cut(entry, "=")
+		deviceType, commandType, typed := strings.Cut(strings.TrimSpace(target), ":")
+		deviceType = strings.TrimSpace(deviceType)
+		commandType = strings.TrimSpace(commandType)
+		if !ok || !typed || deviceType == "" || commandType == "" {
+			return nil, fmt.Errorf("invalid signature requirement %q: expected deviceType:commandType=signatures", entry)
+		}
+
+		signatures, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || signatures < 1 || signatures > MaxSignatures {
+			return nil, fmt.Errorf("invalid signature requirement %q: signatures must be between 1 and %d", entry, MaxSignatures)
+		}
+
+		requirements = append(requirements, Requirement{
+			DeviceType:  deviceType,
+			CommandType: commandType,
+			Signatures:  signatures,
+		})
+	}
+
+	return requirements, nil
+}
+
+// policyKey identifies the requirement for a command type on a device type
+type policyKey struct {
+	deviceType  string
+	commandType string
+}
+
+// Policy answers how many signatures a command needs
+type Policy struct {
+	requirements map[policyKey]int
+}
+
+// NewPolicy creates a policy from signature requirements. A later
+// requirement for the same device and command type replaces an earlier one.
+func NewPolicy(requirements []Requirement) *Policy {
+	policy := &Policy{requirements: make(map[policyKey]int, len(requirements))}
+	for _, requirement := range requirements {
+		policy.requirements[policyKey{requirement.DeviceType, requirement.CommandType}] = requirement.Signatures
+	}
+	return policy
+}
+
+// Required returns the number of signatures a command type needs on a device
+// type, preferring a requirement for the device type over a wildcard one
+func (p *Policy) Required(deviceType, commandType string) int {
+	if signatures, ok := p.requirements[policyKey{deviceType, commandType}]; ok {
+		return signatures
+	}
+	return p.requirements[policyKey{AnyDeviceType, commandType}]
+}
@@ -0,0 +1,204 @@
+// Package esign implements electronic signatures on critical commands. A
+// signature is a person authenticating again at the time of signing, plus the
+// meaning of their signature, bound to the content of the command they sign.
+package esign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/rbac"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// Signature errors
+var (
+	ErrSignaturesRequired = errors.New("electronic signatures required")
+	ErrInvalidSignature   = errors.New("invalid electronic signature")
+	ErrSignerNotPermitted = errors.New("signer is not permitted to sign the command")
+	ErrSigningUnavailable = errors.New("electronic signatures require bearer token authentication")
+)
+
+// DefaultMaxCredentialAge is how recently a signer must have authenticated
+const DefaultMaxCredentialAge = 5 * time.Minute
+
+// maxCommentLength bounds the comment a signer may add
+const maxCommentLength = 1000
+
+// Config represents the electronic signature configuration
+type Config struct {
+	// Requirements lists the command types that need signatures
+	Requirements []Requirement
+
+	// MaxCredentialAge is how long before signing the signer's token may
+	// have been issued
+	MaxCredentialAge time.Duration
+}
+
+// SetDefaults sets default values for the signature configuration
+func (c *Config) SetDefaults() {
+	if c.MaxCredentialAge <= 0 {
+		c.MaxCredentialAge = DefaultMaxCredentialAge
+	}
+}
+
+// Request is a signature submitted with a command
+type Request struct {
+	// Credential is a bearer token the signer obtained by authenticating
+	// again to sign; it is verified and discarded
+	Credential string
+	Meaning    models.SignatureMeaning
+	Comment    string
+}
+
+// Service verifies the electronic signatures submitted with commands
+type Service struct {
+	policy        *Policy
+	commands      repository.CommandRepository
+	authenticator *auth.Authenticator
+	authorizer    *rbac.Authorizer
+	config        Config
+	logger        *logger.Logger
+}
+
+// NewService creates a new signature service, checking the credentials of
+// earlier signatures in commands. authenticator may be nil if authentication
+// is disabled, in which case commands that need signatures are refused;
+// authorizer may be nil if RBAC is disabled, in which case any authenticated
+// person may sign.
+func NewService(config Config, commands repository.CommandRepository, authenticator *auth.Authenticator, authorizer *rbac.Authorizer, logger *logger.Logger) *Service {
+	config.SetDefaults()
+
+	return &Service{
+		policy:        NewPolicy(config.Requirements),
+		commands:      commands,
+		authenticator: authenticator,
+		authorizer:    authorizer,
+		config:        config,
+		logger:        logger,
+	}
+}
+
+// Required returns the number of signatures a command type needs on a
+// device type
+func (s *Service) Required(deviceType, commandType string) int {
+	return s.policy.Required(deviceType, commandType)
+}
+
+// Sign verifies the signatures submitted with a command for a device of the
+// given type and returns them as signature records. It fails unless there
+// are at least as many valid signatures, by distinct signers, as the policy
+// requires. Each credential signs once: a token a signer has already signed
+// with is rejected, and the store refuses a token used by concurrent
+// submissions. Signatures on commands that need none are verified and kept
+// too.
+func (s *Service) Sign(ctx context.Context, deviceType string, command *models.Command, requests []Request) ([]*models.CommandSignature, error) {
+	required := s.policy.Required(deviceType, command.Type)
+	if len(requests) < required {
+		return nil, fmt.Errorf("%w: %s commands for %s devices need %d signatures, %d given",
+			ErrSignaturesRequired, command.Type, deviceType, required, len(requests))
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	if s.authenticator == nil {
+		return nil, ErrSigningUnavailable
+	}
+
+	contentHash, err := command.ContentHash()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash signed command: %w", err)
+	}
+
+	signedAt := time.Now()
+	signers := make(map[string]bool, len(requests))
+	signatures := make([]*models.CommandSignature, 0, len(requests))
+
+	for i, request := range requests {
+		if !request.Meaning.IsValid() {
+			return nil, fmt.Errorf("%w: signature %d has unknown meaning %q", ErrInvalidSignature, i+1, request.Meaning)
+		}
+
+		comment := strings.TrimSpace(request.Comment)
+		if len(comment) > maxCommentLength {
+			return nil, fmt.Errorf("%w: signature %d comment too long (max %d characters)", ErrInvalidSignature, i+1, maxCommentLength)
+		}
+
+		identity, err := s.authenticator.Reauthenticate(request.Credential, s.config.MaxCredentialAge)
+		if err != nil {
+			s.logger.WithFields(map[string]interface{}{
+				"device_id":    command.DeviceID,
+				"command_type": command.Type,
+			}).WithError(err).Warn("Rejected command signature credential")
+			return nil, fmt.Errorf("%w: signature %d: %v", ErrInvalidSignature, i+1, err)
+		}
+
+		if signers[identity.Subject] {
+			return nil, fmt.Errorf("%w: %s signed more than once", ErrInvalidSignature, identity.Subject)
+		}
+		signers[identity.Subject] = true
+
+		// Without a token ID a reused credential could not be told apart
+		if identity.TokenID == "" {
+			return nil, fmt.Errorf("%w: signature %d: credential has no token ID (jti claim)", ErrInvalidSignature, i+1)
+		}
+
+		used, err := s.commands.SignatureTokenUsed(ctx, identity.Subject, identity.TokenID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check signature credential: %w", err)
+		}
+		if used {
+			s.logger.WithFields(map[string]interface{}{
+				"device_id":    command.DeviceID,
+				"command_type": command.Type,
+				"signer":       identity.Subject,
+			}).Warn("Rejected reused command signature credential")
+			return nil, fmt.Errorf("%w: signature %d: credential has already been used to sign, authenticate again", ErrInvalidSignature, i+1)
+		}
+
+		if err := s.authorize(ctx, identity, command.DeviceID); err != nil {
+			return nil, err
+		}
+
+		signatures = append(signatures, &models.CommandSignature{
+			CommandID:   command.ID,
+			Signer:      identity.Subject,
+			AuthMethod:  string(identity.Method),
+			Meaning:     request.Meaning,
+			Comment:     comment,
+			ContentHash: contentHash,
+			SignedAt:    signedAt,
+			TokenID:     identity.TokenID,
+		})
+	}
+
+	return signatures, nil
+}
+
+// authorize checks that a signer holds commands:sign for the device
+func (s *Service) authorize(ctx context.Context, identity *auth.Identity, deviceID string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	grants, err := s.authorizer.Grants(ctx, identity)
+	if err != nil {
+		return fmt.Errorf("failed to load signer grants: %w", err)
+	}
+
+	if err := s.authorizer.AuthorizeDevice(ctx, grants, models.PermissionCommandsSign, deviceID); err != nil {
+		if errors.Is(err, rbac.ErrPermissionDenied) {
+			return fmt.Errorf("%w: %s lacks %s for device %s", ErrSignerNotPermitted, identity.Subject, models.PermissionCommandsSign, deviceID)
+		}
+		return err
+	}
+
+	return nil
+}
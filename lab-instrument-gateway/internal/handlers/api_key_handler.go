@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/rbac"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// APIKeyHandler handles API key management gRPC operations. Administrators
+// whose access:manage grant is limited to device groups may only manage keys
+// scoped to a subset of those groups. Keys cannot be managed while role-based
+// access control is disabled.
+type APIKeyHandler struct {
+	repos  repository.RepositoryManager
+	logger *logger.Logger
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(repos repository.RepositoryManager, logger *logger.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		repos:  repos,
+		logger: logger,
+	}
+}
+
+// CreateAPIKey handles API key creation requests
+func (h *APIKeyHandler) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	if err := h.validateCreateAPIKeyRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	groups, all, err := h.manageableGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !all {
+		if len(req.DeviceGroups) == 0 {
+			return nil, status.Error(codes.PermissionDenied, "keys for all devices require a global access:manage grant")
+		}
+		for _, group := range req.DeviceGroups {
+			if !slices.Contains(groups, group) {
+				return nil, status.Errorf(codes.PermissionDenied, "access:manage required in device group %q", group)
+			}
+		}
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate API key")
+		return nil, status.Error(codes.Internal, "Failed to create API key")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	subject := strings.TrimSpace(req.Subject)
+	if subject == "" {
+		subject = name
+	}
+	createdBy := auth.Subject(ctx)
+	if createdBy == "" {
+		createdBy = "anonymous"
+	}
+
+	apiKey := &models.APIKey{
+		Prefix:       prefix,
+		KeyHash:      hash,
+		Name:         name,
+		Subject:      subject,
+		Roles:        req.Roles,
+		DeviceGroups: req.DeviceGroups,
+		CreatedBy:    createdBy,
+	}
+	if req.TtlSeconds > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.TtlSeconds) * time.Second)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	// The key is usable only once its audit entry is written
+	err = h.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if err := repos.APIKey().Create(ctx, apiKey); err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+		return audit.CommitChange(ctx, repos.Audit(), apiKey.ID, nil, apiKey)
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("name", name).Error("Failed to create API key")
+		return nil, status.Error(codes.Internal, "Failed to create API key")
+	}
+
+	return &pb.CreateAPIKeyResponse{
+		ApiKey: key,
+		Key:    h.convertAPIKeyToProto(apiKey),
+	}, nil
+}
+
+// ListAPIKeys handles API key listing requests. Key hashes are never returned.
+func (h *APIKeyHandler) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	if req.PageSize < 0 || req.PageSize > 1000 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 0 and 1000")
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 50
+	}
+
+	offset, err := decodePageOffset(req.PageToken)
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid page token")
+		return nil, status.Error(codes.InvalidArgument, "Invalid page token")
+	}
+
+	filter := repository.APIKeyFilter{
+		Filter: repository.Filter{
+			Limit:  int(req.PageSize),
+			Offset: offset,
+		},
+		Subject:        strings.TrimSpace(req.Subject),
+		IncludeRevoked: req.IncludeRevoked,
+	}
+	groups, all, err := h.manageableGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !all {
+		filter.WithinGroups = groups
+	}
+
+	keys, err := h.repos.APIKey().List(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list API keys")
+		return nil, status.Error(codes.Internal, "Failed to retrieve API keys")
+	}
+
+	totalCount, err := h.repos.APIKey().Count(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to count API keys")
+		return nil, status.Error(codes.Internal, "Failed to count API keys")
+	}
+
+	protoKeys := make([]*pb.APIKey, len(keys))
+	for i, key := range keys {
+		protoKeys[i] = h.convertAPIKeyToProto(key)
+	}
+
+	return &pb.ListAPIKeysResponse{
+		Keys:          protoKeys,
+		NextPageToken: nextPageToken(offset, len(keys), totalCount),
+		TotalCount:    int32(totalCount),
+	}, nil
+}
+
+// RevokeAPIKey handles API key revocation requests
+func (h *APIKeyHandler) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	if strings.TrimSpace(req.KeyId) == "" {
+		return nil, status.Error(codes.InvalidArgument, "key_id is required")
+	}
+
+	key, err := h.repos.APIKey().GetByID(ctx, req.KeyId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "API key not found")
+		}
+		h.logger.WithError(err).WithField("key_id", req.KeyId).Error("Failed to get API key")
+		return nil, status.Error(codes.Internal, "Failed to revoke API key")
+	}
+
+	// Keys outside the caller's groups are reported as missing, as in listings
+	groups, all, err := h.manageableGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !all && !withinGroups(key.DeviceGroups, groups) {
+		return nil, status.Error(codes.NotFound, "API key not found")
+	}
+
+	revokedAt := time.Now()
+	revoked := *key
+	revoked.RevokedAt = &revokedAt
+	err = h.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if err := repos.APIKey().Revoke(ctx, key.ID, revokedAt); err != nil {
+			return err
+		}
+		return audit.CommitChange(ctx, repos.Audit(), key.ID, key, &revoked)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.FailedPrecondition, "API key is already revoked")
+		}
+		h.logger.WithError(err).WithField("key_id", req.KeyId).Error("Failed to revoke API key")
+		return nil, status.Error(codes.Internal, "Failed to revoke API key")
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"key_id":     key.ID,
+		"prefix":     key.Prefix,
+		"subject":    key.Subject,
+		"revoked_by": auth.Subject(ctx),
+	}).Info("API key revoked by request")
+
+	return &pb.RevokeAPIKeyResponse{
+		Success: true,
+		Message: "API key revoked",
+	}, nil
+}
+
+// validateCreateAPIKeyRequest validates the API key creation request
+func (h *APIKeyHandler) validateCreateAPIKeyRequest(ctx context.Context, req *pb.CreateAPIKeyRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return status.Error(codes.InvalidArgument, "name is required")
+	}
+	if len(req.Name) > 255 || len(req.Subject) > 255 {
+		return status.Error(codes.InvalidArgument, "name and subject must be at most 255 characters")
+	}
+	if req.TtlSeconds < 0 {
+		return status.Error(codes.InvalidArgument, "ttl_seconds cannot be negative")
+	}
+	if len(req.Roles) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one role is required")
+	}
+
+	for _, name := range req.Roles {
+		// Device credentials are issued through enrollment only
+		if name == models.RoleDevice {
+			return status.Error(codes.InvalidArgument, "the device role cannot be granted to API keys")
+		}
+		if _, err := h.repos.Role().GetRole(ctx, name); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return status.Errorf(codes.InvalidArgument, "unknown role: %s", name)
+			}
+			h.logger.WithError(err).WithField("role", name).Error("Failed to get role")
+			return status.Error(codes.Internal, "Failed to validate roles")
+		}
+	}
+
+	for _, group := range req.DeviceGroups {
+		if strings.TrimSpace(group) == "" {
+			return status.Error(codes.InvalidArgument, "device groups cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+// manageableGroups returns the device groups the caller may manage keys
+// for, and true instead if it may manage all keys. Without role-based access
+// control nothing limits who may mint keys, so key management is refused.
+func (h *APIKeyHandler) manageableGroups(ctx context.Context) ([]string, bool, error) {
+	grants, ok := rbac.GrantsFromContext(ctx)
+	if !ok {
+		return nil, false, status.Error(codes.FailedPrecondition, "API key management requires role-based access control")
+	}
+	groups, all := grants.Groups(models.PermissionAccessManage)
+	return groups, all, nil
+}
+
+// withinGroups returns true if the key groups are a non-empty subset of groups
+func withinGroups(keyGroups, groups []string) bool {
+	if len(keyGroups) == 0 {
+		return false
+	}
+	for _, group := range keyGroups {
+		if !slices.Contains(groups, group) {
+			return false
+		}
+	}
+	return true
+}
+
+// convertAPIKeyToProto converts an API key model to protobuf, without its hash
+func (h *APIKeyHandler) convertAPIKeyToProto(key *models.APIKey) *pb.APIKey {
+	result := &pb.APIKey{
+		Id:           key.ID,
+		Prefix:       key.Prefix,
+		Name:         key.Name,
+		Subject:      key.Subject,
+		Roles:        key.Roles,
+		DeviceGroups: key.DeviceGroups,
+		CreatedBy:    key.CreatedBy,
+		CreatedAt:    timestamppb.New(key.CreatedAt),
+	}
+
+	if key.DeviceID != nil {
+		result.DeviceId = *key.DeviceID
+	}
+	if key.ExpiresAt != nil {
+		result.ExpiresAt = timestamppb.New(*key.ExpiresAt)
+	}
+	if key.RevokedAt != nil {
+		result.RevokedAt = timestamppb.New(*key.RevokedAt)
+	}
+	if key.LastUsedAt != nil {
+		result.LastUsedAt = timestamppb.New(*key.LastUsedAt)
+	}
+
+	return result
+}
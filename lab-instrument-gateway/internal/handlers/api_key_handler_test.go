@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/rbac"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// apiKeyStubs is an in-memory RepositoryManager with stubbed roles and API
+// keys
+type apiKeyStubs struct {
+	repository.RepositoryManager
+	roles *roleStub
+	keys  *apiKeyStub
+}
+
+func (s *apiKeyStubs) Role() repository.RoleRepository     { return s.roles }
+func (s *apiKeyStubs) APIKey() repository.APIKeyRepository { return s.keys }
+
+func (s *apiKeyStubs) WithTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.RepositoryManager) error) error {
+	return fn(ctx, s)
+}
+
+type roleStub struct {
+	repository.RoleRepository
+}
+
+func (roleStub) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	switch name {
+	case models.RoleViewer, models.RoleOperator, models.RoleAdmin, models.RoleDevice:
+		return &models.Role{Name: name}, nil
+	}
+	return nil, fmt.Errorf("%w: role %s", repository.ErrNotFound, name)
+}
+
+type apiKeyStub struct {
+	repository.APIKeyRepository
+	keys map[string]*models.APIKey
+}
+
+func (s *apiKeyStub) Create(ctx context.Context, key *models.APIKey) error {
+	key.SetDefaults()
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	s.keys[key.ID] = key
+	return nil
+}
+
+func (s *apiKeyStub) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: API key %s", repository.ErrNotFound, id)
+	}
+	return key, nil
+}
+
+func (s *apiKeyStub) Revoke(ctx context.Context, id string, at time.Time) error {
+	key := s.keys[id]
+	if key.RevokedAt != nil {
+		return fmt.Errorf("%w: active API key %s", repository.ErrNotFound, id)
+	}
+	key.RevokedAt = &at
+	return nil
+}
+
+// administrator returns a context for a caller with access:manage in a
+// device group, or in all of them when the group is nil
+func administrator(t *testing.T, subject string, deviceGroup *string) context.Context {
+	repos := &rbacStubs{bindings: []*models.RoleBinding{{Subject: subject, Role: models.RoleAdmin, DeviceGroup: deviceGroup}}}
+	authorizer := rbac.NewAuthorizer(repos, rbac.Config{Enabled: true}, logger.NewDefaultLogger())
+	identity := &auth.Identity{Subject: subject, Method: auth.MethodJWT}
+	grants, err := authorizer.Grants(context.Background(), identity)
+	require.NoError(t, err)
+	return rbac.WithGrants(auth.WithIdentity(context.Background(), identity), grants)
+}
+
+type rbacStubs struct {
+	repository.RepositoryManager
+	bindings []*models.RoleBinding
+}
+
+func (s *rbacStubs) Role() repository.RoleRepository { return &bindingStub{bindings: s.bindings} }
+
+type bindingStub struct {
+	repository.RoleRepository
+	bindings []*models.RoleBinding
+}
+
+func (s *bindingStub) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	return []*models.Role{{Name: models.RoleAdmin, Permissions: []models.Permission{models.PermissionAccessManage}}}, nil
+}
+
+func (s *bindingStub) ListBindings(ctx context.Context, subject string) ([]*models.RoleBinding, error) {
+	return s.bindings, nil
+}
+
+func TestAPIKeyHandler_CreateAndRevoke(t *testing.T) {
+	keys := &apiKeyStub{keys: map[string]*models.APIKey{}}
+	handler := NewAPIKeyHandler(&apiKeyStubs{RepositoryManager: memory.NewRepositoryManager(), roles: &roleStub{}, keys: keys}, logger.NewDefaultLogger())
+	ctx := administrator(t, "root", nil)
+
+	resp, err := handler.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{
+		Name:         "lims",
+		Roles:        []string{models.RoleViewer},
+		DeviceGroups: []string{"lab-a"},
+		TtlSeconds:   3600,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resp.ApiKey, resp.Key.Prefix)
+	assert.Equal(t, "lims", resp.Key.Subject)
+	assert.Equal(t, "root", resp.Key.CreatedBy)
+	assert.NotNil(t, resp.Key.ExpiresAt)
+	assert.NotEqual(t, resp.ApiKey, keys.keys[resp.Key.Id].KeyHash)
+
+	for name, req := range map[string]*pb.CreateAPIKeyRequest{
+		"no name":      {Roles: []string{models.RoleViewer}},
+		"no roles":     {Name: "lims"},
+		"unknown role": {Name: "lims", Roles: []string{"superuser"}},
+		"device role":  {Name: "lims", Roles: []string{models.RoleDevice}},
+	} {
+		_, err := handler.CreateAPIKey(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+	}
+
+	_, err = handler.RevokeAPIKey(ctx, &pb.RevokeAPIKeyRequest{KeyId: resp.Key.Id})
+	require.NoError(t, err)
+	assert.NotNil(t, keys.keys[resp.Key.Id].RevokedAt)
+
+	_, err = handler.RevokeAPIKey(ctx, &pb.RevokeAPIKeyRequest{KeyId: resp.Key.Id})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = handler.RevokeAPIKey(ctx, &pb.RevokeAPIKeyRequest{KeyId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Without role-based access control no caller may mint or revoke keys
+	unauthorized := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "root", Method: auth.MethodJWT})
+	_, err = handler.CreateAPIKey(unauthorized, &pb.CreateAPIKeyRequest{Name: "lims", Roles: []string{models.RoleAdmin}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = handler.ListAPIKeys(unauthorized, &pb.ListAPIKeysRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAPIKeyHandler_GroupScopedAdministrator(t *testing.T) {
+	keys := &apiKeyStub{keys: map[string]*models.APIKey{}}
+	handler := NewAPIKeyHandler(&apiKeyStubs{RepositoryManager: memory.NewRepositoryManager(), roles: &roleStub{}, keys: keys}, logger.NewDefaultLogger())
+	labA := "lab-a"
+	ctx := administrator(t, "ada", &labA)
+
+	_, err := handler.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{Name: "lims", Roles: []string{models.RoleViewer}, DeviceGroups: []string{"lab-a"}})
+	assert.NoError(t, err)
+
+	_, err = handler.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{Name: "lims", Roles: []string{models.RoleViewer}, DeviceGroups: []string{"lab-a", "lab-b"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = handler.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{Name: "lims", Roles: []string{models.RoleViewer}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "keys for all devices need a global grant")
+
+	keys.keys["global"] = &models.APIKey{ID: "global", Subject: "pipeline", Roles: []string{models.RoleViewer}}
+	_, err = handler.RevokeAPIKey(ctx, &pb.RevokeAPIKeyRequest{KeyId: "global"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Nil(t, keys.keys["global"].RevokedAt)
+}
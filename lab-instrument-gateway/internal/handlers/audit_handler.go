@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/internal/rbac"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// AuditHandler handles audit trail gRPC operations. Entries are not scoped
+// to device groups, so reading them requires a global audit:read grant.
+type AuditHandler struct {
+	repos  repository.RepositoryManager
+	logger *logger.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(repos repository.RepositoryManager, logger *logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		repos:  repos,
+		logger: logger,
+	}
+}
+
+// QueryAuditLog handles audit trail queries, returning entries newest first
+func (h *AuditHandler) QueryAuditLog(ctx context.Context, req *pb.QueryAuditLogRequest) (*pb.QueryAuditLogResponse, error) {
+	if grants, ok := rbac.GrantsFromContext(ctx); ok {
+		if _, all := grants.Groups(models.PermissionAuditRead); !all {
+			return nil, status.Error(codes.PermissionDenied, "reading the audit trail requires a global audit:read grant")
+		}
+	}
+
+	if req.PageSize < 0 || req.PageSize > 1000 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 0 and 1000")
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 50
+	}
+
+	offset, err := decodePageOffset(req.PageToken)
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid page token")
+		return nil, status.Error(codes.InvalidArgument, "Invalid page token")
+	}
+
+	filter := repository.AuditFilter{
+		Filter: repository.Filter{
+			Limit:  int(req.PageSize),
+			Offset: offset,
+		},
+		Actor:        strings.TrimSpace(req.Actor),
+		Action:       strings.TrimSpace(req.Action),
+		ResourceType: strings.TrimSpace(req.ResourceType),
+		ResourceID:   strings.TrimSpace(req.ResourceId),
+	}
+	if req.StartTime != nil {
+		startTime := req.StartTime.AsTime()
+		filter.StartTime = &startTime
+	}
+	if req.EndTime != nil {
+		endTime := req.EndTime.AsTime()
+		filter.EndTime = &endTime
+	}
+	if filter.StartTime != nil && filter.EndTime != nil && filter.EndTime.Before(*filter.StartTime) {
+		return nil, status.Error(codes.InvalidArgument, "end_time must not be before start_time")
+	}
+
+	entries, err := h.repos.Audit().List(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list audit entries")
+		return nil, status.Error(codes.Internal, "Failed to retrieve audit entries")
+	}
+
+	totalCount, err := h.repos.Audit().Count(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to count audit entries")
+		return nil, status.Error(codes.Internal, "Failed to count audit entries")
+	}
+
+	protoEntries := make([]*pb.AuditEntry, len(entries))
+	for i, entry := range entries {
+		protoEntries[i] = h.convertAuditEntryToProto(entry)
+	}
+
+	return &pb.QueryAuditLogResponse{
+		Entries:       protoEntries,
+		NextPageToken: nextPageToken(offset, len(entries), totalCount),
+		TotalCount:    int32(totalCount),
+	}, nil
+}
+
+// convertAuditEntryToProto converts an audit entry model to protobuf
+func (h *AuditHandler) convertAuditEntryToProto(entry *models.AuditEntry) *pb.AuditEntry {
+	return &pb.AuditEntry{
+		Id:            entry.ID,
+		Sequence:      entry.Sequence,
+		OccurredAt:    timestamppb.New(entry.OccurredAt),
+		Actor:         entry.Actor,
+		AuthMethod:    entry.AuthMethod,
+		Action:        entry.Action,
+		ResourceType:  entry.ResourceType,
+		ResourceId:    entry.ResourceID,
+		Reason:        entry.Reason,
+		Outcome:       entry.Outcome,
+		Before:        auditValueJSON(entry.Before),
+		After:         auditValueJSON(entry.After),
+		CorrelationId: entry.CorrelationID,
+		PreviousHash:  entry.PreviousHash,
+		Hash:          entry.Hash,
+	}
+}
+
+// auditValueJSON encodes a before or after value, or "" if there is none
+func auditValueJSON(value map[string]interface{}) string {
+	if len(value) == 0 {
+		return ""
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/internal/esign"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// CommandHandler handles command-related gRPC operations
+type CommandHandler struct {
+	repos      repository.RepositoryManager
+	signatures *esign.Service
+	dispatcher *device.Dispatcher
+	logger     *logger.Logger
+}
+
+// NewCommandHandler creates a new command handler
+func NewCommandHandler(repos repository.RepositoryManager, signatures *esign.Service, dispatcher *device.Dispatcher, logger *logger.Logger) *CommandHandler {
+	return &CommandHandler{
+		repos:      repos,
+		signatures: signatures,
+		dispatcher: dispatcher,
+		logger:     logger,
+	}
+}
+
+// SendCommand handles command submission. Commands that need electronic
+// signatures are refused unless enough valid signatures are attached. The
+// command and its signatures are stored, then sent to the device if it has
+// a stream attached, forwarded to the replica holding its stream, or queued
+// until it attaches one. Devices report results asynchronously, so the call
+// never waits for execution.
+func (h *CommandHandler) SendCommand(ctx context.Context, req *pb.SendCommandRequest) (*pb.SendCommandResponse, error) {
+	if err := h.validateSendCommandRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	target, err := h.repos.Device().GetByID(ctx, req.DeviceId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "Device not found")
+		}
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to get device")
+		return nil, status.Error(codes.Internal, "Failed to retrieve device")
+	}
+
+	command := h.createCommandFromRequest(req)
+
+	if _, err := h.repos.Command().GetByCommandID(ctx, command.CommandID); err == nil {
+		return nil, status.Error(codes.AlreadyExists, "A command with this id already exists")
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		h.logger.WithError(err).WithField("command_id", command.CommandID).Error("Failed to check existing command")
+		return nil, status.Error(codes.Internal, "Failed to check command id")
+	}
+
+	signatures, err := h.signatures.Sign(ctx, target.Type, command, h.convertSignatureRequests(req.Signatures))
+	if err != nil {
+		h.logger.WithFields(map[string]interface{}{
+			"device_id":    req.DeviceId,
+			"command_type": command.Type,
+		}).WithError(err).Warn("Command signatures rejected")
+
+		switch {
+		case errors.Is(err, esign.ErrSignaturesRequired), errors.Is(err, esign.ErrSigningUnavailable):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, esign.ErrSignerNotPermitted):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, esign.ErrInvalidSignature):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "Failed to verify command signatures")
+	}
+	command.Signatures = signatures
+
+	// The command is stored with its audit entry before it can reach the
+	// device, so that no command is sent unrecorded
+	err = h.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if err := repos.Command().Create(ctx, command); err != nil {
+			return fmt.Errorf("failed to create command: %w", err)
+		}
+		return audit.CommitChange(ctx, repos.Audit(), command.CommandID, nil, command)
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to create command")
+		return nil, status.Error(codes.Internal, "Failed to store command")
+	}
+
+	message := "Command queued until the device connects"
+	delivered, err := h.dispatcher.Dispatch(ctx, command)
+	if err != nil {
+		h.logger.WithError(err).WithFields(map[string]interface{}{
+			"device_id":  req.DeviceId,
+			"command_id": command.CommandID,
+		}).Warn("Failed to dispatch command")
+	}
+	if delivered {
+		message = "Command sent to device"
+	} else if err == nil {
+		forwarded, err := h.dispatcher.Forward(ctx, command)
+		if err != nil {
+			h.logger.WithError(err).WithFields(map[string]interface{}{
+				"device_id":  req.DeviceId,
+				"command_id": command.CommandID,
+			}).Warn("Failed to forward command")
+		}
+		if forwarded {
+			message = "Command forwarded to the gateway holding the device stream"
+		}
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"device_id":  req.DeviceId,
+		"command_id": command.CommandID,
+		"type":       command.Type,
+		"signatures": len(command.Signatures),
+		"delivered":  delivered,
+	}).Info("Command submitted")
+
+	return &pb.SendCommandResponse{
+		Success:     true,
+		Message:     message,
+		CommandId:   command.CommandID,
+		Status:      h.convertCommandStatusToProto(command.Status),
+		SubmittedAt: timestamppb.New(command.SubmittedAt),
+		Signatures:  h.convertSignaturesToProto(command.Signatures),
+	}, nil
+}
+
+// validateSendCommandRequest validates the command submission
+func (h *CommandHandler) validateSendCommandRequest(req *pb.SendCommandRequest) error {
+	if strings.TrimSpace(req.DeviceId) == "" {
+		return fmt.Errorf("device_id is required")
+	}
+
+	if req.Command == nil {
+		return fmt.Errorf("command is required")
+	}
+
+	if strings.TrimSpace(req.Command.Type) == "" {
+		return fmt.Errorf("command type is required")
+	}
+
+	if len(req.Command.Id) > 255 {
+		return fmt.Errorf("command id too long (max 255 characters)")
+	}
+
+	if req.Command.ExpiresAt != nil && !req.Command.ExpiresAt.AsTime().After(time.Now()) {
+		return fmt.Errorf("command expires_at must be in the future")
+	}
+
+	if len(req.Signatures) > esign.MaxSignatures {
+		return fmt.Errorf("too many signatures (max %d)", esign.MaxSignatures)
+	}
+
+	return nil
+}
+
+// createCommandFromRequest creates a command model from the request
+func (h *CommandHandler) createCommandFromRequest(req *pb.SendCommandRequest) *models.Command {
+	command := &models.Command{
+		ID:             uuid.New().String(),
+		DeviceID:       req.DeviceId,
+		CommandID:      strings.TrimSpace(req.Command.Id),
+		Type:           strings.TrimSpace(req.Command.Type),
+		Parameters:     make(map[string]interface{}, len(req.Command.Parameters)),
+		Priority:       int(req.Command.Priority),
+		TimeoutSeconds: int(req.TimeoutSeconds),
+	}
+	if command.CommandID == "" {
+		command.CommandID = uuid.New().String()
+	}
+
+	for k, v := range req.Command.Parameters {
+		command.Parameters[k] = v
+	}
+
+	if req.Command.ExpiresAt != nil {
+		expiresAt := req.Command.ExpiresAt.AsTime()
+		command.ExpiresAt = &expiresAt
+	}
+
+	command.SetDefaults()
+	return command
+}
+
+// convertSignatureRequests converts the submitted signatures
+func (h *CommandHandler) convertSignatureRequests(signatures []*pb.CommandSignature) []esign.Request {
+	requests := make([]esign.Request, len(signatures))
+	for i, signature := range signatures {
+		requests[i] = esign.Request{
+			Credential: signature.Credential,
+			Meaning:    models.SignatureMeaning(strings.ToLower(strings.TrimSpace(signature.Meaning))),
+			Comment:    signature.Comment,
+		}
+	}
+	return requests
+}
+
+// convertSignaturesToProto converts signature records to protobuf
+func (h *CommandHandler) convertSignaturesToProto(signatures []*models.CommandSignature) []*pb.CommandSignatureRecord {
+	records := make([]*pb.CommandSignatureRecord, len(signatures))
+	for i, signature := range signatures {
+		records[i] = &pb.CommandSignatureRecord{
+			Signer:      signature.Signer,
+			Meaning:     string(signature.Meaning),
+			Comment:     signature.Comment,
+			SignedAt:    timestamppb.New(signature.SignedAt),
+			ContentHash: signature.ContentHash,
+		}
+	}
+	return records
+}
+
+// convertCommandStatusToProto converts the internal command status to protobuf
+func (h *CommandHandler) convertCommandStatusToProto(commandStatus models.CommandStatus) pb.CommandStatus {
+	switch commandStatus {
+	case models.CommandStatusPending:
+		return pb.CommandStatus_COMMAND_STATUS_PENDING
+	case models.CommandStatusExecuting:
+		return pb.CommandStatus_COMMAND_STATUS_EXECUTING
+	case models.CommandStatusCompleted:
+		return pb.CommandStatus_COMMAND_STATUS_COMPLETED
+	case models.CommandStatusFailed:
+		return pb.CommandStatus_COMMAND_STATUS_FAILED
+	case models.CommandStatusTimeout:
+		return pb.CommandStatus_COMMAND_STATUS_TIMEOUT
+	case models.CommandStatusCancelled:
+		return pb.CommandStatus_COMMAND_STATUS_CANCELLED
+	default:
+		return pb.CommandStatus_COMMAND_STATUS_UNKNOWN
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/internal/esign"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+const commandTestSecret = "0123456789abcdef0123456789abcdef"
+
+func newCommandTestHandler(t *testing.T, requirements string) (*CommandHandler, repository.RepositoryManager, *device.Dispatcher) {
+	repos := memory.NewRepositoryManager()
+	require.NoError(t, repos.Device().Create(context.Background(), &models.Device{
+		ID:     "hplc-01",
+		Name:   "HPLC 1",
+		Type:   "hplc",
+		Status: models.DeviceStatusOnline,
+	}))
+	log := logger.NewDefaultLogger()
+
+	parsed, err := esign.ParseRequirements(requirements)
+	require.NoError(t, err)
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{Secret: commandTestSecret})
+	require.NoError(t, err)
+	signatures := esign.NewService(esign.Config{Requirements: parsed}, repos.Command(), auth.NewAuthenticator(verifier, nil, false), nil, log)
+
+	dispatcher := device.NewDispatcher(repos.Command(), log)
+	return NewCommandHandler(repos, signatures, dispatcher, log), repos, dispatcher
+}
+
+func freshToken(t *testing.T, subject string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(commandTestSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestCommandHandler_SendCommand_RequiresSignatures(t *testing.T) {
+	handler, repos, _ := newCommandTestHandler(t, "hplc:start_run=1")
+	ctx := context.Background()
+
+	req := &pb.SendCommandRequest{
+		DeviceId:       "hplc-01",
+		Command:        &pb.Command{Id: "run-1", Type: "start_run", Parameters: map[string]string{"method": "assay-42"}},
+		TimeoutSeconds: 60,
+	}
+
+	_, err := handler.SendCommand(ctx, req)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	count, err := repos.Command().Count(ctx, repository.CommandFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, count, "unsigned critical commands are not stored")
+
+	req.Signatures = []*pb.CommandSignature{{Credential: "not.a.token", Meaning: "approved"}}
+	_, err = handler.SendCommand(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	req.Signatures = []*pb.CommandSignature{{Credential: freshToken(t, "alice"), Meaning: "Approved", Comment: "method validated"}}
+	resp, err := handler.SendCommand(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", resp.CommandId)
+	assert.Equal(t, pb.CommandStatus_COMMAND_STATUS_PENDING, resp.Status)
+	require.Len(t, resp.Signatures, 1)
+	assert.Equal(t, "alice", resp.Signatures[0].Signer)
+	assert.Equal(t, "approved", resp.Signatures[0].Meaning)
+
+	stored, err := repos.Command().GetByCommandID(ctx, "run-1")
+	require.NoError(t, err)
+	signatures, err := repos.Command().ListSignatures(ctx, stored.ID)
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+	assert.Equal(t, "alice", signatures[0].Signer)
+
+	_, err = handler.SendCommand(ctx, req)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestCommandHandler_SendCommand_Dispatch(t *testing.T) {
+	handler, repos, dispatcher := newCommandTestHandler(t, "")
+	ctx := context.Background()
+
+	var sent []*models.Command
+	dispatcher.Attach("hplc-01", "stream-1", func(command *models.Command) error {
+		sent = append(sent, command)
+		return nil
+	})
+
+	resp, err := handler.SendCommand(ctx, &pb.SendCommandRequest{
+		DeviceId:       "hplc-01",
+		Command:        &pb.Command{Type: "calibrate"},
+		TimeoutSeconds: 60,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pb.CommandStatus_COMMAND_STATUS_EXECUTING, resp.Status)
+	assert.NotEmpty(t, resp.CommandId)
+	require.Len(t, sent, 1)
+	stored, err := repos.Command().GetByCommandID(ctx, resp.CommandId)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusExecuting, stored.Status)
+
+	// A stale stream cannot detach its replacement
+	dispatcher.Detach("hplc-01", "stream-0")
+	_, err = handler.SendCommand(ctx, &pb.SendCommandRequest{DeviceId: "hplc-01", Command: &pb.Command{Type: "calibrate"}, TimeoutSeconds: 60})
+	require.NoError(t, err)
+	assert.Len(t, sent, 2)
+
+	_, err = handler.SendCommand(ctx, &pb.SendCommandRequest{DeviceId: "unknown", Command: &pb.Command{Type: "calibrate"}, TimeoutSeconds: 60})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCommandHandler_SendCommand_AuditedBeforeDispatch(t *testing.T) {
+	handler, repos, dispatcher := newCommandTestHandler(t, "")
+	var sent int
+	dispatcher.Attach("hplc-01", "stream-1", func(*models.Command) error {
+		sent++
+		return nil
+	})
+	req := &pb.SendCommandRequest{DeviceId: "hplc-01", Command: &pb.Command{Type: "calibrate"}, TimeoutSeconds: 60}
+	audited := func() context.Context {
+		ctx, _ := audit.WithChange(context.Background(), models.AuditEntry{
+			Action:       pb.LabInstrumentGateway_SendCommand_FullMethodName,
+			ResourceType: "command",
+		})
+		return ctx
+	}
+
+	resp, err := handler.SendCommand(audited(), req)
+	require.NoError(t, err)
+	entries, err := repos.Audit().List(context.Background(), repository.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, resp.CommandId, entries[0].ResourceID)
+
+	// A command whose entry cannot be written is neither stored nor sent
+	handler.repos = unavailableAudit{repos}
+	_, err = handler.SendCommand(audited(), req)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, 1, sent)
+	count, err := repos.Command().Count(context.Background(), repository.CommandFilter{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestCommandHandler_SendCommand_Draining(t *testing.T) {
+	handler, repos, dispatcher := newCommandTestHandler(t, "")
+	ctx := context.Background()
+
+	sent := 0
+	dispatcher.Attach("hplc-01", "stream-1", func(command *models.Command) error {
+		sent++
+		return nil
+	})
+	require.NoError(t, dispatcher.Drain(ctx))
+
+	// Commands stay pending for the replica the device reconnects to
+	resp, err := handler.SendCommand(ctx, &pb.SendCommandRequest{
+		DeviceId:       "hplc-01",
+		Command:        &pb.Command{Type: "calibrate"},
+		TimeoutSeconds: 60,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pb.CommandStatus_COMMAND_STATUS_PENDING, resp.Status)
+	stored, err := repos.Command().GetByCommandID(ctx, resp.CommandId)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusPending, stored.Status)
+	assert.Zero(t, sent)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +11,9 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/yourorg/lab-gateway/internal/alerting"
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/internal/auth"
 	"github.com/yourorg/lab-gateway/internal/device"
 	"github.com/yourorg/lab-gateway/pkg/logger"
 	"github.com/yourorg/lab-gateway/pkg/models"
@@ -22,6 +26,9 @@ type DeviceHandler struct {
 	repos             repository.RepositoryManager
 	connectionManager *device.ConnectionManager
 	logger            *logger.Logger
+
+	// alerts is set when registrations must present device credentials
+	alerts *alerting.Manager
 }
 
 // NewDeviceHandler creates a new device handler
@@ -33,6 +40,12 @@ func NewDeviceHandler(repos repository.RepositoryManager, connMgr *device.Connec
 	}
 }
 
+// RequireCredentials makes RegisterDevice reject callers without valid
+// device credentials, raising a security breach alert for each rejection
+func (h *DeviceHandler) RequireCredentials(alerts *alerting.Manager) {
+	h.alerts = alerts
+}
+
 // RegisterDevice handles device registration requests
 func (h *DeviceHandler) RegisterDevice(ctx context.Context, req *pb.RegisterDeviceRequest) (*pb.RegisterDeviceResponse, error) {
 	// Input validation
@@ -50,36 +63,27 @@ func (h *DeviceHandler) RegisterDevice(ctx context.Context, req *pb.RegisterDevi
 
 	// Check if device already exists
 	existingDevice, err := h.repos.Device().GetByID(ctx, req.DeviceId)
-	if err != nil && err != repository.ErrNotFound {
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to check existing device")
 		return nil, status.Error(codes.Internal, "Failed to check device registration status")
 	}
 
+	if h.alerts != nil {
+		if err := h.checkRegistrationCredentials(ctx, req, existingDevice); err != nil {
+			return nil, err
+		}
+	}
+
 	var device *models.Device
-	var isUpdate bool
+	var before interface{}
+	isUpdate := existingDevice != nil
 
-	if existingDevice != nil {
-		// Device exists - update it
-		isUpdate = true
+	if isUpdate {
 		device = existingDevice
+		before = audit.Snapshot(existingDevice)
 		h.updateDeviceFromRequest(device, req)
-		
-		if err := h.repos.Device().Update(ctx, device); err != nil {
-			h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to update existing device")
-			return nil, status.Error(codes.Internal, "Failed to update device registration")
-		}
-		
-		h.logger.WithField("device_id", req.DeviceId).Info("Device registration updated")
 	} else {
-		// New device - create it
 		device = h.createDeviceFromRequest(req)
-		
-		if err := h.repos.Device().Create(ctx, device); err != nil {
-			h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to create new device")
-			return nil, status.Error(codes.Internal, "Failed to register new device")
-		}
-		
-		h.logger.WithField("device_id", req.DeviceId).Info("New device registered")
 	}
 
 	// Generate session ID for the device connection
@@ -102,20 +106,47 @@ func (h *DeviceHandler) RegisterDevice(ctx context.Context, req *pb.RegisterDevi
 		}
 	}
 
+	// The device, its session, its status and the audit entry are written
+	// together, so that a failed registration leaves none of them behind
+	err = h.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if isUpdate {
+			if err := repos.Device().Update(ctx, device); err != nil {
+				return fmt.Errorf("failed to update device: %w", err)
+			}
+		} else if err := repos.Device().Create(ctx, device); err != nil {
+			return fmt.Errorf("failed to create device: %w", err)
+		}
+
+		if err := audit.CommitChange(ctx, repos.Audit(), device.ID, before, device); err != nil {
+			return err
+		}
+
+		if err := repos.DeviceSession().Create(ctx, session); err != nil {
+			return fmt.Errorf("failed to create device session: %w", err)
+		}
+
+		return h.markOnline(ctx, repos, device, sessionID)
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to register device")
+		if isUpdate {
+			return nil, status.Error(codes.Internal, "Failed to update device registration")
+		}
+		return nil, status.Error(codes.Internal, "Failed to register new device")
+	}
+
+	if isUpdate {
+		h.logger.WithField("device_id", req.DeviceId).Info("Device registration updated")
+	} else {
+		h.logger.WithField("device_id", req.DeviceId).Info("New device registered")
+	}
+
 	// Register the connection
 	if err := h.connectionManager.RegisterConnection(ctx, session); err != nil {
 		h.logger.WithError(err).WithField("device_id", req.DeviceId).Warn("Failed to register device connection")
 		// Don't fail the registration if connection tracking fails
 	}
 
-	// Update device status to online
-	device.SetStatus(models.DeviceStatusOnline)
-	device.UpdateLastSeen()
-	
-	if err := h.repos.Device().UpdateStatus(ctx, device.ID, device.Status); err != nil {
-		h.logger.WithError(err).WithField("device_id", req.DeviceId).Warn("Failed to update device status to online")
-	}
-
 	// Prepare response
 	message := "Device registered successfully"
 	if isUpdate {
@@ -130,6 +161,108 @@ func (h *DeviceHandler) RegisterDevice(ctx context.Context, req *pb.RegisterDevi
 	}, nil
 }
 
+// markOnline sets a registered device online, recording the status change
+// in its event log. Devices in maintenance, an operator-controlled state
+// that mutes their alerts, stay in maintenance.
+func (h *DeviceHandler) markOnline(ctx context.Context, repos repository.RepositoryManager, device *models.Device, sessionID string) error {
+	from := device.Status
+	if from == models.DeviceStatusMaintenance {
+		return nil
+	}
+
+	if err := repos.Device().UpdateStatus(ctx, device.ID, models.DeviceStatusOnline); err != nil {
+		return fmt.Errorf("failed to update device status: %w", err)
+	}
+
+	if from == models.DeviceStatusOnline {
+		return nil
+	}
+
+	to := models.DeviceStatusOnline
+	event := &models.DeviceEvent{
+		DeviceID:   device.ID,
+		Type:       models.DeviceEventStatusChanged,
+		SessionID:  &sessionID,
+		FromStatus: &from,
+		ToStatus:   &to,
+	}
+	if err := repos.DeviceEvent().Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to record device status change: %w", err)
+	}
+
+	return nil
+}
+
+// checkRegistrationCredentials verifies that the caller may register the
+// device. Devices must authenticate with a credential bound to the device ID
+// and may not change the type or group they were enrolled with; other
+// authenticated callers have already been authorized for devices:write.
+func (h *DeviceHandler) checkRegistrationCredentials(ctx context.Context, req *pb.RegisterDeviceRequest, existing *models.Device) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		h.raiseRegistrationBreach(ctx, req, existing, nil, "registration without valid credentials")
+		return status.Error(codes.Unauthenticated, "device credentials required")
+	}
+
+	if !identity.IsDevice() {
+		return nil
+	}
+
+	bound := false
+	for _, id := range identity.DeviceIDs {
+		if id == req.DeviceId {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		h.raiseRegistrationBreach(ctx, req, existing, identity, "credentials are not bound to the device")
+		return status.Errorf(codes.PermissionDenied, "credentials are not valid for device %s", req.DeviceId)
+	}
+
+	if existing != nil {
+		requestedGroup, setsGroup := req.Metadata[models.DeviceGroupMetadataKey]
+		typeChanged := strings.ToLower(strings.TrimSpace(req.Type)) != existing.Type
+		if typeChanged || (setsGroup && requestedGroup != existing.Group()) {
+			h.raiseRegistrationBreach(ctx, req, existing, identity, "device attempted to change its enrolled type or group")
+			return status.Error(codes.PermissionDenied, "device type and group cannot be changed")
+		}
+	}
+
+	return nil
+}
+
+// raiseRegistrationBreach logs a rejected registration and raises a security
+// breach alert for it
+func (h *DeviceHandler) raiseRegistrationBreach(ctx context.Context, req *pb.RegisterDeviceRequest, existing *models.Device, identity *auth.Identity, reason string) {
+	metadata := map[string]interface{}{
+		"claimed_device_id": req.DeviceId,
+		"device_type":       req.Type,
+		"reason":            reason,
+	}
+	if identity != nil {
+		metadata["subject"] = identity.Subject
+		metadata["auth_method"] = identity.Method
+	}
+
+	h.logger.WithFields(metadata).Warn("Rejected device registration")
+
+	alert := &models.Alert{
+		Type:     models.AlertTypeSecurityBreach,
+		Severity: models.AlertSeverityCritical,
+		Message:  fmt.Sprintf("Rejected registration of device %s: %s", req.DeviceId, reason),
+		Metadata: metadata,
+	}
+	// Alerts reference registered devices only
+	if existing != nil {
+		alert.DeviceID = &existing.ID
+	}
+
+	if err := h.alerts.Raise(ctx, alert); err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to raise security breach alert")
+	}
+}
+
 // validateRegisterDeviceRequest validates the device registration request
 func (h *DeviceHandler) validateRegisterDeviceRequest(req *pb.RegisterDeviceRequest) error {
 	if req == nil {
@@ -2,466 +2,33 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/yourorg/lab-gateway/internal/alerting"
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/internal/auth"
 	"github.com/yourorg/lab-gateway/internal/device"
 	"github.com/yourorg/lab-gateway/pkg/logger"
 	"github.com/yourorg/lab-gateway/pkg/models"
 	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
 	pb "github.com/yourorg/lab-gateway/proto"
 )
 
-// MockRepositoryManager is a mock implementation of RepositoryManager
-type MockRepositoryManager struct {
-	mock.Mock
-}
-
-func (m *MockRepositoryManager) Device() repository.DeviceRepository {
-	args := m.Called()
-	return args.Get(0).(repository.DeviceRepository)
-}
-
-func (m *MockRepositoryManager) Measurement() repository.MeasurementRepository {
-	args := m.Called()
-	return args.Get(0).(repository.MeasurementRepository)
-}
-
-func (m *MockRepositoryManager) Command() repository.CommandRepository {
-	args := m.Called()
-	return args.Get(0).(repository.CommandRepository)
-}
-
-func (m *MockRepositoryManager) Alert() repository.AlertRepository {
-	args := m.Called()
-	return args.Get(0).(repository.AlertRepository)
-}
-
-func (m *MockRepositoryManager) WithTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.RepositoryManager) error) error {
-	args := m.Called(ctx, fn)
-	return args.Error(0)
-}
-
-func (m *MockRepositoryManager) HealthCheck(ctx context.Context) error {
-	args := m.Called(ctx)
-	return args.Error(0)
-}
-
-func (m *MockRepositoryManager) Close() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-// MockDeviceRepository is a mock implementation of DeviceRepository
-type MockDeviceRepository struct {
-	mock.Mock
-}
-
-func (m *MockDeviceRepository) Create(ctx context.Context, device *models.Device) error {
-	args := m.Called(ctx, device)
-	return args.Error(0)
-}
-
-func (m *MockDeviceRepository) GetByID(ctx context.Context, id string) (*models.Device, error) {
-	args := m.Called(ctx, id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Device), args.Error(1)
-}
-
-func (m *MockDeviceRepository) Update(ctx context.Context, device *models.Device) error {
-	args := m.Called(ctx, device)
-	return args.Error(0)
-}
-
-func (m *MockDeviceRepository) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
-}
-
-func (m *MockDeviceRepository) List(ctx context.Context, filter repository.DeviceFilter) ([]*models.Device, error) {
-	args := m.Called(ctx, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Device), args.Error(1)
-}
-
-
-
-func (m *MockDeviceRepository) UpdateStatus(ctx context.Context, id string, status models.DeviceStatus) error {
-	args := m.Called(ctx, id, status)
-	return args.Error(0)
-}
-
-func (m *MockDeviceRepository) Count(ctx context.Context, filter repository.DeviceFilter) (int64, error) {
-	args := m.Called(ctx, filter)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *MockDeviceRepository) CreateBulk(ctx context.Context, devices []*models.Device) (*repository.BulkResult, error) {
-	args := m.Called(ctx, devices)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.BulkResult), args.Error(1)
-}
-
-func (m *MockDeviceRepository) UpdateBulk(ctx context.Context, devices []*models.Device) (*repository.BulkResult, error) {
-	args := m.Called(ctx, devices)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.BulkResult), args.Error(1)
-}
-
-func (m *MockDeviceRepository) UpdateLastSeen(ctx context.Context, deviceID string, timestamp time.Time) error {
-	args := m.Called(ctx, deviceID, timestamp)
-	return args.Error(0)
-}
-
-func (m *MockDeviceRepository) SearchByMetadata(ctx context.Context, metadata map[string]interface{}) ([]*models.Device, error) {
-	args := m.Called(ctx, metadata)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Device), args.Error(1)
-}
-
-func (m *MockDeviceRepository) GetByCapability(ctx context.Context, capability string) ([]*models.Device, error) {
-	args := m.Called(ctx, capability)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Device), args.Error(1)
-}
-
-func (m *MockDeviceRepository) GetOnlineDevices(ctx context.Context) ([]*models.Device, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Device), args.Error(1)
-}
-
-func (m *MockDeviceRepository) GetOfflineDevices(ctx context.Context, threshold time.Duration) ([]*models.Device, error) {
-	args := m.Called(ctx, threshold)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Device), args.Error(1)
-}
-
-
-
-// MockAlertRepository is a mock implementation of AlertRepository
-type MockAlertRepository struct {
-	mock.Mock
-}
-
-func (m *MockAlertRepository) Create(ctx context.Context, alert *models.Alert) error {
-	args := m.Called(ctx, alert)
-	return args.Error(0)
-}
-
-func (m *MockAlertRepository) GetByID(ctx context.Context, id string) (*models.Alert, error) {
-	args := m.Called(ctx, id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Alert), args.Error(1)
-}
-
-func (m *MockAlertRepository) Update(ctx context.Context, alert *models.Alert) error {
-	args := m.Called(ctx, alert)
-	return args.Error(0)
-}
-
-func (m *MockAlertRepository) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
-}
-
-func (m *MockAlertRepository) List(ctx context.Context, filter repository.AlertFilter) ([]*models.Alert, error) {
-	args := m.Called(ctx, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Alert), args.Error(1)
-}
-
-func (m *MockAlertRepository) Count(ctx context.Context, filter repository.AlertFilter) (int64, error) {
-	args := m.Called(ctx, filter)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *MockAlertRepository) Acknowledge(ctx context.Context, alertID string, acknowledgedBy string) error {
-	args := m.Called(ctx, alertID, acknowledgedBy)
-	return args.Error(0)
-}
-
-func (m *MockAlertRepository) Resolve(ctx context.Context, alertID string) error {
-	args := m.Called(ctx, alertID)
-	return args.Error(0)
-}
-
-func (m *MockAlertRepository) GetUnacknowledged(ctx context.Context) ([]*models.Alert, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Alert), args.Error(1)
-}
-
-func (m *MockAlertRepository) GetUnresolved(ctx context.Context) ([]*models.Alert, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Alert), args.Error(1)
-}
-
-func (m *MockAlertRepository) GetCriticalAlerts(ctx context.Context) ([]*models.Alert, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Alert), args.Error(1)
-}
-
-func (m *MockAlertRepository) GetAlertStats(ctx context.Context, timeRange repository.TimeRangeFilter) (map[models.AlertSeverity]int64, error) {
-	args := m.Called(ctx, timeRange)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(map[models.AlertSeverity]int64), args.Error(1)
-}
-
-func (m *MockAlertRepository) GetAlertsByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Alert, error) {
-	args := m.Called(ctx, deviceID, limit)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Alert), args.Error(1)
-}
-
-func (m *MockAlertRepository) DeleteResolvedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
-	args := m.Called(ctx, threshold)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-// MockMeasurementRepository is a mock implementation of MeasurementRepository
-type MockMeasurementRepository struct {
-	mock.Mock
-}
-
-func (m *MockMeasurementRepository) Create(ctx context.Context, measurement *models.Measurement) error {
-	args := m.Called(ctx, measurement)
-	return args.Error(0)
-}
-
-func (m *MockMeasurementRepository) GetByID(ctx context.Context, id string) (*models.Measurement, error) {
-	args := m.Called(ctx, id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Measurement), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
-}
-
-func (m *MockMeasurementRepository) CreateBulk(ctx context.Context, measurements []*models.Measurement) (*repository.BulkResult, error) {
-	args := m.Called(ctx, measurements)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.BulkResult), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) CreateBatch(ctx context.Context, batch *models.MeasurementBatch) error {
-	args := m.Called(ctx, batch)
-	return args.Error(0)
-}
-
-func (m *MockMeasurementRepository) List(ctx context.Context, filter repository.MeasurementFilter) ([]*models.Measurement, error) {
-	args := m.Called(ctx, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Measurement), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) Count(ctx context.Context, filter repository.MeasurementFilter) (int64, error) {
-	args := m.Called(ctx, filter)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) GetByTimeRange(ctx context.Context, deviceID string, startTime, endTime time.Time) ([]*models.Measurement, error) {
-	args := m.Called(ctx, deviceID, startTime, endTime)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Measurement), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) GetLatest(ctx context.Context, deviceID string, measurementType string) (*models.Measurement, error) {
-	args := m.Called(ctx, deviceID, measurementType)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Measurement), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) GetLatestByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Measurement, error) {
-	args := m.Called(ctx, deviceID, limit)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Measurement), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) Aggregate(ctx context.Context, req repository.AggregationRequest) ([]*repository.AggregationResult, error) {
-	args := m.Called(ctx, req)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*repository.AggregationResult), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) GetStatistics(ctx context.Context, filter repository.MeasurementFilter) (*models.MeasurementStats, error) {
-	args := m.Called(ctx, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.MeasurementStats), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
-	args := m.Called(ctx, threshold)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *MockMeasurementRepository) DeleteByDevice(ctx context.Context, deviceID string) (int64, error) {
-	args := m.Called(ctx, deviceID)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-// MockCommandRepository is a mock implementation of CommandRepository
-type MockCommandRepository struct {
-	mock.Mock
-}
-
-func (m *MockCommandRepository) Create(ctx context.Context, command *models.Command) error {
-	args := m.Called(ctx, command)
-	return args.Error(0)
-}
-
-func (m *MockCommandRepository) GetByID(ctx context.Context, id string) (*models.Command, error) {
-	args := m.Called(ctx, id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Command), args.Error(1)
-}
-
-func (m *MockCommandRepository) GetByCommandID(ctx context.Context, commandID string) (*models.Command, error) {
-	args := m.Called(ctx, commandID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Command), args.Error(1)
-}
-
-func (m *MockCommandRepository) Update(ctx context.Context, command *models.Command) error {
-	args := m.Called(ctx, command)
-	return args.Error(0)
-}
-
-func (m *MockCommandRepository) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
-}
-
-func (m *MockCommandRepository) List(ctx context.Context, filter repository.CommandFilter) ([]*models.Command, error) {
-	args := m.Called(ctx, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Command), args.Error(1)
-}
-
-func (m *MockCommandRepository) Count(ctx context.Context, filter repository.CommandFilter) (int64, error) {
-	args := m.Called(ctx, filter)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *MockCommandRepository) GetPendingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
-	args := m.Called(ctx, deviceID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Command), args.Error(1)
-}
-
-func (m *MockCommandRepository) GetExecutingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
-	args := m.Called(ctx, deviceID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Command), args.Error(1)
-}
-
-func (m *MockCommandRepository) UpdateStatus(ctx context.Context, commandID string, status models.CommandStatus) error {
-	args := m.Called(ctx, commandID, status)
-	return args.Error(0)
-}
-
-func (m *MockCommandRepository) GetExpiredCommands(ctx context.Context) ([]*models.Command, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Command), args.Error(1)
-}
-
-func (m *MockCommandRepository) MarkExpiredAsTimeout(ctx context.Context) (int64, error) {
-	args := m.Called(ctx)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *MockCommandRepository) DeleteCompletedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
-	args := m.Called(ctx, threshold)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *MockCommandRepository) GetCommandStats(ctx context.Context, deviceID string, timeRange repository.TimeRangeFilter) (map[models.CommandStatus]int64, error) {
-	args := m.Called(ctx, deviceID, timeRange)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(map[models.CommandStatus]int64), args.Error(1)
-}
-
 func TestDeviceHandler_RegisterDevice(t *testing.T) {
-	// Setup
-	mockRepos := &MockRepositoryManager{}
-	mockDeviceRepo := &MockDeviceRepository{}
 	logger := logger.NewDefaultLogger()
-	connMgr := device.NewConnectionManager(logger)
-	
-	handler := NewDeviceHandler(mockRepos, connMgr, logger)
 	
 	tests := []struct {
 		name           string
 		request        *pb.RegisterDeviceRequest
-		setupMocks     func()
+		setup          func(repos repository.RepositoryManager)
 		expectedError  bool
 		expectedStatus bool
 	}{
@@ -477,12 +44,7 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 					"location": "lab-1",
 				},
 			},
-			setupMocks: func() {
-				mockRepos.On("Device").Return(mockDeviceRepo)
-				mockDeviceRepo.On("GetByID", mock.Anything, "test-device-1").Return(nil, repository.ErrNotFound)
-				mockDeviceRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Device")).Return(nil)
-				mockDeviceRepo.On("UpdateStatus", mock.Anything, "test-device-1", models.DeviceStatusOnline).Return(nil)
-			},
+			setup:          func(repos repository.RepositoryManager) {},
 			expectedError:  false,
 			expectedStatus: true,
 		},
@@ -495,7 +57,7 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 				Version:      "2.0.0",
 				Capabilities: []string{"temperature"},
 			},
-			setupMocks: func() {
+			setup: func(repos repository.RepositoryManager) {
 				existingDevice := &models.Device{
 					ID:           "existing-device",
 					Name:         "Old Device",
@@ -507,10 +69,7 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 					UpdatedAt:    time.Now().Add(-time.Hour),
 				}
 				
-				mockRepos.On("Device").Return(mockDeviceRepo)
-				mockDeviceRepo.On("GetByID", mock.Anything, "existing-device").Return(existingDevice, nil)
-				mockDeviceRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Device")).Return(nil)
-				mockDeviceRepo.On("UpdateStatus", mock.Anything, "existing-device", models.DeviceStatusOnline).Return(nil)
+				require.NoError(t, repos.Device().Create(context.Background(), existingDevice))
 			},
 			expectedError:  false,
 			expectedStatus: true,
@@ -524,7 +83,7 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 				Version:      "1.0.0",
 				Capabilities: []string{"temperature"},
 			},
-			setupMocks:     func() {},
+			setup:          func(repos repository.RepositoryManager) {},
 			expectedError:  true,
 			expectedStatus: false,
 		},
@@ -537,7 +96,7 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 				Version:      "1.0.0",
 				Capabilities: []string{"temperature"},
 			},
-			setupMocks:     func() {},
+			setup:          func(repos repository.RepositoryManager) {},
 			expectedError:  true,
 			expectedStatus: false,
 		},
@@ -550,7 +109,7 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 				Version:      "1.0.0",
 				Capabilities: []string{},
 			},
-			setupMocks:     func() {},
+			setup:          func(repos repository.RepositoryManager) {},
 			expectedError:  true,
 			expectedStatus: false,
 		},
@@ -558,12 +117,10 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 	
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset mocks
-			mockRepos.ExpectedCalls = nil
-			mockDeviceRepo.ExpectedCalls = nil
-			
-			// Setup mocks
-			tt.setupMocks()
+			// Setup
+			repos := memory.NewRepositoryManager()
+			tt.setup(repos)
+			handler := NewDeviceHandler(repos, device.NewConnectionManager(device.Config{}, logger), logger)
 			
 			// Execute
 			ctx := context.Background()
@@ -579,11 +136,13 @@ func TestDeviceHandler_RegisterDevice(t *testing.T) {
 				assert.Equal(t, tt.expectedStatus, resp.Success)
 				assert.NotEmpty(t, resp.SessionId)
 				assert.NotNil(t, resp.RegisteredAt)
+				
+				stored, err := repos.Device().GetByID(ctx, tt.request.DeviceId)
+				require.NoError(t, err)
+				assert.Equal(t, tt.request.Name, stored.Name)
+				assert.Equal(t, tt.request.Version, stored.Version)
+				assert.Equal(t, models.DeviceStatusOnline, stored.Status)
 			}
-			
-			// Verify mocks
-			mockRepos.AssertExpectations(t)
-			mockDeviceRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -721,4 +280,189 @@ func TestDeviceHandler_createDeviceFromRequest(t *testing.T) {
 	assert.False(t, device.RegisteredAt.IsZero())
 	assert.False(t, device.CreatedAt.IsZero())
 	assert.False(t, device.UpdatedAt.IsZero())
-}
\ No newline at end of file
+}
+
+func TestDeviceHandler_RegisterDevice_RequiresCredentials(t *testing.T) {
+	logger := logger.NewDefaultLogger()
+	enrolled := func() *models.Device {
+		return &models.Device{
+			ID:       "hplc-01",
+			Name:     "HPLC 1",
+			Type:     "analyzer",
+			Status:   models.DeviceStatusOffline,
+			Metadata: map[string]interface{}{models.DeviceGroupMetadataKey: "lab-a"},
+		}
+	}
+	request := func(deviceType, group string) *pb.RegisterDeviceRequest {
+		return &pb.RegisterDeviceRequest{
+			DeviceId:     "hplc-01",
+			Name:         "HPLC 1",
+			Type:         deviceType,
+			Version:      "1.0.0",
+			Capabilities: []string{"spectrum"},
+			Metadata:     map[string]string{models.DeviceGroupMetadataKey: group},
+		}
+	}
+	deviceIdentity := func(deviceIDs ...string) context.Context {
+		return auth.WithIdentity(context.Background(), &auth.Identity{
+			Subject:   "hplc-01",
+			Method:    auth.MethodAPIKey,
+			DeviceIDs: deviceIDs,
+		})
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		request  *pb.RegisterDeviceRequest
+		existing *models.Device
+		code     codes.Code
+	}{
+		{"no credentials", context.Background(), request("analyzer", "lab-a"), nil, codes.Unauthenticated},
+		{"credentials for another device", deviceIdentity("hplc-02"), request("analyzer", "lab-a"), enrolled(), codes.PermissionDenied},
+		{"changes enrolled type", deviceIdentity("hplc-01"), request("sensor", "lab-a"), enrolled(), codes.PermissionDenied},
+		{"changes enrolled group", deviceIdentity("hplc-01"), request("analyzer", "lab-b"), enrolled(), codes.PermissionDenied},
+		{"enrolled device", deviceIdentity("hplc-01"), request("analyzer", "lab-a"), enrolled(), codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := memory.NewRepositoryManager()
+			if tt.existing != nil {
+				require.NoError(t, repos.Device().Create(ctx, tt.existing))
+			}
+
+			handler := NewDeviceHandler(repos, device.NewConnectionManager(device.Config{}, logger), logger)
+			handler.RequireCredentials(alerting.NewManager(repos, nil, logger))
+
+			_, err := handler.RegisterDevice(tt.ctx, tt.request)
+			assert.Equal(t, tt.code, status.Code(err))
+
+			raised, err := repos.Alert().List(ctx, repository.AlertFilter{})
+			require.NoError(t, err)
+			stored, err := repos.Device().GetByID(ctx, "hplc-01")
+
+			if tt.code == codes.OK {
+				assert.Empty(t, raised)
+				require.NoError(t, err)
+				assert.Equal(t, "1.0.0", stored.Version)
+				return
+			}
+			if assert.Len(t, raised, 1) {
+				assert.Equal(t, models.AlertTypeSecurityBreach, raised[0].Type)
+				assert.Equal(t, "hplc-01", raised[0].Metadata["claimed_device_id"])
+				assert.Equal(t, tt.existing != nil, raised[0].DeviceID != nil)
+			}
+			if tt.existing == nil {
+				assert.ErrorIs(t, err, repository.ErrNotFound)
+			} else {
+				require.NoError(t, err)
+				assert.Empty(t, stored.Version, "rejected registrations leave the device unchanged")
+			}
+		})
+	}
+}
+
+// unavailableAudit fails every audit append, including those made within
+// transactions
+type unavailableAudit struct {
+	repository.RepositoryManager
+}
+
+func (u unavailableAudit) Audit() repository.AuditRepository {
+	return unavailableAuditRepo{u.RepositoryManager.Audit()}
+}
+
+func (u unavailableAudit) WithTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.RepositoryManager) error) error {
+	return u.RepositoryManager.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		return fn(ctx, unavailableAudit{repos})
+	})
+}
+
+type unavailableAuditRepo struct {
+	repository.AuditRepository
+}
+
+func (unavailableAuditRepo) Append(ctx context.Context, entry *models.AuditEntry) error {
+	return errors.New("audit trail unavailable")
+}
+
+func TestDeviceHandler_RegisterDevice_AuditedAtomically(t *testing.T) {
+	logger := logger.NewDefaultLogger()
+	request := &pb.RegisterDeviceRequest{
+		DeviceId:     "hplc-01",
+		Name:         "HPLC 1",
+		Type:         "analyzer",
+		Version:      "1.0.0",
+		Capabilities: []string{"spectrum"},
+	}
+	audited := func() context.Context {
+		ctx, _ := audit.WithChange(context.Background(), models.AuditEntry{
+			Action:       pb.LabInstrumentGateway_RegisterDevice_FullMethodName,
+			ResourceType: "device",
+		})
+		return ctx
+	}
+
+	t.Run("audit entry written with the device", func(t *testing.T) {
+		repos := memory.NewRepositoryManager()
+		handler := NewDeviceHandler(repos, device.NewConnectionManager(device.Config{}, logger), logger)
+
+		_, err := handler.RegisterDevice(audited(), request)
+		require.NoError(t, err)
+
+		entries, err := repos.Audit().List(context.Background(), repository.AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "hplc-01", entries[0].ResourceID)
+
+		events, err := repos.DeviceEvent().Count(context.Background(), repository.DeviceEventFilter{DeviceID: "hplc-01"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), events, "the status change is recorded")
+	})
+
+	t.Run("failed audit append rolls back the registration", func(t *testing.T) {
+		repos := memory.NewRepositoryManager()
+		connections := device.NewConnectionManager(device.Config{}, logger)
+		handler := NewDeviceHandler(unavailableAudit{repos}, connections, logger)
+
+		_, err := handler.RegisterDevice(audited(), request)
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		_, err = repos.Device().GetByID(context.Background(), "hplc-01")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		assert.Zero(t, connections.GetConnectionCount())
+	})
+}
+
+func TestDeviceHandler_RegisterDevice_KeepsMaintenance(t *testing.T) {
+	logger := logger.NewDefaultLogger()
+	repos := memory.NewRepositoryManager()
+	ctx := context.Background()
+	require.NoError(t, repos.Device().Create(ctx, &models.Device{
+		ID:     "hplc-01",
+		Name:   "HPLC 1",
+		Type:   "analyzer",
+		Status: models.DeviceStatusMaintenance,
+	}))
+	handler := NewDeviceHandler(repos, device.NewConnectionManager(device.Config{}, logger), logger)
+
+	// Reconnecting during a maintenance window keeps its alerts muted
+	_, err := handler.RegisterDevice(ctx, &pb.RegisterDeviceRequest{
+		DeviceId:     "hplc-01",
+		Name:         "HPLC 1",
+		Type:         "analyzer",
+		Version:      "1.0.0",
+		Capabilities: []string{"spectrum"},
+	})
+	require.NoError(t, err)
+
+	stored, err := repos.Device().GetByID(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, models.DeviceStatusMaintenance, stored.Status)
+
+	events, err := repos.DeviceEvent().Count(ctx, repository.DeviceEventFilter{DeviceID: "hplc-01"})
+	require.NoError(t, err)
+	assert.Zero(t, events, "no status change is recorded")
+}
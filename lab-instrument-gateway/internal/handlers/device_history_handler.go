@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// defaultHistoryWindow is the history window used when no start time is given
+const defaultHistoryWindow = 24 * time.Hour
+
+// DeviceHistoryHandler handles device lifecycle history gRPC operations
+type DeviceHistoryHandler struct {
+	repos  repository.RepositoryManager
+	logger *logger.Logger
+}
+
+// NewDeviceHistoryHandler creates a new device history handler
+func NewDeviceHistoryHandler(repos repository.RepositoryManager, logger *logger.Logger) *DeviceHistoryHandler {
+	return &DeviceHistoryHandler{
+		repos:  repos,
+		logger: logger,
+	}
+}
+
+// GetDeviceHistory returns a paged timeline of device events, newest first,
+// together with the device's uptime over the requested window
+func (h *DeviceHistoryHandler) GetDeviceHistory(ctx context.Context, req *pb.GetDeviceHistoryRequest) (*pb.GetDeviceHistoryResponse, error) {
+	if err := h.validateGetDeviceHistoryRequest(req); err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Invalid device history request")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.PageSize == 0 {
+		req.PageSize = 100
+	}
+
+	offset, err := decodePageOffset(req.PageToken)
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid page token")
+		return nil, status.Error(codes.InvalidArgument, "Invalid page token")
+	}
+
+	device, err := h.repos.Device().GetByID(ctx, req.DeviceId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "Device not found")
+		}
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to get device")
+		return nil, status.Error(codes.Internal, "Failed to retrieve device information")
+	}
+
+	windowStart, windowEnd := h.resolveWindow(req)
+
+	filter := repository.DeviceEventFilter{
+		Filter: repository.Filter{
+			Limit:  int(req.PageSize),
+			Offset: offset,
+		},
+		TimeRangeFilter: repository.TimeRangeFilter{
+			StartTime: &windowStart,
+			EndTime:   &windowEnd,
+		},
+		DeviceID: req.DeviceId,
+	}
+	for _, eventType := range req.EventTypes {
+		filter.Types = append(filter.Types, models.DeviceEventType(eventType))
+	}
+
+	events, err := h.repos.DeviceEvent().List(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to list device events")
+		return nil, status.Error(codes.Internal, "Failed to retrieve device history")
+	}
+
+	totalCount, err := h.repos.DeviceEvent().Count(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to count device events")
+		return nil, status.Error(codes.Internal, "Failed to count device history")
+	}
+
+	uptime, err := h.calculateUptime(ctx, device, windowStart, windowEnd)
+	if err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Error("Failed to calculate device uptime")
+		return nil, status.Error(codes.Internal, "Failed to calculate device uptime")
+	}
+
+	protoEvents := make([]*pb.DeviceEvent, len(events))
+	for i, event := range events {
+		protoEvents[i] = h.convertDeviceEventToProto(event)
+	}
+
+	return &pb.GetDeviceHistoryResponse{
+		Events:           protoEvents,
+		NextPageToken:    nextPageToken(offset, len(events), totalCount),
+		TotalCount:       int32(totalCount),
+		UptimePercentage: uptime,
+		WindowStart:      timestamppb.New(windowStart),
+		WindowEnd:        timestamppb.New(windowEnd),
+	}, nil
+}
+
+// validateGetDeviceHistoryRequest validates the device history request
+func (h *DeviceHistoryHandler) validateGetDeviceHistoryRequest(req *pb.GetDeviceHistoryRequest) error {
+	if strings.TrimSpace(req.DeviceId) == "" {
+		return fmt.Errorf("device_id is required")
+	}
+
+	if req.PageSize < 0 || req.PageSize > 1000 {
+		return fmt.Errorf("page_size must be between 0 and 1000")
+	}
+
+	if req.StartTime != nil && req.EndTime != nil && !req.EndTime.AsTime().After(req.StartTime.AsTime()) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+
+	for _, eventType := range req.EventTypes {
+		if !models.DeviceEventType(eventType).IsValid() {
+			return fmt.Errorf("invalid event type: %s", eventType)
+		}
+	}
+
+	return nil
+}
+
+// resolveWindow returns the requested window, defaulting to the last 24
+// hours and never extending into the future
+func (h *DeviceHistoryHandler) resolveWindow(req *pb.GetDeviceHistoryRequest) (time.Time, time.Time) {
+	now := time.Now()
+
+	end := now
+	if req.EndTime != nil && req.EndTime.AsTime().Before(now) {
+		end = req.EndTime.AsTime()
+	}
+
+	start := end.Add(-defaultHistoryWindow)
+	if req.StartTime != nil {
+		start = req.StartTime.AsTime()
+	}
+	if start.After(end) {
+		start = end
+	}
+
+	return start, end
+}
+
+// calculateUptime returns the percentage of the window the device spent
+// online. Time before the device was registered is not counted.
+func (h *DeviceHistoryHandler) calculateUptime(ctx context.Context, device *models.Device, start, end time.Time) (float64, error) {
+	if device.RegisteredAt.After(start) {
+		start = device.RegisteredAt
+	}
+	if !end.After(start) {
+		return 0, nil
+	}
+
+	// Status at the start of the window is the target of the last change before it
+	initial := models.DeviceStatusOffline
+	last, err := h.repos.DeviceEvent().GetLastBefore(ctx, device.ID, models.DeviceEventStatusChanged, start)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return 0, err
+	}
+	if last != nil && last.ToStatus != nil {
+		initial = *last.ToStatus
+	}
+
+	changes, err := h.repos.DeviceEvent().List(ctx, repository.DeviceEventFilter{
+		Filter: repository.Filter{
+			Order: "ASC",
+		},
+		TimeRangeFilter: repository.TimeRangeFilter{
+			StartTime: &start,
+			EndTime:   &end,
+		},
+		DeviceID: device.ID,
+		Types:    []models.DeviceEventType{models.DeviceEventStatusChanged},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return models.Uptime(initial, changes, start, end), nil
+}
+
+// convertDeviceEventToProto converts a device event to protobuf format
+func (h *DeviceHistoryHandler) convertDeviceEventToProto(event *models.DeviceEvent) *pb.DeviceEvent {
+	result := &pb.DeviceEvent{
+		Id:         event.ID,
+		DeviceId:   event.DeviceID,
+		Type:       string(event.Type),
+		Message:    event.Message,
+		Metadata:   make(map[string]string, len(event.Metadata)),
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+
+	if event.SessionID != nil {
+		result.SessionId = *event.SessionID
+	}
+	if event.FromStatus != nil {
+		result.FromStatus = h.convertDeviceStatusToProto(*event.FromStatus)
+	}
+	if event.ToStatus != nil {
+		result.ToStatus = h.convertDeviceStatusToProto(*event.ToStatus)
+	}
+	for k, v := range event.Metadata {
+		result.Metadata[k] = fmt.Sprintf("%v", v)
+	}
+
+	return result
+}
+
+// convertDeviceStatusToProto converts internal device status to protobuf enum
+func (h *DeviceHistoryHandler) convertDeviceStatusToProto(status models.DeviceStatus) pb.DeviceStatus {
+	switch status {
+	case models.DeviceStatusOnline:
+		return pb.DeviceStatus_DEVICE_STATUS_ONLINE
+	case models.DeviceStatusOffline:
+		return pb.DeviceStatus_DEVICE_STATUS_OFFLINE
+	case models.DeviceStatusError:
+		return pb.DeviceStatus_DEVICE_STATUS_ERROR
+	case models.DeviceStatusMaintenance:
+		return pb.DeviceStatus_DEVICE_STATUS_MAINTENANCE
+	case models.DeviceStatusConnecting:
+		return pb.DeviceStatus_DEVICE_STATUS_CONNECTING
+	default:
+		return pb.DeviceStatus_DEVICE_STATUS_UNKNOWN
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+func statusChange(at time.Time, from, to models.DeviceStatus) *models.DeviceEvent {
+	return &models.DeviceEvent{
+		ID:         uuid.New().String(),
+		DeviceID:   "device-1",
+		Type:       models.DeviceEventStatusChanged,
+		FromStatus: &from,
+		ToStatus:   &to,
+		OccurredAt: at,
+	}
+}
+
+func TestDeviceHistoryHandler_GetDeviceHistory(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositoryManager()
+	handler := NewDeviceHistoryHandler(repos, logger.NewDefaultLogger())
+
+	end := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	start := end.Add(-10 * time.Hour)
+
+	require.NoError(t, repos.Device().Create(ctx, &models.Device{
+		ID:           "device-1",
+		Name:         "Device 1",
+		Type:         "sensor",
+		Status:       models.DeviceStatusOnline,
+		RegisteredAt: start.Add(-48 * time.Hour),
+	}))
+
+	// Online from the start, offline for 2 hours, then back online
+	for _, event := range []*models.DeviceEvent{
+		statusChange(start.Add(-time.Hour), models.DeviceStatusOffline, models.DeviceStatusOnline),
+		statusChange(start.Add(4*time.Hour), models.DeviceStatusOnline, models.DeviceStatusOffline),
+		{
+			ID:         uuid.New().String(),
+			DeviceID:   "device-1",
+			Type:       models.DeviceEventStreamOpened,
+			OccurredAt: start.Add(5 * time.Hour),
+		},
+		statusChange(start.Add(6*time.Hour), models.DeviceStatusOffline, models.DeviceStatusOnline),
+	} {
+		require.NoError(t, repos.DeviceEvent().Create(ctx, event))
+	}
+
+	resp, err := handler.GetDeviceHistory(ctx, &pb.GetDeviceHistoryRequest{
+		DeviceId:  "device-1",
+		StartTime: timestamppb.New(start),
+		EndTime:   timestamppb.New(end),
+		PageSize:  2,
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, resp.Events, 2)
+	assert.Equal(t, pb.DeviceStatus_DEVICE_STATUS_ONLINE, resp.Events[0].ToStatus)
+	assert.Equal(t, int32(3), resp.TotalCount)
+	assert.NotEmpty(t, resp.NextPageToken)
+	assert.InDelta(t, 80.0, resp.UptimePercentage, 0.01)
+}
+
+func TestDeviceHistoryHandler_GetDeviceHistory_Validation(t *testing.T) {
+	handler := NewDeviceHistoryHandler(memory.NewRepositoryManager(), logger.NewDefaultLogger())
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		request *pb.GetDeviceHistoryRequest
+	}{
+		{
+			name:    "missing device id",
+			request: &pb.GetDeviceHistoryRequest{},
+		},
+		{
+			name: "end before start",
+			request: &pb.GetDeviceHistoryRequest{
+				DeviceId:  "device-1",
+				StartTime: timestamppb.New(now),
+				EndTime:   timestamppb.New(now.Add(-time.Hour)),
+			},
+		},
+		{
+			name:    "unknown event type",
+			request: &pb.GetDeviceHistoryRequest{DeviceId: "device-1", EventTypes: []string{"exploded"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.GetDeviceHistory(context.Background(), tt.request)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/yourorg/lab-gateway/internal/rbac"
 	"github.com/yourorg/lab-gateway/pkg/logger"
 	"github.com/yourorg/lab-gateway/pkg/models"
 	"github.com/yourorg/lab-gateway/pkg/repository"
@@ -67,6 +68,16 @@ func (h *DeviceListHandler) ListDevices(ctx context.Context, req *pb.ListDevices
 	// Build device filter
 	deviceFilter := h.buildDeviceFilter(req, offset)
 
+	// Restrict the listing to the device groups the caller may read
+	if grants, ok := rbac.GrantsFromContext(ctx); ok {
+		if groups, all := grants.Groups(models.PermissionDevicesRead); !all {
+			if len(groups) == 0 {
+				return nil, status.Error(codes.PermissionDenied, "permission denied")
+			}
+			deviceFilter.Groups = groups
+		}
+	}
+
 	// Get devices
 	devices, err := h.repos.Device().List(ctx, deviceFilter)
 	if err != nil {
@@ -180,6 +191,9 @@ func (h *DeviceListHandler) validateDeviceFilter(filter *pb.DeviceFilter) error
 			if len(value) > 1000 {
 				return fmt.Errorf("metadata filter value too long for key %s", key)
 			}
+			if !repository.FilterableMetadata(key) {
+				return fmt.Errorf("metadata filter key %s is encrypted and cannot be filtered on", key)
+			}
 		}
 	}
 
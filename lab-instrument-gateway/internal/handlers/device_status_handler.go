@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -46,7 +47,7 @@ func (h *DeviceStatusHandler) GetDeviceStatus(ctx context.Context, req *pb.GetDe
 	// Get device information
 	device, err := h.repos.Device().GetByID(ctx, req.DeviceId)
 	if err != nil {
-		if err == repository.ErrNotFound {
+		if errors.Is(err, repository.ErrNotFound) {
 			h.logger.WithField("device_id", req.DeviceId).Warn("Device not found")
 			return nil, status.Error(codes.NotFound, "Device not found")
 		}
@@ -54,8 +55,12 @@ func (h *DeviceStatusHandler) GetDeviceStatus(ctx context.Context, req *pb.GetDe
 		return nil, status.Error(codes.Internal, "Failed to retrieve device information")
 	}
 
-	// Get connection status
-	connectionStatus := h.connectionManager.GetConnectionStatus(req.DeviceId)
+	// Get connection status, from the replica holding the connection
+	registration, err := h.connectionManager.LookupConnection(ctx, req.DeviceId)
+	if err != nil {
+		h.logger.WithError(err).WithField("device_id", req.DeviceId).Warn("Failed to look up device connection")
+	}
+	connectionStatus := registeredConnection(registration)
 	
 	// Update device status based on connection
 	actualStatus := h.determineActualDeviceStatus(device, connectionStatus)
@@ -84,6 +89,9 @@ func (h *DeviceStatusHandler) GetDeviceStatus(ctx context.Context, req *pb.GetDe
 
 	// Prepare metadata with performance metrics
 	metadata := h.prepareStatusMetadata(device, connectionStatus)
+	if registration != nil {
+		metadata["replica_id"] = registration.ReplicaID
+	}
 
 	// Convert last seen timestamp
 	var lastSeenProto *timestamppb.Timestamp
@@ -101,6 +109,15 @@ func (h *DeviceStatusHandler) GetDeviceStatus(ctx context.Context, req *pb.GetDe
 	}, nil
 }
 
+// registeredConnection returns the connection status of a registration, or
+// nil without one
+func registeredConnection(registration *device.Registration) *device.ConnectionStatus {
+	if registration == nil {
+		return nil
+	}
+	return &registration.Connection
+}
+
 // validateGetDeviceStatusRequest validates the device status request
 func (h *DeviceStatusHandler) validateGetDeviceStatusRequest(req *pb.GetDeviceStatusRequest) error {
 	if req == nil {
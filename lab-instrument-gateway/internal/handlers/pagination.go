@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// decodePageOffset parses an offset-based pagination token. An empty token
+// starts at the first page.
+func decodePageOffset(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid token encoding: %w", err)
+	}
+
+	var pageToken PageToken
+	if err := json.Unmarshal(decoded, &pageToken); err != nil {
+		return 0, fmt.Errorf("invalid token format: %w", err)
+	}
+
+	if pageToken.Offset < 0 {
+		return 0, fmt.Errorf("invalid token offset: %d", pageToken.Offset)
+	}
+
+	return pageToken.Offset, nil
+}
+
+// encodePageOffset generates the pagination token for the page at offset
+func encodePageOffset(offset int) string {
+	tokenBytes, _ := json.Marshal(PageToken{Offset: offset})
+	return base64.URLEncoding.EncodeToString(tokenBytes)
+}
+
+// nextPageToken returns the token for the page after one holding count items
+// starting at offset, or "" if there are no more results
+func nextPageToken(offset, count int, total int64) string {
+	if int64(offset+count) < total {
+		return encodePageOffset(offset + count)
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/provisioning"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// ProvisioningHandler handles device enrollment gRPC operations
+type ProvisioningHandler struct {
+	service *provisioning.Service
+	logger  *logger.Logger
+}
+
+// NewProvisioningHandler creates a new provisioning handler
+func NewProvisioningHandler(service *provisioning.Service, logger *logger.Logger) *ProvisioningHandler {
+	return &ProvisioningHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateEnrollmentToken handles enrollment token creation requests
+func (h *ProvisioningHandler) CreateEnrollmentToken(ctx context.Context, req *pb.CreateEnrollmentTokenRequest) (*pb.CreateEnrollmentTokenResponse, error) {
+	if strings.TrimSpace(req.DeviceType) == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_type is required")
+	}
+	if req.TtlSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds cannot be negative")
+	}
+
+	createdBy := auth.Subject(ctx)
+	if createdBy == "" {
+		createdBy = "anonymous"
+	}
+
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	token, record, err := h.service.CreateEnrollmentToken(ctx, req.DeviceType, strings.TrimSpace(req.DeviceGroup), createdBy, ttl)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrInvalidTokenLifetime) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		h.logger.WithError(err).Error("Failed to create enrollment token")
+		return nil, status.Error(codes.Internal, "Failed to create enrollment token")
+	}
+
+	return &pb.CreateEnrollmentTokenResponse{
+		Token:     token,
+		TokenId:   record.ID,
+		ExpiresAt: timestamppb.New(record.ExpiresAt),
+	}, nil
+}
+
+// EnrollDevice exchanges an enrollment token for a device credential
+func (h *ProvisioningHandler) EnrollDevice(ctx context.Context, req *pb.EnrollDeviceRequest) (*pb.EnrollDeviceResponse, error) {
+	if req.EnrollmentToken == "" {
+		return nil, status.Error(codes.Unauthenticated, "enrollment_token is required")
+	}
+	if strings.TrimSpace(req.DeviceId) == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_id is required")
+	}
+	if len(req.DeviceId) > 255 {
+		return nil, status.Error(codes.InvalidArgument, "device_id too long (max 255 characters)")
+	}
+
+	credentialType, ok := credentialTypeFromProto(req.CredentialType)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "credential_type must be API_KEY or CERTIFICATE")
+	}
+
+	credential, err := h.service.Enroll(ctx, provisioning.EnrollRequest{
+		Token:          req.EnrollmentToken,
+		DeviceID:       req.DeviceId,
+		Name:           req.Name,
+		CredentialType: credentialType,
+		CSR:            req.Csr,
+	})
+	if err != nil {
+		fields := map[string]interface{}{
+			"device_id":       req.DeviceId,
+			"credential_type": credentialType,
+			"error":           err.Error(),
+		}
+
+		switch {
+		case errors.Is(err, provisioning.ErrInvalidEnrollmentToken):
+			h.logger.WithFields(fields).Warn("Enrollment rejected")
+			return nil, status.Error(codes.Unauthenticated, "invalid, used or expired enrollment token")
+		case errors.Is(err, provisioning.ErrEnrollmentConflict):
+			h.logger.WithFields(fields).Warn("Enrollment rejected")
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, provisioning.ErrCertificatesUnavailable):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, provisioning.ErrInvalidCSR), errors.Is(err, provisioning.ErrUnsupportedCredential):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		h.logger.WithFields(fields).Error("Failed to enroll device")
+		return nil, status.Error(codes.Internal, "Failed to enroll device")
+	}
+
+	response := &pb.EnrollDeviceResponse{
+		DeviceId:       credential.DeviceID,
+		CredentialType: req.CredentialType,
+		ApiKey:         credential.APIKey,
+		Certificate:    credential.Certificate,
+		CaCertificate:  credential.CACertificate,
+	}
+	if credential.ExpiresAt != nil {
+		response.ExpiresAt = timestamppb.New(*credential.ExpiresAt)
+	}
+
+	return response, nil
+}
+
+// credentialTypeFromProto converts a protobuf credential type
+func credentialTypeFromProto(credentialType pb.CredentialType) (provisioning.CredentialType, bool) {
+	switch credentialType {
+	case pb.CredentialType_CREDENTIAL_TYPE_API_KEY:
+		return provisioning.CredentialAPIKey, true
+	case pb.CredentialType_CREDENTIAL_TYPE_CERTIFICATE:
+		return provisioning.CredentialCertificate, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// SilenceHandler handles alert silence gRPC operations
+type SilenceHandler struct {
+	repos  repository.RepositoryManager
+	logger *logger.Logger
+}
+
+// NewSilenceHandler creates a new silence handler
+func NewSilenceHandler(repos repository.RepositoryManager, logger *logger.Logger) *SilenceHandler {
+	return &SilenceHandler{
+		repos:  repos,
+		logger: logger,
+	}
+}
+
+// CreateSilence handles silence creation requests
+func (h *SilenceHandler) CreateSilence(ctx context.Context, req *pb.CreateSilenceRequest) (*pb.CreateSilenceResponse, error) {
+	if err := h.validateCreateSilenceRequest(req); err != nil {
+		h.logger.WithError(err).Error("Invalid create silence request")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	silence := h.convertProtoToSilence(req.Silence)
+
+	// The silence mutes alerts only once its audit entry is written
+	err := h.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if err := repos.Silence().Create(ctx, silence); err != nil {
+			return fmt.Errorf("failed to create silence: %w", err)
+		}
+		return audit.CommitChange(ctx, repos.Audit(), silence.ID, nil, silence)
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create silence")
+		return nil, status.Error(codes.Internal, "Failed to create silence")
+	}
+
+	return &pb.CreateSilenceResponse{
+		Silence: h.convertSilenceToProto(silence),
+	}, nil
+}
+
+// ListSilences handles silence listing requests
+func (h *SilenceHandler) ListSilences(ctx context.Context, req *pb.ListSilencesRequest) (*pb.ListSilencesResponse, error) {
+	if req.PageSize < 0 || req.PageSize > 1000 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 0 and 1000")
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 50
+	}
+
+	offset, err := decodePageOffset(req.PageToken)
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid page token")
+		return nil, status.Error(codes.InvalidArgument, "Invalid page token")
+	}
+
+	filter := repository.SilenceFilter{
+		Filter: repository.Filter{
+			Limit:  int(req.PageSize),
+			Offset: offset,
+		},
+	}
+	if req.DeviceId != "" {
+		filter.DeviceIDs = []string{req.DeviceId}
+	}
+	if req.ActiveOnly {
+		now := time.Now()
+		filter.ActiveAt = &now
+	}
+
+	silences, err := h.repos.Silence().List(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list silences")
+		return nil, status.Error(codes.Internal, "Failed to retrieve silences")
+	}
+
+	totalCount, err := h.repos.Silence().Count(ctx, filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to count silences")
+		return nil, status.Error(codes.Internal, "Failed to count silences")
+	}
+
+	protoSilences := make([]*pb.Silence, len(silences))
+	for i, silence := range silences {
+		protoSilences[i] = h.convertSilenceToProto(silence)
+	}
+
+	return &pb.ListSilencesResponse{
+		Silences:      protoSilences,
+		NextPageToken: nextPageToken(offset, len(silences), totalCount),
+		TotalCount:    int32(totalCount),
+	}, nil
+}
+
+// ExpireSilence handles requests to end a silence early
+func (h *SilenceHandler) ExpireSilence(ctx context.Context, req *pb.ExpireSilenceRequest) (*pb.ExpireSilenceResponse, error) {
+	if strings.TrimSpace(req.SilenceId) == "" {
+		return nil, status.Error(codes.InvalidArgument, "silence_id is required")
+	}
+
+	err := h.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		before, err := repos.Silence().GetByID(ctx, req.SilenceId)
+		if err != nil {
+			return err
+		}
+		if err := repos.Silence().Expire(ctx, req.SilenceId); err != nil {
+			return err
+		}
+		after, err := repos.Silence().GetByID(ctx, req.SilenceId)
+		if err != nil {
+			return err
+		}
+		return audit.CommitChange(ctx, repos.Audit(), req.SilenceId, before, after)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "Silence not found")
+		}
+		h.logger.WithError(err).WithField("silence_id", req.SilenceId).Error("Failed to expire silence")
+		return nil, status.Error(codes.Internal, "Failed to expire silence")
+	}
+
+	return &pb.ExpireSilenceResponse{
+		Success: true,
+		Message: "Silence expired",
+	}, nil
+}
+
+// validateCreateSilenceRequest validates the silence creation request
+func (h *SilenceHandler) validateCreateSilenceRequest(req *pb.CreateSilenceRequest) error {
+	if req == nil || req.Silence == nil {
+		return fmt.Errorf("silence is required")
+	}
+
+	silence := req.Silence
+	if strings.TrimSpace(silence.CreatedBy) == "" {
+		return fmt.Errorf("created_by is required")
+	}
+
+	if silence.EndsAt == nil {
+		return fmt.Errorf("ends_at is required")
+	}
+
+	if silence.DeviceId == "" && silence.DeviceType == "" && silence.AlertType == "" {
+		return fmt.Errorf("at least one of device_id, device_type or alert_type is required")
+	}
+
+	if silence.AlertType != "" && !models.AlertType(silence.AlertType).IsValid() {
+		return fmt.Errorf("invalid alert_type: %s", silence.AlertType)
+	}
+
+	startsAt := time.Now()
+	if silence.StartsAt != nil {
+		startsAt = silence.StartsAt.AsTime()
+	}
+	if !silence.EndsAt.AsTime().After(startsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+
+	return nil
+}
+
+// convertProtoToSilence converts a protobuf silence to the internal model
+func (h *SilenceHandler) convertProtoToSilence(silence *pb.Silence) *models.Silence {
+	result := &models.Silence{
+		EndsAt:    silence.EndsAt.AsTime(),
+		CreatedBy: strings.TrimSpace(silence.CreatedBy),
+		Comment:   silence.Comment,
+	}
+
+	if silence.StartsAt != nil {
+		result.StartsAt = silence.StartsAt.AsTime()
+	}
+	if silence.DeviceId != "" {
+		deviceID := silence.DeviceId
+		result.DeviceID = &deviceID
+	}
+	if silence.DeviceType != "" {
+		deviceType := strings.ToLower(strings.TrimSpace(silence.DeviceType))
+		result.DeviceType = &deviceType
+	}
+	if silence.AlertType != "" {
+		alertType := models.AlertType(silence.AlertType)
+		result.AlertType = &alertType
+	}
+
+	return result
+}
+
+// convertSilenceToProto converts a silence model to protobuf format
+func (h *SilenceHandler) convertSilenceToProto(silence *models.Silence) *pb.Silence {
+	result := &pb.Silence{
+		Id:        silence.ID,
+		StartsAt:  timestamppb.New(silence.StartsAt),
+		EndsAt:    timestamppb.New(silence.EndsAt),
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+		CreatedAt: timestamppb.New(silence.CreatedAt),
+	}
+
+	if silence.DeviceID != nil {
+		result.DeviceId = *silence.DeviceID
+	}
+	if silence.DeviceType != nil {
+		result.DeviceType = *silence.DeviceType
+	}
+	if silence.AlertType != nil {
+		result.AlertType = string(*silence.AlertType)
+	}
+
+	return result
+}
@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/internal/ingest"
+	"github.com/yourorg/lab-gateway/internal/ratelimit"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// Stream error codes sent to devices in StreamError messages
+const (
+	streamErrorInvalidData    = "INVALID_DATA"
+	streamErrorIngestFailed   = "INGEST_FAILED"
+	streamErrorUnexpectedInit = "UNEXPECTED_INIT"
+	streamErrorRateLimited    = "RATE_LIMITED"
+)
+
+// drainingMessage is the status message of streams ended or refused while
+// the gateway shuts down
+const drainingMessage = "Gateway is shutting down, reconnect to another replica"
+
+// handOffFlushTimeout bounds writing the measurements of a stream that is
+// handed off
+const handOffFlushTimeout = 10 * time.Second
+
+// StreamHandler handles device data streams
+type StreamHandler struct {
+	connectionManager *device.ConnectionManager
+	ingester          *ingest.Ingester
+	limiter           *ratelimit.Limiter
+	dispatcher        *device.Dispatcher
+	logger            *logger.Logger
+
+	// streams holds the channel of each open stream that is closed to hand
+	// its device off; no streams are opened once draining
+	streamsMutex sync.Mutex
+	streams      map[string]chan struct{}
+	draining     bool
+	streamsDone  sync.WaitGroup
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(connMgr *device.ConnectionManager, ingester *ingest.Ingester, logger *logger.Logger) *StreamHandler {
+	return &StreamHandler{
+		connectionManager: connMgr,
+		ingester:          ingester,
+		logger:            logger,
+		streams:           make(map[string]chan struct{}),
+	}
+}
+
+// LimitMessages throttles the measurement messages of each device with the
+// limiter. Messages over the limit are delayed, then rejected.
+func (h *StreamHandler) LimitMessages(limiter *ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// DeliverCommands registers each stream with the dispatcher, so that
+// commands are sent to the device over it, and delivers the commands that
+// were queued while the device had no stream
+func (h *StreamHandler) DeliverCommands(dispatcher *device.Dispatcher) {
+	h.dispatcher = dispatcher
+}
+
+// syncStream serializes sends on a stream, which commands dispatched from
+// other RPCs share with the stream's own responses
+type syncStream struct {
+	pb.LabInstrumentGateway_StreamDataServer
+	mutex sync.Mutex
+}
+
+// Send sends a response on the stream
+func (s *syncStream) Send(resp *pb.StreamDataResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.LabInstrumentGateway_StreamDataServer.Send(resp)
+}
+
+// received is a message read from a stream, or the error that ended it
+type received struct {
+	req *pb.StreamDataRequest
+	err error
+}
+
+// receive reads the messages of a stream in the background, until the
+// stream fails or done is closed
+func receive(stream pb.LabInstrumentGateway_StreamDataServer, done <-chan struct{}) <-chan received {
+	messages := make(chan received)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			select {
+			case messages <- received{req: req, err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return messages
+}
+
+// StreamData handles a device data stream. The first message must be a
+// StreamInit for a session obtained from RegisterDevice; after that the
+// device sends measurement data and heartbeats until it closes the stream,
+// or until the gateway drains and hands the device off.
+func (h *StreamHandler) StreamData(stream pb.LabInstrumentGateway_StreamDataServer) error {
+	stream = &syncStream{LabInstrumentGateway_StreamDataServer: stream}
+
+	streamID := uuid.New().String()
+	drain, ok := h.openStream(streamID)
+	if !ok {
+		return status.Error(codes.Unavailable, drainingMessage)
+	}
+	defer h.closeStream(streamID)
+
+	// Messages are received in the background, so that a drain can end the
+	// stream while it waits for the device
+	done := make(chan struct{})
+	defer close(done)
+	messages := receive(stream, done)
+
+	var first received
+	select {
+	case first = <-messages:
+	case <-drain:
+		return status.Error(codes.Unavailable, drainingMessage)
+	}
+	if first.err != nil {
+		return first.err
+	}
+
+	init := first.req.GetInit()
+	if init == nil {
+		return status.Error(codes.FailedPrecondition, "First stream message must be init")
+	}
+
+	if err := h.validateStreamInit(init); err != nil {
+		h.logger.WithError(err).WithField("device_id", init.DeviceId).Warn("Invalid stream init")
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Sessions handed off by another replica are taken over
+	session, err := h.connectionManager.ResumeSession(stream.Context(), init.DeviceId, init.SessionId)
+	if err != nil {
+		h.logger.WithError(err).WithField("device_id", init.DeviceId).Warn("Failed to resume session")
+	}
+	if session == nil || session.DeviceID != init.DeviceId {
+		return status.Error(codes.FailedPrecondition, "Unknown session, register the device first")
+	}
+
+	if err := h.connectionManager.AttachStream(init.DeviceId, streamID); err != nil {
+		h.logger.WithError(err).WithField("device_id", init.DeviceId).Warn("Failed to attach stream")
+		return status.Error(codes.FailedPrecondition, "Device is not connected")
+	}
+
+	closeReason := "stream ended"
+	defer func() {
+		if err := h.connectionManager.DetachStream(init.DeviceId, closeReason); err != nil {
+			h.logger.WithError(err).WithField("device_id", init.DeviceId).Debug("Failed to detach stream")
+		}
+	}()
+
+	if err := stream.Send(&pb.StreamDataResponse{
+		Message: &pb.StreamDataResponse_Ack{Ack: &pb.StreamAck{
+			Success:  true,
+			Message:  "Stream established",
+			StreamId: streamID,
+		}},
+	}); err != nil {
+		closeReason = "failed to acknowledge stream"
+		return err
+	}
+
+	if h.dispatcher != nil {
+		h.dispatcher.Attach(init.DeviceId, streamID, func(command *models.Command) error {
+			return stream.Send(&pb.StreamDataResponse{
+				Message: &pb.StreamDataResponse_Command{Command: h.convertCommandToProto(command)},
+			})
+		})
+		defer h.dispatcher.Detach(init.DeviceId, streamID)
+
+		if delivered, err := h.dispatcher.DeliverPending(stream.Context(), init.DeviceId); err != nil {
+			h.logger.WithError(err).WithField("device_id", init.DeviceId).Warn("Failed to deliver pending commands")
+		} else if delivered > 0 {
+			h.logger.WithFields(map[string]interface{}{
+				"device_id": init.DeviceId,
+				"commands":  delivered,
+			}).Info("Delivered pending commands")
+		}
+	}
+
+	// lastSequence is the sequence number of the last measurement message
+	// ingested, from which a handed off device resumes
+	var lastSequence int32
+	for {
+		var in received
+		select {
+		case in = <-messages:
+		case <-drain:
+			closeReason = "gateway shutting down"
+			return h.handOff(stream, init.DeviceId, lastSequence)
+		}
+
+		req, err := in.req, in.err
+		if errors.Is(err, io.EOF) {
+			closeReason = "client closed stream"
+			return nil
+		}
+		if err != nil {
+			closeReason = fmt.Sprintf("stream error: %v", status.Code(err))
+			if stream.Context().Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		_ = h.connectionManager.UpdateConnectionStats(init.DeviceId, 0, 1, 0, int64(proto.Size(req)))
+
+		switch msg := req.Message.(type) {
+		case *pb.StreamDataRequest_Data:
+			streamErr, err := h.throttleData(stream.Context(), init.DeviceId)
+			if err != nil {
+				closeReason = "stream context done while throttled"
+				return nil
+			}
+			if streamErr == nil {
+				streamErr = h.handleData(stream.Context(), init.DeviceId, msg.Data)
+			}
+			if streamErr == nil && msg.Data.SequenceNumber != 0 {
+				lastSequence = msg.Data.SequenceNumber
+			}
+			if streamErr != nil {
+				if err := h.sendError(stream, streamErr); err != nil {
+					closeReason = "failed to send stream error"
+					return err
+				}
+			}
+
+		case *pb.StreamDataRequest_Heartbeat:
+			if err := h.handleHeartbeat(stream, init.DeviceId, msg.Heartbeat); err != nil {
+				closeReason = "failed to answer heartbeat"
+				return err
+			}
+
+		case *pb.StreamDataRequest_Close:
+			closeReason = msg.Close.Reason
+			if closeReason == "" {
+				closeReason = "client closed stream"
+			}
+			return nil
+
+		case *pb.StreamDataRequest_Init:
+			if err := h.sendError(stream, &pb.StreamError{
+				Code:        streamErrorUnexpectedInit,
+				Message:     "Stream is already initialized",
+				Recoverable: true,
+			}); err != nil {
+				closeReason = "failed to send stream error"
+				return err
+			}
+		}
+	}
+}
+
+// openStream registers a stream, returning the channel that is closed when
+// it must hand its device off, or false if the gateway is draining
+func (h *StreamHandler) openStream(streamID string) (<-chan struct{}, bool) {
+	h.streamsMutex.Lock()
+	defer h.streamsMutex.Unlock()
+
+	if h.draining {
+		return nil, false
+	}
+
+	drain := make(chan struct{})
+	h.streams[streamID] = drain
+	h.streamsDone.Add(1)
+	return drain, true
+}
+
+// closeStream unregisters a stream that has ended
+func (h *StreamHandler) closeStream(streamID string) {
+	h.streamsMutex.Lock()
+	delete(h.streams, streamID)
+	h.streamsMutex.Unlock()
+
+	h.streamsDone.Done()
+}
+
+// Drain hands the devices of all open streams off for a shutdown. Each
+// stream stops reading, writes the measurements it received and sends the
+// device a Reconnect before ending with Unavailable, so that the device
+// resumes on another replica without losing data. New streams are refused.
+// Drain waits until the streams have ended, or until ctx is done.
+func (h *StreamHandler) Drain(ctx context.Context) error {
+	h.streamsMutex.Lock()
+	h.draining = true
+	count := len(h.streams)
+	for _, drain := range h.streams {
+		close(drain)
+	}
+	h.streams = make(map[string]chan struct{})
+	h.streamsMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.streamsDone.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		h.logger.WithField("streams", count).Info("Device streams handed off")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("device streams still open: %w", ctx.Err())
+	}
+}
+
+// handOff ends a stream while the gateway drains. The measurements received
+// so far are written first, so that the device only needs to resend the
+// messages after the last sequence number it is told.
+func (h *StreamHandler) handOff(stream pb.LabInstrumentGateway_StreamDataServer, deviceID string, lastSequence int32) error {
+	ctx, cancel := context.WithTimeout(context.Background(), handOffFlushTimeout)
+	defer cancel()
+
+	reconnect := &pb.Reconnect{Reason: "Gateway is shutting down"}
+	if err := h.ingester.Flush(ctx); err != nil {
+		// Without a sequence number the device resends all it still buffers
+		h.logger.WithError(err).WithField("device_id", deviceID).Error("Failed to write measurements of handed off stream")
+	} else {
+		reconnect.LastSequenceNumber = lastSequence
+	}
+
+	if err := stream.Send(&pb.StreamDataResponse{
+		Message: &pb.StreamDataResponse_Reconnect{Reconnect: reconnect},
+	}); err != nil {
+		h.logger.WithError(err).WithField("device_id", deviceID).Debug("Failed to send reconnect")
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"device_id":     deviceID,
+		"last_sequence": reconnect.LastSequenceNumber,
+	}).Info("Device stream handed off")
+
+	return status.Error(codes.Unavailable, drainingMessage)
+}
+
+// validateStreamInit validates the stream init message
+func (h *StreamHandler) validateStreamInit(init *pb.StreamInit) error {
+	if strings.TrimSpace(init.DeviceId) == "" {
+		return fmt.Errorf("device_id is required")
+	}
+
+	if strings.TrimSpace(init.SessionId) == "" {
+		return fmt.Errorf("session_id is required")
+	}
+
+	return nil
+}
+
+// throttleData applies the stream message limit of a device. It waits out a
+// short delay, and returns a stream error if the message must be dropped, or
+// an error if the stream ended while waiting.
+func (h *StreamHandler) throttleData(ctx context.Context, deviceID string) (*pb.StreamError, error) {
+	if h.limiter == nil {
+		return nil, nil
+	}
+
+	decision := h.limiter.AllowMessage(deviceID)
+	if !decision.Allowed {
+		h.logger.WithFields(map[string]interface{}{
+			"device_id":   deviceID,
+			"retry_after": decision.RetryAfter.String(),
+		}).Warn("Stream message rate limited")
+
+		return &pb.StreamError{
+			Code:        streamErrorRateLimited,
+			Message:     fmt.Sprintf("Message rate limit exceeded, measurement dropped, retry after %s", decision.RetryAfter.Round(time.Millisecond)),
+			Recoverable: true,
+		}, nil
+	}
+
+	if decision.RetryAfter > 0 {
+		timer := time.NewTimer(decision.RetryAfter)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil, nil
+}
+
+// handleData converts and ingests a measurement data message, returning a
+// stream error to report to the device if it could not be ingested
+func (h *StreamHandler) handleData(ctx context.Context, deviceID string, data *pb.MeasurementData) *pb.StreamError {
+	if data.DeviceId != "" && data.DeviceId != deviceID {
+		return &pb.StreamError{
+			Code:        streamErrorInvalidData,
+			Message:     "Measurement device_id does not match the stream",
+			Recoverable: true,
+		}
+	}
+
+	measurements := h.convertMeasurementData(deviceID, data)
+	if len(measurements) == 0 {
+		return nil
+	}
+
+	if err := h.ingester.Ingest(ctx, measurements); err != nil {
+		h.logger.WithError(err).WithField("device_id", deviceID).Warn("Failed to ingest measurements")
+		return &pb.StreamError{
+			Code:        streamErrorIngestFailed,
+			Message:     err.Error(),
+			Recoverable: true,
+		}
+	}
+
+	return nil
+}
+
+// handleHeartbeat records a heartbeat and echoes it back to the device
+func (h *StreamHandler) handleHeartbeat(stream pb.LabInstrumentGateway_StreamDataServer, deviceID string, heartbeat *pb.Heartbeat) error {
+	var metrics map[string]interface{}
+	if len(heartbeat.Metrics) > 0 {
+		metrics = make(map[string]interface{}, len(heartbeat.Metrics))
+		for k, v := range heartbeat.Metrics {
+			metrics[k] = v
+		}
+	}
+
+	if err := h.connectionManager.UpdateHeartbeat(deviceID, metrics); err != nil {
+		h.logger.WithError(err).WithField("device_id", deviceID).Warn("Failed to update heartbeat")
+	}
+
+	return stream.Send(&pb.StreamDataResponse{
+		Message: &pb.StreamDataResponse_Heartbeat{Heartbeat: &pb.Heartbeat{
+			Timestamp: timestamppb.Now(),
+			DeviceId:  deviceID,
+		}},
+	})
+}
+
+// sendError sends a stream error to the device
+func (h *StreamHandler) sendError(stream pb.LabInstrumentGateway_StreamDataServer, streamErr *pb.StreamError) error {
+	return stream.Send(&pb.StreamDataResponse{
+		Message: &pb.StreamDataResponse_Error{Error: streamErr},
+	})
+}
+
+// convertMeasurementData converts a measurement data message into
+// measurements, one per data point
+func (h *StreamHandler) convertMeasurementData(deviceID string, data *pb.MeasurementData) []*models.Measurement {
+	timestamp := time.Now()
+	if data.Timestamp != nil {
+		timestamp = data.Timestamp.AsTime()
+	}
+
+	var batchID *string
+	if data.BatchId != "" {
+		batchID = &data.BatchId
+	}
+
+	measurements := make([]*models.Measurement, 0, len(data.DataPoints))
+	for i, point := range data.DataPoints {
+		measurement := &models.Measurement{
+			DeviceID:  deviceID,
+			Timestamp: timestamp,
+			Type:      point.Type,
+			Value:     point.Value,
+			Unit:      point.Unit,
+			Quality:   h.convertQualityFromProto(point.Quality),
+			Metadata:  make(map[string]interface{}, len(point.Metadata)),
+			BatchID:   batchID,
+		}
+		if data.SequenceNumber != 0 {
+			sequence := int(data.SequenceNumber) + i
+			measurement.SequenceNumber = &sequence
+		}
+		for k, v := range point.Metadata {
+			measurement.Metadata[k] = v
+		}
+		measurements = append(measurements, measurement)
+	}
+
+	return measurements
+}
+
+// convertCommandToProto converts a command to the message sent to the device
+func (h *StreamHandler) convertCommandToProto(command *models.Command) *pb.Command {
+	parameters := make(map[string]string, len(command.Parameters))
+	for k, v := range command.Parameters {
+		parameters[k] = fmt.Sprintf("%v", v)
+	}
+
+	msg := &pb.Command{
+		Id:         command.CommandID,
+		Type:       command.Type,
+		Parameters: parameters,
+		Priority:   int32(command.Priority),
+	}
+	if command.ExpiresAt != nil {
+		msg.ExpiresAt = timestamppb.New(*command.ExpiresAt)
+	}
+
+	return msg
+}
+
+// convertQualityFromProto converts a protobuf quality code to the internal type
+func (h *StreamHandler) convertQualityFromProto(quality pb.QualityCode) models.QualityCode {
+	switch quality {
+	case pb.QualityCode_QUALITY_GOOD:
+		return models.QualityGood
+	case pb.QualityCode_QUALITY_BAD:
+		return models.QualityBad
+	case pb.QualityCode_QUALITY_UNCERTAIN:
+		return models.QualityUncertain
+	case pb.QualityCode_QUALITY_SUBSTITUTED:
+		return models.QualitySubstituted
+	default:
+		return models.QualityUnknown
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/internal/ingest"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// measurementStubs is a RepositoryManager recording written measurements
+type measurementStubs struct {
+	repository.RepositoryManager
+	measurements *measurementStub
+}
+
+func (s *measurementStubs) Measurement() repository.MeasurementRepository { return s.measurements }
+
+type measurementStub struct {
+	repository.MeasurementRepository
+	mutex   sync.Mutex
+	written []*models.Measurement
+}
+
+func (s *measurementStub) CreateBulk(ctx context.Context, measurements []*models.Measurement) (*repository.BulkResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.written = append(s.written, measurements...)
+	return &repository.BulkResult{SuccessCount: len(measurements)}, nil
+}
+
+func (s *measurementStub) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.written)
+}
+
+// fakeDataStream is a device stream fed from a channel. Recv blocks until a
+// message is sent or the stream context is cancelled.
+type fakeDataStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests chan *pb.StreamDataRequest
+
+	mutex     sync.Mutex
+	responses []*pb.StreamDataResponse
+}
+
+func newFakeDataStream(ctx context.Context) *fakeDataStream {
+	return &fakeDataStream{ctx: ctx, requests: make(chan *pb.StreamDataRequest)}
+}
+
+func (s *fakeDataStream) Context() context.Context { return s.ctx }
+
+func (s *fakeDataStream) Recv() (*pb.StreamDataRequest, error) {
+	select {
+	case req := <-s.requests:
+		return req, nil
+	case <-s.ctx.Done():
+		return nil, status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+func (s *fakeDataStream) Send(resp *pb.StreamDataResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func (s *fakeDataStream) sent() []*pb.StreamDataResponse {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*pb.StreamDataResponse(nil), s.responses...)
+}
+
+func TestStreamHandler_Drain(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	repos := &measurementStubs{measurements: &measurementStub{}}
+
+	connections := device.NewConnectionManager(device.Config{}, log)
+	defer connections.Close()
+	require.NoError(t, connections.RegisterConnection(context.Background(), &models.DeviceSession{
+		DeviceID:  "hplc-01",
+		SessionID: "session-1",
+	}))
+
+	// Measurements stay buffered until the stream is handed off
+	ingester := ingest.NewIngester(ingest.Config{BatchSize: 100, FlushInterval: time.Hour}, repos, nil, log)
+	handler := NewStreamHandler(connections, ingester, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newFakeDataStream(ctx)
+
+	result := make(chan error, 1)
+	go func() { result <- handler.StreamData(stream) }()
+
+	stream.requests <- &pb.StreamDataRequest{Message: &pb.StreamDataRequest_Init{
+		Init: &pb.StreamInit{DeviceId: "hplc-01", SessionId: "session-1"},
+	}}
+	for _, sequence := range []int32{1, 2} {
+		stream.requests <- &pb.StreamDataRequest{Message: &pb.StreamDataRequest_Data{
+			Data: &pb.MeasurementData{
+				SequenceNumber: sequence,
+				DataPoints:     []*pb.DataPoint{{Type: "pressure", Value: 101.3, Unit: "kPa"}},
+			},
+		}}
+	}
+	// A heartbeat is only answered once the data before it was handled
+	stream.requests <- &pb.StreamDataRequest{Message: &pb.StreamDataRequest_Heartbeat{
+		Heartbeat: &pb.Heartbeat{DeviceId: "hplc-01"},
+	}}
+	require.Eventually(t, func() bool { return len(stream.sent()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Zero(t, repos.measurements.count())
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer drainCancel()
+	require.NoError(t, handler.Drain(drainCtx))
+
+	select {
+	case err := <-result:
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end after drain")
+	}
+
+	assert.Equal(t, 2, repos.measurements.count())
+	responses := stream.sent()
+	reconnect := responses[len(responses)-1].GetReconnect()
+	require.NotNil(t, reconnect)
+	assert.Equal(t, int32(2), reconnect.LastSequenceNumber)
+
+	// New streams are refused while draining
+	err := handler.StreamData(newFakeDataStream(ctx))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
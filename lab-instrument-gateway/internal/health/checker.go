@@ -0,0 +1,309 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// Subsystems whose health is reported as grpc.health.v1 services
+const (
+	SubsystemDatabase    = "database"
+	SubsystemIngest      = "ingest"
+	SubsystemConnections = "connections"
+	SubsystemNotifier    = "notifier"
+	SubsystemRegistry    = "registry"
+)
+
+// CheckFunc reports whether a subsystem is able to serve; nil means healthy
+type CheckFunc func(ctx context.Context) error
+
+// Config represents the health checker configuration
+type Config struct {
+	// Interval is how often the subsystems are checked, Timeout bounds a
+	// single check
+	Interval time.Duration
+	Timeout  time.Duration
+
+	// Services are the gRPC services reported with the overall status, in
+	// addition to the empty service name
+	Services []string
+}
+
+// SetDefaults sets default values for the health configuration
+func (c *Config) SetDefaults() {
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+}
+
+// Checker periodically checks the registered subsystems and publishes their
+// status through the standard gRPC health service and the HTTP probes. Each
+// subsystem is a health service of its own; the overall status is serving
+// only while every subsystem is.
+type Checker struct {
+	server   *health.Server
+	names    []string
+	checks   map[string]CheckFunc
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+	logger   *logger.Logger
+
+	// results holds the error of the latest check of each subsystem;
+	// subsystems that have not been checked yet are missing
+	results  map[string]error
+	draining bool
+	mutex    sync.RWMutex
+
+	// Channels for lifecycle management
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewChecker creates a health checker reporting not serving until the
+// subsystems have been checked
+func NewChecker(config Config, logger *logger.Logger) *Checker {
+	config.SetDefaults()
+
+	checker := &Checker{
+		server:   health.NewServer(),
+		checks:   make(map[string]CheckFunc),
+		services: append([]string{""}, config.Services...),
+		interval: config.Interval,
+		timeout:  config.Timeout,
+		logger:   logger,
+		results:  make(map[string]error),
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+
+	for _, service := range checker.services {
+		checker.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return checker
+}
+
+// Register adds a subsystem check; it must be called before Start
+func (c *Checker) Register(name string, check CheckFunc) {
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+	c.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Server returns the grpc.health.v1 service to register on the gRPC server
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// Start checks the subsystems once, so that the gateway reports serving as
+// soon as it is able to, and then keeps checking them in the background
+func (c *Checker) Start() {
+	c.Check(context.Background())
+	go c.checkRoutine()
+
+	c.logger.WithFields(map[string]interface{}{
+		"subsystems": c.names,
+		"interval":   c.interval.String(),
+	}).Info("Health checker started")
+}
+
+// Check runs every subsystem check and updates the published statuses
+func (c *Checker) Check(ctx context.Context) {
+	results := make(map[string]error, len(c.names))
+	for _, name := range c.names {
+		checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		results[name] = c.checks[name](checkCtx)
+		cancel()
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.draining {
+		return
+	}
+
+	healthy := true
+	for _, name := range c.names {
+		err := results[name]
+		previous, checked := c.results[name]
+		c.results[name] = err
+
+		if err != nil {
+			healthy = false
+			if !checked || previous == nil {
+				c.logger.WithFields(map[string]interface{}{
+					"subsystem": name,
+				}).WithError(err).Warn("Subsystem is not serving")
+			}
+		} else if checked && previous != nil {
+			c.logger.WithField("subsystem", name).Info("Subsystem recovered")
+		}
+
+		c.server.SetServingStatus(name, servingStatus(err == nil))
+	}
+
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, servingStatus(healthy))
+	}
+}
+
+// Drain reports every service as not serving from now on, so that load
+// balancers and Kubernetes stop routing new calls while open ones drain
+func (c *Checker) Drain() {
+	c.mutex.Lock()
+	c.draining = true
+	c.mutex.Unlock()
+
+	c.server.Shutdown()
+	c.logger.Info("Health status set to not serving for shutdown")
+}
+
+// Ready reports whether every subsystem is serving and the gateway is not
+// draining
+func (c *Checker) Ready() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.draining {
+		return false
+	}
+	for _, name := range c.names {
+		if err, checked := c.results[name]; !checked || err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Statuses returns the latest check result of each subsystem, with an
+// error for subsystems that have not been checked yet
+func (c *Checker) Statuses() map[string]error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	statuses := make(map[string]error, len(c.names))
+	for _, name := range c.names {
+		err, checked := c.results[name]
+		if !checked {
+			err = fmt.Errorf("not checked yet")
+		}
+		statuses[name] = err
+	}
+	return statuses
+}
+
+// Draining reports whether Drain has been called
+func (c *Checker) Draining() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.draining
+}
+
+// LivezHandler returns the liveness probe handler. The gateway is alive as
+// long as it serves HTTP; failing dependencies only affect readiness, so
+// that they do not get the process restarted.
+func (c *Checker) LivezHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// ReadyzHandler returns the readiness probe handler, answering 200 while
+// every subsystem is serving and 503 otherwise. The status of each
+// subsystem is listed, with the reasons of failures when ?verbose is set.
+func (c *Checker) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, verbose := r.URL.Query()["verbose"]
+		statuses := c.Statuses()
+
+		names := make([]string, 0, len(statuses))
+		for name := range statuses {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var body strings.Builder
+		ready := !c.Draining()
+		if !ready {
+			body.WriteString("[-]shutdown draining\n")
+		}
+		for _, name := range names {
+			err := statuses[name]
+			switch {
+			case err == nil:
+				fmt.Fprintf(&body, "[+]%s ok\n", name)
+			case verbose:
+				ready = false
+				fmt.Fprintf(&body, "[-]%s failed: %v\n", name, err)
+			default:
+				ready = false
+				fmt.Fprintf(&body, "[-]%s failed\n", name)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if ready {
+			body.WriteString("ready\n")
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			body.WriteString("not ready\n")
+		}
+		fmt.Fprint(w, body.String())
+	})
+}
+
+// Close stops the background checks
+func (c *Checker) Close() error {
+	close(c.stopChan)
+
+	select {
+	case <-c.doneChan:
+	case <-time.After(5 * time.Second):
+		c.logger.Warn("Health check routine did not stop within timeout")
+	}
+
+	return nil
+}
+
+// checkRoutine checks the subsystems every interval
+func (c *Checker) checkRoutine() {
+	defer close(c.doneChan)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Check(context.Background())
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// servingStatus converts a health result to a gRPC serving status
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
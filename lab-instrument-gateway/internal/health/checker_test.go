@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// toggle is a subsystem check whose result is set by the test
+type toggle struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (t *toggle) set(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.err = err
+}
+
+func (t *toggle) check(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func servingStatusOf(t *testing.T, checker *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := checker.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func probe(t *testing.T, handler http.Handler, target string) (int, string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+	return recorder.Code, string(body)
+}
+
+func TestChecker(t *testing.T) {
+	database, ingest := &toggle{}, &toggle{}
+
+	checker := NewChecker(Config{Services: []string{"lab_instrument.LabInstrumentGateway"}}, logger.NewDefaultLogger())
+	checker.Register(SubsystemDatabase, database.check)
+	checker.Register(SubsystemIngest, ingest.check)
+
+	// Nothing is serving before the first check
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, checker, SubsystemDatabase))
+	assert.False(t, checker.Ready())
+
+	checker.Start()
+	defer checker.Close()
+
+	for _, service := range []string{"", "lab_instrument.LabInstrumentGateway", SubsystemDatabase, SubsystemIngest} {
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatusOf(t, checker, service), service)
+	}
+	assert.True(t, checker.Ready())
+
+	// A failing subsystem takes the overall status down with it
+	database.set(errors.New("connection refused"))
+	checker.Check(context.Background())
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, checker, SubsystemDatabase))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatusOf(t, checker, SubsystemIngest))
+	assert.EqualError(t, checker.Statuses()[SubsystemDatabase], "connection refused")
+
+	database.set(nil)
+	checker.Check(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatusOf(t, checker, ""))
+
+	// Draining is final, whatever later checks find
+	checker.Drain()
+	checker.Check(context.Background())
+	for _, service := range []string{"", SubsystemDatabase, SubsystemIngest} {
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, checker, service), service)
+	}
+	assert.False(t, checker.Ready())
+}
+
+func TestChecker_Probes(t *testing.T) {
+	database, notifier := &toggle{}, &toggle{}
+
+	checker := NewChecker(Config{}, logger.NewDefaultLogger())
+	checker.Register(SubsystemDatabase, database.check)
+	checker.Register(SubsystemNotifier, notifier.check)
+
+	code, body := probe(t, checker.ReadyzHandler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]database failed")
+
+	checker.Check(context.Background())
+	code, body = probe(t, checker.ReadyzHandler(), "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "[+]database ok\n[+]notifier ok\nready\n", body)
+
+	notifier.set(errors.New("webhook unreachable"))
+	checker.Check(context.Background())
+
+	code, body = probe(t, checker.ReadyzHandler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]notifier failed\n")
+	assert.NotContains(t, body, "webhook unreachable")
+
+	_, body = probe(t, checker.ReadyzHandler(), "/readyz?verbose")
+	assert.Contains(t, body, "[-]notifier failed: webhook unreachable\n")
+
+	// Liveness does not depend on the subsystems
+	code, body = probe(t, checker.LivezHandler(), "/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok\n", body)
+
+	notifier.set(nil)
+	checker.Check(context.Background())
+	checker.Drain()
+	code, body = probe(t, checker.ReadyzHandler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]shutdown draining\n")
+
+	code, _ = probe(t, checker.LivezHandler(), "/livez")
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,414 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/yourorg/lab-gateway/internal/alerting"
+	"github.com/yourorg/lab-gateway/internal/anomaly"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/tracing"
+)
+
+// Default ingest settings
+const (
+	DefaultBatchSize     = 500
+	DefaultFlushInterval = time.Second
+	DefaultAlertCooldown = 15 * time.Minute
+	DefaultSeriesIdle    = 24 * time.Hour
+
+	// DefaultBufferedBatches is the default number of batches the buffer
+	// holds while writes fail
+	DefaultBufferedBatches = 20
+)
+
+const (
+	// flushTimeout bounds a single buffered write
+	flushTimeout = 30 * time.Second
+
+	// pruneInterval is how often idle anomaly series are discarded
+	pruneInterval = 10 * time.Minute
+
+	// flushFailureWindow is how long a failed batch write keeps the
+	// pipeline unhealthy when no later batch succeeds, e.g. while idle
+	flushFailureWindow = time.Minute
+)
+
+// Config represents the ingester configuration
+type Config struct {
+	// BatchSize is the number of buffered measurements that triggers a write,
+	// FlushInterval the longest a measurement waits in the buffer
+	BatchSize     int
+	FlushInterval time.Duration
+
+	// MaxBuffered is the most measurements the buffer holds while writes
+	// fail, defaulting to DefaultBufferedBatches batches. A failed batch is
+	// put back to be retried with the next flush; beyond this limit the
+	// oldest measurements are dropped.
+	MaxBuffered int
+
+	// AnomalyDetection enables statistical anomaly detection. Flagged
+	// measurements are stored with uncertain quality and raise data quality
+	// alerts, at most one per series every AlertCooldown.
+	AnomalyDetection bool
+	Anomaly          anomaly.Config
+	AlertCooldown    time.Duration
+
+	// SeriesIdle is how long a series may go without data before its anomaly
+	// state is discarded
+	SeriesIdle time.Duration
+}
+
+// Ingester buffers incoming measurements and writes them in batches, running
+// anomaly detection on each measurement as it arrives
+type Ingester struct {
+	repos    repository.RepositoryManager
+	alerts   *alerting.Manager
+	detector *anomaly.Detector
+	logger   *logger.Logger
+
+	batchSize     int
+	maxBuffered   int
+	flushInterval time.Duration
+	alertCooldown time.Duration
+	seriesIdle    time.Duration
+
+	buffer     []*models.Measurement
+	lastAlerts map[anomaly.SeriesKey]time.Time
+	flushErr   error
+	flushedAt  time.Time
+	mutex      sync.Mutex
+	flushMutex sync.Mutex
+
+	// Channels for lifecycle management
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewIngester creates a new ingester
+func NewIngester(config Config, repos repository.RepositoryManager, alerts *alerting.Manager, logger *logger.Logger) *Ingester {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	if config.MaxBuffered < config.BatchSize {
+		config.MaxBuffered = DefaultBufferedBatches * config.BatchSize
+	}
+	if config.AlertCooldown <= 0 {
+		config.AlertCooldown = DefaultAlertCooldown
+	}
+	if config.SeriesIdle <= 0 {
+		config.SeriesIdle = DefaultSeriesIdle
+	}
+
+	ingester := &Ingester{
+		repos:         repos,
+		alerts:        alerts,
+		logger:        logger,
+		batchSize:     config.BatchSize,
+		maxBuffered:   config.MaxBuffered,
+		flushInterval: config.FlushInterval,
+		alertCooldown: config.AlertCooldown,
+		seriesIdle:    config.SeriesIdle,
+		lastAlerts:    make(map[anomaly.SeriesKey]time.Time),
+		stopChan:      make(chan struct{}),
+		doneChan:      make(chan struct{}),
+	}
+
+	if config.AnomalyDetection {
+		ingester.detector = anomaly.NewDetector(config.Anomaly)
+	}
+
+	return ingester
+}
+
+// Start starts the periodic flush loop
+func (i *Ingester) Start() {
+	go i.flushRoutine()
+
+	i.logger.WithFields(map[string]interface{}{
+		"batch_size":        i.batchSize,
+		"flush_interval":    i.flushInterval,
+		"anomaly_detection": i.detector != nil,
+	}).Info("Measurement ingester started")
+}
+
+// Ingest validates and buffers measurements, flushing when the buffer is
+// full. Measurements flagged by anomaly detection are marked uncertain.
+func (i *Ingester) Ingest(ctx context.Context, measurements []*models.Measurement) error {
+	for _, measurement := range measurements {
+		if measurement.ID == "" {
+			measurement.ID = uuid.New().String()
+		}
+		measurement.SetDefaults()
+		if err := measurement.Validate(); err != nil {
+			measurementsTotal.WithLabelValues("rejected").Add(float64(len(measurements)))
+			return fmt.Errorf("invalid measurement: %w", err)
+		}
+	}
+
+	if i.detector != nil {
+		for _, measurement := range measurements {
+			i.detect(ctx, measurement)
+		}
+	}
+
+	i.mutex.Lock()
+	i.buffer = append(i.buffer, measurements...)
+	full := len(i.buffer) >= i.batchSize
+	bufferedMeasurements.Set(float64(len(i.buffer)))
+	i.mutex.Unlock()
+
+	if full {
+		return i.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush writes all buffered measurements. A batch that cannot be written is
+// put back into the buffer to be retried with the next flush.
+func (i *Ingester) Flush(ctx context.Context) error {
+	// Serialize flushes so batches are written in arrival order
+	i.flushMutex.Lock()
+	defer i.flushMutex.Unlock()
+
+	i.mutex.Lock()
+	batch := i.buffer
+	i.buffer = nil
+	bufferedMeasurements.Set(0)
+	i.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "ingest.Flush", attribute.Int("ingest.batch_size", len(batch)))
+	defer span.End()
+
+	start := time.Now()
+	result, err := i.repos.Measurement().CreateBulk(ctx, batch)
+	batchSizes.Observe(float64(len(batch)))
+	i.recordFlush(err)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		batchDuration.WithLabelValues("error").Observe(time.Since(start).Seconds())
+		i.logger.WithError(err).WithField("count", len(batch)).Error("Failed to write measurement batch")
+		i.requeue(batch)
+		return fmt.Errorf("failed to write measurement batch: %w", err)
+	}
+	batchDuration.WithLabelValues("success").Observe(time.Since(start).Seconds())
+	measurementsTotal.WithLabelValues("written").Add(float64(result.SuccessCount))
+	measurementsTotal.WithLabelValues("failed").Add(float64(result.FailureCount))
+	span.SetAttributes(
+		attribute.Int("ingest.written", result.SuccessCount),
+		attribute.Int("ingest.failed", result.FailureCount),
+	)
+
+	if result.FailureCount > 0 {
+		i.logger.WithFields(map[string]interface{}{
+			"succeeded": result.SuccessCount,
+			"failed":    result.FailureCount,
+		}).Warn("Some measurements could not be written")
+	}
+
+	i.logger.WithField("count", result.SuccessCount).Debug("Measurement batch written")
+	return nil
+}
+
+// requeue puts a batch that could not be written back in front of the
+// measurements buffered since, dropping the oldest beyond the buffer limit
+func (i *Ingester) requeue(batch []*models.Measurement) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.buffer = append(batch, i.buffer...)
+	if dropped := len(i.buffer) - i.maxBuffered; dropped > 0 {
+		i.buffer = i.buffer[dropped:]
+		measurementsTotal.WithLabelValues("dropped").Add(float64(dropped))
+		i.logger.WithFields(map[string]interface{}{
+			"dropped":  dropped,
+			"buffered": len(i.buffer),
+		}).Error("Measurement buffer full, dropped the oldest measurements")
+	}
+	bufferedMeasurements.Set(float64(len(i.buffer)))
+}
+
+// ResetCalibration discards the anomaly model state of a device, to be
+// called when the device has been recalibrated
+func (i *Ingester) ResetCalibration(deviceID string) {
+	if i.detector == nil {
+		return
+	}
+
+	count := i.detector.ResetDevice(deviceID)
+	i.logger.WithFields(map[string]interface{}{
+		"device_id": deviceID,
+		"series":    count,
+	}).Info("Anomaly detection state reset after calibration")
+}
+
+// recordFlush keeps the outcome of the latest batch write for health checks
+func (i *Ingester) recordFlush(err error) {
+	i.mutex.Lock()
+	i.flushErr = err
+	i.flushedAt = time.Now()
+	i.mutex.Unlock()
+}
+
+// HealthCheck reports the ingest pipeline unhealthy once it is closed, or
+// when the latest batch could not be written in the last minute
+func (i *Ingester) HealthCheck(ctx context.Context) error {
+	select {
+	case <-i.stopChan:
+		return fmt.Errorf("ingester is closed")
+	default:
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.flushErr != nil && time.Since(i.flushedAt) < flushFailureWindow {
+		return fmt.Errorf("latest measurement batch failed: %w", i.flushErr)
+	}
+	return nil
+}
+
+// GetStats returns ingester statistics
+func (i *Ingester) GetStats() map[string]interface{} {
+	i.mutex.Lock()
+	buffered := len(i.buffer)
+	i.mutex.Unlock()
+
+	stats := map[string]interface{}{
+		"buffered":          buffered,
+		"batch_size":        i.batchSize,
+		"flush_interval":    i.flushInterval.String(),
+		"anomaly_detection": i.detector != nil,
+	}
+	if i.detector != nil {
+		stats["anomaly_series"] = i.detector.SeriesCount()
+	}
+
+	return stats
+}
+
+// Close stops the flush loop and writes any remaining measurements
+func (i *Ingester) Close() error {
+	close(i.stopChan)
+
+	select {
+	case <-i.doneChan:
+	case <-time.After(5 * time.Second):
+		i.logger.Warn("Measurement flush routine did not stop within timeout")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	return i.Flush(ctx)
+}
+
+// flushRoutine periodically flushes the buffer and prunes idle series
+func (i *Ingester) flushRoutine() {
+	defer close(i.doneChan)
+
+	flushTicker := time.NewTicker(i.flushInterval)
+	defer flushTicker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-flushTicker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			if err := i.Flush(ctx); err != nil {
+				i.logger.WithError(err).Warn("Periodic measurement flush failed")
+			}
+			cancel()
+		case <-pruneTicker.C:
+			i.pruneIdleSeries(time.Now())
+		case <-i.stopChan:
+			return
+		}
+	}
+}
+
+// detect runs anomaly detection on a measurement, marking it uncertain and
+// raising a data quality alert if it is flagged
+func (i *Ingester) detect(ctx context.Context, measurement *models.Measurement) {
+	findings := i.detector.Observe(measurement)
+	if len(findings) == 0 {
+		return
+	}
+
+	anomaliesTotal.Inc()
+
+	reasons := make([]string, len(findings))
+	for j, finding := range findings {
+		reasons[j] = finding.String()
+	}
+
+	if measurement.Quality == models.QualityGood || measurement.Quality == models.QualityUnknown {
+		measurement.Quality = models.QualityUncertain
+	}
+	measurement.Metadata["anomaly"] = strings.Join(reasons, "; ")
+
+	key := anomaly.SeriesKey{DeviceID: measurement.DeviceID, Type: measurement.Type}
+	now := time.Now()
+
+	i.mutex.Lock()
+	last, alerted := i.lastAlerts[key]
+	if alerted && now.Sub(last) < i.alertCooldown {
+		i.mutex.Unlock()
+		return
+	}
+	i.lastAlerts[key] = now
+	i.mutex.Unlock()
+
+	alert := &models.Alert{
+		DeviceID: &measurement.DeviceID,
+		Type:     models.AlertTypeDataQuality,
+		Severity: models.AlertSeverityWarning,
+		Message:  fmt.Sprintf("Anomalous %s reading %g %s from device %s", measurement.Type, measurement.Value, measurement.Unit, measurement.DeviceID),
+		Metadata: map[string]interface{}{
+			"measurement_type": measurement.Type,
+			"value":            measurement.Value,
+			"timestamp":        measurement.Timestamp.UTC().Format(time.RFC3339Nano),
+			"findings":         reasons,
+		},
+	}
+
+	if err := i.alerts.Raise(ctx, alert); err != nil {
+		i.logger.WithError(err).WithField("device_id", measurement.DeviceID).Error("Failed to raise data quality alert")
+	}
+}
+
+// pruneIdleSeries discards anomaly state and alert cooldowns for series that
+// have gone quiet
+func (i *Ingester) pruneIdleSeries(now time.Time) {
+	if i.detector == nil {
+		return
+	}
+
+	if pruned := i.detector.Prune(now.Add(-i.seriesIdle)); pruned > 0 {
+		i.logger.WithField("series", pruned).Debug("Pruned idle anomaly series")
+	}
+
+	i.mutex.Lock()
+	for key, last := range i.lastAlerts {
+		if now.Sub(last) >= i.alertCooldown {
+			delete(i.lastAlerts, key)
+		}
+	}
+	i.mutex.Unlock()
+}
@@ -0,0 +1,247 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/yourorg/lab-gateway/internal/alerting"
+	"github.com/yourorg/lab-gateway/internal/anomaly"
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+)
+
+// newTestRepos returns an in-memory repository manager holding the device
+// the readings come from
+func newTestRepos(t *testing.T) repository.RepositoryManager {
+	repos := memory.NewRepositoryManager()
+	require.NoError(t, repos.Device().Create(context.Background(), &models.Device{
+		ID:     "hplc-1",
+		Name:   "HPLC 1",
+		Type:   "hplc",
+		Status: models.DeviceStatusOnline,
+	}))
+	return repos
+}
+
+// unavailableRepos fails measurement writes while err is set
+type unavailableRepos struct {
+	repository.RepositoryManager
+	err error
+}
+
+func (u *unavailableRepos) Measurement() repository.MeasurementRepository {
+	return &unavailableMeasurements{MeasurementRepository: u.RepositoryManager.Measurement(), repos: u}
+}
+
+type unavailableMeasurements struct {
+	repository.MeasurementRepository
+	repos *unavailableRepos
+}
+
+func (u *unavailableMeasurements) CreateBulk(ctx context.Context, measurements []*models.Measurement) (*repository.BulkResult, error) {
+	if u.repos.err != nil {
+		return nil, u.repos.err
+	}
+	return u.MeasurementRepository.CreateBulk(ctx, measurements)
+}
+
+func newTestIngester(repos repository.RepositoryManager, config Config) *Ingester {
+	log := logger.NewDefaultLogger()
+	return NewIngester(config, repos, alerting.NewManager(repos, nil, log), log)
+}
+
+func reading(value float64) *models.Measurement {
+	return &models.Measurement{
+		DeviceID:  "hplc-1",
+		Type:      "absorbance",
+		Value:     value,
+		Unit:      "AU",
+		Quality:   models.QualityGood,
+		Timestamp: time.Now(),
+	}
+}
+
+func stored(t *testing.T, repos repository.RepositoryManager) int64 {
+	count, err := repos.Measurement().Count(context.Background(), repository.MeasurementFilter{})
+	require.NoError(t, err)
+	return count
+}
+
+func TestIngester_Batching(t *testing.T) {
+	repos := newTestRepos(t)
+	ingester := newTestIngester(repos, Config{BatchSize: 3})
+	ctx := context.Background()
+
+	batch := []*models.Measurement{reading(1), reading(2)}
+	require.NoError(t, ingester.Ingest(ctx, batch))
+	assert.Zero(t, stored(t, repos))
+
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{reading(3)}))
+	assert.Equal(t, int64(3), stored(t, repos))
+	assert.NotEmpty(t, batch[0].ID)
+
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{reading(4)}))
+	require.NoError(t, ingester.Flush(ctx))
+	assert.Equal(t, int64(4), stored(t, repos))
+
+	invalid := reading(5)
+	invalid.Type = ""
+	assert.Error(t, ingester.Ingest(ctx, []*models.Measurement{invalid}))
+}
+
+func TestIngester_Close(t *testing.T) {
+	repos := newTestRepos(t)
+	ingester := newTestIngester(repos, Config{FlushInterval: time.Hour})
+	ingester.Start()
+
+	require.NoError(t, ingester.Ingest(context.Background(), []*models.Measurement{reading(1)}))
+	require.NoError(t, ingester.Close())
+
+	assert.Equal(t, int64(1), stored(t, repos))
+}
+
+func TestIngester_AnomalyDetection(t *testing.T) {
+	repos := newTestRepos(t)
+	ingester := newTestIngester(repos, Config{
+		BatchSize:        1000,
+		AnomalyDetection: true,
+		Anomaly:          anomaly.Config{WarmupSamples: 20},
+	})
+	ctx := context.Background()
+
+	alerts := func() []*models.Alert {
+		list, err := repos.Alert().List(ctx, repository.AlertFilter{})
+		require.NoError(t, err)
+		return list
+	}
+
+	for i := 0; i < 40; i++ {
+		require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{reading(1 + float64(i%3)*0.01)}))
+	}
+
+	spike := reading(5)
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{spike}))
+	assert.Equal(t, models.QualityUncertain, spike.Quality)
+	assert.Contains(t, spike.Metadata["anomaly"], "zscore")
+
+	require.Len(t, alerts(), 1)
+	assert.Equal(t, models.AlertTypeDataQuality, alerts()[0].Type)
+
+	// Further anomalies in the same series are marked but not re-alerted
+	second := reading(6)
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{second}))
+	assert.Equal(t, models.QualityUncertain, second.Quality)
+	assert.Len(t, alerts(), 1)
+
+	// Bad quality readings keep their quality
+	bad := reading(100)
+	bad.Quality = models.QualityBad
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{bad}))
+	assert.Equal(t, models.QualityBad, bad.Quality)
+
+	// Delivering a calibration clears the model, so the new level starts a
+	// fresh baseline
+	dispatcher := device.NewDispatcher(repos.Command(), logger.NewDefaultLogger())
+	dispatcher.OnCalibrate(ingester.ResetCalibration)
+	dispatcher.Attach("hplc-1", "stream-1", func(*models.Command) error { return nil })
+	calibrate := &models.Command{
+		ID:        uuid.New().String(),
+		DeviceID:  "hplc-1",
+		CommandID: "cmd-calibrate",
+		Type:      models.CommandTypeCalibrate,
+	}
+	calibrate.SetDefaults()
+	require.NoError(t, repos.Command().Create(ctx, calibrate))
+	delivered, err := dispatcher.Dispatch(ctx, calibrate)
+	require.NoError(t, err)
+	require.True(t, delivered)
+
+	shifted := reading(5)
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{shifted}))
+	assert.Equal(t, models.QualityGood, shifted.Quality)
+}
+
+func TestIngester_FlushSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	repos := newTestRepos(t)
+	ingester := newTestIngester(repos, Config{BatchSize: 10})
+	ctx := context.Background()
+
+	require.NoError(t, ingester.Flush(ctx))
+	assert.Empty(t, exporter.GetSpans(), "empty buffers are not flushed")
+
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{reading(1), reading(2)}))
+	require.NoError(t, ingester.Flush(ctx))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "ingest.Flush", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.Int("ingest.batch_size", 2))
+	assert.Contains(t, spans[0].Attributes, attribute.Int("ingest.written", 2))
+}
+
+func TestIngester_HealthCheck(t *testing.T) {
+	repos := &unavailableRepos{RepositoryManager: newTestRepos(t)}
+	ingester := newTestIngester(repos, Config{})
+	ctx := context.Background()
+
+	assert.NoError(t, ingester.HealthCheck(ctx))
+
+	repos.err = errors.New("database unavailable")
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{reading(1)}))
+	require.Error(t, ingester.Flush(ctx))
+	assert.ErrorContains(t, ingester.HealthCheck(ctx), "database unavailable")
+
+	// The pipeline recovers with the next batch written, which retries the
+	// failed one
+	repos.err = nil
+	require.NoError(t, ingester.Ingest(ctx, []*models.Measurement{reading(2)}))
+	require.NoError(t, ingester.Flush(ctx))
+	assert.NoError(t, ingester.HealthCheck(ctx))
+	assert.Equal(t, int64(2), stored(t, repos))
+
+	ingester.Start()
+	require.NoError(t, ingester.Close())
+	assert.Error(t, ingester.HealthCheck(ctx))
+}
+
+func TestIngester_RetriesFailedBatches(t *testing.T) {
+	repos := &unavailableRepos{RepositoryManager: newTestRepos(t), err: errors.New("database unavailable")}
+	ingester := newTestIngester(repos, Config{BatchSize: 2, MaxBuffered: 3})
+	ctx := context.Background()
+
+	readings := []*models.Measurement{reading(1), reading(2), reading(3), reading(4)}
+	assert.Error(t, ingester.Ingest(ctx, readings[:2]))
+	assert.Error(t, ingester.Ingest(ctx, readings[2:3]))
+	assert.Equal(t, 3, ingester.GetStats()["buffered"])
+
+	// Beyond the limit the oldest readings are dropped
+	assert.Error(t, ingester.Ingest(ctx, readings[3:]))
+	assert.Equal(t, 3, ingester.GetStats()["buffered"])
+
+	repos.err = nil
+	require.NoError(t, ingester.Flush(ctx))
+	assert.Equal(t, int64(3), stored(t, repos))
+	_, err := repos.Measurement().GetByID(ctx, readings[0].ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repos.Measurement().GetByID(ctx, readings[3].ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,53 @@
+package ingest
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Measurements by outcome: rejected by validation, written, failed to
+	// write, or dropped from a full buffer after failed writes
+	measurementsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ingest_measurements_total",
+			Help: "Total number of measurements ingested, by result",
+		},
+		[]string{"result"},
+	)
+
+	// Measurements flagged by anomaly detection
+	anomaliesTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ingest_anomalies_total",
+			Help: "Total number of measurements flagged by anomaly detection",
+		},
+	)
+
+	// Batch write duration
+	batchDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ingest_batch_duration_seconds",
+			Help:    "Duration of measurement batch writes in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"status"},
+	)
+
+	// Batch sizes
+	batchSizes = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "ingest_batch_size",
+			Help:    "Number of measurements per batch write",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+		},
+	)
+
+	// Measurements waiting in the buffer
+	bufferedMeasurements = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ingest_buffered_measurements",
+			Help: "Number of measurements waiting to be written",
+		},
+	)
+)
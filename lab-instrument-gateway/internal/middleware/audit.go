@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// auditWriteTimeout bounds writing an audit entry after the RPC completed
+const auditWriteTimeout = 5 * time.Second
+
+// auditedMethods maps each state-changing RPC to the type of resource it acts
+// on. Devices are updated by registering them again, which is audited; the
+// API has no RPC to delete a device, cancel a command or acknowledge an alert,
+// and no retention job deletes old records, so none of these can happen
+// unaudited. Alert escalations are audited by the escalator.
+var auditedMethods = map[string]string{
+	pb.LabInstrumentGateway_RegisterDevice_FullMethodName:        "device",
+	pb.LabInstrumentGateway_SendCommand_FullMethodName:           "command",
+	pb.LabInstrumentGateway_CreateSilence_FullMethodName:         "silence",
+	pb.LabInstrumentGateway_ExpireSilence_FullMethodName:         "silence",
+	pb.LabInstrumentGateway_CreateEnrollmentToken_FullMethodName: "enrollment_token",
+	pb.LabInstrumentGateway_EnrollDevice_FullMethodName:          "device",
+	pb.LabInstrumentGateway_CreateAPIKey_FullMethodName:          "api_key",
+	pb.LabInstrumentGateway_RevokeAPIKey_FullMethodName:          "api_key",
+}
+
+// AuditInterceptor creates a unary server interceptor that records every
+// call to a state-changing RPC in the audit trail, including calls that were
+// denied or failed. It must run after AuthInterceptor so that entries name
+// the caller.
+//
+// With failClosed, a call that succeeded fails when its entry cannot be
+// written, so that no caller is told an unrecorded action succeeded. Changes
+// a handler committed with their entry through audit.CommitChange are then
+// not stored either; other changes may already be.
+func AuditInterceptor(recorder *audit.Recorder, failClosed bool, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resourceType, ok := auditedMethods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		entry := models.AuditEntry{
+			Action:        info.FullMethod,
+			ResourceType:  resourceType,
+			ResourceID:    requestResourceID(req),
+			Reason:        auditReason(ctx),
+			Outcome:       models.AuditOutcomeSuccess,
+			CorrelationID: GetCorrelationID(ctx),
+		}
+
+		ctx, change := audit.WithChange(ctx, entry)
+		resp, err := handler(ctx, req)
+
+		// The handler wrote the entry along with the change
+		if err == nil && change.Committed() {
+			return resp, nil
+		}
+
+		if err != nil {
+			entry.Outcome = status.Code(err).String()
+		}
+
+		if resourceID, before, after, recorded := change.Values(); recorded {
+			if resourceID != "" {
+				entry.ResourceID = resourceID
+			}
+			entry.Before = before
+			entry.After = after
+		} else if msg, ok := req.(proto.Message); ok {
+			entry.After = audit.RequestSnapshot(msg)
+		}
+
+		// The entry is written even if the caller has gone away
+		writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+		defer cancel()
+
+		if auditErr := recorder.Record(writeCtx, &entry); auditErr != nil {
+			log.WithFields(map[string]interface{}{
+				"correlation_id": entry.CorrelationID,
+				"action":         entry.Action,
+				"resource_type":  entry.ResourceType,
+				"resource_id":    entry.ResourceID,
+				"actor":          entry.Actor,
+				"outcome":        entry.Outcome,
+			}).WithError(auditErr).Error("Failed to write audit entry")
+
+			if failClosed && err == nil {
+				return nil, status.Error(codes.Internal, "the action could not be recorded in the audit trail")
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// requestResourceID returns the resource a request names, if any
+func requestResourceID(req interface{}) string {
+	switch r := req.(type) {
+	case deviceIDGetter:
+		return r.GetDeviceId()
+	case interface{ GetSilenceId() string }:
+		return r.GetSilenceId()
+	case interface{ GetKeyId() string }:
+		return r.GetKeyId()
+	}
+	return ""
+}
+
+// auditReason returns the reason the caller gave for the action
+func auditReason(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(audit.ReasonHeader)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(values[0])
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// auditStub collects appended audit entries, failing appends while err is set
+type auditStub struct {
+	repository.AuditRepository
+	entries []*models.AuditEntry
+	err     error
+}
+
+func (s *auditStub) Append(ctx context.Context, entry *models.AuditEntry) error {
+	if s.err != nil {
+		return s.err
+	}
+	entry.SetDefaults()
+	s.entries = append(s.entries, entry)
+	return entry.Validate()
+}
+
+func TestAuditInterceptor(t *testing.T) {
+	stub := &auditStub{}
+	interceptor := AuditInterceptor(audit.NewRecorder(stub, logger.NewDefaultLogger()), false, logger.NewDefaultLogger())
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "ada", Method: auth.MethodJWT})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(audit.ReasonHeader, "column replaced"))
+
+	register := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_RegisterDevice_FullMethodName}
+	_, err := interceptor(ctx, &pb.RegisterDeviceRequest{DeviceId: "hplc-01"}, register, func(ctx context.Context, req interface{}) (interface{}, error) {
+		audit.RecordChange(ctx, "hplc-01", &models.Device{ID: "hplc-01", Version: "1.0"}, &models.Device{ID: "hplc-01", Version: "1.1"})
+		return &pb.RegisterDeviceResponse{Success: true}, nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, stub.entries, 1)
+	entry := stub.entries[0]
+	assert.Equal(t, "ada", entry.Actor)
+	assert.Equal(t, "jwt", entry.AuthMethod)
+	assert.Equal(t, pb.LabInstrumentGateway_RegisterDevice_FullMethodName, entry.Action)
+	assert.Equal(t, "device", entry.ResourceType)
+	assert.Equal(t, "hplc-01", entry.ResourceID)
+	assert.Equal(t, "column replaced", entry.Reason)
+	assert.Equal(t, models.AuditOutcomeSuccess, entry.Outcome)
+	assert.Equal(t, "1.0", entry.Before["version"])
+	assert.Equal(t, "1.1", entry.After["version"])
+
+	// Failed calls are audited with their status code and request
+	enroll := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_EnrollDevice_FullMethodName}
+	_, err = interceptor(context.Background(), &pb.EnrollDeviceRequest{EnrollmentToken: "let-me-in", DeviceId: "hplc-02"}, enroll, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "invalid enrollment token")
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	require.Len(t, stub.entries, 2)
+	entry = stub.entries[1]
+	assert.Equal(t, "anonymous", entry.Actor)
+	assert.Equal(t, "Unauthenticated", entry.Outcome)
+	assert.Equal(t, "hplc-02", entry.ResourceID)
+	assert.Nil(t, entry.Before)
+	assert.Equal(t, "[REDACTED]", entry.After["enrollment_token"])
+
+	// Reads are not audited
+	list := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_ListDevices_FullMethodName}
+	_, err = interceptor(ctx, &pb.ListDevicesRequest{}, list, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.ListDevicesResponse{}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, stub.entries, 2)
+}
+
+func TestAuditInterceptor_FailClosed(t *testing.T) {
+	register := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_RegisterDevice_FullMethodName}
+	succeed := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.RegisterDeviceResponse{Success: true}, nil
+	}
+	fail := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no such device")
+	}
+	stub := &auditStub{err: errors.New("audit trail unavailable")}
+	req := &pb.RegisterDeviceRequest{DeviceId: "hplc-01"}
+
+	failOpen := AuditInterceptor(audit.NewRecorder(stub, logger.NewDefaultLogger()), false, logger.NewDefaultLogger())
+	_, err := failOpen(context.Background(), req, register, succeed)
+	assert.NoError(t, err)
+
+	failClosed := AuditInterceptor(audit.NewRecorder(stub, logger.NewDefaultLogger()), true, logger.NewDefaultLogger())
+	resp, err := failClosed(context.Background(), req, register, succeed)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// Failed calls keep their own error
+	_, err = failClosed(context.Background(), req, register, fail)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// A change committed with its entry is not written again
+	stub.err = nil
+	_, err = failClosed(context.Background(), req, register, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := audit.CommitChange(ctx, stub, "hplc-01", nil, &models.Device{ID: "hplc-01"}); err != nil {
+			return nil, err
+		}
+		return &pb.RegisterDeviceResponse{Success: true}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, stub.entries, 1)
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// unauthenticatedMethods are the RPCs that may be called without credentials
+var unauthenticatedMethods = map[string]bool{
+	pb.LabInstrumentGateway_HealthCheck_FullMethodName:  true,
+	pb.LabInstrumentGateway_EnrollDevice_FullMethodName: true,
+	healthpb.Health_Check_FullMethodName:                true,
+	healthpb.Health_List_FullMethodName:                 true,
+	healthpb.Health_Watch_FullMethodName:                true,
+}
+
+// optionalAuthMethods are the RPCs that reach their handler without an
+// identity when authentication fails, so that the handler can record the
+// rejection; the handler is responsible for refusing the call
+var optionalAuthMethods = map[string]bool{
+	pb.LabInstrumentGateway_RegisterDevice_FullMethodName: true,
+}
+
+// AuthInterceptor creates a unary server interceptor that authenticates the
+// caller and stores its identity in the request context
+func AuthInterceptor(authenticator *auth.Authenticator, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if unauthenticatedMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		identity, err := authenticate(ctx, authenticator, log, info.FullMethod)
+		if err != nil {
+			if optionalAuthMethods[info.FullMethod] {
+				return handler(ctx, req)
+			}
+			return nil, err
+		}
+
+		return handler(auth.WithIdentity(ctx, identity), req)
+	}
+}
+
+// StreamAuthInterceptor creates a stream server interceptor that
+// authenticates the caller and stores its identity in the stream context
+func StreamAuthInterceptor(authenticator *auth.Authenticator, log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if unauthenticatedMethods[info.FullMethod] {
+			return handler(srv, stream)
+		}
+
+		identity, err := authenticate(stream.Context(), authenticator, log, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authServerStream{
+			ServerStream: stream,
+			ctx:          auth.WithIdentity(stream.Context(), identity),
+		})
+	}
+}
+
+// authServerStream carries the caller identity in its context
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context with the caller identity
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate returns the caller identity or an Unauthenticated error
+func authenticate(ctx context.Context, authenticator *auth.Authenticator, log *logger.Logger, method string) (*auth.Identity, error) {
+	identity, err := authenticator.Authenticate(ctx)
+	if err != nil {
+		log.WithFields(map[string]interface{}{
+			"correlation_id": GetCorrelationID(ctx),
+			"method":         method,
+			"error":          err.Error(),
+		}).Warn("Request authentication failed")
+
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	return identity, nil
+}
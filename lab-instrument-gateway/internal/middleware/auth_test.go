@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef"
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{Secret: secret})
+	require.NoError(t, err)
+	interceptor := AuthInterceptor(auth.NewAuthenticator(verifier, nil, false), logger.NewDefaultLogger())
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "alice",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	var seen *auth.Identity
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen, _ = auth.FromContext(ctx)
+		return "ok", nil
+	}
+	list := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_ListDevices_FullMethodName}
+	health := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_HealthCheck_FullMethodName}
+
+	_, err = interceptor(context.Background(), &pb.ListDevicesRequest{}, list, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = interceptor(context.Background(), &pb.HealthCheckRequest{}, health, handler)
+	assert.NoError(t, err)
+	assert.Nil(t, seen)
+
+	// Registrations reach the handler without an identity so that it can
+	// record the rejection
+	register := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_RegisterDevice_FullMethodName}
+	_, err = interceptor(context.Background(), &pb.RegisterDeviceRequest{}, register, handler)
+	assert.NoError(t, err)
+	assert.Nil(t, seen)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err = interceptor(ctx, &pb.ListDevicesRequest{}, list, handler)
+	require.NoError(t, err)
+	require.NotNil(t, seen)
+	assert.Equal(t, "alice", seen.Subject)
+}
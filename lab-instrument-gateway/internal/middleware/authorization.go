@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/rbac"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// AuthorizationInterceptor creates a unary server interceptor that checks the
+// caller's permissions for the RPC and the devices it addresses, and stores
+// the caller grants in the request context. It must run after AuthInterceptor.
+func AuthorizationInterceptor(authorizer *rbac.Authorizer, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if unauthenticatedMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		identity, ok := auth.FromContext(ctx)
+		if !ok {
+			if optionalAuthMethods[info.FullMethod] {
+				return handler(ctx, req)
+			}
+			return nil, status.Error(codes.Unauthenticated, "authentication required")
+		}
+
+		grants, err := authorizer.Authorize(ctx, identity, info.FullMethod, req)
+		if err != nil {
+			return nil, authorizationError(ctx, log, identity, info.FullMethod, err)
+		}
+
+		return handler(rbac.WithGrants(ctx, grants), req)
+	}
+}
+
+// StreamAuthorizationInterceptor creates a stream server interceptor that
+// checks the caller's permissions for the RPC, and for the device of every
+// message that names one
+func StreamAuthorizationInterceptor(authorizer *rbac.Authorizer, log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if unauthenticatedMethods[info.FullMethod] {
+			return handler(srv, stream)
+		}
+
+		ctx := stream.Context()
+		identity, ok := auth.FromContext(ctx)
+		if !ok {
+			return status.Error(codes.Unauthenticated, "authentication required")
+		}
+
+		permission, _ := rbac.RequiredPermission(info.FullMethod)
+		grants, err := authorizer.Authorize(ctx, identity, info.FullMethod, nil)
+		if err != nil {
+			return authorizationError(ctx, log, identity, info.FullMethod, err)
+		}
+
+		return handler(srv, &authorizedServerStream{
+			ServerStream: stream,
+			ctx:          rbac.WithGrants(ctx, grants),
+			authorizer:   authorizer,
+			identity:     identity,
+			grants:       grants,
+			permission:   permission,
+			method:       info.FullMethod,
+			log:          log,
+			allowed:      make(map[string]bool),
+		})
+	}
+}
+
+// authorizedServerStream checks each device named by received messages;
+// devices are looked up once per stream
+type authorizedServerStream struct {
+	grpc.ServerStream
+	ctx        context.Context
+	authorizer *rbac.Authorizer
+	identity   *auth.Identity
+	grants     *rbac.Grants
+	permission models.Permission
+	method     string
+	log        *logger.Logger
+
+	mutex   sync.Mutex
+	allowed map[string]bool
+}
+
+// Context returns the context with the caller grants
+func (s *authorizedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg receives a message and checks access to the device it names
+func (s *authorizedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, deviceID := range requestDeviceIDs(m) {
+		if s.allowed[deviceID] {
+			continue
+		}
+		if err := s.authorizer.AuthorizeDevice(s.ctx, s.grants, s.permission, deviceID); err != nil {
+			return authorizationError(s.ctx, s.log, s.identity, s.method, err)
+		}
+		s.allowed[deviceID] = true
+	}
+
+	return nil
+}
+
+// authorizationError logs a failed authorization check and converts it to a
+// gRPC status error
+func authorizationError(ctx context.Context, log *logger.Logger, identity *auth.Identity, method string, err error) error {
+	fields := map[string]interface{}{
+		"correlation_id": GetCorrelationID(ctx),
+		"method":         method,
+		"subject":        identity.Subject,
+		"error":          err.Error(),
+	}
+
+	if errors.Is(err, rbac.ErrPermissionDenied) {
+		log.WithFields(fields).Warn("Request denied by access policy")
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	log.WithFields(fields).Error("Authorization check failed")
+	return status.Error(codes.Internal, "authorization check failed")
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+)
+
+// ConsistencyHeader is the request metadata key selecting the consistency of
+// the reads of a request
+const ConsistencyHeader = "x-read-consistency"
+
+// ReadYourWrites is the ConsistencyHeader value making a request read from
+// the primary database, seeing every write made before it instead of the
+// possibly lagging data of a read replica
+const ReadYourWrites = "read-your-writes"
+
+// ConsistencyInterceptor creates a unary server interceptor that sends the
+// reads of requests asking for ReadYourWrites to the primary database
+func ConsistencyInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if wantsReadYourWrites(ctx) {
+			ctx = db.WithReadYourWrites(ctx)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamConsistencyInterceptor creates a stream server interceptor that
+// sends the reads of streams asking for ReadYourWrites to the primary
+// database
+func StreamConsistencyInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !wantsReadYourWrites(stream.Context()) {
+			return handler(srv, stream)
+		}
+
+		return handler(srv, &consistencyServerStream{
+			ServerStream: stream,
+			ctx:          db.WithReadYourWrites(stream.Context()),
+		})
+	}
+}
+
+// consistencyServerStream carries the read consistency of a stream
+type consistencyServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context with the read consistency
+func (s *consistencyServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// wantsReadYourWrites reports whether the caller asked for ReadYourWrites
+func wantsReadYourWrites(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, value := range md.Get(ConsistencyHeader) {
+		if strings.EqualFold(strings.TrimSpace(value), ReadYourWrites) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+)
+
+func TestConsistencyInterceptor(t *testing.T) {
+	interceptor := ConsistencyInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Method"}
+
+	readsYourWrites := func(ctx context.Context) bool {
+		var required bool
+		_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			required = db.ReadsYourWrites(ctx)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		return required
+	}
+
+	assert.False(t, readsYourWrites(context.Background()))
+	assert.False(t, readsYourWrites(metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(ConsistencyHeader, "eventual"))))
+	assert.True(t, readsYourWrites(metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(ConsistencyHeader, ReadYourWrites))))
+	assert.True(t, readsYourWrites(metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(ConsistencyHeader, " Read-Your-Writes "))))
+}
+
+func TestStreamConsistencyInterceptor(t *testing.T) {
+	interceptor := StreamConsistencyInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/test/Stream"}
+
+	readsYourWrites := func(ctx context.Context) bool {
+		var required bool
+		err := interceptor(nil, &fakeServerStream{ctx: ctx}, info, func(srv interface{}, stream grpc.ServerStream) error {
+			required = db.ReadsYourWrites(stream.Context())
+			return nil
+		})
+		assert.NoError(t, err)
+		return required
+	}
+
+	assert.False(t, readsYourWrites(context.Background()))
+	assert.True(t, readsYourWrites(metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(ConsistencyHeader, ReadYourWrites))))
+}
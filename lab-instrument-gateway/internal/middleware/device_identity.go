@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/tlsutil"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// deviceBoundMethods are the RPCs a device makes on its own behalf. When the
+// caller presents a client certificate, every device_id in these requests
+// must be one the certificate is issued for; likewise for device API keys.
+var deviceBoundMethods = map[string]bool{
+	pb.LabInstrumentGateway_RegisterDevice_FullMethodName: true,
+	pb.LabInstrumentGateway_StreamData_FullMethodName:     true,
+}
+
+// deviceIDGetter is implemented by requests carrying a device ID
+type deviceIDGetter interface {
+	GetDeviceId() string
+}
+
+// DeviceIdentityInterceptor creates a unary server interceptor that binds
+// device RPCs to the identity in the caller's client certificate
+func DeviceIdentityInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if deviceBoundMethods[info.FullMethod] {
+			if err := checkDeviceIdentity(ctx, log, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamDeviceIdentityInterceptor creates a stream server interceptor that
+// checks every message of a device stream against the caller's client
+// certificate
+func StreamDeviceIdentityInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !deviceBoundMethods[info.FullMethod] {
+			return handler(srv, stream)
+		}
+
+		return handler(srv, &identityServerStream{
+			ServerStream: stream,
+			method:       info.FullMethod,
+			logger:       log,
+		})
+	}
+}
+
+// identityServerStream checks the device ID of each received message
+type identityServerStream struct {
+	grpc.ServerStream
+	method string
+	logger *logger.Logger
+}
+
+func (s *identityServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return checkDeviceIdentity(s.Context(), s.logger, s.method, m)
+}
+
+// checkDeviceIdentity returns PermissionDenied if the caller presented a
+// client certificate or device API key that is not issued for the device
+// named in req
+func checkDeviceIdentity(ctx context.Context, log *logger.Logger, method string, req interface{}) error {
+	if identity, ok := auth.FromContext(ctx); ok && identity.Method == auth.MethodAPIKey && identity.IsDevice() {
+		for _, deviceID := range requestDeviceIDs(req) {
+			if slices.Contains(identity.DeviceIDs, deviceID) {
+				continue
+			}
+
+			log.WithFields(map[string]interface{}{
+				"correlation_id": GetCorrelationID(ctx),
+				"method":         method,
+				"device_id":      deviceID,
+				"subject":        identity.Subject,
+			}).Warn("API key does not match device")
+
+			return status.Errorf(codes.PermissionDenied, "API key is not valid for device %s", deviceID)
+		}
+	}
+
+	cert := tlsutil.PeerCertificate(ctx)
+	if cert == nil {
+		return nil
+	}
+
+	for _, deviceID := range requestDeviceIDs(req) {
+		if tlsutil.CertificateMatchesDevice(cert, deviceID) {
+			continue
+		}
+
+		log.WithFields(map[string]interface{}{
+			"correlation_id": GetCorrelationID(ctx),
+			"method":         method,
+			"device_id":      deviceID,
+			"cert_subject":   cert.Subject.String(),
+		}).Warn("Client certificate does not match device")
+
+		return status.Errorf(codes.PermissionDenied, "client certificate is not valid for device %s", deviceID)
+	}
+
+	return nil
+}
+
+// requestDeviceIDs returns the non-empty device IDs carried by a request,
+// including those nested in stream messages
+func requestDeviceIDs(req interface{}) []string {
+	var getters []interface{}
+	if streamReq, ok := req.(*pb.StreamDataRequest); ok {
+		getters = append(getters, streamReq.GetInit(), streamReq.GetData(), streamReq.GetHeartbeat())
+	} else {
+		getters = append(getters, req)
+	}
+
+	var ids []string
+	for _, getter := range getters {
+		if g, ok := getter.(deviceIDGetter); ok {
+			if id := g.GetDeviceId(); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
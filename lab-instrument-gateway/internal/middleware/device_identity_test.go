@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// contextWithClientCert returns a context whose peer presented a verified
+// certificate with the given common name
+func contextWithClientCert(commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{cert}},
+		}},
+	})
+}
+
+// fakeServerStream replays a fixed sequence of stream requests
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []*pb.StreamDataRequest
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	next := s.requests[0]
+	s.requests = s.requests[1:]
+	m.(*pb.StreamDataRequest).Message = next.Message
+	return nil
+}
+
+func TestDeviceIdentityInterceptor(t *testing.T) {
+	interceptor := DeviceIdentityInterceptor(logger.NewDefaultLogger())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	register := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_RegisterDevice_FullMethodName}
+	statusInfo := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_GetDeviceStatus_FullMethodName}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		info *grpc.UnaryServerInfo
+		req  interface{}
+		want codes.Code
+	}{
+		{"matching certificate", contextWithClientCert("hplc-01"), register, &pb.RegisterDeviceRequest{DeviceId: "hplc-01"}, codes.OK},
+		{"other device's id", contextWithClientCert("hplc-01"), register, &pb.RegisterDeviceRequest{DeviceId: "hplc-02"}, codes.PermissionDenied},
+		{"no client certificate", context.Background(), register, &pb.RegisterDeviceRequest{DeviceId: "hplc-02"}, codes.OK},
+		{"operator RPC is not bound", contextWithClientCert("hplc-01"), statusInfo, &pb.GetDeviceStatusRequest{DeviceId: "hplc-02"}, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(tt.ctx, tt.req, tt.info, handler)
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestStreamDeviceIdentityInterceptor(t *testing.T) {
+	interceptor := StreamDeviceIdentityInterceptor(logger.NewDefaultLogger())
+	info := &grpc.StreamServerInfo{FullMethod: pb.LabInstrumentGateway_StreamData_FullMethodName}
+
+	stream := &fakeServerStream{
+		ctx: contextWithClientCert("hplc-01"),
+		requests: []*pb.StreamDataRequest{
+			{Message: &pb.StreamDataRequest_Init{Init: &pb.StreamInit{DeviceId: "hplc-01", SessionId: "s"}}},
+			{Message: &pb.StreamDataRequest_Heartbeat{Heartbeat: &pb.Heartbeat{}}},
+			{Message: &pb.StreamDataRequest_Data{Data: &pb.MeasurementData{DeviceId: "hplc-02"}}},
+		},
+	}
+
+	var errs []error
+	err := interceptor(nil, stream, info, func(srv interface{}, s grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			errs = append(errs, s.RecvMsg(&pb.StreamDataRequest{}))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, codes.PermissionDenied, status.Code(errs[2]))
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/tracing"
 )
 
 // ContextKey represents a context key type
@@ -33,12 +35,12 @@ func LoggingInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
 		startTime := time.Now()
 		ctx = context.WithValue(ctx, RequestStartTimeKey, startTime)
 		
-		// Create logger with correlation ID
+		// Create logger with correlation ID and the trace of the request
 		reqLogger := log.WithFields(map[string]interface{}{
 			"correlation_id": correlationID,
 			"method":         info.FullMethod,
 			"request_time":   startTime.Format(time.RFC3339),
-		})
+		}).WithFields(tracing.LogFields(ctx))
 		
 		// Log request
 		reqLogger.WithField("request", req).Info("gRPC request started")
@@ -85,22 +87,24 @@ func StreamLoggingInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		// Generate correlation ID
 		correlationID := uuid.New().String()
+		traceFields := tracing.LogFields(stream.Context())
 		
 		// Create wrapped stream with correlation ID in context
 		wrappedStream := &loggingServerStream{
 			ServerStream:  stream,
 			correlationID: correlationID,
+			traceFields:   traceFields,
 			logger:        log,
 			method:        info.FullMethod,
 			startTime:     time.Now(),
 		}
 		
-		// Create logger with correlation ID
+		// Create logger with correlation ID and the trace of the stream
 		streamLogger := log.WithFields(map[string]interface{}{
 			"correlation_id": correlationID,
 			"method":         info.FullMethod,
 			"stream_type":    getStreamType(info),
-		})
+		}).WithFields(traceFields)
 		
 		// Log stream start
 		streamLogger.Info("gRPC stream started")
@@ -117,8 +121,8 @@ func StreamLoggingInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
 			"method":            info.FullMethod,
 			"duration_ms":       duration.Milliseconds(),
 			"duration":          duration.String(),
-			"messages_sent":     wrappedStream.messagesSent,
-			"messages_received": wrappedStream.messagesReceived,
+			"messages_sent":     wrappedStream.messagesSent.Load(),
+			"messages_received": wrappedStream.messagesReceived.Load(),
 		}
 		
 		// Log stream completion
@@ -141,15 +145,17 @@ func StreamLoggingInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
 	}
 }
 
-// loggingServerStream wraps grpc.ServerStream to add logging functionality
+// loggingServerStream wraps grpc.ServerStream to add logging functionality.
+// Messages may be sent and received from different goroutines.
 type loggingServerStream struct {
 	grpc.ServerStream
 	correlationID      string
+	traceFields        map[string]interface{}
 	logger             *logger.Logger
 	method             string
 	startTime          time.Time
-	messagesSent       int64
-	messagesReceived   int64
+	messagesSent       atomic.Int64
+	messagesReceived   atomic.Int64
 }
 
 // Context returns the context with correlation ID
@@ -162,13 +168,13 @@ func (s *loggingServerStream) Context() context.Context {
 func (s *loggingServerStream) SendMsg(m interface{}) error {
 	err := s.ServerStream.SendMsg(m)
 	if err == nil {
-		s.messagesSent++
+		count := s.messagesSent.Add(1)
 		s.logger.WithFields(map[string]interface{}{
 			"correlation_id": s.correlationID,
 			"method":         s.method,
 			"direction":      "outbound",
-			"message_count":  s.messagesSent,
-		}).Debug("gRPC stream message sent")
+			"message_count":  count,
+		}).WithFields(s.traceFields).Debug("gRPC stream message sent")
 	}
 	return err
 }
@@ -177,13 +183,13 @@ func (s *loggingServerStream) SendMsg(m interface{}) error {
 func (s *loggingServerStream) RecvMsg(m interface{}) error {
 	err := s.ServerStream.RecvMsg(m)
 	if err == nil {
-		s.messagesReceived++
+		count := s.messagesReceived.Add(1)
 		s.logger.WithFields(map[string]interface{}{
 			"correlation_id": s.correlationID,
 			"method":         s.method,
 			"direction":      "inbound",
-			"message_count":  s.messagesReceived,
-		}).Debug("gRPC stream message received")
+			"message_count":  count,
+		}).WithFields(s.traceFields).Debug("gRPC stream message received")
 	}
 	return err
 }
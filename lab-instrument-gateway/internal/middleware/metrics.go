@@ -8,6 +8,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
 )
 
@@ -39,6 +40,15 @@ var (
 		},
 	)
 	
+	// In-flight requests and streams gauge
+	grpcRequestsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "grpc_requests_in_flight",
+			Help: "Number of gRPC requests and streams being handled",
+		},
+		[]string{"type"},
+	)
+	
 	// Device registration metrics
 	deviceRegistrationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -57,6 +67,21 @@ var (
 		[]string{"status"},
 	)
 	
+	// Device connection metrics
+	deviceConnectionsActive = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "device_connections_active",
+			Help: "Number of devices with an active connection",
+		},
+	)
+	
+	deviceSessionsActive = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "device_sessions_active",
+			Help: "Number of device sessions held by the connection manager",
+		},
+	)
+	
 	// Stream metrics
 	grpcStreamMessagesTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -116,9 +141,10 @@ func MetricsInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		startTime := time.Now()
 		
-		// Increment active connections
-		grpcActiveConnections.Inc()
-		defer grpcActiveConnections.Dec()
+		// Track in-flight requests
+		inFlight := grpcRequestsInFlight.WithLabelValues("unary")
+		inFlight.Inc()
+		defer inFlight.Dec()
 		
 		// Call handler
 		resp, err := handler(ctx, req)
@@ -157,14 +183,17 @@ func StreamMetricsInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		startTime := time.Now()
 		
-		// Increment active connections
-		grpcActiveConnections.Inc()
-		defer grpcActiveConnections.Dec()
+		// Track in-flight streams
+		inFlight := grpcRequestsInFlight.WithLabelValues("stream")
+		inFlight.Inc()
+		defer inFlight.Dec()
 		
-		// Create wrapped stream for message counting
+		// Create wrapped stream for message counting; messages are counted
+		// as they pass so that long-lived streams report while open
 		wrappedStream := &metricsServerStream{
 			ServerStream: stream,
-			method:       info.FullMethod,
+			sent:         grpcStreamMessagesTotal.WithLabelValues(info.FullMethod, "sent"),
+			received:     grpcStreamMessagesTotal.WithLabelValues(info.FullMethod, "received"),
 		}
 		
 		// Call handler
@@ -182,10 +211,6 @@ func StreamMetricsInterceptor() grpc.StreamServerInterceptor {
 		// Record metrics
 		grpcStreamDuration.WithLabelValues(info.FullMethod, statusCode.String()).Observe(duration.Seconds())
 		
-		// Record message metrics
-		grpcStreamMessagesTotal.WithLabelValues(info.FullMethod, "sent").Add(float64(wrappedStream.messagesSent))
-		grpcStreamMessagesTotal.WithLabelValues(info.FullMethod, "received").Add(float64(wrappedStream.messagesReceived))
-		
 		// Record error metrics if applicable
 		if err != nil {
 			errorType := getErrorType(statusCode)
@@ -199,16 +224,15 @@ func StreamMetricsInterceptor() grpc.StreamServerInterceptor {
 // metricsServerStream wraps grpc.ServerStream to count messages
 type metricsServerStream struct {
 	grpc.ServerStream
-	method           string
-	messagesSent     int64
-	messagesReceived int64
+	sent     prometheus.Counter
+	received prometheus.Counter
 }
 
 // SendMsg counts outgoing messages
 func (s *metricsServerStream) SendMsg(m interface{}) error {
 	err := s.ServerStream.SendMsg(m)
 	if err == nil {
-		s.messagesSent++
+		s.sent.Inc()
 	}
 	return err
 }
@@ -217,11 +241,44 @@ func (s *metricsServerStream) SendMsg(m interface{}) error {
 func (s *metricsServerStream) RecvMsg(m interface{}) error {
 	err := s.ServerStream.RecvMsg(m)
 	if err == nil {
-		s.messagesReceived++
+		s.received.Inc()
 	}
 	return err
 }
 
+// connectionMetricsHandler is a gRPC stats handler counting open transport
+// connections
+type connectionMetricsHandler struct{}
+
+// ConnectionMetricsHandler creates a stats handler that keeps the active
+// connections gauge up to date; install it with grpc.StatsHandler
+func ConnectionMetricsHandler() stats.Handler {
+	return connectionMetricsHandler{}
+}
+
+// TagRPC implements stats.Handler
+func (connectionMetricsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler
+func (connectionMetricsHandler) HandleRPC(ctx context.Context, rpcStats stats.RPCStats) {}
+
+// TagConn implements stats.Handler
+func (connectionMetricsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn counts connections as they begin and end
+func (connectionMetricsHandler) HandleConn(ctx context.Context, connStats stats.ConnStats) {
+	switch connStats.(type) {
+	case *stats.ConnBegin:
+		grpcActiveConnections.Inc()
+	case *stats.ConnEnd:
+		grpcActiveConnections.Dec()
+	}
+}
+
 // recordDeviceMetrics records device-specific metrics based on the method and request
 func recordDeviceMetrics(method string, req, resp interface{}, err error) {
 	switch {
@@ -321,6 +378,12 @@ func UpdateDeviceStatusMetrics(statusCounts map[string]int) {
 	}
 }
 
+// UpdateDeviceConnectionMetrics updates device connection metrics
+func UpdateDeviceConnectionMetrics(connected, sessions int) {
+	deviceConnectionsActive.Set(float64(connected))
+	deviceSessionsActive.Set(float64(sessions))
+}
+
 // GetMetricsRegistry returns the Prometheus registry for metrics exposure
 func GetMetricsRegistry() *prometheus.Registry {
 	return prometheus.DefaultRegisterer.(*prometheus.Registry)
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// echoStream accepts every message sent and receives empty requests
+type echoStream struct {
+	grpc.ServerStream
+}
+
+func (s *echoStream) Context() context.Context    { return context.Background() }
+func (s *echoStream) SendMsg(m interface{}) error { return nil }
+func (s *echoStream) RecvMsg(m interface{}) error { return nil }
+
+func TestStreamMetricsInterceptor(t *testing.T) {
+	const method = "/lab_instrument.LabInstrumentGateway/StreamData"
+	sent := grpcStreamMessagesTotal.WithLabelValues(method, "sent")
+	received := grpcStreamMessagesTotal.WithLabelValues(method, "received")
+	sentBefore, receivedBefore := testutil.ToFloat64(sent), testutil.ToFloat64(received)
+
+	interceptor := StreamMetricsInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: method}
+
+	err := interceptor(nil, &echoStream{}, info, func(srv interface{}, stream grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, stream.RecvMsg(&pb.StreamDataRequest{}))
+		}
+		// Messages are counted while the stream is open
+		assert.Equal(t, receivedBefore+3, testutil.ToFloat64(received))
+		assert.Equal(t, float64(1), testutil.ToFloat64(grpcRequestsInFlight.WithLabelValues("stream")))
+		return stream.SendMsg(&pb.StreamDataResponse{})
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, sentBefore+1, testutil.ToFloat64(sent))
+	assert.Equal(t, float64(0), testutil.ToFloat64(grpcRequestsInFlight.WithLabelValues("stream")))
+}
+
+func TestConnectionMetricsHandler(t *testing.T) {
+	handler := ConnectionMetricsHandler()
+	before := testutil.ToFloat64(grpcActiveConnections)
+
+	handler.HandleConn(context.Background(), &stats.ConnBegin{})
+	handler.HandleConn(context.Background(), &stats.ConnBegin{})
+	assert.Equal(t, before+2, testutil.ToFloat64(grpcActiveConnections))
+
+	handler.HandleConn(context.Background(), &stats.ConnEnd{})
+	assert.Equal(t, before+1, testutil.ToFloat64(grpcActiveConnections))
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/ratelimit"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// unlimitedMethods are the health check RPCs, which probes call regardless
+// of any limit
+var unlimitedMethods = map[string]bool{
+	pb.LabInstrumentGateway_HealthCheck_FullMethodName: true,
+	healthpb.Health_Check_FullMethodName:               true,
+	healthpb.Health_List_FullMethodName:                true,
+	healthpb.Health_Watch_FullMethodName:               true,
+}
+
+// RetryAfterHeader is the response metadata key carrying the number of
+// seconds a rate limited caller should wait before retrying
+const RetryAfterHeader = "retry-after"
+
+// RateLimitInterceptor creates a unary server interceptor that enforces the
+// per principal, per method and per device request limits. It must run after
+// AuthInterceptor so that callers are limited by identity; a device's limit
+// only counts the calls of the device itself.
+func RateLimitInterceptor(limiter *ratelimit.Limiter, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if unlimitedMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		principal := rateLimitPrincipal(ctx)
+		decision := limiter.AllowRequest(principal, info.FullMethod, rateLimitDevices(ctx, req))
+		if !decision.Allowed {
+			_ = grpc.SetHeader(ctx, retryAfterMetadata(decision.RetryAfter))
+			return nil, rateLimitError(ctx, log, principal, info.FullMethod, decision)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor creates a stream server interceptor that counts
+// opening a stream against the caller's request limits. Messages within a
+// stream are limited by the stream handler.
+func StreamRateLimitInterceptor(limiter *ratelimit.Limiter, log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if unlimitedMethods[info.FullMethod] {
+			return handler(srv, stream)
+		}
+
+		ctx := stream.Context()
+		principal := rateLimitPrincipal(ctx)
+
+		decision := limiter.AllowRequest(principal, info.FullMethod, nil)
+		if !decision.Allowed {
+			_ = stream.SetHeader(retryAfterMetadata(decision.RetryAfter))
+			return rateLimitError(ctx, log, principal, info.FullMethod, decision)
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// rateLimitPrincipal identifies the caller for rate limiting: by its
+// authenticated identity, or else by its network address
+func rateLimitPrincipal(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return string(identity.Method) + ":" + identity.Subject
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "addr:" + host
+	}
+
+	return "anonymous"
+}
+
+// rateLimitDevices returns the devices named by a request that the caller
+// authenticated as. Requests naming other devices, including unauthenticated
+// registrations, only count against their principal, so that no caller can
+// use up an instrument's limit by naming it.
+func rateLimitDevices(ctx context.Context, req interface{}) []string {
+	identity, ok := auth.FromContext(ctx)
+	if !ok || !identity.IsDevice() {
+		return nil
+	}
+
+	var deviceIDs []string
+	for _, deviceID := range requestDeviceIDs(req) {
+		if slices.Contains(identity.DeviceIDs, deviceID) {
+			deviceIDs = append(deviceIDs, deviceID)
+		}
+	}
+	return deviceIDs
+}
+
+// retryAfterMetadata returns the retry-after header, in whole seconds
+func retryAfterMetadata(retryAfter time.Duration) metadata.MD {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return metadata.Pairs(RetryAfterHeader, strconv.FormatInt(seconds, 10))
+}
+
+// rateLimitError logs a rejected call and returns a ResourceExhausted error
+// carrying the retry delay
+func rateLimitError(ctx context.Context, log *logger.Logger, principal, method string, decision ratelimit.Decision) error {
+	log.WithFields(map[string]interface{}{
+		"correlation_id": GetCorrelationID(ctx),
+		"method":         method,
+		"principal":      principal,
+		"scope":          decision.Scope,
+		"retry_after":    decision.RetryAfter.String(),
+	}).Warn("Request rate limited")
+
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("%s rate limit exceeded, retry after %s", decision.Scope, decision.RetryAfter.Round(time.Millisecond)))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(decision.RetryAfter)}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/ratelimit"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// headerStream records the headers set on a stream
+type headerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *headerStream) Context() context.Context { return s.ctx }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Enabled:   true,
+		Principal: ratelimit.Limit{Requests: 1, Window: 90 * time.Second},
+	}, logger.NewDefaultLogger())
+	interceptor := RateLimitInterceptor(limiter, logger.NewDefaultLogger())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_ListDevices_FullMethodName}
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "ada", Method: auth.MethodJWT})
+
+	_, err := interceptor(ctx, &pb.ListDevicesRequest{}, info, handler)
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, &pb.ListDevicesRequest{}, info, handler)
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.InDelta(t, 90, retryInfo.RetryDelay.AsDuration().Seconds(), 1)
+
+	health := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_HealthCheck_FullMethodName}
+	_, err = interceptor(ctx, &pb.HealthCheckRequest{}, health, handler)
+	assert.NoError(t, err, "health checks are never limited")
+
+	other := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "bob", Method: auth.MethodJWT})
+	_, err = interceptor(other, &pb.ListDevicesRequest{}, info, handler)
+	assert.NoError(t, err)
+}
+
+func TestRateLimitInterceptor_DeviceLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Enabled:   true,
+		Principal: ratelimit.Limit{Requests: 100, Window: time.Minute},
+		Device:    ratelimit.Limit{Requests: 1, Window: time.Minute},
+	}, logger.NewDefaultLogger())
+	interceptor := RateLimitInterceptor(limiter, logger.NewDefaultLogger())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: pb.LabInstrumentGateway_RegisterDevice_FullMethodName}
+	req := &pb.RegisterDeviceRequest{DeviceId: "hplc-01"}
+
+	// Callers naming a device they are not do not use up its limit
+	for _, ctx := range []context.Context{
+		context.Background(),
+		auth.WithIdentity(context.Background(), &auth.Identity{Subject: "ada", Method: auth.MethodJWT}),
+		auth.WithIdentity(context.Background(), &auth.Identity{Subject: "hplc-02", Method: auth.MethodAPIKey, DeviceIDs: []string{"hplc-02"}}),
+	} {
+		for i := 0; i < 3; i++ {
+			_, err := interceptor(ctx, req, info, handler)
+			require.NoError(t, err)
+		}
+	}
+
+	device := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "hplc-01", Method: auth.MethodAPIKey, DeviceIDs: []string{"hplc-01"}})
+	_, err := interceptor(device, req, info, handler)
+	require.NoError(t, err)
+	_, err = interceptor(device, req, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestStreamRateLimitInterceptor(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Enabled:   true,
+		Principal: ratelimit.Limit{Requests: 1, Window: 2500 * time.Millisecond},
+	}, logger.NewDefaultLogger())
+	interceptor := StreamRateLimitInterceptor(limiter, logger.NewDefaultLogger())
+	handler := func(srv interface{}, stream grpc.ServerStream) error { return nil }
+	info := &grpc.StreamServerInfo{FullMethod: pb.LabInstrumentGateway_StreamData_FullMethodName}
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{Subject: "hplc-01", Method: auth.MethodCertificate})
+
+	require.NoError(t, interceptor(nil, &headerStream{ctx: ctx}, info, handler))
+
+	stream := &headerStream{ctx: ctx}
+	err := interceptor(nil, stream, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"3"}, stream.header.Get(RetryAfterHeader))
+}
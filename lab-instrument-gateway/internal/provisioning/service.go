@@ -0,0 +1,322 @@
+package provisioning
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// Provisioning errors
+var (
+	ErrInvalidEnrollmentToken  = errors.New("invalid, used or expired enrollment token")
+	ErrEnrollmentConflict      = errors.New("device is already registered")
+	ErrCertificatesUnavailable = errors.New("certificate issuance is not configured")
+	ErrInvalidTokenLifetime    = errors.New("invalid enrollment token lifetime")
+	ErrInvalidCSR              = errors.New("invalid certificate signing request")
+	ErrUnsupportedCredential   = errors.New("unsupported credential type")
+)
+
+// Default provisioning settings
+const (
+	DefaultTokenTTL            = 24 * time.Hour
+	DefaultMaxTokenTTL         = 7 * 24 * time.Hour
+	DefaultCertificateValidity = 365 * 24 * time.Hour
+)
+
+// CredentialType is the kind of long-lived credential issued on enrollment
+type CredentialType string
+
+const (
+	CredentialAPIKey      CredentialType = "api_key"
+	CredentialCertificate CredentialType = "certificate"
+)
+
+// Config represents the provisioning configuration
+type Config struct {
+	// CACertFile and CAKeyFile locate the local CA that signs device
+	// certificates; certificate enrollment is unavailable without them
+	CACertFile string
+	CAKeyFile  string
+
+	// TokenTTL is the default enrollment token lifetime, MaxTokenTTL the
+	// longest an administrator may request
+	TokenTTL    time.Duration
+	MaxTokenTTL time.Duration
+
+	// CertificateValidity is the lifetime of issued certificates;
+	// APIKeyValidity that of issued API keys, which never expire if zero
+	CertificateValidity time.Duration
+	APIKeyValidity      time.Duration
+}
+
+// SetDefaults sets default values for the provisioning configuration
+func (c *Config) SetDefaults() {
+	if c.TokenTTL <= 0 {
+		c.TokenTTL = DefaultTokenTTL
+	}
+	if c.MaxTokenTTL <= 0 {
+		c.MaxTokenTTL = DefaultMaxTokenTTL
+	}
+	if c.CertificateValidity <= 0 {
+		c.CertificateValidity = DefaultCertificateValidity
+	}
+}
+
+// EnrollRequest is a device's request to exchange an enrollment token for a
+// credential
+type EnrollRequest struct {
+	Token          string
+	DeviceID       string
+	Name           string
+	CredentialType CredentialType
+
+	// CSR is the PEM certificate signing request for certificate credentials
+	CSR []byte
+}
+
+// Credential is the credential issued to an enrolled device
+type Credential struct {
+	DeviceID string
+	Type     CredentialType
+
+	// APIKey is set for API key credentials
+	APIKey string
+
+	// Certificate and CACertificate are PEM encoded, for certificate credentials
+	Certificate   []byte
+	CACertificate []byte
+
+	ExpiresAt *time.Time
+}
+
+// Service issues enrollment tokens and exchanges them for device credentials
+type Service struct {
+	repos  repository.RepositoryManager
+	ca     *LocalCA
+	config Config
+	logger *logger.Logger
+}
+
+// NewService creates a new provisioning service, loading the local CA if one
+// is configured
+func NewService(config Config, repos repository.RepositoryManager, logger *logger.Logger) (*Service, error) {
+	config.SetDefaults()
+
+	var ca *LocalCA
+	if config.CACertFile != "" || config.CAKeyFile != "" {
+		var err error
+		ca, err = LoadLocalCA(config.CACertFile, config.CAKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load provisioning CA: %w", err)
+		}
+	}
+
+	return &Service{
+		repos:  repos,
+		ca:     ca,
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// CertificatesEnabled returns true if certificate credentials can be issued
+func (s *Service) CertificatesEnabled() bool {
+	return s.ca != nil
+}
+
+// CreateEnrollmentToken creates a one-time token allowing a device of the
+// given type to enroll. The returned token is not stored and cannot be
+// retrieved again. A zero ttl uses the configured default.
+func (s *Service) CreateEnrollmentToken(ctx context.Context, deviceType, deviceGroup, createdBy string, ttl time.Duration) (string, *models.EnrollmentToken, error) {
+	if ttl == 0 {
+		ttl = s.config.TokenTTL
+	}
+	if ttl < 0 || ttl > s.config.MaxTokenTTL {
+		return "", nil, fmt.Errorf("%w: must be at most %s", ErrInvalidTokenLifetime, s.config.MaxTokenTTL)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate enrollment token: %w", err)
+	}
+	plaintext := base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &models.EnrollmentToken{
+		TokenHash:  auth.HashSecret(plaintext),
+		DeviceType: normalizeDeviceType(deviceType),
+		CreatedBy:  createdBy,
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+	if deviceGroup != "" {
+		token.DeviceGroup = &deviceGroup
+	}
+
+	if err := s.repos.Provisioning().CreateEnrollmentToken(ctx, token); err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, token, nil
+}
+
+// Enroll consumes an enrollment token and issues a credential for a new
+// device, created with the token's type and group. Tokens are not tied to a
+// device, so existing devices cannot be enrolled: their credentials would let
+// any holder of a token for the group impersonate them.
+func (s *Service) Enroll(ctx context.Context, req EnrollRequest) (*Credential, error) {
+	// Reject malformed requests before the token is spent
+	switch req.CredentialType {
+	case CredentialAPIKey:
+	case CredentialCertificate:
+		if s.ca == nil {
+			return nil, ErrCertificatesUnavailable
+		}
+		if _, err := ParseCSR(req.CSR); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedCredential, req.CredentialType)
+	}
+
+	var credential *Credential
+	err := s.repos.WithTransaction(ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		token, err := repos.Provisioning().ConsumeEnrollmentToken(ctx, auth.HashSecret(req.Token), req.DeviceID, time.Now())
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidEnrollmentToken
+			}
+			return err
+		}
+
+		if err := s.createDevice(ctx, repos, token, req); err != nil {
+			return err
+		}
+
+		if req.CredentialType == CredentialCertificate {
+			credential, err = s.issueCertificate(ctx, repos, req)
+		} else {
+			credential, err = s.issueAPIKey(ctx, repos, token, req)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"device_id":       req.DeviceID,
+		"credential_type": req.CredentialType,
+	}).Info("Device enrolled")
+
+	return credential, nil
+}
+
+// createDevice creates the enrolling device, refusing device IDs that are
+// already registered
+func (s *Service) createDevice(ctx context.Context, repos repository.RepositoryManager, token *models.EnrollmentToken, req EnrollRequest) error {
+	group := ""
+	if token.DeviceGroup != nil {
+		group = *token.DeviceGroup
+	}
+
+	if _, err := repos.Device().GetByID(ctx, req.DeviceID); err == nil {
+		return ErrEnrollmentConflict
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to look up device: %w", err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = req.DeviceID
+	}
+
+	now := time.Now()
+	device := &models.Device{
+		ID:           req.DeviceID,
+		Name:         name,
+		Type:         token.DeviceType,
+		Status:       models.DeviceStatusUnknown,
+		Metadata:     map[string]interface{}{"enrollment_token_id": token.ID},
+		RegisteredAt: now,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if group != "" {
+		device.Metadata[models.DeviceGroupMetadataKey] = group
+	}
+
+	return repos.Device().Create(ctx, device)
+}
+
+// issueAPIKey creates and stores an API key bound to the device
+func (s *Service) issueAPIKey(ctx context.Context, repos repository.RepositoryManager, token *models.EnrollmentToken, req EnrollRequest) (*Credential, error) {
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceID := req.DeviceID
+	apiKey := &models.APIKey{
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Name:      "enrollment",
+		Subject:   deviceID,
+		DeviceID:  &deviceID,
+		CreatedBy: token.CreatedBy,
+	}
+	if s.config.APIKeyValidity > 0 {
+		expiresAt := time.Now().Add(s.config.APIKeyValidity)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := repos.APIKey().Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		DeviceID:  deviceID,
+		Type:      CredentialAPIKey,
+		APIKey:    key,
+		ExpiresAt: apiKey.ExpiresAt,
+	}, nil
+}
+
+// issueCertificate signs the device's CSR with the local CA and records it
+func (s *Service) issueCertificate(ctx context.Context, repos repository.RepositoryManager, req EnrollRequest) (*Credential, error) {
+	cert, certPEM, err := s.ca.SignCSR(req.CSR, req.DeviceID, s.config.CertificateValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repos.Provisioning().RecordCertificate(ctx, &models.DeviceCertificate{
+		SerialNumber: cert.SerialNumber.Text(16),
+		DeviceID:     req.DeviceID,
+		Subject:      cert.Subject.String(),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		DeviceID:      req.DeviceID,
+		Type:          CredentialCertificate,
+		Certificate:   certPEM,
+		CACertificate: s.ca.CertificatePEM(),
+		ExpiresAt:     &cert.NotAfter,
+	}, nil
+}
+
+// normalizeDeviceType matches the normalization applied on registration
+func normalizeDeviceType(deviceType string) string {
+	return strings.ToLower(strings.TrimSpace(deviceType))
+}
@@ -0,0 +1,183 @@
+package provisioning
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/internal/tlsutil"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+)
+
+// newTestCA creates a self-signed ECDSA CA
+func newTestCA(t *testing.T) *LocalCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Lab Gateway Device CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(30 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	ca, err := NewLocalCA(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	)
+	require.NoError(t, err)
+	return ca
+}
+
+// newTestCSR creates a CSR for a fresh ECDSA key
+func newTestCSR(t *testing.T, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: commonName},
+	}, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func newTestService(t *testing.T, ca *LocalCA) (*Service, repository.RepositoryManager) {
+	repos := memory.NewRepositoryManager()
+	service, err := NewService(Config{}, repos, logger.NewDefaultLogger())
+	require.NoError(t, err)
+	service.ca = ca
+	return service, repos
+}
+
+func TestLocalCA_SignCSR(t *testing.T) {
+	ca := newTestCA(t)
+
+	// The requested subject is ignored in favour of the enrolled device ID
+	cert, certPEM, err := ca.SignCSR(newTestCSR(t, "someone-else"), "hplc-01", 24*time.Hour)
+	require.NoError(t, err)
+	assert.NotEmpty(t, certPEM)
+	assert.Equal(t, "hplc-01", cert.Subject.CommonName)
+	assert.Equal(t, []string{"hplc-01"}, tlsutil.DeviceIDs(cert))
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca.CertificatePEM())
+	_, err = cert.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+
+	// Validity is capped at the CA's own expiry
+	cert, _, err = ca.SignCSR(newTestCSR(t, "hplc-01"), "hplc-01", 365*24*time.Hour)
+	require.NoError(t, err)
+	assert.False(t, cert.NotAfter.After(ca.cert.NotAfter))
+
+	_, _, err = ca.SignCSR([]byte("not a csr"), "hplc-01", time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidCSR)
+}
+
+func TestService_EnrollWithAPIKey(t *testing.T) {
+	service, repos := newTestService(t, nil)
+	ctx := context.Background()
+
+	token, record, err := service.CreateEnrollmentToken(ctx, " Analyzer ", "lab-a", "admin", 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, record.TokenHash)
+	assert.Equal(t, "analyzer", record.DeviceType)
+
+	credential, err := service.Enroll(ctx, EnrollRequest{Token: token, DeviceID: "hplc-01", CredentialType: CredentialAPIKey})
+	require.NoError(t, err)
+	assert.Equal(t, "hplc-01", credential.DeviceID)
+
+	device, err := repos.Device().GetByID(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Equal(t, "analyzer", device.Type)
+	assert.Equal(t, "lab-a", device.Group())
+
+	// The issued key authenticates as the enrolled device
+	identity, err := auth.NewAPIKeyVerifier(repos.APIKey(), logger.NewDefaultLogger()).Verify(ctx, credential.APIKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hplc-01"}, identity.DeviceIDs)
+
+	// Tokens are single use
+	_, err = service.Enroll(ctx, EnrollRequest{Token: token, DeviceID: "hplc-02", CredentialType: CredentialAPIKey})
+	assert.ErrorIs(t, err, ErrInvalidEnrollmentToken)
+
+	_, err = service.Enroll(ctx, EnrollRequest{Token: "guess", DeviceID: "hplc-02", CredentialType: CredentialAPIKey})
+	assert.ErrorIs(t, err, ErrInvalidEnrollmentToken)
+
+	_, _, err = service.CreateEnrollmentToken(ctx, "analyzer", "", "admin", 30*24*time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidTokenLifetime)
+}
+
+func TestService_EnrollRejectsExistingDevice(t *testing.T) {
+	service, repos := newTestService(t, nil)
+	ctx := context.Background()
+	require.NoError(t, repos.Device().Create(ctx, &models.Device{
+		ID:       "hplc-01",
+		Name:     "HPLC 1",
+		Type:     "analyzer",
+		Status:   models.DeviceStatusOffline,
+		Metadata: map[string]interface{}{models.DeviceGroupMetadataKey: "lab-b"},
+	}))
+
+	// A token for the device's own type and group does not issue
+	// credentials for it either
+	for _, deviceGroup := range []string{"lab-a", "lab-b"} {
+		token, _, err := service.CreateEnrollmentToken(ctx, "analyzer", deviceGroup, "admin", time.Hour)
+		require.NoError(t, err)
+
+		_, err = service.Enroll(ctx, EnrollRequest{Token: token, DeviceID: "hplc-01", CredentialType: CredentialAPIKey})
+		assert.ErrorIs(t, err, ErrEnrollmentConflict, deviceGroup)
+	}
+	keys, err := repos.APIKey().Count(ctx, repository.APIKeyFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, keys)
+}
+
+func TestService_EnrollWithCertificate(t *testing.T) {
+	ctx := context.Background()
+
+	withoutCA, _ := newTestService(t, nil)
+	_, err := withoutCA.Enroll(ctx, EnrollRequest{Token: "x", DeviceID: "hplc-01", CredentialType: CredentialCertificate})
+	assert.ErrorIs(t, err, ErrCertificatesUnavailable)
+
+	service, repos := newTestService(t, newTestCA(t))
+	token, _, err := service.CreateEnrollmentToken(ctx, "analyzer", "", "admin", time.Hour)
+	require.NoError(t, err)
+
+	// A malformed CSR does not spend the token
+	_, err = service.Enroll(ctx, EnrollRequest{Token: token, DeviceID: "hplc-01", CredentialType: CredentialCertificate, CSR: []byte("junk")})
+	assert.ErrorIs(t, err, ErrInvalidCSR)
+
+	credential, err := service.Enroll(ctx, EnrollRequest{
+		Token:          token,
+		DeviceID:       "hplc-01",
+		CredentialType: CredentialCertificate,
+		CSR:            newTestCSR(t, "hplc-01"),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, credential.Certificate)
+	assert.Equal(t, service.ca.CertificatePEM(), credential.CACertificate)
+	certificates, err := repos.Provisioning().ListCertificates(ctx, "hplc-01")
+	require.NoError(t, err)
+	assert.Len(t, certificates, 1)
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket allowing Requests per Window on average, with
+// bursts of up to Burst requests. A zero Requests means unlimited.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+	Burst    int
+}
+
+// Unlimited returns true if the limit does not restrict anything
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Window <= 0
+}
+
+// rate returns the number of tokens added per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
+
+// burst returns the bucket capacity, defaulting to Requests
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// String formats the limit as requests/window
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// ParseLimit parses a limit written as requests/window, such as 100/1m
+func ParseLimit(value string) (Limit, error) {
+	requests, window, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected requests/window", value)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", value)
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: window must be a positive duration", value)
+	}
+
+	return Limit{Requests: n, Window: d}, nil
+}
+
+// ParseMethodLimits parses per-method limits written as a comma separated
+// list of method=requests/window, such as GetMeasurements=10/1s. Methods are
+// short RPC names.
+func ParseMethodLimits(value string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	if strings.TrimSpace(value) == "" {
+		return limits, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		method, spec, ok := strings.Cut(entry, "=")
+		method = strings.TrimSpace(method)
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid method rate limit %q: expected method=requests/window", entry)
+		}
+
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		limits[method] = limit
+	}
+
+	return limits, nil
+}
+
+// bucket is the state of one token bucket. Tokens may go negative when a
+// caller reserves capacity it will wait for.
+type bucket struct {
+	tokens   float64
+	last     time.Time
+	lastUsed time.Time
+}
+
+// refill adds the tokens accrued since the last update
+func (b *bucket) refill(now time.Time, limit Limit) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(limit.burst(), b.tokens+elapsed*limit.rate())
+		b.last = now
+	}
+}
+
+// wait returns how long until the bucket holds a whole token
+func (b *bucket) wait(limit Limit) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / limit.rate() * float64(time.Second))
+}
@@ -0,0 +1,240 @@
+package ratelimit
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// Default rate limiting settings
+const (
+	DefaultStreamMaxDelay = time.Second
+	DefaultIdleTimeout    = 10 * time.Minute
+)
+
+// Config represents the rate limiting configuration
+type Config struct {
+	Enabled bool
+
+	// Principal limits the requests of each caller, identified by its
+	// authenticated subject or else its address
+	Principal Limit
+
+	// Device limits the requests each device makes as itself
+	Device Limit
+
+	// Methods limits each caller's requests to individual RPCs, keyed by
+	// short method name, in addition to its principal limit
+	Methods map[string]Limit
+
+	// StreamMessages limits the measurement messages of each StreamData
+	// device. Messages over the limit are delayed by up to StreamMaxDelay,
+	// then rejected.
+	StreamMessages Limit
+	StreamMaxDelay time.Duration
+
+	// IdleTimeout is how long an unused bucket is kept
+	IdleTimeout time.Duration
+}
+
+// SetDefaults sets default values for the rate limiting configuration
+func (c *Config) SetDefaults() {
+	if c.StreamMaxDelay <= 0 {
+		c.StreamMaxDelay = DefaultStreamMaxDelay
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = DefaultIdleTimeout
+	}
+
+	// Buckets must refill completely before they are evicted
+	limits := []Limit{c.Principal, c.Device, c.StreamMessages}
+	for _, limit := range c.Methods {
+		limits = append(limits, limit)
+	}
+	for _, limit := range limits {
+		if limit.Window > c.IdleTimeout {
+			c.IdleTimeout = limit.Window
+		}
+	}
+}
+
+// Decision is the outcome of a rate limit check
+type Decision struct {
+	Allowed bool
+
+	// RetryAfter is how long the caller should wait before retrying a
+	// rejected request, or must wait before a delayed message is processed
+	RetryAfter time.Duration
+
+	// Scope names the limit that rejected the request: principal, device
+	// or method
+	Scope string
+}
+
+// check is a bucket to take a token from
+type check struct {
+	key   string
+	scope string
+	limit Limit
+}
+
+// Limiter enforces token bucket limits per principal, device and method.
+// Call Start to begin evicting idle buckets.
+type Limiter struct {
+	config Config
+	logger *logger.Logger
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewLimiter creates a new rate limiter
+func NewLimiter(config Config, logger *logger.Logger) *Limiter {
+	config.SetDefaults()
+
+	return &Limiter{
+		config:   config,
+		logger:   logger,
+		buckets:  make(map[string]*bucket),
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// AllowRequest takes a token from the buckets of the principal, of the
+// principal and method and of every device the request is made by. Tokens are
+// only taken if all buckets allow the request.
+func (l *Limiter) AllowRequest(principal, fullMethod string, deviceIDs []string) Decision {
+	checks := []check{{key: "principal:" + principal, scope: "principal", limit: l.config.Principal}}
+
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if limit, ok := l.config.Methods[method]; ok {
+		checks = append(checks, check{key: "method:" + method + ":" + principal, scope: "method", limit: limit})
+	}
+
+	for _, deviceID := range deviceIDs {
+		checks = append(checks, check{key: "device:" + deviceID, scope: "device", limit: l.config.Device})
+	}
+
+	return l.take(checks, 0)
+}
+
+// AllowMessage takes a token from the stream message bucket of a device. A
+// message over the limit is allowed if it can be processed within the
+// configured maximum delay, and RetryAfter is then how long to wait first.
+func (l *Limiter) AllowMessage(deviceID string) Decision {
+	return l.take([]check{{key: "stream:" + deviceID, scope: "device", limit: l.config.StreamMessages}}, l.config.StreamMaxDelay)
+}
+
+// take takes one token from each bucket if every bucket has one within
+// maxDelay
+func (l *Limiter) take(checks []check, maxDelay time.Duration) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	decision := Decision{Allowed: true}
+	buckets := make([]*bucket, len(checks))
+
+	for i, c := range checks {
+		if c.limit.Unlimited() {
+			continue
+		}
+
+		b, ok := l.buckets[c.key]
+		if !ok {
+			b = &bucket{tokens: c.limit.burst(), last: now}
+			l.buckets[c.key] = b
+		}
+		b.refill(now, c.limit)
+		b.lastUsed = now
+		buckets[i] = b
+
+		if wait := b.wait(c.limit); wait > decision.RetryAfter {
+			decision.RetryAfter = wait
+			decision.Scope = c.scope
+		}
+	}
+
+	if decision.RetryAfter > maxDelay {
+		decision.Allowed = false
+		return decision
+	}
+
+	for _, b := range buckets {
+		if b != nil {
+			b.tokens--
+		}
+	}
+
+	return decision
+}
+
+// Start starts evicting idle buckets
+func (l *Limiter) Start() {
+	go l.evictionRoutine()
+}
+
+// Close stops the eviction routine
+func (l *Limiter) Close() error {
+	close(l.stopChan)
+
+	select {
+	case <-l.doneChan:
+		l.logger.Info("Rate limiter stopped")
+	case <-time.After(5 * time.Second):
+		l.logger.Warn("Rate limiter did not stop within timeout")
+	}
+
+	return nil
+}
+
+// evictionRoutine periodically drops buckets that have not been used
+func (l *Limiter) evictionRoutine() {
+	defer close(l.doneChan)
+
+	ticker := time.NewTicker(l.config.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stopChan:
+			return
+		case <-ticker.C:
+			l.evictIdle()
+		}
+	}
+}
+
+// evictIdle drops buckets unused for longer than the idle timeout. As the
+// timeout is at least the longest window, such a bucket has refilled
+// completely and dropping it does not change any future decision.
+func (l *Limiter) evictIdle() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	threshold := l.now().Add(-l.config.IdleTimeout)
+	evicted := 0
+	for key, b := range l.buckets {
+		if b.lastUsed.Before(threshold) {
+			delete(l.buckets, key)
+			evicted++
+		}
+	}
+
+	if evicted > 0 {
+		l.logger.WithFields(map[string]interface{}{
+			"evicted":   evicted,
+			"remaining": len(l.buckets),
+		}).Debug("Evicted idle rate limit buckets")
+	}
+
+	return evicted
+}
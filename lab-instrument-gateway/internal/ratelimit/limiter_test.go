@@ -0,0 +1,179 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+const getMeasurements = "/lab.instrument.v1.LabInstrumentGateway/GetMeasurements"
+
+// newTestLimiter returns a limiter whose clock is advanced by the returned
+// function
+func newTestLimiter(config Config) (*Limiter, func(time.Duration)) {
+	limiter := NewLimiter(config, logger.NewDefaultLogger())
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestLimiter_PrincipalBurstAndRefill(t *testing.T) {
+	limiter, advance := newTestLimiter(Config{Principal: Limit{Requests: 2, Window: time.Second}})
+
+	assert.True(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+	assert.True(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+
+	decision := limiter.AllowRequest("jwt:ada", getMeasurements, nil)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "principal", decision.Scope)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+
+	assert.True(t, limiter.AllowRequest("jwt:bob", getMeasurements, nil).Allowed, "principals have separate buckets")
+
+	advance(500 * time.Millisecond)
+	assert.True(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+	assert.False(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+}
+
+func TestLimiter_Burst(t *testing.T) {
+	limiter, advance := newTestLimiter(Config{Principal: Limit{Requests: 1, Window: time.Second, Burst: 3}})
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+	}
+	assert.False(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+
+	advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+	}
+	assert.False(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed, "refill is capped at the burst")
+}
+
+func TestLimiter_MethodAndDeviceLimits(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{
+		Principal: Limit{Requests: 10, Window: time.Second},
+		Device:    Limit{Requests: 2, Window: time.Minute},
+		Methods:   map[string]Limit{"GetMeasurements": {Requests: 1, Window: time.Second}},
+	})
+
+	assert.True(t, limiter.AllowRequest("jwt:ada", getMeasurements, nil).Allowed)
+	decision := limiter.AllowRequest("jwt:ada", getMeasurements, nil)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "method", decision.Scope)
+	assert.True(t, limiter.AllowRequest("jwt:ada", "/lab.instrument.v1.LabInstrumentGateway/ListDevices", nil).Allowed)
+
+	status := "/lab.instrument.v1.LabInstrumentGateway/GetDeviceStatus"
+	assert.True(t, limiter.AllowRequest("jwt:ada", status, []string{"hplc-01"}).Allowed)
+	assert.True(t, limiter.AllowRequest("jwt:bob", status, []string{"hplc-01"}).Allowed)
+	decision = limiter.AllowRequest("jwt:carol", status, []string{"hplc-01"})
+	assert.False(t, decision.Allowed, "devices are limited whoever names them")
+	assert.Equal(t, "device", decision.Scope)
+	assert.Equal(t, 30*time.Second, decision.RetryAfter)
+}
+
+func TestLimiter_RejectionTakesNoTokens(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{
+		Principal: Limit{Requests: 1, Window: time.Second},
+		Device:    Limit{Requests: 1, Window: time.Second},
+	})
+	status := "/lab.instrument.v1.LabInstrumentGateway/GetDeviceStatus"
+
+	require.True(t, limiter.AllowRequest("jwt:ada", status, []string{"hplc-01"}).Allowed)
+	require.False(t, limiter.AllowRequest("jwt:bob", status, []string{"hplc-01"}).Allowed)
+
+	assert.True(t, limiter.AllowRequest("jwt:bob", status, []string{"hplc-02"}).Allowed,
+		"a request rejected by the device limit must not use the principal's token")
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{})
+
+	for i := 0; i < 1000; i++ {
+		require.True(t, limiter.AllowRequest("jwt:ada", getMeasurements, []string{"hplc-01"}).Allowed)
+		require.True(t, limiter.AllowMessage("hplc-01").Allowed)
+	}
+	assert.Empty(t, limiter.buckets)
+}
+
+func TestLimiter_AllowMessageDelaysThenRejects(t *testing.T) {
+	limiter, advance := newTestLimiter(Config{
+		StreamMessages: Limit{Requests: 10, Window: time.Second},
+		StreamMaxDelay: 250 * time.Millisecond,
+	})
+
+	for i := 0; i < 10; i++ {
+		decision := limiter.AllowMessage("hplc-01")
+		require.True(t, decision.Allowed)
+		require.Zero(t, decision.RetryAfter)
+	}
+
+	// The next messages wait for tokens to accrue, up to the maximum delay
+	delays := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}
+	for _, want := range delays {
+		decision := limiter.AllowMessage("hplc-01")
+		assert.True(t, decision.Allowed)
+		assert.InDelta(t, float64(want), float64(decision.RetryAfter), float64(time.Microsecond))
+	}
+
+	decision := limiter.AllowMessage("hplc-01")
+	assert.False(t, decision.Allowed)
+	assert.InDelta(t, float64(300*time.Millisecond), float64(decision.RetryAfter), float64(time.Microsecond))
+
+	assert.True(t, limiter.AllowMessage("hplc-02").Allowed, "devices have separate buckets")
+
+	advance(time.Second)
+	decision = limiter.AllowMessage("hplc-01")
+	assert.True(t, decision.Allowed)
+	assert.Zero(t, decision.RetryAfter)
+}
+
+func TestLimiter_EvictIdle(t *testing.T) {
+	limiter, advance := newTestLimiter(Config{
+		Principal:   Limit{Requests: 1, Window: time.Hour},
+		IdleTimeout: time.Minute,
+	})
+	assert.Equal(t, time.Hour, limiter.config.IdleTimeout, "idle timeout is raised to the longest window")
+
+	limiter.AllowRequest("jwt:ada", getMeasurements, nil)
+	advance(30 * time.Minute)
+	limiter.AllowRequest("jwt:bob", getMeasurements, nil)
+	advance(31 * time.Minute)
+
+	assert.Equal(t, 1, limiter.evictIdle())
+	assert.Contains(t, limiter.buckets, "principal:jwt:bob")
+}
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit(" 100/1m ")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 100, Window: time.Minute}, limit)
+	assert.Equal(t, "100/1m0s", limit.String())
+
+	for _, value := range []string{"", "100", "0/1s", "-1/1s", "x/1s", "10/", "10/0s", "10/soon"} {
+		_, err := ParseLimit(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseMethodLimits(t *testing.T) {
+	limits, err := ParseMethodLimits("GetMeasurements=10/1s, ListDevices = 30/1m")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Limit{
+		"GetMeasurements": {Requests: 10, Window: time.Second},
+		"ListDevices":     {Requests: 30, Window: time.Minute},
+	}, limits)
+
+	limits, err = ParseMethodLimits("")
+	require.NoError(t, err)
+	assert.Empty(t, limits)
+
+	for _, value := range []string{"GetMeasurements", "=10/1s", "GetMeasurements=10"} {
+		_, err := ParseMethodLimits(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,304 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// ErrPermissionDenied is returned when the caller lacks a required permission
+var ErrPermissionDenied = errors.New("permission denied")
+
+// DefaultCacheTTL is how long roles and role bindings are cached
+const DefaultCacheTTL = 30 * time.Second
+
+// Config represents the authorization configuration
+type Config struct {
+	Enabled bool
+
+	// CacheTTL bounds how long a role or binding change takes to apply
+	CacheTTL time.Duration
+}
+
+// SetDefaults sets default values for the authorization configuration
+func (c *Config) SetDefaults() {
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = DefaultCacheTTL
+	}
+}
+
+// cachedBindings are the role bindings of one subject
+type cachedBindings struct {
+	bindings []*models.RoleBinding
+	loadedAt time.Time
+}
+
+// Authorizer decides whether a caller may invoke an RPC. Callers receive the
+// roles asserted by their credential, which apply to every device, plus the
+// role bindings stored for their subject, which may be scoped to a device
+// group. Devices authenticated by a device credential hold the built-in
+// device role.
+type Authorizer struct {
+	repos    repository.RepositoryManager
+	cacheTTL time.Duration
+	logger   *logger.Logger
+
+	mutex       sync.Mutex
+	roles       map[string]*models.Role
+	rolesLoaded time.Time
+	bindings    map[string]cachedBindings
+}
+
+// NewAuthorizer creates a new authorizer
+func NewAuthorizer(repos repository.RepositoryManager, config Config, logger *logger.Logger) *Authorizer {
+	config.SetDefaults()
+
+	return &Authorizer{
+		repos:    repos,
+		cacheTTL: config.CacheTTL,
+		logger:   logger,
+		bindings: make(map[string]cachedBindings),
+	}
+}
+
+// Authorize checks that the caller holds the permission required by the RPC
+// for every device the request addresses, and returns the caller grants
+func (a *Authorizer) Authorize(ctx context.Context, identity *auth.Identity, fullMethod string, req interface{}) (*Grants, error) {
+	permission, ok := RequiredPermission(fullMethod)
+	if !ok {
+		return nil, fmt.Errorf("%w: no policy for %s", ErrPermissionDenied, fullMethod)
+	}
+
+	grants, err := a.Grants(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if !grants.AllowsAny(permission) {
+		return nil, fmt.Errorf("%w: %s required", ErrPermissionDenied, permission)
+	}
+
+	// A global grant covers every device
+	if _, all := grants.Groups(permission); all {
+		return grants, nil
+	}
+
+	// A registration may name a device that does not exist yet; only an
+	// existing device's current group needs checking besides the target group
+	deviceIDs, targetGroup, err := a.requestScope(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(deviceIDs) == 0 && targetGroup == nil && !scopedMethods[fullMethod] {
+		return nil, fmt.Errorf("%w: %s on all devices requires a global grant", ErrPermissionDenied, permission)
+	}
+
+	for _, deviceID := range deviceIDs {
+		group, found, err := a.deviceGroup(ctx, deviceID)
+		if err != nil {
+			return nil, err
+		}
+		if !found && targetGroup != nil {
+			continue
+		}
+		if !grants.Allows(permission, group) {
+			return nil, fmt.Errorf("%w: %s required for device %s", ErrPermissionDenied, permission, deviceID)
+		}
+	}
+
+	if targetGroup != nil && !grants.Allows(permission, *targetGroup) {
+		return nil, fmt.Errorf("%w: %s required in device group %q", ErrPermissionDenied, permission, *targetGroup)
+	}
+
+	return grants, nil
+}
+
+// AuthorizeDevice checks that the grants include the permission for the
+// group of a device. Unknown devices are treated as ungrouped.
+func (a *Authorizer) AuthorizeDevice(ctx context.Context, grants *Grants, permission models.Permission, deviceID string) error {
+	if _, all := grants.Groups(permission); all {
+		return nil
+	}
+
+	group, _, err := a.deviceGroup(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+
+	if !grants.Allows(permission, group) {
+		return fmt.Errorf("%w: %s required for device %s", ErrPermissionDenied, permission, deviceID)
+	}
+
+	return nil
+}
+
+// requestScope returns the devices a request acts on and its target group.
+// Expiring a silence acts on the silence's device, so a scoped caller cannot
+// expire silences outside its groups; unknown silences name no device.
+func (a *Authorizer) requestScope(ctx context.Context, req interface{}) ([]string, *string, error) {
+	r, ok := req.(*pb.ExpireSilenceRequest)
+	if !ok {
+		deviceIDs, targetGroup := requestScope(req)
+		return deviceIDs, targetGroup, nil
+	}
+
+	silence, err := a.repos.Silence().GetByID(ctx, r.GetSilenceId())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to resolve silence device: %w", err)
+	}
+	if silence.DeviceID == nil {
+		return nil, nil, nil
+	}
+
+	return []string{*silence.DeviceID}, nil, nil
+}
+
+// deviceGroup returns the group of a device and whether the device exists
+func (a *Authorizer) deviceGroup(ctx context.Context, deviceID string) (string, bool, error) {
+	device, err := a.repos.Device().GetByID(ctx, deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to resolve device group: %w", err)
+	}
+
+	return device.Group(), true, nil
+}
+
+// Grants returns the effective permissions of a caller
+func (a *Authorizer) Grants(ctx context.Context, identity *auth.Identity) (*Grants, error) {
+	roles, err := a.loadRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty subject would list the bindings of every subject. API keys
+	// carry their own scope, which bindings must not widen.
+	var bindings []*models.RoleBinding
+	if identity.Subject != "" && identity.Method != auth.MethodAPIKey {
+		bindings, err = a.loadBindings(ctx, identity.Subject)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	grants := newGrants()
+
+	// Devices hold the device role on all devices; the device identity
+	// middleware limits them to the devices they authenticated as
+	if identity.IsDevice() {
+		if role, ok := roles[models.RoleDevice]; ok {
+			grants.add(role, nil)
+		}
+	}
+
+	for _, name := range identity.Roles {
+		role, ok := roles[name]
+		if !ok {
+			a.logger.WithFields(map[string]interface{}{
+				"subject": identity.Subject,
+				"role":    name,
+			}).Debug("Ignoring unknown role asserted by credential")
+			continue
+		}
+
+		if len(identity.DeviceGroups) == 0 {
+			grants.add(role, nil)
+			continue
+		}
+		for _, group := range identity.DeviceGroups {
+			grants.add(role, &group)
+		}
+	}
+
+	for _, binding := range bindings {
+		if role, ok := roles[binding.Role]; ok {
+			grants.add(role, binding.DeviceGroup)
+		}
+	}
+
+	return grants, nil
+}
+
+// Invalidate drops cached roles and bindings so that changes apply at once
+func (a *Authorizer) Invalidate() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.roles = nil
+	a.bindings = make(map[string]cachedBindings)
+}
+
+// loadRoles returns all roles by name, reloading them when the cache expires
+func (a *Authorizer) loadRoles(ctx context.Context) (map[string]*models.Role, error) {
+	a.mutex.Lock()
+	if a.roles != nil && time.Since(a.rolesLoaded) < a.cacheTTL {
+		roles := a.roles
+		a.mutex.Unlock()
+		return roles, nil
+	}
+	a.mutex.Unlock()
+
+	list, err := a.repos.Role().ListRoles(ctx)
+	if err != nil {
+		a.logger.WithError(err).Error("Failed to load roles")
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	roles := make(map[string]*models.Role, len(list))
+	for _, role := range list {
+		roles[role.Name] = role
+	}
+
+	a.mutex.Lock()
+	a.roles = roles
+	a.rolesLoaded = time.Now()
+	a.mutex.Unlock()
+
+	return roles, nil
+}
+
+// loadBindings returns the role bindings of a subject, reloading them when
+// the cache expires
+func (a *Authorizer) loadBindings(ctx context.Context, subject string) ([]*models.RoleBinding, error) {
+	a.mutex.Lock()
+	cached, ok := a.bindings[subject]
+	a.mutex.Unlock()
+	if ok && time.Since(cached.loadedAt) < a.cacheTTL {
+		return cached.bindings, nil
+	}
+
+	bindings, err := a.repos.Role().ListBindings(ctx, subject)
+	if err != nil {
+		a.logger.WithField("subject", subject).WithError(err).Error("Failed to load role bindings")
+		return nil, fmt.Errorf("failed to load role bindings: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.pruneBindings()
+	a.bindings[subject] = cachedBindings{bindings: bindings, loadedAt: time.Now()}
+	a.mutex.Unlock()
+
+	return bindings, nil
+}
+
+// pruneBindings drops expired cache entries. Must be called with the mutex held.
+func (a *Authorizer) pruneBindings() {
+	for subject, cached := range a.bindings {
+		if time.Since(cached.loadedAt) >= a.cacheTTL {
+			delete(a.bindings, subject)
+		}
+	}
+}
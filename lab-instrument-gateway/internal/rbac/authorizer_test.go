@@ -0,0 +1,214 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/internal/auth"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// newTestAuthorizer returns an authorizer over an in-memory repository
+// manager holding the built-in roles, the given bindings, and a device in
+// lab A, one in lab B and an ungrouped one
+func newTestAuthorizer(t *testing.T, bindings ...*models.RoleBinding) (*Authorizer, repository.RepositoryManager) {
+	ctx := context.Background()
+	repos := memory.NewRepositoryManager()
+
+	for id, deviceGroup := range map[string]string{"hplc-a": "lab-a", "hplc-b": "lab-b", "shared": ""} {
+		device := &models.Device{ID: id, Name: id, Type: "analyzer", Status: models.DeviceStatusOnline, Metadata: map[string]interface{}{}}
+		if deviceGroup != "" {
+			device.Metadata[models.DeviceGroupMetadataKey] = deviceGroup
+		}
+		require.NoError(t, repos.Device().Create(ctx, device))
+	}
+	for _, binding := range bindings {
+		require.NoError(t, repos.Role().CreateBinding(ctx, binding))
+	}
+
+	return NewAuthorizer(repos, Config{Enabled: true}, logger.NewDefaultLogger()), repos
+}
+
+func group(name string) *string { return &name }
+
+func TestAuthorizer_TokenRolesApplyToAllDevices(t *testing.T) {
+	authorizer, _ := newTestAuthorizer(t)
+	ctx := context.Background()
+
+	viewer := &auth.Identity{Subject: "vera", Method: auth.MethodJWT, Roles: []string{models.RoleViewer}}
+	_, err := authorizer.Authorize(ctx, viewer, pb.LabInstrumentGateway_GetDeviceStatus_FullMethodName, &pb.GetDeviceStatusRequest{DeviceId: "hplc-b"})
+	assert.NoError(t, err)
+
+	_, err = authorizer.Authorize(ctx, viewer, pb.LabInstrumentGateway_SendCommand_FullMethodName, &pb.SendCommandRequest{DeviceId: "hplc-b"})
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+
+	operator := &auth.Identity{Subject: "otto", Method: auth.MethodJWT, Roles: []string{models.RoleOperator, "unknown"}}
+	_, err = authorizer.Authorize(ctx, operator, pb.LabInstrumentGateway_SendCommand_FullMethodName, &pb.SendCommandRequest{DeviceId: "shared"})
+	assert.NoError(t, err)
+
+	_, err = authorizer.Authorize(ctx, operator, "/lab_gateway.LabInstrumentGateway/Unlisted", nil)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestAuthorizer_APIKeyScopedToDeviceGroups(t *testing.T) {
+	// A binding for the key's subject must not widen the key's own scope
+	authorizer, _ := newTestAuthorizer(t, &models.RoleBinding{Subject: "lims", Role: models.RoleAdmin})
+	ctx := context.Background()
+	key := &auth.Identity{
+		Subject:      "lims",
+		Method:       auth.MethodAPIKey,
+		Roles:        []string{models.RoleViewer},
+		DeviceGroups: []string{"lab-a"},
+	}
+
+	status := func(deviceID string) error {
+		_, err := authorizer.Authorize(ctx, key, pb.LabInstrumentGateway_GetDeviceStatus_FullMethodName, &pb.GetDeviceStatusRequest{DeviceId: deviceID})
+		return err
+	}
+	assert.NoError(t, status("hplc-a"))
+	assert.ErrorIs(t, status("hplc-b"), ErrPermissionDenied)
+	assert.ErrorIs(t, status("shared"), ErrPermissionDenied)
+
+	_, err := authorizer.Authorize(ctx, key, pb.LabInstrumentGateway_SendCommand_FullMethodName, &pb.SendCommandRequest{DeviceId: "hplc-a"})
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+
+	key.DeviceGroups = nil
+	assert.NoError(t, status("shared"), "keys without groups apply to all devices")
+}
+
+func TestAuthorizer_GroupScopedBindings(t *testing.T) {
+	authorizer, _ := newTestAuthorizer(t,
+		&models.RoleBinding{Subject: "alice", Role: models.RoleOperator, DeviceGroup: group("lab-a")},
+		&models.RoleBinding{Subject: "alice", Role: models.RoleViewer, DeviceGroup: group("lab-b")},
+	)
+	ctx := context.Background()
+	alice := &auth.Identity{Subject: "alice", Method: auth.MethodJWT}
+
+	send := func(deviceID string) error {
+		_, err := authorizer.Authorize(ctx, alice, pb.LabInstrumentGateway_SendCommand_FullMethodName, &pb.SendCommandRequest{DeviceId: deviceID})
+		return err
+	}
+	assert.NoError(t, send("hplc-a"))
+	assert.ErrorIs(t, send("hplc-b"), ErrPermissionDenied, "operator in lab A cannot command lab B")
+	assert.ErrorIs(t, send("shared"), ErrPermissionDenied, "ungrouped devices need a global grant")
+	assert.ErrorIs(t, send("missing"), ErrPermissionDenied)
+
+	grants, err := authorizer.Authorize(ctx, alice, pb.LabInstrumentGateway_ListDevices_FullMethodName, &pb.ListDevicesRequest{})
+	require.NoError(t, err)
+	groups, all := grants.Groups(models.PermissionDevicesRead)
+	assert.False(t, all)
+	assert.Equal(t, []string{"lab-a", "lab-b"}, groups)
+
+	_, err = authorizer.Authorize(ctx, &auth.Identity{Subject: "bob", Method: auth.MethodJWT}, pb.LabInstrumentGateway_ListDevices_FullMethodName, &pb.ListDevicesRequest{})
+	assert.ErrorIs(t, err, ErrPermissionDenied, "no roles at all")
+}
+
+func TestAuthorizer_GroupScopedCallersNeedADevice(t *testing.T) {
+	authorizer, repos := newTestAuthorizer(t,
+		&models.RoleBinding{Subject: "alice", Role: models.RoleAdmin, DeviceGroup: group("lab-a")},
+	)
+	ctx := context.Background()
+	alice := &auth.Identity{Subject: "alice", Method: auth.MethodJWT}
+
+	authorize := func(fullMethod string, req interface{}) error {
+		_, err := authorizer.Authorize(ctx, alice, fullMethod, req)
+		return err
+	}
+
+	// Requests naming no device would reach every lab's devices
+	assert.NoError(t, authorize(pb.LabInstrumentGateway_GetMeasurements_FullMethodName, &pb.GetMeasurementsRequest{DeviceId: "hplc-a"}))
+	assert.ErrorIs(t, authorize(pb.LabInstrumentGateway_GetMeasurements_FullMethodName, &pb.GetMeasurementsRequest{}), ErrPermissionDenied)
+	assert.NoError(t, authorize(pb.LabInstrumentGateway_ListSilences_FullMethodName, &pb.ListSilencesRequest{DeviceId: "hplc-a"}))
+	assert.ErrorIs(t, authorize(pb.LabInstrumentGateway_ListSilences_FullMethodName, &pb.ListSilencesRequest{}), ErrPermissionDenied)
+	assert.ErrorIs(t, authorize(pb.LabInstrumentGateway_CreateSilence_FullMethodName, &pb.CreateSilenceRequest{
+		Silence: &pb.Silence{AlertType: string(models.AlertTypeDeviceOffline)},
+	}), ErrPermissionDenied)
+
+	// Listings filtered by the handler to the caller's groups are allowed
+	assert.NoError(t, authorize(pb.LabInstrumentGateway_ListDevices_FullMethodName, &pb.ListDevicesRequest{}))
+
+	// Expiring a silence acts on the silence's device
+	expire := func(deviceID *string) error {
+		now := time.Now()
+		silence := &models.Silence{
+			DeviceID:  deviceID,
+			AlertType: &[]models.AlertType{models.AlertTypeDeviceOffline}[0],
+			StartsAt:  now,
+			EndsAt:    now.Add(time.Hour),
+			CreatedBy: "ops",
+		}
+		require.NoError(t, repos.Silence().Create(ctx, silence))
+		return authorize(pb.LabInstrumentGateway_ExpireSilence_FullMethodName, &pb.ExpireSilenceRequest{SilenceId: silence.ID})
+	}
+	assert.NoError(t, expire(group("hplc-a")))
+	assert.ErrorIs(t, expire(group("hplc-b")), ErrPermissionDenied)
+	assert.ErrorIs(t, expire(nil), ErrPermissionDenied, "silences for all devices need a global grant")
+}
+
+func TestAuthorizer_Registration(t *testing.T) {
+	authorizer, _ := newTestAuthorizer(t,
+		&models.RoleBinding{Subject: "ada", Role: models.RoleAdmin, DeviceGroup: group("lab-a")},
+	)
+	ctx := context.Background()
+	ada := &auth.Identity{Subject: "ada", Method: auth.MethodJWT}
+
+	register := func(deviceID, deviceGroup string) error {
+		req := &pb.RegisterDeviceRequest{DeviceId: deviceID, Metadata: map[string]string{}}
+		if deviceGroup != "" {
+			req.Metadata[models.DeviceGroupMetadataKey] = deviceGroup
+		}
+		_, err := authorizer.Authorize(ctx, ada, pb.LabInstrumentGateway_RegisterDevice_FullMethodName, req)
+		return err
+	}
+	assert.NoError(t, register("new-device", "lab-a"))
+	assert.ErrorIs(t, register("new-device", "lab-b"), ErrPermissionDenied)
+	assert.ErrorIs(t, register("new-device", ""), ErrPermissionDenied)
+	assert.ErrorIs(t, register("hplc-b", "lab-a"), ErrPermissionDenied, "cannot move another lab's device")
+
+	device := &auth.Identity{Subject: "hplc-b", Method: auth.MethodCertificate, DeviceIDs: []string{"hplc-b"}}
+	_, err := authorizer.Authorize(ctx, device, pb.LabInstrumentGateway_StreamData_FullMethodName, nil)
+	assert.NoError(t, err, "certificates hold the device role")
+}
+
+func TestAuthorizer_CachesRoles(t *testing.T) {
+	authorizer, repos := newTestAuthorizer(t)
+	ctx := context.Background()
+	identity := &auth.Identity{Subject: "vera", Method: auth.MethodJWT, Roles: []string{models.RoleViewer}}
+
+	grants, err := authorizer.Grants(ctx, identity)
+	require.NoError(t, err)
+	assert.False(t, grants.Allows(models.PermissionCommandsSend, ""))
+
+	viewer, err := repos.Role().GetRole(ctx, models.RoleViewer)
+	require.NoError(t, err)
+	viewer.Permissions = append(viewer.Permissions, models.PermissionCommandsSend)
+	require.NoError(t, repos.Role().UpdateRole(ctx, viewer))
+
+	grants, err = authorizer.Grants(ctx, identity)
+	require.NoError(t, err)
+	assert.False(t, grants.Allows(models.PermissionCommandsSend, ""), "roles are served from the cache")
+
+	authorizer.Invalidate()
+	grants, err = authorizer.Grants(ctx, identity)
+	require.NoError(t, err)
+	assert.True(t, grants.Allows(models.PermissionCommandsSend, ""))
+}
+
+func TestGrantsContext(t *testing.T) {
+	_, ok := GrantsFromContext(context.Background())
+	assert.False(t, ok)
+
+	grants := newGrants()
+	grants.add(&models.Role{Permissions: []models.Permission{models.PermissionDevicesRead}}, nil)
+	stored, ok := GrantsFromContext(WithGrants(context.Background(), grants))
+	require.True(t, ok)
+	assert.True(t, stored.Allows(models.PermissionDevicesRead, ""))
+}
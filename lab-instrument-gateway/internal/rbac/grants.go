@@ -0,0 +1,81 @@
+package rbac
+
+import (
+	"context"
+	"slices"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// Grants are the effective permissions of a caller. A global permission
+// applies to every device; a group permission only to devices in that group.
+type Grants struct {
+	global map[models.Permission]bool
+	groups map[models.Permission]map[string]bool
+}
+
+// newGrants creates an empty set of grants
+func newGrants() *Grants {
+	return &Grants{
+		global: make(map[models.Permission]bool),
+		groups: make(map[models.Permission]map[string]bool),
+	}
+}
+
+// add grants the permissions of a role, globally if group is nil
+func (g *Grants) add(role *models.Role, group *string) {
+	for _, permission := range role.Permissions {
+		if group == nil {
+			g.global[permission] = true
+			continue
+		}
+		if g.groups[permission] == nil {
+			g.groups[permission] = make(map[string]bool)
+		}
+		g.groups[permission][*group] = true
+	}
+}
+
+// Allows returns true if the permission is granted for devices in the group.
+// Devices without a group ("") are only accessible with a global grant.
+func (g *Grants) Allows(permission models.Permission, group string) bool {
+	if g.global[permission] {
+		return true
+	}
+	return group != "" && g.groups[permission][group]
+}
+
+// AllowsAny returns true if the permission is granted for at least one group
+func (g *Grants) AllowsAny(permission models.Permission) bool {
+	return g.global[permission] || len(g.groups[permission]) > 0
+}
+
+// Groups returns the device groups the permission is granted for, and true
+// instead if it is granted globally
+func (g *Grants) Groups(permission models.Permission) ([]string, bool) {
+	if g.global[permission] {
+		return nil, true
+	}
+
+	groups := make([]string, 0, len(g.groups[permission]))
+	for group := range g.groups[permission] {
+		groups = append(groups, group)
+	}
+	slices.Sort(groups)
+
+	return groups, false
+}
+
+type grantsKey struct{}
+
+// WithGrants returns a context carrying the caller grants
+func WithGrants(ctx context.Context, grants *Grants) context.Context {
+	return context.WithValue(ctx, grantsKey{}, grants)
+}
+
+// GrantsFromContext returns the caller grants stored in the context. There
+// are none when authorization is disabled.
+func GrantsFromContext(ctx context.Context) (*Grants, bool) {
+	grants, ok := ctx.Value(grantsKey{}).(*Grants)
+	return grants, ok && grants != nil
+}
@@ -0,0 +1,76 @@
+package rbac
+
+import (
+	"github.com/yourorg/lab-gateway/pkg/models"
+	pb "github.com/yourorg/lab-gateway/proto"
+)
+
+// methodPermissions maps each RPC, by full method name, to the permission it
+// requires. RPCs that are not listed are denied to every caller.
+var methodPermissions = map[string]models.Permission{
+	pb.LabInstrumentGateway_RegisterDevice_FullMethodName:        models.PermissionDevicesWrite,
+	pb.LabInstrumentGateway_GetDeviceStatus_FullMethodName:       models.PermissionDevicesRead,
+	pb.LabInstrumentGateway_ListDevices_FullMethodName:           models.PermissionDevicesRead,
+	pb.LabInstrumentGateway_GetDeviceHistory_FullMethodName:      models.PermissionDevicesRead,
+	pb.LabInstrumentGateway_StreamData_FullMethodName:            models.PermissionMeasurementsWrite,
+	pb.LabInstrumentGateway_SendCommand_FullMethodName:           models.PermissionCommandsSend,
+	pb.LabInstrumentGateway_GetMeasurements_FullMethodName:       models.PermissionMeasurementsRead,
+	pb.LabInstrumentGateway_CreateSilence_FullMethodName:         models.PermissionAlertsManage,
+	pb.LabInstrumentGateway_ListSilences_FullMethodName:          models.PermissionAlertsRead,
+	pb.LabInstrumentGateway_ExpireSilence_FullMethodName:         models.PermissionAlertsManage,
+	pb.LabInstrumentGateway_CreateEnrollmentToken_FullMethodName: models.PermissionAccessManage,
+	pb.LabInstrumentGateway_CreateAPIKey_FullMethodName:          models.PermissionAccessManage,
+	pb.LabInstrumentGateway_ListAPIKeys_FullMethodName:           models.PermissionAccessManage,
+	pb.LabInstrumentGateway_RevokeAPIKey_FullMethodName:          models.PermissionAccessManage,
+	pb.LabInstrumentGateway_QueryAuditLog_FullMethodName:         models.PermissionAuditRead,
+}
+
+// scopedMethods lists the RPCs that may act on no particular device for
+// callers whose grants are limited to device groups: their handlers limit
+// results to the caller's groups, and streams check the device of each
+// message. Other requests naming no device need a global grant.
+var scopedMethods = map[string]bool{
+	pb.LabInstrumentGateway_ListDevices_FullMethodName:   true,
+	pb.LabInstrumentGateway_StreamData_FullMethodName:    true,
+	pb.LabInstrumentGateway_CreateAPIKey_FullMethodName:  true,
+	pb.LabInstrumentGateway_ListAPIKeys_FullMethodName:   true,
+	pb.LabInstrumentGateway_RevokeAPIKey_FullMethodName:  true,
+	pb.LabInstrumentGateway_QueryAuditLog_FullMethodName: true,
+}
+
+// RequiredPermission returns the permission needed to call an RPC
+func RequiredPermission(fullMethod string) (models.Permission, bool) {
+	permission, ok := methodPermissions[fullMethod]
+	return permission, ok
+}
+
+// deviceRequest is implemented by requests that address a single device
+type deviceRequest interface {
+	GetDeviceId() string
+}
+
+// requestScope returns the devices a request acts on and, for registrations
+// and enrollment tokens, the device group the device is being placed in
+func requestScope(req interface{}) (deviceIDs []string, targetGroup *string) {
+	switch r := req.(type) {
+	case *pb.RegisterDeviceRequest:
+		group := r.GetMetadata()[models.DeviceGroupMetadataKey]
+		return []string{r.GetDeviceId()}, &group
+
+	case *pb.CreateEnrollmentTokenRequest:
+		group := r.GetDeviceGroup()
+		return nil, &group
+
+	case *pb.CreateSilenceRequest:
+		if deviceID := r.GetSilence().GetDeviceId(); deviceID != "" {
+			return []string{deviceID}, nil
+		}
+
+	case deviceRequest:
+		if deviceID := r.GetDeviceId(); deviceID != "" {
+			return []string{deviceID}, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// AdminConfig represents the admin HTTP server configuration
+type AdminConfig struct {
+	// Enabled serves the admin endpoints, such as the /livez and /readyz
+	// probes, on Port
+	Enabled bool
+	Port    int
+
+	// Metrics exposes Prometheus metrics at MetricsPath
+	Metrics     bool
+	MetricsPath string
+
+	// MetricsInterval is how often device and connection gauges are refreshed
+	MetricsInterval time.Duration
+}
+
+// SetDefaults sets default values for the admin configuration
+func (c *AdminConfig) SetDefaults() {
+	if c.MetricsPath == "" {
+		c.MetricsPath = "/metrics"
+	}
+	if c.MetricsInterval <= 0 {
+		c.MetricsInterval = 15 * time.Second
+	}
+}
+
+// AdminServer serves operational HTTP endpoints, such as metrics, next to
+// the gRPC server
+type AdminServer struct {
+	server   *http.Server
+	mux      *http.ServeMux
+	listener net.Listener
+	port     int
+	logger   *logger.Logger
+}
+
+// NewAdminServer creates an admin server, exposing Prometheus metrics if
+// enabled
+func NewAdminServer(config AdminConfig, logger *logger.Logger) *AdminServer {
+	config.SetDefaults()
+
+	mux := http.NewServeMux()
+	if config.Metrics {
+		mux.Handle(config.MetricsPath, promhttp.Handler())
+	}
+
+	return &AdminServer{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		mux:    mux,
+		port:   config.Port,
+		logger: logger,
+	}
+}
+
+// Handle registers an additional admin endpoint; it must be called before Start
+func (s *AdminServer) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start starts serving the admin endpoints
+func (s *AdminServer) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on admin port %d: %w", s.port, err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.WithError(err).Error("Admin server failed")
+		}
+	}()
+
+	s.logger.WithField("address", listener.Addr().String()).Info("Admin server started")
+	return nil
+}
+
+// Addr returns the address the admin server listens on
+func (s *AdminServer) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop shuts the admin server down, waiting for open requests until ctx is done
+func (s *AdminServer) Stop(ctx context.Context) error {
+	if s.listener == nil {
+		return nil
+	}
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop admin server: %w", err)
+	}
+
+	s.logger.Info("Admin server stopped")
+	return nil
+}
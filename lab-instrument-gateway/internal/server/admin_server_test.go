@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository/memory"
+)
+
+func scrape(t *testing.T, url string) string {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestAdminServer_Metrics(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	admin := NewAdminServer(AdminConfig{Enabled: true, Metrics: true}, log)
+	require.NoError(t, admin.Start())
+	defer admin.Stop(context.Background())
+
+	connections := device.NewConnectionManager(device.Config{}, log)
+	defer connections.Close()
+
+	repos := memory.NewRepositoryManager()
+	for i, status := range []models.DeviceStatus{
+		models.DeviceStatusOnline,
+		models.DeviceStatusOnline,
+		models.DeviceStatusOnline,
+		models.DeviceStatusOffline,
+	} {
+		require.NoError(t, repos.Device().Create(context.Background(), &models.Device{
+			ID:     fmt.Sprintf("hplc-%d", i),
+			Name:   fmt.Sprintf("HPLC %d", i),
+			Type:   "analyzer",
+			Status: status,
+		}))
+	}
+	publisher := newMetricsPublisher(repos, connections, time.Hour, log)
+	publisher.dbStats = func() db.ConnectionStats {
+		return db.ConnectionStats{InUseConnections: 4, IdleConnections: 2}
+	}
+	publisher.start()
+	publisher.close()
+
+	body := scrape(t, "http://"+admin.Addr()+"/metrics")
+	assert.Contains(t, body, `device_status_total{status="online"} 3`)
+	assert.Contains(t, body, `device_status_total{status="offline"} 1`)
+	assert.Contains(t, body, `device_status_total{status="maintenance"} 0`)
+	assert.Contains(t, body, "device_connections_active 0")
+	assert.Contains(t, body, "database_connections_active 4")
+	assert.Contains(t, body, "database_connections_idle 2")
+	assert.Contains(t, body, "grpc_active_connections")
+}
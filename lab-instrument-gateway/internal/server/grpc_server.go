@@ -6,13 +6,28 @@ import (
 	"net"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/yourorg/lab-gateway/internal/alerting"
+	"github.com/yourorg/lab-gateway/internal/audit"
+	"github.com/yourorg/lab-gateway/internal/auth"
 	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/internal/esign"
 	"github.com/yourorg/lab-gateway/internal/handlers"
+	"github.com/yourorg/lab-gateway/internal/health"
+	"github.com/yourorg/lab-gateway/internal/ingest"
 	"github.com/yourorg/lab-gateway/internal/middleware"
+	"github.com/yourorg/lab-gateway/internal/provisioning"
+	"github.com/yourorg/lab-gateway/internal/ratelimit"
+	"github.com/yourorg/lab-gateway/internal/rbac"
+	"github.com/yourorg/lab-gateway/internal/tlsutil"
+	"github.com/yourorg/lab-gateway/pkg/db"
 	"github.com/yourorg/lab-gateway/pkg/logger"
 	"github.com/yourorg/lab-gateway/pkg/repository"
 	pb "github.com/yourorg/lab-gateway/proto"
@@ -24,17 +39,90 @@ type GRPCServer struct {
 	listener          net.Listener
 	repos             repository.RepositoryManager
 	connectionManager *device.ConnectionManager
+	statusTracker     *device.StatusTracker
+	alertManager      *alerting.Manager
+	escalator         *alerting.Escalator
+	ingester          *ingest.Ingester
+	dispatcher        *device.Dispatcher
+	registry          device.Registry
+	certReloader      *tlsutil.CertReloader
+	authenticator     *auth.Authenticator
+	authorizer        *rbac.Authorizer
+	limiter           *ratelimit.Limiter
+	auditRecorder     *audit.Recorder
+	adminServer       *AdminServer
+	metrics           *metricsPublisher
+	health            *health.Checker
 	logger            *logger.Logger
 	
 	// Handlers
 	deviceHandler       *handlers.DeviceHandler
 	deviceStatusHandler *handlers.DeviceStatusHandler
 	deviceListHandler   *handlers.DeviceListHandler
+	deviceHistoryHandler *handlers.DeviceHistoryHandler
+	silenceHandler      *handlers.SilenceHandler
+	streamHandler       *handlers.StreamHandler
+	provisioningHandler *handlers.ProvisioningHandler
+	apiKeyHandler       *handlers.APIKeyHandler
+	auditHandler        *handlers.AuditHandler
+	commandHandler      *handlers.CommandHandler
 	
 	// Configuration
 	port           int
 	maxMessageSize int
 	maxConcurrent  int
+	tlsClientAuth  tlsutil.ClientAuthMode
+	tlsReload      time.Duration
+	auditFailClosed bool
+}
+
+// TLSConfig represents the transport security configuration
+type TLSConfig struct {
+	Enabled  bool
+	CertFile string
+	KeyFile  string
+	
+	// CAFile is the CA bundle client certificates are verified against.
+	// ClientAuth is none, request or require, defaulting to require when a
+	// CA is configured.
+	CAFile     string
+	ClientAuth string
+	
+	// ReloadInterval is how often the files are checked for rotation
+	ReloadInterval time.Duration
+}
+
+// Connection registry backends
+const (
+	RegistryLocal = "local"
+	RegistryRedis = "redis"
+)
+
+// RegistryConfig represents the connection registry configuration
+type RegistryConfig struct {
+	// Backend is local, where each replica only knows the devices connected
+	// to it, or redis, where replicas share their device connections and
+	// forward commands to the replica holding the device stream
+	Backend string
+	
+	// ReplicaID identifies this replica in the registry, defaulting to the
+	// host name
+	ReplicaID string
+	
+	Redis device.RedisRegistryConfig
+}
+
+// AuthConfig represents the caller authentication configuration
+type AuthConfig struct {
+	// Enabled rejects calls without valid credentials, except health checks
+	Enabled bool
+	
+	// JWT configures bearer token verification. Verified client certificates
+	// are accepted as well when TLS client authentication is enabled.
+	JWT auth.JWTConfig
+	
+	// RBAC configures role-based authorization of authenticated callers
+	RBAC rbac.Config
 }
 
 // Config represents the gRPC server configuration
@@ -42,17 +130,157 @@ type Config struct {
 	Port           int
 	MaxMessageSize int // in bytes
 	MaxConcurrent  int // max concurrent streams
+	
+	// EscalationInterval is how often unacknowledged alerts are checked for escalation
+	EscalationInterval time.Duration
+	
+	// HeartbeatTimeout is how long a device may go without a heartbeat before
+	// it is marked offline; HeartbeatCheckInterval is how often this is checked
+	HeartbeatTimeout       time.Duration
+	HeartbeatCheckInterval time.Duration
+	
+	// Ingest configures measurement batching and anomaly detection
+	Ingest ingest.Config
+	
+	// TLS configures TLS and client certificate authentication
+	TLS TLSConfig
+	
+	// Auth configures caller authentication
+	Auth AuthConfig
+	
+	// AuditFailClosed fails calls to audited RPCs that succeeded but could
+	// not be recorded in the audit trail, instead of only logging the failure
+	AuditFailClosed bool
+	
+	// Provisioning configures device enrollment and credential issuance
+	Provisioning provisioning.Config
+	
+	// RateLimit configures request and stream message rate limits
+	RateLimit ratelimit.Config
+	
+	// Signatures configures the electronic signatures critical commands need
+	Signatures esign.Config
+	
+	// Admin configures the admin HTTP server exposing metrics and probes
+	Admin AdminConfig
+	
+	// Health configures the subsystem checks behind the gRPC health service
+	// and the readiness probe
+	Health health.Config
+	
+	// Registry configures how device connections are shared between replicas
+	Registry RegistryConfig
 }
 
 // NewGRPCServer creates a new gRPC server
 func NewGRPCServer(config Config, repos repository.RepositoryManager, logger *logger.Logger) (*GRPCServer, error) {
-	// Create connection manager
-	connectionManager := device.NewConnectionManager(logger)
+	// Load TLS certificates up front so a misconfiguration fails fast
+	var certReloader *tlsutil.CertReloader
+	var clientAuth tlsutil.ClientAuthMode
+	if config.TLS.Enabled {
+		var err error
+		clientAuth, err = tlsutil.ParseClientAuthMode(config.TLS.ClientAuth, config.TLS.CAFile != "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+		certReloader, err = tlsutil.NewCertReloader(config.TLS.CertFile, config.TLS.KeyFile, config.TLS.CAFile, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+		}
+	}
+	
+	// Set up caller authentication
+	var authenticator *auth.Authenticator
+	if config.Auth.Enabled {
+		var verifier *auth.JWTVerifier
+		if config.Auth.JWT.Secret != "" || config.Auth.JWT.JWKSFile != "" {
+			var err error
+			verifier, err = auth.NewJWTVerifier(config.Auth.JWT)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT configuration: %w", err)
+			}
+		}
+		trustCertificates := certReloader != nil && clientAuth != tlsutil.ClientAuthNone
+		if verifier == nil && !trustCertificates {
+			logger.Warn("Authentication is enabled without JWT keys or a client CA; only API keys are accepted")
+		}
+		authenticator = auth.NewAuthenticator(verifier, auth.NewAPIKeyVerifier(repos.APIKey(), logger), trustCertificates)
+	}
+	
+	var authorizer *rbac.Authorizer
+	if config.Auth.Enabled && config.Auth.RBAC.Enabled {
+		authorizer = rbac.NewAuthorizer(repos, config.Auth.RBAC, logger)
+	}
+	
+	var limiter *ratelimit.Limiter
+	if config.RateLimit.Enabled {
+		limiter = ratelimit.NewLimiter(config.RateLimit, logger)
+	}
+	
+	// Create alert manager
+	notifier := alerting.NewLogNotifier(logger)
+	alertManager := alerting.NewManager(repos, notifier, logger)
+	escalator := alerting.NewEscalator(repos, notifier, config.EscalationInterval, logger)
+	
+	// Share device connections with the other replicas if configured
+	var registry device.Registry
+	switch config.Registry.Backend {
+	case "", RegistryLocal:
+	case RegistryRedis:
+		registry = device.NewRedisRegistry(config.Registry.Redis)
+	default:
+		return nil, fmt.Errorf("unknown connection registry %q", config.Registry.Backend)
+	}
+	if config.Registry.ReplicaID == "" {
+		config.Registry.ReplicaID = device.DefaultReplicaID()
+	}
+	
+	// Create connection manager, persisting status transitions and raising offline alerts
+	if config.HeartbeatTimeout == 0 {
+		config.HeartbeatTimeout = device.DefaultHeartbeatTimeout
+	}
+	statusTracker := device.NewStatusTracker(repos, alertManager, config.HeartbeatTimeout, logger)
+	connectionManager := device.NewConnectionManager(device.Config{
+		HeartbeatTimeout: config.HeartbeatTimeout,
+		CleanupInterval:  config.HeartbeatCheckInterval,
+		Observer:         statusTracker,
+		Registry:         registry,
+		ReplicaID:        config.Registry.ReplicaID,
+	}, logger)
+	
+	// Create measurement ingester
+	ingester := ingest.NewIngester(config.Ingest, repos, alertManager, logger)
+	
+	// Create provisioning service, loading the local CA if configured
+	provisioningService, err := provisioning.NewService(config.Provisioning, repos, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provisioning configuration: %w", err)
+	}
 	
 	// Create handlers
 	deviceHandler := handlers.NewDeviceHandler(repos, connectionManager, logger)
+	if config.Auth.Enabled {
+		deviceHandler.RequireCredentials(alertManager)
+	}
 	deviceStatusHandler := handlers.NewDeviceStatusHandler(repos, connectionManager, logger)
 	deviceListHandler := handlers.NewDeviceListHandler(repos, logger)
+	deviceHistoryHandler := handlers.NewDeviceHistoryHandler(repos, logger)
+	silenceHandler := handlers.NewSilenceHandler(repos, logger)
+	dispatcher := device.NewDispatcher(repos.Command(), logger)
+	dispatcher.OnCalibrate(ingester.ResetCalibration)
+	if registry != nil {
+		dispatcher.ForwardCommands(registry, config.Registry.ReplicaID)
+	}
+	streamHandler := handlers.NewStreamHandler(connectionManager, ingester, logger)
+	if limiter != nil {
+		streamHandler.LimitMessages(limiter)
+	}
+	streamHandler.DeliverCommands(dispatcher)
+	provisioningHandler := handlers.NewProvisioningHandler(provisioningService, logger)
+	apiKeyHandler := handlers.NewAPIKeyHandler(repos, logger)
+	auditHandler := handlers.NewAuditHandler(repos, logger)
+	signatures := esign.NewService(config.Signatures, repos.Command(), authenticator, authorizer, logger)
+	commandHandler := handlers.NewCommandHandler(repos, signatures, dispatcher, logger)
 	
 	// Set default configuration values
 	if config.Port == 0 {
@@ -65,21 +293,87 @@ func NewGRPCServer(config Config, repos repository.RepositoryManager, logger *lo
 		config.MaxConcurrent = 1000
 	}
 	
+	// Check the subsystems for the gRPC health service and readiness probe
+	config.Health.Services = append(config.Health.Services, pb.LabInstrumentGateway_ServiceDesc.ServiceName)
+	checker := health.NewChecker(config.Health, logger)
+	checker.Register(health.SubsystemDatabase, repos.HealthCheck)
+	checker.Register(health.SubsystemIngest, ingester.HealthCheck)
+	checker.Register(health.SubsystemConnections, connectionManager.HealthCheck)
+	checker.Register(health.SubsystemNotifier, func(ctx context.Context) error {
+		return alerting.CheckNotifier(ctx, notifier)
+	})
+	if registry != nil {
+		checker.Register(health.SubsystemRegistry, registry.HealthCheck)
+	}
+	
+	// Serve probes and metrics on the admin server, refreshing sampled gauges
+	var adminServer *AdminServer
+	var metrics *metricsPublisher
+	if config.Admin.Enabled {
+		config.Admin.SetDefaults()
+		adminServer = NewAdminServer(config.Admin, logger)
+		adminServer.Handle("/livez", checker.LivezHandler())
+		adminServer.Handle("/readyz", checker.ReadyzHandler())
+		if config.Admin.Metrics {
+			metrics = newMetricsPublisher(repos, connectionManager, config.Admin.MetricsInterval, logger)
+		}
+	}
+	
 	return &GRPCServer{
 		repos:               repos,
 		connectionManager:   connectionManager,
+		statusTracker:       statusTracker,
+		alertManager:        alertManager,
+		escalator:           escalator,
+		ingester:            ingester,
+		dispatcher:          dispatcher,
+		registry:            registry,
+		certReloader:        certReloader,
+		authenticator:       authenticator,
+		authorizer:          authorizer,
+		limiter:             limiter,
+		auditRecorder:       audit.NewRecorder(repos.Audit(), logger),
+		adminServer:         adminServer,
+		metrics:             metrics,
+		health:              checker,
 		logger:              logger,
 		deviceHandler:       deviceHandler,
 		deviceStatusHandler: deviceStatusHandler,
 		deviceListHandler:   deviceListHandler,
+		deviceHistoryHandler: deviceHistoryHandler,
+		silenceHandler:      silenceHandler,
+		streamHandler:       streamHandler,
+		provisioningHandler: provisioningHandler,
+		apiKeyHandler:       apiKeyHandler,
+		auditHandler:        auditHandler,
+		commandHandler:      commandHandler,
 		port:                config.Port,
 		maxMessageSize:      config.MaxMessageSize,
 		maxConcurrent:       config.MaxConcurrent,
+		tlsClientAuth:       clientAuth,
+		tlsReload:           config.TLS.ReloadInterval,
+		auditFailClosed:     config.AuditFailClosed,
 	}, nil
 }
 
+// ReportDatabaseStats publishes the database pool statistics returned by
+// stats with the other sampled metrics. It must be called before Start.
+func (s *GRPCServer) ReportDatabaseStats(stats func() db.ConnectionStats) {
+	if s.metrics != nil {
+		s.metrics.dbStats = stats
+	}
+}
+
 // Start starts the gRPC server
 func (s *GRPCServer) Start() error {
+	// Receive the commands other replicas forward before serving, so that
+	// commands for the devices streaming here are not left pending
+	subscribeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.dispatcher.Start(subscribeCtx); err != nil {
+		return fmt.Errorf("failed to start command forwarding: %w", err)
+	}
+	
 	// Create listener
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
 	if err != nil {
@@ -87,6 +381,43 @@ func (s *GRPCServer) Start() error {
 	}
 	s.listener = listener
 	
+	// Middleware chain; authentication runs before anything that relies on
+	// the caller identity, and rate limiting right after it so rejected
+	// callers cost as little as possible. Auditing wraps authorization so
+	// that denied attempts are recorded too.
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		middleware.LoggingInterceptor(s.logger),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		middleware.StreamLoggingInterceptor(s.logger),
+	}
+	if s.authenticator != nil {
+		unaryInterceptors = append(unaryInterceptors, middleware.AuthInterceptor(s.authenticator, s.logger))
+		streamInterceptors = append(streamInterceptors, middleware.StreamAuthInterceptor(s.authenticator, s.logger))
+	}
+	if s.limiter != nil {
+		unaryInterceptors = append(unaryInterceptors, middleware.RateLimitInterceptor(s.limiter, s.logger))
+		streamInterceptors = append(streamInterceptors, middleware.StreamRateLimitInterceptor(s.limiter, s.logger))
+	}
+	unaryInterceptors = append(unaryInterceptors, middleware.AuditInterceptor(s.auditRecorder, s.auditFailClosed, s.logger))
+	unaryInterceptors = append(unaryInterceptors, middleware.DeviceIdentityInterceptor(s.logger))
+	streamInterceptors = append(streamInterceptors, middleware.StreamDeviceIdentityInterceptor(s.logger))
+	if s.authorizer != nil {
+		unaryInterceptors = append(unaryInterceptors, middleware.AuthorizationInterceptor(s.authorizer, s.logger))
+		streamInterceptors = append(streamInterceptors, middleware.StreamAuthorizationInterceptor(s.authorizer, s.logger))
+	}
+	unaryInterceptors = append(unaryInterceptors,
+		middleware.ValidationInterceptor(),
+		middleware.ConsistencyInterceptor(),
+		middleware.MetricsInterceptor(),
+		middleware.RecoveryInterceptor(s.logger),
+	)
+	streamInterceptors = append(streamInterceptors,
+		middleware.StreamConsistencyInterceptor(),
+		middleware.StreamMetricsInterceptor(),
+		middleware.StreamRecoveryInterceptor(s.logger),
+	)
+	
 	// Create gRPC server with options
 	serverOptions := []grpc.ServerOption{
 		// Message size limits
@@ -110,16 +441,21 @@ func (s *GRPCServer) Start() error {
 		}),
 		
 		// Middleware chain
-		grpc.ChainUnaryInterceptor(
-			middleware.LoggingInterceptor(s.logger),
-			middleware.ValidationInterceptor(),
-			middleware.MetricsInterceptor(),
-			middleware.RecoveryInterceptor(s.logger),
-		),
-		grpc.ChainStreamInterceptor(
-			middleware.StreamLoggingInterceptor(s.logger),
-			middleware.StreamRecoveryInterceptor(s.logger),
-		),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		
+		// Connection metrics
+		grpc.StatsHandler(middleware.ConnectionMetricsHandler()),
+		
+		// Server spans, continuing the W3C trace context of the caller
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	}
+	
+	// Transport security
+	if s.certReloader != nil {
+		tlsConfig := tlsutil.NewServerConfig(s.certReloader, s.tlsClientAuth)
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		s.certReloader.Start(s.tlsReload)
 	}
 	
 	s.server = grpc.NewServer(serverOptions...)
@@ -129,13 +465,25 @@ func (s *GRPCServer) Start() error {
 		deviceHandler:       s.deviceHandler,
 		deviceStatusHandler: s.deviceStatusHandler,
 		deviceListHandler:   s.deviceListHandler,
+		deviceHistoryHandler: s.deviceHistoryHandler,
+		silenceHandler:      s.silenceHandler,
+		streamHandler:       s.streamHandler,
+		provisioningHandler: s.provisioningHandler,
+		apiKeyHandler:       s.apiKeyHandler,
+		auditHandler:        s.auditHandler,
+		commandHandler:      s.commandHandler,
 		connectionManager:   s.connectionManager,
-		repos:               s.repos,
+		health:              s.health,
 		logger:              s.logger,
 	}
 	
 	pb.RegisterLabInstrumentGatewayServer(s.server, labInstrumentService)
 	
+	// Standard health service for load balancers and Kubernetes probes,
+	// serving once the subsystems have been checked
+	healthpb.RegisterHealthServer(s.server, s.health.Server())
+	s.health.Start()
+	
 	// Enable reflection for development
 	reflection.Register(s.server)
 	
@@ -143,6 +491,11 @@ func (s *GRPCServer) Start() error {
 		"port":             s.port,
 		"max_message_size": s.maxMessageSize,
 		"max_concurrent":   s.maxConcurrent,
+		"tls":              s.certReloader != nil,
+		"client_auth":      s.tlsClientAuth,
+		"auth_enabled":     s.authenticator != nil,
+		"rbac_enabled":     s.authorizer != nil,
+		"rate_limit":       s.limiter != nil,
 	}).Info("Starting gRPC server")
 	
 	// Start serving
@@ -152,14 +505,47 @@ func (s *GRPCServer) Start() error {
 		}
 	}()
 	
+	// Start the admin server and metrics publishing
+	if s.adminServer != nil {
+		if err := s.adminServer.Start(); err != nil {
+			s.server.Stop()
+			return err
+		}
+	}
+	if s.metrics != nil {
+		s.metrics.start()
+	}
+	
+	// Start background alert escalation and measurement flushing
+	s.escalator.Start()
+	s.ingester.Start()
+	if s.limiter != nil {
+		s.limiter.Start()
+	}
+	
 	s.logger.WithField("address", listener.Addr().String()).Info("gRPC server started")
 	return nil
 }
 
-// Stop gracefully stops the gRPC server
+// Stop gracefully stops the gRPC server: it reports not serving, hands the
+// connected devices off to other replicas and drains open calls until ctx
+// is done
 func (s *GRPCServer) Stop(ctx context.Context) error {
 	s.logger.Info("Stopping gRPC server")
 	
+	// Stop receiving new traffic while open calls drain
+	s.health.Drain()
+	
+	// Leave undelivered and unacknowledged commands pending for the replicas
+	// devices reconnect to, then hand the devices off, writing their
+	// measurements first
+	if err := s.dispatcher.Drain(ctx); err != nil {
+		s.logger.WithError(err).Warn("Failed to drain command dispatcher")
+	}
+	if err := s.streamHandler.Drain(ctx); err != nil {
+		s.logger.WithError(err).Warn("Failed to hand off all device streams")
+	}
+	
 	// Create a channel to signal when graceful stop is complete
 	stopped := make(chan struct{})
 	
@@ -177,10 +563,66 @@ func (s *GRPCServer) Stop(ctx context.Context) error {
 		s.server.Stop()
 	}
 	
-	// Close connection manager
+	// Write out buffered measurements
+	if err := s.ingester.Close(); err != nil {
+		s.logger.WithError(err).Warn("Failed to flush buffered measurements")
+	}
+	
+	// Stop alert escalation
+	if err := s.escalator.Close(); err != nil {
+		s.logger.WithError(err).Warn("Failed to stop alert escalator")
+	}
+	
+	// Stop evicting idle rate limit buckets
+	if s.limiter != nil {
+		if err := s.limiter.Close(); err != nil {
+			s.logger.WithError(err).Warn("Failed to stop rate limiter")
+		}
+	}
+	
+	// Stop watching TLS certificates
+	if s.certReloader != nil {
+		if err := s.certReloader.Close(); err != nil {
+			s.logger.WithError(err).Warn("Failed to stop TLS certificate watcher")
+		}
+	}
+	
+	// Stop receiving forwarded commands
+	if err := s.dispatcher.Close(); err != nil {
+		s.logger.WithError(err).Warn("Failed to stop command forwarding")
+	}
+	
+	// Close connection manager, unregistering its remaining streams
 	if err := s.connectionManager.Close(); err != nil {
 		s.logger.WithError(err).Warn("Failed to close connection manager")
 	}
+	if s.registry != nil {
+		if err := s.registry.Close(); err != nil {
+			s.logger.WithError(err).Warn("Failed to close connection registry")
+		}
+	}
+	
+	// Persist the status transitions still queued
+	if err := s.statusTracker.Close(); err != nil {
+		s.logger.WithError(err).Warn("Failed to persist device status updates")
+	}
+	
+	// Stop checking subsystems; the health status stays not serving
+	if err := s.health.Close(); err != nil {
+		s.logger.WithError(err).Warn("Failed to stop health checker")
+	}
+	
+	// Stop serving metrics and probes last, so that the drain can be observed
+	if s.metrics != nil {
+		s.metrics.close()
+	}
+	if s.adminServer != nil {
+		adminCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.adminServer.Stop(adminCtx); err != nil {
+			s.logger.WithError(err).Warn("Failed to stop admin server")
+		}
+	}
 	
 	return nil
 }
@@ -192,6 +634,10 @@ func (s *GRPCServer) GetStats() map[string]interface{} {
 		"max_message_size": s.maxMessageSize,
 		"max_concurrent":   s.maxConcurrent,
 		"is_serving":       s.server != nil,
+		"tls_enabled":      s.certReloader != nil,
+	}
+	if s.adminServer != nil {
+		stats["admin_address"] = s.adminServer.Addr()
 	}
 	
 	// Add connection manager stats
@@ -202,6 +648,13 @@ func (s *GRPCServer) GetStats() map[string]interface{} {
 		}
 	}
 	
+	// Add ingester stats
+	if s.ingester != nil {
+		for k, v := range s.ingester.GetStats() {
+			stats[fmt.Sprintf("ingest_%s", k)] = v
+		}
+	}
+	
 	return stats
 }
 
@@ -212,8 +665,15 @@ type LabInstrumentService struct {
 	deviceHandler       *handlers.DeviceHandler
 	deviceStatusHandler *handlers.DeviceStatusHandler
 	deviceListHandler   *handlers.DeviceListHandler
+	deviceHistoryHandler *handlers.DeviceHistoryHandler
+	silenceHandler      *handlers.SilenceHandler
+	streamHandler       *handlers.StreamHandler
+	provisioningHandler *handlers.ProvisioningHandler
+	apiKeyHandler       *handlers.APIKeyHandler
+	auditHandler        *handlers.AuditHandler
+	commandHandler      *handlers.CommandHandler
 	connectionManager   *device.ConnectionManager
-	repos               repository.RepositoryManager
+	health              *health.Checker
 	logger              *logger.Logger
 }
 
@@ -232,18 +692,64 @@ func (s *LabInstrumentService) ListDevices(ctx context.Context, req *pb.ListDevi
 	return s.deviceListHandler.ListDevices(ctx, req)
 }
 
-// StreamData handles real-time data streaming (placeholder implementation)
+// GetDeviceHistory handles device lifecycle history requests
+func (s *LabInstrumentService) GetDeviceHistory(ctx context.Context, req *pb.GetDeviceHistoryRequest) (*pb.GetDeviceHistoryResponse, error) {
+	return s.deviceHistoryHandler.GetDeviceHistory(ctx, req)
+}
+
+// CreateSilence handles alert silence creation
+func (s *LabInstrumentService) CreateSilence(ctx context.Context, req *pb.CreateSilenceRequest) (*pb.CreateSilenceResponse, error) {
+	return s.silenceHandler.CreateSilence(ctx, req)
+}
+
+// ListSilences handles alert silence listing requests
+func (s *LabInstrumentService) ListSilences(ctx context.Context, req *pb.ListSilencesRequest) (*pb.ListSilencesResponse, error) {
+	return s.silenceHandler.ListSilences(ctx, req)
+}
+
+// ExpireSilence handles requests to end an alert silence early
+func (s *LabInstrumentService) ExpireSilence(ctx context.Context, req *pb.ExpireSilenceRequest) (*pb.ExpireSilenceResponse, error) {
+	return s.silenceHandler.ExpireSilence(ctx, req)
+}
+
+// CreateEnrollmentToken handles enrollment token creation
+func (s *LabInstrumentService) CreateEnrollmentToken(ctx context.Context, req *pb.CreateEnrollmentTokenRequest) (*pb.CreateEnrollmentTokenResponse, error) {
+	return s.provisioningHandler.CreateEnrollmentToken(ctx, req)
+}
+
+// EnrollDevice handles device enrollment
+func (s *LabInstrumentService) EnrollDevice(ctx context.Context, req *pb.EnrollDeviceRequest) (*pb.EnrollDeviceResponse, error) {
+	return s.provisioningHandler.EnrollDevice(ctx, req)
+}
+
+// CreateAPIKey handles API key creation
+func (s *LabInstrumentService) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	return s.apiKeyHandler.CreateAPIKey(ctx, req)
+}
+
+// ListAPIKeys handles API key listing
+func (s *LabInstrumentService) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	return s.apiKeyHandler.ListAPIKeys(ctx, req)
+}
+
+// RevokeAPIKey handles API key revocation
+func (s *LabInstrumentService) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	return s.apiKeyHandler.RevokeAPIKey(ctx, req)
+}
+
+// QueryAuditLog handles audit trail queries
+func (s *LabInstrumentService) QueryAuditLog(ctx context.Context, req *pb.QueryAuditLogRequest) (*pb.QueryAuditLogResponse, error) {
+	return s.auditHandler.QueryAuditLog(ctx, req)
+}
+
+// StreamData handles real-time data streaming
 func (s *LabInstrumentService) StreamData(stream pb.LabInstrumentGateway_StreamDataServer) error {
-	// TODO: Implement streaming functionality
-	s.logger.Info("StreamData called - not yet implemented")
-	return fmt.Errorf("streaming not yet implemented")
+	return s.streamHandler.StreamData(stream)
 }
 
-// SendCommand handles command sending (placeholder implementation)
+// SendCommand handles command submission
 func (s *LabInstrumentService) SendCommand(ctx context.Context, req *pb.SendCommandRequest) (*pb.SendCommandResponse, error) {
-	// TODO: Implement command sending functionality
-	s.logger.WithField("device_id", req.DeviceId).Info("SendCommand called - not yet implemented")
-	return nil, fmt.Errorf("command sending not yet implemented")
+	return s.commandHandler.SendCommand(ctx, req)
 }
 
 // GetMeasurements handles historical data requests (placeholder implementation)
@@ -253,16 +759,12 @@ func (s *LabInstrumentService) GetMeasurements(ctx context.Context, req *pb.GetM
 	return nil, fmt.Errorf("measurements retrieval not yet implemented")
 }
 
-// HealthCheck handles health check requests
+// HealthCheck handles health check requests, reporting the latest status of
+// each subsystem, or only of the subsystem the request names
 func (s *LabInstrumentService) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
-	// Perform repository health check
-	if err := s.repos.HealthCheck(ctx); err != nil {
-		s.logger.WithError(err).Error("Repository health check failed")
-		return &pb.HealthCheckResponse{
-			Status:    pb.HealthStatus_HEALTH_NOT_SERVING,
-			Message:   "Repository health check failed",
-			Timestamp: nil,
-		}, nil
+	statuses := s.health.Statuses()
+	if err, ok := statuses[req.Service]; ok {
+		statuses = map[string]error{req.Service: err}
 	}
 	
 	// Get connection manager stats
@@ -272,10 +774,26 @@ func (s *LabInstrumentService) HealthCheck(ctx context.Context, req *pb.HealthCh
 		details[k] = fmt.Sprintf("%v", v)
 	}
 	
-	return &pb.HealthCheckResponse{
+	healthy := !s.health.Draining()
+	for name, err := range statuses {
+		if err != nil {
+			healthy = false
+			details[name] = err.Error()
+		} else {
+			details[name] = "ok"
+		}
+	}
+	
+	response := &pb.HealthCheckResponse{
 		Status:    pb.HealthStatus_HEALTH_SERVING,
 		Message:   "Service is healthy",
 		Details:   details,
-		Timestamp: nil,
-	}, nil
+		Timestamp: timestamppb.Now(),
+	}
+	if !healthy {
+		response.Status = pb.HealthStatus_HEALTH_NOT_SERVING
+		response.Message = "Service is not healthy"
+	}
+	
+	return response, nil
 }
\ No newline at end of file
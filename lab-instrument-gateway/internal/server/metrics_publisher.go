@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourorg/lab-gateway/internal/device"
+	"github.com/yourorg/lab-gateway/internal/middleware"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// deviceStatuses are published even when no device is in them, so that
+// gauges drop to zero rather than disappearing
+var deviceStatuses = []models.DeviceStatus{
+	models.DeviceStatusUnknown,
+	models.DeviceStatusOnline,
+	models.DeviceStatusOffline,
+	models.DeviceStatusError,
+	models.DeviceStatusMaintenance,
+	models.DeviceStatusConnecting,
+}
+
+// metricsPublisher periodically refreshes the gauges that are sampled
+// rather than updated as events happen
+type metricsPublisher struct {
+	repos       repository.RepositoryManager
+	connections *device.ConnectionManager
+	dbStats     func() db.ConnectionStats
+	interval    time.Duration
+	logger      *logger.Logger
+
+	// Channels for lifecycle management
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// newMetricsPublisher creates a metrics publisher. Call start to begin publishing.
+func newMetricsPublisher(repos repository.RepositoryManager, connections *device.ConnectionManager, interval time.Duration, logger *logger.Logger) *metricsPublisher {
+	return &metricsPublisher{
+		repos:       repos,
+		connections: connections,
+		interval:    interval,
+		logger:      logger,
+		stopChan:    make(chan struct{}),
+		doneChan:    make(chan struct{}),
+	}
+}
+
+// start publishes once and then every interval until close
+func (p *metricsPublisher) start() {
+	go func() {
+		defer close(p.doneChan)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.publish()
+
+			select {
+			case <-ticker.C:
+			case <-p.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// close stops publishing
+func (p *metricsPublisher) close() {
+	close(p.stopChan)
+	<-p.doneChan
+}
+
+// publish samples device counts by status, device connections and the
+// database pool
+func (p *metricsPublisher) publish() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+	defer cancel()
+
+	counts, err := p.repos.Device().GetStatusCounts(ctx)
+	if err != nil {
+		p.logger.WithError(err).Warn("Failed to publish device status metrics")
+	} else {
+		statusCounts := make(map[string]int, len(deviceStatuses))
+		for _, status := range deviceStatuses {
+			statusCounts[string(status)] = 0
+		}
+		for status, count := range counts {
+			statusCounts[string(status)] = int(count)
+		}
+		middleware.UpdateDeviceStatusMetrics(statusCounts)
+	}
+
+	middleware.UpdateDeviceConnectionMetrics(p.connections.GetConnectionCount(), p.connections.GetSessionCount())
+
+	if p.dbStats != nil {
+		stats := p.dbStats()
+		middleware.UpdateDatabaseConnectionMetrics(stats.InUseConnections, stats.IdleConnections)
+	}
+}
@@ -0,0 +1,72 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// ClientAuthMode controls whether clients must present a certificate
+type ClientAuthMode string
+
+const (
+	// ClientAuthNone serves TLS without asking for client certificates
+	ClientAuthNone ClientAuthMode = "none"
+	// ClientAuthRequest verifies a client certificate if one is presented
+	ClientAuthRequest ClientAuthMode = "request"
+	// ClientAuthRequire rejects clients without a valid certificate (mTLS)
+	ClientAuthRequire ClientAuthMode = "require"
+)
+
+// ParseClientAuthMode parses a client authentication mode. An empty value
+// defaults to requiring client certificates when a CA is configured.
+func ParseClientAuthMode(value string, hasCA bool) (ClientAuthMode, error) {
+	mode := ClientAuthMode(strings.ToLower(strings.TrimSpace(value)))
+
+	switch mode {
+	case "":
+		if hasCA {
+			return ClientAuthRequire, nil
+		}
+		return ClientAuthNone, nil
+	case ClientAuthNone:
+		return mode, nil
+	case ClientAuthRequest, ClientAuthRequire:
+		if !hasCA {
+			return "", fmt.Errorf("client auth mode %q requires a CA file", mode)
+		}
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid client auth mode %q, must be none, request or require", value)
+	}
+}
+
+// NewServerConfig returns a server TLS configuration that takes its
+// certificate and client CAs from the reloader on every handshake
+func NewServerConfig(reloader *CertReloader, mode ClientAuthMode) *tls.Config {
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		// gRPC requires HTTP/2 to be negotiated; set it here because configs
+		// returned by GetConfigForClient are used as they are
+		NextProtos: []string{"h2"},
+	}
+
+	switch mode {
+	case ClientAuthRequest:
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		base.ClientAuth = tls.NoClientCert
+	}
+
+	config := base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := base.Clone()
+		handshake.ClientCAs = reloader.ClientCAs()
+		return handshake, nil
+	}
+
+	return config
+}
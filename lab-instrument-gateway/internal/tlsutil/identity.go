@@ -0,0 +1,58 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// DeviceURIScheme is the URI SAN scheme naming a device, as in device://hplc-01
+const DeviceURIScheme = "device"
+
+// PeerCertificate returns the verified client certificate of the caller, or
+// nil if the connection is not TLS or the client presented no certificate
+func PeerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return nil
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+// DeviceIDs returns the device identities a certificate is issued for: the
+// hosts of device:// URI SANs if there are any, otherwise the subject
+// common name and DNS SANs
+func DeviceIDs(cert *x509.Certificate) []string {
+	var ids []string
+	for _, uri := range cert.URIs {
+		if uri.Scheme == DeviceURIScheme && uri.Host != "" {
+			ids = append(ids, uri.Host)
+		}
+	}
+	if len(ids) > 0 {
+		return ids
+	}
+
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, cert.Subject.CommonName)
+	}
+	return append(ids, cert.DNSNames...)
+}
+
+// CertificateMatchesDevice reports whether the certificate identifies the device
+func CertificateMatchesDevice(cert *x509.Certificate, deviceID string) bool {
+	for _, id := range DeviceIDs(cert) {
+		if id == deviceID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// DefaultReloadInterval is how often certificate files are checked for changes
+const DefaultReloadInterval = time.Minute
+
+// CertReloader serves a certificate and client CA pool loaded from files,
+// reloading them when the files change so certificates can be rotated
+// without a restart
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	logger   *logger.Logger
+
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	modTimes    map[string]time.Time
+	mutex       sync.RWMutex
+
+	// Channels for lifecycle management
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewCertReloader loads the certificate, key and optional CA bundle. It
+// fails if they cannot be loaded, so a misconfigured server never starts.
+func NewCertReloader(certFile, keyFile, caFile string, logger *logger.Logger) (*CertReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("TLS certificate and key files are required")
+	}
+
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		logger:   logger,
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the files again. On failure the previously loaded
+// certificate stays in use.
+func (r *CertReloader) Reload() error {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	if certificate.Leaf == nil && len(certificate.Certificate) > 0 {
+		certificate.Leaf, _ = x509.ParseCertificate(certificate.Certificate[0])
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA file %s", r.caFile)
+		}
+	}
+
+	r.mutex.Lock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.mutex.Unlock()
+
+	fields := map[string]interface{}{
+		"cert_file": r.certFile,
+		"ca_file":   r.caFile,
+	}
+	if certificate.Leaf != nil {
+		fields["subject"] = certificate.Leaf.Subject.String()
+		fields["not_after"] = certificate.Leaf.NotAfter
+	}
+	r.logger.WithFields(fields).Info("TLS certificates loaded")
+
+	return nil
+}
+
+// GetCertificate returns the current server certificate, for use as
+// tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.certificate, nil
+}
+
+// ClientCAs returns the current client CA pool, or nil if no CA is configured
+func (r *CertReloader) ClientCAs() *x509.CertPool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.clientCAs
+}
+
+// Start periodically checks the files for changes and reloads them
+func (r *CertReloader) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	go r.watchRoutine(interval)
+}
+
+// Close stops watching the files
+func (r *CertReloader) Close() error {
+	close(r.stopChan)
+
+	select {
+	case <-r.doneChan:
+	case <-time.After(5 * time.Second):
+		r.logger.Warn("TLS certificate watcher did not stop within timeout")
+	}
+
+	return nil
+}
+
+// watchRoutine reloads the files whenever one of them changes
+func (r *CertReloader) watchRoutine(interval time.Duration) {
+	defer close(r.doneChan)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				r.logger.WithError(err).Error("Failed to reload TLS certificates, keeping previous ones")
+			}
+		case <-r.stopChan:
+			return
+		}
+	}
+}
+
+// changed reports whether any of the files was modified since the last load
+func (r *CertReloader) changed() bool {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to check TLS certificate files")
+		return false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// statFiles returns the modification time of each configured file
+func (r *CertReloader) statFiles() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
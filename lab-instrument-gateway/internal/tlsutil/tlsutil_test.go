@@ -0,0 +1,255 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for the template
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func serverTemplate(name string) *x509.Certificate {
+	return &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+}
+
+func clientTemplate(commonName string, uris ...string) *x509.Certificate {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, raw := range uris {
+		uri, _ := url.Parse(raw)
+		template.URIs = append(template.URIs, uri)
+	}
+	return template
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestDeviceIDs(t *testing.T) {
+	ca := newTestCA(t)
+
+	certPEM, _ := ca.issue(t, clientTemplate("hplc-01"))
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hplc-01"}, DeviceIDs(cert))
+	assert.True(t, CertificateMatchesDevice(cert, "hplc-01"))
+	assert.False(t, CertificateMatchesDevice(cert, "hplc-02"))
+
+	// device:// URI SANs take precedence over the common name
+	certPEM, _ = ca.issue(t, clientTemplate("Lab 3 HPLC", "device://hplc-03", "https://example.com"))
+	block, _ = pem.Decode(certPEM)
+	cert, err = x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hplc-03"}, DeviceIDs(cert))
+	assert.False(t, CertificateMatchesDevice(cert, "Lab 3 HPLC"))
+}
+
+func TestParseClientAuthMode(t *testing.T) {
+	mode, err := ParseClientAuthMode("", true)
+	require.NoError(t, err)
+	assert.Equal(t, ClientAuthRequire, mode)
+
+	mode, err = ParseClientAuthMode("", false)
+	require.NoError(t, err)
+	assert.Equal(t, ClientAuthNone, mode)
+
+	_, err = ParseClientAuthMode("require", false)
+	assert.Error(t, err)
+	_, err = ParseClientAuthMode("sometimes", true)
+	assert.Error(t, err)
+}
+
+func TestCertReloader_MutualTLSAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server-cert")
+	keyFile := filepath.Join(dir, "server-key")
+	caFile := filepath.Join(dir, "client-ca")
+
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, serverTemplate("gateway-v1"))
+	past := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, certPEM, past)
+	writeFile(t, keyFile, keyPEM, past)
+	writeFile(t, caFile, ca.pem, past)
+
+	reloader, err := NewCertReloader(certFile, keyFile, caFile, logger.NewDefaultLogger())
+	require.NoError(t, err)
+	reloader.Start(10 * time.Millisecond)
+	defer reloader.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", NewServerConfig(reloader, ClientAuthRequire))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	peerCerts := make(chan []*x509.Certificate, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			if tlsConn.Handshake() == nil {
+				chains := tlsConn.ConnectionState().VerifiedChains
+				if len(chains) > 0 {
+					peerCerts <- chains[0]
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCertPEM, clientKeyPEM := ca.issue(t, clientTemplate("hplc-01"))
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	require.NoError(t, err)
+
+	dial := func(certs []tls.Certificate) (*tls.Conn, error) {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: certs,
+			NextProtos:   []string{"h2"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		// TLS 1.3 reports client certificate rejection on the first read;
+		// an accepted client just sees the server close the connection
+		if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	_, err = dial(nil)
+	assert.Error(t, err, "clients without a certificate are rejected")
+
+	conn, err := dial([]tls.Certificate{clientCert})
+	require.NoError(t, err)
+	assert.Equal(t, "gateway-v1", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+	assert.Equal(t, "h2", conn.ConnectionState().NegotiatedProtocol)
+	conn.Close()
+
+	select {
+	case chain := <-peerCerts:
+		assert.Equal(t, []string{"hplc-01"}, DeviceIDs(chain[0]))
+	case <-time.After(time.Second):
+		t.Fatal("server did not verify the client certificate")
+	}
+
+	// Rotating the server certificate on disk is picked up without a restart
+	certPEM, keyPEM = ca.issue(t, serverTemplate("gateway-v2"))
+	now := time.Now()
+	writeFile(t, keyFile, keyPEM, now)
+	writeFile(t, certFile, certPEM, now)
+
+	require.Eventually(t, func() bool {
+		conn, err := dial([]tls.Certificate{clientCert})
+		if err != nil {
+			return false
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName == "gateway-v2"
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestCertReloader_KeepsCertificateOnFailedReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server-cert")
+	keyFile := filepath.Join(dir, "server-key")
+
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, serverTemplate("gateway-v1"))
+	writeFile(t, certFile, certPEM, time.Now())
+	writeFile(t, keyFile, keyPEM, time.Now())
+
+	reloader, err := NewCertReloader(certFile, keyFile, "", logger.NewDefaultLogger())
+	require.NoError(t, err)
+	assert.Nil(t, reloader.ClientCAs())
+
+	writeFile(t, certFile, []byte("not a certificate"), time.Now())
+	assert.Error(t, reloader.Reload())
+
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "gateway-v1", cert.Leaf.Subject.CommonName)
+
+	_, err = NewCertReloader(certFile, keyFile, "", logger.NewDefaultLogger())
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+-- Alert silences and maintenance-window muting
+-- Migration: 002_alert_silences.sql
+
+-- Silences mute alerts matching all of their non-null matchers between starts_at and ends_at
+CREATE TABLE alert_silences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    device_id VARCHAR(255) REFERENCES devices(id) ON DELETE CASCADE,
+    device_type VARCHAR(100),
+    alert_type VARCHAR(100),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT alert_silences_window CHECK (ends_at >= starts_at)
+);
+
+CREATE INDEX idx_alert_silences_window ON alert_silences(starts_at, ends_at);
+CREATE INDEX idx_alert_silences_device_id ON alert_silences(device_id);
+
+-- Silenced alerts are still stored so they remain queryable, but flagged
+ALTER TABLE alerts ADD COLUMN silenced BOOLEAN DEFAULT false;
+ALTER TABLE alerts ADD COLUMN silenced_by VARCHAR(255);
+
+CREATE INDEX idx_alerts_silenced ON alerts(silenced);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON alert_silences TO lab_gateway_user;
@@ -0,0 +1,52 @@
+-- Alert escalation policies, routes and on-call schedules
+-- Migration: 003_alert_escalation.sql
+
+-- Weekly on-call rotations; the participant on call advances every 7 days from rotation_start
+CREATE TABLE on_call_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    rotation_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    participants TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT on_call_schedules_participants CHECK (array_length(participants, 1) > 0)
+);
+
+-- Escalation policies hold an ordered list of steps, each with a delay measured from alert creation
+CREATE TABLE escalation_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    steps JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Alert routes attach a policy to alerts matching all of their non-null matchers
+CREATE TABLE alert_routes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    device_type VARCHAR(100),
+    alert_type VARCHAR(100),
+    min_severity VARCHAR(50),
+    policy_id UUID NOT NULL REFERENCES escalation_policies(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_alert_routes_priority ON alert_routes(priority);
+
+-- Escalation progress per alert; level is the number of steps already notified
+CREATE TABLE alert_escalations (
+    alert_id UUID PRIMARY KEY REFERENCES alerts(id) ON DELETE CASCADE,
+    policy_id UUID REFERENCES escalation_policies(id) ON DELETE SET NULL,
+    level INTEGER NOT NULL DEFAULT 0,
+    last_escalated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TRIGGER update_escalation_policies_updated_at BEFORE UPDATE ON escalation_policies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON on_call_schedules TO lab_gateway_user;
+GRANT SELECT, INSERT, UPDATE, DELETE ON escalation_policies TO lab_gateway_user;
+GRANT SELECT, INSERT, UPDATE, DELETE ON alert_routes TO lab_gateway_user;
+GRANT SELECT, INSERT, UPDATE, DELETE ON alert_escalations TO lab_gateway_user;
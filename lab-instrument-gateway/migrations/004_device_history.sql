@@ -0,0 +1,26 @@
+-- Device lifecycle event log and connection history
+-- Migration: 004_device_history.sql
+
+-- Sessions are kept after they end so that connection history can be reconstructed
+ALTER TABLE device_sessions ADD COLUMN disconnected_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE device_sessions ADD COLUMN disconnect_reason TEXT;
+
+CREATE INDEX idx_device_sessions_connected_at ON device_sessions(device_id, connected_at DESC);
+
+-- Append-only log of device lifecycle events
+CREATE TABLE device_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    device_id VARCHAR(255) NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    session_id VARCHAR(255),
+    from_status device_status,
+    to_status device_status,
+    message TEXT,
+    metadata JSONB DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_device_events_device_time ON device_events(device_id, occurred_at DESC);
+CREATE INDEX idx_device_events_type ON device_events(type);
+
+GRANT SELECT, INSERT, DELETE ON device_events TO lab_gateway_user;
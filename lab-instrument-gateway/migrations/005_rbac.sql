@@ -0,0 +1,45 @@
+-- Role-based access control
+-- Migration: 005_rbac.sql
+
+-- Roles are named sets of permissions; built-in roles are seeded below
+CREATE TABLE roles (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    built_in BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Role bindings grant a role to a subject for all devices (device_group NULL) or for one device group
+CREATE TABLE role_bindings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subject VARCHAR(255) NOT NULL,
+    role VARCHAR(100) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    device_group VARCHAR(255),
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_role_bindings_unique ON role_bindings(subject, role, COALESCE(device_group, ''));
+CREATE INDEX idx_role_bindings_subject ON role_bindings(subject);
+
+-- Devices are scoped to a group through their device_group metadata key
+CREATE INDEX idx_devices_device_group ON devices((metadata->>'device_group'));
+
+CREATE TRIGGER update_roles_updated_at BEFORE UPDATE ON roles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO roles (name, description, permissions, built_in) VALUES
+    ('viewer', 'Read devices, measurements and alerts',
+        ARRAY['devices:read', 'measurements:read', 'alerts:read'], TRUE),
+    ('operator', 'Viewer permissions and sending commands',
+        ARRAY['devices:read', 'measurements:read', 'alerts:read', 'commands:send'], TRUE),
+    ('admin', 'Full access, including device registration, alert management and access control',
+        ARRAY['devices:read', 'devices:write', 'measurements:read', 'measurements:write', 'commands:send',
+              'alerts:read', 'alerts:manage', 'access:manage'], TRUE),
+    ('device', 'Granted to instruments authenticated by client certificate',
+        ARRAY['devices:write', 'measurements:write'], TRUE);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON roles TO lab_gateway_user;
+GRANT SELECT, INSERT, UPDATE, DELETE ON role_bindings TO lab_gateway_user;
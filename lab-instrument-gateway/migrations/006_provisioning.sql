@@ -0,0 +1,49 @@
+-- Device provisioning: enrollment tokens and issued credentials
+-- Migration: 006_provisioning.sql
+
+-- One-time enrollment tokens; only the SHA-256 hash of the token is stored
+CREATE TABLE enrollment_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    device_type VARCHAR(100) NOT NULL,
+    device_group VARCHAR(255),
+    created_by VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    used_by_device VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_enrollment_tokens_expires_at ON enrollment_tokens(expires_at);
+
+-- API keys are looked up by their public prefix and verified against the SHA-256 hash of the full key
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    subject VARCHAR(255) NOT NULL,
+    device_id VARCHAR(255) REFERENCES devices(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_keys_subject ON api_keys(subject);
+
+-- Client certificates issued by the gateway's local CA
+CREATE TABLE device_certificates (
+    serial_number VARCHAR(64) PRIMARY KEY,
+    device_id VARCHAR(255) NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    not_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    not_after TIMESTAMP WITH TIME ZONE NOT NULL,
+    issued_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_device_certificates_device_id ON device_certificates(device_id);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON enrollment_tokens TO lab_gateway_user;
+GRANT SELECT, INSERT, UPDATE, DELETE ON api_keys TO lab_gateway_user;
+GRANT SELECT, INSERT, DELETE ON device_certificates TO lab_gateway_user;
@@ -0,0 +1,12 @@
+-- API key scopes and usage tracking
+-- Migration: 007_api_key_scopes.sql
+
+-- Keys grant their roles only within device_groups, or on all devices when
+-- no groups are listed. Device keys issued on enrollment have neither and
+-- are limited to their device.
+ALTER TABLE api_keys
+    ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN device_groups TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_api_keys_created_at ON api_keys(created_at);
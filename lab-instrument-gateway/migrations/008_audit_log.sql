@@ -0,0 +1,47 @@
+-- Tamper-evident audit trail
+-- Migration: 008_audit_log.sql
+
+-- Append-only record of state-changing actions. Each entry's hash covers its
+-- contents and the hash of the previous entry, so any change to a stored
+-- entry breaks the chain from that point on.
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY,
+    sequence BIGINT NOT NULL UNIQUE CHECK (sequence > 0),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    auth_method VARCHAR(50) NOT NULL DEFAULT '',
+    action VARCHAR(255) NOT NULL,
+    resource_type VARCHAR(100) NOT NULL DEFAULT '',
+    resource_id VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    outcome VARCHAR(50) NOT NULL,
+    before JSONB,
+    after JSONB,
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    previous_hash CHAR(64) NOT NULL DEFAULT '',
+    hash CHAR(64) NOT NULL
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON audit_log(occurred_at DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor, sequence DESC);
+CREATE INDEX idx_audit_log_resource ON audit_log(resource_type, resource_id, sequence DESC);
+
+-- Reject changes to recorded entries, including by the table owner
+CREATE OR REPLACE FUNCTION reject_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
+
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change();
+
+-- Administrators may read the audit trail
+UPDATE roles SET permissions = array_append(permissions, 'audit:read')
+    WHERE name = 'admin' AND NOT ('audit:read' = ANY(permissions));
+
+GRANT SELECT, INSERT ON audit_log TO lab_gateway_user;
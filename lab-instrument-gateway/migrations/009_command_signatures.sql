@@ -0,0 +1,36 @@
+-- Electronic signatures on commands
+-- Migration: 009_command_signatures.sql
+
+-- Signatures a command was submitted with. Each records who signed, what the
+-- signature means and a hash of the command content that was signed.
+CREATE TABLE command_signatures (
+    id UUID PRIMARY KEY,
+    command_id UUID NOT NULL REFERENCES commands(id) ON DELETE CASCADE,
+    signer VARCHAR(255) NOT NULL,
+    auth_method VARCHAR(50) NOT NULL DEFAULT '',
+    meaning VARCHAR(50) NOT NULL CHECK (meaning IN ('authored', 'reviewed', 'approved', 'verified')),
+    comment TEXT NOT NULL DEFAULT '',
+    content_hash CHAR(64) NOT NULL,
+    signed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (command_id, signer)
+);
+
+CREATE INDEX idx_command_signatures_command_id ON command_signatures(command_id);
+CREATE INDEX idx_command_signatures_signer ON command_signatures(signer, signed_at DESC);
+
+-- Signatures cannot be altered once recorded
+CREATE OR REPLACE FUNCTION reject_command_signature_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'command signatures cannot be modified';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER command_signatures_immutable BEFORE UPDATE ON command_signatures
+    FOR EACH ROW EXECUTE FUNCTION reject_command_signature_change();
+
+-- Operators and administrators may sign commands
+UPDATE roles SET permissions = array_append(permissions, 'commands:sign')
+    WHERE name IN ('operator', 'admin') AND NOT ('commands:sign' = ANY(permissions));
+
+GRANT SELECT, INSERT, DELETE ON command_signatures TO lab_gateway_user;
@@ -0,0 +1,9 @@
+-- Single-use signing credentials
+-- Migration: 010_signature_tokens.sql
+
+-- The credential each signature was given with. A signer's credential signs
+-- only one command, so a captured token cannot be replayed to sign another.
+ALTER TABLE command_signatures ADD COLUMN token_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_command_signatures_token ON command_signatures(signer, token_id)
+    WHERE token_id IS NOT NULL;
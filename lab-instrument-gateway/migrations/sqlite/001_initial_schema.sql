@@ -0,0 +1,131 @@
+-- Initial schema for Lab Instrument Gateway, SQLite edition
+-- Migration: 001_initial_schema.sql
+--
+-- Mirrors ../001_initial_schema.sql. SQLite has no enum, JSONB or array
+-- types: enums are TEXT checked against their values, JSON documents and
+-- arrays are TEXT holding JSON, UUIDs are TEXT and timestamps are UTC text
+-- of fixed width (2006-01-02T15:04:05.000000Z) so that they sort in time
+-- order. The repositories set every timestamp and updated_at themselves.
+
+-- Devices table
+CREATE TABLE devices (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    version VARCHAR(50),
+    status TEXT DEFAULT 'unknown'
+        CHECK (status IN ('unknown', 'online', 'offline', 'error', 'maintenance', 'connecting')),
+    metadata TEXT DEFAULT '{}' CHECK (json_valid(metadata)),
+    capabilities TEXT DEFAULT '[]' CHECK (json_valid(capabilities)),
+    last_seen TIMESTAMP,
+    registered_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    created_at TIMESTAMP
+);
+
+-- Device sessions table for tracking active connections
+CREATE TABLE device_sessions (
+    id TEXT PRIMARY KEY,
+    device_id VARCHAR(255) NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    session_id VARCHAR(255) NOT NULL UNIQUE,
+    stream_id VARCHAR(255),
+    connected_at TIMESTAMP,
+    last_heartbeat TIMESTAMP,
+    metadata TEXT DEFAULT '{}' CHECK (json_valid(metadata)),
+    is_active BOOLEAN DEFAULT 1
+);
+
+-- Measurements table. A single bench does not need the monthly range
+-- partitions of the PostgreSQL schema; the timestamp indexes serve the
+-- time-range queries and DeleteOlderThan replaces dropping partitions.
+CREATE TABLE measurements (
+    id TEXT PRIMARY KEY,
+    device_id VARCHAR(255) NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    timestamp TIMESTAMP NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    unit VARCHAR(50),
+    quality TEXT DEFAULT 'unknown'
+        CHECK (quality IN ('unknown', 'good', 'bad', 'uncertain', 'substituted')),
+    metadata TEXT DEFAULT '{}' CHECK (json_valid(metadata)),
+    batch_id VARCHAR(255),
+    sequence_number INTEGER,
+    created_at TIMESTAMP
+);
+
+-- Commands table
+CREATE TABLE commands (
+    id TEXT PRIMARY KEY,
+    device_id VARCHAR(255) NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    command_id VARCHAR(255) NOT NULL UNIQUE,
+    type VARCHAR(100) NOT NULL,
+    parameters TEXT DEFAULT '{}' CHECK (json_valid(parameters)),
+    status TEXT DEFAULT 'pending'
+        CHECK (status IN ('unknown', 'pending', 'executing', 'completed', 'failed', 'timeout', 'cancelled')),
+    priority INTEGER DEFAULT 0,
+    timeout_seconds INTEGER DEFAULT 30,
+    result TEXT DEFAULT '{}' CHECK (json_valid(result)),
+    error_message TEXT,
+    submitted_at TIMESTAMP,
+    executed_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    execution_time_ms DOUBLE PRECISION,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+-- Alerts table for system notifications
+CREATE TABLE alerts (
+    id TEXT PRIMARY KEY,
+    device_id VARCHAR(255) REFERENCES devices(id) ON DELETE CASCADE,
+    type VARCHAR(100) NOT NULL,
+    severity VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    metadata TEXT DEFAULT '{}' CHECK (json_valid(metadata)),
+    acknowledged BOOLEAN DEFAULT 0,
+    acknowledged_at TIMESTAMP,
+    acknowledged_by VARCHAR(255),
+    created_at TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+-- Create indexes for performance optimization
+
+-- Device indexes
+CREATE INDEX idx_devices_status ON devices(status);
+CREATE INDEX idx_devices_type ON devices(type);
+CREATE INDEX idx_devices_last_seen ON devices(last_seen);
+
+-- Device sessions indexes
+CREATE INDEX idx_device_sessions_device_id ON device_sessions(device_id);
+CREATE INDEX idx_device_sessions_active ON device_sessions(is_active);
+CREATE INDEX idx_device_sessions_last_heartbeat ON device_sessions(last_heartbeat);
+
+-- Measurements indexes
+CREATE INDEX idx_measurements_device_timestamp ON measurements(device_id, timestamp DESC);
+CREATE INDEX idx_measurements_type_timestamp ON measurements(type, timestamp DESC);
+CREATE INDEX idx_measurements_timestamp ON measurements(timestamp DESC);
+CREATE INDEX idx_measurements_batch_id ON measurements(batch_id);
+
+-- Commands indexes
+CREATE INDEX idx_commands_device_id ON commands(device_id);
+CREATE INDEX idx_commands_status ON commands(status);
+CREATE INDEX idx_commands_submitted_at ON commands(submitted_at DESC);
+CREATE INDEX idx_commands_expires_at ON commands(expires_at);
+
+-- Alerts indexes
+CREATE INDEX idx_alerts_device_id ON alerts(device_id);
+CREATE INDEX idx_alerts_type ON alerts(type);
+CREATE INDEX idx_alerts_severity ON alerts(severity);
+CREATE INDEX idx_alerts_acknowledged ON alerts(acknowledged);
+CREATE INDEX idx_alerts_created_at ON alerts(created_at DESC);
+
+-- Create initial data for testing (optional)
+INSERT INTO devices (id, name, type, version, status, capabilities, registered_at, updated_at, created_at) VALUES
+    ('device-001', 'Spectrometer Alpha', 'spectrometer', '1.2.3', 'offline', '["measurement","calibration"]',
+        strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z')),
+    ('device-002', 'Microscope Beta', 'microscope', '2.1.0', 'offline', '["imaging","measurement"]',
+        strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z')),
+    ('device-003', 'Analyzer Gamma', 'analyzer', '1.0.5', 'offline', '["analysis","reporting"]',
+        strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z'));
@@ -0,0 +1,44 @@
+-- Alert escalation policies, routes and on-call schedules
+-- Migration: 003_alert_escalation.sql
+
+-- Weekly on-call rotations; the participant on call advances every 7 days from rotation_start
+CREATE TABLE on_call_schedules (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    rotation_start TIMESTAMP NOT NULL,
+    participants TEXT NOT NULL CHECK (json_valid(participants)),
+    created_at TIMESTAMP,
+    CONSTRAINT on_call_schedules_participants CHECK (json_array_length(participants) > 0)
+);
+
+-- Escalation policies hold an ordered list of steps, each with a delay measured from alert creation
+CREATE TABLE escalation_policies (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    steps TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(steps)),
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+-- Alert routes attach a policy to alerts matching all of their non-null matchers
+CREATE TABLE alert_routes (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    device_type VARCHAR(100),
+    alert_type VARCHAR(100),
+    min_severity VARCHAR(50),
+    policy_id TEXT NOT NULL REFERENCES escalation_policies(id) ON DELETE CASCADE,
+    created_at TIMESTAMP
+);
+
+CREATE INDEX idx_alert_routes_priority ON alert_routes(priority);
+
+-- Escalation progress per alert; level is the number of steps already notified
+CREATE TABLE alert_escalations (
+    alert_id TEXT PRIMARY KEY REFERENCES alerts(id) ON DELETE CASCADE,
+    policy_id TEXT REFERENCES escalation_policies(id) ON DELETE SET NULL,
+    level INTEGER NOT NULL DEFAULT 0,
+    last_escalated_at TIMESTAMP NOT NULL
+);
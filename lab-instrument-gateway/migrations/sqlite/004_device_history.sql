@@ -0,0 +1,26 @@
+-- Device lifecycle event log and connection history
+-- Migration: 004_device_history.sql
+
+-- Sessions are kept after they end so that connection history can be reconstructed
+ALTER TABLE device_sessions ADD COLUMN disconnected_at TIMESTAMP;
+ALTER TABLE device_sessions ADD COLUMN disconnect_reason TEXT;
+
+CREATE INDEX idx_device_sessions_connected_at ON device_sessions(device_id, connected_at DESC);
+
+-- Append-only log of device lifecycle events
+CREATE TABLE device_events (
+    id TEXT PRIMARY KEY,
+    device_id VARCHAR(255) NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    session_id VARCHAR(255),
+    from_status TEXT
+        CHECK (from_status IN ('unknown', 'online', 'offline', 'error', 'maintenance', 'connecting')),
+    to_status TEXT
+        CHECK (to_status IN ('unknown', 'online', 'offline', 'error', 'maintenance', 'connecting')),
+    message TEXT,
+    metadata TEXT DEFAULT '{}' CHECK (json_valid(metadata)),
+    occurred_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_device_events_device_time ON device_events(device_id, occurred_at DESC);
+CREATE INDEX idx_device_events_type ON device_events(type);
@@ -0,0 +1,42 @@
+-- Role-based access control
+-- Migration: 005_rbac.sql
+
+-- Roles are named sets of permissions; built-in roles are seeded below
+CREATE TABLE roles (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT,
+    permissions TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(permissions)),
+    built_in BOOLEAN NOT NULL DEFAULT 0,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+-- Role bindings grant a role to a subject for all devices (device_group NULL) or for one device group
+CREATE TABLE role_bindings (
+    id TEXT PRIMARY KEY,
+    subject VARCHAR(255) NOT NULL,
+    role VARCHAR(100) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    device_group VARCHAR(255),
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_role_bindings_unique ON role_bindings(subject, role, COALESCE(device_group, ''));
+CREATE INDEX idx_role_bindings_subject ON role_bindings(subject);
+
+-- Devices are scoped to a group through their device_group metadata key
+CREATE INDEX idx_devices_device_group ON devices(json_extract(metadata, '$.device_group'));
+
+INSERT INTO roles (name, description, permissions, built_in, created_at, updated_at) VALUES
+    ('viewer', 'Read devices, measurements and alerts',
+        '["devices:read","measurements:read","alerts:read"]', 1,
+        strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z')),
+    ('operator', 'Viewer permissions and sending commands',
+        '["devices:read","measurements:read","alerts:read","commands:send"]', 1,
+        strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z')),
+    ('admin', 'Full access, including device registration, alert management and access control',
+        '["devices:read","devices:write","measurements:read","measurements:write","commands:send","alerts:read","alerts:manage","access:manage"]', 1,
+        strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z')),
+    ('device', 'Granted to instruments authenticated by client certificate',
+        '["devices:write","measurements:write"]', 1,
+        strftime('%Y-%m-%dT%H:%M:%f000Z'), strftime('%Y-%m-%dT%H:%M:%f000Z'));
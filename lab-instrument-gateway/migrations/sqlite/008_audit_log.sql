@@ -0,0 +1,43 @@
+-- Tamper-evident audit trail
+-- Migration: 008_audit_log.sql
+
+-- Append-only record of state-changing actions. Each entry's hash covers its
+-- contents and the hash of the previous entry, so any change to a stored
+-- entry breaks the chain from that point on.
+CREATE TABLE audit_log (
+    id TEXT PRIMARY KEY,
+    sequence BIGINT NOT NULL UNIQUE CHECK (sequence > 0),
+    occurred_at TIMESTAMP NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    auth_method VARCHAR(50) NOT NULL DEFAULT '',
+    action VARCHAR(255) NOT NULL,
+    resource_type VARCHAR(100) NOT NULL DEFAULT '',
+    resource_id VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    outcome VARCHAR(50) NOT NULL,
+    before TEXT CHECK (json_valid(before)),
+    after TEXT CHECK (json_valid(after)),
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    previous_hash CHAR(64) NOT NULL DEFAULT '',
+    hash CHAR(64) NOT NULL
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON audit_log(occurred_at DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor, sequence DESC);
+CREATE INDEX idx_audit_log_resource ON audit_log(resource_type, resource_id, sequence DESC);
+
+-- Reject changes to recorded entries. SQLite has no TRUNCATE; DELETE
+-- without a WHERE clause fires the delete trigger like any other.
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+-- Administrators may read the audit trail
+UPDATE roles SET permissions = json_insert(permissions, '$[#]', 'audit:read')
+    WHERE name = 'admin' AND NOT EXISTS (SELECT 1 FROM json_each(permissions) WHERE value = 'audit:read');
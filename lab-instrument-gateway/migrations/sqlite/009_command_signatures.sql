@@ -0,0 +1,29 @@
+-- Electronic signatures on commands
+-- Migration: 009_command_signatures.sql
+
+-- Signatures a command was submitted with. Each records who signed, what the
+-- signature means and a hash of the command content that was signed.
+CREATE TABLE command_signatures (
+    id TEXT PRIMARY KEY,
+    command_id TEXT NOT NULL REFERENCES commands(id) ON DELETE CASCADE,
+    signer VARCHAR(255) NOT NULL,
+    auth_method VARCHAR(50) NOT NULL DEFAULT '',
+    meaning VARCHAR(50) NOT NULL CHECK (meaning IN ('authored', 'reviewed', 'approved', 'verified')),
+    comment TEXT NOT NULL DEFAULT '',
+    content_hash CHAR(64) NOT NULL,
+    signed_at TIMESTAMP NOT NULL,
+    UNIQUE (command_id, signer)
+);
+
+CREATE INDEX idx_command_signatures_command_id ON command_signatures(command_id);
+CREATE INDEX idx_command_signatures_signer ON command_signatures(signer, signed_at DESC);
+
+-- Signatures cannot be altered once recorded
+CREATE TRIGGER command_signatures_immutable BEFORE UPDATE ON command_signatures
+BEGIN
+    SELECT RAISE(ABORT, 'command signatures cannot be modified');
+END;
+
+-- Operators and administrators may sign commands
+UPDATE roles SET permissions = json_insert(permissions, '$[#]', 'commands:sign')
+    WHERE name IN ('operator', 'admin') AND NOT EXISTS (SELECT 1 FROM json_each(permissions) WHERE value = 'commands:sign');
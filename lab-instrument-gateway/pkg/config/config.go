@@ -3,11 +3,16 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/secrets"
+	"github.com/yourorg/lab-gateway/pkg/tracing"
 )
 
 // Config holds all configuration for the application
 type Config struct {
+	Environment string
 	Server   ServerConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
@@ -15,6 +20,12 @@ type Config struct {
 	Metrics  MetricsConfig
 	Security SecurityConfig
 	Performance PerformanceConfig
+	Alerting AlertingConfig
+	Device   DeviceConfig
+	Ingest   IngestConfig
+	Provisioning ProvisioningConfig
+	Secrets  secrets.Config
+	Tracing  tracing.Config
 }
 
 // ServerConfig holds server-related configuration
@@ -25,24 +36,84 @@ type ServerConfig struct {
 	TLSCert  string
 	TLSKey   string
 	TLSCA    string
+
+	// ShutdownTimeout is how long open calls and streams may drain on
+	// shutdown before they are cut off
+	ShutdownTimeout time.Duration
+
+	// AdminEnabled serves the /livez and /readyz probes, and metrics if
+	// enabled, over HTTP on the metrics port
+	AdminEnabled bool
+
+	// HealthCheckInterval is how often the database, ingest pipeline,
+	// connection manager and notifier are checked for readiness;
+	// HealthCheckTimeout bounds each check
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
 }
 
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
+	// Driver selects the repository implementation, DriverPostgres,
+	// DriverSQLite or DriverMemory
+	Driver string
+
+	// Path is the database file of the SQLite driver
+	Path string
+
 	Host     string
 	Port     int
 	Name     string
 	User     string
 	Password string
 	SSLMode  string
+
+	// CredentialsRefreshInterval is how often rotated credentials are read
+	// from the secrets provider; zero disables the refresh
+	CredentialsRefreshInterval time.Duration
+
+	// WaitTimeout is how long the gateway waits for the database on startup
+	WaitTimeout time.Duration
+
+	// AutoMigrate applies pending migrations from MigrationsPath on startup
+	AutoMigrate    bool
+	MigrationsPath string
+
+	// TxIsolation is the isolation level of repository transactions:
+	// read_committed, repeatable_read or serializable, or empty for the
+	// database default. Transactions failing on serialization failures or
+	// deadlocks are retried up to TxMaxRetries times.
+	TxIsolation  string
+	TxMaxRetries int
+
+	// ReplicaDSNs are the connection strings of read replicas of a
+	// PostgreSQL primary, serving the queries over measurement, event and
+	// audit history. Replicas are checked every ReplicaCheckInterval and
+	// skipped while unreachable or lagging more than ReplicaMaxLag behind.
+	ReplicaDSNs          []string
+	ReplicaMaxLag        time.Duration
+	ReplicaCheckInterval time.Duration
 }
 
+// Repository implementations. SQLite keeps the data of a single gateway
+// in a local file, for benches without a database server. The memory
+// implementation keeps data only while the gateway runs and is meant for
+// development and tests.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
 // RedisConfig holds Redis connection configuration
 type RedisConfig struct {
 	Host     string
 	Port     int
 	Password string
 	DB       int
+
+	// KeyPrefix namespaces the gateway's keys and channels
+	KeyPrefix string
 }
 
 // LoggingConfig holds logging configuration
@@ -56,14 +127,40 @@ type MetricsConfig struct {
 	Port    int
 	Path    string
 	Enabled bool
+
+	// PublishInterval is how often sampled gauges, such as device counts
+	// by status, are refreshed
+	PublishInterval time.Duration
 }
 
 // SecurityConfig holds security-related configuration
 type SecurityConfig struct {
-	JWTSecret           string
-	RateLimitRequests   int
-	RateLimitWindow     time.Duration
-	TLSEnabled          bool
+	JWTSecret               string
+	JWTJWKSFile             string
+	JWTIssuer               string
+	JWTAudience             string
+	AuthEnabled             bool
+	RBACEnabled             bool
+	RBACCacheTTL            time.Duration
+	AuditFailClosed         bool
+	RateLimitEnabled        bool
+	RateLimitRequests       int
+	RateLimitWindow         time.Duration
+	RateLimitBurst          int
+	RateLimitDeviceRequests int
+	RateLimitDeviceWindow   time.Duration
+	RateLimitMethods        string
+	StreamRateLimitMessages int
+	StreamRateLimitWindow   time.Duration
+	StreamRateLimitMaxDelay time.Duration
+	SignatureRequirements   string
+	SignatureMaxTokenAge    time.Duration
+	FieldEncryptionKeyFile  string
+	FieldEncryptionFields   string
+	FieldEncryptionKeyTTL   time.Duration
+	TLSEnabled              bool
+	TLSClientAuth           string
+	TLSReloadInterval       time.Duration
 }
 
 // PerformanceConfig holds performance-related configuration
@@ -75,9 +172,54 @@ type PerformanceConfig struct {
 	KeepaliveTimeout     time.Duration
 }
 
+// AlertingConfig holds alert delivery and escalation configuration
+type AlertingConfig struct {
+	EscalationInterval time.Duration
+}
+
+// DeviceConfig holds device connectivity configuration
+type DeviceConfig struct {
+	HeartbeatTimeout       time.Duration
+	HeartbeatCheckInterval time.Duration
+
+	// Registry is local, or redis to share device connections between
+	// replicas and forward commands to the replica holding a device's
+	// stream. ReplicaID defaults to the host name; registrations expire
+	// RegistryTTL after the replica stops refreshing them.
+	Registry    string
+	ReplicaID   string
+	RegistryTTL time.Duration
+}
+
+// IngestConfig holds measurement ingest and anomaly detection configuration
+type IngestConfig struct {
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxBuffered   int
+	
+	AnomalyDetection       bool
+	AnomalyWindowSize      int
+	AnomalyWarmupSamples   int
+	AnomalyZScoreThreshold float64
+	AnomalyEWMALambda      float64
+	AnomalyEWMAWidth       float64
+	AnomalyRules           string
+	AnomalyAlertCooldown   time.Duration
+}
+
+// ProvisioningConfig holds device enrollment configuration
+type ProvisioningConfig struct {
+	CACertFile          string
+	CAKeyFile           string
+	EnrollmentTokenTTL  time.Duration
+	CertificateValidity time.Duration
+	APIKeyValidity      time.Duration
+}
+
 // Load loads configuration from environment variables
 func Load() *Config {
 	return &Config{
+		Environment: getEnv("APP_ENV", EnvironmentProduction),
 		Server: ServerConfig{
 			Host:     getEnv("SERVER_HOST", "0.0.0.0"),
 			Port:     getEnvAsInt("SERVER_PORT", 8080),
@@ -85,20 +227,40 @@ func Load() *Config {
 			TLSCert:  getEnv("TLS_CERT_FILE", ""),
 			TLSKey:   getEnv("TLS_KEY_FILE", ""),
 			TLSCA:    getEnv("TLS_CA_FILE", ""),
+
+			ShutdownTimeout: getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+			AdminEnabled:        getEnvAsBool("ADMIN_ENABLED", true),
+			HealthCheckInterval: getEnvAsDuration("HEALTH_CHECK_INTERVAL", 10*time.Second),
+			HealthCheckTimeout:  getEnvAsDuration("HEALTH_CHECK_TIMEOUT", 5*time.Second),
 		},
 		Database: DatabaseConfig{
+			Driver:   getEnv("DB_DRIVER", DriverPostgres),
+			Path:     getEnv("DB_PATH", "./lab-gateway.db"),
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnvAsInt("DB_PORT", 5432),
 			Name:     getEnv("DB_NAME", "lab_instruments"),
 			User:     getEnv("DB_USER", "user"),
 			Password: getEnv("DB_PASSWORD", "password"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+			CredentialsRefreshInterval: getEnvAsDuration("DB_CREDENTIALS_REFRESH_INTERVAL", 0),
+			WaitTimeout:                getEnvAsDuration("DB_WAIT_TIMEOUT", time.Minute),
+			AutoMigrate:                getEnvAsBool("DB_AUTO_MIGRATE", false),
+			MigrationsPath:             getEnv("DB_MIGRATIONS_PATH", "./migrations"),
+			TxIsolation:                getEnv("DB_TX_ISOLATION", ""),
+			TxMaxRetries:               getEnvAsInt("DB_TX_MAX_RETRIES", 3),
+			ReplicaDSNs:                getEnvAsList("DB_REPLICA_DSNS"),
+			ReplicaMaxLag:              getEnvAsDuration("DB_REPLICA_MAX_LAG", 5*time.Second),
+			ReplicaCheckInterval:       getEnvAsDuration("DB_REPLICA_CHECK_INTERVAL", 10*time.Second),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
 			Port:     getEnvAsInt("REDIS_PORT", 6379),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
+
+			KeyPrefix: getEnv("REDIS_KEY_PREFIX", "lab-gateway:"),
 		},
 		Logging: LoggingConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -108,12 +270,36 @@ func Load() *Config {
 			Port:    getEnvAsInt("METRICS_PORT", 8081),
 			Path:    getEnv("METRICS_PATH", "/metrics"),
 			Enabled: getEnvAsBool("PROMETHEUS_ENABLED", true),
+
+			PublishInterval: getEnvAsDuration("METRICS_PUBLISH_INTERVAL", 15*time.Second),
 		},
 		Security: SecurityConfig{
-			JWTSecret:           getEnv("JWT_SECRET", "your-jwt-secret-key"),
-			RateLimitRequests:   getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
-			RateLimitWindow:     getEnvAsDuration("RATE_LIMIT_WINDOW", time.Minute),
-			TLSEnabled:          getEnvAsBool("TLS_ENABLED", false),
+			JWTSecret:               getEnv("JWT_SECRET", "your-jwt-secret-key"),
+			JWTJWKSFile:             getEnv("JWT_JWKS_FILE", ""),
+			JWTIssuer:               getEnv("JWT_ISSUER", ""),
+			JWTAudience:             getEnv("JWT_AUDIENCE", ""),
+			AuthEnabled:             getEnvAsBool("AUTH_ENABLED", true),
+			RBACEnabled:             getEnvAsBool("RBAC_ENABLED", true),
+			RBACCacheTTL:            getEnvAsDuration("RBAC_CACHE_TTL", 30*time.Second),
+			AuditFailClosed:         getEnvAsBool("AUDIT_FAIL_CLOSED", true),
+			RateLimitEnabled:        getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			RateLimitRequests:       getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
+			RateLimitWindow:         getEnvAsDuration("RATE_LIMIT_WINDOW", time.Minute),
+			RateLimitBurst:          getEnvAsInt("RATE_LIMIT_BURST", 0),
+			RateLimitDeviceRequests: getEnvAsInt("RATE_LIMIT_DEVICE_REQUESTS", 300),
+			RateLimitDeviceWindow:   getEnvAsDuration("RATE_LIMIT_DEVICE_WINDOW", time.Minute),
+			RateLimitMethods:        getEnv("RATE_LIMIT_METHODS", ""),
+			StreamRateLimitMessages: getEnvAsInt("STREAM_RATE_LIMIT_MESSAGES", 100),
+			StreamRateLimitWindow:   getEnvAsDuration("STREAM_RATE_LIMIT_WINDOW", time.Second),
+			StreamRateLimitMaxDelay: getEnvAsDuration("STREAM_RATE_LIMIT_MAX_DELAY", time.Second),
+			SignatureRequirements:   getEnv("SIGNATURE_REQUIREMENTS", ""),
+			SignatureMaxTokenAge:    getEnvAsDuration("SIGNATURE_MAX_TOKEN_AGE", 5*time.Minute),
+			FieldEncryptionKeyFile:  getEnv("FIELD_ENCRYPTION_KEY_FILE", ""),
+			FieldEncryptionFields:   getEnv("FIELD_ENCRYPTION_FIELDS", ""),
+			FieldEncryptionKeyTTL:   getEnvAsDuration("FIELD_ENCRYPTION_DATA_KEY_TTL", 10*time.Minute),
+			TLSEnabled:              getEnvAsBool("TLS_ENABLED", false),
+			TLSClientAuth:           getEnv("TLS_CLIENT_AUTH", ""),
+			TLSReloadInterval:       getEnvAsDuration("TLS_RELOAD_INTERVAL", time.Minute),
 		},
 		Performance: PerformanceConfig{
 			MaxConcurrentStreams: getEnvAsInt("MAX_CONCURRENT_STREAMS", 1000),
@@ -122,6 +308,58 @@ func Load() *Config {
 			KeepaliveTime:        getEnvAsDuration("KEEPALIVE_TIME", 30*time.Second),
 			KeepaliveTimeout:     getEnvAsDuration("KEEPALIVE_TIMEOUT", 5*time.Second),
 		},
+		Alerting: AlertingConfig{
+			EscalationInterval: getEnvAsDuration("ALERT_ESCALATION_INTERVAL", 30*time.Second),
+		},
+		Device: DeviceConfig{
+			HeartbeatTimeout:       getEnvAsDuration("DEVICE_HEARTBEAT_TIMEOUT", 25*time.Second),
+			HeartbeatCheckInterval: getEnvAsDuration("DEVICE_HEARTBEAT_CHECK_INTERVAL", 5*time.Second),
+
+			Registry:    getEnv("DEVICE_REGISTRY", "local"),
+			ReplicaID:   getEnv("REPLICA_ID", ""),
+			RegistryTTL: getEnvAsDuration("DEVICE_REGISTRY_TTL", time.Minute),
+		},
+		Ingest: IngestConfig{
+			BatchSize:              getEnvAsInt("INGEST_BATCH_SIZE", 500),
+			FlushInterval:          getEnvAsDuration("INGEST_FLUSH_INTERVAL", time.Second),
+			MaxBuffered:            getEnvAsInt("INGEST_MAX_BUFFERED", 10000),
+			AnomalyDetection:       getEnvAsBool("ANOMALY_DETECTION_ENABLED", false),
+			AnomalyWindowSize:      getEnvAsInt("ANOMALY_WINDOW_SIZE", 50),
+			AnomalyWarmupSamples:   getEnvAsInt("ANOMALY_WARMUP_SAMPLES", 30),
+			AnomalyZScoreThreshold: getEnvAsFloat("ANOMALY_ZSCORE_THRESHOLD", 4.0),
+			AnomalyEWMALambda:      getEnvAsFloat("ANOMALY_EWMA_LAMBDA", 0.2),
+			AnomalyEWMAWidth:       getEnvAsFloat("ANOMALY_EWMA_WIDTH", 3.0),
+			AnomalyRules:           getEnv("ANOMALY_RULES", "western_electric"),
+			AnomalyAlertCooldown:   getEnvAsDuration("ANOMALY_ALERT_COOLDOWN", 15*time.Minute),
+		},
+		Provisioning: ProvisioningConfig{
+			CACertFile:          getEnv("PROVISIONING_CA_CERT_FILE", ""),
+			CAKeyFile:           getEnv("PROVISIONING_CA_KEY_FILE", ""),
+			EnrollmentTokenTTL:  getEnvAsDuration("ENROLLMENT_TOKEN_TTL", 24*time.Hour),
+			CertificateValidity: getEnvAsDuration("DEVICE_CERT_VALIDITY", 365*24*time.Hour),
+			APIKeyValidity:      getEnvAsDuration("DEVICE_API_KEY_VALIDITY", 0),
+		},
+		Secrets: secrets.Config{
+			Backend:        getEnv("SECRETS_BACKEND", secrets.BackendEnv),
+			Dir:            getEnv("SECRETS_DIR", "/run/secrets"),
+			VaultAddress:   getEnv("VAULT_ADDR", ""),
+			VaultToken:     getEnv("VAULT_TOKEN", ""),
+			VaultTokenFile: getEnv("VAULT_TOKEN_FILE", ""),
+			VaultNamespace: getEnv("VAULT_NAMESPACE", ""),
+			VaultMount:     getEnv("VAULT_KV_MOUNT", "secret"),
+			VaultPath:      getEnv("VAULT_SECRET_PATH", ""),
+			VaultKVVersion: getEnvAsInt("VAULT_KV_VERSION", 2),
+			Timeout:        getEnvAsDuration("VAULT_TIMEOUT", 10*time.Second),
+		},
+		Tracing: tracing.Config{
+			Enabled:       getEnvAsBool("TRACING_ENABLED", false),
+			Endpoint:      getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+			Insecure:      getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", false),
+			ServiceName:   getEnv("OTEL_SERVICE_NAME", "lab-gateway"),
+			Sampler:       getEnv("OTEL_TRACES_SAMPLER", tracing.SamplerParentBasedAlwaysOn),
+			SampleRatio:   getEnvAsFloat("OTEL_TRACES_SAMPLER_ARG", 1.0),
+			ExportTimeout: getEnvAsDuration("OTEL_EXPORTER_OTLP_TIMEOUT", 10*time.Second),
+		},
 	}
 }
 
@@ -152,6 +390,15 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -159,4 +406,15 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 		}
 	}
 	return defaultValue
+}
+
+// getEnvAsList splits a comma separated variable, dropping empty items
+func getEnvAsList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
\ No newline at end of file
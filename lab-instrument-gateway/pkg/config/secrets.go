@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/lab-gateway/pkg/secrets"
+)
+
+// Environments the gateway runs in. Insecure defaults are only accepted in
+// development.
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentProduction  = "production"
+)
+
+// insecureValues are the built-in defaults of secrets, which are public and
+// must be replaced outside development
+var insecureValues = map[string]bool{
+	"your-jwt-secret-key": true,
+	"password":            true,
+}
+
+// insecurePlaceholderPrefix marks the placeholders of .env.example
+const insecurePlaceholderPrefix = "CHANGE_ME"
+
+// secretFields maps the secrets read from the secrets provider to the
+// configuration they set
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"DB_USER":        &c.Database.User,
+		"DB_PASSWORD":    &c.Database.Password,
+		"REDIS_PASSWORD": &c.Redis.Password,
+		"JWT_SECRET":     &c.Security.JWTSecret,
+	}
+}
+
+// IsDevelopment reports whether the gateway runs in development
+func (c *Config) IsDevelopment() bool {
+	return strings.EqualFold(c.Environment, EnvironmentDevelopment)
+}
+
+// ResolveSecrets reads the secrets of the configuration from the configured
+// secrets provider, keeping the values from environment variables for
+// secrets the provider does not hold. The provider is returned for reading
+// rotated secrets later.
+func (c *Config) ResolveSecrets(ctx context.Context) (secrets.Provider, error) {
+	provider, err := secrets.New(c.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secrets provider: %w", err)
+	}
+
+	for name, field := range c.secretFields() {
+		value, err := provider.Get(ctx, name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %s: %w", name, err)
+		}
+		*field = value
+	}
+
+	return provider, nil
+}
+
+// Validate refuses configurations that use insecure default secrets outside
+// development
+func (c *Config) Validate() error {
+	if c.IsDevelopment() {
+		return nil
+	}
+
+	var insecure []string
+	for name, field := range c.secretFields() {
+		if isInsecureSecret(*field) {
+			insecure = append(insecure, name)
+		}
+	}
+	if len(insecure) == 0 {
+		return nil
+	}
+
+	sort.Strings(insecure)
+
+	return fmt.Errorf("insecure default values for %s are not allowed in the %s environment; set them or APP_ENV=%s",
+		strings.Join(insecure, ", "), c.Environment, EnvironmentDevelopment)
+}
+
+// isInsecureSecret reports whether a secret is a built-in default or an
+// example placeholder
+func isInsecureSecret(value string) bool {
+	return insecureValues[value] || strings.HasPrefix(strings.ToUpper(value), insecurePlaceholderPrefix)
+}
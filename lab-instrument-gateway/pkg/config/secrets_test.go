@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/secrets"
+)
+
+func TestValidate_InsecureDefaults(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	t.Setenv("DB_PASSWORD", "")
+	t.Setenv("JWT_SECRET", "")
+
+	cfg := Load()
+	assert.Equal(t, EnvironmentProduction, cfg.Environment)
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DB_PASSWORD, JWT_SECRET")
+
+	cfg.Environment = "Development"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Environment = "staging"
+	cfg.Database.Password = "CHANGE_ME_STRONG_PASSWORD"
+	cfg.Security.JWTSecret = "3f9c1b7e2d8a4f6c0e5b9d1a7c3e8f2b"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "JWT_SECRET")
+
+	cfg.Database.Password = "Zq8#vL2!pR6t"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db_password"), []byte("from-file\n"), 0600))
+
+	t.Setenv("DB_USER", "gateway")
+	t.Setenv("DB_PASSWORD", "from-env")
+
+	cfg := Load()
+	cfg.Secrets = secrets.Config{Backend: secrets.BackendFile, Dir: dir}
+
+	provider, err := cfg.ResolveSecrets(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, provider)
+	assert.Equal(t, "from-file", cfg.Database.Password)
+	assert.Equal(t, "gateway", cfg.Database.User, "secrets the provider does not hold are kept")
+
+	cfg.Secrets = secrets.Config{Backend: secrets.BackendVault}
+	_, err = cfg.ResolveSecrets(context.Background())
+	assert.Error(t, err)
+}
@@ -3,19 +3,60 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"github.com/yourorg/lab-gateway/pkg/config"
 	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/secrets"
+	"github.com/yourorg/lab-gateway/pkg/tracing"
 )
 
 // ConnectionManager manages database connections with pooling and health checks
 type ConnectionManager struct {
+	mu     sync.RWMutex
 	db     *sql.DB
 	config *config.DatabaseConfig
 	logger *logger.Logger
+
+	// txDefaults are the options of transactions run without their own
+	txDefaults TxOptions
+
+	refreshStop chan struct{}
+	refreshDone chan struct{}
+
+	// replicas serve the queries run on Reader, taken in turn from
+	// nextReplica; they are checked until replicaStop is closed
+	replicas    []*replica
+	nextReplica uint32
+	replicaStop chan struct{}
+	replicaDone chan struct{}
+}
+
+// CredentialsFunc returns the current database user and password. An empty
+// user keeps the configured one.
+type CredentialsFunc func(ctx context.Context) (user, password string, err error)
+
+// ProviderCredentials reads rotated database credentials from the DB_USER
+// and DB_PASSWORD secrets of a secrets provider
+func ProviderCredentials(provider secrets.Provider) CredentialsFunc {
+	return func(ctx context.Context) (string, string, error) {
+		user, err := provider.Get(ctx, "DB_USER")
+		if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+			return "", "", err
+		}
+
+		password, err := provider.Get(ctx, "DB_PASSWORD")
+		if err != nil {
+			return "", "", err
+		}
+
+		return user, password, nil
+	}
 }
 
 // ConnectionStats holds connection pool statistics
@@ -32,21 +73,37 @@ type ConnectionStats struct {
 
 // NewConnectionManager creates a new database connection manager
 func NewConnectionManager(cfg *config.DatabaseConfig, log *logger.Logger) (*ConnectionManager, error) {
+	isolation, err := ParseIsolationLevel(cfg.TxIsolation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction isolation: %w", err)
+	}
+
+	// The manager keeps its own copy, updated when credentials are rotated
+	dbConfig := *cfg
 	cm := &ConnectionManager{
-		config: cfg,
+		config: &dbConfig,
 		logger: log,
+		txDefaults: TxOptions{
+			Isolation:  isolation,
+			MaxRetries: cfg.TxMaxRetries,
+		},
 	}
 
 	if err := cm.connect(); err != nil {
 		return nil, fmt.Errorf("failed to establish database connection: %w", err)
 	}
 
+	if err := cm.openReplicas(); err != nil {
+		cm.conn().Close()
+		return nil, err
+	}
+
 	return cm, nil
 }
 
 // connect establishes the database connection with retry logic
 func (cm *ConnectionManager) connect() error {
-	dsn := cm.buildDSN()
+	driver, dsn := cm.dataSource()
 	
 	var db *sql.DB
 	var err error
@@ -56,7 +113,7 @@ func (cm *ConnectionManager) connect() error {
 	baseDelay := time.Second
 	
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		db, err = sql.Open("postgres", dsn)
+		db, err = sql.Open(driver, dsn)
 		if err != nil {
 			cm.logger.WithError(err).Errorf("Failed to open database connection (attempt %d/%d)", attempt+1, maxRetries)
 			if attempt < maxRetries-1 {
@@ -91,27 +148,62 @@ func (cm *ConnectionManager) connect() error {
 	// Configure connection pool
 	cm.configureConnectionPool(db)
 	
+	cm.mu.Lock()
 	cm.db = db
+	cm.mu.Unlock()
 	cm.logger.Info("Database connection established successfully")
 	
 	return nil
 }
 
+// dataSource returns the database/sql driver name and connection string of
+// the configured database
+func (cm *ConnectionManager) dataSource() (string, string) {
+	if cm.config.Driver == config.DriverSQLite {
+		return sqliteDriverName, sqliteDSN(cm.config.Path)
+	}
+	return "postgres", cm.buildDSN()
+}
+
 // buildDSN constructs the database connection string
 func (cm *ConnectionManager) buildDSN() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return buildDSN(cm.config, cm.config.User, cm.config.Password)
+}
+
+// buildDSN constructs the connection string of a configuration with the
+// given credentials
+func buildDSN(cfg *config.DatabaseConfig, user, password string) string {
 	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cm.config.Host,
-		cm.config.Port,
-		cm.config.User,
-		cm.config.Password,
-		cm.config.Name,
-		cm.config.SSLMode,
+		cfg.Host,
+		cfg.Port,
+		quoteDSNValue(user),
+		quoteDSNValue(password),
+		cfg.Name,
+		cfg.SSLMode,
 	)
 }
 
+// quoteDSNValue quotes a connection string value, so that generated
+// credentials may contain spaces, quotes and backslashes
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
 // configureConnectionPool sets up connection pool parameters for high concurrency
 func (cm *ConnectionManager) configureConnectionPool(db *sql.DB) {
+	if cm.config.Driver == config.DriverSQLite {
+		configureSQLitePool(db)
+		return
+	}
+
 	// Set maximum number of open connections (for 1000+ concurrent connections)
 	db.SetMaxOpenConns(100)
 	
@@ -127,14 +219,128 @@ func (cm *ConnectionManager) configureConnectionPool(db *sql.DB) {
 	cm.logger.Info("Database connection pool configured for high concurrency")
 }
 
-// GetDB returns the database connection
-func (cm *ConnectionManager) GetDB() *sql.DB {
+// conn returns the current connection pool, which is replaced when the
+// database credentials are rotated
+func (cm *ConnectionManager) conn() *sql.DB {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 	return cm.db
 }
 
+// GetDB returns the database connection. Callers holding on to it keep the
+// pool of the credentials at the time of the call.
+func (cm *ConnectionManager) GetDB() *sql.DB {
+	return cm.conn()
+}
+
+// RefreshCredentials re-reads the database credentials from source every
+// interval and, when they changed, replaces the connection pool with one
+// using the new credentials. The old pool is closed once its queries have
+// finished. It runs until the manager is closed and may only be started once.
+func (cm *ConnectionManager) RefreshCredentials(source CredentialsFunc, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	cm.mu.Lock()
+	if cm.refreshStop != nil {
+		cm.mu.Unlock()
+		cm.logger.Warn("Database credentials refresh is already running")
+		return
+	}
+	cm.refreshStop = make(chan struct{})
+	cm.refreshDone = make(chan struct{})
+	stop, done := cm.refreshStop, cm.refreshDone
+	cm.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				if _, err := cm.refreshCredentials(ctx, source); err != nil {
+					cm.logger.WithError(err).Error("Failed to refresh database credentials")
+				}
+				cancel()
+			}
+		}
+	}()
+
+	cm.logger.WithFields(map[string]interface{}{
+		"interval": interval.String(),
+	}).Info("Database credentials refresh started")
+}
+
+// refreshCredentials replaces the connection pool if the credentials from
+// source differ from the current ones, reporting whether it did. The pool
+// is only replaced once the new credentials are accepted by the database.
+func (cm *ConnectionManager) refreshCredentials(ctx context.Context, source CredentialsFunc) (bool, error) {
+	user, password, err := source(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to read database credentials: %w", err)
+	}
+
+	cm.mu.RLock()
+	if user == "" {
+		user = cm.config.User
+	}
+	unchanged := user == cm.config.User && password == cm.config.Password
+	dsn := buildDSN(cm.config, user, password)
+	cm.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return false, fmt.Errorf("failed to open database with rotated credentials: %w", err)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	err = db.PingContext(pingCtx)
+	cancel()
+	if err != nil {
+		db.Close()
+		return false, fmt.Errorf("rotated database credentials were rejected: %w", err)
+	}
+
+	cm.configureConnectionPool(db)
+
+	cm.mu.Lock()
+	old := cm.db
+	cm.db = db
+	cm.config.User = user
+	cm.config.Password = password
+	cm.mu.Unlock()
+
+	if old != nil {
+		// Close waits for the queries already running on the old pool
+		go func() {
+			if err := old.Close(); err != nil {
+				cm.logger.WithError(err).Warn("Error closing database connection of previous credentials")
+			}
+		}()
+	}
+
+	cm.logger.WithFields(map[string]interface{}{
+		"user": user,
+	}).Info("Database credentials rotated")
+
+	return true, nil
+}
+
 // HealthCheck performs a database health check
 func (cm *ConnectionManager) HealthCheck(ctx context.Context) error {
-	if cm.db == nil {
+	db := cm.conn()
+	if db == nil {
 		return fmt.Errorf("database connection is nil")
 	}
 
@@ -142,7 +348,7 @@ func (cm *ConnectionManager) HealthCheck(ctx context.Context) error {
 	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	
-	if err := cm.db.PingContext(pingCtx); err != nil {
+	if err := db.PingContext(pingCtx); err != nil {
 		return fmt.Errorf("database ping failed: %w", err)
 	}
 
@@ -151,7 +357,7 @@ func (cm *ConnectionManager) HealthCheck(ctx context.Context) error {
 	defer cancel()
 	
 	var result int
-	err := cm.db.QueryRowContext(queryCtx, "SELECT 1").Scan(&result)
+	err := db.QueryRowContext(queryCtx, "SELECT 1").Scan(&result)
 	if err != nil {
 		return fmt.Errorf("database query test failed: %w", err)
 	}
@@ -165,11 +371,12 @@ func (cm *ConnectionManager) HealthCheck(ctx context.Context) error {
 
 // GetStats returns connection pool statistics
 func (cm *ConnectionManager) GetStats() ConnectionStats {
-	if cm.db == nil {
+	db := cm.conn()
+	if db == nil {
 		return ConnectionStats{}
 	}
 
-	stats := cm.db.Stats()
+	stats := db.Stats()
 	return ConnectionStats{
 		OpenConnections:     stats.OpenConnections,
 		InUseConnections:    stats.InUse,
@@ -184,12 +391,14 @@ func (cm *ConnectionManager) GetStats() ConnectionStats {
 
 // BeginTx starts a new transaction with context
 func (cm *ConnectionManager) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
-	if cm.db == nil {
+	db := cm.conn()
+	if db == nil {
 		return nil, fmt.Errorf("database connection is nil")
 	}
 	
-	tx, err := cm.db.BeginTx(ctx, opts)
+	tx, err := db.BeginTx(ctx, opts)
 	if err != nil {
+		tracing.RecordError(ctx, err)
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	
@@ -198,12 +407,14 @@ func (cm *ConnectionManager) BeginTx(ctx context.Context, opts *sql.TxOptions) (
 
 // ExecContext executes a query with context
 func (cm *ConnectionManager) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	if cm.db == nil {
+	db := cm.conn()
+	if db == nil {
 		return nil, fmt.Errorf("database connection is nil")
 	}
 	
-	result, err := cm.db.ExecContext(ctx, query, args...)
+	result, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
+		tracing.RecordError(ctx, err)
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	
@@ -212,12 +423,14 @@ func (cm *ConnectionManager) ExecContext(ctx context.Context, query string, args
 
 // QueryContext executes a query that returns rows with context
 func (cm *ConnectionManager) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	if cm.db == nil {
+	db := cm.conn()
+	if db == nil {
 		return nil, fmt.Errorf("database connection is nil")
 	}
 	
-	rows, err := cm.db.QueryContext(ctx, query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
+		tracing.RecordError(ctx, err)
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	
@@ -226,22 +439,25 @@ func (cm *ConnectionManager) QueryContext(ctx context.Context, query string, arg
 
 // QueryRowContext executes a query that returns a single row with context
 func (cm *ConnectionManager) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	if cm.db == nil {
+	db := cm.conn()
+	if db == nil {
 		// Return a row that will return an error when scanned
 		return &sql.Row{}
 	}
 	
-	return cm.db.QueryRowContext(ctx, query, args...)
+	return db.QueryRowContext(ctx, query, args...)
 }
 
 // PrepareContext prepares a statement with context
 func (cm *ConnectionManager) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
-	if cm.db == nil {
+	db := cm.conn()
+	if db == nil {
 		return nil, fmt.Errorf("database connection is nil")
 	}
 	
-	stmt, err := cm.db.PrepareContext(ctx, query)
+	stmt, err := db.PrepareContext(ctx, query)
 	if err != nil {
+		tracing.RecordError(ctx, err)
 		return nil, fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	
@@ -250,14 +466,29 @@ func (cm *ConnectionManager) PrepareContext(ctx context.Context, query string) (
 
 // Close gracefully closes the database connection
 func (cm *ConnectionManager) Close() error {
-	if cm.db == nil {
+	cm.mu.Lock()
+	stop, done := cm.refreshStop, cm.refreshDone
+	cm.refreshStop = nil
+	cm.mu.Unlock()
+
+	// Stop the credentials refresh before closing the pool it replaces
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	cm.stopReplicaChecks()
+	cm.closeReplicas()
+
+	db := cm.conn()
+	if db == nil {
 		return nil
 	}
 
 	cm.logger.Info("Closing database connection...")
 	
 	// Close the database connection
-	if err := cm.db.Close(); err != nil {
+	if err := db.Close(); err != nil {
 		cm.logger.WithError(err).Error("Error closing database connection")
 		return fmt.Errorf("failed to close database connection: %w", err)
 	}
@@ -292,7 +523,7 @@ func (cm *ConnectionManager) WaitForConnection(ctx context.Context, maxWait time
 
 // IsConnected checks if the database connection is active
 func (cm *ConnectionManager) IsConnected() bool {
-	if cm.db == nil {
+	if cm.conn() == nil {
 		return false
 	}
 	
@@ -2,11 +2,13 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/yourorg/lab-gateway/pkg/config"
 	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/secrets"
 )
 
 func TestConnectionManager(t *testing.T) {
@@ -70,4 +72,67 @@ func TestConnectionManager(t *testing.T) {
 			t.Error("Invalid connection stats")
 		}
 	})
-}
\ No newline at end of file
+}
+func TestBuildDSN(t *testing.T) {
+	cfg := &config.DatabaseConfig{Host: "db", Port: 5432, Name: "lab", SSLMode: "require"}
+
+	got := buildDSN(cfg, "v-gateway-1", `p4ss w'rd\`)
+	want := `host=db port=5432 user=v-gateway-1 password='p4ss w\'rd\\' dbname=lab sslmode=require`
+	if got != want {
+		t.Errorf("buildDSN() = %q, want %q", got, want)
+	}
+}
+
+func TestRefreshCredentials(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	// Nothing listens on port 1, so new credentials cannot be verified
+	cfg := &config.DatabaseConfig{Host: "127.0.0.1", Port: 1, Name: "lab", User: "gateway", Password: "initial", SSLMode: "disable"}
+	pool, err := sql.Open("postgres", buildDSN(cfg, cfg.User, cfg.Password))
+	if err != nil {
+		t.Fatalf("Failed to open pool: %v", err)
+	}
+	cm := &ConnectionManager{db: pool, config: cfg, logger: log}
+	defer cm.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	unchanged := func(ctx context.Context) (string, string, error) { return "", "initial", nil }
+	if rotated, err := cm.refreshCredentials(ctx, unchanged); err != nil || rotated {
+		t.Errorf("Unchanged credentials: rotated=%v err=%v", rotated, err)
+	}
+
+	rotatedCreds := func(ctx context.Context) (string, string, error) { return "gateway", "rotated", nil }
+	if rotated, err := cm.refreshCredentials(ctx, rotatedCreds); err == nil || rotated {
+		t.Errorf("Rejected credentials: rotated=%v err=%v", rotated, err)
+	}
+	if cm.GetDB() != pool || cm.config.Password != "initial" {
+		t.Error("Rejected credentials replaced the connection pool")
+	}
+
+	failing := func(ctx context.Context) (string, string, error) { return "", "", secrets.ErrNotFound }
+	if _, err := cm.refreshCredentials(ctx, failing); err == nil {
+		t.Error("Expected an error when credentials cannot be read")
+	}
+
+	// The refresh loop stops when the manager is closed
+	cm.RefreshCredentials(unchanged, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if err := cm.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func TestProviderCredentials(t *testing.T) {
+	t.Setenv("DB_USER", "")
+	t.Setenv("DB_PASSWORD", "from-env")
+
+	user, password, err := ProviderCredentials(secrets.NewEnvProvider())(context.Background())
+	if err != nil {
+		t.Fatalf("ProviderCredentials failed: %v", err)
+	}
+	if user != "" || password != "from-env" {
+		t.Errorf("ProviderCredentials() = %q, %q", user, password)
+	}
+}
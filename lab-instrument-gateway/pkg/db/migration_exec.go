@@ -87,9 +87,13 @@ func (mr *MigrationRunner) applyMigration(ctx context.Context, migration Migrati
 	}
 	
 	// Record migration in schema_migrations table
+	now := "NOW()"
+	if mr.sqlite {
+		now = "CURRENT_TIMESTAMP"
+	}
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO schema_migrations (version, name, checksum, applied_at)
-		VALUES ($1, $2, $3, NOW())
+		VALUES ($1, $2, $3, `+now+`)
 	`, migration.Version, migration.Name, migration.Checksum)
 	if err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
@@ -14,6 +14,9 @@ type MigrationRunner struct {
 	db             *sql.DB
 	logger         *logger.Logger
 	migrationsPath string
+
+	// sqlite selects the SQLite dialect for the migrations table
+	sqlite bool
 }
 
 // Migration represents a database migration
@@ -43,9 +46,31 @@ func NewMigrationRunner(db *sql.DB, migrationsPath string, log *logger.Logger) *
 	}
 }
 
+// NewSQLiteMigrationRunner creates a migration runner for a SQLite database,
+// applying the SQLite migrations in migrationsPath
+func NewSQLiteMigrationRunner(db *sql.DB, migrationsPath string, log *logger.Logger) *MigrationRunner {
+	mr := NewMigrationRunner(db, migrationsPath, log)
+	mr.sqlite = true
+	return mr
+}
+
 // Initialize creates the migrations table if it doesn't exist
 func (mr *MigrationRunner) Initialize(ctx context.Context) error {
-	query := `
+	if mr.sqlite {
+		return mr.initialize(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INTEGER PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				checksum VARCHAR(64) NOT NULL,
+				applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_schema_migrations_applied_at
+			ON schema_migrations(applied_at);
+		`)
+	}
+
+	return mr.initialize(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version INTEGER PRIMARY KEY,
 			name VARCHAR(255) NOT NULL,
@@ -55,8 +80,11 @@ func (mr *MigrationRunner) Initialize(ctx context.Context) error {
 		
 		CREATE INDEX IF NOT EXISTS idx_schema_migrations_applied_at 
 		ON schema_migrations(applied_at);
-	`
-	
+	`)
+}
+
+// initialize creates the migrations table with the given statements
+func (mr *MigrationRunner) initialize(ctx context.Context, query string) error {
 	_, err := mr.db.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to initialize migrations table: %w", err)
@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/config"
+	"github.com/yourorg/lab-gateway/pkg/tracing"
+)
+
+// replicaLagQuery returns how many seconds the replay of a replica trails
+// the primary. A replica that replayed everything it received is current
+// even if the primary has not written since, and a server that is not in
+// recovery is no replica at all.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END
+`
+
+// readYourWritesKey is the context key of WithReadYourWrites
+type readYourWritesKey struct{}
+
+// WithReadYourWrites returns a context whose queries all run on the
+// primary, so that they see the writes made before them
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+// ReadsYourWrites reports whether the queries of ctx must run on the primary
+func ReadsYourWrites(ctx context.Context) bool {
+	required, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return required
+}
+
+// ForRead returns the executor of a read-only query that may see data a
+// little behind the primary: a replica when exec is a connection manager
+// that has one available, or exec itself. Queries within a transaction stay
+// on it.
+func ForRead(ctx context.Context, exec Executor) Executor {
+	if reader, ok := exec.(interface {
+		Reader(ctx context.Context) Executor
+	}); ok {
+		return reader.Reader(ctx)
+	}
+	return exec
+}
+
+// Reader returns the executor of read-only queries that may see data a
+// little behind the primary. Replicas are used in turn, skipping those that
+// failed their last check or lag too far behind; without one, or when ctx
+// requires reading its own writes, queries run on the primary.
+func (cm *ConnectionManager) Reader(ctx context.Context) Executor {
+	if len(cm.replicas) == 0 || ReadsYourWrites(ctx) {
+		return cm
+	}
+
+	start := atomic.AddUint32(&cm.nextReplica, 1)
+	for i := range cm.replicas {
+		r := cm.replicas[(int(start)+i)%len(cm.replicas)]
+		if r.usable(cm.config.ReplicaMaxLag) {
+			return r
+		}
+	}
+
+	return cm
+}
+
+// openReplicas opens the configured read replicas and checks them once, so
+// that reads go to them from the start, then keeps checking them. A replica
+// that cannot be reached is skipped until it passes a check.
+func (cm *ConnectionManager) openReplicas() error {
+	if len(cm.config.ReplicaDSNs) == 0 {
+		return nil
+	}
+	if cm.config.Driver == config.DriverSQLite {
+		cm.logger.Warn("Read replicas are not supported with the SQLite driver, reading from the database file")
+		return nil
+	}
+
+	for i, dsn := range cm.config.ReplicaDSNs {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			cm.closeReplicas()
+			return fmt.Errorf("failed to open read replica %d: %w", i, err)
+		}
+		cm.configureConnectionPool(db)
+		cm.replicas = append(cm.replicas, &replica{index: i, db: db})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	cm.checkReplicas(ctx)
+	cancel()
+
+	interval := cm.config.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	cm.replicaStop = make(chan struct{})
+	cm.replicaDone = make(chan struct{})
+	go func() {
+		defer close(cm.replicaDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-cm.replicaStop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				cm.checkReplicas(ctx)
+				cancel()
+			}
+		}
+	}()
+
+	cm.logger.WithFields(map[string]interface{}{
+		"replicas": len(cm.replicas),
+		"max_lag":  cm.config.ReplicaMaxLag.String(),
+		"interval": interval.String(),
+	}).Info("Read replicas configured")
+
+	return nil
+}
+
+// checkReplicas checks each replica, logging the ones whose state changed
+func (cm *ConnectionManager) checkReplicas(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range cm.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+
+			before := r.usable(cm.config.ReplicaMaxLag)
+			r.check(ctx)
+			after := r.usable(cm.config.ReplicaMaxLag)
+			if before == after {
+				return
+			}
+
+			lag, err := r.state()
+			entry := cm.logger.WithFields(map[string]interface{}{
+				"replica": r.index,
+				"lag":     lag.String(),
+			})
+			switch {
+			case after:
+				entry.Info("Read replica available")
+			case err != nil:
+				entry.WithError(err).Warn("Read replica unavailable, reading from the primary")
+			default:
+				entry.Warn("Read replica lagging behind, reading from the primary")
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+// stopReplicaChecks stops checking the replicas
+func (cm *ConnectionManager) stopReplicaChecks() {
+	if cm.replicaStop == nil {
+		return
+	}
+	close(cm.replicaStop)
+	<-cm.replicaDone
+	cm.replicaStop = nil
+}
+
+// closeReplicas closes the connection pools of the replicas
+func (cm *ConnectionManager) closeReplicas() {
+	for _, r := range cm.replicas {
+		if err := r.db.Close(); err != nil {
+			cm.logger.WithField("replica", r.index).WithError(err).Warn("Error closing read replica connection")
+		}
+	}
+	cm.replicas = nil
+}
+
+// replica is a read replica of the primary database
+type replica struct {
+	// index identifies the replica in logs without its connection string,
+	// which holds its credentials
+	index int
+	db    *sql.DB
+
+	mu      sync.RWMutex
+	checked bool
+	lag     time.Duration
+	err     error
+}
+
+var _ Executor = (*replica)(nil)
+
+// check pings the replica and measures its replication lag
+func (r *replica) check(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var seconds float64
+	err := r.db.QueryRowContext(checkCtx, replicaLagQuery).Scan(&seconds)
+	if err != nil {
+		err = fmt.Errorf("read replica check failed: %w", err)
+	}
+
+	r.mu.Lock()
+	r.checked = true
+	r.lag = time.Duration(seconds * float64(time.Second))
+	r.err = err
+	r.mu.Unlock()
+}
+
+// usable reports whether the replica passed its last check lagging no more
+// than maxLag behind; zero allows any lag
+func (r *replica) usable(maxLag time.Duration) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.checked && r.err == nil && (maxLag <= 0 || r.lag <= maxLag)
+}
+
+// state returns the lag and error of the last check of the replica
+func (r *replica) state() (time.Duration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lag, r.err
+}
+
+// ExecContext executes a statement on the replica, which fails unless it
+// only reads
+func (r *replica) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, fmt.Errorf("failed to execute query on read replica: %w", err)
+	}
+	return result, nil
+}
+
+// QueryContext executes a query that returns rows on the replica
+func (r *replica) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, fmt.Errorf("failed to execute query on read replica: %w", err)
+	}
+	return rows, nil
+}
+
+// QueryRowContext executes a query that returns a single row on the replica
+func (r *replica) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return r.db.QueryRowContext(ctx, query, args...)
+}
+
+// PrepareContext prepares a statement on the replica
+func (r *replica) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, fmt.Errorf("failed to prepare statement on read replica: %w", err)
+	}
+	return stmt, nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/config"
+)
+
+func TestReader(t *testing.T) {
+	cm, _ := newFakeConnectionManager(t)
+	cm.config = &config.DatabaseConfig{ReplicaMaxLag: 5 * time.Second}
+	ctx := context.Background()
+
+	t.Run("WithoutReplicas", func(t *testing.T) {
+		assert.Same(t, cm, ForRead(ctx, cm))
+	})
+
+	first := &replica{index: 0, checked: true}
+	second := &replica{index: 1, checked: true}
+	cm.replicas = []*replica{first, second}
+
+	t.Run("RoundRobin", func(t *testing.T) {
+		seen := map[Executor]int{}
+		for i := 0; i < 4; i++ {
+			seen[ForRead(ctx, cm)]++
+		}
+		assert.Equal(t, map[Executor]int{first: 2, second: 2}, seen)
+	})
+
+	t.Run("ReadYourWrites", func(t *testing.T) {
+		assert.Same(t, cm, ForRead(WithReadYourWrites(ctx), cm))
+	})
+
+	t.Run("SkipsUnhealthyAndLagging", func(t *testing.T) {
+		first.err = errors.New("connection refused")
+		for i := 0; i < 3; i++ {
+			assert.Same(t, second, ForRead(ctx, cm))
+		}
+
+		second.lag = 6 * time.Second
+		assert.Same(t, cm, ForRead(ctx, cm))
+
+		second.lag = time.Second
+		assert.Same(t, second, ForRead(ctx, cm))
+	})
+
+	t.Run("Unchecked", func(t *testing.T) {
+		cm.replicas = []*replica{{index: 0}}
+		assert.Same(t, cm, ForRead(ctx, cm))
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		cm.replicas = []*replica{{index: 0, checked: true}}
+
+		tx, err := cm.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		assert.Same(t, tx, ForRead(ctx, tx))
+	})
+}
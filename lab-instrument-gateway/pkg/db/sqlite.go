@@ -0,0 +1,37 @@
+package db
+
+import (
+	"database/sql"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite" // SQLite driver, pure Go so builds stay static
+)
+
+// sqliteDriverName is the name the SQLite driver registers with database/sql
+const sqliteDriverName = "sqlite"
+
+// sqliteDSN returns the connection string of a SQLite database file. Every
+// connection enforces foreign keys, uses the write-ahead log so that reads
+// do not wait for writers, waits for the write lock instead of failing, and
+// takes the write lock when a transaction begins, so that transactions
+// serialize on it rather than fail when upgrading a read to a write.
+func sqliteDSN(path string) string {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Set("_txlock", "immediate")
+
+	return "file:" + path + "?" + query.Encode()
+}
+
+// configureSQLitePool sets up the connection pool of a SQLite database. A
+// SQLite database has a single writer, so a few connections are enough to
+// let reads proceed while a transaction holds the write lock.
+func configureSQLitePool(db *sql.DB) {
+	db.SetMaxOpenConns(4)
+	db.SetMaxIdleConns(4)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yourorg/lab-gateway/pkg/tracing"
+)
+
+// Executor runs queries. *ConnectionManager runs them on the connection
+// pool and *sql.Tx within a transaction, so that repositories built on an
+// Executor work either way.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+var (
+	_ Executor = (*ConnectionManager)(nil)
+	_ Executor = (*sql.Tx)(nil)
+)
+
+// Tx is a transaction begun or joined by Begin
+type Tx interface {
+	Executor
+	Commit() error
+	Rollback() error
+}
+
+// Begin starts a transaction on exec, or joins the transaction exec already
+// is. A joined transaction is committed or rolled back by its owner, so
+// Commit and Rollback do nothing on it.
+func Begin(ctx context.Context, exec Executor) (Tx, error) {
+	switch exec := exec.(type) {
+	case *sql.Tx:
+		return joinedTx{exec}, nil
+	case interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}:
+		return exec.BeginTx(ctx, nil)
+	default:
+		return nil, fmt.Errorf("cannot begin a transaction on %T", exec)
+	}
+}
+
+// joinedTx is an enclosing transaction joined by Begin
+type joinedTx struct {
+	*sql.Tx
+}
+
+// Commit leaves committing to the enclosing transaction
+func (joinedTx) Commit() error { return nil }
+
+// Rollback leaves rolling back to the enclosing transaction
+func (joinedTx) Rollback() error { return nil }
+
+// Isolation levels accepted by ParseIsolationLevel
+const (
+	IsolationReadCommitted  = "read_committed"
+	IsolationRepeatableRead = "repeatable_read"
+	IsolationSerializable   = "serializable"
+)
+
+// ParseIsolationLevel parses a transaction isolation level; empty selects
+// the database default
+func ParseIsolationLevel(level string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "":
+		return sql.LevelDefault, nil
+	case IsolationReadCommitted:
+		return sql.LevelReadCommitted, nil
+	case IsolationRepeatableRead:
+		return sql.LevelRepeatableRead, nil
+	case IsolationSerializable:
+		return sql.LevelSerializable, nil
+	default:
+		return sql.LevelDefault, fmt.Errorf("unknown isolation level %q", level)
+	}
+}
+
+// TxOptions configures the transactions run by RunInTx
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	// MaxRetries is how many times a transaction that failed on a
+	// serialization failure or deadlock is run again; RetryBackoff is the
+	// delay before the first retry, doubling with each further one
+	MaxRetries   int
+	RetryBackoff time.Duration
+}
+
+// SetDefaults sets default values for the transaction options
+func (o *TxOptions) SetDefaults() {
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 10 * time.Millisecond
+	}
+}
+
+// Postgres error codes of transactions that may succeed when run again
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+// IsSerializationFailure reports whether err is a serialization failure or
+// deadlock, after which the transaction may succeed when run again
+func IsSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == serializationFailureCode || pqErr.Code == deadlockDetectedCode
+}
+
+// TxDefaults returns the configured transaction options
+func (cm *ConnectionManager) TxDefaults() TxOptions {
+	return cm.txDefaults
+}
+
+// RunInTx runs fn in a transaction, committing it if fn succeeds and
+// rolling it back otherwise. Transactions failing on a serialization
+// failure or deadlock are run again, so fn must not have effects outside
+// the transaction that cannot be repeated.
+func (cm *ConnectionManager) RunInTx(ctx context.Context, options TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	options.SetDefaults()
+
+	backoff := options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := cm.runInTx(ctx, options, fn)
+		if err == nil || !IsSerializationFailure(err) || attempt >= options.MaxRetries {
+			return err
+		}
+
+		cm.logger.WithFields(map[string]interface{}{
+			"attempt":     attempt + 1,
+			"max_retries": options.MaxRetries,
+		}).WithError(err).Debug("Retrying transaction after serialization failure")
+
+		// Jitter keeps conflicting transactions from retrying in lockstep
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("transaction not retried: %w", errors.Join(err, ctx.Err()))
+		}
+		backoff *= 2
+	}
+}
+
+// runInTx runs fn in a single transaction
+func (cm *ConnectionManager) runInTx(ctx context.Context, options TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	tx, err := cm.BeginTx(ctx, &sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tracing.RecordError(ctx, err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
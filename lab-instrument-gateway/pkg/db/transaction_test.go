@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// fakeDriver is a database driver that only runs transactions, failing
+// their commits with queued errors
+type fakeDriver struct {
+	mutex      sync.Mutex
+	commitErrs []error
+	commits    int
+	rollbacks  int
+	options    driver.TxOptions
+}
+
+func (d *fakeDriver) Connect(ctx context.Context) (driver.Conn, error) { return &fakeConn{d}, nil }
+func (d *fakeDriver) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("statements are not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, options driver.TxOptions) (driver.Tx, error) {
+	c.driver.mutex.Lock()
+	defer c.driver.mutex.Unlock()
+	c.driver.options = options
+	return &fakeTx{c.driver}, nil
+}
+
+type fakeTx struct {
+	driver *fakeDriver
+}
+
+func (t *fakeTx) Commit() error {
+	t.driver.mutex.Lock()
+	defer t.driver.mutex.Unlock()
+	if len(t.driver.commitErrs) > 0 {
+		err := t.driver.commitErrs[0]
+		t.driver.commitErrs = t.driver.commitErrs[1:]
+		return err
+	}
+	t.driver.commits++
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.driver.mutex.Lock()
+	defer t.driver.mutex.Unlock()
+	t.driver.rollbacks++
+	return nil
+}
+
+func newFakeConnectionManager(t *testing.T, commitErrs ...error) (*ConnectionManager, *fakeDriver) {
+	fake := &fakeDriver{commitErrs: commitErrs}
+	sqlDB := sql.OpenDB(fake)
+	t.Cleanup(func() { sqlDB.Close() })
+	return &ConnectionManager{db: sqlDB, logger: logger.NewDefaultLogger()}, fake
+}
+
+func TestRunInTx(t *testing.T) {
+	ctx := context.Background()
+	serializationFailure := &pq.Error{Code: "40001"}
+	deadlock := &pq.Error{Code: "40P01"}
+
+	t.Run("RetriesSerializationFailures", func(t *testing.T) {
+		cm, fake := newFakeConnectionManager(t, serializationFailure, deadlock)
+
+		calls := 0
+		err := cm.RunInTx(ctx, TxOptions{Isolation: sql.LevelSerializable, MaxRetries: 3}, func(ctx context.Context, tx *sql.Tx) error {
+			calls++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, 1, fake.commits)
+		assert.Equal(t, driver.IsolationLevel(sql.LevelSerializable), fake.options.Isolation)
+	})
+
+	t.Run("GivesUpAfterMaxRetries", func(t *testing.T) {
+		cm, fake := newFakeConnectionManager(t, serializationFailure, serializationFailure, serializationFailure)
+
+		calls := 0
+		err := cm.RunInTx(ctx, TxOptions{MaxRetries: 1}, func(ctx context.Context, tx *sql.Tx) error {
+			calls++
+			return nil
+		})
+		require.Error(t, err)
+		assert.True(t, IsSerializationFailure(err))
+		assert.Equal(t, 2, calls)
+		assert.Zero(t, fake.commits)
+	})
+
+	t.Run("RollsBackOnError", func(t *testing.T) {
+		cm, fake := newFakeConnectionManager(t)
+
+		calls := 0
+		failure := errors.New("device exists")
+		err := cm.RunInTx(ctx, TxOptions{MaxRetries: 3}, func(ctx context.Context, tx *sql.Tx) error {
+			calls++
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, 1, calls)
+		assert.Zero(t, fake.commits)
+		assert.Equal(t, 1, fake.rollbacks)
+	})
+}
+
+func TestBegin_JoinsTransaction(t *testing.T) {
+	ctx := context.Background()
+	cm, fake := newFakeConnectionManager(t)
+
+	outer, err := Begin(ctx, cm)
+	require.NoError(t, err)
+
+	inner, err := Begin(ctx, outer.(*sql.Tx))
+	require.NoError(t, err)
+	require.NoError(t, inner.Commit())
+	require.NoError(t, inner.Rollback())
+	assert.Zero(t, fake.commits)
+	assert.Zero(t, fake.rollbacks)
+
+	require.NoError(t, outer.Commit())
+	assert.Equal(t, 1, fake.commits)
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	tests := map[string]sql.IsolationLevel{
+		"":                sql.LevelDefault,
+		"read_committed":  sql.LevelReadCommitted,
+		"REPEATABLE_READ": sql.LevelRepeatableRead,
+		"serializable":    sql.LevelSerializable,
+	}
+	for level, want := range tests {
+		got, err := ParseIsolationLevel(level)
+		require.NoError(t, err, level)
+		assert.Equal(t, want, got, level)
+	}
+
+	_, err := ParseIsolationLevel("snapshot")
+	assert.Error(t, err)
+}
@@ -0,0 +1,488 @@
+// Package fieldcrypt encrypts designated fields of JSON documents with
+// AES-256-GCM envelope encryption. Each value is encrypted under a data key,
+// which is stored next to it wrapped by a key encryption key held by a
+// KeyProvider, so that key encryption keys can be rotated without losing
+// access to existing data.
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// EnvelopeKey marks an encrypted value: it is replaced by an object with
+// this single key holding the envelope
+const EnvelopeKey = "$enc"
+
+// envelopeVersion is the version of the envelope layout
+const envelopeVersion = 1
+
+// Default cipher settings
+const (
+	DefaultDataKeyTTL       = 10 * time.Minute
+	DefaultDataKeyCacheSize = 1000
+)
+
+// providerTimeout bounds a single call to the key provider
+const providerTimeout = 10 * time.Second
+
+// ErrInvalidEnvelope is returned when an encrypted value cannot be decoded
+var ErrInvalidEnvelope = errors.New("invalid encrypted value")
+
+// Config represents the field encryption configuration
+type Config struct {
+	// KeyFile is the local keyfile holding the key encryption keys
+	KeyFile string
+
+	// Fields are the keys whose values are encrypted, at any depth of a
+	// document. Encryption is disabled when there are none.
+	Fields []string
+
+	// DataKeyTTL is how long one data key encrypts new values before a
+	// new one is generated
+	DataKeyTTL time.Duration
+
+	// DataKeyCacheSize bounds the unwrapped data keys kept for decryption
+	DataKeyCacheSize int
+}
+
+// SetDefaults sets default values for the field encryption configuration
+func (c *Config) SetDefaults() {
+	if c.DataKeyTTL <= 0 {
+		c.DataKeyTTL = DefaultDataKeyTTL
+	}
+	if c.DataKeyCacheSize <= 0 {
+		c.DataKeyCacheSize = DefaultDataKeyCacheSize
+	}
+}
+
+// ParseFields parses a comma separated list of field names
+func ParseFields(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// envelope is the stored form of an encrypted value
+type envelope struct {
+	Version int    `json:"v"`
+	KeyID   string `json:"kid"`
+	DataKey string `json:"dek"`
+	Data    string `json:"ct"`
+}
+
+// currentKey is the data key used to encrypt new values
+type currentKey struct {
+	keyID     string
+	wrapped   string
+	aead      cipher.AEAD
+	expiresAt time.Time
+}
+
+// Cipher encrypts and decrypts the designated fields of JSON documents
+type Cipher struct {
+	provider KeyProvider
+	fields   map[string]bool
+	config   Config
+
+	mutex   sync.Mutex
+	current *currentKey
+	cache   map[string]cipher.AEAD
+}
+
+// Load creates a cipher from the configuration, reading the keyfile. It
+// returns nil if no fields are designated for encryption. Fields that access
+// control matches in queries, such as device_group, are refused; other
+// encrypted fields cannot be used as metadata filters.
+func Load(config Config) (*Cipher, error) {
+	if len(config.Fields) == 0 {
+		return nil, nil
+	}
+
+	if config.KeyFile == "" {
+		return nil, errors.New("a keyfile is required to encrypt fields")
+	}
+
+	provider, err := LoadKeyFile(config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCipher(config, provider)
+}
+
+// NewCipher creates a cipher for the configured fields using data keys from
+// the provider
+func NewCipher(config Config, provider KeyProvider) (*Cipher, error) {
+	config.SetDefaults()
+
+	if len(config.Fields) == 0 {
+		return nil, errors.New("no fields designated for encryption")
+	}
+
+	fields := make(map[string]bool, len(config.Fields))
+	for _, field := range config.Fields {
+		switch field {
+		case "", EnvelopeKey:
+			return nil, fmt.Errorf("invalid encrypted field %q", field)
+		case models.DeviceGroupMetadataKey:
+			// Device groups are matched in queries, which cannot see encrypted values
+			return nil, fmt.Errorf("%s cannot be encrypted, access control queries match it", field)
+		}
+		fields[field] = true
+	}
+
+	return &Cipher{
+		provider: provider,
+		fields:   fields,
+		config:   config,
+		cache:    make(map[string]cipher.AEAD),
+	}, nil
+}
+
+// Encrypts returns true if values of the field are encrypted, and so cannot
+// be matched by queries
+func (c *Cipher) Encrypts(field string) bool {
+	return c.fields[field]
+}
+
+// Encrypt returns a copy of a document with the values of designated fields
+// encrypted. Maps and slices are searched at any depth; other values, and
+// values that are already encrypted, are returned unchanged.
+func (c *Cipher) Encrypt(value interface{}) (interface{}, error) {
+	encrypted, _, err := c.encrypt(value)
+	return encrypted, err
+}
+
+// encrypt encrypts a document, reporting whether anything was encrypted so
+// that unchanged parts need not be copied
+func (c *Cipher) encrypt(value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isEnvelope(v) {
+			return v, false, nil
+		}
+
+		var result map[string]interface{}
+		for key, field := range v {
+			var updated interface{}
+			var changed bool
+			var err error
+			if c.fields[key] && !isEnvelopeValue(field) {
+				updated, err = c.encryptValue(key, field)
+				changed = true
+			} else {
+				updated, changed, err = c.encrypt(field)
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			if !changed {
+				continue
+			}
+
+			if result == nil {
+				result = make(map[string]interface{}, len(v))
+				for k, f := range v {
+					result[k] = f
+				}
+			}
+			result[key] = updated
+		}
+
+		if result == nil {
+			return v, false, nil
+		}
+		return result, true, nil
+
+	case []interface{}:
+		var result []interface{}
+		for i, item := range v {
+			updated, changed, err := c.encrypt(item)
+			if err != nil {
+				return nil, false, err
+			}
+			if !changed {
+				continue
+			}
+
+			if result == nil {
+				result = append([]interface{}(nil), v...)
+			}
+			result[i] = updated
+		}
+
+		if result == nil {
+			return v, false, nil
+		}
+		return result, true, nil
+	}
+
+	return value, false, nil
+}
+
+// encryptValue encrypts a single field value. The field name is bound to the
+// ciphertext, so that values cannot be moved between fields.
+func (c *Cipher) encryptValue(field string, value interface{}) (interface{}, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode field %s: %w", field, err)
+	}
+
+	key, err := c.currentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := seal(key.aead, plaintext, []byte(field))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt field %s: %w", field, err)
+	}
+
+	return map[string]interface{}{
+		EnvelopeKey: map[string]interface{}{
+			"v":   envelopeVersion,
+			"kid": key.keyID,
+			"dek": key.wrapped,
+			"ct":  base64.StdEncoding.EncodeToString(sealed),
+		},
+	}, nil
+}
+
+// Decrypt returns a copy of a document with every encrypted value replaced
+// by its plaintext
+func (c *Cipher) Decrypt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			if env, ok := asEnvelope(field); ok {
+				plaintext, err := c.decryptValue(key, env)
+				if err != nil {
+					return nil, err
+				}
+				result[key] = plaintext
+				continue
+			}
+
+			decrypted, err := c.Decrypt(field)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = decrypted
+		}
+		return result, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			decrypted, err := c.Decrypt(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = decrypted
+		}
+		return result, nil
+	}
+
+	return value, nil
+}
+
+// decryptValue decrypts a single field value
+func (c *Cipher) decryptValue(field string, env *envelope) (interface{}, error) {
+	if env.Version != envelopeVersion {
+		return nil, fmt.Errorf("%w: field %s has unsupported version %d", ErrInvalidEnvelope, field, env.Version)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: field %s: %v", ErrInvalidEnvelope, field, err)
+	}
+
+	aead, err := c.dataKey(env.KeyID, env.DataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(aead, sealed, []byte(field))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt field %s: %w", field, err)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(plaintext, &value); err != nil {
+		return nil, fmt.Errorf("%w: field %s: %v", ErrInvalidEnvelope, field, err)
+	}
+
+	return value, nil
+}
+
+// NeedsRotation returns true if a document has designated fields in
+// plaintext, or values encrypted under a key other than the primary key
+func (c *Cipher) NeedsRotation(value interface{}) bool {
+	primary := c.provider.PrimaryKeyID()
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if env, ok := asEnvelope(field); ok {
+				if env.KeyID != primary {
+					return true
+				}
+				continue
+			}
+			if c.fields[key] || c.NeedsRotation(field) {
+				return true
+			}
+		}
+
+	case []interface{}:
+		for _, item := range v {
+			if c.NeedsRotation(item) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// currentKey returns the data key for new values, generating one if the
+// current key has expired
+func (c *Cipher) currentKey() (*currentKey, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if c.current != nil && now.Before(c.current.expiresAt) {
+		return c.current, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	dataKey, err := c.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	c.current = &currentKey{
+		keyID:     dataKey.KeyID,
+		wrapped:   base64.StdEncoding.EncodeToString(dataKey.Wrapped),
+		aead:      aead,
+		expiresAt: now.Add(c.config.DataKeyTTL),
+	}
+	c.cacheKey(c.current.keyID, c.current.wrapped, aead)
+
+	return c.current, nil
+}
+
+// dataKey returns the cipher for a wrapped data key, unwrapping it with the
+// provider unless it is cached
+func (c *Cipher) dataKey(keyID, wrapped string) (cipher.AEAD, error) {
+	c.mutex.Lock()
+	aead, ok := c.cache[keyID+"/"+wrapped]
+	c.mutex.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("%w: data key: %v", ErrInvalidEnvelope, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	plaintext, err := c.provider.DecryptDataKey(ctx, keyID, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err = newAEAD(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	c.mutex.Lock()
+	c.cacheKey(keyID, wrapped, aead)
+	c.mutex.Unlock()
+
+	return aead, nil
+}
+
+// cacheKey caches an unwrapped data key, starting over when the cache is
+// full. The caller must hold the mutex.
+func (c *Cipher) cacheKey(keyID, wrapped string, aead cipher.AEAD) {
+	if len(c.cache) >= c.config.DataKeyCacheSize {
+		c.cache = make(map[string]cipher.AEAD)
+	}
+	c.cache[keyID+"/"+wrapped] = aead
+}
+
+// ContainsEncrypted reports whether a JSON document may hold encrypted
+// values, without parsing it
+func ContainsEncrypted(data []byte) bool {
+	return bytes.Contains(data, []byte(`"`+EnvelopeKey+`"`))
+}
+
+// isEnvelope returns true if a map is an encrypted value
+func isEnvelope(v map[string]interface{}) bool {
+	_, ok := asEnvelope(v)
+	return ok
+}
+
+// isEnvelopeValue returns true if a value is an encrypted value
+func isEnvelopeValue(value interface{}) bool {
+	_, ok := asEnvelope(value)
+	return ok
+}
+
+// asEnvelope decodes an encrypted value, as produced by Encrypt or read back
+// from JSON
+func asEnvelope(value interface{}) (*envelope, bool) {
+	v, ok := value.(map[string]interface{})
+	if !ok || len(v) != 1 {
+		return nil, false
+	}
+
+	fields, ok := v[EnvelopeKey].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	env := &envelope{}
+	switch version := fields["v"].(type) {
+	case int:
+		env.Version = version
+	case float64:
+		env.Version = int(version)
+	default:
+		return nil, false
+	}
+	env.KeyID, _ = fields["kid"].(string)
+	env.DataKey, _ = fields["dek"].(string)
+	env.Data, _ = fields["ct"].(string)
+
+	return env, true
+}
@@ -0,0 +1,171 @@
+package fieldcrypt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider counts the data keys generated by a provider
+type countingProvider struct {
+	KeyProvider
+	generated int
+}
+
+func (p *countingProvider) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	p.generated++
+	return p.KeyProvider.GenerateDataKey(ctx)
+}
+
+func newKeys(t *testing.T, ids ...string) map[string][]byte {
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		key, err := GenerateKey()
+		require.NoError(t, err)
+		keys[id] = key
+	}
+	return keys
+}
+
+// roundTrip stores and reads back a document as JSON
+func roundTrip(t *testing.T, value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	var document interface{}
+	require.NoError(t, json.Unmarshal(data, &document))
+	return document
+}
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	provider, err := NewLocalKeyProvider("k1", newKeys(t, "k1"))
+	require.NoError(t, err)
+	counting := &countingProvider{KeyProvider: provider}
+
+	cipher, err := NewCipher(Config{Fields: []string{"operator", "sample_id"}}, counting)
+	require.NoError(t, err)
+
+	metadata := map[string]interface{}{
+		"operator":  "Dana Scully",
+		"sample_id": float64(4711),
+		"location":  "lab-2",
+		"run": map[string]interface{}{
+			"operator": "Fox Mulder",
+		},
+		"history": []interface{}{map[string]interface{}{"sample_id": "S-1"}},
+	}
+
+	encrypted, err := cipher.Encrypt(metadata)
+	require.NoError(t, err)
+	assert.Equal(t, "Dana Scully", metadata["operator"], "the input is not modified")
+
+	stored := roundTrip(t, encrypted)
+	data, _ := json.Marshal(stored)
+	assert.NotContains(t, string(data), "Scully")
+	assert.NotContains(t, string(data), "Mulder")
+	assert.NotContains(t, string(data), "S-1")
+	assert.Contains(t, string(data), "lab-2")
+	assert.True(t, ContainsEncrypted(data))
+
+	// Encrypting again leaves encrypted values alone
+	again, err := cipher.Encrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, stored, again)
+
+	decrypted, err := cipher.Decrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, decrypted)
+
+	assert.Equal(t, 1, counting.generated, "one data key is reused while it is current")
+	assert.False(t, cipher.NeedsRotation(stored))
+	assert.True(t, cipher.NeedsRotation(metadata))
+
+	// Values cannot be moved to another field
+	swapped := stored.(map[string]interface{})
+	swapped["sample_id"], swapped["operator"] = swapped["operator"], swapped["sample_id"]
+	_, err = cipher.Decrypt(swapped)
+	assert.Error(t, err)
+}
+
+func TestCipher_KeyRotation(t *testing.T) {
+	keys := newKeys(t, "2026-09", "2026-10")
+
+	oldProvider, err := NewLocalKeyProvider("2026-09", map[string][]byte{"2026-09": keys["2026-09"]})
+	require.NoError(t, err)
+	oldCipher, err := NewCipher(Config{Fields: []string{"operator"}}, oldProvider)
+	require.NoError(t, err)
+
+	encrypted, err := oldCipher.Encrypt(map[string]interface{}{"operator": "Dana Scully"})
+	require.NoError(t, err)
+	stored := roundTrip(t, encrypted)
+
+	// The new primary key is added, the retired key kept for reading
+	newProvider, err := NewLocalKeyProvider("2026-10", keys)
+	require.NoError(t, err)
+	newCipher, err := NewCipher(Config{Fields: []string{"operator"}}, newProvider)
+	require.NoError(t, err)
+
+	assert.True(t, newCipher.NeedsRotation(stored))
+	plaintext, err := newCipher.Decrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"operator": "Dana Scully"}, plaintext)
+
+	rotated, err := newCipher.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.False(t, newCipher.NeedsRotation(roundTrip(t, rotated)))
+
+	// Once the retired key is removed, its data cannot be read
+	_, err = oldCipher.Decrypt(roundTrip(t, rotated))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestNewCipher_Validation(t *testing.T) {
+	provider, err := NewLocalKeyProvider("k1", newKeys(t, "k1"))
+	require.NoError(t, err)
+
+	for _, fields := range [][]string{nil, {""}, {EnvelopeKey}, {"device_group"}} {
+		_, err := NewCipher(Config{Fields: fields}, provider)
+		assert.Error(t, err, fields)
+	}
+
+	_, err = NewLocalKeyProvider("missing", newKeys(t, "k1"))
+	assert.Error(t, err)
+	_, err = NewLocalKeyProvider("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	keys := newKeys(t, "2026-10")
+	keyfile := `{"primary": "2026-10", "keys": [{"id": "2026-10", "key": "` + base64.StdEncoding.EncodeToString(keys["2026-10"]) + `"}]}`
+	path := filepath.Join(t.TempDir(), "field-keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(keyfile), 0600))
+
+	cipher, err := Load(Config{KeyFile: path, Fields: ParseFields(" operator, ,sample_id ")})
+	require.NoError(t, err)
+	require.NotNil(t, cipher)
+	assert.Equal(t, map[string]bool{"operator": true, "sample_id": true}, cipher.fields)
+	assert.True(t, cipher.Encrypts("sample_id"))
+	assert.False(t, cipher.Encrypts("location"))
+
+	cipher, err = Load(Config{KeyFile: path})
+	require.NoError(t, err)
+	assert.Nil(t, cipher, "no fields disables encryption")
+
+	// Access control matches device groups in queries
+	_, err = Load(Config{KeyFile: path, Fields: []string{"operator", "device_group"}})
+	assert.ErrorContains(t, err, "device_group")
+
+	_, err = Load(Config{Fields: []string{"operator"}})
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(keyfile, `"primary": "2026-10"`, `"primary": "2026-11"`, 1)), 0600))
+	_, err = Load(Config{KeyFile: path, Fields: []string{"operator"}})
+	assert.Error(t, err)
+}
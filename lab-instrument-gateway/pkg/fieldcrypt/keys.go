@@ -0,0 +1,198 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// KeySize is the size of key encryption keys and data keys, in bytes
+const KeySize = 32
+
+// ErrUnknownKey is returned when data was encrypted under a key the provider
+// does not hold
+var ErrUnknownKey = errors.New("unknown key encryption key")
+
+// DataKey is a data encryption key, in plaintext for immediate use and
+// wrapped by a key encryption key for storage next to the data
+type DataKey struct {
+	KeyID     string
+	Plaintext []byte
+	Wrapped   []byte
+}
+
+// KeyProvider issues and unwraps data keys under key encryption keys it
+// holds. It follows the GenerateDataKey/Decrypt model of cloud KMS services,
+// so a KMS can stand in for the local keyfile.
+type KeyProvider interface {
+	// GenerateDataKey returns a new data key wrapped by the primary key
+	GenerateDataKey(ctx context.Context) (*DataKey, error)
+
+	// DecryptDataKey unwraps a data key wrapped by the given key
+	DecryptDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+
+	// PrimaryKeyID is the key new data keys are wrapped by
+	PrimaryKeyID() string
+}
+
+// keyFile is the JSON layout of a local keyfile. Retired keys stay in the
+// file so that data encrypted under them can still be read.
+type keyFile struct {
+	Primary string `json:"primary"`
+	Keys    []struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	} `json:"keys"`
+}
+
+// LocalKeyProvider wraps data keys with AES-GCM key encryption keys loaded
+// from a local keyfile
+type LocalKeyProvider struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// LoadKeyFile loads a local key provider from a JSON keyfile listing base64
+// encoded 256-bit keys by ID and naming the primary one:
+//
+//	{"primary": "2026-10", "keys": [{"id": "2026-10", "key": "..."}]}
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyfile: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse keyfile: %w", err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for _, entry := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(entry.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in keyfile: %w", entry.ID, err)
+		}
+		if _, ok := keys[entry.ID]; ok {
+			return nil, fmt.Errorf("duplicate key %q in keyfile", entry.ID)
+		}
+		keys[entry.ID] = key
+	}
+
+	return NewLocalKeyProvider(file.Primary, keys)
+}
+
+// NewLocalKeyProvider creates a local key provider from key encryption keys
+// by ID
+func NewLocalKeyProvider(primary string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	provider := &LocalKeyProvider{
+		primary: primary,
+		keys:    make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for id, key := range keys {
+		if id == "" {
+			return nil, errors.New("key IDs cannot be empty")
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		provider.keys[id] = aead
+	}
+
+	if _, ok := provider.keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q is not in the keyfile", primary)
+	}
+
+	return provider, nil
+}
+
+// GenerateDataKey returns a new data key wrapped by the primary key
+func (p *LocalKeyProvider) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	plaintext, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := seal(p.keys[p.primary], plaintext, []byte(p.primary))
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return &DataKey{
+		KeyID:     p.primary,
+		Plaintext: plaintext,
+		Wrapped:   wrapped,
+	}, nil
+}
+
+// DecryptDataKey unwraps a data key wrapped by the given key
+func (p *LocalKeyProvider) DecryptDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	plaintext, err := open(aead, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// PrimaryKeyID is the key new data keys are wrapped by
+func (p *LocalKeyProvider) PrimaryKeyID() string {
+	return p.primary
+}
+
+// GenerateKey returns a new random 256-bit key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// newAEAD creates an AES-256-GCM cipher
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("keys must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce, returning nonce and
+// ciphertext together
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts the output of seal
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
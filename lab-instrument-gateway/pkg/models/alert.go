@@ -41,6 +41,8 @@ type Alert struct {
 	AcknowledgedBy  *string                `json:"acknowledged_by" db:"acknowledged_by"`
 	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
 	ResolvedAt      *time.Time             `json:"resolved_at" db:"resolved_at"`
+	Silenced        bool                   `json:"silenced" db:"silenced"`
+	SilencedBy      *string                `json:"silenced_by" db:"silenced_by"`
 }
 
 // Validate validates the alert data
@@ -70,23 +72,46 @@ func (a *Alert) Validate() error {
 	}
 	
 	// Validate type
-	validTypes := map[AlertType]bool{
-		AlertTypeDeviceOffline:  true,
-		AlertTypeDeviceError:    true,
-		AlertTypeCommandTimeout: true,
-		AlertTypeDataQuality:    true,
-		AlertTypeSystemHealth:   true,
-		AlertTypeSecurityBreach: true,
-		AlertTypePerformance:    true,
-	}
-	
-	if !validTypes[a.Type] {
+	if !a.Type.IsValid() {
 		return fmt.Errorf("invalid alert type: %s", a.Type)
 	}
 	
 	return nil
 }
 
+// IsValid returns true if the alert type is one of the known types
+func (t AlertType) IsValid() bool {
+	switch t {
+	case AlertTypeDeviceOffline,
+		AlertTypeDeviceError,
+		AlertTypeCommandTimeout,
+		AlertTypeDataQuality,
+		AlertTypeSystemHealth,
+		AlertTypeSecurityBreach,
+		AlertTypePerformance:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rank returns the ordering of the severity, from 1 for info to 4 for
+// critical, or 0 if the severity is unknown
+func (s AlertSeverity) Rank() int {
+	switch s {
+	case AlertSeverityInfo:
+		return 1
+	case AlertSeverityWarning:
+		return 2
+	case AlertSeverityError:
+		return 3
+	case AlertSeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
 // IsResolved returns true if the alert has been resolved
 func (a *Alert) IsResolved() bool {
 	return a.ResolvedAt != nil
@@ -125,4 +150,10 @@ func (a *Alert) Acknowledge(acknowledgedBy string) {
 func (a *Alert) Resolve() {
 	now := time.Now()
 	a.ResolvedAt = &now
+}
+
+// Silence flags the alert as muted by the given silence ID or reason
+func (a *Alert) Silence(silencedBy string) {
+	a.Silenced = true
+	a.SilencedBy = &silencedBy
 }
\ No newline at end of file
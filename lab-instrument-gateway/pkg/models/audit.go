@@ -0,0 +1,132 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditOutcomeSuccess is the outcome of an audited action that succeeded.
+// Failed actions record their gRPC status code instead.
+const AuditOutcomeSuccess = "OK"
+
+// AuditActorSystem is the actor of actions the gateway takes on its own
+const AuditActorSystem = "system"
+
+// AuditActionAlertEscalated is the action of notifying the next escalation
+// step of an alert. Actions requested through an RPC are named after its
+// full method instead.
+const AuditActionAlertEscalated = "alerting/Escalate"
+
+// AuditEntry is a record in the append-only audit trail. Entries form a hash
+// chain: each entry's hash covers its contents and the previous entry's hash,
+// so altering, removing or reordering an entry breaks every later link.
+type AuditEntry struct {
+	ID            string                 `json:"id" db:"id"`
+	Sequence      int64                  `json:"sequence" db:"sequence"`
+	OccurredAt    time.Time              `json:"occurred_at" db:"occurred_at"`
+	Actor         string                 `json:"actor" db:"actor"`
+	AuthMethod    string                 `json:"auth_method" db:"auth_method"`
+	Action        string                 `json:"action" db:"action"`
+	ResourceType  string                 `json:"resource_type" db:"resource_type"`
+	ResourceID    string                 `json:"resource_id" db:"resource_id"`
+	Reason        string                 `json:"reason" db:"reason"`
+	Outcome       string                 `json:"outcome" db:"outcome"`
+	Before        map[string]interface{} `json:"before" db:"before"`
+	After         map[string]interface{} `json:"after" db:"after"`
+	CorrelationID string                 `json:"correlation_id" db:"correlation_id"`
+	PreviousHash  string                 `json:"previous_hash" db:"previous_hash"`
+	Hash          string                 `json:"hash" db:"hash"`
+}
+
+// Validate validates the audit entry data
+func (e *AuditEntry) Validate() error {
+	if e.Actor == "" {
+		return fmt.Errorf("audit actor is required")
+	}
+
+	if e.Action == "" {
+		return fmt.Errorf("audit action is required")
+	}
+
+	if e.Outcome == "" {
+		return fmt.Errorf("audit outcome is required")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the audit entry. The timestamp is kept
+// at the microsecond precision of the database so that the hash computed on
+// insert matches the stored entry.
+func (e *AuditEntry) SetDefaults() {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	e.OccurredAt = e.OccurredAt.UTC().Truncate(time.Microsecond)
+}
+
+// ComputeHash returns the hex SHA-256 hash of the entry, covering every field
+// except the hash itself. Before and after values are hashed in a canonical
+// form, so that entries read back from the database hash identically.
+func (e *AuditEntry) ComputeHash() (string, error) {
+	before, err := canonicalAuditValue(e.Before)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize before value: %w", err)
+	}
+	after, err := canonicalAuditValue(e.After)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize after value: %w", err)
+	}
+
+	content, err := json.Marshal([]interface{}{
+		e.ID,
+		e.Sequence,
+		e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		e.Actor,
+		e.AuthMethod,
+		e.Action,
+		e.ResourceType,
+		e.ResourceID,
+		e.Reason,
+		e.Outcome,
+		before,
+		after,
+		e.CorrelationID,
+		e.PreviousHash,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalAuditValue round-trips a value through JSON, as storing it does:
+// numbers become float64 and an empty value becomes nil
+func canonicalAuditValue(value map[string]interface{}) (interface{}, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var canonical interface{}
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return nil, err
+	}
+
+	return canonical, nil
+}
@@ -20,6 +20,9 @@ const (
 	CommandStatusCancelled CommandStatus = "cancelled"
 )
 
+// CommandTypeCalibrate is the type of the commands that recalibrate a device
+const CommandTypeCalibrate = "calibrate"
+
 // Command represents a command sent to a device
 type Command struct {
 	ID              string                 `json:"id" db:"id"`
@@ -39,6 +42,9 @@ type Command struct {
 	ExecutionTimeMs *float64               `json:"execution_time_ms" db:"execution_time_ms"`
 	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+
+	// Signatures are the electronic signatures the command was submitted with
+	Signatures []*CommandSignature `json:"signatures,omitempty" db:"-"`
 }
 
 // CommandResult represents the result of a command execution
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EnrollmentToken is a one-time secret that lets a device of the given type
+// enroll and obtain a long-lived credential. Only a hash of the token is stored.
+type EnrollmentToken struct {
+	ID           string     `json:"id" db:"id"`
+	TokenHash    string     `json:"-" db:"token_hash"`
+	DeviceType   string     `json:"device_type" db:"device_type"`
+	DeviceGroup  *string    `json:"device_group" db:"device_group"`
+	CreatedBy    string     `json:"created_by" db:"created_by"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt       *time.Time `json:"used_at" db:"used_at"`
+	UsedByDevice *string    `json:"used_by_device" db:"used_by_device"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the enrollment token data
+func (t *EnrollmentToken) Validate() error {
+	if t.TokenHash == "" {
+		return fmt.Errorf("enrollment token hash is required")
+	}
+
+	if t.DeviceType == "" {
+		return fmt.Errorf("enrollment token device type is required")
+	}
+
+	if t.CreatedBy == "" {
+		return fmt.Errorf("enrollment token creator is required")
+	}
+
+	if t.ExpiresAt.IsZero() {
+		return fmt.Errorf("enrollment token expiry is required")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the enrollment token
+func (t *EnrollmentToken) SetDefaults() {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+}
+
+// IsUsable returns true if the token has not been used and has not expired
+func (t *EnrollmentToken) IsUsable(at time.Time) bool {
+	return t.UsedAt == nil && at.Before(t.ExpiresAt)
+}
+
+// APIKey is a long-lived secret credential. The key is shown once when it is
+// created; the prefix identifies it and only a hash of the full key is stored.
+// Service keys grant Roles, limited to DeviceGroups when any are listed;
+// device keys are bound to DeviceID instead.
+type APIKey struct {
+	ID           string     `json:"id" db:"id"`
+	Prefix       string     `json:"prefix" db:"prefix"`
+	KeyHash      string     `json:"-" db:"key_hash"`
+	Name         string     `json:"name" db:"name"`
+	Subject      string     `json:"subject" db:"subject"`
+	Roles        []string   `json:"roles" db:"roles"`
+	DeviceGroups []string   `json:"device_groups" db:"device_groups"`
+	DeviceID     *string    `json:"device_id" db:"device_id"`
+	ExpiresAt    *time.Time `json:"expires_at" db:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at" db:"revoked_at"`
+	LastUsedAt   *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedBy    string     `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the API key data
+func (k *APIKey) Validate() error {
+	if k.Prefix == "" || k.KeyHash == "" {
+		return fmt.Errorf("API key prefix and hash are required")
+	}
+
+	if k.Subject == "" {
+		return fmt.Errorf("API key subject is required")
+	}
+
+	if k.CreatedBy == "" {
+		return fmt.Errorf("API key creator is required")
+	}
+
+	if k.DeviceID != nil && (len(k.Roles) > 0 || len(k.DeviceGroups) > 0) {
+		return fmt.Errorf("device API keys cannot carry roles or device groups")
+	}
+
+	if k.DeviceID == nil && len(k.Roles) == 0 {
+		return fmt.Errorf("API key requires at least one role")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the API key
+func (k *APIKey) SetDefaults() {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now()
+	}
+}
+
+// IsActive returns true if the key has not been revoked and has not expired
+func (k *APIKey) IsActive(at time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || at.Before(*k.ExpiresAt)
+}
+
+// DeviceCertificate records a client certificate issued to a device by the
+// gateway's local CA
+type DeviceCertificate struct {
+	SerialNumber string    `json:"serial_number" db:"serial_number"`
+	DeviceID     string    `json:"device_id" db:"device_id"`
+	Subject      string    `json:"subject" db:"subject"`
+	NotBefore    time.Time `json:"not_before" db:"not_before"`
+	NotAfter     time.Time `json:"not_after" db:"not_after"`
+	IssuedAt     time.Time `json:"issued_at" db:"issued_at"`
+}
@@ -38,14 +38,16 @@ type Device struct {
 
 // DeviceSession represents an active device connection session
 type DeviceSession struct {
-	ID            string                 `json:"id" db:"id"`
-	DeviceID      string                 `json:"device_id" db:"device_id"`
-	SessionID     string                 `json:"session_id" db:"session_id"`
-	StreamID      *string                `json:"stream_id" db:"stream_id"`
-	ConnectedAt   time.Time              `json:"connected_at" db:"connected_at"`
-	LastHeartbeat time.Time              `json:"last_heartbeat" db:"last_heartbeat"`
-	Metadata      map[string]interface{} `json:"metadata" db:"metadata"`
-	IsActive      bool                   `json:"is_active" db:"is_active"`
+	ID               string                 `json:"id" db:"id"`
+	DeviceID         string                 `json:"device_id" db:"device_id"`
+	SessionID        string                 `json:"session_id" db:"session_id"`
+	StreamID         *string                `json:"stream_id" db:"stream_id"`
+	ConnectedAt      time.Time              `json:"connected_at" db:"connected_at"`
+	LastHeartbeat    time.Time              `json:"last_heartbeat" db:"last_heartbeat"`
+	Metadata         map[string]interface{} `json:"metadata" db:"metadata"`
+	IsActive         bool                   `json:"is_active" db:"is_active"`
+	DisconnectedAt   *time.Time             `json:"disconnected_at" db:"disconnected_at"`
+	DisconnectReason *string                `json:"disconnect_reason" db:"disconnect_reason"`
 }
 
 // Validate validates the device data
@@ -99,6 +101,13 @@ func (d *Device) HasCapability(capability string) bool {
 	return false
 }
 
+// Group returns the device group from the device metadata, or "" if the
+// device is not assigned to a group
+func (d *Device) Group() string {
+	group, _ := d.Metadata[DeviceGroupMetadataKey].(string)
+	return group
+}
+
 // UpdateLastSeen updates the last seen timestamp
 func (d *Device) UpdateLastSeen() {
 	now := time.Now()
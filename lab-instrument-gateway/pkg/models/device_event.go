@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceEventType represents the kind of device lifecycle event
+type DeviceEventType string
+
+const (
+	DeviceEventRegistered       DeviceEventType = "registered"
+	DeviceEventStreamOpened     DeviceEventType = "stream_opened"
+	DeviceEventStreamClosed     DeviceEventType = "stream_closed"
+	DeviceEventHeartbeatLost    DeviceEventType = "heartbeat_lost"
+	DeviceEventHeartbeatResumed DeviceEventType = "heartbeat_resumed"
+	DeviceEventDisconnected     DeviceEventType = "disconnected"
+	DeviceEventStatusChanged    DeviceEventType = "status_changed"
+)
+
+// IsValid returns true if the event type is one of the known types
+func (t DeviceEventType) IsValid() bool {
+	switch t {
+	case DeviceEventRegistered,
+		DeviceEventStreamOpened,
+		DeviceEventStreamClosed,
+		DeviceEventHeartbeatLost,
+		DeviceEventHeartbeatResumed,
+		DeviceEventDisconnected,
+		DeviceEventStatusChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+// DeviceEvent is an entry in a device's lifecycle event log
+type DeviceEvent struct {
+	ID         string                 `json:"id" db:"id"`
+	DeviceID   string                 `json:"device_id" db:"device_id"`
+	Type       DeviceEventType        `json:"type" db:"type"`
+	SessionID  *string                `json:"session_id" db:"session_id"`
+	FromStatus *DeviceStatus          `json:"from_status" db:"from_status"`
+	ToStatus   *DeviceStatus          `json:"to_status" db:"to_status"`
+	Message    string                 `json:"message" db:"message"`
+	Metadata   map[string]interface{} `json:"metadata" db:"metadata"`
+	OccurredAt time.Time              `json:"occurred_at" db:"occurred_at"`
+}
+
+// Validate validates the device event data
+func (e *DeviceEvent) Validate() error {
+	if e.DeviceID == "" {
+		return fmt.Errorf("device ID is required")
+	}
+
+	if !e.Type.IsValid() {
+		return fmt.Errorf("invalid device event type: %s", e.Type)
+	}
+
+	if e.Type == DeviceEventStatusChanged && e.ToStatus == nil {
+		return fmt.Errorf("status change event requires a target status")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the device event
+func (e *DeviceEvent) SetDefaults() {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]interface{})
+	}
+}
+
+// Uptime returns the fraction of [start, end) the device spent online, as a
+// percentage. initial is the device status at start and changes are the
+// status change events within the window in chronological order.
+func Uptime(initial DeviceStatus, changes []*DeviceEvent, start, end time.Time) float64 {
+	window := end.Sub(start)
+	if window <= 0 {
+		return 0
+	}
+
+	var online time.Duration
+	current := initial
+	since := start
+
+	for _, change := range changes {
+		if change.Type != DeviceEventStatusChanged || change.ToStatus == nil {
+			continue
+		}
+
+		at := change.OccurredAt
+		if at.Before(start) {
+			at = start
+		}
+		if at.After(end) {
+			break
+		}
+
+		if current == DeviceStatusOnline {
+			online += at.Sub(since)
+		}
+		current = *change.ToStatus
+		since = at
+	}
+
+	if current == DeviceStatusOnline {
+		online += end.Sub(since)
+	}
+
+	return float64(online) / float64(window) * 100
+}
@@ -0,0 +1,214 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// rotationPeriod is how long each participant of an on-call schedule is on call
+const rotationPeriod = 7 * 24 * time.Hour
+
+// EscalationStep notifies a set of recipients once an unacknowledged alert
+// has been open for at least Delay
+type EscalationStep struct {
+	Delay      time.Duration `json:"delay"`
+	Recipients []string      `json:"recipients"`
+	ScheduleID *string       `json:"schedule_id"`
+}
+
+// EscalationPolicy is an ordered list of escalation steps
+type EscalationPolicy struct {
+	ID          string           `json:"id" db:"id"`
+	Name        string           `json:"name" db:"name"`
+	Description string           `json:"description" db:"description"`
+	Steps       []EscalationStep `json:"steps" db:"steps"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// Validate validates the escalation policy data
+func (p *EscalationPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("escalation policy name is required")
+	}
+
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("escalation policy requires at least one step")
+	}
+
+	for i, step := range p.Steps {
+		if step.Delay < 0 {
+			return fmt.Errorf("escalation step %d has a negative delay", i)
+		}
+		if i > 0 && step.Delay < p.Steps[i-1].Delay {
+			return fmt.Errorf("escalation step %d fires before the previous step", i)
+		}
+		if len(step.Recipients) == 0 && step.ScheduleID == nil {
+			return fmt.Errorf("escalation step %d has no recipients or schedule", i)
+		}
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the escalation policy
+func (p *EscalationPolicy) SetDefaults() {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = now
+	}
+}
+
+// NextStep returns the step to notify for an alert that has already been
+// escalated level times and has been open for age, or nil if the next step
+// is not due yet or the policy is exhausted
+func (p *EscalationPolicy) NextStep(level int, age time.Duration) *EscalationStep {
+	if level < 0 || level >= len(p.Steps) {
+		return nil
+	}
+
+	step := &p.Steps[level]
+	if age < step.Delay {
+		return nil
+	}
+
+	return step
+}
+
+// AlertRoute attaches an escalation policy to alerts matching all of its
+// matchers. A nil matcher matches any value.
+type AlertRoute struct {
+	ID          string         `json:"id" db:"id"`
+	Name        string         `json:"name" db:"name"`
+	Priority    int            `json:"priority" db:"priority"`
+	DeviceType  *string        `json:"device_type" db:"device_type"`
+	AlertType   *AlertType     `json:"alert_type" db:"alert_type"`
+	MinSeverity *AlertSeverity `json:"min_severity" db:"min_severity"`
+	PolicyID    string         `json:"policy_id" db:"policy_id"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the alert route data
+func (r *AlertRoute) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("alert route name is required")
+	}
+
+	if r.PolicyID == "" {
+		return fmt.Errorf("alert route policy is required")
+	}
+
+	if r.AlertType != nil && !r.AlertType.IsValid() {
+		return fmt.Errorf("invalid alert type: %s", *r.AlertType)
+	}
+
+	if r.MinSeverity != nil && r.MinSeverity.Rank() == 0 {
+		return fmt.Errorf("invalid alert severity: %s", *r.MinSeverity)
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the alert route
+func (r *AlertRoute) SetDefaults() {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+}
+
+// Matches returns true if the alert, raised for a device of the given type,
+// satisfies every matcher of the route
+func (r *AlertRoute) Matches(alert *Alert, deviceType string) bool {
+	if r.DeviceType != nil && *r.DeviceType != deviceType {
+		return false
+	}
+
+	if r.AlertType != nil && *r.AlertType != alert.Type {
+		return false
+	}
+
+	if r.MinSeverity != nil && alert.Severity.Rank() < r.MinSeverity.Rank() {
+		return false
+	}
+
+	return true
+}
+
+// OnCallSchedule is a weekly rotation through a list of participants
+type OnCallSchedule struct {
+	ID            string    `json:"id" db:"id"`
+	Name          string    `json:"name" db:"name"`
+	RotationStart time.Time `json:"rotation_start" db:"rotation_start"`
+	Participants  []string  `json:"participants" db:"participants"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the on-call schedule data
+func (s *OnCallSchedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("on-call schedule name is required")
+	}
+
+	if s.RotationStart.IsZero() {
+		return fmt.Errorf("on-call schedule rotation start is required")
+	}
+
+	if len(s.Participants) == 0 {
+		return fmt.Errorf("on-call schedule requires at least one participant")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the on-call schedule
+func (s *OnCallSchedule) SetDefaults() {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+}
+
+// OnCall returns the participant on call at the given time
+func (s *OnCallSchedule) OnCall(at time.Time) string {
+	if len(s.Participants) == 0 {
+		return ""
+	}
+
+	elapsed := at.Sub(s.RotationStart)
+	weeks := int(elapsed / rotationPeriod)
+	if elapsed%rotationPeriod < 0 {
+		// Integer division truncates toward zero; step back into the previous week
+		weeks--
+	}
+
+	index := weeks % len(s.Participants)
+	if index < 0 {
+		index += len(s.Participants)
+	}
+
+	return s.Participants[index]
+}
+
+// AlertEscalation records how far an alert has progressed through its policy
+type AlertEscalation struct {
+	AlertID         string    `json:"alert_id" db:"alert_id"`
+	PolicyID        string    `json:"policy_id" db:"policy_id"`
+	Level           int       `json:"level" db:"level"`
+	LastEscalatedAt time.Time `json:"last_escalated_at" db:"last_escalated_at"`
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceGroupMetadataKey is the device metadata key naming the group (for
+// example the lab) a device belongs to. Role bindings can be scoped to a group.
+const DeviceGroupMetadataKey = "device_group"
+
+// Permission is an action a role allows
+type Permission string
+
+const (
+	PermissionDevicesRead       Permission = "devices:read"
+	PermissionDevicesWrite      Permission = "devices:write"
+	PermissionMeasurementsRead  Permission = "measurements:read"
+	PermissionMeasurementsWrite Permission = "measurements:write"
+	PermissionCommandsSend      Permission = "commands:send"
+	PermissionCommandsSign      Permission = "commands:sign"
+	PermissionAlertsRead        Permission = "alerts:read"
+	PermissionAlertsManage      Permission = "alerts:manage"
+	PermissionAccessManage      Permission = "access:manage"
+	PermissionAuditRead         Permission = "audit:read"
+)
+
+// Built-in role names
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+	RoleDevice   = "device"
+)
+
+// validPermissions lists every permission known to the gateway
+var validPermissions = map[Permission]bool{
+	PermissionDevicesRead:       true,
+	PermissionDevicesWrite:      true,
+	PermissionMeasurementsRead:  true,
+	PermissionMeasurementsWrite: true,
+	PermissionCommandsSend:      true,
+	PermissionCommandsSign:      true,
+	PermissionAlertsRead:        true,
+	PermissionAlertsManage:      true,
+	PermissionAccessManage:      true,
+	PermissionAuditRead:         true,
+}
+
+// IsValid returns true if the permission is known
+func (p Permission) IsValid() bool {
+	return validPermissions[p]
+}
+
+// Role is a named set of permissions
+type Role struct {
+	Name        string       `json:"name" db:"name"`
+	Description string       `json:"description" db:"description"`
+	Permissions []Permission `json:"permissions" db:"permissions"`
+	BuiltIn     bool         `json:"built_in" db:"built_in"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// Validate validates the role data
+func (r *Role) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("role name is required")
+	}
+
+	for _, permission := range r.Permissions {
+		if !permission.IsValid() {
+			return fmt.Errorf("invalid permission: %s", permission)
+		}
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the role
+func (r *Role) SetDefaults() {
+	now := time.Now()
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now
+	}
+	if r.UpdatedAt.IsZero() {
+		r.UpdatedAt = now
+	}
+}
+
+// HasPermission returns true if the role grants the permission
+func (r *Role) HasPermission(permission Permission) bool {
+	return slices.Contains(r.Permissions, permission)
+}
+
+// RoleBinding grants a role to a subject, either for all devices or only for
+// the devices of one group
+type RoleBinding struct {
+	ID          string    `json:"id" db:"id"`
+	Subject     string    `json:"subject" db:"subject"`
+	Role        string    `json:"role" db:"role"`
+	DeviceGroup *string   `json:"device_group" db:"device_group"`
+	CreatedBy   string    `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the role binding data
+func (b *RoleBinding) Validate() error {
+	if b.Subject == "" {
+		return fmt.Errorf("role binding subject is required")
+	}
+
+	if b.Role == "" {
+		return fmt.Errorf("role binding role is required")
+	}
+
+	if b.DeviceGroup != nil && *b.DeviceGroup == "" {
+		return fmt.Errorf("role binding device group cannot be empty")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the role binding
+func (b *RoleBinding) SetDefaults() {
+	if b.ID == "" {
+		b.ID = uuid.New().String()
+	}
+
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now()
+	}
+}
+
+// IsGlobal returns true if the binding applies to devices of every group
+func (b *RoleBinding) IsGlobal() bool {
+	return b.DeviceGroup == nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignatureMeaning is what an electronic signature attests
+type SignatureMeaning string
+
+const (
+	SignatureMeaningAuthored SignatureMeaning = "authored"
+	SignatureMeaningReviewed SignatureMeaning = "reviewed"
+	SignatureMeaningApproved SignatureMeaning = "approved"
+	SignatureMeaningVerified SignatureMeaning = "verified"
+)
+
+// validSignatureMeanings lists every meaning a signature may carry
+var validSignatureMeanings = map[SignatureMeaning]bool{
+	SignatureMeaningAuthored: true,
+	SignatureMeaningReviewed: true,
+	SignatureMeaningApproved: true,
+	SignatureMeaningVerified: true,
+}
+
+// IsValid returns true if the meaning is known
+func (m SignatureMeaning) IsValid() bool {
+	return validSignatureMeanings[m]
+}
+
+// CommandSignature is an electronic signature on a command: a signer who
+// authenticated again to sign, and the meaning of their signature. The
+// content hash ties the signature to the command as it was signed.
+type CommandSignature struct {
+	ID          string           `json:"id" db:"id"`
+	CommandID   string           `json:"command_id" db:"command_id"`
+	Signer      string           `json:"signer" db:"signer"`
+	AuthMethod  string           `json:"auth_method" db:"auth_method"`
+	Meaning     SignatureMeaning `json:"meaning" db:"meaning"`
+	Comment     string           `json:"comment,omitempty" db:"comment"`
+	ContentHash string           `json:"content_hash" db:"content_hash"`
+	SignedAt    time.Time        `json:"signed_at" db:"signed_at"`
+
+	// TokenID identifies the credential the signer authenticated with; a
+	// credential signs only once
+	TokenID string `json:"-" db:"token_id"`
+}
+
+// Validate validates the signature data
+func (s *CommandSignature) Validate() error {
+	if s.CommandID == "" {
+		return fmt.Errorf("signed command ID is required")
+	}
+
+	if s.Signer == "" {
+		return fmt.Errorf("signer is required")
+	}
+
+	if !s.Meaning.IsValid() {
+		return fmt.Errorf("invalid signature meaning: %s", s.Meaning)
+	}
+
+	if s.ContentHash == "" {
+		return fmt.Errorf("signature content hash is required")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the signature
+func (s *CommandSignature) SetDefaults() {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+
+	if s.SignedAt.IsZero() {
+		s.SignedAt = time.Now()
+	}
+}
+
+// ContentHash returns the hex SHA-256 hash of what a signature on the command
+// covers: the target device, the command type and its parameters
+func (c *Command) ContentHash() (string, error) {
+	content, err := json.Marshal([]interface{}{
+		c.DeviceID,
+		c.Type,
+		c.Parameters,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode command: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
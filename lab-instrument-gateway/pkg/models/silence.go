@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaintenanceSilence is recorded as the silencer of alerts muted because
+// their device was in maintenance when the alert was raised
+const MaintenanceSilence = "maintenance"
+
+// Silence mutes alerts that match all of its matchers during a time window.
+// A nil matcher matches any value.
+type Silence struct {
+	ID         string     `json:"id" db:"id"`
+	DeviceID   *string    `json:"device_id" db:"device_id"`
+	DeviceType *string    `json:"device_type" db:"device_type"`
+	AlertType  *AlertType `json:"alert_type" db:"alert_type"`
+	StartsAt   time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time  `json:"ends_at" db:"ends_at"`
+	CreatedBy  string     `json:"created_by" db:"created_by"`
+	Comment    string     `json:"comment" db:"comment"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the silence data
+func (s *Silence) Validate() error {
+	if s.CreatedBy == "" {
+		return fmt.Errorf("silence creator is required")
+	}
+
+	if s.StartsAt.IsZero() || s.EndsAt.IsZero() {
+		return fmt.Errorf("silence start and end times are required")
+	}
+
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("silence must end after it starts")
+	}
+
+	// A silence without matchers would mute every alert in the system
+	if s.DeviceID == nil && s.DeviceType == nil && s.AlertType == nil {
+		return fmt.Errorf("silence requires at least one matcher")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the silence
+func (s *Silence) SetDefaults() {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+
+	if s.StartsAt.IsZero() {
+		s.StartsAt = now
+	}
+}
+
+// IsActive returns true if the silence window contains the given time
+func (s *Silence) IsActive(at time.Time) bool {
+	return !at.Before(s.StartsAt) && at.Before(s.EndsAt)
+}
+
+// Matches returns true if the alert, raised for a device of the given type,
+// satisfies every matcher of the silence
+func (s *Silence) Matches(alert *Alert, deviceType string) bool {
+	if s.DeviceID != nil {
+		if alert.DeviceID == nil || *alert.DeviceID != *s.DeviceID {
+			return false
+		}
+	}
+
+	if s.DeviceType != nil && *s.DeviceType != deviceType {
+		return false
+	}
+
+	if s.AlertType != nil && *s.AlertType != alert.Type {
+		return false
+	}
+
+	return true
+}
+
+// Expire ends the silence immediately. A silence that has not started yet
+// is collapsed to an empty window so that it never becomes active.
+func (s *Silence) Expire() {
+	now := time.Now()
+	if s.StartsAt.After(now) {
+		s.StartsAt = now
+	}
+	if now.Before(s.EndsAt) {
+		s.EndsAt = now
+	}
+}
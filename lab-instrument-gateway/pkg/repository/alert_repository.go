@@ -15,12 +15,12 @@ import (
 
 // alertRepository implements AlertRepository interface
 type alertRepository struct {
-	db     *db.ConnectionManager
+	db     db.Executor
 	logger *logger.Logger
 }
 
 // NewAlertRepository creates a new alert repository
-func NewAlertRepository(db *db.ConnectionManager, logger *logger.Logger) AlertRepository {
+func NewAlertRepository(db db.Executor, logger *logger.Logger) AlertRepository {
 	return &alertRepository{
 		db:     db,
 		logger: logger,
@@ -29,6 +29,9 @@ func NewAlertRepository(db *db.ConnectionManager, logger *logger.Logger) AlertRe
 
 // Create creates a new alert
 func (r *alertRepository) Create(ctx context.Context, alert *models.Alert) error {
+	ctx, span := startSpan(ctx, "alert", "Create")
+	defer span.End()
+
 	if err := alert.Validate(); err != nil {
 		return fmt.Errorf("alert validation failed: %w", err)
 	}
@@ -36,8 +39,8 @@ func (r *alertRepository) Create(ctx context.Context, alert *models.Alert) error
 	alert.SetDefaults()
 
 	query := `
-		INSERT INTO alerts (id, device_id, type, severity, message, metadata, acknowledged, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO alerts (id, device_id, type, severity, message, metadata, acknowledged, created_at, silenced, silenced_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	metadataJSON, err := marshalJSON(alert.Metadata)
@@ -54,6 +57,8 @@ func (r *alertRepository) Create(ctx context.Context, alert *models.Alert) error
 		metadataJSON,
 		alert.Acknowledged,
 		alert.CreatedAt,
+		alert.Silenced,
+		alert.SilencedBy,
 	)
 
 	if err != nil {
@@ -80,9 +85,12 @@ func (r *alertRepository) Create(ctx context.Context, alert *models.Alert) error
 
 // GetByID retrieves an alert by ID
 func (r *alertRepository) GetByID(ctx context.Context, id string) (*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetByID")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, type, severity, message, metadata, acknowledged, acknowledged_by, 
-		       acknowledged_at, resolved_at, created_at
+		       acknowledged_at, resolved_at, created_at, silenced, silenced_by
 		FROM alerts
 		WHERE id = $1
 	`
@@ -102,11 +110,13 @@ func (r *alertRepository) GetByID(ctx context.Context, id string) (*models.Alert
 		&alert.AcknowledgedAt,
 		&alert.ResolvedAt,
 		&alert.CreatedAt,
+		&alert.Silenced,
+		&alert.SilencedBy,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("alert not found: %s", id)
+			return nil, fmt.Errorf("%w: alert %s", ErrNotFound, id)
 		}
 		r.logger.WithError(err).Error("Failed to get alert")
 		return nil, fmt.Errorf("failed to get alert: %w", err)
@@ -121,6 +131,9 @@ func (r *alertRepository) GetByID(ctx context.Context, id string) (*models.Alert
 
 // Update updates an existing alert
 func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error {
+	ctx, span := startSpan(ctx, "alert", "Update")
+	defer span.End()
+
 	if err := alert.Validate(); err != nil {
 		return fmt.Errorf("alert validation failed: %w", err)
 	}
@@ -128,7 +141,7 @@ func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error
 	query := `
 		UPDATE alerts 
 		SET type = $2, severity = $3, message = $4, metadata = $5, acknowledged = $6, 
-		    acknowledged_by = $7, acknowledged_at = $8, resolved_at = $9
+		    acknowledged_by = $7, acknowledged_at = $8, resolved_at = $9, silenced = $10, silenced_by = $11
 		WHERE id = $1
 	`
 
@@ -147,6 +160,8 @@ func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error
 		alert.AcknowledgedBy,
 		alert.AcknowledgedAt,
 		alert.ResolvedAt,
+		alert.Silenced,
+		alert.SilencedBy,
 	)
 
 	if err != nil {
@@ -164,7 +179,7 @@ func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("alert not found: %s", alert.ID)
+		return fmt.Errorf("%w: alert %s", ErrNotFound, alert.ID)
 	}
 
 	deviceID := ""
@@ -181,6 +196,9 @@ func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error
 
 // Delete removes an alert
 func (r *alertRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "alert", "Delete")
+	defer span.End()
+
 	query := `DELETE FROM alerts WHERE id = $1`
 
 	result, err := r.db.ExecContext(ctx, query, id)
@@ -195,7 +213,7 @@ func (r *alertRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("alert not found: %s", id)
+		return fmt.Errorf("%w: alert %s", ErrNotFound, id)
 	}
 
 	r.logger.WithFields(map[string]interface{}{
@@ -207,9 +225,12 @@ func (r *alertRepository) Delete(ctx context.Context, id string) error {
 
 // List retrieves alerts with filtering and pagination
 func (r *alertRepository) List(ctx context.Context, filter AlertFilter) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "List")
+	defer span.End()
+
 	query, args := r.buildListQuery(filter)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to list alerts")
 		return nil, fmt.Errorf("failed to list alerts: %w", err)
@@ -233,6 +254,8 @@ func (r *alertRepository) List(ctx context.Context, filter AlertFilter) ([]*mode
 			&alert.AcknowledgedAt,
 			&alert.ResolvedAt,
 			&alert.CreatedAt,
+			&alert.Silenced,
+			&alert.SilencedBy,
 		)
 
 		if err != nil {
@@ -257,10 +280,13 @@ func (r *alertRepository) List(ctx context.Context, filter AlertFilter) ([]*mode
 
 // Count returns the total number of alerts matching the filter
 func (r *alertRepository) Count(ctx context.Context, filter AlertFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "alert", "Count")
+	defer span.End()
+
 	query, args := r.buildCountQuery(filter)
 
 	var count int64
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := db.ForRead(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to count alerts")
 		return 0, fmt.Errorf("failed to count alerts: %w", err)
@@ -271,6 +297,9 @@ func (r *alertRepository) Count(ctx context.Context, filter AlertFilter) (int64,
 
 // Acknowledge acknowledges an alert
 func (r *alertRepository) Acknowledge(ctx context.Context, alertID string, acknowledgedBy string) error {
+	ctx, span := startSpan(ctx, "alert", "Acknowledge")
+	defer span.End()
+
 	query := `
 		UPDATE alerts 
 		SET acknowledged = true, acknowledged_by = $2, acknowledged_at = $3
@@ -293,7 +322,7 @@ func (r *alertRepository) Acknowledge(ctx context.Context, alertID string, ackno
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("alert not found or already acknowledged: %s", alertID)
+		return fmt.Errorf("%w: unacknowledged alert %s", ErrNotFound, alertID)
 	}
 
 	r.logger.WithFields(map[string]interface{}{
@@ -306,6 +335,9 @@ func (r *alertRepository) Acknowledge(ctx context.Context, alertID string, ackno
 
 // Resolve resolves an alert
 func (r *alertRepository) Resolve(ctx context.Context, alertID string) error {
+	ctx, span := startSpan(ctx, "alert", "Resolve")
+	defer span.End()
+
 	query := `
 		UPDATE alerts 
 		SET resolved_at = $2
@@ -327,7 +359,7 @@ func (r *alertRepository) Resolve(ctx context.Context, alertID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("alert not found or already resolved: %s", alertID)
+		return fmt.Errorf("%w: unresolved alert %s", ErrNotFound, alertID)
 	}
 
 	r.logger.WithFields(map[string]interface{}{
@@ -339,9 +371,12 @@ func (r *alertRepository) Resolve(ctx context.Context, alertID string) error {
 
 // GetUnacknowledged retrieves all unacknowledged alerts
 func (r *alertRepository) GetUnacknowledged(ctx context.Context) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetUnacknowledged")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, type, severity, message, metadata, acknowledged, acknowledged_by, 
-		       acknowledged_at, resolved_at, created_at
+		       acknowledged_at, resolved_at, created_at, silenced, silenced_by
 		FROM alerts
 		WHERE acknowledged = false
 		ORDER BY severity DESC, created_at DESC
@@ -352,9 +387,12 @@ func (r *alertRepository) GetUnacknowledged(ctx context.Context) ([]*models.Aler
 
 // GetUnresolved retrieves all unresolved alerts
 func (r *alertRepository) GetUnresolved(ctx context.Context) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetUnresolved")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, type, severity, message, metadata, acknowledged, acknowledged_by, 
-		       acknowledged_at, resolved_at, created_at
+		       acknowledged_at, resolved_at, created_at, silenced, silenced_by
 		FROM alerts
 		WHERE resolved_at IS NULL
 		ORDER BY severity DESC, created_at DESC
@@ -365,9 +403,12 @@ func (r *alertRepository) GetUnresolved(ctx context.Context) ([]*models.Alert, e
 
 // GetCriticalAlerts retrieves all critical alerts
 func (r *alertRepository) GetCriticalAlerts(ctx context.Context) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetCriticalAlerts")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, type, severity, message, metadata, acknowledged, acknowledged_by, 
-		       acknowledged_at, resolved_at, created_at
+		       acknowledged_at, resolved_at, created_at, silenced, silenced_by
 		FROM alerts
 		WHERE severity = 'critical' AND resolved_at IS NULL
 		ORDER BY created_at DESC
@@ -378,6 +419,9 @@ func (r *alertRepository) GetCriticalAlerts(ctx context.Context) ([]*models.Aler
 
 // GetAlertStats retrieves alert statistics for a time range
 func (r *alertRepository) GetAlertStats(ctx context.Context, timeRange TimeRangeFilter) (map[models.AlertSeverity]int64, error) {
+	ctx, span := startSpan(ctx, "alert", "GetAlertStats")
+	defer span.End()
+
 	query := `
 		SELECT severity, COUNT(*) as count
 		FROM alerts
@@ -401,7 +445,7 @@ func (r *alertRepository) GetAlertStats(ctx context.Context, timeRange TimeRange
 
 	query += " GROUP BY severity"
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to get alert statistics")
 		return nil, fmt.Errorf("failed to get alert statistics: %w", err)
@@ -427,9 +471,12 @@ func (r *alertRepository) GetAlertStats(ctx context.Context, timeRange TimeRange
 
 // GetAlertsByDevice retrieves alerts for a specific device
 func (r *alertRepository) GetAlertsByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetAlertsByDevice")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, type, severity, message, metadata, acknowledged, acknowledged_by, 
-		       acknowledged_at, resolved_at, created_at
+		       acknowledged_at, resolved_at, created_at, silenced, silenced_by
 		FROM alerts
 		WHERE device_id = $1
 		ORDER BY created_at DESC
@@ -448,6 +495,9 @@ func (r *alertRepository) GetAlertsByDevice(ctx context.Context, deviceID string
 
 // DeleteResolvedOlderThan removes resolved alerts older than the specified threshold
 func (r *alertRepository) DeleteResolvedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "alert", "DeleteResolvedOlderThan")
+	defer span.End()
+
 	query := `
 		DELETE FROM alerts 
 		WHERE resolved_at IS NOT NULL AND resolved_at < $1
@@ -507,6 +557,8 @@ func (r *alertRepository) scanAlerts(rows *sql.Rows) ([]*models.Alert, error) {
 			&alert.AcknowledgedAt,
 			&alert.ResolvedAt,
 			&alert.CreatedAt,
+			&alert.Silenced,
+			&alert.SilencedBy,
 		)
 
 		if err != nil {
@@ -529,7 +581,7 @@ func (r *alertRepository) scanAlerts(rows *sql.Rows) ([]*models.Alert, error) {
 func (r *alertRepository) buildListQuery(filter AlertFilter) (string, []interface{}) {
 	query := `
 		SELECT id, device_id, type, severity, message, metadata, acknowledged, acknowledged_by, 
-		       acknowledged_at, resolved_at, created_at
+		       acknowledged_at, resolved_at, created_at, silenced, silenced_by
 		FROM alerts
 	`
 
@@ -578,6 +630,12 @@ func (r *alertRepository) buildListQuery(filter AlertFilter) (string, []interfac
 		}
 	}
 
+	if filter.Silenced != nil {
+		conditions = append(conditions, fmt.Sprintf("silenced = $%d", argIndex))
+		args = append(args, *filter.Silenced)
+		argIndex++
+	}
+
 	if filter.StartTime != nil {
 		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIndex))
 		args = append(args, *filter.StartTime)
@@ -670,6 +728,12 @@ func (r *alertRepository) buildCountQuery(filter AlertFilter) (string, []interfa
 		}
 	}
 
+	if filter.Silenced != nil {
+		conditions = append(conditions, fmt.Sprintf("silenced = $%d", argIndex))
+		args = append(args, *filter.Silenced)
+		argIndex++
+	}
+
 	if filter.StartTime != nil {
 		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIndex))
 		args = append(args, *filter.StartTime)
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// apiKeyColumns lists the columns selected for API key queries
+const apiKeyColumns = `id, prefix, key_hash, name, subject, roles, device_groups, device_id, expires_at, revoked_at, last_used_at, created_by, created_at`
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	db     db.Executor
+	logger *logger.Logger
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db db.Executor, logger *logger.Logger) APIKeyRepository {
+	return &apiKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	ctx, span := startSpan(ctx, "api_key", "Create")
+	defer span.End()
+
+	key.SetDefaults()
+
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("API key validation failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO api_keys (id, prefix, key_hash, name, subject, roles, device_groups, device_id, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID,
+		key.Prefix,
+		key.KeyHash,
+		key.Name,
+		key.Subject,
+		pq.Array(nonNilStrings(key.Roles)),
+		pq.Array(nonNilStrings(key.DeviceGroups)),
+		key.DeviceID,
+		key.ExpiresAt,
+		key.CreatedBy,
+		key.CreatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("key_id", key.ID).WithError(err).Error("Failed to create API key")
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"key_id":     key.ID,
+		"prefix":     key.Prefix,
+		"subject":    key.Subject,
+		"created_by": key.CreatedBy,
+	}).Info("API key created successfully")
+
+	return nil
+}
+
+// GetByID retrieves an API key by ID
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	ctx, span := startSpan(ctx, "api_key", "GetByID")
+	defer span.End()
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+
+	key, err := r.scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: API key %s", ErrNotFound, id)
+		}
+		r.logger.WithField("key_id", id).WithError(err).Error("Failed to get API key")
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByPrefix retrieves an API key by its public prefix
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	ctx, span := startSpan(ctx, "api_key", "GetByPrefix")
+	defer span.End()
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+
+	key, err := r.scanAPIKey(r.db.QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: API key %s", ErrNotFound, prefix)
+		}
+		r.logger.WithField("prefix", prefix).WithError(err).Error("Failed to get API key")
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// List retrieves API keys matching the filter, newest first
+func (r *apiKeyRepository) List(ctx context.Context, filter APIKeyFilter) ([]*models.APIKey, error) {
+	ctx, span := startSpan(ctx, "api_key", "List")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+
+	// Add LIMIT and OFFSET
+	argIndex := len(args) + 1
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list API keys")
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := r.scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Count returns the total number of API keys matching the filter
+func (r *apiKeyRepository) Count(ctx context.Context, filter APIKeyFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "api_key", "Count")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := "SELECT COUNT(*) FROM api_keys"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count API keys")
+		return 0, fmt.Errorf("failed to count API keys: %w", err)
+	}
+
+	return count, nil
+}
+
+// Revoke marks an API key as revoked
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	ctx, span := startSpan(ctx, "api_key", "Revoke")
+	defer span.End()
+
+	query := `UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, at)
+	if err != nil {
+		r.logger.WithField("key_id", id).WithError(err).Error("Failed to revoke API key")
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: active API key %s", ErrNotFound, id)
+	}
+
+	r.logger.WithField("key_id", id).Info("API key revoked")
+	return nil
+}
+
+// TouchLastUsed records that a key was used at the given time
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	ctx, span := startSpan(ctx, "api_key", "TouchLastUsed")
+	defer span.End()
+
+	query := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)`
+
+	if _, err := r.db.ExecContext(ctx, query, id, at); err != nil {
+		return fmt.Errorf("failed to update API key last use: %w", err)
+	}
+
+	return nil
+}
+
+// Helper methods
+
+// buildConditions builds WHERE conditions for API key queries
+func (r *apiKeyRepository) buildConditions(filter APIKeyFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if filter.Subject != "" {
+		conditions = append(conditions, fmt.Sprintf("subject = $%d", argIndex))
+		args = append(args, filter.Subject)
+		argIndex++
+	}
+
+	if !filter.IncludeRevoked {
+		conditions = append(conditions, "revoked_at IS NULL")
+	}
+
+	if filter.WithinGroups != nil {
+		conditions = append(conditions, fmt.Sprintf("cardinality(device_groups) > 0 AND device_groups <@ $%d", argIndex))
+		args = append(args, pq.Array(filter.WithinGroups))
+		argIndex++
+	}
+
+	return conditions, args
+}
+
+// nonNilStrings returns an empty slice for nil, as array columns are NOT NULL
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// scanAPIKey scans a single row into an API key
+func (r *apiKeyRepository) scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var name, deviceID sql.NullString
+	var roles, deviceGroups pq.StringArray
+	var expiresAt, revokedAt, lastUsedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.Prefix,
+		&key.KeyHash,
+		&name,
+		&key.Subject,
+		&roles,
+		&deviceGroups,
+		&deviceID,
+		&expiresAt,
+		&revokedAt,
+		&lastUsedAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Name = name.String
+	key.Roles = []string(roles)
+	key.DeviceGroups = []string(deviceGroups)
+	if deviceID.Valid {
+		key.DeviceID = &deviceID.String
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return key, nil
+}
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// auditColumns lists the columns selected for audit trail queries
+const auditColumns = `id, sequence, occurred_at, actor, auth_method, action, resource_type, resource_id, reason, outcome, before, after, correlation_id, previous_hash, hash`
+
+// auditChainLockID is the advisory lock serializing appends to the audit
+// chain, so that concurrent entries cannot link to the same predecessor
+const auditChainLockID = 0x6175646974 // "audit"
+
+// auditRepository implements AuditRepository interface
+type auditRepository struct {
+	db     db.Executor
+	logger *logger.Logger
+}
+
+// NewAuditRepository creates a new audit trail repository
+func NewAuditRepository(db db.Executor, logger *logger.Logger) AuditRepository {
+	return &auditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Append chains an entry to the last one and stores it
+func (r *auditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	ctx, span := startSpan(ctx, "audit", "Append")
+	defer span.End()
+
+	entry.SetDefaults()
+
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("audit entry validation failed: %w", err)
+	}
+
+	beforeJSON, err := nullableJSON(entry.Before)
+	if err != nil {
+		return fmt.Errorf("failed to marshal before value: %w", err)
+	}
+	afterJSON, err := nullableJSON(entry.After)
+	if err != nil {
+		return fmt.Errorf("failed to marshal after value: %w", err)
+	}
+
+	tx, err := db.Begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockID); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var lastSequence int64
+	var lastHash string
+	err = tx.QueryRowContext(ctx, `SELECT sequence, hash FROM audit_log ORDER BY sequence DESC LIMIT 1`).Scan(&lastSequence, &lastHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get last audit entry: %w", err)
+	}
+
+	entry.Sequence = lastSequence + 1
+	entry.PreviousHash = lastHash
+	entry.Hash, err = entry.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_log (` + auditColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		entry.ID,
+		entry.Sequence,
+		entry.OccurredAt,
+		entry.Actor,
+		entry.AuthMethod,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.Reason,
+		entry.Outcome,
+		beforeJSON,
+		afterJSON,
+		entry.CorrelationID,
+		entry.PreviousHash,
+		entry.Hash,
+	)
+	if err != nil {
+		r.logger.WithFields(map[string]interface{}{
+			"actor":  entry.Actor,
+			"action": entry.Action,
+		}).WithError(err).Error("Failed to append audit entry")
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves audit entries with filtering and pagination, newest first
+func (r *auditRepository) List(ctx context.Context, filter AuditFilter) ([]*models.AuditEntry, error) {
+	ctx, span := startSpan(ctx, "audit", "List")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY sequence DESC"
+
+	// Add LIMIT and OFFSET
+	argIndex := len(args) + 1
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+	}
+
+	return r.query(ctx, db.ForRead(ctx, r.db), query, args...)
+}
+
+// Count returns the number of audit entries matching the filter
+func (r *auditRepository) Count(ctx context.Context, filter AuditFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "audit", "Count")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := "SELECT COUNT(*) FROM audit_log"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	if err := db.ForRead(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count audit entries")
+		return 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListAfter lists entries following a sequence number in chain order
+func (r *auditRepository) ListAfter(ctx context.Context, sequence int64, limit int) ([]*models.AuditEntry, error) {
+	ctx, span := startSpan(ctx, "audit", "ListAfter")
+	defer span.End()
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE sequence > $1 ORDER BY sequence ASC LIMIT $2`
+	return r.query(ctx, r.db, query, sequence, limit)
+}
+
+// Helper methods
+
+// query runs an audit entry query on exec. Unlike other listings, a row that
+// cannot be scanned fails the query, as skipping it would hide a gap in the
+// chain.
+func (r *auditRepository) query(ctx context.Context, exec db.Executor, query string, args ...interface{}) ([]*models.AuditEntry, error) {
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list audit entries")
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry, err := r.scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entry rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// buildConditions constructs the WHERE conditions shared by List and Count
+func (r *auditRepository) buildConditions(filter AuditFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if filter.Actor != "" {
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", argIndex))
+		args = append(args, filter.Actor)
+		argIndex++
+	}
+
+	if filter.Action != "" {
+		conditions = append(conditions, fmt.Sprintf("action = $%d", argIndex))
+		args = append(args, filter.Action)
+		argIndex++
+	}
+
+	if filter.ResourceType != "" {
+		conditions = append(conditions, fmt.Sprintf("resource_type = $%d", argIndex))
+		args = append(args, filter.ResourceType)
+		argIndex++
+	}
+
+	if filter.ResourceID != "" {
+		conditions = append(conditions, fmt.Sprintf("resource_id = $%d", argIndex))
+		args = append(args, filter.ResourceID)
+		argIndex++
+	}
+
+	if filter.StartTime != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= $%d", argIndex))
+		args = append(args, *filter.StartTime)
+		argIndex++
+	}
+
+	if filter.EndTime != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at <= $%d", argIndex))
+		args = append(args, *filter.EndTime)
+		argIndex++
+	}
+
+	return conditions, args
+}
+
+// scanEntry scans a single row into an audit entry
+func (r *auditRepository) scanEntry(row rowScanner) (*models.AuditEntry, error) {
+	entry := &models.AuditEntry{}
+	var beforeJSON, afterJSON []byte
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.Sequence,
+		&entry.OccurredAt,
+		&entry.Actor,
+		&entry.AuthMethod,
+		&entry.Action,
+		&entry.ResourceType,
+		&entry.ResourceID,
+		&entry.Reason,
+		&entry.Outcome,
+		&beforeJSON,
+		&afterJSON,
+		&entry.CorrelationID,
+		&entry.PreviousHash,
+		&entry.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalJSON(beforeJSON, &entry.Before); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal before value: %w", err)
+	}
+	if err := unmarshalJSON(afterJSON, &entry.After); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal after value: %w", err)
+	}
+
+	return entry, nil
+}
+
+// nullableJSON marshals a value to JSON, or to NULL if it is empty. Audit
+// snapshots are stored in plaintext rather than under the field cipher: the
+// log is append-only, so values it encrypted could not be re-encrypted when
+// keys rotate.
+func nullableJSON(value map[string]interface{}) ([]byte, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
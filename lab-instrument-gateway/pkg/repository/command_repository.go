@@ -15,26 +15,37 @@ import (
 
 // commandRepository implements CommandRepository interface
 type commandRepository struct {
-	db     *db.ConnectionManager
+	db     db.Executor
 	logger *logger.Logger
 }
 
 // NewCommandRepository creates a new command repository
-func NewCommandRepository(db *db.ConnectionManager, logger *logger.Logger) CommandRepository {
+func NewCommandRepository(db db.Executor, logger *logger.Logger) CommandRepository {
 	return &commandRepository{
 		db:     db,
 		logger: logger,
 	}
 }
 
-// Create creates a new command
+// Create creates a new command, together with the signatures it carries
 func (r *commandRepository) Create(ctx context.Context, command *models.Command) error {
+	ctx, span := startSpan(ctx, "command", "Create")
+	defer span.End()
+
 	if err := command.Validate(); err != nil {
 		return fmt.Errorf("command validation failed: %w", err)
 	}
 
 	command.SetDefaults()
 
+	for _, signature := range command.Signatures {
+		signature.CommandID = command.ID
+		signature.SetDefaults()
+		if err := signature.Validate(); err != nil {
+			return fmt.Errorf("command signature validation failed: %w", err)
+		}
+	}
+
 	query := `
 		INSERT INTO commands (id, command_id, device_id, type, parameters, status, priority, timeout_seconds, created_at, updated_at, expires_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
@@ -45,7 +56,13 @@ func (r *commandRepository) Create(ctx context.Context, command *models.Command)
 		return fmt.Errorf("failed to marshal parameters: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
+	tx, err := db.Begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query,
 		command.ID,
 		command.CommandID,
 		command.DeviceID,
@@ -64,9 +81,37 @@ func (r *commandRepository) Create(ctx context.Context, command *models.Command)
 		return fmt.Errorf("failed to create command: %w", err)
 	}
 
+	signatureQuery := `
+		INSERT INTO command_signatures (id, command_id, signer, auth_method, meaning, comment, content_hash, signed_at, token_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	for _, signature := range command.Signatures {
+		_, err = tx.ExecContext(ctx, signatureQuery,
+			signature.ID,
+			signature.CommandID,
+			signature.Signer,
+			signature.AuthMethod,
+			signature.Meaning,
+			signature.Comment,
+			signature.ContentHash,
+			signature.SignedAt,
+			sql.NullString{String: signature.TokenID, Valid: signature.TokenID != ""},
+		)
+		if err != nil {
+			r.logger.WithField("device_id", command.DeviceID).WithError(err).Error("Failed to store command signature")
+			return fmt.Errorf("failed to store command signature: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit command: %w", err)
+	}
+
 	r.logger.WithField("device_id", command.DeviceID).WithFields(map[string]interface{}{
 		"command_id": command.CommandID,
 		"type":       command.Type,
+		"signatures": len(command.Signatures),
 	}).Info("Command created successfully")
 
 	return nil
@@ -74,6 +119,9 @@ func (r *commandRepository) Create(ctx context.Context, command *models.Command)
 
 // GetByID retrieves a command by ID
 func (r *commandRepository) GetByID(ctx context.Context, id string) (*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetByID")
+	defer span.End()
+
 	query := `
 		SELECT id, command_id, device_id, type, parameters, status, priority, timeout_seconds, 
 		       result, error_message, executed_at, created_at, updated_at, expires_at
@@ -103,7 +151,7 @@ func (r *commandRepository) GetByID(ctx context.Context, id string) (*models.Com
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("command not found: %s", id)
+			return nil, fmt.Errorf("%w: command %s", ErrNotFound, id)
 		}
 		r.logger.WithError(err).Error("Failed to get command")
 		return nil, fmt.Errorf("failed to get command: %w", err)
@@ -127,6 +175,9 @@ func (r *commandRepository) GetByID(ctx context.Context, id string) (*models.Com
 
 // GetByCommandID retrieves a command by command ID
 func (r *commandRepository) GetByCommandID(ctx context.Context, commandID string) (*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetByCommandID")
+	defer span.End()
+
 	query := `
 		SELECT id, command_id, device_id, type, parameters, status, priority, timeout_seconds, 
 		       result, error_message, executed_at, created_at, updated_at, expires_at
@@ -156,7 +207,7 @@ func (r *commandRepository) GetByCommandID(ctx context.Context, commandID string
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("command not found: %s", commandID)
+			return nil, fmt.Errorf("%w: command %s", ErrNotFound, commandID)
 		}
 		r.logger.WithError(err).Error("Failed to get command by command ID")
 		return nil, fmt.Errorf("failed to get command by command ID: %w", err)
@@ -180,6 +231,9 @@ func (r *commandRepository) GetByCommandID(ctx context.Context, commandID string
 
 // Update updates an existing command
 func (r *commandRepository) Update(ctx context.Context, command *models.Command) error {
+	ctx, span := startSpan(ctx, "command", "Update")
+	defer span.End()
+
 	if err := command.Validate(); err != nil {
 		return fmt.Errorf("command validation failed: %w", err)
 	}
@@ -228,7 +282,7 @@ func (r *commandRepository) Update(ctx context.Context, command *models.Command)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("command not found: %s", command.ID)
+		return fmt.Errorf("%w: command %s", ErrNotFound, command.ID)
 	}
 
 	r.logger.WithField("device_id", command.DeviceID).WithFields(map[string]interface{}{
@@ -241,6 +295,9 @@ func (r *commandRepository) Update(ctx context.Context, command *models.Command)
 
 // Delete removes a command
 func (r *commandRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "command", "Delete")
+	defer span.End()
+
 	query := `DELETE FROM commands WHERE id = $1`
 
 	result, err := r.db.ExecContext(ctx, query, id)
@@ -255,7 +312,7 @@ func (r *commandRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("command not found: %s", id)
+		return fmt.Errorf("%w: command %s", ErrNotFound, id)
 	}
 
 	r.logger.WithFields(map[string]interface{}{
@@ -267,9 +324,12 @@ func (r *commandRepository) Delete(ctx context.Context, id string) error {
 
 // List retrieves commands with filtering and pagination
 func (r *commandRepository) List(ctx context.Context, filter CommandFilter) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "List")
+	defer span.End()
+
 	query, args := r.buildListQuery(filter)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to list commands")
 		return nil, fmt.Errorf("failed to list commands: %w", err)
@@ -281,10 +341,13 @@ func (r *commandRepository) List(ctx context.Context, filter CommandFilter) ([]*
 
 // Count returns the total number of commands matching the filter
 func (r *commandRepository) Count(ctx context.Context, filter CommandFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "command", "Count")
+	defer span.End()
+
 	query, args := r.buildCountQuery(filter)
 
 	var count int64
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := db.ForRead(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to count commands")
 		return 0, fmt.Errorf("failed to count commands: %w", err)
@@ -295,6 +358,9 @@ func (r *commandRepository) Count(ctx context.Context, filter CommandFilter) (in
 
 // GetPendingCommands retrieves pending commands for a device
 func (r *commandRepository) GetPendingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetPendingCommands")
+	defer span.End()
+
 	query := `
 		SELECT id, command_id, device_id, type, parameters, status, priority, timeout_seconds, 
 		       result, error_message, executed_at, created_at, updated_at, expires_at
@@ -315,6 +381,9 @@ func (r *commandRepository) GetPendingCommands(ctx context.Context, deviceID str
 
 // GetExecutingCommands retrieves executing commands for a device
 func (r *commandRepository) GetExecutingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetExecutingCommands")
+	defer span.End()
+
 	query := `
 		SELECT id, command_id, device_id, type, parameters, status, priority, timeout_seconds, 
 		       result, error_message, executed_at, created_at, updated_at, expires_at
@@ -335,6 +404,9 @@ func (r *commandRepository) GetExecutingCommands(ctx context.Context, deviceID s
 
 // UpdateStatus updates the status of a command
 func (r *commandRepository) UpdateStatus(ctx context.Context, commandID string, status models.CommandStatus) error {
+	ctx, span := startSpan(ctx, "command", "UpdateStatus")
+	defer span.End()
+
 	query := `
 		UPDATE commands 
 		SET status = $2, updated_at = $3
@@ -356,7 +428,7 @@ func (r *commandRepository) UpdateStatus(ctx context.Context, commandID string,
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("command not found: %s", commandID)
+		return fmt.Errorf("%w: command %s", ErrNotFound, commandID)
 	}
 
 	r.logger.WithFields(map[string]interface{}{
@@ -367,8 +439,44 @@ func (r *commandRepository) UpdateStatus(ctx context.Context, commandID string,
 	return nil
 }
 
+// Claim marks a pending command executing, returning false when it is no
+// longer pending
+func (r *commandRepository) Claim(ctx context.Context, command *models.Command) (bool, error) {
+	ctx, span := startSpan(ctx, "command", "Claim")
+	defer span.End()
+
+	claimed := *command
+	claimed.StartExecution()
+
+	query := `
+		UPDATE commands
+		SET status = $2, executed_at = $3, updated_at = $4
+		WHERE id = $1 AND status = 'pending'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, command.ID, claimed.Status, claimed.ExecutedAt, claimed.UpdatedAt)
+	if err != nil {
+		r.logger.WithField("command_id", command.CommandID).WithError(err).Error("Failed to claim command")
+		return false, fmt.Errorf("failed to claim command: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	*command = claimed
+	return true, nil
+}
+
 // GetExpiredCommands retrieves commands that have expired
 func (r *commandRepository) GetExpiredCommands(ctx context.Context) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetExpiredCommands")
+	defer span.End()
+
 	query := `
 		SELECT id, command_id, device_id, type, parameters, status, priority, timeout_seconds, 
 		       result, error_message, executed_at, created_at, updated_at, expires_at
@@ -389,6 +497,9 @@ func (r *commandRepository) GetExpiredCommands(ctx context.Context) ([]*models.C
 
 // MarkExpiredAsTimeout marks expired commands as timed out
 func (r *commandRepository) MarkExpiredAsTimeout(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "command", "MarkExpiredAsTimeout")
+	defer span.End()
+
 	query := `
 		UPDATE commands 
 		SET status = 'timeout', error_message = 'Command expired', updated_at = NOW()
@@ -417,6 +528,9 @@ func (r *commandRepository) MarkExpiredAsTimeout(ctx context.Context) (int64, er
 
 // DeleteCompletedOlderThan removes completed commands older than the specified threshold
 func (r *commandRepository) DeleteCompletedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "command", "DeleteCompletedOlderThan")
+	defer span.End()
+
 	query := `
 		DELETE FROM commands 
 		WHERE created_at < $1 AND status IN ('completed', 'failed', 'timeout')
@@ -445,6 +559,9 @@ func (r *commandRepository) DeleteCompletedOlderThan(ctx context.Context, thresh
 
 // GetCommandStats retrieves command statistics for a device
 func (r *commandRepository) GetCommandStats(ctx context.Context, deviceID string, timeRange TimeRangeFilter) (map[models.CommandStatus]int64, error) {
+	ctx, span := startSpan(ctx, "command", "GetCommandStats")
+	defer span.End()
+
 	query := `
 		SELECT status, COUNT(*) as count
 		FROM commands
@@ -492,6 +609,70 @@ func (r *commandRepository) GetCommandStats(ctx context.Context, deviceID string
 	return stats, nil
 }
 
+// ListSignatures retrieves the signatures of a command, in signing order
+func (r *commandRepository) ListSignatures(ctx context.Context, id string) ([]*models.CommandSignature, error) {
+	ctx, span := startSpan(ctx, "command", "ListSignatures")
+	defer span.End()
+
+	query := `
+		SELECT id, command_id, signer, auth_method, meaning, comment, content_hash, signed_at
+		FROM command_signatures
+		WHERE command_id = $1
+		ORDER BY signed_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		r.logger.WithField("id", id).WithError(err).Error("Failed to list command signatures")
+		return nil, fmt.Errorf("failed to list command signatures: %w", err)
+	}
+	defer rows.Close()
+
+	var signatures []*models.CommandSignature
+	for rows.Next() {
+		signature := &models.CommandSignature{}
+		err := rows.Scan(
+			&signature.ID,
+			&signature.CommandID,
+			&signature.Signer,
+			&signature.AuthMethod,
+			&signature.Meaning,
+			&signature.Comment,
+			&signature.ContentHash,
+			&signature.SignedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command signature: %w", err)
+		}
+		signatures = append(signatures, signature)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating command signature rows: %w", err)
+	}
+
+	return signatures, nil
+}
+
+// SignatureTokenUsed reports whether a signer has already signed with the
+// credential of the given token ID
+func (r *commandRepository) SignatureTokenUsed(ctx context.Context, signer, tokenID string) (bool, error) {
+	ctx, span := startSpan(ctx, "command", "SignatureTokenUsed")
+	defer span.End()
+
+	query := `
+		SELECT EXISTS (SELECT 1 FROM command_signatures WHERE signer = $1 AND token_id = $2)
+	`
+
+	var used bool
+	if err := r.db.QueryRowContext(ctx, query, signer, tokenID).Scan(&used); err != nil {
+		r.logger.WithField("signer", signer).WithError(err).Error("Failed to check signature token")
+		return false, fmt.Errorf("failed to check signature token: %w", err)
+	}
+
+	return used, nil
+}
+
 // Helper methods
 
 // scanCommands scans rows into command objects
@@ -0,0 +1,43 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/yourorg/lab-gateway/pkg/config"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/repositorytest"
+)
+
+// TestConformance runs the conformance checks against the database the
+// DB_* environment variables point to when TEST_POSTGRES is set
+func TestConformance(t *testing.T) {
+	if os.Getenv("TEST_POSTGRES") == "" {
+		t.Skip("Requires running PostgreSQL instance, set TEST_POSTGRES to run")
+	}
+
+	cfg := config.Load()
+	log := logger.NewDefaultLogger()
+
+	cm, err := db.NewConnectionManager(&cfg.Database, log)
+	if err != nil {
+		t.Fatalf("Failed to create connection manager: %v", err)
+	}
+	defer cm.Close()
+
+	ctx := context.Background()
+	migrator := db.NewMigrationRunner(cm.GetDB(), "../../migrations", log)
+	if err := migrator.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize migrations: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Migration up failed: %v", err)
+	}
+
+	repositorytest.Run(t, func(t *testing.T) repository.RepositoryManager {
+		return repository.NewRepositoryManager(cm, log)
+	})
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// deviceEventColumns lists the columns selected for device event queries
+const deviceEventColumns = `id, device_id, type, session_id, from_status, to_status, message, metadata, occurred_at`
+
+// deviceEventRepository implements DeviceEventRepository interface
+type deviceEventRepository struct {
+	db     db.Executor
+	logger *logger.Logger
+}
+
+// NewDeviceEventRepository creates a new device event repository
+func NewDeviceEventRepository(db db.Executor, logger *logger.Logger) DeviceEventRepository {
+	return &deviceEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create appends an event to the device event log
+func (r *deviceEventRepository) Create(ctx context.Context, event *models.DeviceEvent) error {
+	ctx, span := startSpan(ctx, "device_event", "Create")
+	defer span.End()
+
+	event.SetDefaults()
+
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("device event validation failed: %w", err)
+	}
+
+	metadataJSON, err := marshalJSON(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	query := `
+		INSERT INTO device_events (id, device_id, type, session_id, from_status, to_status, message, metadata, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		event.ID,
+		event.DeviceID,
+		event.Type,
+		event.SessionID,
+		event.FromStatus,
+		event.ToStatus,
+		event.Message,
+		metadataJSON,
+		event.OccurredAt,
+	)
+
+	if err != nil {
+		r.logger.WithFields(map[string]interface{}{
+			"device_id": event.DeviceID,
+			"type":      event.Type,
+		}).WithError(err).Error("Failed to create device event")
+		return fmt.Errorf("failed to create device event: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"event_id":  event.ID,
+		"device_id": event.DeviceID,
+		"type":      event.Type,
+	}).Debug("Device event recorded")
+
+	return nil
+}
+
+// List retrieves device events with filtering and pagination
+func (r *deviceEventRepository) List(ctx context.Context, filter DeviceEventFilter) ([]*models.DeviceEvent, error) {
+	ctx, span := startSpan(ctx, "device_event", "List")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := `SELECT ` + deviceEventColumns + ` FROM device_events`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Add ORDER BY
+	orderBy := "occurred_at"
+	if filter.SortBy != "" {
+		orderBy = filter.SortBy
+	}
+	order := "DESC"
+	if filter.Order != "" {
+		order = strings.ToUpper(filter.Order)
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s", orderBy, order)
+
+	// Add LIMIT and OFFSET
+	argIndex := len(args) + 1
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list device events")
+		return nil, fmt.Errorf("failed to list device events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.DeviceEvent
+	for rows.Next() {
+		event, err := r.scanEvent(rows)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan device event")
+			continue
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// Count returns the number of device events matching the filter
+func (r *deviceEventRepository) Count(ctx context.Context, filter DeviceEventFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "device_event", "Count")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := "SELECT COUNT(*) FROM device_events"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	if err := db.ForRead(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count device events")
+		return 0, fmt.Errorf("failed to count device events: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetLastBefore retrieves the most recent event of the given type that
+// occurred before the given time
+func (r *deviceEventRepository) GetLastBefore(ctx context.Context, deviceID string, eventType models.DeviceEventType, before time.Time) (*models.DeviceEvent, error) {
+	ctx, span := startSpan(ctx, "device_event", "GetLastBefore")
+	defer span.End()
+
+	query := `
+		SELECT ` + deviceEventColumns + `
+		FROM device_events
+		WHERE device_id = $1 AND type = $2 AND occurred_at < $3
+		ORDER BY occurred_at DESC
+		LIMIT 1
+	`
+
+	event, err := r.scanEvent(r.db.QueryRowContext(ctx, query, deviceID, eventType, before))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s event for device %s", ErrNotFound, eventType, deviceID)
+		}
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get last device event")
+		return nil, fmt.Errorf("failed to get last device event: %w", err)
+	}
+
+	return event, nil
+}
+
+// DeleteOlderThan removes events that occurred before the threshold
+func (r *deviceEventRepository) DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "device_event", "DeleteOlderThan")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM device_events WHERE occurred_at < $1`, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete old device events")
+		return 0, fmt.Errorf("failed to delete old device events: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithFields(map[string]interface{}{
+			"deleted":   rowsAffected,
+			"threshold": threshold,
+		}).Info("Old device events deleted")
+	}
+
+	return rowsAffected, nil
+}
+
+// Helper methods
+
+// buildConditions constructs the WHERE conditions shared by List and Count
+func (r *deviceEventRepository) buildConditions(filter DeviceEventFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if filter.DeviceID != "" {
+		conditions = append(conditions, fmt.Sprintf("device_id = $%d", argIndex))
+		args = append(args, filter.DeviceID)
+		argIndex++
+	}
+
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, eventType := range filter.Types {
+			types[i] = string(eventType)
+		}
+		conditions = append(conditions, fmt.Sprintf("type = ANY($%d)", argIndex))
+		args = append(args, pq.Array(types))
+		argIndex++
+	}
+
+	if filter.StartTime != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= $%d", argIndex))
+		args = append(args, *filter.StartTime)
+		argIndex++
+	}
+
+	if filter.EndTime != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at <= $%d", argIndex))
+		args = append(args, *filter.EndTime)
+		argIndex++
+	}
+
+	return conditions, args
+}
+
+// scanEvent scans a single row into a device event
+func (r *deviceEventRepository) scanEvent(row rowScanner) (*models.DeviceEvent, error) {
+	event := &models.DeviceEvent{}
+	var message sql.NullString
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&event.ID,
+		&event.DeviceID,
+		&event.Type,
+		&event.SessionID,
+		&event.FromStatus,
+		&event.ToStatus,
+		&message,
+		&metadataJSON,
+		&event.OccurredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Message = message.String
+	if err := unmarshalJSON(metadataJSON, &event.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return event, nil
+}
@@ -15,12 +15,12 @@ import (
 
 // deviceRepository implements DeviceRepository interface
 type deviceRepository struct {
-	db     *db.ConnectionManager
+	db     db.Executor
 	logger *logger.Logger
 }
 
 // NewDeviceRepository creates a new device repository
-func NewDeviceRepository(db *db.ConnectionManager, logger *logger.Logger) DeviceRepository {
+func NewDeviceRepository(db db.Executor, logger *logger.Logger) DeviceRepository {
 	return &deviceRepository{
 		db:     db,
 		logger: logger,
@@ -29,6 +29,9 @@ func NewDeviceRepository(db *db.ConnectionManager, logger *logger.Logger) Device
 
 // Create creates a new device
 func (r *deviceRepository) Create(ctx context.Context, device *models.Device) error {
+	ctx, span := startSpan(ctx, "device", "Create")
+	defer span.End()
+
 	if err := device.Validate(); err != nil {
 		return fmt.Errorf("device validation failed: %w", err)
 	}
@@ -77,6 +80,9 @@ func (r *deviceRepository) Create(ctx context.Context, device *models.Device) er
 
 // GetByID retrieves a device by ID
 func (r *deviceRepository) GetByID(ctx context.Context, id string) (*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetByID")
+	defer span.End()
+
 	query := `
 		SELECT id, name, type, version, status, metadata, capabilities, last_seen, registered_at, created_at, updated_at
 		FROM devices
@@ -103,7 +109,7 @@ func (r *deviceRepository) GetByID(ctx context.Context, id string) (*models.Devi
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("device not found: %s", id)
+			return nil, fmt.Errorf("%w: device %s", ErrNotFound, id)
 		}
 		r.logger.WithField("device_id", id).WithError(err).Error("Failed to get device")
 		return nil, fmt.Errorf("failed to get device: %w", err)
@@ -120,6 +126,9 @@ func (r *deviceRepository) GetByID(ctx context.Context, id string) (*models.Devi
 
 // Update updates an existing device
 func (r *deviceRepository) Update(ctx context.Context, device *models.Device) error {
+	ctx, span := startSpan(ctx, "device", "Update")
+	defer span.End()
+
 	if err := device.Validate(); err != nil {
 		return fmt.Errorf("device validation failed: %w", err)
 	}
@@ -160,7 +169,7 @@ func (r *deviceRepository) Update(ctx context.Context, device *models.Device) er
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("device not found: %s", device.ID)
+		return fmt.Errorf("%w: device %s", ErrNotFound, device.ID)
 	}
 
 	r.logger.WithField("device_id", device.ID).Info("Device updated successfully")
@@ -169,6 +178,9 @@ func (r *deviceRepository) Update(ctx context.Context, device *models.Device) er
 
 // Delete removes a device
 func (r *deviceRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "device", "Delete")
+	defer span.End()
+
 	query := `DELETE FROM devices WHERE id = $1`
 
 	result, err := r.db.ExecContext(ctx, query, id)
@@ -183,7 +195,7 @@ func (r *deviceRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("device not found: %s", id)
+		return fmt.Errorf("%w: device %s", ErrNotFound, id)
 	}
 
 	r.logger.WithField("device_id", id).Info("Device deleted successfully")
@@ -192,13 +204,16 @@ func (r *deviceRepository) Delete(ctx context.Context, id string) error {
 
 // CreateBulk creates multiple devices in a single transaction
 func (r *deviceRepository) CreateBulk(ctx context.Context, devices []*models.Device) (*BulkResult, error) {
+	ctx, span := startSpan(ctx, "device", "CreateBulk")
+	defer span.End()
+
 	if len(devices) == 0 {
 		return &BulkResult{}, nil
 	}
 
 	result := &BulkResult{}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := db.Begin(ctx, r.db)
 	if err != nil {
 		return result, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -275,13 +290,16 @@ func (r *deviceRepository) CreateBulk(ctx context.Context, devices []*models.Dev
 
 // UpdateBulk updates multiple devices in a single transaction
 func (r *deviceRepository) UpdateBulk(ctx context.Context, devices []*models.Device) (*BulkResult, error) {
+	ctx, span := startSpan(ctx, "device", "UpdateBulk")
+	defer span.End()
+
 	if len(devices) == 0 {
 		return &BulkResult{}, nil
 	}
 
 	result := &BulkResult{}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := db.Begin(ctx, r.db)
 	if err != nil {
 		return result, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -363,9 +381,12 @@ func (r *deviceRepository) UpdateBulk(ctx context.Context, devices []*models.Dev
 
 // List retrieves devices with filtering and pagination
 func (r *deviceRepository) List(ctx context.Context, filter DeviceFilter) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "List")
+	defer span.End()
+
 	query, args := r.buildListQuery(filter)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to list devices")
 		return nil, fmt.Errorf("failed to list devices: %w", err)
@@ -416,10 +437,13 @@ func (r *deviceRepository) List(ctx context.Context, filter DeviceFilter) ([]*mo
 
 // Count returns the total number of devices matching the filter
 func (r *deviceRepository) Count(ctx context.Context, filter DeviceFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "device", "Count")
+	defer span.End()
+
 	query, args := r.buildCountQuery(filter)
 
 	var count int64
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := db.ForRead(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to count devices")
 		return 0, fmt.Errorf("failed to count devices: %w", err)
@@ -430,6 +454,9 @@ func (r *deviceRepository) Count(ctx context.Context, filter DeviceFilter) (int6
 
 // UpdateStatus updates device status
 func (r *deviceRepository) UpdateStatus(ctx context.Context, deviceID string, status models.DeviceStatus) error {
+	ctx, span := startSpan(ctx, "device", "UpdateStatus")
+	defer span.End()
+
 	query := `
 		UPDATE devices 
 		SET status = $2, updated_at = $3
@@ -448,7 +475,7 @@ func (r *deviceRepository) UpdateStatus(ctx context.Context, deviceID string, st
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("device not found: %s", deviceID)
+		return fmt.Errorf("%w: device %s", ErrNotFound, deviceID)
 	}
 
 	r.logger.WithField("device_id", deviceID).WithFields(map[string]interface{}{
@@ -458,8 +485,44 @@ func (r *deviceRepository) UpdateStatus(ctx context.Context, deviceID string, st
 	return nil
 }
 
+// GetStatusCounts retrieves the number of devices in each status
+func (r *deviceRepository) GetStatusCounts(ctx context.Context) (map[models.DeviceStatus]int64, error) {
+	ctx, span := startSpan(ctx, "device", "GetStatusCounts")
+	defer span.End()
+
+	query := `SELECT status, COUNT(*) FROM devices GROUP BY status`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to count devices by status")
+		return nil, fmt.Errorf("failed to count devices by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[models.DeviceStatus]int64)
+	for rows.Next() {
+		var status models.DeviceStatus
+		var count int64
+
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan device status count: %w", err)
+		}
+
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device status counts: %w", err)
+	}
+
+	return counts, nil
+}
+
 // UpdateLastSeen updates the last seen timestamp
 func (r *deviceRepository) UpdateLastSeen(ctx context.Context, deviceID string, timestamp time.Time) error {
+	ctx, span := startSpan(ctx, "device", "UpdateLastSeen")
+	defer span.End()
+
 	query := `
 		UPDATE devices 
 		SET last_seen = $2, updated_at = $3
@@ -478,7 +541,7 @@ func (r *deviceRepository) UpdateLastSeen(ctx context.Context, deviceID string,
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("device not found: %s", deviceID)
+		return fmt.Errorf("%w: device %s", ErrNotFound, deviceID)
 	}
 
 	return nil
@@ -486,6 +549,9 @@ func (r *deviceRepository) UpdateLastSeen(ctx context.Context, deviceID string,
 
 // SearchByMetadata searches devices by metadata fields
 func (r *deviceRepository) SearchByMetadata(ctx context.Context, metadata map[string]interface{}) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "SearchByMetadata")
+	defer span.End()
+
 	if len(metadata) == 0 {
 		return []*models.Device{}, nil
 	}
@@ -554,6 +620,9 @@ func (r *deviceRepository) SearchByMetadata(ctx context.Context, metadata map[st
 
 // GetByCapability retrieves devices with a specific capability
 func (r *deviceRepository) GetByCapability(ctx context.Context, capability string) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetByCapability")
+	defer span.End()
+
 	query := `
 		SELECT id, name, type, version, status, metadata, capabilities, last_seen, registered_at, created_at, updated_at
 		FROM devices
@@ -608,6 +677,9 @@ func (r *deviceRepository) GetByCapability(ctx context.Context, capability strin
 
 // GetOnlineDevices retrieves all online devices
 func (r *deviceRepository) GetOnlineDevices(ctx context.Context) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetOnlineDevices")
+	defer span.End()
+
 	query := `
 		SELECT id, name, type, version, status, metadata, capabilities, last_seen, registered_at, created_at, updated_at
 		FROM devices
@@ -662,6 +734,9 @@ func (r *deviceRepository) GetOnlineDevices(ctx context.Context) ([]*models.Devi
 
 // GetOfflineDevices retrieves devices that have been offline for longer than threshold
 func (r *deviceRepository) GetOfflineDevices(ctx context.Context, threshold time.Duration) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetOfflineDevices")
+	defer span.End()
+
 	cutoffTime := time.Now().Add(-threshold)
 
 	query := `
@@ -779,6 +854,12 @@ func (r *deviceRepository) buildListQuery(filter DeviceFilter) (string, []interf
 		argIndex++
 	}
 
+	if len(filter.Groups) > 0 {
+		conditions = append(conditions, fmt.Sprintf("metadata->>'%s' = ANY($%d)", models.DeviceGroupMetadataKey, argIndex))
+		args = append(args, pq.Array(filter.Groups))
+		argIndex++
+	}
+
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
@@ -868,6 +949,12 @@ func (r *deviceRepository) buildCountQuery(filter DeviceFilter) (string, []inter
 		argIndex++
 	}
 
+	if len(filter.Groups) > 0 {
+		conditions = append(conditions, fmt.Sprintf("metadata->>'%s' = ANY($%d)", models.DeviceGroupMetadataKey, argIndex))
+		args = append(args, pq.Array(filter.Groups))
+		argIndex++
+	}
+
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
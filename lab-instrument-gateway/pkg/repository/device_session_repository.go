@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// deviceSessionColumns lists the columns selected for device session queries
+const deviceSessionColumns = `id, device_id, session_id, stream_id, connected_at, last_heartbeat, metadata, is_active, disconnected_at, disconnect_reason`
+
+// deviceSessionRepository implements DeviceSessionRepository interface
+type deviceSessionRepository struct {
+	db     db.Executor
+	logger *logger.Logger
+}
+
+// NewDeviceSessionRepository creates a new device session repository
+func NewDeviceSessionRepository(db db.Executor, logger *logger.Logger) DeviceSessionRepository {
+	return &deviceSessionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create persists a new device session
+func (r *deviceSessionRepository) Create(ctx context.Context, session *models.DeviceSession) error {
+	ctx, span := startSpan(ctx, "device_session", "Create")
+	defer span.End()
+
+	if session.DeviceID == "" || session.SessionID == "" {
+		return fmt.Errorf("device session requires device ID and session ID")
+	}
+
+	metadataJSON, err := marshalJSON(session.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	query := `
+		INSERT INTO device_sessions (device_id, session_id, stream_id, connected_at, last_heartbeat, metadata, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		session.DeviceID,
+		session.SessionID,
+		session.StreamID,
+		session.ConnectedAt,
+		session.LastHeartbeat,
+		metadataJSON,
+		session.IsActive,
+	).Scan(&session.ID)
+
+	if err != nil {
+		r.logger.WithField("session_id", session.SessionID).WithError(err).Error("Failed to create device session")
+		return fmt.Errorf("failed to create device session: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"device_id":  session.DeviceID,
+		"session_id": session.SessionID,
+	}).Debug("Device session persisted")
+
+	return nil
+}
+
+// GetBySessionID retrieves a device session by its session ID
+func (r *deviceSessionRepository) GetBySessionID(ctx context.Context, sessionID string) (*models.DeviceSession, error) {
+	ctx, span := startSpan(ctx, "device_session", "GetBySessionID")
+	defer span.End()
+
+	query := `SELECT ` + deviceSessionColumns + ` FROM device_sessions WHERE session_id = $1`
+
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, sessionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: device session %s", ErrNotFound, sessionID)
+		}
+		r.logger.WithField("session_id", sessionID).WithError(err).Error("Failed to get device session")
+		return nil, fmt.Errorf("failed to get device session: %w", err)
+	}
+
+	return session, nil
+}
+
+// ListByDevice retrieves the sessions of a device that overlap the time
+// range, newest first
+func (r *deviceSessionRepository) ListByDevice(ctx context.Context, deviceID string, timeRange TimeRangeFilter, limit int) ([]*models.DeviceSession, error) {
+	ctx, span := startSpan(ctx, "device_session", "ListByDevice")
+	defer span.End()
+
+	conditions := []string{"device_id = $1"}
+	args := []interface{}{deviceID}
+	argIndex := 2
+
+	if timeRange.StartTime != nil {
+		conditions = append(conditions, fmt.Sprintf("(disconnected_at IS NULL OR disconnected_at >= $%d)", argIndex))
+		args = append(args, *timeRange.StartTime)
+		argIndex++
+	}
+
+	if timeRange.EndTime != nil {
+		conditions = append(conditions, fmt.Sprintf("connected_at <= $%d", argIndex))
+		args = append(args, *timeRange.EndTime)
+		argIndex++
+	}
+
+	query := `SELECT ` + deviceSessionColumns + ` FROM device_sessions WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY connected_at DESC`
+
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to list device sessions")
+		return nil, fmt.Errorf("failed to list device sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.DeviceSession
+	for rows.Next() {
+		session, err := r.scanSession(rows)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan device session")
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device session rows: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// End marks a session as ended at the given time
+func (r *deviceSessionRepository) End(ctx context.Context, sessionID string, endedAt time.Time, reason string) error {
+	ctx, span := startSpan(ctx, "device_session", "End")
+	defer span.End()
+
+	query := `
+		UPDATE device_sessions
+		SET is_active = false, disconnected_at = $2, disconnect_reason = $3
+		WHERE session_id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, sessionID, endedAt, reason)
+	if err != nil {
+		r.logger.WithField("session_id", sessionID).WithError(err).Error("Failed to end device session")
+		return fmt.Errorf("failed to end device session: %w", err)
+	}
+
+	return r.checkAffected(result, sessionID)
+}
+
+// Resume reactivates a session whose device came back before reconnecting
+func (r *deviceSessionRepository) Resume(ctx context.Context, sessionID string, at time.Time) error {
+	ctx, span := startSpan(ctx, "device_session", "Resume")
+	defer span.End()
+
+	query := `
+		UPDATE device_sessions
+		SET is_active = true, disconnected_at = NULL, disconnect_reason = NULL, last_heartbeat = $2
+		WHERE session_id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, sessionID, at)
+	if err != nil {
+		r.logger.WithField("session_id", sessionID).WithError(err).Error("Failed to resume device session")
+		return fmt.Errorf("failed to resume device session: %w", err)
+	}
+
+	return r.checkAffected(result, sessionID)
+}
+
+// DeleteEndedOlderThan removes sessions that ended before the threshold
+func (r *deviceSessionRepository) DeleteEndedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "device_session", "DeleteEndedOlderThan")
+	defer span.End()
+
+	query := `DELETE FROM device_sessions WHERE is_active = false AND disconnected_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete ended device sessions")
+		return 0, fmt.Errorf("failed to delete ended device sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// Helper methods
+
+// checkAffected returns ErrNotFound if a statement did not touch any rows
+func (r *deviceSessionRepository) checkAffected(result sql.Result, sessionID string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: device session %s", ErrNotFound, sessionID)
+	}
+
+	return nil
+}
+
+// scanSession scans a single row into a device session
+func (r *deviceSessionRepository) scanSession(row rowScanner) (*models.DeviceSession, error) {
+	session := &models.DeviceSession{}
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&session.ID,
+		&session.DeviceID,
+		&session.SessionID,
+		&session.StreamID,
+		&session.ConnectedAt,
+		&session.LastHeartbeat,
+		&metadataJSON,
+		&session.IsActive,
+		&session.DisconnectedAt,
+		&session.DisconnectReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalJSON(metadataJSON, &session.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,557 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// escalationStepRecord is the stored JSON form of an escalation step
+type escalationStepRecord struct {
+	DelaySeconds int64    `json:"delay_seconds"`
+	Recipients   []string `json:"recipients,omitempty"`
+	ScheduleID   *string  `json:"schedule_id,omitempty"`
+}
+
+// escalationRepository implements EscalationRepository interface
+type escalationRepository struct {
+	db     db.Executor
+	logger *logger.Logger
+}
+
+// NewEscalationRepository creates a new escalation repository
+func NewEscalationRepository(db db.Executor, logger *logger.Logger) EscalationRepository {
+	return &escalationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreatePolicy creates a new escalation policy
+func (r *escalationRepository) CreatePolicy(ctx context.Context, policy *models.EscalationPolicy) error {
+	ctx, span := startSpan(ctx, "escalation", "CreatePolicy")
+	defer span.End()
+
+	policy.SetDefaults()
+
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("escalation policy validation failed: %w", err)
+	}
+
+	stepsJSON, err := marshalSteps(policy.Steps)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO escalation_policies (id, name, description, steps, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		policy.ID,
+		policy.Name,
+		policy.Description,
+		stepsJSON,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("policy_id", policy.ID).WithError(err).Error("Failed to create escalation policy")
+		return fmt.Errorf("failed to create escalation policy: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"policy_id": policy.ID,
+		"name":      policy.Name,
+		"steps":     len(policy.Steps),
+	}).Info("Escalation policy created successfully")
+
+	return nil
+}
+
+// GetPolicy retrieves an escalation policy by ID
+func (r *escalationRepository) GetPolicy(ctx context.Context, id string) (*models.EscalationPolicy, error) {
+	ctx, span := startSpan(ctx, "escalation", "GetPolicy")
+	defer span.End()
+
+	query := `
+		SELECT id, name, description, steps, created_at, updated_at
+		FROM escalation_policies
+		WHERE id = $1
+	`
+
+	policy, err := r.scanPolicy(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: escalation policy %s", ErrNotFound, id)
+		}
+		r.logger.WithField("policy_id", id).WithError(err).Error("Failed to get escalation policy")
+		return nil, fmt.Errorf("failed to get escalation policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// UpdatePolicy updates an existing escalation policy
+func (r *escalationRepository) UpdatePolicy(ctx context.Context, policy *models.EscalationPolicy) error {
+	ctx, span := startSpan(ctx, "escalation", "UpdatePolicy")
+	defer span.End()
+
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("escalation policy validation failed: %w", err)
+	}
+
+	stepsJSON, err := marshalSteps(policy.Steps)
+	if err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE escalation_policies
+		SET name = $2, description = $3, steps = $4, updated_at = $5
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		policy.ID,
+		policy.Name,
+		policy.Description,
+		stepsJSON,
+		policy.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("policy_id", policy.ID).WithError(err).Error("Failed to update escalation policy")
+		return fmt.Errorf("failed to update escalation policy: %w", err)
+	}
+
+	return r.checkAffected(result, "escalation policy", policy.ID)
+}
+
+// DeletePolicy removes an escalation policy and the routes that use it
+func (r *escalationRepository) DeletePolicy(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "escalation", "DeletePolicy")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM escalation_policies WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("policy_id", id).WithError(err).Error("Failed to delete escalation policy")
+		return fmt.Errorf("failed to delete escalation policy: %w", err)
+	}
+
+	return r.checkAffected(result, "escalation policy", id)
+}
+
+// ListPolicies retrieves all escalation policies
+func (r *escalationRepository) ListPolicies(ctx context.Context) ([]*models.EscalationPolicy, error) {
+	ctx, span := startSpan(ctx, "escalation", "ListPolicies")
+	defer span.End()
+
+	query := `
+		SELECT id, name, description, steps, created_at, updated_at
+		FROM escalation_policies
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list escalation policies")
+		return nil, fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*models.EscalationPolicy
+	for rows.Next() {
+		policy, err := r.scanPolicy(rows)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan escalation policy")
+			continue
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating escalation policy rows: %w", err)
+	}
+
+	return policies, nil
+}
+
+// CreateRoute creates a new alert route
+func (r *escalationRepository) CreateRoute(ctx context.Context, route *models.AlertRoute) error {
+	ctx, span := startSpan(ctx, "escalation", "CreateRoute")
+	defer span.End()
+
+	route.SetDefaults()
+
+	if err := route.Validate(); err != nil {
+		return fmt.Errorf("alert route validation failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO alert_routes (id, name, priority, device_type, alert_type, min_severity, policy_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		route.ID,
+		route.Name,
+		route.Priority,
+		route.DeviceType,
+		route.AlertType,
+		route.MinSeverity,
+		route.PolicyID,
+		route.CreatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("route_id", route.ID).WithError(err).Error("Failed to create alert route")
+		return fmt.Errorf("failed to create alert route: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"route_id":  route.ID,
+		"name":      route.Name,
+		"policy_id": route.PolicyID,
+	}).Info("Alert route created successfully")
+
+	return nil
+}
+
+// DeleteRoute removes an alert route
+func (r *escalationRepository) DeleteRoute(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "escalation", "DeleteRoute")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_routes WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("route_id", id).WithError(err).Error("Failed to delete alert route")
+		return fmt.Errorf("failed to delete alert route: %w", err)
+	}
+
+	return r.checkAffected(result, "alert route", id)
+}
+
+// ListRoutes retrieves all alert routes in ascending priority order
+func (r *escalationRepository) ListRoutes(ctx context.Context) ([]*models.AlertRoute, error) {
+	ctx, span := startSpan(ctx, "escalation", "ListRoutes")
+	defer span.End()
+
+	query := `
+		SELECT id, name, priority, device_type, alert_type, min_severity, policy_id, created_at
+		FROM alert_routes
+		ORDER BY priority, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list alert routes")
+		return nil, fmt.Errorf("failed to list alert routes: %w", err)
+	}
+	defer rows.Close()
+
+	var routes []*models.AlertRoute
+	for rows.Next() {
+		route := &models.AlertRoute{}
+		err := rows.Scan(
+			&route.ID,
+			&route.Name,
+			&route.Priority,
+			&route.DeviceType,
+			&route.AlertType,
+			&route.MinSeverity,
+			&route.PolicyID,
+			&route.CreatedAt,
+		)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan alert route")
+			continue
+		}
+		routes = append(routes, route)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert route rows: %w", err)
+	}
+
+	return routes, nil
+}
+
+// CreateSchedule creates a new on-call schedule
+func (r *escalationRepository) CreateSchedule(ctx context.Context, schedule *models.OnCallSchedule) error {
+	ctx, span := startSpan(ctx, "escalation", "CreateSchedule")
+	defer span.End()
+
+	schedule.SetDefaults()
+
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("on-call schedule validation failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO on_call_schedules (id, name, rotation_start, participants, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		schedule.ID,
+		schedule.Name,
+		schedule.RotationStart,
+		pq.Array(schedule.Participants),
+		schedule.CreatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("schedule_id", schedule.ID).WithError(err).Error("Failed to create on-call schedule")
+		return fmt.Errorf("failed to create on-call schedule: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"schedule_id":  schedule.ID,
+		"name":         schedule.Name,
+		"participants": len(schedule.Participants),
+	}).Info("On-call schedule created successfully")
+
+	return nil
+}
+
+// GetSchedule retrieves an on-call schedule by ID
+func (r *escalationRepository) GetSchedule(ctx context.Context, id string) (*models.OnCallSchedule, error) {
+	ctx, span := startSpan(ctx, "escalation", "GetSchedule")
+	defer span.End()
+
+	query := `
+		SELECT id, name, rotation_start, participants, created_at
+		FROM on_call_schedules
+		WHERE id = $1
+	`
+
+	schedule, err := r.scanSchedule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: on-call schedule %s", ErrNotFound, id)
+		}
+		r.logger.WithField("schedule_id", id).WithError(err).Error("Failed to get on-call schedule")
+		return nil, fmt.Errorf("failed to get on-call schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule removes an on-call schedule
+func (r *escalationRepository) DeleteSchedule(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "escalation", "DeleteSchedule")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM on_call_schedules WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("schedule_id", id).WithError(err).Error("Failed to delete on-call schedule")
+		return fmt.Errorf("failed to delete on-call schedule: %w", err)
+	}
+
+	return r.checkAffected(result, "on-call schedule", id)
+}
+
+// ListSchedules retrieves all on-call schedules
+func (r *escalationRepository) ListSchedules(ctx context.Context) ([]*models.OnCallSchedule, error) {
+	ctx, span := startSpan(ctx, "escalation", "ListSchedules")
+	defer span.End()
+
+	query := `
+		SELECT id, name, rotation_start, participants, created_at
+		FROM on_call_schedules
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list on-call schedules")
+		return nil, fmt.Errorf("failed to list on-call schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*models.OnCallSchedule
+	for rows.Next() {
+		schedule, err := r.scanSchedule(rows)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan on-call schedule")
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating on-call schedule rows: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// GetEscalations retrieves the escalation progress of the given alerts,
+// keyed by alert ID. Alerts that have never escalated are omitted.
+func (r *escalationRepository) GetEscalations(ctx context.Context, alertIDs []string) (map[string]*models.AlertEscalation, error) {
+	ctx, span := startSpan(ctx, "escalation", "GetEscalations")
+	defer span.End()
+
+	escalations := make(map[string]*models.AlertEscalation)
+	if len(alertIDs) == 0 {
+		return escalations, nil
+	}
+
+	query := `
+		SELECT alert_id, policy_id, level, last_escalated_at
+		FROM alert_escalations
+		WHERE alert_id = ANY($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(alertIDs))
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get alert escalations")
+		return nil, fmt.Errorf("failed to get alert escalations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		escalation := &models.AlertEscalation{}
+		var policyID sql.NullString
+		if err := rows.Scan(&escalation.AlertID, &policyID, &escalation.Level, &escalation.LastEscalatedAt); err != nil {
+			r.logger.WithError(err).Error("Failed to scan alert escalation")
+			continue
+		}
+		escalation.PolicyID = policyID.String
+		escalations[escalation.AlertID] = escalation
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert escalation rows: %w", err)
+	}
+
+	return escalations, nil
+}
+
+// RecordEscalation creates or updates the escalation progress of an alert
+func (r *escalationRepository) RecordEscalation(ctx context.Context, escalation *models.AlertEscalation) error {
+	ctx, span := startSpan(ctx, "escalation", "RecordEscalation")
+	defer span.End()
+
+	query := `
+		INSERT INTO alert_escalations (alert_id, policy_id, level, last_escalated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (alert_id) DO UPDATE
+		SET policy_id = EXCLUDED.policy_id, level = EXCLUDED.level, last_escalated_at = EXCLUDED.last_escalated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		escalation.AlertID,
+		escalation.PolicyID,
+		escalation.Level,
+		escalation.LastEscalatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("alert_id", escalation.AlertID).WithError(err).Error("Failed to record alert escalation")
+		return fmt.Errorf("failed to record alert escalation: %w", err)
+	}
+
+	return nil
+}
+
+// Helper methods
+
+// checkAffected returns ErrNotFound if a statement did not touch any rows
+func (r *escalationRepository) checkAffected(result sql.Result, kind, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s %s", ErrNotFound, kind, id)
+	}
+
+	return nil
+}
+
+// scanPolicy scans a single row into an escalation policy
+func (r *escalationRepository) scanPolicy(row rowScanner) (*models.EscalationPolicy, error) {
+	policy := &models.EscalationPolicy{}
+	var description sql.NullString
+	var stepsJSON []byte
+
+	err := row.Scan(
+		&policy.ID,
+		&policy.Name,
+		&description,
+		&stepsJSON,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Description = description.String
+
+	var records []escalationStepRecord
+	if err := unmarshalJSON(stepsJSON, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal escalation steps: %w", err)
+	}
+
+	policy.Steps = make([]models.EscalationStep, len(records))
+	for i, record := range records {
+		policy.Steps[i] = models.EscalationStep{
+			Delay:      time.Duration(record.DelaySeconds) * time.Second,
+			Recipients: record.Recipients,
+			ScheduleID: record.ScheduleID,
+		}
+	}
+
+	return policy, nil
+}
+
+// scanSchedule scans a single row into an on-call schedule
+func (r *escalationRepository) scanSchedule(row rowScanner) (*models.OnCallSchedule, error) {
+	schedule := &models.OnCallSchedule{}
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.RotationStart,
+		pq.Array(&schedule.Participants),
+		&schedule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// marshalSteps converts escalation steps to their stored JSON form
+func marshalSteps(steps []models.EscalationStep) ([]byte, error) {
+	records := make([]escalationStepRecord, len(steps))
+	for i, step := range steps {
+		records[i] = escalationStepRecord{
+			DelaySeconds: int64(step.Delay / time.Second),
+			Recipients:   step.Recipients,
+			ScheduleID:   step.ScheduleID,
+		}
+	}
+
+	stepsJSON, err := marshalJSON(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal escalation steps: %w", err)
+	}
+
+	return stepsJSON, nil
+}
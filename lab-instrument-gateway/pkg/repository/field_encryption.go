@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+)
+
+// DefaultReencryptBatchSize is the number of rows ReencryptFields reads at once
+const DefaultReencryptBatchSize = 500
+
+// reencryptTarget is a JSON column rewritten by ReencryptFields, with the
+// smallest value of its table's key to start paging from
+type reencryptTarget struct {
+	table  string
+	column string
+	start  string
+}
+
+// reencryptTargets are the metadata columns holding encrypted fields. The
+// audit log stores its snapshots in plaintext, though entries written before
+// that still need the key they were encrypted with to be read.
+var reencryptTargets = []reencryptTarget{
+	{table: "devices", column: "metadata", start: ""},
+	{table: "measurements", column: "metadata", start: "00000000-0000-0000-0000-000000000000"},
+}
+
+// ReencryptResult reports the rows ReencryptFields rewrote, by table
+type ReencryptResult struct {
+	Scanned   map[string]int64
+	Rewritten map[string]int64
+}
+
+// ReencryptFields rewrites device and measurement metadata whose designated
+// fields are in plaintext or encrypted under a retired key, so that they are
+// encrypted under the current primary key. It is used after rotating keys or
+// designating new fields, and requires a field cipher to be set.
+func ReencryptFields(ctx context.Context, cm *db.ConnectionManager, log *logger.Logger, batchSize int) (*ReencryptResult, error) {
+	cipher := fieldCipher.Load()
+	if cipher == nil {
+		return nil, errors.New("field encryption is not configured")
+	}
+
+	if batchSize <= 0 {
+		batchSize = DefaultReencryptBatchSize
+	}
+
+	result := &ReencryptResult{
+		Scanned:   make(map[string]int64),
+		Rewritten: make(map[string]int64),
+	}
+
+	for _, target := range reencryptTargets {
+		selectQuery := fmt.Sprintf(`SELECT id, %s FROM %s WHERE id > $1 AND %s IS NOT NULL AND %s != '{}' ORDER BY id LIMIT $2`,
+			target.column, target.table, target.column, target.column)
+		updateQuery := fmt.Sprintf(`UPDATE %s SET %s = $2 WHERE id = $1`, target.table, target.column)
+
+		lastID := target.start
+		for {
+			rows, err := cm.QueryContext(ctx, selectQuery, lastID, batchSize)
+			if err != nil {
+				return result, fmt.Errorf("failed to read %s: %w", target.table, err)
+			}
+
+			type row struct {
+				id       string
+				document interface{}
+			}
+			var batch []row
+			for rows.Next() {
+				var id string
+				var data []byte
+				if err := rows.Scan(&id, &data); err != nil {
+					rows.Close()
+					return result, fmt.Errorf("failed to scan %s: %w", target.table, err)
+				}
+
+				var document interface{}
+				if err := json.Unmarshal(data, &document); err != nil {
+					rows.Close()
+					return result, fmt.Errorf("invalid %s.%s of %s: %w", target.table, target.column, id, err)
+				}
+				batch = append(batch, row{id: id, document: document})
+			}
+			err = rows.Err()
+			rows.Close()
+			if err != nil {
+				return result, fmt.Errorf("error iterating %s rows: %w", target.table, err)
+			}
+
+			for _, r := range batch {
+				lastID = r.id
+				result.Scanned[target.table]++
+
+				if !cipher.NeedsRotation(r.document) {
+					continue
+				}
+
+				plaintext, err := cipher.Decrypt(r.document)
+				if err != nil {
+					return result, fmt.Errorf("failed to decrypt %s.%s of %s: %w", target.table, target.column, r.id, err)
+				}
+				data, err := marshalJSON(plaintext)
+				if err != nil {
+					return result, fmt.Errorf("failed to encrypt %s.%s of %s: %w", target.table, target.column, r.id, err)
+				}
+
+				if _, err := cm.ExecContext(ctx, updateQuery, r.id, data); err != nil {
+					return result, fmt.Errorf("failed to rewrite %s.%s of %s: %w", target.table, target.column, r.id, err)
+				}
+				result.Rewritten[target.table]++
+			}
+
+			if len(batch) < batchSize {
+				break
+			}
+		}
+
+		log.WithFields(map[string]interface{}{
+			"table":     target.table,
+			"scanned":   result.Scanned[target.table],
+			"rewritten": result.Rewritten[target.table],
+		}).Info("Re-encrypted metadata fields")
+	}
+
+	return result, nil
+}
@@ -39,6 +39,9 @@ type DeviceFilter struct {
 	LastSeenAfter *time.Time
 	LastSeenBefore *time.Time
 	MetadataFilters map[string]interface{}
+	
+	// Groups restricts the result to devices in any of the device groups
+	Groups []string
 }
 
 // MeasurementFilter represents measurement-specific filtering options
@@ -70,6 +73,44 @@ type AlertFilter struct {
 	Severities   []models.AlertSeverity
 	Acknowledged *bool
 	Resolved     *bool
+	Silenced     *bool
+}
+
+// DeviceEventFilter represents device event filtering options
+type DeviceEventFilter struct {
+	Filter
+	TimeRangeFilter
+	DeviceID string
+	Types    []models.DeviceEventType
+}
+
+// SilenceFilter represents silence-specific filtering options
+type SilenceFilter struct {
+	Filter
+	DeviceIDs []string
+	CreatedBy *string
+	ActiveAt  *time.Time
+}
+
+// APIKeyFilter represents filtering options for API key queries
+type APIKeyFilter struct {
+	Filter
+	Subject        string
+	IncludeRevoked bool
+	
+	// WithinGroups limits results to keys scoped to a non-empty subset of
+	// these device groups
+	WithinGroups []string
+}
+
+// AuditFilter represents audit trail filtering options
+type AuditFilter struct {
+	Filter
+	TimeRangeFilter
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   string
 }
 
 // AggregationRequest represents aggregation parameters
@@ -117,6 +158,7 @@ type DeviceRepository interface {
 	// Status operations
 	UpdateStatus(ctx context.Context, deviceID string, status models.DeviceStatus) error
 	UpdateLastSeen(ctx context.Context, deviceID string, timestamp time.Time) error
+	GetStatusCounts(ctx context.Context) (map[models.DeviceStatus]int64, error)
 	
 	// Search operations
 	SearchByMetadata(ctx context.Context, metadata map[string]interface{}) ([]*models.Device, error)
@@ -174,6 +216,17 @@ type CommandRepository interface {
 	GetExecutingCommands(ctx context.Context, deviceID string) ([]*models.Command, error)
 	UpdateStatus(ctx context.Context, commandID string, status models.CommandStatus) error
 	
+	// Claim marks a pending command executing, returning false when it is
+	// no longer pending, e.g. because another dispatch claimed it first
+	Claim(ctx context.Context, command *models.Command) (bool, error)
+	
+	// ListSignatures lists the electronic signatures of a command by its ID
+	ListSignatures(ctx context.Context, id string) ([]*models.CommandSignature, error)
+	
+	// SignatureTokenUsed reports whether a signer has already signed with
+	// the credential of the given token ID
+	SignatureTokenUsed(ctx context.Context, signer, tokenID string) (bool, error)
+	
 	// Timeout and cleanup operations
 	GetExpiredCommands(ctx context.Context) ([]*models.Command, error)
 	MarkExpiredAsTimeout(ctx context.Context) (int64, error)
@@ -212,12 +265,150 @@ type AlertRepository interface {
 	DeleteResolvedOlderThan(ctx context.Context, threshold time.Time) (int64, error)
 }
 
+// DeviceEventRepository defines the interface for the device lifecycle event log
+type DeviceEventRepository interface {
+	Create(ctx context.Context, event *models.DeviceEvent) error
+	
+	// Query operations; events are listed newest first unless sorted otherwise
+	List(ctx context.Context, filter DeviceEventFilter) ([]*models.DeviceEvent, error)
+	Count(ctx context.Context, filter DeviceEventFilter) (int64, error)
+	GetLastBefore(ctx context.Context, deviceID string, eventType models.DeviceEventType, before time.Time) (*models.DeviceEvent, error)
+	
+	// Cleanup operations
+	DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error)
+}
+
+// DeviceSessionRepository defines the interface for persisted connection sessions
+type DeviceSessionRepository interface {
+	Create(ctx context.Context, session *models.DeviceSession) error
+	GetBySessionID(ctx context.Context, sessionID string) (*models.DeviceSession, error)
+	ListByDevice(ctx context.Context, deviceID string, timeRange TimeRangeFilter, limit int) ([]*models.DeviceSession, error)
+	
+	// Lifecycle operations
+	End(ctx context.Context, sessionID string, endedAt time.Time, reason string) error
+	Resume(ctx context.Context, sessionID string, at time.Time) error
+	
+	// Cleanup operations
+	DeleteEndedOlderThan(ctx context.Context, threshold time.Time) (int64, error)
+}
+
+// SilenceRepository defines the interface for alert silence operations
+type SilenceRepository interface {
+	// Basic CRUD operations
+	Create(ctx context.Context, silence *models.Silence) error
+	GetByID(ctx context.Context, id string) (*models.Silence, error)
+	Update(ctx context.Context, silence *models.Silence) error
+	Delete(ctx context.Context, id string) error
+	
+	// Query operations
+	List(ctx context.Context, filter SilenceFilter) ([]*models.Silence, error)
+	Count(ctx context.Context, filter SilenceFilter) (int64, error)
+	GetActive(ctx context.Context, at time.Time) ([]*models.Silence, error)
+	
+	// Lifecycle operations
+	Expire(ctx context.Context, id string) error
+	DeleteExpiredOlderThan(ctx context.Context, threshold time.Time) (int64, error)
+}
+
+// EscalationRepository defines the interface for alert escalation policies,
+// routes, on-call schedules and per-alert escalation progress
+type EscalationRepository interface {
+	// Policy operations
+	CreatePolicy(ctx context.Context, policy *models.EscalationPolicy) error
+	GetPolicy(ctx context.Context, id string) (*models.EscalationPolicy, error)
+	UpdatePolicy(ctx context.Context, policy *models.EscalationPolicy) error
+	DeletePolicy(ctx context.Context, id string) error
+	ListPolicies(ctx context.Context) ([]*models.EscalationPolicy, error)
+	
+	// Route operations; routes are listed in ascending priority order
+	CreateRoute(ctx context.Context, route *models.AlertRoute) error
+	DeleteRoute(ctx context.Context, id string) error
+	ListRoutes(ctx context.Context) ([]*models.AlertRoute, error)
+	
+	// On-call schedule operations
+	CreateSchedule(ctx context.Context, schedule *models.OnCallSchedule) error
+	GetSchedule(ctx context.Context, id string) (*models.OnCallSchedule, error)
+	DeleteSchedule(ctx context.Context, id string) error
+	ListSchedules(ctx context.Context) ([]*models.OnCallSchedule, error)
+	
+	// Escalation progress operations
+	GetEscalations(ctx context.Context, alertIDs []string) (map[string]*models.AlertEscalation, error)
+	RecordEscalation(ctx context.Context, escalation *models.AlertEscalation) error
+}
+
+// RoleRepository defines the interface for access control roles and the
+// bindings that grant them to subjects
+type RoleRepository interface {
+	// Role operations
+	CreateRole(ctx context.Context, role *models.Role) error
+	GetRole(ctx context.Context, name string) (*models.Role, error)
+	UpdateRole(ctx context.Context, role *models.Role) error
+	DeleteRole(ctx context.Context, name string) error
+	ListRoles(ctx context.Context) ([]*models.Role, error)
+	
+	// Binding operations; an empty subject lists the bindings of every subject
+	CreateBinding(ctx context.Context, binding *models.RoleBinding) error
+	DeleteBinding(ctx context.Context, id string) error
+	ListBindings(ctx context.Context, subject string) ([]*models.RoleBinding, error)
+}
+
+// ProvisioningRepository defines the interface for device enrollment tokens
+// and the certificates issued to enrolled devices
+type ProvisioningRepository interface {
+	// Enrollment token operations. ConsumeEnrollmentToken atomically marks an
+	// unused, unexpired token as used and returns it, or returns ErrNotFound.
+	CreateEnrollmentToken(ctx context.Context, token *models.EnrollmentToken) error
+	ConsumeEnrollmentToken(ctx context.Context, tokenHash string, deviceID string, at time.Time) (*models.EnrollmentToken, error)
+	DeleteExpiredEnrollmentTokens(ctx context.Context, threshold time.Time) (int64, error)
+	
+	// Certificate operations
+	RecordCertificate(ctx context.Context, certificate *models.DeviceCertificate) error
+	ListCertificates(ctx context.Context, deviceID string) ([]*models.DeviceCertificate, error)
+}
+
+// APIKeyRepository defines the interface for API key operations
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByID(ctx context.Context, id string) (*models.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	List(ctx context.Context, filter APIKeyFilter) ([]*models.APIKey, error)
+	Count(ctx context.Context, filter APIKeyFilter) (int64, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	
+	// TouchLastUsed records that a key was used at the given time
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+// AuditRepository defines the interface for the append-only audit trail.
+// Entries can be appended and read but never changed or deleted.
+type AuditRepository interface {
+	// Append assigns the entry the next sequence number, chains it to the
+	// last entry and stores it
+	Append(ctx context.Context, entry *models.AuditEntry) error
+	
+	// Query operations; entries are listed newest first
+	List(ctx context.Context, filter AuditFilter) ([]*models.AuditEntry, error)
+	Count(ctx context.Context, filter AuditFilter) (int64, error)
+	
+	// ListAfter lists up to limit entries following a sequence number in
+	// chain order, for verification
+	ListAfter(ctx context.Context, sequence int64, limit int) ([]*models.AuditEntry, error)
+}
+
 // RepositoryManager defines the interface for managing all repositories
 type RepositoryManager interface {
 	Device() DeviceRepository
 	Measurement() MeasurementRepository
 	Command() CommandRepository
 	Alert() AlertRepository
+	Silence() SilenceRepository
+	Escalation() EscalationRepository
+	DeviceEvent() DeviceEventRepository
+	DeviceSession() DeviceSessionRepository
+	Role() RoleRepository
+	Provisioning() ProvisioningRepository
+	APIKey() APIKeyRepository
+	Audit() AuditRepository
 	
 	// Transaction support
 	WithTransaction(ctx context.Context, fn func(ctx context.Context, repos RepositoryManager) error) error
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/yourorg/lab-gateway/pkg/db"
@@ -13,21 +14,47 @@ type repositoryManager struct {
 	db     *db.ConnectionManager
 	logger *logger.Logger
 
+	// tx is the transaction the repositories run in, nil outside
+	// WithTransaction
+	tx *sql.Tx
+
 	deviceRepo      DeviceRepository
 	measurementRepo MeasurementRepository
 	commandRepo     CommandRepository
 	alertRepo       AlertRepository
+	silenceRepo     SilenceRepository
+	escalationRepo  EscalationRepository
+	eventRepo       DeviceEventRepository
+	sessionRepo     DeviceSessionRepository
+	roleRepo        RoleRepository
+	provisionRepo   ProvisioningRepository
+	apiKeyRepo      APIKeyRepository
+	auditRepo       AuditRepository
 }
 
 // NewRepositoryManager creates a new repository manager
 func NewRepositoryManager(db *db.ConnectionManager, logger *logger.Logger) RepositoryManager {
+	return newRepositoryManager(db, db, logger)
+}
+
+// newRepositoryManager creates a repository manager whose repositories run
+// their queries on exec
+func newRepositoryManager(cm *db.ConnectionManager, exec db.Executor, logger *logger.Logger) *repositoryManager {
 	return &repositoryManager{
-		db:              db,
+		db:              cm,
 		logger:          logger,
-		deviceRepo:      NewDeviceRepository(db, logger),
-		measurementRepo: NewMeasurementRepository(db, logger),
-		commandRepo:     NewCommandRepository(db, logger),
-		alertRepo:       NewAlertRepository(db, logger),
+		deviceRepo:      NewDeviceRepository(exec, logger),
+		measurementRepo: NewMeasurementRepository(exec, logger),
+		commandRepo:     NewCommandRepository(exec, logger),
+		alertRepo:       NewAlertRepository(exec, logger),
+		silenceRepo:     NewSilenceRepository(exec, logger),
+		escalationRepo:  NewEscalationRepository(exec, logger),
+		eventRepo:       NewDeviceEventRepository(exec, logger),
+		sessionRepo:     NewDeviceSessionRepository(exec, logger),
+		roleRepo:        NewRoleRepository(exec, logger),
+		provisionRepo:   NewProvisioningRepository(exec, logger),
+		apiKeyRepo:      NewAPIKeyRepository(exec, logger),
+		auditRepo:       NewAuditRepository(exec, logger),
 	}
 }
 
@@ -51,28 +78,66 @@ func (rm *repositoryManager) Alert() AlertRepository {
 	return rm.alertRepo
 }
 
-// WithTransaction executes a function within a database transaction
+// Silence returns the alert silence repository
+func (rm *repositoryManager) Silence() SilenceRepository {
+	return rm.silenceRepo
+}
+
+// Escalation returns the alert escalation repository
+func (rm *repositoryManager) Escalation() EscalationRepository {
+	return rm.escalationRepo
+}
+
+// DeviceEvent returns the device lifecycle event repository
+func (rm *repositoryManager) DeviceEvent() DeviceEventRepository {
+	return rm.eventRepo
+}
+
+// DeviceSession returns the device session repository
+func (rm *repositoryManager) DeviceSession() DeviceSessionRepository {
+	return rm.sessionRepo
+}
+
+// Role returns the access control role repository
+func (rm *repositoryManager) Role() RoleRepository {
+	return rm.roleRepo
+}
+
+// Provisioning returns the device provisioning repository
+func (rm *repositoryManager) Provisioning() ProvisioningRepository {
+	return rm.provisionRepo
+}
+
+// APIKey returns the API key repository
+func (rm *repositoryManager) APIKey() APIKeyRepository {
+	return rm.apiKeyRepo
+}
+
+// Audit returns the audit trail repository
+func (rm *repositoryManager) Audit() AuditRepository {
+	return rm.auditRepo
+}
+
+// WithTransaction executes a function within a database transaction, using
+// the configured isolation level. The repositories passed to fn run in the
+// transaction, which is committed if fn succeeds and rolled back otherwise.
+// Transactions failing on a serialization failure are run again, so fn may
+// be called more than once. Nested calls join the enclosing transaction.
 func (rm *repositoryManager) WithTransaction(ctx context.Context, fn func(ctx context.Context, repos RepositoryManager) error) error {
-	tx, err := rm.db.BeginTx(ctx, nil)
-	if err != nil {
-		rm.logger.WithError(err).Error("Failed to begin transaction")
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	if rm.tx != nil {
+		return fn(ctx, rm)
 	}
-	defer tx.Rollback()
 
-	// For now, just use the same repository manager within the transaction
-	// TODO: Implement proper transactional repositories
-	if err := fn(ctx, rm); err != nil {
-		rm.logger.WithError(err).Error("Transaction function failed")
+	err := rm.db.RunInTx(ctx, rm.db.TxDefaults(), func(ctx context.Context, tx *sql.Tx) error {
+		repos := newRepositoryManager(rm.db, tx, rm.logger)
+		repos.tx = tx
+		return fn(ctx, repos)
+	})
+	if err != nil {
+		rm.logger.WithError(err).Debug("Transaction rolled back")
 		return err
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		rm.logger.WithError(err).Error("Failed to commit transaction")
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	rm.logger.Debug("Transaction committed successfully")
 	return nil
 }
@@ -121,6 +186,3 @@ func (rm *repositoryManager) Close() error {
 	rm.logger.Info("Repository manager closed successfully")
 	return nil
 }
-
-// TODO: Implement transactional repositories when needed
-// For now, transactions are handled at the individual repository level
\ No newline at end of file
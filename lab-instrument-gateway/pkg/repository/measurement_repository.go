@@ -15,12 +15,12 @@ import (
 
 // measurementRepository implements MeasurementRepository interface
 type measurementRepository struct {
-	db     *db.ConnectionManager
+	db     db.Executor
 	logger *logger.Logger
 }
 
 // NewMeasurementRepository creates a new measurement repository
-func NewMeasurementRepository(db *db.ConnectionManager, logger *logger.Logger) MeasurementRepository {
+func NewMeasurementRepository(db db.Executor, logger *logger.Logger) MeasurementRepository {
 	return &measurementRepository{
 		db:     db,
 		logger: logger,
@@ -29,6 +29,9 @@ func NewMeasurementRepository(db *db.ConnectionManager, logger *logger.Logger) M
 
 // Create creates a new measurement
 func (r *measurementRepository) Create(ctx context.Context, measurement *models.Measurement) error {
+	ctx, span := startSpan(ctx, "measurement", "Create")
+	defer span.End()
+
 	if err := measurement.Validate(); err != nil {
 		return fmt.Errorf("measurement validation failed: %w", err)
 	}
@@ -69,6 +72,9 @@ func (r *measurementRepository) Create(ctx context.Context, measurement *models.
 
 // GetByID retrieves a measurement by ID
 func (r *measurementRepository) GetByID(ctx context.Context, id string) (*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetByID")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, timestamp, type, value, unit, quality, metadata, batch_id, sequence_number, created_at
 		FROM measurements
@@ -94,7 +100,7 @@ func (r *measurementRepository) GetByID(ctx context.Context, id string) (*models
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("measurement not found: %s", id)
+			return nil, fmt.Errorf("%w: measurement %s", ErrNotFound, id)
 		}
 		r.logger.WithError(err).Error("Failed to get measurement")
 		return nil, fmt.Errorf("failed to get measurement: %w", err)
@@ -109,6 +115,9 @@ func (r *measurementRepository) GetByID(ctx context.Context, id string) (*models
 
 // Delete deletes a measurement by ID
 func (r *measurementRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "measurement", "Delete")
+	defer span.End()
+
 	query := `DELETE FROM measurements WHERE id = $1`
 
 	result, err := r.db.ExecContext(ctx, query, id)
@@ -123,7 +132,7 @@ func (r *measurementRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("measurement not found: %s", id)
+		return fmt.Errorf("%w: measurement %s", ErrNotFound, id)
 	}
 
 	return nil
@@ -131,13 +140,16 @@ func (r *measurementRepository) Delete(ctx context.Context, id string) error {
 
 // CreateBulk creates multiple measurements in a single transaction for high-throughput scenarios
 func (r *measurementRepository) CreateBulk(ctx context.Context, measurements []*models.Measurement) (*BulkResult, error) {
+	ctx, span := startSpan(ctx, "measurement", "CreateBulk")
+	defer span.End()
+
 	if len(measurements) == 0 {
 		return &BulkResult{}, nil
 	}
 
 	result := &BulkResult{}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := db.Begin(ctx, r.db)
 	if err != nil {
 		return result, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -207,6 +219,9 @@ func (r *measurementRepository) CreateBulk(ctx context.Context, measurements []*
 
 // CreateBatch creates a batch of measurements with shared metadata
 func (r *measurementRepository) CreateBatch(ctx context.Context, batch *models.MeasurementBatch) error {
+	ctx, span := startSpan(ctx, "measurement", "CreateBatch")
+	defer span.End()
+
 	if len(batch.Measurements) == 0 {
 		return nil
 	}
@@ -244,9 +259,12 @@ func (r *measurementRepository) CreateBatch(ctx context.Context, batch *models.M
 
 // List retrieves measurements with filtering and pagination
 func (r *measurementRepository) List(ctx context.Context, filter MeasurementFilter) ([]*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "List")
+	defer span.End()
+
 	query, args := r.buildListQuery(filter)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to list measurements")
 		return nil, fmt.Errorf("failed to list measurements: %w", err)
@@ -294,10 +312,13 @@ func (r *measurementRepository) List(ctx context.Context, filter MeasurementFilt
 
 // Count returns the total number of measurements matching the filter
 func (r *measurementRepository) Count(ctx context.Context, filter MeasurementFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "measurement", "Count")
+	defer span.End()
+
 	query, args := r.buildCountQuery(filter)
 
 	var count int64
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := db.ForRead(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to count measurements")
 		return 0, fmt.Errorf("failed to count measurements: %w", err)
@@ -308,6 +329,9 @@ func (r *measurementRepository) Count(ctx context.Context, filter MeasurementFil
 
 // GetByTimeRange retrieves measurements within a time range
 func (r *measurementRepository) GetByTimeRange(ctx context.Context, deviceID string, startTime, endTime time.Time) ([]*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetByTimeRange")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, timestamp, type, value, unit, quality, metadata, batch_id, sequence_number, created_at
 		FROM measurements
@@ -315,7 +339,7 @@ func (r *measurementRepository) GetByTimeRange(ctx context.Context, deviceID str
 		ORDER BY timestamp ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, deviceID, startTime, endTime)
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, deviceID, startTime, endTime)
 	if err != nil {
 		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get measurements by time range")
 		return nil, fmt.Errorf("failed to get measurements by time range: %w", err)
@@ -359,6 +383,9 @@ func (r *measurementRepository) GetByTimeRange(ctx context.Context, deviceID str
 
 // GetLatest retrieves the latest measurement for a device and type
 func (r *measurementRepository) GetLatest(ctx context.Context, deviceID string, measurementType string) (*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetLatest")
+	defer span.End()
+
 	query := `
 		SELECT id, device_id, timestamp, type, value, unit, quality, metadata, batch_id, sequence_number, created_at
 		FROM measurements
@@ -386,7 +413,7 @@ func (r *measurementRepository) GetLatest(ctx context.Context, deviceID string,
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("no measurements found for device %s and type %s", deviceID, measurementType)
+			return nil, fmt.Errorf("%w: %s measurement for device %s", ErrNotFound, measurementType, deviceID)
 		}
 		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get latest measurement")
 		return nil, fmt.Errorf("failed to get latest measurement: %w", err)
@@ -402,6 +429,9 @@ func (r *measurementRepository) GetLatest(ctx context.Context, deviceID string,
 
 // GetLatestByDevice retrieves the latest measurements for a device (one per type)
 func (r *measurementRepository) GetLatestByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetLatestByDevice")
+	defer span.End()
+
 	query := `
 		SELECT DISTINCT ON (type) id, device_id, timestamp, type, value, unit, quality, metadata, batch_id, sequence_number, created_at
 		FROM measurements
@@ -454,9 +484,12 @@ func (r *measurementRepository) GetLatestByDevice(ctx context.Context, deviceID
 
 // Aggregate performs data aggregation on measurements
 func (r *measurementRepository) Aggregate(ctx context.Context, req AggregationRequest) ([]*AggregationResult, error) {
+	ctx, span := startSpan(ctx, "measurement", "Aggregate")
+	defer span.End()
+
 	query, args := r.buildAggregationQuery(req)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := db.ForRead(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to aggregate measurements")
 		return nil, fmt.Errorf("failed to aggregate measurements: %w", err)
@@ -495,16 +528,22 @@ func (r *measurementRepository) Aggregate(ctx context.Context, req AggregationRe
 
 // GetStatistics retrieves statistical information for measurements
 func (r *measurementRepository) GetStatistics(ctx context.Context, filter MeasurementFilter) (*models.MeasurementStats, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetStatistics")
+	defer span.End()
+
 	query, args := r.buildStatsQuery(filter)
 
+	// The aggregates are NULL when no measurements match
 	stats := &models.MeasurementStats{}
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+	var minValue, maxValue, avgValue sql.NullFloat64
+	var earliestTime, latestTime sql.NullTime
+	err := db.ForRead(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&stats.Count,
-		&stats.MinValue,
-		&stats.MaxValue,
-		&stats.AvgValue,
-		&stats.EarliestTime,
-		&stats.LatestTime,
+		&minValue,
+		&maxValue,
+		&avgValue,
+		&earliestTime,
+		&latestTime,
 		&stats.GoodQuality,
 		&stats.BadQuality,
 	)
@@ -517,11 +556,20 @@ func (r *measurementRepository) GetStatistics(ctx context.Context, filter Measur
 		return nil, fmt.Errorf("failed to get measurement statistics: %w", err)
 	}
 
+	stats.MinValue = minValue.Float64
+	stats.MaxValue = maxValue.Float64
+	stats.AvgValue = avgValue.Float64
+	stats.EarliestTime = earliestTime.Time
+	stats.LatestTime = latestTime.Time
+
 	return stats, nil
 }
 
 // DeleteOlderThan removes measurements older than the specified threshold
 func (r *measurementRepository) DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "measurement", "DeleteOlderThan")
+	defer span.End()
+
 	query := `DELETE FROM measurements WHERE timestamp < $1`
 
 	result, err := r.db.ExecContext(ctx, query, threshold)
@@ -545,6 +593,9 @@ func (r *measurementRepository) DeleteOlderThan(ctx context.Context, threshold t
 
 // DeleteByDevice removes all measurements for a specific device
 func (r *measurementRepository) DeleteByDevice(ctx context.Context, deviceID string) (int64, error) {
+	ctx, span := startSpan(ctx, "measurement", "DeleteByDevice")
+	defer span.End()
+
 	query := `DELETE FROM measurements WHERE device_id = $1`
 
 	result, err := r.db.ExecContext(ctx, query, deviceID)
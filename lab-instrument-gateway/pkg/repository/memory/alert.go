@@ -0,0 +1,306 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// alertColumns are the columns alerts can be sorted by. Severities are
+// stored as text, so they sort alphabetically.
+var alertColumns = map[string]column[*models.Alert]{
+	"id":              func(a, b *models.Alert) int { return strings.Compare(a.ID, b.ID) },
+	"device_id":       func(a, b *models.Alert) int { return compareNullable(a.DeviceID, b.DeviceID, strings.Compare) },
+	"type":            func(a, b *models.Alert) int { return strings.Compare(string(a.Type), string(b.Type)) },
+	"severity":        func(a, b *models.Alert) int { return strings.Compare(string(a.Severity), string(b.Severity)) },
+	"message":         func(a, b *models.Alert) int { return strings.Compare(a.Message, b.Message) },
+	"acknowledged":    func(a, b *models.Alert) int { return compareBools(a.Acknowledged, b.Acknowledged) },
+	"acknowledged_at": func(a, b *models.Alert) int { return compareNullTimes(a.AcknowledgedAt, b.AcknowledgedAt) },
+	"resolved_at":     func(a, b *models.Alert) int { return compareNullTimes(a.ResolvedAt, b.ResolvedAt) },
+	"created_at":      func(a, b *models.Alert) int { return compareTimes(a.CreatedAt, b.CreatedAt) },
+	"silenced":        func(a, b *models.Alert) int { return compareBools(a.Silenced, b.Silenced) },
+}
+
+// alertRepository implements AlertRepository in memory
+type alertRepository struct {
+	*tables
+}
+
+// Create creates a new alert
+func (r *alertRepository) Create(ctx context.Context, alert *models.Alert) error {
+	if err := alert.Validate(); err != nil {
+		return fmt.Errorf("alert validation failed: %w", err)
+	}
+
+	alert.SetDefaults()
+
+	if err := checkUUID(alert.ID); err != nil {
+		return fmt.Errorf("failed to create alert: %w", err)
+	}
+	metadata, err := toJSON(alert.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	// Only the inserted columns are stored; the others start out empty
+	row := &models.Alert{
+		ID:           alert.ID,
+		DeviceID:     copyPtr(alert.DeviceID),
+		Type:         alert.Type,
+		Severity:     alert.Severity,
+		Message:      alert.Message,
+		Metadata:     metadata,
+		Acknowledged: alert.Acknowledged,
+		CreatedAt:    roundTime(alert.CreatedAt),
+		Silenced:     alert.Silenced,
+		SilencedBy:   copyPtr(alert.SilencedBy),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.alerts[row.ID]; ok {
+			return fmt.Errorf("failed to create alert: alert %s already exists", row.ID)
+		}
+		if row.DeviceID != nil {
+			if _, ok := s.devices[*row.DeviceID]; !ok {
+				return fmt.Errorf("failed to create alert: device %s does not exist", *row.DeviceID)
+			}
+		}
+		put(s, s.alerts, row.ID, row)
+		return nil
+	})
+}
+
+// GetByID retrieves an alert by ID
+func (r *alertRepository) GetByID(ctx context.Context, id string) (*models.Alert, error) {
+	var alert *models.Alert
+	err := r.read(func(s *store) error {
+		row, ok := s.alerts[id]
+		if !ok {
+			return fmt.Errorf("%w: alert %s", repository.ErrNotFound, id)
+		}
+		alert = copyAlert(row)
+		return nil
+	})
+	return alert, err
+}
+
+// Update updates an existing alert
+func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error {
+	if err := alert.Validate(); err != nil {
+		return fmt.Errorf("alert validation failed: %w", err)
+	}
+
+	metadata, err := toJSON(alert.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return r.change(alert.ID, fmt.Sprintf("alert %s", alert.ID), func(row *models.Alert) bool { return true }, func(changed *models.Alert) {
+		changed.Type = alert.Type
+		changed.Severity = alert.Severity
+		changed.Message = alert.Message
+		changed.Metadata = metadata
+		changed.Acknowledged = alert.Acknowledged
+		changed.AcknowledgedBy = copyPtr(alert.AcknowledgedBy)
+		changed.AcknowledgedAt = roundTimePtr(alert.AcknowledgedAt)
+		changed.ResolvedAt = roundTimePtr(alert.ResolvedAt)
+		changed.Silenced = alert.Silenced
+		changed.SilencedBy = copyPtr(alert.SilencedBy)
+	})
+}
+
+// change applies a change to a copy of an alert and stores it, if the alert
+// exists and matches the condition
+func (r *alertRepository) change(id, missing string, match func(row *models.Alert) bool, apply func(changed *models.Alert)) error {
+	return r.write(func(s *store) error {
+		row, ok := s.alerts[id]
+		if !ok || !match(row) {
+			return fmt.Errorf("%w: %s", repository.ErrNotFound, missing)
+		}
+		changed := copyAlert(row)
+		apply(changed)
+		put(s, s.alerts, id, changed)
+		return nil
+	})
+}
+
+// Delete removes an alert
+func (r *alertRepository) Delete(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.alerts[id]; !ok {
+			return fmt.Errorf("%w: alert %s", repository.ErrNotFound, id)
+		}
+		s.deleteAlert(id)
+		return nil
+	})
+}
+
+// List retrieves alerts with filtering and pagination
+func (r *alertRepository) List(ctx context.Context, filter repository.AlertFilter) ([]*models.Alert, error) {
+	var alerts []*models.Alert
+	err := r.read(func(s *store) error {
+		matched := rows(s.alerts, func(alert *models.Alert) bool { return matchAlert(alert, filter) })
+		if err := sortRows(matched, alertColumns, filter.Filter, "created_at"); err != nil {
+			return err
+		}
+		for _, row := range paginate(matched, filter.Filter) {
+			alerts = append(alerts, copyAlert(row))
+		}
+		return nil
+	})
+	return alerts, err
+}
+
+// Count returns the total number of alerts matching the filter
+func (r *alertRepository) Count(ctx context.Context, filter repository.AlertFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		count = int64(len(rows(s.alerts, func(alert *models.Alert) bool { return matchAlert(alert, filter) })))
+		return nil
+	})
+	return count, err
+}
+
+// Acknowledge acknowledges an alert that is not acknowledged yet
+func (r *alertRepository) Acknowledge(ctx context.Context, alertID string, acknowledgedBy string) error {
+	now := roundTime(time.Now())
+	return r.change(alertID, fmt.Sprintf("unacknowledged alert %s", alertID), func(row *models.Alert) bool {
+		return !row.Acknowledged
+	}, func(changed *models.Alert) {
+		changed.Acknowledged = true
+		changed.AcknowledgedBy = &acknowledgedBy
+		changed.AcknowledgedAt = &now
+	})
+}
+
+// Resolve resolves an alert that is not resolved yet
+func (r *alertRepository) Resolve(ctx context.Context, alertID string) error {
+	now := roundTime(time.Now())
+	return r.change(alertID, fmt.Sprintf("unresolved alert %s", alertID), func(row *models.Alert) bool {
+		return row.ResolvedAt == nil
+	}, func(changed *models.Alert) {
+		changed.ResolvedAt = &now
+	})
+}
+
+// GetUnacknowledged retrieves all unacknowledged alerts
+func (r *alertRepository) GetUnacknowledged(ctx context.Context) ([]*models.Alert, error) {
+	return r.query(func(alert *models.Alert) bool { return !alert.Acknowledged }, bySeverityDesc, 0)
+}
+
+// GetUnresolved retrieves all unresolved alerts
+func (r *alertRepository) GetUnresolved(ctx context.Context) ([]*models.Alert, error) {
+	return r.query(func(alert *models.Alert) bool { return alert.ResolvedAt == nil }, bySeverityDesc, 0)
+}
+
+// GetCriticalAlerts retrieves all unresolved critical alerts
+func (r *alertRepository) GetCriticalAlerts(ctx context.Context) ([]*models.Alert, error) {
+	return r.query(func(alert *models.Alert) bool {
+		return alert.Severity == models.AlertSeverityCritical && alert.ResolvedAt == nil
+	}, byAlertCreatedAtDesc, 0)
+}
+
+// GetAlertStats retrieves the number of alerts of each severity created
+// within the time range
+func (r *alertRepository) GetAlertStats(ctx context.Context, timeRange repository.TimeRangeFilter) (map[models.AlertSeverity]int64, error) {
+	stats := make(map[models.AlertSeverity]int64)
+	err := r.read(func(s *store) error {
+		for _, row := range s.alerts {
+			if inRange(row.CreatedAt, timeRange) {
+				stats[row.Severity]++
+			}
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// GetAlertsByDevice retrieves the latest alerts of a device
+func (r *alertRepository) GetAlertsByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Alert, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("failed to get alerts by device: LIMIT must not be negative")
+	}
+	if limit == 0 {
+		return nil, nil
+	}
+	return r.query(func(alert *models.Alert) bool { return equalPtr(alert.DeviceID, deviceID) }, byAlertCreatedAtDesc, limit)
+}
+
+// DeleteResolvedOlderThan removes alerts resolved before the threshold
+func (r *alertRepository) DeleteResolvedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		for id, row := range s.alerts {
+			if row.ResolvedAt != nil && row.ResolvedAt.Before(threshold) {
+				s.deleteAlert(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// query lists the alerts matching a condition in the given order, up to
+// limit alerts unless limit is zero
+func (r *alertRepository) query(match func(alert *models.Alert) bool, compare func(a, b *models.Alert) int, limit int) ([]*models.Alert, error) {
+	var alerts []*models.Alert
+	err := r.read(func(s *store) error {
+		matched := rows(s.alerts, match)
+		slices.SortStableFunc(matched, compare)
+		if limit > 0 && len(matched) > limit {
+			matched = matched[:limit]
+		}
+		for _, row := range matched {
+			alerts = append(alerts, copyAlert(row))
+		}
+		return nil
+	})
+	return alerts, err
+}
+
+func bySeverityDesc(a, b *models.Alert) int {
+	if c := strings.Compare(string(b.Severity), string(a.Severity)); c != 0 {
+		return c
+	}
+	return byAlertCreatedAtDesc(a, b)
+}
+
+func byAlertCreatedAtDesc(a, b *models.Alert) int {
+	return compareTimes(b.CreatedAt, a.CreatedAt)
+}
+
+// matchAlert returns true if an alert matches every condition of the filter
+func matchAlert(alert *models.Alert, filter repository.AlertFilter) bool {
+	if len(filter.DeviceIDs) > 0 && (alert.DeviceID == nil || !slices.Contains(filter.DeviceIDs, *alert.DeviceID)) {
+		return false
+	}
+	if filter.Acknowledged != nil && alert.Acknowledged != *filter.Acknowledged {
+		return false
+	}
+	if filter.Resolved != nil && (alert.ResolvedAt != nil) != *filter.Resolved {
+		return false
+	}
+	if filter.Silenced != nil && alert.Silenced != *filter.Silenced {
+		return false
+	}
+	return matchAny(filter.Types, alert.Type) &&
+		matchAny(filter.Severities, alert.Severity) &&
+		inRange(alert.CreatedAt, filter.TimeRangeFilter)
+}
+
+func copyAlert(alert *models.Alert) *models.Alert {
+	copied := *alert
+	copied.DeviceID = copyPtr(alert.DeviceID)
+	copied.Metadata = copyJSON(alert.Metadata)
+	copied.AcknowledgedAt = copyPtr(alert.AcknowledgedAt)
+	copied.AcknowledgedBy = copyPtr(alert.AcknowledgedBy)
+	copied.ResolvedAt = copyPtr(alert.ResolvedAt)
+	copied.SilencedBy = copyPtr(alert.SilencedBy)
+	return &copied
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// apiKeyRepository implements APIKeyRepository in memory
+type apiKeyRepository struct {
+	*tables
+}
+
+// Create stores a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	key.SetDefaults()
+
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("API key validation failed: %w", err)
+	}
+
+	if err := checkUUID(key.ID); err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	row := &models.APIKey{
+		ID:           key.ID,
+		Prefix:       key.Prefix,
+		KeyHash:      key.KeyHash,
+		Name:         key.Name,
+		Subject:      key.Subject,
+		Roles:        copyStrings(key.Roles),
+		DeviceGroups: copyStrings(key.DeviceGroups),
+		DeviceID:     copyPtr(key.DeviceID),
+		ExpiresAt:    roundTimePtr(key.ExpiresAt),
+		CreatedBy:    key.CreatedBy,
+		CreatedAt:    roundTime(key.CreatedAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.apiKeys[row.ID]; ok {
+			return fmt.Errorf("failed to create API key: API key %s already exists", row.ID)
+		}
+		for _, existing := range s.apiKeys {
+			if existing.Prefix == row.Prefix {
+				return fmt.Errorf("failed to create API key: prefix %s already exists", row.Prefix)
+			}
+		}
+		if row.DeviceID != nil {
+			if _, ok := s.devices[*row.DeviceID]; !ok {
+				return fmt.Errorf("failed to create API key: device %s does not exist", *row.DeviceID)
+			}
+		}
+		put(s, s.apiKeys, row.ID, row)
+		return nil
+	})
+}
+
+// GetByID retrieves an API key by ID
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	return r.get(id, func(key *models.APIKey) bool { return key.ID == id })
+}
+
+// GetByPrefix retrieves an API key by its public prefix
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	return r.get(prefix, func(key *models.APIKey) bool { return key.Prefix == prefix })
+}
+
+// get retrieves the API key matching a condition
+func (r *apiKeyRepository) get(name string, match func(key *models.APIKey) bool) (*models.APIKey, error) {
+	var key *models.APIKey
+	err := r.read(func(s *store) error {
+		matched := rows(s.apiKeys, match)
+		if len(matched) == 0 {
+			return fmt.Errorf("%w: API key %s", repository.ErrNotFound, name)
+		}
+		key = copyAPIKey(matched[0])
+		return nil
+	})
+	return key, err
+}
+
+// List retrieves API keys matching the filter, newest first
+func (r *apiKeyRepository) List(ctx context.Context, filter repository.APIKeyFilter) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	err := r.read(func(s *store) error {
+		matched := rows(s.apiKeys, func(key *models.APIKey) bool { return matchAPIKey(key, filter) })
+		slices.SortStableFunc(matched, func(a, b *models.APIKey) int {
+			return compareTimes(b.CreatedAt, a.CreatedAt)
+		})
+		for _, row := range paginate(matched, filter.Filter) {
+			keys = append(keys, copyAPIKey(row))
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// Count returns the total number of API keys matching the filter
+func (r *apiKeyRepository) Count(ctx context.Context, filter repository.APIKeyFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		count = int64(len(rows(s.apiKeys, func(key *models.APIKey) bool { return matchAPIKey(key, filter) })))
+		return nil
+	})
+	return count, err
+}
+
+// Revoke marks an API key as revoked
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return r.write(func(s *store) error {
+		row, ok := s.apiKeys[id]
+		if !ok || row.RevokedAt != nil {
+			return fmt.Errorf("%w: active API key %s", repository.ErrNotFound, id)
+		}
+		changed := copyAPIKey(row)
+		changed.RevokedAt = roundTimePtr(&at)
+		put(s, s.apiKeys, id, changed)
+		return nil
+	})
+}
+
+// TouchLastUsed records that a key was used at the given time
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	return r.write(func(s *store) error {
+		row, ok := s.apiKeys[id]
+		if !ok || (row.LastUsedAt != nil && !row.LastUsedAt.Before(at)) {
+			return nil
+		}
+		changed := copyAPIKey(row)
+		changed.LastUsedAt = roundTimePtr(&at)
+		put(s, s.apiKeys, id, changed)
+		return nil
+	})
+}
+
+// matchAPIKey returns true if an API key matches every condition of the
+// filter
+func matchAPIKey(key *models.APIKey, filter repository.APIKeyFilter) bool {
+	if filter.Subject != "" && key.Subject != filter.Subject {
+		return false
+	}
+	if !filter.IncludeRevoked && key.RevokedAt != nil {
+		return false
+	}
+	if filter.WithinGroups != nil {
+		if len(key.DeviceGroups) == 0 {
+			return false
+		}
+		for _, group := range key.DeviceGroups {
+			if !slices.Contains(filter.WithinGroups, group) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// copyStrings copies an array column value as it is read back, with an
+// empty array read as nil
+func copyStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return slices.Clone(values)
+}
+
+func copyAPIKey(key *models.APIKey) *models.APIKey {
+	copied := *key
+	copied.Roles = copyStrings(key.Roles)
+	copied.DeviceGroups = copyStrings(key.DeviceGroups)
+	copied.DeviceID = copyPtr(key.DeviceID)
+	copied.ExpiresAt = copyPtr(key.ExpiresAt)
+	copied.RevokedAt = copyPtr(key.RevokedAt)
+	copied.LastUsedAt = copyPtr(key.LastUsedAt)
+	return &copied
+}
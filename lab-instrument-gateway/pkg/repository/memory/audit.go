@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// auditRepository implements AuditRepository in memory
+type auditRepository struct {
+	*tables
+}
+
+// Append chains an entry to the last one and stores it
+func (r *auditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	entry.SetDefaults()
+
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("audit entry validation failed: %w", err)
+	}
+
+	if err := checkUUID(entry.ID); err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	before, err := toAuditValue(entry.Before)
+	if err != nil {
+		return fmt.Errorf("failed to marshal before value: %w", err)
+	}
+	after, err := toAuditValue(entry.After)
+	if err != nil {
+		return fmt.Errorf("failed to marshal after value: %w", err)
+	}
+
+	// The write lock serializes appends, as the advisory lock does in the
+	// database implementation
+	return r.write(func(s *store) error {
+		var lastSequence int64
+		var lastHash string
+		if n := len(s.audit); n > 0 {
+			lastSequence = s.audit[n-1].Sequence
+			lastHash = s.audit[n-1].Hash
+		}
+		for _, existing := range s.audit {
+			if existing.ID == entry.ID {
+				return fmt.Errorf("failed to append audit entry: audit entry %s already exists", entry.ID)
+			}
+		}
+
+		entry.Sequence = lastSequence + 1
+		entry.PreviousHash = lastHash
+		entry.Hash, err = entry.ComputeHash()
+		if err != nil {
+			return fmt.Errorf("failed to hash audit entry: %w", err)
+		}
+
+		row := *entry
+		row.Before = before
+		row.After = after
+		s.appendAudit(&row)
+		return nil
+	})
+}
+
+// List retrieves audit entries with filtering and pagination, newest first
+func (r *auditRepository) List(ctx context.Context, filter repository.AuditFilter) ([]*models.AuditEntry, error) {
+	var entries []*models.AuditEntry
+	err := r.read(func(s *store) error {
+		var matched []*models.AuditEntry
+		for _, row := range slices.Backward(s.audit) {
+			if matchAuditEntry(row, filter) {
+				matched = append(matched, row)
+			}
+		}
+		for _, row := range paginate(matched, filter.Filter) {
+			entries = append(entries, copyAuditEntry(row))
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Count returns the number of audit entries matching the filter
+func (r *auditRepository) Count(ctx context.Context, filter repository.AuditFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		for _, row := range s.audit {
+			if matchAuditEntry(row, filter) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// ListAfter lists entries following a sequence number in chain order
+func (r *auditRepository) ListAfter(ctx context.Context, sequence int64, limit int) ([]*models.AuditEntry, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("failed to list audit entries: LIMIT must not be negative")
+	}
+
+	var entries []*models.AuditEntry
+	err := r.read(func(s *store) error {
+		for _, row := range s.audit {
+			if len(entries) == limit {
+				break
+			}
+			if row.Sequence > sequence {
+				entries = append(entries, copyAuditEntry(row))
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// matchAuditEntry returns true if an entry matches every condition of the
+// filter
+func matchAuditEntry(entry *models.AuditEntry, filter repository.AuditFilter) bool {
+	return (filter.Actor == "" || entry.Actor == filter.Actor) &&
+		(filter.Action == "" || entry.Action == filter.Action) &&
+		(filter.ResourceType == "" || entry.ResourceType == filter.ResourceType) &&
+		(filter.ResourceID == "" || entry.ResourceID == filter.ResourceID) &&
+		inRange(entry.OccurredAt, filter.TimeRangeFilter)
+}
+
+// toAuditValue converts a before or after value to the form read back from
+// the database, where an empty value is stored as NULL
+func toAuditValue(value map[string]interface{}) (map[string]interface{}, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return toJSON(value)
+}
+
+func copyAuditEntry(entry *models.AuditEntry) *models.AuditEntry {
+	copied := *entry
+	copied.Before = copyJSON(entry.Before)
+	copied.After = copyJSON(entry.After)
+	return &copied
+}
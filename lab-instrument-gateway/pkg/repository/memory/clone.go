@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// roundTime rounds a time to the microsecond precision of the database
+func roundTime(t time.Time) time.Time {
+	return t.Round(time.Microsecond)
+}
+
+func roundTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	rounded := roundTime(*t)
+	return &rounded
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// checkUUID checks that an ID fits a UUID column
+func checkUUID(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return fmt.Errorf("invalid UUID %q", id)
+	}
+	return nil
+}
+
+// equalPtr returns true if an optional value is set and equal to v
+func equalPtr[T comparable](p *T, v T) bool {
+	return p != nil && *p == v
+}
+
+// toJSON converts a value stored in a JSON column to the form read back
+// from it, with numbers as float64, failing as the insert would if it
+// cannot be marshaled
+func toJSON(m map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return document, nil
+}
+
+// copyJSON deep-copies a document produced by toJSON
+func copyJSON(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	document := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		document[key] = copyJSONValue(value)
+	}
+	return document
+}
+
+func copyJSONValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return copyJSON(value)
+	case []interface{}:
+		values := make([]interface{}, len(value))
+		for i, element := range value {
+			values[i] = copyJSONValue(element)
+		}
+		return values
+	}
+	return value
+}
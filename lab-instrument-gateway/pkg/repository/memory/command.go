@@ -0,0 +1,446 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// commandColumns are the columns commands can be sorted by
+var commandColumns = map[string]column[*models.Command]{
+	"id":              func(a, b *models.Command) int { return strings.Compare(a.ID, b.ID) },
+	"command_id":      func(a, b *models.Command) int { return strings.Compare(a.CommandID, b.CommandID) },
+	"device_id":       func(a, b *models.Command) int { return strings.Compare(a.DeviceID, b.DeviceID) },
+	"type":            func(a, b *models.Command) int { return strings.Compare(a.Type, b.Type) },
+	"status":          func(a, b *models.Command) int { return compareCommandStatuses(a.Status, b.Status) },
+	"priority":        func(a, b *models.Command) int { return cmp.Compare(a.Priority, b.Priority) },
+	"timeout_seconds": func(a, b *models.Command) int { return cmp.Compare(a.TimeoutSeconds, b.TimeoutSeconds) },
+	"executed_at":     func(a, b *models.Command) int { return compareNullTimes(a.ExecutedAt, b.ExecutedAt) },
+	"expires_at":      func(a, b *models.Command) int { return compareNullTimes(a.ExpiresAt, b.ExpiresAt) },
+	"created_at":      func(a, b *models.Command) int { return compareTimes(a.CreatedAt, b.CreatedAt) },
+	"updated_at":      func(a, b *models.Command) int { return compareTimes(a.UpdatedAt, b.UpdatedAt) },
+}
+
+// compareCommandStatuses orders command statuses as the command_status enum
+var compareCommandStatuses = enumOrder(
+	models.CommandStatusUnknown,
+	models.CommandStatusPending,
+	models.CommandStatusExecuting,
+	models.CommandStatusCompleted,
+	models.CommandStatusFailed,
+	models.CommandStatusTimeout,
+	models.CommandStatusCancelled,
+)
+
+// commandRepository implements CommandRepository in memory
+type commandRepository struct {
+	*tables
+}
+
+// Create creates a new command, together with the signatures it carries
+func (r *commandRepository) Create(ctx context.Context, command *models.Command) error {
+	if err := command.Validate(); err != nil {
+		return fmt.Errorf("command validation failed: %w", err)
+	}
+
+	command.SetDefaults()
+
+	for _, signature := range command.Signatures {
+		signature.CommandID = command.ID
+		signature.SetDefaults()
+		if err := signature.Validate(); err != nil {
+			return fmt.Errorf("command signature validation failed: %w", err)
+		}
+	}
+
+	if err := checkUUID(command.ID); err != nil {
+		return fmt.Errorf("failed to create command: %w", err)
+	}
+	parameters, err := toJSON(command.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameters: %w", err)
+	}
+
+	// Only the inserted columns are stored; the others take their defaults
+	row := &models.Command{
+		ID:             command.ID,
+		DeviceID:       command.DeviceID,
+		CommandID:      command.CommandID,
+		Type:           command.Type,
+		Parameters:     parameters,
+		Status:         command.Status,
+		Priority:       command.Priority,
+		TimeoutSeconds: command.TimeoutSeconds,
+		Result:         map[string]interface{}{},
+		ExpiresAt:      roundTimePtr(command.ExpiresAt),
+		CreatedAt:      roundTime(command.CreatedAt),
+		UpdatedAt:      roundTime(command.UpdatedAt),
+	}
+
+	signatures := make([]*models.CommandSignature, len(command.Signatures))
+	for i, signature := range command.Signatures {
+		if err := checkUUID(signature.ID); err != nil {
+			return fmt.Errorf("failed to store command signature: %w", err)
+		}
+		signatures[i] = copySignature(signature)
+		signatures[i].SignedAt = roundTime(signature.SignedAt)
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.commands[row.ID]; ok {
+			return fmt.Errorf("failed to create command: command %s already exists", row.ID)
+		}
+		if _, ok := s.devices[row.DeviceID]; !ok {
+			return fmt.Errorf("failed to create command: device %s does not exist", row.DeviceID)
+		}
+		for _, existing := range s.commands {
+			if existing.CommandID == row.CommandID {
+				return fmt.Errorf("failed to create command: command ID %s already exists", row.CommandID)
+			}
+		}
+
+		signers := make(map[string]bool)
+		for _, signature := range signatures {
+			if _, ok := s.signatures[signature.ID]; ok || signers[signature.Signer] {
+				return fmt.Errorf("failed to store command signature: %s already signed command %s", signature.Signer, row.CommandID)
+			}
+			if signature.TokenID != "" && tokenUsed(s, signature.Signer, signature.TokenID) {
+				return fmt.Errorf("failed to store command signature: %s already signed with token %s", signature.Signer, signature.TokenID)
+			}
+			signers[signature.Signer] = true
+		}
+
+		put(s, s.commands, row.ID, row)
+		for _, signature := range signatures {
+			put(s, s.signatures, signature.ID, signature)
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a command by ID
+func (r *commandRepository) GetByID(ctx context.Context, id string) (*models.Command, error) {
+	var command *models.Command
+	err := r.read(func(s *store) error {
+		row, ok := s.commands[id]
+		if !ok {
+			return fmt.Errorf("%w: command %s", repository.ErrNotFound, id)
+		}
+		command = copyCommand(row)
+		return nil
+	})
+	return command, err
+}
+
+// GetByCommandID retrieves a command by command ID
+func (r *commandRepository) GetByCommandID(ctx context.Context, commandID string) (*models.Command, error) {
+	var command *models.Command
+	err := r.read(func(s *store) error {
+		for _, row := range s.commands {
+			if row.CommandID == commandID {
+				command = copyCommand(row)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: command %s", repository.ErrNotFound, commandID)
+	})
+	return command, err
+}
+
+// Update updates an existing command
+func (r *commandRepository) Update(ctx context.Context, command *models.Command) error {
+	if err := command.Validate(); err != nil {
+		return fmt.Errorf("command validation failed: %w", err)
+	}
+
+	command.UpdatedAt = time.Now()
+
+	parameters, err := toJSON(command.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameters: %w", err)
+	}
+	result, err := toJSON(command.Result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return r.change(func(row *models.Command) bool { return row.ID == command.ID }, command.ID, func(changed *models.Command) {
+		changed.Type = command.Type
+		changed.Parameters = parameters
+		changed.Status = command.Status
+		changed.Priority = command.Priority
+		changed.TimeoutSeconds = command.TimeoutSeconds
+		changed.Result = result
+		changed.ErrorMessage = copyPtr(command.ErrorMessage)
+		changed.ExecutedAt = roundTimePtr(command.ExecutedAt)
+		changed.ExpiresAt = roundTimePtr(command.ExpiresAt)
+	})
+}
+
+// change applies a change to a copy of the command matching a condition
+// and stores it
+func (r *commandRepository) change(match func(row *models.Command) bool, id string, apply func(changed *models.Command)) error {
+	return r.write(func(s *store) error {
+		for _, row := range s.commands {
+			if match(row) {
+				changed := copyCommand(row)
+				apply(changed)
+				changed.UpdatedAt = roundTime(time.Now())
+				put(s, s.commands, row.ID, changed)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: command %s", repository.ErrNotFound, id)
+	})
+}
+
+// Delete removes a command
+func (r *commandRepository) Delete(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.commands[id]; !ok {
+			return fmt.Errorf("%w: command %s", repository.ErrNotFound, id)
+		}
+		s.deleteCommand(id)
+		return nil
+	})
+}
+
+// List retrieves commands with filtering and pagination
+func (r *commandRepository) List(ctx context.Context, filter repository.CommandFilter) ([]*models.Command, error) {
+	var commands []*models.Command
+	err := r.read(func(s *store) error {
+		matched := rows(s.commands, func(command *models.Command) bool { return matchCommand(command, filter) })
+		if err := sortRows(matched, commandColumns, filter.Filter, "created_at"); err != nil {
+			return err
+		}
+		for _, row := range paginate(matched, filter.Filter) {
+			commands = append(commands, copyCommand(row))
+		}
+		return nil
+	})
+	return commands, err
+}
+
+// Count returns the total number of commands matching the filter
+func (r *commandRepository) Count(ctx context.Context, filter repository.CommandFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		count = int64(len(rows(s.commands, func(command *models.Command) bool { return matchCommand(command, filter) })))
+		return nil
+	})
+	return count, err
+}
+
+// GetPendingCommands retrieves the unexpired pending commands for a device,
+// highest priority first
+func (r *commandRepository) GetPendingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
+	now := time.Now()
+	return r.query(func(command *models.Command) bool {
+		return command.DeviceID == deviceID && command.Status == models.CommandStatusPending &&
+			(command.ExpiresAt == nil || command.ExpiresAt.After(now))
+	}, func(a, b *models.Command) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	})
+}
+
+// GetExecutingCommands retrieves the executing commands for a device
+func (r *commandRepository) GetExecutingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
+	return r.query(func(command *models.Command) bool {
+		return command.DeviceID == deviceID && command.Status == models.CommandStatusExecuting
+	}, commandColumns["created_at"])
+}
+
+// UpdateStatus updates the status of a command
+func (r *commandRepository) UpdateStatus(ctx context.Context, commandID string, status models.CommandStatus) error {
+	return r.change(func(row *models.Command) bool { return row.CommandID == commandID }, commandID, func(changed *models.Command) {
+		changed.Status = status
+	})
+}
+
+// Claim marks a pending command executing, returning false when it is no
+// longer pending
+func (r *commandRepository) Claim(ctx context.Context, command *models.Command) (bool, error) {
+	claimed := *command
+	claimed.StartExecution()
+
+	err := r.change(func(row *models.Command) bool {
+		return row.ID == command.ID && row.Status == models.CommandStatusPending
+	}, command.ID, func(changed *models.Command) {
+		changed.Status = claimed.Status
+		changed.ExecutedAt = roundTimePtr(claimed.ExecutedAt)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	*command = claimed
+	return true, nil
+}
+
+// ListSignatures retrieves the signatures of a command, in signing order
+func (r *commandRepository) ListSignatures(ctx context.Context, id string) ([]*models.CommandSignature, error) {
+	var signatures []*models.CommandSignature
+	err := r.read(func(s *store) error {
+		matched := rows(s.signatures, func(signature *models.CommandSignature) bool { return signature.CommandID == id })
+		slices.SortStableFunc(matched, func(a, b *models.CommandSignature) int {
+			if c := compareTimes(a.SignedAt, b.SignedAt); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ID, b.ID)
+		})
+		for _, row := range matched {
+			signatures = append(signatures, copySignature(row))
+		}
+		return nil
+	})
+	return signatures, err
+}
+
+// SignatureTokenUsed reports whether a signer has already signed with the
+// credential of the given token ID
+func (r *commandRepository) SignatureTokenUsed(ctx context.Context, signer, tokenID string) (bool, error) {
+	var used bool
+	err := r.read(func(s *store) error {
+		used = tokenUsed(s, signer, tokenID)
+		return nil
+	})
+	return used, err
+}
+
+// tokenUsed reports whether a stored signature of the signer carries the
+// token ID
+func tokenUsed(s *store, signer, tokenID string) bool {
+	for _, signature := range s.signatures {
+		if signature.Signer == signer && signature.TokenID == tokenID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetExpiredCommands retrieves pending or executing commands past their
+// expiry, earliest expiry first
+func (r *commandRepository) GetExpiredCommands(ctx context.Context) ([]*models.Command, error) {
+	now := time.Now()
+	return r.query(func(command *models.Command) bool {
+		return isExpired(command, now)
+	}, commandColumns["expires_at"])
+}
+
+// MarkExpiredAsTimeout marks expired commands as timed out
+func (r *commandRepository) MarkExpiredAsTimeout(ctx context.Context) (int64, error) {
+	var marked int64
+	err := r.write(func(s *store) error {
+		now := time.Now()
+		for id, row := range s.commands {
+			if !isExpired(row, now) {
+				continue
+			}
+
+			changed := copyCommand(row)
+			changed.Status = models.CommandStatusTimeout
+			message := "Command expired"
+			changed.ErrorMessage = &message
+			changed.UpdatedAt = roundTime(now)
+			put(s, s.commands, id, changed)
+			marked++
+		}
+		return nil
+	})
+	return marked, err
+}
+
+// isExpired returns true if a pending or executing command expired
+func isExpired(command *models.Command, now time.Time) bool {
+	return command.ExpiresAt != nil && !command.ExpiresAt.After(now) &&
+		(command.Status == models.CommandStatusPending || command.Status == models.CommandStatusExecuting)
+}
+
+// DeleteCompletedOlderThan removes finished commands created before the
+// threshold
+func (r *commandRepository) DeleteCompletedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		for id, row := range s.commands {
+			switch row.Status {
+			case models.CommandStatusCompleted, models.CommandStatusFailed, models.CommandStatusTimeout:
+				if row.CreatedAt.Before(threshold) {
+					s.deleteCommand(id)
+					deleted++
+				}
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// GetCommandStats retrieves the number of commands of a device in each
+// status, created within the time range
+func (r *commandRepository) GetCommandStats(ctx context.Context, deviceID string, timeRange repository.TimeRangeFilter) (map[models.CommandStatus]int64, error) {
+	stats := make(map[models.CommandStatus]int64)
+	err := r.read(func(s *store) error {
+		for _, row := range s.commands {
+			if row.DeviceID == deviceID && inRange(row.CreatedAt, timeRange) {
+				stats[row.Status]++
+			}
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// query lists the commands matching a condition in the given order
+func (r *commandRepository) query(match func(command *models.Command) bool, compare func(a, b *models.Command) int) ([]*models.Command, error) {
+	var commands []*models.Command
+	err := r.read(func(s *store) error {
+		matched := rows(s.commands, match)
+		slices.SortStableFunc(matched, compare)
+		for _, row := range matched {
+			commands = append(commands, copyCommand(row))
+		}
+		return nil
+	})
+	return commands, err
+}
+
+// matchCommand returns true if a command matches every condition of the
+// filter
+func matchCommand(command *models.Command, filter repository.CommandFilter) bool {
+	return matchAny(filter.DeviceIDs, command.DeviceID) &&
+		matchAny(filter.Types, command.Type) &&
+		matchAny(filter.Statuses, command.Status) &&
+		matchAny(filter.Priorities, command.Priority) &&
+		inRange(command.CreatedAt, filter.TimeRangeFilter)
+}
+
+func copyCommand(command *models.Command) *models.Command {
+	copied := *command
+	copied.Parameters = copyJSON(command.Parameters)
+	copied.Result = copyJSON(command.Result)
+	copied.ErrorMessage = copyPtr(command.ErrorMessage)
+	copied.ExecutedAt = copyPtr(command.ExecutedAt)
+	copied.CompletedAt = copyPtr(command.CompletedAt)
+	copied.ExpiresAt = copyPtr(command.ExpiresAt)
+	copied.ExecutionTimeMs = copyPtr(command.ExecutionTimeMs)
+	copied.Signatures = nil
+	return &copied
+}
+
+func copySignature(signature *models.CommandSignature) *models.CommandSignature {
+	copied := *signature
+	return &copied
+}
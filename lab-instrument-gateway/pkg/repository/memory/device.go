@@ -0,0 +1,392 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// deviceColumns are the columns devices can be sorted by
+var deviceColumns = map[string]column[*models.Device]{
+	"id":            func(a, b *models.Device) int { return strings.Compare(a.ID, b.ID) },
+	"name":          func(a, b *models.Device) int { return strings.Compare(a.Name, b.Name) },
+	"type":          func(a, b *models.Device) int { return strings.Compare(a.Type, b.Type) },
+	"version":       func(a, b *models.Device) int { return strings.Compare(a.Version, b.Version) },
+	"status":        func(a, b *models.Device) int { return compareDeviceStatuses(a.Status, b.Status) },
+	"last_seen":     func(a, b *models.Device) int { return compareNullTimes(a.LastSeen, b.LastSeen) },
+	"registered_at": func(a, b *models.Device) int { return compareTimes(a.RegisteredAt, b.RegisteredAt) },
+	"created_at":    func(a, b *models.Device) int { return compareTimes(a.CreatedAt, b.CreatedAt) },
+	"updated_at":    func(a, b *models.Device) int { return compareTimes(a.UpdatedAt, b.UpdatedAt) },
+}
+
+// compareDeviceStatuses orders device statuses as the device_status enum
+var compareDeviceStatuses = enumOrder(
+	models.DeviceStatusUnknown,
+	models.DeviceStatusOnline,
+	models.DeviceStatusOffline,
+	models.DeviceStatusError,
+	models.DeviceStatusMaintenance,
+	models.DeviceStatusConnecting,
+)
+
+// deviceRepository implements DeviceRepository in memory
+type deviceRepository struct {
+	*tables
+}
+
+// Create creates a new device
+func (r *deviceRepository) Create(ctx context.Context, device *models.Device) error {
+	if err := device.Validate(); err != nil {
+		return fmt.Errorf("device validation failed: %w", err)
+	}
+
+	now := time.Now()
+	if device.RegisteredAt.IsZero() {
+		device.RegisteredAt = now
+	}
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = now
+	}
+	device.UpdatedAt = now
+
+	row, err := newDeviceRow(device)
+	if err != nil {
+		return err
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.devices[row.ID]; ok {
+			return fmt.Errorf("failed to create device: device %s already exists", row.ID)
+		}
+		put(s, s.devices, row.ID, row)
+		return nil
+	})
+}
+
+// GetByID retrieves a device by ID
+func (r *deviceRepository) GetByID(ctx context.Context, id string) (*models.Device, error) {
+	var device *models.Device
+	err := r.read(func(s *store) error {
+		row, ok := s.devices[id]
+		if !ok {
+			return fmt.Errorf("%w: device %s", repository.ErrNotFound, id)
+		}
+		device = copyDevice(row)
+		return nil
+	})
+	return device, err
+}
+
+// Update updates an existing device
+func (r *deviceRepository) Update(ctx context.Context, device *models.Device) error {
+	if err := device.Validate(); err != nil {
+		return fmt.Errorf("device validation failed: %w", err)
+	}
+
+	device.UpdatedAt = time.Now()
+
+	return r.update(device)
+}
+
+// update replaces the changeable columns of a device
+func (r *deviceRepository) update(device *models.Device) error {
+	changed, err := newDeviceRow(device)
+	if err != nil {
+		return err
+	}
+
+	return r.write(func(s *store) error {
+		row, ok := s.devices[device.ID]
+		if !ok {
+			return fmt.Errorf("%w: device %s", repository.ErrNotFound, device.ID)
+		}
+		changed.RegisteredAt = row.RegisteredAt
+		changed.CreatedAt = row.CreatedAt
+		put(s, s.devices, device.ID, changed)
+		return nil
+	})
+}
+
+// Delete removes a device and everything recorded for it
+func (r *deviceRepository) Delete(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.devices[id]; !ok {
+			return fmt.Errorf("%w: device %s", repository.ErrNotFound, id)
+		}
+		s.deleteDevice(id)
+		return nil
+	})
+}
+
+// CreateBulk creates multiple devices at once. Invalid devices are skipped
+// and reported; any other failure fails the whole insert, as it aborts the
+// transaction of the database implementation.
+func (r *deviceRepository) CreateBulk(ctx context.Context, devices []*models.Device) (*repository.BulkResult, error) {
+	result := &repository.BulkResult{}
+	if len(devices) == 0 {
+		return result, nil
+	}
+
+	now := time.Now()
+	var valid []*models.Device
+	for _, device := range devices {
+		if err := device.Validate(); err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("device %s validation failed: %w", device.ID, err))
+			continue
+		}
+
+		if device.RegisteredAt.IsZero() {
+			device.RegisteredAt = now
+		}
+		if device.CreatedAt.IsZero() {
+			device.CreatedAt = now
+		}
+		device.UpdatedAt = now
+
+		row, err := newDeviceRow(device)
+		if err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("device %s metadata marshal failed: %w", device.ID, err))
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	err := r.write(func(s *store) error {
+		inserted := make(map[string]bool, len(valid))
+		for _, row := range valid {
+			if _, ok := s.devices[row.ID]; ok || inserted[row.ID] {
+				return fmt.Errorf("failed to commit transaction: device %s already exists", row.ID)
+			}
+			inserted[row.ID] = true
+		}
+
+		for _, row := range valid {
+			put(s, s.devices, row.ID, row)
+		}
+		result.SuccessCount = len(valid)
+		return nil
+	})
+
+	return result, err
+}
+
+// UpdateBulk updates multiple devices, reporting the ones that failed
+func (r *deviceRepository) UpdateBulk(ctx context.Context, devices []*models.Device) (*repository.BulkResult, error) {
+	result := &repository.BulkResult{}
+	for _, device := range devices {
+		if err := r.Update(ctx, device); err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("device %s: %w", device.ID, err))
+			continue
+		}
+		result.SuccessCount++
+	}
+	return result, nil
+}
+
+// List retrieves devices with filtering and pagination
+func (r *deviceRepository) List(ctx context.Context, filter repository.DeviceFilter) ([]*models.Device, error) {
+	var devices []*models.Device
+	err := r.read(func(s *store) error {
+		matched := rows(s.devices, func(device *models.Device) bool { return matchDevice(device, filter) })
+		if err := sortRows(matched, deviceColumns, filter.Filter, "created_at"); err != nil {
+			return err
+		}
+		for _, row := range paginate(matched, filter.Filter) {
+			devices = append(devices, copyDevice(row))
+		}
+		return nil
+	})
+	return devices, err
+}
+
+// Count returns the total number of devices matching the filter
+func (r *deviceRepository) Count(ctx context.Context, filter repository.DeviceFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		count = int64(len(rows(s.devices, func(device *models.Device) bool { return matchDevice(device, filter) })))
+		return nil
+	})
+	return count, err
+}
+
+// UpdateStatus updates device status
+func (r *deviceRepository) UpdateStatus(ctx context.Context, deviceID string, status models.DeviceStatus) error {
+	return r.change(deviceID, func(device *models.Device) {
+		device.Status = status
+	})
+}
+
+// UpdateLastSeen updates the last seen timestamp
+func (r *deviceRepository) UpdateLastSeen(ctx context.Context, deviceID string, timestamp time.Time) error {
+	return r.change(deviceID, func(device *models.Device) {
+		device.LastSeen = roundTimePtr(&timestamp)
+	})
+}
+
+// change applies a change to a copy of a device and stores it
+func (r *deviceRepository) change(deviceID string, apply func(device *models.Device)) error {
+	return r.write(func(s *store) error {
+		row, ok := s.devices[deviceID]
+		if !ok {
+			return fmt.Errorf("%w: device %s", repository.ErrNotFound, deviceID)
+		}
+		changed := copyDevice(row)
+		apply(changed)
+		changed.UpdatedAt = roundTime(time.Now())
+		put(s, s.devices, deviceID, changed)
+		return nil
+	})
+}
+
+// GetStatusCounts retrieves the number of devices in each status
+func (r *deviceRepository) GetStatusCounts(ctx context.Context) (map[models.DeviceStatus]int64, error) {
+	counts := make(map[models.DeviceStatus]int64)
+	err := r.read(func(s *store) error {
+		for _, device := range s.devices {
+			counts[device.Status]++
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// SearchByMetadata searches devices by metadata fields
+func (r *deviceRepository) SearchByMetadata(ctx context.Context, metadata map[string]interface{}) ([]*models.Device, error) {
+	if len(metadata) == 0 {
+		return []*models.Device{}, nil
+	}
+
+	return r.query(func(device *models.Device) bool {
+		return matchMetadata(device.Metadata, metadata)
+	}, byCreatedAtDesc)
+}
+
+// GetByCapability retrieves devices that have a specific capability
+func (r *deviceRepository) GetByCapability(ctx context.Context, capability string) ([]*models.Device, error) {
+	return r.query(func(device *models.Device) bool {
+		return slices.Contains(device.Capabilities, capability)
+	}, byCreatedAtDesc)
+}
+
+// GetOnlineDevices retrieves all online devices, most recently seen first
+func (r *deviceRepository) GetOnlineDevices(ctx context.Context) ([]*models.Device, error) {
+	return r.query(func(device *models.Device) bool {
+		return device.Status == models.DeviceStatusOnline
+	}, func(a, b *models.Device) int {
+		return compareNullTimes(b.LastSeen, a.LastSeen)
+	})
+}
+
+// GetOfflineDevices retrieves devices not seen within the threshold, except
+// those in maintenance, least recently seen first
+func (r *deviceRepository) GetOfflineDevices(ctx context.Context, threshold time.Duration) ([]*models.Device, error) {
+	cutoff := time.Now().Add(-threshold)
+	return r.query(func(device *models.Device) bool {
+		return (device.LastSeen == nil || device.LastSeen.Before(cutoff)) && device.Status != models.DeviceStatusMaintenance
+	}, func(a, b *models.Device) int {
+		// Ascending, but with the devices never seen first
+		if a.LastSeen == nil || b.LastSeen == nil {
+			return -compareNullTimes(a.LastSeen, b.LastSeen)
+		}
+		return compareTimes(*a.LastSeen, *b.LastSeen)
+	})
+}
+
+// query lists the devices matching a condition in the given order
+func (r *deviceRepository) query(match func(device *models.Device) bool, compare func(a, b *models.Device) int) ([]*models.Device, error) {
+	var devices []*models.Device
+	err := r.read(func(s *store) error {
+		matched := rows(s.devices, match)
+		slices.SortStableFunc(matched, compare)
+		for _, row := range matched {
+			devices = append(devices, copyDevice(row))
+		}
+		return nil
+	})
+	return devices, err
+}
+
+func byCreatedAtDesc(a, b *models.Device) int {
+	return compareTimes(b.CreatedAt, a.CreatedAt)
+}
+
+// matchDevice returns true if a device matches every condition of the filter
+func matchDevice(device *models.Device, filter repository.DeviceFilter) bool {
+	if !matchAny(filter.DeviceIDs, device.ID) || !matchAny(filter.Types, device.Type) || !matchAny(filter.Statuses, device.Status) {
+		return false
+	}
+
+	for _, capability := range filter.Capabilities {
+		if !slices.Contains(device.Capabilities, capability) {
+			return false
+		}
+	}
+
+	if filter.LastSeenAfter != nil && (device.LastSeen == nil || !device.LastSeen.After(*filter.LastSeenAfter)) {
+		return false
+	}
+	if filter.LastSeenBefore != nil && (device.LastSeen == nil || !device.LastSeen.Before(*filter.LastSeenBefore)) {
+		return false
+	}
+
+	if !matchMetadata(device.Metadata, filter.MetadataFilters) {
+		return false
+	}
+
+	if len(filter.Groups) > 0 {
+		group, ok := jsonText(device.Metadata[models.DeviceGroupMetadataKey])
+		if !ok || !slices.Contains(filter.Groups, group) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchMetadata returns true if every metadata value, compared as text,
+// equals the wanted one
+func matchMetadata(metadata map[string]interface{}, wanted map[string]interface{}) bool {
+	for key, value := range wanted {
+		text, ok := jsonText(metadata[key])
+		if !ok || text != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// newDeviceRow converts a device to the row stored for it
+func newDeviceRow(device *models.Device) (*models.Device, error) {
+	metadata, err := toJSON(device.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	row := copyDevice(device)
+	row.Metadata = metadata
+	row.LastSeen = roundTimePtr(device.LastSeen)
+	row.RegisteredAt = roundTime(device.RegisteredAt)
+	row.CreatedAt = roundTime(device.CreatedAt)
+	row.UpdatedAt = roundTime(device.UpdatedAt)
+	return row, nil
+}
+
+func copyDevice(device *models.Device) *models.Device {
+	copied := *device
+	copied.Metadata = copyJSON(device.Metadata)
+	if device.Capabilities != nil {
+		copied.Capabilities = pq.StringArray(slices.Clone([]string(device.Capabilities)))
+	}
+	copied.LastSeen = copyPtr(device.LastSeen)
+	return &copied
+}
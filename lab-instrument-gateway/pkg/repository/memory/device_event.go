@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// deviceEventColumns are the columns device events can be sorted by
+var deviceEventColumns = map[string]column[*models.DeviceEvent]{
+	"id":          func(a, b *models.DeviceEvent) int { return strings.Compare(a.ID, b.ID) },
+	"device_id":   func(a, b *models.DeviceEvent) int { return strings.Compare(a.DeviceID, b.DeviceID) },
+	"type":        func(a, b *models.DeviceEvent) int { return strings.Compare(string(a.Type), string(b.Type)) },
+	"session_id":  func(a, b *models.DeviceEvent) int { return compareNullable(a.SessionID, b.SessionID, strings.Compare) },
+	"message":     func(a, b *models.DeviceEvent) int { return strings.Compare(a.Message, b.Message) },
+	"occurred_at": func(a, b *models.DeviceEvent) int { return compareTimes(a.OccurredAt, b.OccurredAt) },
+}
+
+// deviceEventRepository implements DeviceEventRepository in memory
+type deviceEventRepository struct {
+	*tables
+}
+
+// Create appends an event to the device event log
+func (r *deviceEventRepository) Create(ctx context.Context, event *models.DeviceEvent) error {
+	event.SetDefaults()
+
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("device event validation failed: %w", err)
+	}
+
+	if err := checkUUID(event.ID); err != nil {
+		return fmt.Errorf("failed to create device event: %w", err)
+	}
+	metadata, err := toJSON(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	row := &models.DeviceEvent{
+		ID:         event.ID,
+		DeviceID:   event.DeviceID,
+		Type:       event.Type,
+		SessionID:  copyPtr(event.SessionID),
+		FromStatus: copyPtr(event.FromStatus),
+		ToStatus:   copyPtr(event.ToStatus),
+		Message:    event.Message,
+		Metadata:   metadata,
+		OccurredAt: roundTime(event.OccurredAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.events[row.ID]; ok {
+			return fmt.Errorf("failed to create device event: device event %s already exists", row.ID)
+		}
+		if _, ok := s.devices[row.DeviceID]; !ok {
+			return fmt.Errorf("failed to create device event: device %s does not exist", row.DeviceID)
+		}
+		put(s, s.events, row.ID, row)
+		return nil
+	})
+}
+
+// List retrieves device events with filtering and pagination
+func (r *deviceEventRepository) List(ctx context.Context, filter repository.DeviceEventFilter) ([]*models.DeviceEvent, error) {
+	var events []*models.DeviceEvent
+	err := r.read(func(s *store) error {
+		matched := rows(s.events, func(event *models.DeviceEvent) bool { return matchDeviceEvent(event, filter) })
+		if err := sortRows(matched, deviceEventColumns, filter.Filter, "occurred_at"); err != nil {
+			return err
+		}
+		for _, row := range paginate(matched, filter.Filter) {
+			events = append(events, copyDeviceEvent(row))
+		}
+		return nil
+	})
+	return events, err
+}
+
+// Count returns the number of device events matching the filter
+func (r *deviceEventRepository) Count(ctx context.Context, filter repository.DeviceEventFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		count = int64(len(rows(s.events, func(event *models.DeviceEvent) bool { return matchDeviceEvent(event, filter) })))
+		return nil
+	})
+	return count, err
+}
+
+// GetLastBefore retrieves the most recent event of the given type that
+// occurred before the given time
+func (r *deviceEventRepository) GetLastBefore(ctx context.Context, deviceID string, eventType models.DeviceEventType, before time.Time) (*models.DeviceEvent, error) {
+	var event *models.DeviceEvent
+	err := r.read(func(s *store) error {
+		matched := rows(s.events, func(event *models.DeviceEvent) bool {
+			return event.DeviceID == deviceID && event.Type == eventType && event.OccurredAt.Before(before)
+		})
+		if len(matched) == 0 {
+			return fmt.Errorf("%w: %s event for device %s", repository.ErrNotFound, eventType, deviceID)
+		}
+		event = copyDeviceEvent(slices.MaxFunc(matched, deviceEventColumns["occurred_at"]))
+		return nil
+	})
+	return event, err
+}
+
+// DeleteOlderThan removes events that occurred before the threshold
+func (r *deviceEventRepository) DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		deleted = deleteWhere(s, s.events, func(event *models.DeviceEvent) bool { return event.OccurredAt.Before(threshold) })
+		return nil
+	})
+	return deleted, err
+}
+
+// matchDeviceEvent returns true if an event matches every condition of the
+// filter
+func matchDeviceEvent(event *models.DeviceEvent, filter repository.DeviceEventFilter) bool {
+	return (filter.DeviceID == "" || event.DeviceID == filter.DeviceID) &&
+		matchAny(filter.Types, event.Type) &&
+		inRange(event.OccurredAt, filter.TimeRangeFilter)
+}
+
+func copyDeviceEvent(event *models.DeviceEvent) *models.DeviceEvent {
+	copied := *event
+	copied.SessionID = copyPtr(event.SessionID)
+	copied.FromStatus = copyPtr(event.FromStatus)
+	copied.ToStatus = copyPtr(event.ToStatus)
+	copied.Metadata = copyJSON(event.Metadata)
+	return &copied
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// deviceSessionRepository implements DeviceSessionRepository in memory
+type deviceSessionRepository struct {
+	*tables
+}
+
+// Create persists a new device session
+func (r *deviceSessionRepository) Create(ctx context.Context, session *models.DeviceSession) error {
+	if session.DeviceID == "" || session.SessionID == "" {
+		return fmt.Errorf("device session requires device ID and session ID")
+	}
+
+	metadata, err := toJSON(session.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	// The ID is generated on insert, as the database column default does
+	row := &models.DeviceSession{
+		ID:            uuid.New().String(),
+		DeviceID:      session.DeviceID,
+		SessionID:     session.SessionID,
+		StreamID:      copyPtr(session.StreamID),
+		ConnectedAt:   roundTime(session.ConnectedAt),
+		LastHeartbeat: roundTime(session.LastHeartbeat),
+		Metadata:      metadata,
+		IsActive:      session.IsActive,
+	}
+
+	err = r.write(func(s *store) error {
+		if _, ok := s.devices[row.DeviceID]; !ok {
+			return fmt.Errorf("failed to create device session: device %s does not exist", row.DeviceID)
+		}
+		for _, existing := range s.sessions {
+			if existing.SessionID == row.SessionID {
+				return fmt.Errorf("failed to create device session: device session %s already exists", row.SessionID)
+			}
+		}
+		put(s, s.sessions, row.ID, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	session.ID = row.ID
+	return nil
+}
+
+// GetBySessionID retrieves a device session by its session ID
+func (r *deviceSessionRepository) GetBySessionID(ctx context.Context, sessionID string) (*models.DeviceSession, error) {
+	var session *models.DeviceSession
+	err := r.read(func(s *store) error {
+		for _, row := range s.sessions {
+			if row.SessionID == sessionID {
+				session = copyDeviceSession(row)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: device session %s", repository.ErrNotFound, sessionID)
+	})
+	return session, err
+}
+
+// ListByDevice retrieves the sessions of a device that overlap the time
+// range, newest first
+func (r *deviceSessionRepository) ListByDevice(ctx context.Context, deviceID string, timeRange repository.TimeRangeFilter, limit int) ([]*models.DeviceSession, error) {
+	var sessions []*models.DeviceSession
+	err := r.read(func(s *store) error {
+		matched := rows(s.sessions, func(session *models.DeviceSession) bool {
+			if session.DeviceID != deviceID {
+				return false
+			}
+			if timeRange.StartTime != nil && session.DisconnectedAt != nil && session.DisconnectedAt.Before(*timeRange.StartTime) {
+				return false
+			}
+			if timeRange.EndTime != nil && session.ConnectedAt.After(*timeRange.EndTime) {
+				return false
+			}
+			return true
+		})
+		slices.SortStableFunc(matched, func(a, b *models.DeviceSession) int {
+			return compareTimes(b.ConnectedAt, a.ConnectedAt)
+		})
+		if limit > 0 && len(matched) > limit {
+			matched = matched[:limit]
+		}
+		for _, row := range matched {
+			sessions = append(sessions, copyDeviceSession(row))
+		}
+		return nil
+	})
+	return sessions, err
+}
+
+// End marks a session as ended at the given time
+func (r *deviceSessionRepository) End(ctx context.Context, sessionID string, endedAt time.Time, reason string) error {
+	endedAt = roundTime(endedAt)
+	return r.change(sessionID, func(changed *models.DeviceSession) {
+		changed.IsActive = false
+		changed.DisconnectedAt = &endedAt
+		changed.DisconnectReason = &reason
+	})
+}
+
+// Resume reactivates a session whose device came back before reconnecting
+func (r *deviceSessionRepository) Resume(ctx context.Context, sessionID string, at time.Time) error {
+	return r.change(sessionID, func(changed *models.DeviceSession) {
+		changed.IsActive = true
+		changed.DisconnectedAt = nil
+		changed.DisconnectReason = nil
+		changed.LastHeartbeat = roundTime(at)
+	})
+}
+
+// change applies a change to a copy of a session and stores it
+func (r *deviceSessionRepository) change(sessionID string, apply func(changed *models.DeviceSession)) error {
+	return r.write(func(s *store) error {
+		for id, row := range s.sessions {
+			if row.SessionID == sessionID {
+				changed := copyDeviceSession(row)
+				apply(changed)
+				put(s, s.sessions, id, changed)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: device session %s", repository.ErrNotFound, sessionID)
+	})
+}
+
+// DeleteEndedOlderThan removes sessions that ended before the threshold
+func (r *deviceSessionRepository) DeleteEndedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		deleted = deleteWhere(s, s.sessions, func(session *models.DeviceSession) bool {
+			return !session.IsActive && session.DisconnectedAt != nil && session.DisconnectedAt.Before(threshold)
+		})
+		return nil
+	})
+	return deleted, err
+}
+
+func copyDeviceSession(session *models.DeviceSession) *models.DeviceSession {
+	copied := *session
+	copied.StreamID = copyPtr(session.StreamID)
+	copied.Metadata = copyJSON(session.Metadata)
+	copied.DisconnectedAt = copyPtr(session.DisconnectedAt)
+	copied.DisconnectReason = copyPtr(session.DisconnectReason)
+	return &copied
+}
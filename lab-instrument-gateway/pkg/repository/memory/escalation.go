@@ -0,0 +1,344 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// escalationRepository implements EscalationRepository in memory
+type escalationRepository struct {
+	*tables
+}
+
+// CreatePolicy creates a new escalation policy
+func (r *escalationRepository) CreatePolicy(ctx context.Context, policy *models.EscalationPolicy) error {
+	policy.SetDefaults()
+
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("escalation policy validation failed: %w", err)
+	}
+
+	if err := checkUUID(policy.ID); err != nil {
+		return fmt.Errorf("failed to create escalation policy: %w", err)
+	}
+	row := &models.EscalationPolicy{
+		ID:          policy.ID,
+		Name:        policy.Name,
+		Description: policy.Description,
+		Steps:       storeSteps(policy.Steps),
+		CreatedAt:   roundTime(policy.CreatedAt),
+		UpdatedAt:   roundTime(policy.UpdatedAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.policies[row.ID]; ok {
+			return fmt.Errorf("failed to create escalation policy: escalation policy %s already exists", row.ID)
+		}
+		if err := checkPolicyName(s, row); err != nil {
+			return fmt.Errorf("failed to create escalation policy: %w", err)
+		}
+		put(s, s.policies, row.ID, row)
+		return nil
+	})
+}
+
+// checkPolicyName checks that no other policy has the name of a policy
+func checkPolicyName(s *store, row *models.EscalationPolicy) error {
+	for _, existing := range s.policies {
+		if existing.ID != row.ID && existing.Name == row.Name {
+			return fmt.Errorf("escalation policy %s already exists", row.Name)
+		}
+	}
+	return nil
+}
+
+// GetPolicy retrieves an escalation policy by ID
+func (r *escalationRepository) GetPolicy(ctx context.Context, id string) (*models.EscalationPolicy, error) {
+	var policy *models.EscalationPolicy
+	err := r.read(func(s *store) error {
+		row, ok := s.policies[id]
+		if !ok {
+			return fmt.Errorf("%w: escalation policy %s", repository.ErrNotFound, id)
+		}
+		policy = copyPolicy(row)
+		return nil
+	})
+	return policy, err
+}
+
+// UpdatePolicy updates an existing escalation policy
+func (r *escalationRepository) UpdatePolicy(ctx context.Context, policy *models.EscalationPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("escalation policy validation failed: %w", err)
+	}
+
+	policy.UpdatedAt = time.Now()
+
+	return r.write(func(s *store) error {
+		row, ok := s.policies[policy.ID]
+		if !ok {
+			return fmt.Errorf("%w: escalation policy %s", repository.ErrNotFound, policy.ID)
+		}
+
+		changed := copyPolicy(row)
+		changed.Name = policy.Name
+		changed.Description = policy.Description
+		changed.Steps = storeSteps(policy.Steps)
+		changed.UpdatedAt = roundTime(policy.UpdatedAt)
+		if err := checkPolicyName(s, changed); err != nil {
+			return fmt.Errorf("failed to update escalation policy: %w", err)
+		}
+		put(s, s.policies, policy.ID, changed)
+		return nil
+	})
+}
+
+// DeletePolicy removes an escalation policy and the routes that use it
+func (r *escalationRepository) DeletePolicy(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.policies[id]; !ok {
+			return fmt.Errorf("%w: escalation policy %s", repository.ErrNotFound, id)
+		}
+		remove(s, s.policies, id)
+
+		deleteWhere(s, s.routes, func(route *models.AlertRoute) bool { return route.PolicyID == id })
+		for alertID, escalation := range s.escalations {
+			if escalation.PolicyID == id {
+				changed := *escalation
+				changed.PolicyID = ""
+				put(s, s.escalations, alertID, &changed)
+			}
+		}
+		return nil
+	})
+}
+
+// ListPolicies retrieves all escalation policies
+func (r *escalationRepository) ListPolicies(ctx context.Context) ([]*models.EscalationPolicy, error) {
+	var policies []*models.EscalationPolicy
+	err := r.read(func(s *store) error {
+		matched := rows(s.policies, nil)
+		slices.SortStableFunc(matched, func(a, b *models.EscalationPolicy) int { return strings.Compare(a.Name, b.Name) })
+		for _, row := range matched {
+			policies = append(policies, copyPolicy(row))
+		}
+		return nil
+	})
+	return policies, err
+}
+
+// CreateRoute creates a new alert route
+func (r *escalationRepository) CreateRoute(ctx context.Context, route *models.AlertRoute) error {
+	route.SetDefaults()
+
+	if err := route.Validate(); err != nil {
+		return fmt.Errorf("alert route validation failed: %w", err)
+	}
+
+	if err := checkUUID(route.ID); err != nil {
+		return fmt.Errorf("failed to create alert route: %w", err)
+	}
+	row := &models.AlertRoute{
+		ID:          route.ID,
+		Name:        route.Name,
+		Priority:    route.Priority,
+		DeviceType:  copyPtr(route.DeviceType),
+		AlertType:   copyPtr(route.AlertType),
+		MinSeverity: copyPtr(route.MinSeverity),
+		PolicyID:    route.PolicyID,
+		CreatedAt:   roundTime(route.CreatedAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.routes[row.ID]; ok {
+			return fmt.Errorf("failed to create alert route: alert route %s already exists", row.ID)
+		}
+		if _, ok := s.policies[row.PolicyID]; !ok {
+			return fmt.Errorf("failed to create alert route: escalation policy %s does not exist", row.PolicyID)
+		}
+		put(s, s.routes, row.ID, row)
+		return nil
+	})
+}
+
+// DeleteRoute removes an alert route
+func (r *escalationRepository) DeleteRoute(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.routes[id]; !ok {
+			return fmt.Errorf("%w: alert route %s", repository.ErrNotFound, id)
+		}
+		remove(s, s.routes, id)
+		return nil
+	})
+}
+
+// ListRoutes retrieves all alert routes in ascending priority order
+func (r *escalationRepository) ListRoutes(ctx context.Context) ([]*models.AlertRoute, error) {
+	var routes []*models.AlertRoute
+	err := r.read(func(s *store) error {
+		matched := rows(s.routes, nil)
+		slices.SortStableFunc(matched, func(a, b *models.AlertRoute) int {
+			if c := cmp.Compare(a.Priority, b.Priority); c != 0 {
+				return c
+			}
+			return compareTimes(a.CreatedAt, b.CreatedAt)
+		})
+		for _, row := range matched {
+			routes = append(routes, copyRoute(row))
+		}
+		return nil
+	})
+	return routes, err
+}
+
+// CreateSchedule creates a new on-call schedule
+func (r *escalationRepository) CreateSchedule(ctx context.Context, schedule *models.OnCallSchedule) error {
+	schedule.SetDefaults()
+
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("on-call schedule validation failed: %w", err)
+	}
+
+	if err := checkUUID(schedule.ID); err != nil {
+		return fmt.Errorf("failed to create on-call schedule: %w", err)
+	}
+	row := &models.OnCallSchedule{
+		ID:            schedule.ID,
+		Name:          schedule.Name,
+		RotationStart: roundTime(schedule.RotationStart),
+		Participants:  slices.Clone(schedule.Participants),
+		CreatedAt:     roundTime(schedule.CreatedAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.schedules[row.ID]; ok {
+			return fmt.Errorf("failed to create on-call schedule: on-call schedule %s already exists", row.ID)
+		}
+		for _, existing := range s.schedules {
+			if existing.Name == row.Name {
+				return fmt.Errorf("failed to create on-call schedule: on-call schedule %s already exists", row.Name)
+			}
+		}
+		put(s, s.schedules, row.ID, row)
+		return nil
+	})
+}
+
+// GetSchedule retrieves an on-call schedule by ID
+func (r *escalationRepository) GetSchedule(ctx context.Context, id string) (*models.OnCallSchedule, error) {
+	var schedule *models.OnCallSchedule
+	err := r.read(func(s *store) error {
+		row, ok := s.schedules[id]
+		if !ok {
+			return fmt.Errorf("%w: on-call schedule %s", repository.ErrNotFound, id)
+		}
+		schedule = copySchedule(row)
+		return nil
+	})
+	return schedule, err
+}
+
+// DeleteSchedule removes an on-call schedule
+func (r *escalationRepository) DeleteSchedule(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.schedules[id]; !ok {
+			return fmt.Errorf("%w: on-call schedule %s", repository.ErrNotFound, id)
+		}
+		remove(s, s.schedules, id)
+		return nil
+	})
+}
+
+// ListSchedules retrieves all on-call schedules
+func (r *escalationRepository) ListSchedules(ctx context.Context) ([]*models.OnCallSchedule, error) {
+	var schedules []*models.OnCallSchedule
+	err := r.read(func(s *store) error {
+		matched := rows(s.schedules, nil)
+		slices.SortStableFunc(matched, func(a, b *models.OnCallSchedule) int { return strings.Compare(a.Name, b.Name) })
+		for _, row := range matched {
+			schedules = append(schedules, copySchedule(row))
+		}
+		return nil
+	})
+	return schedules, err
+}
+
+// GetEscalations retrieves the escalation progress of the given alerts,
+// keyed by alert ID. Alerts that have never escalated are omitted.
+func (r *escalationRepository) GetEscalations(ctx context.Context, alertIDs []string) (map[string]*models.AlertEscalation, error) {
+	escalations := make(map[string]*models.AlertEscalation)
+	if len(alertIDs) == 0 {
+		return escalations, nil
+	}
+
+	err := r.read(func(s *store) error {
+		for _, id := range alertIDs {
+			if row, ok := s.escalations[id]; ok {
+				escalation := *row
+				escalations[id] = &escalation
+			}
+		}
+		return nil
+	})
+	return escalations, err
+}
+
+// RecordEscalation creates or updates the escalation progress of an alert
+func (r *escalationRepository) RecordEscalation(ctx context.Context, escalation *models.AlertEscalation) error {
+	row := *escalation
+	row.LastEscalatedAt = roundTime(escalation.LastEscalatedAt)
+
+	return r.write(func(s *store) error {
+		if _, ok := s.alerts[row.AlertID]; !ok {
+			return fmt.Errorf("failed to record alert escalation: alert %s does not exist", row.AlertID)
+		}
+		if _, ok := s.policies[row.PolicyID]; !ok {
+			return fmt.Errorf("failed to record alert escalation: escalation policy %s does not exist", row.PolicyID)
+		}
+		put(s, s.escalations, row.AlertID, &row)
+		return nil
+	})
+}
+
+// storeSteps converts escalation steps to the form read back from the
+// database, which keeps delays in whole seconds and omits empty recipients
+func storeSteps(steps []models.EscalationStep) []models.EscalationStep {
+	stored := make([]models.EscalationStep, len(steps))
+	for i, step := range steps {
+		stored[i] = models.EscalationStep{
+			Delay:      step.Delay.Truncate(time.Second),
+			ScheduleID: copyPtr(step.ScheduleID),
+		}
+		if len(step.Recipients) > 0 {
+			stored[i].Recipients = slices.Clone(step.Recipients)
+		}
+	}
+	return stored
+}
+
+func copyPolicy(policy *models.EscalationPolicy) *models.EscalationPolicy {
+	copied := *policy
+	copied.Steps = storeSteps(policy.Steps)
+	return &copied
+}
+
+func copyRoute(route *models.AlertRoute) *models.AlertRoute {
+	copied := *route
+	copied.DeviceType = copyPtr(route.DeviceType)
+	copied.AlertType = copyPtr(route.AlertType)
+	copied.MinSeverity = copyPtr(route.MinSeverity)
+	return &copied
+}
+
+func copySchedule(schedule *models.OnCallSchedule) *models.OnCallSchedule {
+	copied := *schedule
+	copied.Participants = slices.Clone(schedule.Participants)
+	return &copied
+}
@@ -0,0 +1,199 @@
+// Package memory implements the repositories in memory, for tests and for
+// running the gateway without a database. Filtering, sorting, pagination and
+// aggregation follow the PostgreSQL repositories; the conformance suite in
+// package repositorytest checks both against the same expectations.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// database holds the tables. Writes take the lock for the whole statement,
+// and transactions for their whole duration, so transactions are
+// serializable and nobody sees their changes before they commit.
+type database struct {
+	mutex sync.RWMutex
+	data  *store
+}
+
+// repositoryManager implements RepositoryManager over the in-memory tables.
+// Inside a transaction tx is the tables the transaction changes, whose
+// changes are rolled back unless the transaction function returns without
+// error.
+type repositoryManager struct {
+	db *database
+	tx *store
+
+	deviceRepo        repository.DeviceRepository
+	measurementRepo   repository.MeasurementRepository
+	commandRepo       repository.CommandRepository
+	alertRepo         repository.AlertRepository
+	silenceRepo       repository.SilenceRepository
+	escalationRepo    repository.EscalationRepository
+	deviceEventRepo   repository.DeviceEventRepository
+	deviceSessionRepo repository.DeviceSessionRepository
+	roleRepo          repository.RoleRepository
+	provisioningRepo  repository.ProvisioningRepository
+	apiKeyRepo        repository.APIKeyRepository
+	auditRepo         repository.AuditRepository
+}
+
+// NewRepositoryManager creates a repository manager with empty tables and
+// the built-in roles
+func NewRepositoryManager() repository.RepositoryManager {
+	return newRepositoryManager(&database{data: newStore()}, nil)
+}
+
+func newRepositoryManager(db *database, tx *store) *repositoryManager {
+	t := &tables{db: db, tx: tx}
+	return &repositoryManager{
+		db:                db,
+		tx:                tx,
+		deviceRepo:        &deviceRepository{t},
+		measurementRepo:   &measurementRepository{t},
+		commandRepo:       &commandRepository{t},
+		alertRepo:         &alertRepository{t},
+		silenceRepo:       &silenceRepository{t},
+		escalationRepo:    &escalationRepository{t},
+		deviceEventRepo:   &deviceEventRepository{t},
+		deviceSessionRepo: &deviceSessionRepository{t},
+		roleRepo:          &roleRepository{t},
+		provisioningRepo:  &provisioningRepository{t},
+		apiKeyRepo:        &apiKeyRepository{t},
+		auditRepo:         &auditRepository{t},
+	}
+}
+
+// Device returns the device repository
+func (rm *repositoryManager) Device() repository.DeviceRepository {
+	return rm.deviceRepo
+}
+
+// Measurement returns the measurement repository
+func (rm *repositoryManager) Measurement() repository.MeasurementRepository {
+	return rm.measurementRepo
+}
+
+// Command returns the command repository
+func (rm *repositoryManager) Command() repository.CommandRepository {
+	return rm.commandRepo
+}
+
+// Alert returns the alert repository
+func (rm *repositoryManager) Alert() repository.AlertRepository {
+	return rm.alertRepo
+}
+
+// Silence returns the silence repository
+func (rm *repositoryManager) Silence() repository.SilenceRepository {
+	return rm.silenceRepo
+}
+
+// Escalation returns the escalation repository
+func (rm *repositoryManager) Escalation() repository.EscalationRepository {
+	return rm.escalationRepo
+}
+
+// DeviceEvent returns the device event repository
+func (rm *repositoryManager) DeviceEvent() repository.DeviceEventRepository {
+	return rm.deviceEventRepo
+}
+
+// DeviceSession returns the device session repository
+func (rm *repositoryManager) DeviceSession() repository.DeviceSessionRepository {
+	return rm.deviceSessionRepo
+}
+
+// Role returns the role repository
+func (rm *repositoryManager) Role() repository.RoleRepository {
+	return rm.roleRepo
+}
+
+// Provisioning returns the provisioning repository
+func (rm *repositoryManager) Provisioning() repository.ProvisioningRepository {
+	return rm.provisioningRepo
+}
+
+// APIKey returns the API key repository
+func (rm *repositoryManager) APIKey() repository.APIKeyRepository {
+	return rm.apiKeyRepo
+}
+
+// Audit returns the audit repository
+func (rm *repositoryManager) Audit() repository.AuditRepository {
+	return rm.auditRepo
+}
+
+// WithTransaction runs fn against the tables, recording each change in an
+// undo log that is rolled back if fn fails. Other callers wait until the
+// transaction ends, so fn must only use the repositories it is given. Called
+// on the repositories of a transaction, it runs fn in that transaction.
+func (rm *repositoryManager) WithTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.RepositoryManager) error) error {
+	if rm.tx != nil {
+		return fn(ctx, rm)
+	}
+
+	rm.db.mutex.Lock()
+	defer rm.db.mutex.Unlock()
+
+	tx := rm.db.data
+	tx.undo = &undoLog{}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.undo.rollback()
+		}
+		tx.undo = nil
+	}()
+
+	if err := fn(ctx, newRepositoryManager(rm.db, tx)); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+// HealthCheck always succeeds
+func (rm *repositoryManager) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// Close does nothing; the tables live as long as the manager
+func (rm *repositoryManager) Close() error {
+	return nil
+}
+
+// tables gives the repositories access to the tables, either the committed
+// ones under the database lock or those of the enclosing transaction
+type tables struct {
+	db *database
+	tx *store
+}
+
+// read runs fn with the tables for reading
+func (t *tables) read(fn func(s *store) error) error {
+	if t.tx != nil {
+		return fn(t.tx)
+	}
+
+	t.db.mutex.RLock()
+	defer t.db.mutex.RUnlock()
+	return fn(t.db.data)
+}
+
+// write runs fn with the tables for writing. Statements check every
+// constraint before changing a table, so that a failed statement leaves the
+// tables as they were.
+func (t *tables) write(fn func(s *store) error) error {
+	if t.tx != nil {
+		return fn(t.tx)
+	}
+
+	t.db.mutex.Lock()
+	defer t.db.mutex.Unlock()
+	return fn(t.db.data)
+}
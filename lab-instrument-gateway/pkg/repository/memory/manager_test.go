@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/repositorytest"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.RepositoryManager {
+		return NewRepositoryManager()
+	})
+}
+
+func TestWithTransaction_RollsBackCascadesAndAudit(t *testing.T) {
+	ctx := context.Background()
+	repos := NewRepositoryManager()
+	device := &models.Device{ID: "hplc-01", Name: "hplc-01", Type: "analyzer", Status: models.DeviceStatusOnline}
+	require.NoError(t, repos.Device().Create(ctx, device))
+	require.NoError(t, repos.DeviceEvent().Create(ctx, &models.DeviceEvent{DeviceID: device.ID, Type: models.DeviceEventRegistered}))
+	require.NoError(t, repos.Audit().Append(ctx, &models.AuditEntry{Actor: "admin", Action: "devices.register", ResourceType: "device", ResourceID: device.ID, Outcome: models.AuditOutcomeSuccess}))
+
+	failure := errors.New("abort")
+	err := repos.WithTransaction(ctx, func(ctx context.Context, tx repository.RepositoryManager) error {
+		if err := tx.Audit().Append(ctx, &models.AuditEntry{Actor: "admin", Action: "devices.delete", ResourceType: "device", ResourceID: device.ID, Outcome: models.AuditOutcomeSuccess}); err != nil {
+			return err
+		}
+		if err := tx.Device().Delete(ctx, device.ID); err != nil {
+			return err
+		}
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	// A panicking transaction is rolled back as well
+	assert.Panics(t, func() {
+		_ = repos.WithTransaction(ctx, func(ctx context.Context, tx repository.RepositoryManager) error {
+			if err := tx.Device().Delete(ctx, device.ID); err != nil {
+				return err
+			}
+			panic(failure)
+		})
+	})
+
+	_, err = repos.Device().GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	events, err := repos.DeviceEvent().Count(ctx, repository.DeviceEventFilter{DeviceID: device.ID})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, events)
+
+	// The log continues from the last committed entry
+	entry := &models.AuditEntry{Actor: "admin", Action: "devices.update", ResourceType: "device", ResourceID: device.ID, Outcome: models.AuditOutcomeSuccess}
+	require.NoError(t, repos.Audit().Append(ctx, entry))
+	assert.EqualValues(t, 2, entry.Sequence)
+	entries, err := repos.Audit().ListAfter(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, entries[0].Hash, entries[1].PreviousHash)
+}
@@ -0,0 +1,453 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// measurementColumns are the columns measurements can be sorted by
+var measurementColumns = map[string]column[*models.Measurement]{
+	"id":        func(a, b *models.Measurement) int { return strings.Compare(a.ID, b.ID) },
+	"device_id": func(a, b *models.Measurement) int { return strings.Compare(a.DeviceID, b.DeviceID) },
+	"timestamp": func(a, b *models.Measurement) int { return compareTimes(a.Timestamp, b.Timestamp) },
+	"type":      func(a, b *models.Measurement) int { return strings.Compare(a.Type, b.Type) },
+	"value": func(a, b *models.Measurement) int {
+		return compareFloats(a.Value, b.Value)
+	},
+	"unit":    func(a, b *models.Measurement) int { return strings.Compare(a.Unit, b.Unit) },
+	"quality": func(a, b *models.Measurement) int { return compareQualities(a.Quality, b.Quality) },
+	"batch_id": func(a, b *models.Measurement) int {
+		return compareNullable(a.BatchID, b.BatchID, strings.Compare)
+	},
+	"sequence_number": func(a, b *models.Measurement) int {
+		return compareNullable(a.SequenceNumber, b.SequenceNumber, func(x, y int) int { return x - y })
+	},
+	"created_at": func(a, b *models.Measurement) int { return compareTimes(a.CreatedAt, b.CreatedAt) },
+}
+
+// compareQualities orders quality codes as the quality_code enum
+var compareQualities = enumOrder(
+	models.QualityUnknown,
+	models.QualityGood,
+	models.QualityBad,
+	models.QualityUncertain,
+	models.QualitySubstituted,
+)
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// measurementRepository implements MeasurementRepository in memory
+type measurementRepository struct {
+	*tables
+}
+
+// Create creates a new measurement
+func (r *measurementRepository) Create(ctx context.Context, measurement *models.Measurement) error {
+	if err := measurement.Validate(); err != nil {
+		return fmt.Errorf("measurement validation failed: %w", err)
+	}
+
+	measurement.SetDefaults()
+
+	row, err := newMeasurementRow(measurement)
+	if err != nil {
+		return err
+	}
+
+	return r.write(func(s *store) error {
+		if err := s.checkMeasurement(row); err != nil {
+			return fmt.Errorf("failed to create measurement: %w", err)
+		}
+		put(s, s.measurements, row.ID, row)
+		return nil
+	})
+}
+
+// checkMeasurement checks the constraints of a new measurement
+func (s *store) checkMeasurement(row *models.Measurement) error {
+	if _, ok := s.measurements[row.ID]; ok {
+		return fmt.Errorf("measurement %s already exists", row.ID)
+	}
+	if _, ok := s.devices[row.DeviceID]; !ok {
+		return fmt.Errorf("device %s does not exist", row.DeviceID)
+	}
+	return nil
+}
+
+// GetByID retrieves a measurement by ID
+func (r *measurementRepository) GetByID(ctx context.Context, id string) (*models.Measurement, error) {
+	var measurement *models.Measurement
+	err := r.read(func(s *store) error {
+		row, ok := s.measurements[id]
+		if !ok {
+			return fmt.Errorf("%w: measurement %s", repository.ErrNotFound, id)
+		}
+		measurement = copyMeasurement(row)
+		return nil
+	})
+	return measurement, err
+}
+
+// Delete deletes a measurement by ID
+func (r *measurementRepository) Delete(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.measurements[id]; !ok {
+			return fmt.Errorf("%w: measurement %s", repository.ErrNotFound, id)
+		}
+		remove(s, s.measurements, id)
+		return nil
+	})
+}
+
+// CreateBulk creates multiple measurements at once. Invalid measurements are
+// skipped and reported; any other failure fails the whole insert, as it
+// aborts the transaction of the database implementation.
+func (r *measurementRepository) CreateBulk(ctx context.Context, measurements []*models.Measurement) (*repository.BulkResult, error) {
+	result := &repository.BulkResult{}
+	if len(measurements) == 0 {
+		return result, nil
+	}
+
+	var valid []*models.Measurement
+	for _, measurement := range measurements {
+		if err := measurement.Validate(); err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("measurement validation failed: %w", err))
+			continue
+		}
+
+		measurement.SetDefaults()
+
+		row, err := newMeasurementRow(measurement)
+		if err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	err := r.write(func(s *store) error {
+		inserted := make(map[string]bool, len(valid))
+		for _, row := range valid {
+			if err := s.checkMeasurement(row); err != nil || inserted[row.ID] {
+				if err == nil {
+					err = fmt.Errorf("measurement %s already exists", row.ID)
+				}
+				return fmt.Errorf("failed to commit transaction: %w", err)
+			}
+			inserted[row.ID] = true
+		}
+
+		for _, row := range valid {
+			put(s, s.measurements, row.ID, row)
+		}
+		result.SuccessCount = len(valid)
+		return nil
+	})
+
+	return result, err
+}
+
+// CreateBatch creates a batch of measurements with shared metadata
+func (r *measurementRepository) CreateBatch(ctx context.Context, batch *models.MeasurementBatch) error {
+	if len(batch.Measurements) == 0 {
+		return nil
+	}
+
+	// Set batch metadata on all measurements
+	measurements := make([]*models.Measurement, len(batch.Measurements))
+	for i := range batch.Measurements {
+		batch.Measurements[i].DeviceID = batch.DeviceID
+		batch.Measurements[i].BatchID = &batch.BatchID
+		if batch.Measurements[i].Timestamp.IsZero() {
+			batch.Measurements[i].Timestamp = batch.Timestamp
+		}
+		measurements[i] = &batch.Measurements[i]
+	}
+
+	if _, err := r.CreateBulk(ctx, measurements); err != nil {
+		return fmt.Errorf("failed to create measurement batch: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves measurements with filtering and pagination
+func (r *measurementRepository) List(ctx context.Context, filter repository.MeasurementFilter) ([]*models.Measurement, error) {
+	var measurements []*models.Measurement
+	err := r.read(func(s *store) error {
+		matched := rows(s.measurements, func(m *models.Measurement) bool { return matchMeasurement(m, filter) })
+		if err := sortRows(matched, measurementColumns, filter.Filter, "timestamp"); err != nil {
+			return err
+		}
+		for _, row := range paginate(matched, filter.Filter) {
+			measurements = append(measurements, copyMeasurement(row))
+		}
+		return nil
+	})
+	return measurements, err
+}
+
+// Count returns the total number of measurements matching the filter
+func (r *measurementRepository) Count(ctx context.Context, filter repository.MeasurementFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		count = int64(len(rows(s.measurements, func(m *models.Measurement) bool { return matchMeasurement(m, filter) })))
+		return nil
+	})
+	return count, err
+}
+
+// GetByTimeRange retrieves the measurements of a device within an inclusive
+// time range, oldest first
+func (r *measurementRepository) GetByTimeRange(ctx context.Context, deviceID string, startTime, endTime time.Time) ([]*models.Measurement, error) {
+	timeRange := repository.TimeRangeFilter{StartTime: &startTime, EndTime: &endTime}
+
+	var measurements []*models.Measurement
+	err := r.read(func(s *store) error {
+		matched := rows(s.measurements, func(m *models.Measurement) bool {
+			return m.DeviceID == deviceID && inRange(m.Timestamp, timeRange)
+		})
+		slices.SortStableFunc(matched, measurementColumns["timestamp"])
+		for _, row := range matched {
+			measurements = append(measurements, copyMeasurement(row))
+		}
+		return nil
+	})
+	return measurements, err
+}
+
+// GetLatest retrieves the latest measurement for a device and type
+func (r *measurementRepository) GetLatest(ctx context.Context, deviceID string, measurementType string) (*models.Measurement, error) {
+	var measurement *models.Measurement
+	err := r.read(func(s *store) error {
+		for _, row := range s.measurements {
+			if row.DeviceID == deviceID && row.Type == measurementType && (measurement == nil || row.Timestamp.After(measurement.Timestamp)) {
+				measurement = row
+			}
+		}
+		if measurement == nil {
+			return fmt.Errorf("%w: %s measurement for device %s", repository.ErrNotFound, measurementType, deviceID)
+		}
+		measurement = copyMeasurement(measurement)
+		return nil
+	})
+	return measurement, err
+}
+
+// GetLatestByDevice retrieves the latest measurement of each type for a
+// device, ordered by type
+func (r *measurementRepository) GetLatestByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Measurement, error) {
+	latest := make(map[string]*models.Measurement)
+	err := r.read(func(s *store) error {
+		for _, row := range s.measurements {
+			if current, ok := latest[row.Type]; row.DeviceID == deviceID && (!ok || row.Timestamp.After(current.Timestamp)) {
+				latest[row.Type] = row
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var measurements []*models.Measurement
+	for _, row := range rows(latest, nil) {
+		if len(measurements) >= limit {
+			break
+		}
+		measurements = append(measurements, copyMeasurement(row))
+	}
+	return measurements, nil
+}
+
+// Aggregate aggregates measurements into hourly buckets per device and
+// type, newest first
+func (r *measurementRepository) Aggregate(ctx context.Context, req repository.AggregationRequest) ([]*repository.AggregationResult, error) {
+	type bucketKey struct {
+		deviceID        string
+		measurementType string
+		hour            time.Time
+	}
+
+	buckets := make(map[bucketKey][]float64)
+	var keys []bucketKey
+	err := r.read(func(s *store) error {
+		for _, row := range rows(s.measurements, nil) {
+			if !matchAny(req.DeviceIDs, row.DeviceID) || !matchAny(req.Types, row.Type) || !inRange(row.Timestamp, req.TimeRange) {
+				continue
+			}
+
+			key := bucketKey{row.DeviceID, row.Type, row.Timestamp.UTC().Truncate(time.Hour)}
+			if _, ok := buckets[key]; !ok {
+				keys = append(keys, key)
+			}
+			buckets[key] = append(buckets[key], row.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(keys, func(a, b bucketKey) int {
+		return compareTimes(b.hour, a.hour)
+	})
+
+	results := make([]*repository.AggregationResult, 0, len(keys))
+	for _, key := range keys {
+		values := buckets[key]
+		results = append(results, &repository.AggregationResult{
+			DeviceID:  key.deviceID,
+			Type:      key.measurementType,
+			Timestamp: key.hour,
+			Value:     aggregate(req.AggregationType, values),
+			Count:     int64(len(values)),
+			Metadata:  map[string]interface{}{},
+		})
+	}
+	return results, nil
+}
+
+// aggregate applies an aggregation function to the values of a bucket,
+// averaging for unknown functions
+func aggregate(function string, values []float64) float64 {
+	switch function {
+	case "min":
+		return slices.Min(values)
+	case "max":
+		return slices.Max(values)
+	case "sum":
+		return sum(values)
+	case "count":
+		return float64(len(values))
+	}
+	return sum(values) / float64(len(values))
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// GetStatistics retrieves statistical information for the measurements of
+// the filter's devices, types and time range
+func (r *measurementRepository) GetStatistics(ctx context.Context, filter repository.MeasurementFilter) (*models.MeasurementStats, error) {
+	stats := &models.MeasurementStats{}
+	var total float64
+	err := r.read(func(s *store) error {
+		for _, row := range s.measurements {
+			if !matchAny(filter.DeviceIDs, row.DeviceID) || !matchAny(filter.Types, row.Type) || !inRange(row.Timestamp, filter.TimeRangeFilter) {
+				continue
+			}
+
+			if stats.Count == 0 || row.Value < stats.MinValue {
+				stats.MinValue = row.Value
+			}
+			if stats.Count == 0 || row.Value > stats.MaxValue {
+				stats.MaxValue = row.Value
+			}
+			if stats.Count == 0 || row.Timestamp.Before(stats.EarliestTime) {
+				stats.EarliestTime = row.Timestamp
+			}
+			if stats.Count == 0 || row.Timestamp.After(stats.LatestTime) {
+				stats.LatestTime = row.Timestamp
+			}
+
+			switch row.Quality {
+			case models.QualityGood:
+				stats.GoodQuality++
+			case models.QualityBad:
+				stats.BadQuality++
+			}
+
+			total += row.Value
+			stats.Count++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Count > 0 {
+		stats.AvgValue = total / float64(stats.Count)
+	}
+	return stats, nil
+}
+
+// DeleteOlderThan removes measurements older than the specified threshold
+func (r *measurementRepository) DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		deleted = deleteWhere(s, s.measurements, func(m *models.Measurement) bool { return m.Timestamp.Before(threshold) })
+		return nil
+	})
+	return deleted, err
+}
+
+// DeleteByDevice removes all measurements for a specific device
+func (r *measurementRepository) DeleteByDevice(ctx context.Context, deviceID string) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		deleted = deleteWhere(s, s.measurements, func(m *models.Measurement) bool { return m.DeviceID == deviceID })
+		return nil
+	})
+	return deleted, err
+}
+
+// matchMeasurement returns true if a measurement matches every condition
+// of the filter
+func matchMeasurement(measurement *models.Measurement, filter repository.MeasurementFilter) bool {
+	if !matchAny(filter.DeviceIDs, measurement.DeviceID) || !matchAny(filter.Types, measurement.Type) || !matchAny(filter.Qualities, measurement.Quality) {
+		return false
+	}
+	if filter.BatchID != nil && !equalPtr(measurement.BatchID, *filter.BatchID) {
+		return false
+	}
+	return inRange(measurement.Timestamp, filter.TimeRangeFilter)
+}
+
+// newMeasurementRow converts a measurement to the row stored for it
+func newMeasurementRow(measurement *models.Measurement) (*models.Measurement, error) {
+	if err := checkUUID(measurement.ID); err != nil {
+		return nil, fmt.Errorf("failed to create measurement: %w", err)
+	}
+
+	metadata, err := toJSON(measurement.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	row := copyMeasurement(measurement)
+	row.Metadata = metadata
+	row.Timestamp = roundTime(measurement.Timestamp)
+	row.CreatedAt = roundTime(measurement.CreatedAt)
+	return row, nil
+}
+
+func copyMeasurement(measurement *models.Measurement) *models.Measurement {
+	copied := *measurement
+	copied.Metadata = copyJSON(measurement.Metadata)
+	copied.BatchID = copyPtr(measurement.BatchID)
+	copied.SequenceNumber = copyPtr(measurement.SequenceNumber)
+	return &copied
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// provisioningRepository implements ProvisioningRepository in memory
+type provisioningRepository struct {
+	*tables
+}
+
+// CreateEnrollmentToken stores a new enrollment token
+func (r *provisioningRepository) CreateEnrollmentToken(ctx context.Context, token *models.EnrollmentToken) error {
+	token.SetDefaults()
+
+	if err := token.Validate(); err != nil {
+		return fmt.Errorf("enrollment token validation failed: %w", err)
+	}
+
+	if err := checkUUID(token.ID); err != nil {
+		return fmt.Errorf("failed to create enrollment token: %w", err)
+	}
+	row := &models.EnrollmentToken{
+		ID:          token.ID,
+		TokenHash:   token.TokenHash,
+		DeviceType:  token.DeviceType,
+		DeviceGroup: copyPtr(token.DeviceGroup),
+		CreatedBy:   token.CreatedBy,
+		ExpiresAt:   roundTime(token.ExpiresAt),
+		CreatedAt:   roundTime(token.CreatedAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.tokens[row.ID]; ok {
+			return fmt.Errorf("failed to create enrollment token: enrollment token %s already exists", row.ID)
+		}
+		for _, existing := range s.tokens {
+			if existing.TokenHash == row.TokenHash {
+				return fmt.Errorf("failed to create enrollment token: token hash already exists")
+			}
+		}
+		put(s, s.tokens, row.ID, row)
+		return nil
+	})
+}
+
+// ConsumeEnrollmentToken marks an unused, unexpired token as used by a device
+func (r *provisioningRepository) ConsumeEnrollmentToken(ctx context.Context, tokenHash string, deviceID string, at time.Time) (*models.EnrollmentToken, error) {
+	var token *models.EnrollmentToken
+	err := r.write(func(s *store) error {
+		for id, row := range s.tokens {
+			if row.TokenHash != tokenHash || !row.IsUsable(at) {
+				continue
+			}
+
+			changed := copyEnrollmentToken(row)
+			changed.UsedAt = roundTimePtr(&at)
+			changed.UsedByDevice = &deviceID
+			put(s, s.tokens, id, changed)
+			token = copyEnrollmentToken(changed)
+			return nil
+		}
+		return fmt.Errorf("%w: usable enrollment token", repository.ErrNotFound)
+	})
+	return token, err
+}
+
+// DeleteExpiredEnrollmentTokens removes tokens that expired before the threshold
+func (r *provisioningRepository) DeleteExpiredEnrollmentTokens(ctx context.Context, threshold time.Time) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		deleted = deleteWhere(s, s.tokens, func(token *models.EnrollmentToken) bool { return token.ExpiresAt.Before(threshold) })
+		return nil
+	})
+	return deleted, err
+}
+
+// RecordCertificate stores a certificate issued to a device
+func (r *provisioningRepository) RecordCertificate(ctx context.Context, certificate *models.DeviceCertificate) error {
+	if certificate.IssuedAt.IsZero() {
+		certificate.IssuedAt = time.Now()
+	}
+
+	row := *certificate
+	row.NotBefore = roundTime(certificate.NotBefore)
+	row.NotAfter = roundTime(certificate.NotAfter)
+	row.IssuedAt = roundTime(certificate.IssuedAt)
+
+	return r.write(func(s *store) error {
+		if _, ok := s.certificates[row.SerialNumber]; ok {
+			return fmt.Errorf("failed to record device certificate: certificate %s already exists", row.SerialNumber)
+		}
+		if _, ok := s.devices[row.DeviceID]; !ok {
+			return fmt.Errorf("failed to record device certificate: device %s does not exist", row.DeviceID)
+		}
+		put(s, s.certificates, row.SerialNumber, &row)
+		return nil
+	})
+}
+
+// ListCertificates retrieves the certificates issued to a device, newest first
+func (r *provisioningRepository) ListCertificates(ctx context.Context, deviceID string) ([]*models.DeviceCertificate, error) {
+	var certificates []*models.DeviceCertificate
+	err := r.read(func(s *store) error {
+		matched := rows(s.certificates, func(certificate *models.DeviceCertificate) bool {
+			return certificate.DeviceID == deviceID
+		})
+		slices.SortStableFunc(matched, func(a, b *models.DeviceCertificate) int {
+			return compareTimes(b.IssuedAt, a.IssuedAt)
+		})
+		for _, row := range matched {
+			certificate := *row
+			certificates = append(certificates, &certificate)
+		}
+		return nil
+	})
+	return certificates, err
+}
+
+func copyEnrollmentToken(token *models.EnrollmentToken) *models.EnrollmentToken {
+	copied := *token
+	copied.DeviceGroup = copyPtr(token.DeviceGroup)
+	copied.UsedAt = copyPtr(token.UsedAt)
+	copied.UsedByDevice = copyPtr(token.UsedByDevice)
+	return &copied
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// column compares two rows by one of their columns
+type column[T any] func(a, b T) int
+
+// sortRows sorts rows as ORDER BY would, by the filter's column or else by
+// defaultColumn, descending unless the filter asks for ascending order
+func sortRows[T any](rows []T, columns map[string]column[T], filter repository.Filter, defaultColumn string) error {
+	name := defaultColumn
+	if filter.SortBy != "" {
+		name = filter.SortBy
+	}
+
+	compare, ok := columns[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unsupported sort column: %s", name)
+	}
+
+	descending := true
+	switch strings.ToUpper(filter.Order) {
+	case "", "DESC":
+	case "ASC":
+		descending = false
+	default:
+		return fmt.Errorf("unsupported sort order: %s", filter.Order)
+	}
+
+	slices.SortStableFunc(rows, func(a, b T) int {
+		if descending {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return nil
+}
+
+// paginate applies the filter's offset and limit to sorted rows
+func paginate[T any](rows []T, filter repository.Filter) []T {
+	if filter.Offset > 0 {
+		if filter.Offset >= len(rows) {
+			return nil
+		}
+		rows = rows[filter.Offset:]
+	}
+
+	if filter.Limit > 0 && len(rows) > filter.Limit {
+		rows = rows[:filter.Limit]
+	}
+
+	return rows
+}
+
+// matchAny returns true if the values are empty, meaning no condition, or
+// contain the value
+func matchAny[T comparable](values []T, value T) bool {
+	return len(values) == 0 || slices.Contains(values, value)
+}
+
+// inRange returns true if t lies within the inclusive time range
+func inRange(t time.Time, timeRange repository.TimeRangeFilter) bool {
+	if timeRange.StartTime != nil && t.Before(*timeRange.StartTime) {
+		return false
+	}
+	if timeRange.EndTime != nil && t.After(*timeRange.EndTime) {
+		return false
+	}
+	return true
+}
+
+// compareNullable compares optional values the way PostgreSQL orders NULLs:
+// after every value in ascending order and before them in descending order
+func compareNullable[V any](a, b *V, compare func(V, V) int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return compare(*a, *b)
+}
+
+// enumOrder returns a comparison of enum values by their declaration order,
+// which is how PostgreSQL sorts enums
+func enumOrder[T comparable](values ...T) func(a, b T) int {
+	return func(a, b T) int {
+		return slices.Index(values, a) - slices.Index(values, b)
+	}
+}
+
+func compareTimes(a, b time.Time) int {
+	return a.Compare(b)
+}
+
+func compareNullTimes(a, b *time.Time) int {
+	return compareNullable(a, b, compareTimes)
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// jsonText renders a JSON value as the ->> operator does, returning false for
+// a missing or null value
+func jsonText(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	}
+
+	text, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(text), true
+}
@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// roleRepository implements RoleRepository in memory
+type roleRepository struct {
+	*tables
+}
+
+// CreateRole creates a new role
+func (r *roleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	role.SetDefaults()
+
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("role validation failed: %w", err)
+	}
+
+	row := &models.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: copyPermissions(role.Permissions),
+		BuiltIn:     role.BuiltIn,
+		CreatedAt:   roundTime(role.CreatedAt),
+		UpdatedAt:   roundTime(role.UpdatedAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.roles[row.Name]; ok {
+			return fmt.Errorf("failed to create role: role %s already exists", row.Name)
+		}
+		put(s, s.roles, row.Name, row)
+		return nil
+	})
+}
+
+// GetRole retrieves a role by name
+func (r *roleRepository) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	var role *models.Role
+	err := r.read(func(s *store) error {
+		row, ok := s.roles[name]
+		if !ok {
+			return fmt.Errorf("%w: role %s", repository.ErrNotFound, name)
+		}
+		role = copyRole(row)
+		return nil
+	})
+	return role, err
+}
+
+// UpdateRole updates the description and permissions of a role
+func (r *roleRepository) UpdateRole(ctx context.Context, role *models.Role) error {
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("role validation failed: %w", err)
+	}
+
+	return r.write(func(s *store) error {
+		row, ok := s.roles[role.Name]
+		if !ok {
+			return fmt.Errorf("%w: role %s", repository.ErrNotFound, role.Name)
+		}
+		changed := copyRole(row)
+		changed.Description = role.Description
+		changed.Permissions = copyPermissions(role.Permissions)
+		changed.UpdatedAt = roundTime(time.Now())
+		put(s, s.roles, role.Name, changed)
+		return nil
+	})
+}
+
+// DeleteRole removes a role and its bindings. Built-in roles cannot be deleted.
+func (r *roleRepository) DeleteRole(ctx context.Context, name string) error {
+	return r.write(func(s *store) error {
+		row, ok := s.roles[name]
+		if !ok || row.BuiltIn {
+			return fmt.Errorf("%w: role %s", repository.ErrNotFound, name)
+		}
+		remove(s, s.roles, name)
+		deleteWhere(s, s.bindings, func(binding *models.RoleBinding) bool { return binding.Role == name })
+		return nil
+	})
+}
+
+// ListRoles retrieves all roles
+func (r *roleRepository) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	err := r.read(func(s *store) error {
+		// Roles are keyed by name, so they are already in order
+		for _, row := range rows(s.roles, nil) {
+			roles = append(roles, copyRole(row))
+		}
+		return nil
+	})
+	return roles, err
+}
+
+// CreateBinding grants a role to a subject
+func (r *roleRepository) CreateBinding(ctx context.Context, binding *models.RoleBinding) error {
+	binding.SetDefaults()
+
+	if err := binding.Validate(); err != nil {
+		return fmt.Errorf("role binding validation failed: %w", err)
+	}
+
+	if err := checkUUID(binding.ID); err != nil {
+		return fmt.Errorf("failed to create role binding: %w", err)
+	}
+	row := &models.RoleBinding{
+		ID:          binding.ID,
+		Subject:     binding.Subject,
+		Role:        binding.Role,
+		DeviceGroup: copyPtr(binding.DeviceGroup),
+		CreatedBy:   binding.CreatedBy,
+		CreatedAt:   roundTime(binding.CreatedAt),
+	}
+
+	return r.write(func(s *store) error {
+		if _, ok := s.bindings[row.ID]; ok {
+			return fmt.Errorf("failed to create role binding: role binding %s already exists", row.ID)
+		}
+		if _, ok := s.roles[row.Role]; !ok {
+			return fmt.Errorf("failed to create role binding: role %s does not exist", row.Role)
+		}
+		for _, existing := range s.bindings {
+			if existing.Subject == row.Subject && existing.Role == row.Role && deviceGroup(existing) == deviceGroup(row) {
+				return fmt.Errorf("failed to create role binding: %s is already bound to role %s", row.Subject, row.Role)
+			}
+		}
+		put(s, s.bindings, row.ID, row)
+		return nil
+	})
+}
+
+// deviceGroup returns the device group of a binding, or an empty string for
+// a global binding, as the unique index compares them
+func deviceGroup(binding *models.RoleBinding) string {
+	if binding.DeviceGroup == nil {
+		return ""
+	}
+	return *binding.DeviceGroup
+}
+
+// DeleteBinding removes a role binding
+func (r *roleRepository) DeleteBinding(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.bindings[id]; !ok {
+			return fmt.Errorf("%w: role binding %s", repository.ErrNotFound, id)
+		}
+		remove(s, s.bindings, id)
+		return nil
+	})
+}
+
+// ListBindings retrieves the role bindings of a subject, or of every subject
+// if subject is empty
+func (r *roleRepository) ListBindings(ctx context.Context, subject string) ([]*models.RoleBinding, error) {
+	var bindings []*models.RoleBinding
+	err := r.read(func(s *store) error {
+		matched := rows(s.bindings, func(binding *models.RoleBinding) bool {
+			return subject == "" || binding.Subject == subject
+		})
+		slices.SortStableFunc(matched, func(a, b *models.RoleBinding) int {
+			if c := strings.Compare(a.Subject, b.Subject); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Role, b.Role)
+		})
+		for _, row := range matched {
+			binding := *row
+			binding.DeviceGroup = copyPtr(row.DeviceGroup)
+			bindings = append(bindings, &binding)
+		}
+		return nil
+	})
+	return bindings, err
+}
+
+// copyPermissions copies permissions as they are read back, never nil
+func copyPermissions(permissions []models.Permission) []models.Permission {
+	return append(make([]models.Permission, 0, len(permissions)), permissions...)
+}
+
+func copyRole(role *models.Role) *models.Role {
+	copied := *role
+	copied.Permissions = copyPermissions(role.Permissions)
+	return &copied
+}
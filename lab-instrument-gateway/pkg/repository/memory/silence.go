@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// silenceColumns are the columns silences can be sorted by
+var silenceColumns = map[string]column[*models.Silence]{
+	"id":          func(a, b *models.Silence) int { return strings.Compare(a.ID, b.ID) },
+	"device_id":   func(a, b *models.Silence) int { return compareNullable(a.DeviceID, b.DeviceID, strings.Compare) },
+	"device_type": func(a, b *models.Silence) int { return compareNullable(a.DeviceType, b.DeviceType, strings.Compare) },
+	"starts_at":   func(a, b *models.Silence) int { return compareTimes(a.StartsAt, b.StartsAt) },
+	"ends_at":     func(a, b *models.Silence) int { return compareTimes(a.EndsAt, b.EndsAt) },
+	"created_by":  func(a, b *models.Silence) int { return strings.Compare(a.CreatedBy, b.CreatedBy) },
+	"created_at":  func(a, b *models.Silence) int { return compareTimes(a.CreatedAt, b.CreatedAt) },
+}
+
+// silenceRepository implements SilenceRepository in memory
+type silenceRepository struct {
+	*tables
+}
+
+// Create creates a new silence
+func (r *silenceRepository) Create(ctx context.Context, silence *models.Silence) error {
+	silence.SetDefaults()
+
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("silence validation failed: %w", err)
+	}
+
+	if err := checkUUID(silence.ID); err != nil {
+		return fmt.Errorf("failed to create silence: %w", err)
+	}
+	row := newSilenceRow(silence)
+	row.CreatedAt = roundTime(silence.CreatedAt)
+
+	return r.write(func(s *store) error {
+		if _, ok := s.silences[row.ID]; ok {
+			return fmt.Errorf("failed to create silence: silence %s already exists", row.ID)
+		}
+		if err := s.checkSilenceDevice(row); err != nil {
+			return fmt.Errorf("failed to create silence: %w", err)
+		}
+		put(s, s.silences, row.ID, row)
+		return nil
+	})
+}
+
+// checkSilenceDevice checks that the device a silence matches exists
+func (s *store) checkSilenceDevice(row *models.Silence) error {
+	if row.DeviceID == nil {
+		return nil
+	}
+	if _, ok := s.devices[*row.DeviceID]; !ok {
+		return fmt.Errorf("device %s does not exist", *row.DeviceID)
+	}
+	return nil
+}
+
+// GetByID retrieves a silence by ID
+func (r *silenceRepository) GetByID(ctx context.Context, id string) (*models.Silence, error) {
+	var silence *models.Silence
+	err := r.read(func(s *store) error {
+		row, ok := s.silences[id]
+		if !ok {
+			return fmt.Errorf("%w: silence %s", repository.ErrNotFound, id)
+		}
+		silence = copySilence(row)
+		return nil
+	})
+	return silence, err
+}
+
+// Update updates an existing silence
+func (r *silenceRepository) Update(ctx context.Context, silence *models.Silence) error {
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("silence validation failed: %w", err)
+	}
+
+	changed := newSilenceRow(silence)
+
+	return r.write(func(s *store) error {
+		row, ok := s.silences[silence.ID]
+		if !ok {
+			return fmt.Errorf("%w: silence %s", repository.ErrNotFound, silence.ID)
+		}
+		if err := s.checkSilenceDevice(changed); err != nil {
+			return fmt.Errorf("failed to update silence: %w", err)
+		}
+		changed.CreatedBy = row.CreatedBy
+		changed.CreatedAt = row.CreatedAt
+		put(s, s.silences, silence.ID, changed)
+		return nil
+	})
+}
+
+// Delete removes a silence
+func (r *silenceRepository) Delete(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		if _, ok := s.silences[id]; !ok {
+			return fmt.Errorf("%w: silence %s", repository.ErrNotFound, id)
+		}
+		remove(s, s.silences, id)
+		return nil
+	})
+}
+
+// List retrieves silences with filtering and pagination
+func (r *silenceRepository) List(ctx context.Context, filter repository.SilenceFilter) ([]*models.Silence, error) {
+	var silences []*models.Silence
+	err := r.read(func(s *store) error {
+		matched := rows(s.silences, func(silence *models.Silence) bool { return matchSilence(silence, filter) })
+		if err := sortRows(matched, silenceColumns, filter.Filter, "starts_at"); err != nil {
+			return err
+		}
+		for _, row := range paginate(matched, filter.Filter) {
+			silences = append(silences, copySilence(row))
+		}
+		return nil
+	})
+	return silences, err
+}
+
+// Count returns the total number of silences matching the filter
+func (r *silenceRepository) Count(ctx context.Context, filter repository.SilenceFilter) (int64, error) {
+	var count int64
+	err := r.read(func(s *store) error {
+		count = int64(len(rows(s.silences, func(silence *models.Silence) bool { return matchSilence(silence, filter) })))
+		return nil
+	})
+	return count, err
+}
+
+// GetActive retrieves the silences active at the given time
+func (r *silenceRepository) GetActive(ctx context.Context, at time.Time) ([]*models.Silence, error) {
+	var silences []*models.Silence
+	err := r.read(func(s *store) error {
+		matched := rows(s.silences, func(silence *models.Silence) bool { return silence.IsActive(at) })
+		slices.SortStableFunc(matched, silenceColumns["starts_at"])
+		for _, row := range matched {
+			silences = append(silences, copySilence(row))
+		}
+		return nil
+	})
+	return silences, err
+}
+
+// Expire ends a silence immediately
+func (r *silenceRepository) Expire(ctx context.Context, id string) error {
+	return r.write(func(s *store) error {
+		row, ok := s.silences[id]
+		if !ok {
+			return fmt.Errorf("%w: silence %s", repository.ErrNotFound, id)
+		}
+		changed := copySilence(row)
+		changed.Expire()
+		changed.StartsAt = roundTime(changed.StartsAt)
+		changed.EndsAt = roundTime(changed.EndsAt)
+		put(s, s.silences, id, changed)
+		return nil
+	})
+}
+
+// DeleteExpiredOlderThan removes silences that ended before the threshold
+func (r *silenceRepository) DeleteExpiredOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	var deleted int64
+	err := r.write(func(s *store) error {
+		deleted = deleteWhere(s, s.silences, func(silence *models.Silence) bool { return silence.EndsAt.Before(threshold) })
+		return nil
+	})
+	return deleted, err
+}
+
+// matchSilence returns true if a silence matches every condition of the
+// filter
+func matchSilence(silence *models.Silence, filter repository.SilenceFilter) bool {
+	if len(filter.DeviceIDs) > 0 && (silence.DeviceID == nil || !slices.Contains(filter.DeviceIDs, *silence.DeviceID)) {
+		return false
+	}
+	if filter.CreatedBy != nil && silence.CreatedBy != *filter.CreatedBy {
+		return false
+	}
+	if filter.ActiveAt != nil && !silence.IsActive(*filter.ActiveAt) {
+		return false
+	}
+	return true
+}
+
+// newSilenceRow copies the columns of a silence set on insert and update
+func newSilenceRow(silence *models.Silence) *models.Silence {
+	return &models.Silence{
+		ID:         silence.ID,
+		DeviceID:   copyPtr(silence.DeviceID),
+		DeviceType: copyPtr(silence.DeviceType),
+		AlertType:  copyPtr(silence.AlertType),
+		StartsAt:   roundTime(silence.StartsAt),
+		EndsAt:     roundTime(silence.EndsAt),
+		CreatedBy:  silence.CreatedBy,
+		Comment:    silence.Comment,
+	}
+}
+
+func copySilence(silence *models.Silence) *models.Silence {
+	copied := *silence
+	copied.DeviceID = copyPtr(silence.DeviceID)
+	copied.DeviceType = copyPtr(silence.DeviceType)
+	copied.AlertType = copyPtr(silence.AlertType)
+	return &copied
+}
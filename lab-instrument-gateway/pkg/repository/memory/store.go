@@ -0,0 +1,228 @@
+package memory
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// store holds the tables. Rows are never changed in place: statements
+// replace them with changed copies, so that the undo log of a transaction
+// can restore the rows it replaced.
+type store struct {
+	devices      map[string]*models.Device
+	measurements map[string]*models.Measurement
+	commands     map[string]*models.Command
+	signatures   map[string]*models.CommandSignature
+	alerts       map[string]*models.Alert
+	silences     map[string]*models.Silence
+	policies     map[string]*models.EscalationPolicy
+	routes       map[string]*models.AlertRoute
+	schedules    map[string]*models.OnCallSchedule
+	escalations  map[string]*models.AlertEscalation // by alert ID
+	events       map[string]*models.DeviceEvent
+	sessions     map[string]*models.DeviceSession
+	roles        map[string]*models.Role // by name
+	bindings     map[string]*models.RoleBinding
+	tokens       map[string]*models.EnrollmentToken
+	certificates map[string]*models.DeviceCertificate // by serial number
+	apiKeys      map[string]*models.APIKey
+	audit        []*models.AuditEntry // in sequence order
+
+	undo *undoLog // changes of the running transaction, if any
+}
+
+// newStore creates empty tables holding only the built-in roles, as the
+// migrations leave them
+func newStore() *store {
+	s := &store{
+		devices:      make(map[string]*models.Device),
+		measurements: make(map[string]*models.Measurement),
+		commands:     make(map[string]*models.Command),
+		signatures:   make(map[string]*models.CommandSignature),
+		alerts:       make(map[string]*models.Alert),
+		silences:     make(map[string]*models.Silence),
+		policies:     make(map[string]*models.EscalationPolicy),
+		routes:       make(map[string]*models.AlertRoute),
+		schedules:    make(map[string]*models.OnCallSchedule),
+		escalations:  make(map[string]*models.AlertEscalation),
+		events:       make(map[string]*models.DeviceEvent),
+		sessions:     make(map[string]*models.DeviceSession),
+		roles:        make(map[string]*models.Role),
+		bindings:     make(map[string]*models.RoleBinding),
+		tokens:       make(map[string]*models.EnrollmentToken),
+		certificates: make(map[string]*models.DeviceCertificate),
+		apiKeys:      make(map[string]*models.APIKey),
+	}
+
+	now := roundTime(time.Now())
+	for _, role := range builtInRoles() {
+		role.BuiltIn = true
+		role.CreatedAt = now
+		role.UpdatedAt = now
+		s.roles[role.Name] = role
+	}
+
+	return s
+}
+
+// builtInRoles returns the roles seeded by the migrations
+func builtInRoles() []*models.Role {
+	return []*models.Role{
+		{
+			Name:        models.RoleViewer,
+			Description: "Read devices, measurements and alerts",
+			Permissions: []models.Permission{
+				models.PermissionDevicesRead,
+				models.PermissionMeasurementsRead,
+				models.PermissionAlertsRead,
+			},
+		},
+		{
+			Name:        models.RoleOperator,
+			Description: "Viewer permissions and sending commands",
+			Permissions: []models.Permission{
+				models.PermissionDevicesRead,
+				models.PermissionMeasurementsRead,
+				models.PermissionAlertsRead,
+				models.PermissionCommandsSend,
+				models.PermissionCommandsSign,
+			},
+		},
+		{
+			Name:        models.RoleAdmin,
+			Description: "Full access, including device registration, alert management and access control",
+			Permissions: []models.Permission{
+				models.PermissionDevicesRead,
+				models.PermissionDevicesWrite,
+				models.PermissionMeasurementsRead,
+				models.PermissionMeasurementsWrite,
+				models.PermissionCommandsSend,
+				models.PermissionAlertsRead,
+				models.PermissionAlertsManage,
+				models.PermissionAccessManage,
+				models.PermissionAuditRead,
+				models.PermissionCommandsSign,
+			},
+		},
+		{
+			Name:        models.RoleDevice,
+			Description: "Granted to instruments authenticated by client certificate",
+			Permissions: []models.Permission{
+				models.PermissionDevicesWrite,
+				models.PermissionMeasurementsWrite,
+			},
+		},
+	}
+}
+
+// undoLog records how to revert each change a transaction makes to the
+// tables, so that a failed transaction only costs the rows it changed
+type undoLog struct {
+	steps []func()
+}
+
+// rollback reverts the recorded changes, latest first
+func (u *undoLog) rollback() {
+	for i := len(u.steps) - 1; i >= 0; i-- {
+		u.steps[i]()
+	}
+	u.steps = nil
+}
+
+// record adds the step reverting a change to the running transaction, if any
+func (s *store) record(step func()) {
+	if s.undo != nil {
+		s.undo.steps = append(s.undo.steps, step)
+	}
+}
+
+// put stores a row under a key of a table
+func put[K comparable, V any](s *store, table map[K]V, key K, row V) {
+	if previous, ok := table[key]; ok {
+		s.record(func() { table[key] = previous })
+	} else {
+		s.record(func() { delete(table, key) })
+	}
+	table[key] = row
+}
+
+// remove deletes the row under a key of a table, if there is one
+func remove[K comparable, V any](s *store, table map[K]V, key K) {
+	previous, ok := table[key]
+	if !ok {
+		return
+	}
+	s.record(func() { table[key] = previous })
+	delete(table, key)
+}
+
+// appendAudit appends an entry to the audit log
+func (s *store) appendAudit(entry *models.AuditEntry) {
+	length := len(s.audit)
+	s.record(func() { s.audit = slices.Clip(s.audit[:length]) })
+	s.audit = append(s.audit, entry)
+}
+
+// deleteDevice deletes a device together with the rows referencing it
+func (s *store) deleteDevice(id string) {
+	remove(s, s.devices, id)
+
+	deleteWhere(s, s.measurements, func(m *models.Measurement) bool { return m.DeviceID == id })
+	deleteWhere(s, s.sessions, func(session *models.DeviceSession) bool { return session.DeviceID == id })
+	deleteWhere(s, s.events, func(e *models.DeviceEvent) bool { return e.DeviceID == id })
+	deleteWhere(s, s.certificates, func(c *models.DeviceCertificate) bool { return c.DeviceID == id })
+	deleteWhere(s, s.silences, func(silence *models.Silence) bool { return equalPtr(silence.DeviceID, id) })
+	deleteWhere(s, s.apiKeys, func(k *models.APIKey) bool { return equalPtr(k.DeviceID, id) })
+
+	for _, command := range s.commands {
+		if command.DeviceID == id {
+			s.deleteCommand(command.ID)
+		}
+	}
+	for _, alert := range s.alerts {
+		if equalPtr(alert.DeviceID, id) {
+			s.deleteAlert(alert.ID)
+		}
+	}
+}
+
+// deleteCommand deletes a command together with its signatures
+func (s *store) deleteCommand(id string) {
+	remove(s, s.commands, id)
+	deleteWhere(s, s.signatures, func(signature *models.CommandSignature) bool { return signature.CommandID == id })
+}
+
+// deleteAlert deletes an alert together with its escalation progress
+func (s *store) deleteAlert(id string) {
+	remove(s, s.alerts, id)
+	remove(s, s.escalations, id)
+}
+
+// deleteWhere deletes the rows matching a condition, returning how many
+func deleteWhere[K comparable, V any](s *store, table map[K]V, match func(V) bool) int64 {
+	var deleted int64
+	for key, row := range table {
+		if match(row) {
+			remove(s, table, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// rows returns the rows of a table matching a condition, ordered by key so
+// that rows comparing equal under a sort keep a stable order
+func rows[V any](table map[string]V, match func(V) bool) []V {
+	keys := slices.Sorted(maps.Keys(table))
+
+	var result []V
+	for _, key := range keys {
+		if row := table[key]; match == nil || match(row) {
+			result = append(result, row)
+		}
+	}
+	return result
+}
@@ -0,0 +1,1273 @@
+// Package repositorytest checks that an implementation of
+// repository.RepositoryManager behaves as the PostgreSQL implementation does.
+//
+// The checks create their own uniquely named rows and only look at those, so
+// they can run against a database that already holds data.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// Run runs the conformance checks, calling newManager for the repository
+// manager under test in each of them
+func Run(t *testing.T, newManager func(t *testing.T) repository.RepositoryManager) {
+	checks := []struct {
+		name  string
+		check func(t *testing.T, f *fixture)
+	}{
+		{"Device", testDevice},
+		{"DeviceList", testDeviceList},
+		{"DeviceQueries", testDeviceQueries},
+		{"Measurement", testMeasurement},
+		{"MeasurementList", testMeasurementList},
+		{"MeasurementAggregation", testMeasurementAggregation},
+		{"Command", testCommand},
+		{"CommandQueue", testCommandQueue},
+		{"Alert", testAlert},
+		{"AlertList", testAlertList},
+		{"Silence", testSilence},
+		{"Escalation", testEscalation},
+		{"DeviceHistory", testDeviceHistory},
+		{"Role", testRole},
+		{"Credentials", testCredentials},
+		{"Audit", testAudit},
+		{"Transaction", testTransaction},
+	}
+
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			c.check(t, &fixture{
+				ctx:    context.Background(),
+				repos:  newManager(t),
+				suffix: uuid.New().String()[:8],
+				now:    time.Now().Truncate(time.Second),
+			})
+		})
+	}
+}
+
+// fixture creates the rows a check works with. Names carry a random suffix
+// so that checks never see rows of other checks or of earlier runs.
+type fixture struct {
+	ctx    context.Context
+	repos  repository.RepositoryManager
+	suffix string
+	now    time.Time
+}
+
+// name returns a name unique to the check
+func (f *fixture) name(prefix string) string {
+	return fmt.Sprintf("conformance-%s-%s", prefix, f.suffix)
+}
+
+// device creates a device of a type unique to the check
+func (f *fixture) device(t *testing.T, name string, configure ...func(d *models.Device)) *models.Device {
+	t.Helper()
+
+	device := &models.Device{
+		ID:           f.name(name),
+		Name:         name,
+		Type:         f.name("type"),
+		Version:      "1.0.0",
+		Status:       models.DeviceStatusOnline,
+		Capabilities: pq.StringArray{"temperature"},
+		Metadata:     map[string]interface{}{"location": "lab-1"},
+	}
+	for _, apply := range configure {
+		apply(device)
+	}
+
+	require.NoError(t, f.repos.Device().Create(f.ctx, device))
+	return device
+}
+
+// measurement creates a measurement for a device at an offset from the
+// start of the current hour
+func (f *fixture) measurement(deviceID, measurementType string, offset time.Duration, value float64, quality models.QualityCode) *models.Measurement {
+	return &models.Measurement{
+		ID:        uuid.New().String(),
+		DeviceID:  deviceID,
+		Timestamp: f.now.Truncate(time.Hour).Add(offset),
+		Type:      measurementType,
+		Value:     value,
+		Unit:      "celsius",
+		Quality:   quality,
+	}
+}
+
+// command creates a pending command for a device
+func (f *fixture) command(t *testing.T, deviceID string, priority int, configure ...func(c *models.Command)) *models.Command {
+	t.Helper()
+
+	command := &models.Command{
+		ID:             uuid.New().String(),
+		DeviceID:       deviceID,
+		CommandID:      uuid.New().String(),
+		Type:           "start_run",
+		Parameters:     map[string]interface{}{"method": "gradient-a", "runs": 3},
+		Status:         models.CommandStatusPending,
+		Priority:       priority,
+		TimeoutSeconds: 60,
+	}
+	for _, apply := range configure {
+		apply(command)
+	}
+
+	require.NoError(t, f.repos.Command().Create(f.ctx, command))
+	return command
+}
+
+// alert creates an alert for a device
+func (f *fixture) alert(t *testing.T, deviceID string, severity models.AlertSeverity, createdAt time.Time) *models.Alert {
+	t.Helper()
+
+	alert := &models.Alert{
+		ID:        uuid.New().String(),
+		DeviceID:  &deviceID,
+		Type:      models.AlertTypeDeviceError,
+		Severity:  severity,
+		Message:   fmt.Sprintf("%s alert", severity),
+		Metadata:  map[string]interface{}{"code": 42},
+		CreatedAt: createdAt,
+	}
+
+	require.NoError(t, f.repos.Alert().Create(f.ctx, alert))
+	return alert
+}
+
+// requireNotFound fails the check unless err reports a missing row
+func requireNotFound(t *testing.T, err error) {
+	t.Helper()
+
+	require.Error(t, err)
+	require.True(t, errors.Is(err, repository.ErrNotFound), "expected ErrNotFound, got: %v", err)
+}
+
+// ids returns the IDs of rows in order, keeping only those in want when it
+// is not nil, so that listings can be compared without rows of other checks
+func ids[T any](rows []T, id func(T) string, want []string) []string {
+	result := []string{}
+	for _, row := range rows {
+		if want == nil || slices.Contains(want, id(row)) {
+			result = append(result, id(row))
+		}
+	}
+	return result
+}
+
+func deviceID(d *models.Device) string           { return d.ID }
+func measurementID(m *models.Measurement) string { return m.ID }
+func commandID(c *models.Command) string         { return c.ID }
+func alertID(a *models.Alert) string             { return a.ID }
+
+func testDevice(t *testing.T, f *fixture) {
+	repo := f.repos.Device()
+	lastSeen := f.now.Add(-time.Minute)
+	device := f.device(t, "hplc", func(d *models.Device) {
+		d.Capabilities = pq.StringArray{"temperature", "pressure"}
+		d.Metadata = map[string]interface{}{"location": "lab-1", "channels": 4}
+		d.LastSeen = &lastSeen
+	})
+
+	got, err := repo.GetByID(f.ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hplc", got.Name)
+	assert.Equal(t, device.Type, got.Type)
+	assert.Equal(t, models.DeviceStatusOnline, got.Status)
+	assert.Equal(t, pq.StringArray{"temperature", "pressure"}, got.Capabilities)
+	assert.Equal(t, map[string]interface{}{"location": "lab-1", "channels": float64(4)}, got.Metadata)
+	require.NotNil(t, got.LastSeen)
+	assert.True(t, lastSeen.Equal(*got.LastSeen))
+	assert.True(t, device.RegisteredAt.Round(time.Microsecond).Equal(got.RegisteredAt))
+
+	assert.Error(t, repo.Create(f.ctx, device), "duplicate device ID")
+	assert.Error(t, repo.Create(f.ctx, &models.Device{ID: f.name("invalid")}), "device without a name")
+
+	got.Name = "hplc-renamed"
+	got.Status = models.DeviceStatusMaintenance
+	got.Metadata = map[string]interface{}{"location": "lab-2"}
+	require.NoError(t, repo.Update(f.ctx, got))
+
+	updated, err := repo.GetByID(f.ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hplc-renamed", updated.Name)
+	assert.Equal(t, models.DeviceStatusMaintenance, updated.Status)
+	assert.Equal(t, map[string]interface{}{"location": "lab-2"}, updated.Metadata)
+	assert.True(t, got.RegisteredAt.Equal(updated.RegisteredAt))
+
+	require.NoError(t, repo.UpdateStatus(f.ctx, device.ID, models.DeviceStatusOffline))
+	seen := f.now
+	require.NoError(t, repo.UpdateLastSeen(f.ctx, device.ID, seen))
+	updated, err = repo.GetByID(f.ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeviceStatusOffline, updated.Status)
+	require.NotNil(t, updated.LastSeen)
+	assert.True(t, seen.Equal(*updated.LastSeen))
+
+	missing := f.name("missing")
+	_, err = repo.GetByID(f.ctx, missing)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.Update(f.ctx, &models.Device{ID: missing, Name: "missing", Type: "sensor", Version: "1", Status: models.DeviceStatusOnline}))
+	requireNotFound(t, repo.UpdateStatus(f.ctx, missing, models.DeviceStatusOnline))
+	requireNotFound(t, repo.UpdateLastSeen(f.ctx, missing, f.now))
+
+	// Deleting a device deletes what was recorded for it
+	measurement := f.measurement(device.ID, "temperature", time.Minute, 21.5, models.QualityGood)
+	require.NoError(t, f.repos.Measurement().Create(f.ctx, measurement))
+	command := f.command(t, device.ID, 1)
+	alert := f.alert(t, device.ID, models.AlertSeverityWarning, f.now)
+
+	require.NoError(t, repo.Delete(f.ctx, device.ID))
+	_, err = repo.GetByID(f.ctx, device.ID)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.Delete(f.ctx, device.ID))
+
+	_, err = f.repos.Measurement().GetByID(f.ctx, measurement.ID)
+	requireNotFound(t, err)
+	_, err = f.repos.Command().GetByID(f.ctx, command.ID)
+	requireNotFound(t, err)
+	_, err = f.repos.Alert().GetByID(f.ctx, alert.ID)
+	requireNotFound(t, err)
+}
+
+func testDeviceList(t *testing.T, f *fixture) {
+	repo := f.repos.Device()
+	b := f.device(t, "b", func(d *models.Device) {
+		d.CreatedAt = f.now.Add(-3 * time.Minute)
+		d.Capabilities = pq.StringArray{"temperature", "pressure"}
+		d.Metadata = map[string]interface{}{"location": "lab-1", "device_group": "chemistry"}
+	})
+	a := f.device(t, "a", func(d *models.Device) {
+		d.CreatedAt = f.now.Add(-2 * time.Minute)
+		d.Status = models.DeviceStatusOffline
+		d.Metadata = map[string]interface{}{"location": "lab-2", "device_group": "biology"}
+	})
+	c := f.device(t, "c", func(d *models.Device) {
+		d.CreatedAt = f.now.Add(-time.Minute)
+		d.Status = models.DeviceStatusError
+		d.Metadata = map[string]interface{}{"location": "lab-1", "device_group": "chemistry", "channels": 4}
+	})
+
+	list := func(filter repository.DeviceFilter) []string {
+		t.Helper()
+		filter.Types = []string{b.Type}
+		devices, err := repo.List(f.ctx, filter)
+		require.NoError(t, err)
+		return ids(devices, deviceID, nil)
+	}
+	count := func(filter repository.DeviceFilter) int64 {
+		t.Helper()
+		filter.Types = []string{b.Type}
+		n, err := repo.Count(f.ctx, filter)
+		require.NoError(t, err)
+		return n
+	}
+
+	// Newest first by default
+	assert.Equal(t, []string{c.ID, a.ID, b.ID}, list(repository.DeviceFilter{}))
+	assert.Equal(t, []string{a.ID, b.ID, c.ID}, list(repository.DeviceFilter{Filter: repository.Filter{SortBy: "name", Order: "asc"}}))
+	assert.Equal(t, []string{c.ID, b.ID, a.ID}, list(repository.DeviceFilter{Filter: repository.Filter{SortBy: "name", Order: "DESC"}}))
+	assert.Equal(t, []string{b.ID, c.ID}, list(repository.DeviceFilter{Filter: repository.Filter{SortBy: "name", Order: "ASC", Limit: 2, Offset: 1}}))
+
+	// Statuses sort in declaration order: online, offline, error
+	assert.Equal(t, []string{b.ID, a.ID, c.ID}, list(repository.DeviceFilter{Filter: repository.Filter{SortBy: "status", Order: "ASC"}}))
+
+	filter := repository.DeviceFilter{Statuses: []models.DeviceStatus{models.DeviceStatusOnline, models.DeviceStatusError}}
+	assert.ElementsMatch(t, []string{b.ID, c.ID}, list(filter))
+	assert.Equal(t, int64(2), count(filter))
+
+	filter = repository.DeviceFilter{DeviceIDs: []string{a.ID, c.ID}}
+	assert.ElementsMatch(t, []string{a.ID, c.ID}, list(filter))
+
+	filter = repository.DeviceFilter{Capabilities: []string{"temperature", "pressure"}}
+	assert.Equal(t, []string{b.ID}, list(filter))
+
+	filter = repository.DeviceFilter{MetadataFilters: map[string]interface{}{"location": "lab-1", "channels": 4}}
+	assert.Equal(t, []string{c.ID}, list(filter))
+
+	filter = repository.DeviceFilter{Groups: []string{"chemistry"}}
+	assert.ElementsMatch(t, []string{b.ID, c.ID}, list(filter))
+	assert.Equal(t, int64(2), count(filter))
+
+	assert.Equal(t, int64(3), count(repository.DeviceFilter{}))
+	assert.Empty(t, list(repository.DeviceFilter{Filter: repository.Filter{Offset: 5}}))
+}
+
+func testDeviceQueries(t *testing.T, f *fixture) {
+	repo := f.repos.Device()
+	capability := f.name("capability")
+	recent, earlier := f.now.Add(-time.Minute), f.now.Add(-2*time.Minute)
+	stale := f.now.Add(-time.Hour)
+
+	online := f.device(t, "online", func(d *models.Device) {
+		d.Capabilities = pq.StringArray{capability}
+		d.Metadata = map[string]interface{}{"owner": f.suffix}
+		d.LastSeen = &recent
+	})
+	onlineEarlier := f.device(t, "online-earlier", func(d *models.Device) {
+		d.LastSeen = &earlier
+	})
+	offline := f.device(t, "offline", func(d *models.Device) {
+		d.Status = models.DeviceStatusOffline
+		d.Capabilities = pq.StringArray{capability, "pressure"}
+		d.LastSeen = &stale
+	})
+	neverSeen := f.device(t, "never-seen", func(d *models.Device) {
+		d.Status = models.DeviceStatusOffline
+	})
+	maintenance := f.device(t, "maintenance", func(d *models.Device) {
+		d.Status = models.DeviceStatusMaintenance
+	})
+	ours := []string{online.ID, onlineEarlier.ID, offline.ID, neverSeen.ID, maintenance.ID}
+
+	devices, err := repo.GetByCapability(f.ctx, capability)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{online.ID, offline.ID}, ids(devices, deviceID, nil))
+
+	devices, err = repo.SearchByMetadata(f.ctx, map[string]interface{}{"owner": f.suffix})
+	require.NoError(t, err)
+	assert.Equal(t, []string{online.ID}, ids(devices, deviceID, nil))
+
+	devices, err = repo.GetOnlineDevices(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{online.ID, onlineEarlier.ID}, ids(devices, deviceID, ours))
+
+	// Devices never seen come first, devices under maintenance are left out
+	devices, err = repo.GetOfflineDevices(f.ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{neverSeen.ID, offline.ID}, ids(devices, deviceID, ours))
+
+	counts, err := repo.GetStatusCounts(f.ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, counts[models.DeviceStatusOnline], int64(2))
+	assert.GreaterOrEqual(t, counts[models.DeviceStatusOffline], int64(2))
+	assert.GreaterOrEqual(t, counts[models.DeviceStatusMaintenance], int64(1))
+
+	result, err := repo.CreateBulk(f.ctx, []*models.Device{
+		{ID: f.name("bulk-1"), Name: "bulk-1", Type: "sensor", Version: "1", Status: models.DeviceStatusOnline},
+		{ID: f.name("bulk-2"), Type: "sensor", Version: "1", Status: models.DeviceStatusOnline},
+		{ID: f.name("bulk-3"), Name: "bulk-3", Type: "sensor", Version: "1", Status: models.DeviceStatusOnline},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.SuccessCount)
+	assert.Equal(t, 1, result.FailureCount)
+	assert.Len(t, result.Errors, 1)
+
+	_, err = repo.GetByID(f.ctx, f.name("bulk-3"))
+	require.NoError(t, err)
+	_, err = repo.GetByID(f.ctx, f.name("bulk-2"))
+	requireNotFound(t, err)
+}
+
+func testMeasurement(t *testing.T, f *fixture) {
+	repo := f.repos.Measurement()
+	device := f.device(t, "balance")
+
+	batchID := uuid.New().String()
+	measurement := f.measurement(device.ID, "mass", 5*time.Minute, 12.25, models.QualityGood)
+	measurement.BatchID = &batchID
+	measurement.Metadata = map[string]interface{}{"calibrated": true, "drift": 0.5}
+	require.NoError(t, repo.Create(f.ctx, measurement))
+
+	got, err := repo.GetByID(f.ctx, measurement.ID)
+	require.NoError(t, err)
+	assert.Equal(t, device.ID, got.DeviceID)
+	assert.Equal(t, "mass", got.Type)
+	assert.Equal(t, 12.25, got.Value)
+	assert.Equal(t, "celsius", got.Unit)
+	assert.Equal(t, models.QualityGood, got.Quality)
+	assert.True(t, measurement.Timestamp.Equal(got.Timestamp))
+	require.NotNil(t, got.BatchID)
+	assert.Equal(t, batchID, *got.BatchID)
+	assert.Equal(t, map[string]interface{}{"calibrated": true, "drift": 0.5}, got.Metadata)
+
+	assert.Error(t, repo.Create(f.ctx, f.measurement(f.name("unknown"), "mass", time.Minute, 1, models.QualityGood)), "unknown device")
+
+	latest := f.measurement(device.ID, "mass", 10*time.Minute, 12.5, models.QualityGood)
+	require.NoError(t, repo.Create(f.ctx, latest))
+	got, err = repo.GetLatest(f.ctx, device.ID, "mass")
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, got.ID)
+
+	_, err = repo.GetLatest(f.ctx, device.ID, "volume")
+	requireNotFound(t, err)
+
+	require.NoError(t, repo.Delete(f.ctx, measurement.ID))
+	_, err = repo.GetByID(f.ctx, measurement.ID)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.Delete(f.ctx, measurement.ID))
+
+	// Invalid measurements are skipped, the others are stored
+	result, err := repo.CreateBulk(f.ctx, []*models.Measurement{
+		f.measurement(device.ID, "mass", 20*time.Minute, 13, models.QualityGood),
+		f.measurement(device.ID, "", 21*time.Minute, 13, models.QualityGood),
+		f.measurement(device.ID, "mass", 22*time.Minute, 13, models.QualityGood),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.SuccessCount)
+	assert.Equal(t, 1, result.FailureCount)
+
+	deleted, err := repo.DeleteByDevice(f.ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
+
+func testMeasurementList(t *testing.T, f *fixture) {
+	repo := f.repos.Measurement()
+	device := f.device(t, "reader")
+	other := f.device(t, "other-reader")
+
+	m1 := f.measurement(device.ID, "temperature", 1*time.Minute, 20, models.QualityGood)
+	m2 := f.measurement(device.ID, "humidity", 2*time.Minute, 45, models.QualityBad)
+	m3 := f.measurement(device.ID, "temperature", 3*time.Minute, 22, models.QualityUncertain)
+	m4 := f.measurement(other.ID, "temperature", 4*time.Minute, 30, models.QualityGood)
+	result, err := repo.CreateBulk(f.ctx, []*models.Measurement{m1, m2, m3, m4})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.SuccessCount)
+
+	list := func(filter repository.MeasurementFilter) []string {
+		t.Helper()
+		if filter.DeviceIDs == nil {
+			filter.DeviceIDs = []string{device.ID}
+		}
+		measurements, err := repo.List(f.ctx, filter)
+		require.NoError(t, err)
+		return ids(measurements, measurementID, nil)
+	}
+
+	// Newest first by default
+	assert.Equal(t, []string{m3.ID, m2.ID, m1.ID}, list(repository.MeasurementFilter{}))
+	assert.Equal(t, []string{m1.ID, m3.ID, m2.ID}, list(repository.MeasurementFilter{Filter: repository.Filter{SortBy: "value", Order: "ASC"}}))
+	assert.Equal(t, []string{m2.ID}, list(repository.MeasurementFilter{Filter: repository.Filter{Limit: 1, Offset: 1}}))
+	assert.Equal(t, []string{m4.ID, m3.ID, m2.ID, m1.ID}, list(repository.MeasurementFilter{DeviceIDs: []string{device.ID, other.ID}}))
+
+	assert.Equal(t, []string{m3.ID, m1.ID}, list(repository.MeasurementFilter{Types: []string{"temperature"}}))
+	assert.Equal(t, []string{m2.ID}, list(repository.MeasurementFilter{Qualities: []models.QualityCode{models.QualityBad}}))
+
+	// Time ranges include both ends
+	start, end := m1.Timestamp, m2.Timestamp
+	timeRange := repository.TimeRangeFilter{StartTime: &start, EndTime: &end}
+	assert.Equal(t, []string{m2.ID, m1.ID}, list(repository.MeasurementFilter{TimeRangeFilter: timeRange}))
+
+	count, err := repo.Count(f.ctx, repository.MeasurementFilter{DeviceIDs: []string{device.ID}, Types: []string{"temperature"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	measurements, err := repo.GetByTimeRange(f.ctx, device.ID, m1.Timestamp, m3.Timestamp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{m1.ID, m2.ID, m3.ID}, ids(measurements, measurementID, nil))
+
+	// The latest measurement of each type, ordered by type
+	measurements, err = repo.GetLatestByDevice(f.ctx, device.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{m2.ID, m3.ID}, ids(measurements, measurementID, nil))
+
+	measurements, err = repo.GetLatestByDevice(f.ctx, device.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{m2.ID}, ids(measurements, measurementID, nil))
+}
+
+func testMeasurementAggregation(t *testing.T, f *fixture) {
+	repo := f.repos.Measurement()
+	device := f.device(t, "sensor")
+
+	hour := f.now.Truncate(time.Hour)
+	var measurements []*models.Measurement
+	for i, value := range []float64{10, 20, 30, 40} {
+		quality := models.QualityGood
+		if i == 3 {
+			quality = models.QualityBad
+		}
+		measurements = append(measurements, f.measurement(device.ID, "pressure", time.Duration(i+1)*time.Minute, value, quality))
+	}
+	// One measurement in the next hour
+	measurements = append(measurements, f.measurement(device.ID, "pressure", time.Hour+time.Minute, 100, models.QualityUncertain))
+	result, err := repo.CreateBulk(f.ctx, measurements)
+	require.NoError(t, err)
+	require.Equal(t, 5, result.SuccessCount)
+
+	start, end := hour, hour.Add(2*time.Hour)
+	for _, tc := range []struct {
+		function string
+		want     float64
+	}{
+		{"", 25},
+		{"avg", 25},
+		{"min", 10},
+		{"max", 40},
+		{"sum", 100},
+		{"count", 4},
+	} {
+		t.Run("function="+tc.function, func(t *testing.T) {
+			results, err := repo.Aggregate(f.ctx, repository.AggregationRequest{
+				DeviceIDs:       []string{device.ID},
+				Types:           []string{"pressure"},
+				TimeRange:       repository.TimeRangeFilter{StartTime: &start, EndTime: &end},
+				GroupByInterval: time.Hour,
+				AggregationType: tc.function,
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+
+			// The latest hour comes first
+			assert.True(t, hour.Add(time.Hour).Equal(results[0].Timestamp))
+			assert.Equal(t, int64(1), results[0].Count)
+			assert.True(t, hour.Equal(results[1].Timestamp))
+			assert.Equal(t, device.ID, results[1].DeviceID)
+			assert.Equal(t, "pressure", results[1].Type)
+			assert.InDelta(t, tc.want, results[1].Value, 1e-9)
+			assert.Equal(t, int64(4), results[1].Count)
+		})
+	}
+
+	stats, err := repo.GetStatistics(f.ctx, repository.MeasurementFilter{DeviceIDs: []string{device.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats.Count)
+	assert.InDelta(t, 10, stats.MinValue, 1e-9)
+	assert.InDelta(t, 100, stats.MaxValue, 1e-9)
+	assert.InDelta(t, 40, stats.AvgValue, 1e-9)
+	assert.True(t, measurements[0].Timestamp.Equal(stats.EarliestTime))
+	assert.True(t, measurements[4].Timestamp.Equal(stats.LatestTime))
+	assert.Equal(t, int64(3), stats.GoodQuality)
+	assert.Equal(t, int64(1), stats.BadQuality)
+
+	// No matching measurements yields empty statistics rather than an error
+	stats, err = repo.GetStatistics(f.ctx, repository.MeasurementFilter{DeviceIDs: []string{f.name("unknown")}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Count)
+	assert.Zero(t, stats.AvgValue)
+	assert.True(t, stats.EarliestTime.IsZero())
+}
+
+func testCommand(t *testing.T, f *fixture) {
+	repo := f.repos.Command()
+	device := f.device(t, "pump")
+
+	command := &models.Command{
+		ID:             uuid.New().String(),
+		DeviceID:       device.ID,
+		CommandID:      uuid.New().String(),
+		Type:           "set_flow",
+		Parameters:     map[string]interface{}{"rate": 1.5},
+		Status:         models.CommandStatusPending,
+		Priority:       5,
+		TimeoutSeconds: 30,
+		Signatures: []*models.CommandSignature{
+			{Signer: "bob", AuthMethod: "jwt", Meaning: models.SignatureMeaningApproved, ContentHash: "abc", SignedAt: f.now.Add(time.Second)},
+			{Signer: "alice", AuthMethod: "jwt", Meaning: models.SignatureMeaningAuthored, ContentHash: "abc", SignedAt: f.now, TokenID: "token-1"},
+		},
+	}
+	require.NoError(t, repo.Create(f.ctx, command))
+
+	got, err := repo.GetByID(f.ctx, command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, command.CommandID, got.CommandID)
+	assert.Equal(t, "set_flow", got.Type)
+	assert.Equal(t, map[string]interface{}{"rate": 1.5}, got.Parameters)
+	assert.Equal(t, models.CommandStatusPending, got.Status)
+	assert.Equal(t, 5, got.Priority)
+	assert.Equal(t, 30, got.TimeoutSeconds)
+
+	got, err = repo.GetByCommandID(f.ctx, command.CommandID)
+	require.NoError(t, err)
+	assert.Equal(t, command.ID, got.ID)
+
+	signatures, err := repo.ListSignatures(f.ctx, command.ID)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+	assert.Equal(t, "alice", signatures[0].Signer)
+	assert.Equal(t, "bob", signatures[1].Signer)
+	assert.Equal(t, command.ID, signatures[1].CommandID)
+	assert.Equal(t, models.SignatureMeaningApproved, signatures[1].Meaning)
+
+	duplicate := *command
+	duplicate.ID = uuid.New().String()
+	duplicate.Signatures = nil
+	assert.Error(t, repo.Create(f.ctx, &duplicate), "duplicate command ID")
+
+	// A signing token signs once per signer
+	used, err := repo.SignatureTokenUsed(f.ctx, "alice", "token-1")
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.SignatureTokenUsed(f.ctx, "bob", "token-1")
+	require.NoError(t, err)
+	assert.False(t, used)
+	replayed := *command
+	replayed.ID = uuid.New().String()
+	replayed.CommandID = uuid.New().String()
+	replayed.Signatures = []*models.CommandSignature{
+		{Signer: "alice", AuthMethod: "jwt", Meaning: models.SignatureMeaningApproved, ContentHash: "abc", SignedAt: f.now, TokenID: "token-1"},
+	}
+	assert.Error(t, repo.Create(f.ctx, &replayed), "reused signing token")
+	_, err = repo.GetByID(f.ctx, replayed.ID)
+	requireNotFound(t, err)
+
+	// A pending command is claimed once
+	unclaimed := *got
+	claimed, err := repo.Claim(f.ctx, got)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, models.CommandStatusExecuting, got.Status)
+	stored, err := repo.GetByID(f.ctx, command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusExecuting, stored.Status)
+	assert.NotNil(t, stored.ExecutedAt)
+	claimed, err = repo.Claim(f.ctx, &unclaimed)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, models.CommandStatusPending, unclaimed.Status)
+
+	message := "pump stalled"
+	executedAt := f.now
+	got.Status = models.CommandStatusFailed
+	got.ErrorMessage = &message
+	got.ExecutedAt = &executedAt
+	got.Result = map[string]interface{}{"code": 7}
+	require.NoError(t, repo.Update(f.ctx, got))
+
+	got, err = repo.GetByID(f.ctx, command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusFailed, got.Status)
+	require.NotNil(t, got.ErrorMessage)
+	assert.Equal(t, message, *got.ErrorMessage)
+	require.NotNil(t, got.ExecutedAt)
+	assert.True(t, executedAt.Equal(*got.ExecutedAt))
+	assert.Equal(t, map[string]interface{}{"code": float64(7)}, got.Result)
+
+	require.NoError(t, repo.UpdateStatus(f.ctx, command.CommandID, models.CommandStatusCompleted))
+	got, err = repo.GetByID(f.ctx, command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusCompleted, got.Status)
+
+	missing := uuid.New().String()
+	_, err = repo.GetByID(f.ctx, missing)
+	requireNotFound(t, err)
+	_, err = repo.GetByCommandID(f.ctx, missing)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.UpdateStatus(f.ctx, missing, models.CommandStatusCompleted))
+	missingCommand := *got
+	missingCommand.ID = missing
+	requireNotFound(t, repo.Update(f.ctx, &missingCommand))
+
+	require.NoError(t, repo.Delete(f.ctx, command.ID))
+	_, err = repo.GetByID(f.ctx, command.ID)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.Delete(f.ctx, command.ID))
+	signatures, err = repo.ListSignatures(f.ctx, command.ID)
+	require.NoError(t, err)
+	assert.Empty(t, signatures)
+}
+
+func testCommandQueue(t *testing.T, f *fixture) {
+	repo := f.repos.Command()
+	device := f.device(t, "autosampler")
+
+	at := func(offset time.Duration) func(c *models.Command) {
+		return func(c *models.Command) { c.CreatedAt = f.now.Add(offset) }
+	}
+	past := f.now.Add(-time.Minute)
+	future := f.now.Add(time.Hour)
+
+	low := f.command(t, device.ID, 1, at(-4*time.Minute))
+	highLater := f.command(t, device.ID, 9, at(-2*time.Minute), func(c *models.Command) { c.ExpiresAt = &future })
+	highEarlier := f.command(t, device.ID, 9, at(-3*time.Minute))
+	expired := f.command(t, device.ID, 5, at(-5*time.Minute), func(c *models.Command) { c.ExpiresAt = &past })
+	executing := f.command(t, device.ID, 1, at(-time.Minute), func(c *models.Command) { c.Status = models.CommandStatusExecuting })
+	ours := []string{low.ID, highLater.ID, highEarlier.ID, expired.ID, executing.ID}
+
+	// Highest priority first, then oldest first; expired commands are left out
+	commands, err := repo.GetPendingCommands(f.ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{highEarlier.ID, highLater.ID, low.ID}, ids(commands, commandID, nil))
+
+	commands, err = repo.GetExecutingCommands(f.ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{executing.ID}, ids(commands, commandID, nil))
+
+	list := func(filter repository.CommandFilter) []string {
+		t.Helper()
+		filter.DeviceIDs = []string{device.ID}
+		commands, err := repo.List(f.ctx, filter)
+		require.NoError(t, err)
+		return ids(commands, commandID, nil)
+	}
+	assert.Equal(t, []string{executing.ID, highLater.ID, highEarlier.ID, low.ID, expired.ID}, list(repository.CommandFilter{}))
+	assert.Equal(t, []string{expired.ID, low.ID}, list(repository.CommandFilter{Filter: repository.Filter{SortBy: "created_at", Order: "ASC", Limit: 2}}))
+	assert.ElementsMatch(t, []string{highLater.ID, highEarlier.ID}, list(repository.CommandFilter{Priorities: []int{9}}))
+	assert.Equal(t, []string{executing.ID}, list(repository.CommandFilter{Statuses: []models.CommandStatus{models.CommandStatusExecuting}}))
+
+	count, err := repo.Count(f.ctx, repository.CommandFilter{DeviceIDs: []string{device.ID}, Statuses: []models.CommandStatus{models.CommandStatusPending}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	commands, err = repo.GetExpiredCommands(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{expired.ID}, ids(commands, commandID, ours))
+
+	marked, err := repo.MarkExpiredAsTimeout(f.ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, marked, int64(1))
+
+	got, err := repo.GetByID(f.ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusTimeout, got.Status)
+	require.NotNil(t, got.ErrorMessage)
+	assert.Equal(t, "Command expired", *got.ErrorMessage)
+
+	stats, err := repo.GetCommandStats(f.ctx, device.ID, repository.TimeRangeFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, map[models.CommandStatus]int64{
+		models.CommandStatusPending:   3,
+		models.CommandStatusExecuting: 1,
+		models.CommandStatusTimeout:   1,
+	}, stats)
+
+	// Only finished commands are cleaned up
+	deleted, err := repo.DeleteCompletedOlderThan(f.ctx, f.now)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(1))
+	_, err = repo.GetByID(f.ctx, expired.ID)
+	requireNotFound(t, err)
+	_, err = repo.GetByID(f.ctx, low.ID)
+	require.NoError(t, err)
+}
+
+func testAlert(t *testing.T, f *fixture) {
+	repo := f.repos.Alert()
+	device := f.device(t, "incubator")
+
+	alert := f.alert(t, device.ID, models.AlertSeverityError, f.now)
+	got, err := repo.GetByID(f.ctx, alert.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.DeviceID)
+	assert.Equal(t, device.ID, *got.DeviceID)
+	assert.Equal(t, models.AlertTypeDeviceError, got.Type)
+	assert.Equal(t, models.AlertSeverityError, got.Severity)
+	assert.Equal(t, "error alert", got.Message)
+	assert.Equal(t, map[string]interface{}{"code": float64(42)}, got.Metadata)
+	assert.False(t, got.Acknowledged)
+	assert.Nil(t, got.ResolvedAt)
+	assert.True(t, f.now.Equal(got.CreatedAt))
+
+	unknown := f.name("unknown")
+	assert.Error(t, repo.Create(f.ctx, &models.Alert{
+		ID: uuid.New().String(), DeviceID: &unknown, Type: models.AlertTypeDeviceError, Severity: models.AlertSeverityInfo, Message: "unknown device",
+	}), "unknown device")
+
+	require.NoError(t, repo.Acknowledge(f.ctx, alert.ID, "alice"))
+	requireNotFound(t, repo.Acknowledge(f.ctx, alert.ID, "bob"))
+	require.NoError(t, repo.Resolve(f.ctx, alert.ID))
+	requireNotFound(t, repo.Resolve(f.ctx, alert.ID))
+
+	got, err = repo.GetByID(f.ctx, alert.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Acknowledged)
+	require.NotNil(t, got.AcknowledgedBy)
+	assert.Equal(t, "alice", *got.AcknowledgedBy)
+	assert.NotNil(t, got.AcknowledgedAt)
+	assert.NotNil(t, got.ResolvedAt)
+
+	silencedBy := uuid.New().String()
+	got.Message = "updated"
+	got.Silenced = true
+	got.SilencedBy = &silencedBy
+	require.NoError(t, repo.Update(f.ctx, got))
+	got, err = repo.GetByID(f.ctx, alert.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", got.Message)
+	assert.True(t, got.Silenced)
+
+	missing := uuid.New().String()
+	_, err = repo.GetByID(f.ctx, missing)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.Acknowledge(f.ctx, missing, "alice"))
+	requireNotFound(t, repo.Resolve(f.ctx, missing))
+	got.ID = missing
+	requireNotFound(t, repo.Update(f.ctx, got))
+
+	require.NoError(t, repo.Delete(f.ctx, alert.ID))
+	_, err = repo.GetByID(f.ctx, alert.ID)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.Delete(f.ctx, alert.ID))
+}
+
+func testAlertList(t *testing.T, f *fixture) {
+	repo := f.repos.Alert()
+	device := f.device(t, "freezer")
+
+	critical := f.alert(t, device.ID, models.AlertSeverityCritical, f.now.Add(-4*time.Minute))
+	warning := f.alert(t, device.ID, models.AlertSeverityWarning, f.now.Add(-3*time.Minute))
+	info := f.alert(t, device.ID, models.AlertSeverityInfo, f.now.Add(-2*time.Minute))
+	resolved := f.alert(t, device.ID, models.AlertSeverityCritical, f.now.Add(-time.Minute))
+	require.NoError(t, repo.Acknowledge(f.ctx, info.ID, "alice"))
+	require.NoError(t, repo.Resolve(f.ctx, resolved.ID))
+	ours := []string{critical.ID, warning.ID, info.ID, resolved.ID}
+
+	list := func(filter repository.AlertFilter) []string {
+		t.Helper()
+		filter.DeviceIDs = []string{device.ID}
+		alerts, err := repo.List(f.ctx, filter)
+		require.NoError(t, err)
+		return ids(alerts, alertID, nil)
+	}
+	yes, no := true, false
+
+	assert.Equal(t, []string{resolved.ID, info.ID, warning.ID, critical.ID}, list(repository.AlertFilter{}))
+	assert.Equal(t, []string{critical.ID, warning.ID}, list(repository.AlertFilter{Filter: repository.Filter{Order: "ASC", Limit: 2}}))
+	assert.Equal(t, []string{info.ID}, list(repository.AlertFilter{Acknowledged: &yes}))
+	assert.Equal(t, []string{resolved.ID}, list(repository.AlertFilter{Resolved: &yes}))
+	assert.Equal(t, []string{info.ID, warning.ID, critical.ID}, list(repository.AlertFilter{Resolved: &no}))
+	assert.Equal(t, []string{resolved.ID, critical.ID}, list(repository.AlertFilter{Severities: []models.AlertSeverity{models.AlertSeverityCritical}}))
+	assert.Empty(t, list(repository.AlertFilter{Silenced: &yes}))
+
+	start, end := critical.CreatedAt, warning.CreatedAt
+	assert.Equal(t, []string{warning.ID, critical.ID}, list(repository.AlertFilter{TimeRangeFilter: repository.TimeRangeFilter{StartTime: &start, EndTime: &end}}))
+
+	count, err := repo.Count(f.ctx, repository.AlertFilter{DeviceIDs: []string{device.ID}, Resolved: &no})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// Severities are stored as text and sort alphabetically, descending
+	alerts, err := repo.GetUnacknowledged(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{warning.ID, resolved.ID, critical.ID}, ids(alerts, alertID, ours))
+
+	alerts, err = repo.GetUnresolved(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{warning.ID, info.ID, critical.ID}, ids(alerts, alertID, ours))
+
+	alerts, err = repo.GetCriticalAlerts(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{critical.ID}, ids(alerts, alertID, ours))
+
+	alerts, err = repo.GetAlertsByDevice(f.ctx, device.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{resolved.ID, info.ID}, ids(alerts, alertID, nil))
+
+	stats, err := repo.GetAlertStats(f.ctx, repository.TimeRangeFilter{StartTime: &start, EndTime: &end})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, stats[models.AlertSeverityCritical], int64(1))
+	assert.GreaterOrEqual(t, stats[models.AlertSeverityWarning], int64(1))
+
+	deleted, err := repo.DeleteResolvedOlderThan(f.ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(1))
+	_, err = repo.GetByID(f.ctx, resolved.ID)
+	requireNotFound(t, err)
+	_, err = repo.GetByID(f.ctx, critical.ID)
+	require.NoError(t, err)
+}
+
+func testSilence(t *testing.T, f *fixture) {
+	repo := f.repos.Silence()
+	device := f.device(t, "centrifuge")
+	createdBy := f.name("operator")
+
+	active := &models.Silence{DeviceID: &device.ID, StartsAt: f.now.Add(-time.Hour), EndsAt: f.now.Add(time.Hour), CreatedBy: createdBy, Comment: "maintenance"}
+	upcoming := &models.Silence{DeviceID: &device.ID, StartsAt: f.now.Add(time.Hour), EndsAt: f.now.Add(2 * time.Hour), CreatedBy: createdBy}
+	require.NoError(t, repo.Create(f.ctx, active))
+	require.NoError(t, repo.Create(f.ctx, upcoming))
+	assert.Error(t, repo.Create(f.ctx, &models.Silence{StartsAt: f.now, EndsAt: f.now.Add(time.Hour), CreatedBy: createdBy}), "silence without matchers")
+
+	got, err := repo.GetByID(f.ctx, active.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "maintenance", got.Comment)
+	assert.True(t, active.StartsAt.Equal(got.StartsAt))
+
+	silenceIDs := func(silences []*models.Silence, want []string) []string {
+		return ids(silences, func(s *models.Silence) string { return s.ID }, want)
+	}
+
+	silences, err := repo.List(f.ctx, repository.SilenceFilter{CreatedBy: &createdBy})
+	require.NoError(t, err)
+	assert.Equal(t, []string{upcoming.ID, active.ID}, silenceIDs(silences, nil))
+
+	now := f.now
+	silences, err = repo.List(f.ctx, repository.SilenceFilter{DeviceIDs: []string{device.ID}, ActiveAt: &now})
+	require.NoError(t, err)
+	assert.Equal(t, []string{active.ID}, silenceIDs(silences, nil))
+
+	count, err := repo.Count(f.ctx, repository.SilenceFilter{CreatedBy: &createdBy})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	silences, err = repo.GetActive(f.ctx, f.now.Add(90*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []string{upcoming.ID}, silenceIDs(silences, []string{active.ID, upcoming.ID}))
+
+	got.Comment = "extended"
+	got.EndsAt = f.now.Add(3 * time.Hour)
+	require.NoError(t, repo.Update(f.ctx, got))
+	got, err = repo.GetByID(f.ctx, active.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "extended", got.Comment)
+
+	require.NoError(t, repo.Expire(f.ctx, upcoming.ID))
+	got, err = repo.GetByID(f.ctx, upcoming.ID)
+	require.NoError(t, err)
+	assert.False(t, got.IsActive(time.Now()))
+
+	missing := uuid.New().String()
+	_, err = repo.GetByID(f.ctx, missing)
+	requireNotFound(t, err)
+	requireNotFound(t, repo.Expire(f.ctx, missing))
+	require.NoError(t, repo.Delete(f.ctx, active.ID))
+	requireNotFound(t, repo.Delete(f.ctx, active.ID))
+}
+
+func testEscalation(t *testing.T, f *fixture) {
+	repo := f.repos.Escalation()
+	device := f.device(t, "spectrometer")
+
+	schedule := &models.OnCallSchedule{Name: f.name("schedule"), RotationStart: f.now, Participants: []string{"alice", "bob"}}
+	require.NoError(t, repo.CreateSchedule(f.ctx, schedule))
+	assert.Error(t, repo.CreateSchedule(f.ctx, &models.OnCallSchedule{Name: schedule.Name, RotationStart: f.now, Participants: []string{"carol"}}), "duplicate name")
+
+	policy := &models.EscalationPolicy{
+		Name: f.name("policy"),
+		Steps: []models.EscalationStep{
+			{Delay: 5*time.Minute + 500*time.Millisecond, Recipients: []string{"alice"}},
+			{Delay: 15 * time.Minute, ScheduleID: &schedule.ID},
+		},
+	}
+	require.NoError(t, repo.CreatePolicy(f.ctx, policy))
+
+	got, err := repo.GetPolicy(f.ctx, policy.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Steps, 2)
+	assert.Equal(t, 5*time.Minute, got.Steps[0].Delay, "delays are stored in whole seconds")
+	assert.Equal(t, []string{"alice"}, got.Steps[0].Recipients)
+	require.NotNil(t, got.Steps[1].ScheduleID)
+	assert.Equal(t, schedule.ID, *got.Steps[1].ScheduleID)
+
+	got.Description = "updated"
+	require.NoError(t, repo.UpdatePolicy(f.ctx, got))
+	got, err = repo.GetPolicy(f.ctx, policy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", got.Description)
+
+	later := &models.AlertRoute{Name: "later", Priority: 20, PolicyID: policy.ID}
+	earlier := &models.AlertRoute{Name: "earlier", Priority: 10, PolicyID: policy.ID}
+	require.NoError(t, repo.CreateRoute(f.ctx, later))
+	require.NoError(t, repo.CreateRoute(f.ctx, earlier))
+	assert.Error(t, repo.CreateRoute(f.ctx, &models.AlertRoute{Name: "orphan", PolicyID: uuid.New().String()}), "unknown policy")
+
+	routes, err := repo.ListRoutes(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{earlier.ID, later.ID}, ids(routes, func(r *models.AlertRoute) string { return r.ID }, []string{earlier.ID, later.ID}))
+
+	alert := f.alert(t, device.ID, models.AlertSeverityCritical, f.now)
+	escalation := &models.AlertEscalation{AlertID: alert.ID, PolicyID: policy.ID, Level: 1, LastEscalatedAt: f.now}
+	require.NoError(t, repo.RecordEscalation(f.ctx, escalation))
+	escalation.Level = 2
+	require.NoError(t, repo.RecordEscalation(f.ctx, escalation))
+
+	escalations, err := repo.GetEscalations(f.ctx, []string{alert.ID, uuid.New().String()})
+	require.NoError(t, err)
+	require.Len(t, escalations, 1)
+	assert.Equal(t, 2, escalations[alert.ID].Level)
+	assert.True(t, f.now.Equal(escalations[alert.ID].LastEscalatedAt))
+
+	escalations, err = repo.GetEscalations(f.ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, escalations)
+
+	// Deleting a policy deletes its routes and detaches escalations from it
+	require.NoError(t, repo.DeletePolicy(f.ctx, policy.ID))
+	requireNotFound(t, repo.DeleteRoute(f.ctx, earlier.ID))
+	escalations, err = repo.GetEscalations(f.ctx, []string{alert.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "", escalations[alert.ID].PolicyID)
+
+	_, err = repo.GetPolicy(f.ctx, policy.ID)
+	requireNotFound(t, err)
+	require.NoError(t, repo.DeleteSchedule(f.ctx, schedule.ID))
+	_, err = repo.GetSchedule(f.ctx, schedule.ID)
+	requireNotFound(t, err)
+}
+
+func testDeviceHistory(t *testing.T, f *fixture) {
+	events := f.repos.DeviceEvent()
+	sessions := f.repos.DeviceSession()
+	device := f.device(t, "thermocycler")
+
+	online, offline := models.DeviceStatusOnline, models.DeviceStatusOffline
+	opened := &models.DeviceEvent{DeviceID: device.ID, Type: models.DeviceEventStreamOpened, OccurredAt: f.now.Add(-3 * time.Minute)}
+	changed := &models.DeviceEvent{DeviceID: device.ID, Type: models.DeviceEventStatusChanged, FromStatus: &offline, ToStatus: &online, OccurredAt: f.now.Add(-2 * time.Minute)}
+	closed := &models.DeviceEvent{DeviceID: device.ID, Type: models.DeviceEventStreamClosed, Message: "closed", OccurredAt: f.now.Add(-time.Minute)}
+	for _, event := range []*models.DeviceEvent{opened, changed, closed} {
+		require.NoError(t, events.Create(f.ctx, event))
+	}
+	assert.Error(t, events.Create(f.ctx, &models.DeviceEvent{DeviceID: f.name("unknown"), Type: models.DeviceEventRegistered}), "unknown device")
+
+	eventIDs := func(list []*models.DeviceEvent) []string {
+		return ids(list, func(e *models.DeviceEvent) string { return e.ID }, nil)
+	}
+
+	list, err := events.List(f.ctx, repository.DeviceEventFilter{DeviceID: device.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{closed.ID, changed.ID, opened.ID}, eventIDs(list))
+
+	list, err = events.List(f.ctx, repository.DeviceEventFilter{DeviceID: device.ID, Types: []models.DeviceEventType{models.DeviceEventStatusChanged}})
+	require.NoError(t, err)
+	require.Equal(t, []string{changed.ID}, eventIDs(list))
+	require.NotNil(t, list[0].ToStatus)
+	assert.Equal(t, online, *list[0].ToStatus)
+
+	count, err := events.Count(f.ctx, repository.DeviceEventFilter{DeviceID: device.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	event, err := events.GetLastBefore(f.ctx, device.ID, models.DeviceEventStreamClosed, f.now)
+	require.NoError(t, err)
+	assert.Equal(t, closed.ID, event.ID)
+	_, err = events.GetLastBefore(f.ctx, device.ID, models.DeviceEventStreamClosed, closed.OccurredAt)
+	requireNotFound(t, err)
+
+	session := &models.DeviceSession{DeviceID: device.ID, SessionID: f.name("session"), ConnectedAt: f.now.Add(-time.Hour), LastHeartbeat: f.now, IsActive: true}
+	require.NoError(t, sessions.Create(f.ctx, session))
+	assert.NotEmpty(t, session.ID)
+	assert.Error(t, sessions.Create(f.ctx, &models.DeviceSession{DeviceID: device.ID, SessionID: session.SessionID}), "duplicate session ID")
+
+	require.NoError(t, sessions.End(f.ctx, session.SessionID, f.now, "stream closed"))
+	got, err := sessions.GetBySessionID(f.ctx, session.SessionID)
+	require.NoError(t, err)
+	assert.False(t, got.IsActive)
+	require.NotNil(t, got.DisconnectReason)
+	assert.Equal(t, "stream closed", *got.DisconnectReason)
+
+	// Sessions that ended before the range starts are left out
+	after := f.now.Add(time.Minute)
+	list2, err := sessions.ListByDevice(f.ctx, device.ID, repository.TimeRangeFilter{StartTime: &after}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, list2)
+
+	require.NoError(t, sessions.Resume(f.ctx, session.SessionID, f.now))
+	list2, err = sessions.ListByDevice(f.ctx, device.ID, repository.TimeRangeFilter{StartTime: &after}, 0)
+	require.NoError(t, err)
+	require.Len(t, list2, 1)
+	assert.True(t, list2[0].IsActive)
+	assert.Nil(t, list2[0].DisconnectedAt)
+
+	_, err = sessions.GetBySessionID(f.ctx, f.name("missing"))
+	requireNotFound(t, err)
+	requireNotFound(t, sessions.End(f.ctx, f.name("missing"), f.now, "gone"))
+}
+
+func testRole(t *testing.T, f *fixture) {
+	repo := f.repos.Role()
+
+	role := &models.Role{Name: f.name("role"), Description: "Calibration", Permissions: []models.Permission{models.PermissionDevicesRead}}
+	require.NoError(t, repo.CreateRole(f.ctx, role))
+	assert.Error(t, repo.CreateRole(f.ctx, role), "duplicate role")
+
+	role.Permissions = append(role.Permissions, models.PermissionCommandsSend)
+	require.NoError(t, repo.UpdateRole(f.ctx, role))
+	got, err := repo.GetRole(f.ctx, role.Name)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Permission{models.PermissionDevicesRead, models.PermissionCommandsSend}, got.Permissions)
+	assert.False(t, got.BuiltIn)
+
+	viewer, err := repo.GetRole(f.ctx, models.RoleViewer)
+	require.NoError(t, err)
+	assert.True(t, viewer.BuiltIn)
+	requireNotFound(t, repo.DeleteRole(f.ctx, models.RoleViewer))
+
+	roles, err := repo.ListRoles(f.ctx)
+	require.NoError(t, err)
+	names := ids(roles, func(r *models.Role) string { return r.Name }, nil)
+	assert.True(t, slices.IsSorted(names))
+	assert.Contains(t, names, role.Name)
+
+	subject := f.name("subject")
+	group := "chemistry"
+	global := &models.RoleBinding{Subject: subject, Role: role.Name, CreatedBy: "admin"}
+	scoped := &models.RoleBinding{Subject: subject, Role: models.RoleViewer, DeviceGroup: &group, CreatedBy: "admin"}
+	require.NoError(t, repo.CreateBinding(f.ctx, global))
+	require.NoError(t, repo.CreateBinding(f.ctx, scoped))
+	assert.Error(t, repo.CreateBinding(f.ctx, &models.RoleBinding{Subject: subject, Role: role.Name, CreatedBy: "admin"}), "duplicate binding")
+	assert.Error(t, repo.CreateBinding(f.ctx, &models.RoleBinding{Subject: subject, Role: f.name("missing"), CreatedBy: "admin"}), "unknown role")
+
+	bindings, err := repo.ListBindings(f.ctx, subject)
+	require.NoError(t, err)
+	require.Len(t, bindings, 2)
+	assert.Equal(t, role.Name, bindings[0].Role)
+	assert.Nil(t, bindings[0].DeviceGroup)
+	require.NotNil(t, bindings[1].DeviceGroup)
+	assert.Equal(t, group, *bindings[1].DeviceGroup)
+
+	// Deleting a role deletes its bindings
+	require.NoError(t, repo.DeleteRole(f.ctx, role.Name))
+	bindings, err = repo.ListBindings(f.ctx, subject)
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	require.NoError(t, repo.DeleteBinding(f.ctx, scoped.ID))
+	requireNotFound(t, repo.DeleteBinding(f.ctx, scoped.ID))
+	_, err = repo.GetRole(f.ctx, role.Name)
+	requireNotFound(t, err)
+}
+
+func testCredentials(t *testing.T, f *fixture) {
+	provisioning := f.repos.Provisioning()
+	keys := f.repos.APIKey()
+	device := f.device(t, "plate-reader")
+
+	token := &models.EnrollmentToken{TokenHash: f.name("hash"), DeviceType: "plate-reader", CreatedBy: "admin", ExpiresAt: f.now.Add(time.Hour)}
+	require.NoError(t, provisioning.CreateEnrollmentToken(f.ctx, token))
+
+	consumed, err := provisioning.ConsumeEnrollmentToken(f.ctx, token.TokenHash, device.ID, f.now)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, consumed.ID)
+	require.NotNil(t, consumed.UsedByDevice)
+	assert.Equal(t, device.ID, *consumed.UsedByDevice)
+	_, err = provisioning.ConsumeEnrollmentToken(f.ctx, token.TokenHash, device.ID, f.now)
+	requireNotFound(t, err)
+
+	expired := &models.EnrollmentToken{TokenHash: f.name("expired"), DeviceType: "plate-reader", CreatedBy: "admin", ExpiresAt: f.now.Add(-time.Hour)}
+	require.NoError(t, provisioning.CreateEnrollmentToken(f.ctx, expired))
+	_, err = provisioning.ConsumeEnrollmentToken(f.ctx, expired.TokenHash, device.ID, f.now)
+	requireNotFound(t, err)
+
+	for i, serial := range []string{f.name("serial-1"), f.name("serial-2")} {
+		require.NoError(t, provisioning.RecordCertificate(f.ctx, &models.DeviceCertificate{
+			SerialNumber: serial, DeviceID: device.ID, Subject: "CN=" + device.ID,
+			NotBefore: f.now, NotAfter: f.now.Add(24 * time.Hour), IssuedAt: f.now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	certificates, err := provisioning.ListCertificates(f.ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{f.name("serial-2"), f.name("serial-1")}, ids(certificates, func(c *models.DeviceCertificate) string { return c.SerialNumber }, nil))
+
+	subject := f.name("service")
+	scoped := &models.APIKey{Prefix: f.name("scoped"), KeyHash: "hash", Name: "scoped", Subject: subject, Roles: []string{models.RoleViewer}, DeviceGroups: []string{"chemistry"}, CreatedBy: "admin", CreatedAt: f.now.Add(-time.Minute)}
+	global := &models.APIKey{Prefix: f.name("global"), KeyHash: "hash", Subject: subject, Roles: []string{models.RoleOperator}, CreatedBy: "admin", CreatedAt: f.now}
+	require.NoError(t, keys.Create(f.ctx, scoped))
+	require.NoError(t, keys.Create(f.ctx, global))
+	assert.Error(t, keys.Create(f.ctx, &models.APIKey{Prefix: scoped.Prefix, KeyHash: "hash", Subject: subject, Roles: []string{models.RoleViewer}, CreatedBy: "admin"}), "duplicate prefix")
+
+	got, err := keys.GetByPrefix(f.ctx, scoped.Prefix)
+	require.NoError(t, err)
+	assert.Equal(t, scoped.ID, got.ID)
+	assert.Equal(t, "hash", got.KeyHash)
+	assert.Equal(t, []string{"chemistry"}, got.DeviceGroups)
+
+	keyIDs := func(filter repository.APIKeyFilter) []string {
+		t.Helper()
+		filter.Subject = subject
+		list, err := keys.List(f.ctx, filter)
+		require.NoError(t, err)
+		return ids(list, func(k *models.APIKey) string { return k.ID }, nil)
+	}
+	assert.Equal(t, []string{global.ID, scoped.ID}, keyIDs(repository.APIKeyFilter{}))
+	assert.Equal(t, []string{scoped.ID}, keyIDs(repository.APIKeyFilter{WithinGroups: []string{"chemistry", "biology"}}))
+	assert.Empty(t, keyIDs(repository.APIKeyFilter{WithinGroups: []string{}}))
+
+	require.NoError(t, keys.Revoke(f.ctx, global.ID, f.now))
+	requireNotFound(t, keys.Revoke(f.ctx, global.ID, f.now))
+	assert.Equal(t, []string{scoped.ID}, keyIDs(repository.APIKeyFilter{}))
+	assert.Equal(t, []string{global.ID, scoped.ID}, keyIDs(repository.APIKeyFilter{IncludeRevoked: true}))
+
+	count, err := keys.Count(f.ctx, repository.APIKeyFilter{Subject: subject, IncludeRevoked: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// The last use only moves forward
+	require.NoError(t, keys.TouchLastUsed(f.ctx, scoped.ID, f.now))
+	require.NoError(t, keys.TouchLastUsed(f.ctx, scoped.ID, f.now.Add(-time.Hour)))
+	got, err = keys.GetByID(f.ctx, scoped.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastUsedAt)
+	assert.True(t, f.now.Equal(*got.LastUsedAt))
+
+	_, err = keys.GetByID(f.ctx, uuid.New().String())
+	requireNotFound(t, err)
+	_, err = keys.GetByPrefix(f.ctx, f.name("missing"))
+	requireNotFound(t, err)
+}
+
+func testAudit(t *testing.T, f *fixture) {
+	repo := f.repos.Audit()
+	actor := f.name("actor")
+
+	first := &models.AuditEntry{Actor: actor, Action: "devices.register", ResourceType: "device", ResourceID: "hplc-1", Outcome: models.AuditOutcomeSuccess,
+		After: map[string]interface{}{"name": "hplc", "channels": 4}}
+	second := &models.AuditEntry{Actor: actor, Action: "devices.delete", ResourceType: "device", ResourceID: "hplc-1", Outcome: models.AuditOutcomeSuccess}
+	require.NoError(t, repo.Append(f.ctx, first))
+	require.NoError(t, repo.Append(f.ctx, second))
+
+	// Entries are chained in sequence
+	assert.Equal(t, first.Sequence+1, second.Sequence)
+	assert.Equal(t, first.Hash, second.PreviousHash)
+
+	entries, err := repo.ListAfter(f.ctx, first.Sequence-1, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, first.ID, entries[0].ID)
+	assert.Equal(t, map[string]interface{}{"name": "hplc", "channels": float64(4)}, entries[0].After)
+	assert.Nil(t, entries[0].Before)
+	for _, entry := range entries {
+		hash, err := entry.ComputeHash()
+		require.NoError(t, err)
+		assert.Equal(t, entry.Hash, hash, "stored entries hash as they did when appended")
+	}
+
+	entries, err = repo.List(f.ctx, repository.AuditFilter{Actor: actor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID, first.ID}, ids(entries, func(e *models.AuditEntry) string { return e.ID }, nil))
+
+	entries, err = repo.List(f.ctx, repository.AuditFilter{Actor: actor, Action: "devices.register"})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	count, err := repo.Count(f.ctx, repository.AuditFilter{Actor: actor, ResourceType: "device", ResourceID: "hplc-1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func testTransaction(t *testing.T, f *fixture) {
+	committed := &models.Device{ID: f.name("committed"), Name: "committed", Type: "sensor", Version: "1", Status: models.DeviceStatusOnline}
+	err := f.repos.WithTransaction(f.ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if err := repos.Device().Create(ctx, committed); err != nil {
+			return err
+		}
+		// Writes are visible within the transaction
+		_, err := repos.Device().GetByID(ctx, committed.ID)
+		return err
+	})
+	require.NoError(t, err)
+	_, err = f.repos.Device().GetByID(f.ctx, committed.ID)
+	require.NoError(t, err)
+
+	failure := errors.New("abort")
+	rolledBack := &models.Device{ID: f.name("rolled-back"), Name: "rolled-back", Type: "sensor", Version: "1", Status: models.DeviceStatusOnline}
+	err = f.repos.WithTransaction(f.ctx, func(ctx context.Context, repos repository.RepositoryManager) error {
+		if err := repos.Device().Create(ctx, rolledBack); err != nil {
+			return err
+		}
+		if err := repos.Device().UpdateStatus(ctx, committed.ID, models.DeviceStatusOffline); err != nil {
+			return err
+		}
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	_, err = f.repos.Device().GetByID(f.ctx, rolledBack.ID)
+	requireNotFound(t, err)
+	device, err := f.repos.Device().GetByID(f.ctx, committed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeviceStatusOnline, device.Status)
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// roleRepository implements RoleRepository interface
+type roleRepository struct {
+	db     db.Executor
+	logger *logger.Logger
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db db.Executor, logger *logger.Logger) RoleRepository {
+	return &roleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateRole creates a new role
+func (r *roleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	ctx, span := startSpan(ctx, "role", "CreateRole")
+	defer span.End()
+
+	role.SetDefaults()
+
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("role validation failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO roles (name, description, permissions, built_in, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		role.Name,
+		role.Description,
+		pq.Array(permissionStrings(role.Permissions)),
+		role.BuiltIn,
+		role.CreatedAt,
+		role.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("role", role.Name).WithError(err).Error("Failed to create role")
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"role":        role.Name,
+		"permissions": len(role.Permissions),
+	}).Info("Role created successfully")
+
+	return nil
+}
+
+// GetRole retrieves a role by name
+func (r *roleRepository) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	ctx, span := startSpan(ctx, "role", "GetRole")
+	defer span.End()
+
+	query := `
+		SELECT name, description, permissions, built_in, created_at, updated_at
+		FROM roles
+		WHERE name = $1
+	`
+
+	role, err := r.scanRole(r.db.QueryRowContext(ctx, query, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: role %s", ErrNotFound, name)
+		}
+		r.logger.WithField("role", name).WithError(err).Error("Failed to get role")
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return role, nil
+}
+
+// UpdateRole updates the description and permissions of a role
+func (r *roleRepository) UpdateRole(ctx context.Context, role *models.Role) error {
+	ctx, span := startSpan(ctx, "role", "UpdateRole")
+	defer span.End()
+
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("role validation failed: %w", err)
+	}
+
+	query := `
+		UPDATE roles
+		SET description = $2, permissions = $3
+		WHERE name = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		role.Name,
+		role.Description,
+		pq.Array(permissionStrings(role.Permissions)),
+	)
+
+	if err != nil {
+		r.logger.WithField("role", role.Name).WithError(err).Error("Failed to update role")
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return r.checkAffected(result, "role", role.Name)
+}
+
+// DeleteRole removes a role and its bindings. Built-in roles cannot be deleted.
+func (r *roleRepository) DeleteRole(ctx context.Context, name string) error {
+	ctx, span := startSpan(ctx, "role", "DeleteRole")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE name = $1 AND NOT built_in`, name)
+	if err != nil {
+		r.logger.WithField("role", name).WithError(err).Error("Failed to delete role")
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return r.checkAffected(result, "role", name)
+}
+
+// ListRoles retrieves all roles
+func (r *roleRepository) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	ctx, span := startSpan(ctx, "role", "ListRoles")
+	defer span.End()
+
+	query := `
+		SELECT name, description, permissions, built_in, created_at, updated_at
+		FROM roles
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list roles")
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		role, err := r.scanRole(rows)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan role")
+			continue
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role rows: %w", err)
+	}
+
+	return roles, nil
+}
+
+// CreateBinding grants a role to a subject
+func (r *roleRepository) CreateBinding(ctx context.Context, binding *models.RoleBinding) error {
+	ctx, span := startSpan(ctx, "role", "CreateBinding")
+	defer span.End()
+
+	binding.SetDefaults()
+
+	if err := binding.Validate(); err != nil {
+		return fmt.Errorf("role binding validation failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO role_bindings (id, subject, role, device_group, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		binding.ID,
+		binding.Subject,
+		binding.Role,
+		binding.DeviceGroup,
+		binding.CreatedBy,
+		binding.CreatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("binding_id", binding.ID).WithError(err).Error("Failed to create role binding")
+		return fmt.Errorf("failed to create role binding: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"binding_id":   binding.ID,
+		"subject":      binding.Subject,
+		"role":         binding.Role,
+		"device_group": binding.DeviceGroup,
+		"created_by":   binding.CreatedBy,
+	}).Info("Role binding created successfully")
+
+	return nil
+}
+
+// DeleteBinding removes a role binding
+func (r *roleRepository) DeleteBinding(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "role", "DeleteBinding")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM role_bindings WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("binding_id", id).WithError(err).Error("Failed to delete role binding")
+		return fmt.Errorf("failed to delete role binding: %w", err)
+	}
+
+	return r.checkAffected(result, "role binding", id)
+}
+
+// ListBindings retrieves the role bindings of a subject, or of every subject
+// if subject is empty
+func (r *roleRepository) ListBindings(ctx context.Context, subject string) ([]*models.RoleBinding, error) {
+	ctx, span := startSpan(ctx, "role", "ListBindings")
+	defer span.End()
+
+	query := `
+		SELECT id, subject, role, device_group, created_by, created_at
+		FROM role_bindings
+		WHERE $1 = '' OR subject = $1
+		ORDER BY subject, role
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subject)
+	if err != nil {
+		r.logger.WithField("subject", subject).WithError(err).Error("Failed to list role bindings")
+		return nil, fmt.Errorf("failed to list role bindings: %w", err)
+	}
+	defer rows.Close()
+
+	var bindings []*models.RoleBinding
+	for rows.Next() {
+		binding := &models.RoleBinding{}
+		var deviceGroup sql.NullString
+
+		err := rows.Scan(
+			&binding.ID,
+			&binding.Subject,
+			&binding.Role,
+			&deviceGroup,
+			&binding.CreatedBy,
+			&binding.CreatedAt,
+		)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan role binding")
+			continue
+		}
+
+		if deviceGroup.Valid {
+			binding.DeviceGroup = &deviceGroup.String
+		}
+		bindings = append(bindings, binding)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role binding rows: %w", err)
+	}
+
+	return bindings, nil
+}
+
+// Helper methods
+
+// checkAffected returns ErrNotFound if the statement affected no rows
+func (r *roleRepository) checkAffected(result sql.Result, kind, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s %s", ErrNotFound, kind, id)
+	}
+
+	return nil
+}
+
+// scanRole scans a single row into a role
+func (r *roleRepository) scanRole(row rowScanner) (*models.Role, error) {
+	role := &models.Role{}
+	var description sql.NullString
+	var permissions pq.StringArray
+
+	err := row.Scan(
+		&role.Name,
+		&description,
+		&permissions,
+		&role.BuiltIn,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = description.String
+	role.Permissions = make([]models.Permission, len(permissions))
+	for i, permission := range permissions {
+		role.Permissions[i] = models.Permission(permission)
+	}
+
+	return role, nil
+}
+
+// permissionStrings converts permissions to their stored string form
+func permissionStrings(permissions []models.Permission) []string {
+	values := make([]string, len(permissions))
+	for i, permission := range permissions {
+		values[i] = string(permission)
+	}
+	return values
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+)
+
+// silenceColumns lists the columns selected for silence queries
+const silenceColumns = `id, device_id, device_type, alert_type, starts_at, ends_at, created_by, comment, created_at`
+
+// silenceRepository implements SilenceRepository interface
+type silenceRepository struct {
+	db     db.Executor
+	logger *logger.Logger
+}
+
+// NewSilenceRepository creates a new silence repository
+func NewSilenceRepository(db db.Executor, logger *logger.Logger) SilenceRepository {
+	return &silenceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new silence
+func (r *silenceRepository) Create(ctx context.Context, silence *models.Silence) error {
+	ctx, span := startSpan(ctx, "silence", "Create")
+	defer span.End()
+
+	silence.SetDefaults()
+
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("silence validation failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO alert_silences (id, device_id, device_type, alert_type, starts_at, ends_at, created_by, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		silence.ID,
+		silence.DeviceID,
+		silence.DeviceType,
+		silence.AlertType,
+		silence.StartsAt,
+		silence.EndsAt,
+		silence.CreatedBy,
+		silence.Comment,
+		silence.CreatedAt,
+	)
+
+	if err != nil {
+		r.logger.WithField("silence_id", silence.ID).WithError(err).Error("Failed to create silence")
+		return fmt.Errorf("failed to create silence: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"silence_id": silence.ID,
+		"created_by": silence.CreatedBy,
+		"starts_at":  silence.StartsAt,
+		"ends_at":    silence.EndsAt,
+	}).Info("Silence created successfully")
+
+	return nil
+}
+
+// GetByID retrieves a silence by ID
+func (r *silenceRepository) GetByID(ctx context.Context, id string) (*models.Silence, error) {
+	ctx, span := startSpan(ctx, "silence", "GetByID")
+	defer span.End()
+
+	query := `SELECT ` + silenceColumns + ` FROM alert_silences WHERE id = $1`
+
+	silence, err := r.scanSilence(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: silence %s", ErrNotFound, id)
+		}
+		r.logger.WithField("silence_id", id).WithError(err).Error("Failed to get silence")
+		return nil, fmt.Errorf("failed to get silence: %w", err)
+	}
+
+	return silence, nil
+}
+
+// Update updates an existing silence
+func (r *silenceRepository) Update(ctx context.Context, silence *models.Silence) error {
+	ctx, span := startSpan(ctx, "silence", "Update")
+	defer span.End()
+
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("silence validation failed: %w", err)
+	}
+
+	query := `
+		UPDATE alert_silences
+		SET device_id = $2, device_type = $3, alert_type = $4, starts_at = $5, ends_at = $6, comment = $7
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		silence.ID,
+		silence.DeviceID,
+		silence.DeviceType,
+		silence.AlertType,
+		silence.StartsAt,
+		silence.EndsAt,
+		silence.Comment,
+	)
+
+	if err != nil {
+		r.logger.WithField("silence_id", silence.ID).WithError(err).Error("Failed to update silence")
+		return fmt.Errorf("failed to update silence: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: silence %s", ErrNotFound, silence.ID)
+	}
+
+	r.logger.WithField("silence_id", silence.ID).Info("Silence updated successfully")
+	return nil
+}
+
+// Delete removes a silence
+func (r *silenceRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "silence", "Delete")
+	defer span.End()
+
+	query := `DELETE FROM alert_silences WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.logger.WithField("silence_id", id).WithError(err).Error("Failed to delete silence")
+		return fmt.Errorf("failed to delete silence: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: silence %s", ErrNotFound, id)
+	}
+
+	r.logger.WithField("silence_id", id).Info("Silence deleted successfully")
+	return nil
+}
+
+// List retrieves silences with filtering and pagination
+func (r *silenceRepository) List(ctx context.Context, filter SilenceFilter) ([]*models.Silence, error) {
+	ctx, span := startSpan(ctx, "silence", "List")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := `SELECT ` + silenceColumns + ` FROM alert_silences`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Add ORDER BY
+	orderBy := "starts_at"
+	if filter.SortBy != "" {
+		orderBy = filter.SortBy
+	}
+	order := "DESC"
+	if filter.Order != "" {
+		order = strings.ToUpper(filter.Order)
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s", orderBy, order)
+
+	// Add LIMIT and OFFSET
+	argIndex := len(args) + 1
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+	}
+
+	return r.executeQuery(ctx, query, args...)
+}
+
+// Count returns the total number of silences matching the filter
+func (r *silenceRepository) Count(ctx context.Context, filter SilenceFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "silence", "Count")
+	defer span.End()
+
+	conditions, args := r.buildConditions(filter)
+
+	query := "SELECT COUNT(*) FROM alert_silences"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count silences")
+		return 0, fmt.Errorf("failed to count silences: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetActive retrieves all silences whose window contains the given time
+func (r *silenceRepository) GetActive(ctx context.Context, at time.Time) ([]*models.Silence, error) {
+	ctx, span := startSpan(ctx, "silence", "GetActive")
+	defer span.End()
+
+	query := `
+		SELECT ` + silenceColumns + `
+		FROM alert_silences
+		WHERE starts_at <= $1 AND ends_at > $1
+		ORDER BY starts_at
+	`
+
+	return r.executeQuery(ctx, query, at)
+}
+
+// Expire ends a silence immediately
+func (r *silenceRepository) Expire(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "silence", "Expire")
+	defer span.End()
+
+	silence, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	silence.Expire()
+
+	query := `UPDATE alert_silences SET starts_at = $2, ends_at = $3 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, silence.ID, silence.StartsAt, silence.EndsAt); err != nil {
+		r.logger.WithField("silence_id", id).WithError(err).Error("Failed to expire silence")
+		return fmt.Errorf("failed to expire silence: %w", err)
+	}
+
+	r.logger.WithField("silence_id", id).Info("Silence expired")
+	return nil
+}
+
+// DeleteExpiredOlderThan removes silences that ended before the threshold
+func (r *silenceRepository) DeleteExpiredOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "silence", "DeleteExpiredOlderThan")
+	defer span.End()
+
+	query := `DELETE FROM alert_silences WHERE ends_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete expired silences")
+		return 0, fmt.Errorf("failed to delete expired silences: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithFields(map[string]interface{}{
+			"deleted":   rowsAffected,
+			"threshold": threshold,
+		}).Info("Expired silences deleted")
+	}
+
+	return rowsAffected, nil
+}
+
+// Helper methods
+
+// buildConditions constructs the WHERE conditions shared by List and Count
+func (r *silenceRepository) buildConditions(filter SilenceFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if len(filter.DeviceIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("device_id = ANY($%d)", argIndex))
+		args = append(args, pq.Array(filter.DeviceIDs))
+		argIndex++
+	}
+
+	if filter.CreatedBy != nil {
+		conditions = append(conditions, fmt.Sprintf("created_by = $%d", argIndex))
+		args = append(args, *filter.CreatedBy)
+		argIndex++
+	}
+
+	if filter.ActiveAt != nil {
+		conditions = append(conditions, fmt.Sprintf("starts_at <= $%d AND ends_at > $%d", argIndex, argIndex))
+		args = append(args, *filter.ActiveAt)
+		argIndex++
+	}
+
+	return conditions, args
+}
+
+// executeQuery executes a query and returns silences
+func (r *silenceRepository) executeQuery(ctx context.Context, query string, args ...interface{}) ([]*models.Silence, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to execute silence query")
+		return nil, fmt.Errorf("failed to execute silence query: %w", err)
+	}
+	defer rows.Close()
+
+	var silences []*models.Silence
+	for rows.Next() {
+		silence, err := r.scanSilence(rows)
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan silence")
+			continue
+		}
+		silences = append(silences, silence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating silence rows: %w", err)
+	}
+
+	return silences, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSilence scans a single row into a silence
+func (r *silenceRepository) scanSilence(row rowScanner) (*models.Silence, error) {
+	silence := &models.Silence{}
+	var comment sql.NullString
+
+	err := row.Scan(
+		&silence.ID,
+		&silence.DeviceID,
+		&silence.DeviceType,
+		&silence.AlertType,
+		&silence.StartsAt,
+		&silence.EndsAt,
+		&silence.CreatedBy,
+		&comment,
+		&silence.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	silence.Comment = comment.String
+	return silence, nil
+}
@@ -0,0 +1,511 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// alertColumns are the columns selected for an alert, in scanAlert order
+const alertColumns = `id, device_id, type, severity, message, metadata, acknowledged, acknowledged_by,
+	acknowledged_at, resolved_at, created_at, silenced, silenced_by`
+
+// alertSortColumns are the columns alerts can be sorted by. Severities are
+// stored as text, so they sort alphabetically.
+var alertSortColumns = map[string]string{
+	"id":              "id",
+	"device_id":       "device_id",
+	"type":            "type",
+	"severity":        "severity",
+	"message":         "message",
+	"acknowledged":    "acknowledged",
+	"acknowledged_at": "acknowledged_at",
+	"resolved_at":     "resolved_at",
+	"created_at":      "created_at",
+	"silenced":        "silenced",
+}
+
+// alertRepository implements AlertRepository on SQLite
+type alertRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create creates a new alert
+func (r *alertRepository) Create(ctx context.Context, alert *models.Alert) error {
+	ctx, span := startSpan(ctx, "alert", "Create")
+	defer span.End()
+
+	if err := alert.Validate(); err != nil {
+		return fmt.Errorf("alert validation failed: %w", err)
+	}
+
+	alert.SetDefaults()
+
+	metadataJSON, err := encodeJSON(alert.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO alerts (id, device_id, type, severity, message, metadata, acknowledged, created_at, silenced, silenced_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		alert.ID,
+		alert.DeviceID,
+		alert.Type,
+		alert.Severity,
+		alert.Message,
+		metadataJSON,
+		alert.Acknowledged,
+		alert.CreatedAt,
+		alert.Silenced,
+		alert.SilencedBy,
+	)
+	if err != nil {
+		r.logger.WithField("device_id", alertDevice(alert)).WithError(err).Error("Failed to create alert")
+		return fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	r.logger.WithField("device_id", alertDevice(alert)).WithFields(map[string]interface{}{
+		"alert_id": alert.ID,
+		"type":     alert.Type,
+		"severity": alert.Severity,
+	}).Info("Alert created successfully")
+
+	return nil
+}
+
+// GetByID retrieves an alert by ID
+func (r *alertRepository) GetByID(ctx context.Context, id string) (*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetByID")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+alertColumns+` FROM alerts WHERE id = $1`, id)
+	alert, err := scanAlert(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: alert %s", repository.ErrNotFound, id)
+		}
+		r.logger.WithError(err).Error("Failed to get alert")
+		return nil, fmt.Errorf("failed to get alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// Update updates an existing alert
+func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error {
+	ctx, span := startSpan(ctx, "alert", "Update")
+	defer span.End()
+
+	if err := alert.Validate(); err != nil {
+		return fmt.Errorf("alert validation failed: %w", err)
+	}
+
+	metadataJSON, err := encodeJSON(alert.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE alerts
+		SET type = $2, severity = $3, message = $4, metadata = $5, acknowledged = $6,
+		    acknowledged_by = $7, acknowledged_at = $8, resolved_at = $9, silenced = $10, silenced_by = $11
+		WHERE id = $1
+	`,
+		alert.ID,
+		alert.Type,
+		alert.Severity,
+		alert.Message,
+		metadataJSON,
+		alert.Acknowledged,
+		alert.AcknowledgedBy,
+		alert.AcknowledgedAt,
+		alert.ResolvedAt,
+		alert.Silenced,
+		alert.SilencedBy,
+	)
+	if err != nil {
+		r.logger.WithField("device_id", alertDevice(alert)).WithError(err).Error("Failed to update alert")
+		return fmt.Errorf("failed to update alert: %w", err)
+	}
+
+	if err := checkAffected(result, "alert", alert.ID); err != nil {
+		return err
+	}
+
+	r.logger.WithField("device_id", alertDevice(alert)).WithFields(map[string]interface{}{
+		"alert_id": alert.ID,
+		"severity": alert.Severity,
+	}).Info("Alert updated successfully")
+
+	return nil
+}
+
+// Delete removes an alert
+func (r *alertRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "alert", "Delete")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alerts WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete alert")
+		return fmt.Errorf("failed to delete alert: %w", err)
+	}
+
+	if err := checkAffected(result, "alert", id); err != nil {
+		return err
+	}
+
+	r.logger.WithField("alert_id", id).Info("Alert deleted successfully")
+	return nil
+}
+
+// List retrieves alerts with filtering and pagination
+func (r *alertRepository) List(ctx context.Context, filter repository.AlertFilter) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "List")
+	defer span.End()
+
+	q, err := alertConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := orderBy(filter.Filter, alertSortColumns, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := r.query(ctx, `SELECT `+alertColumns+` FROM alerts`+q.clause()+order+q.page(filter.Filter), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list alerts")
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// Count returns the total number of alerts matching the filter
+func (r *alertRepository) Count(ctx context.Context, filter repository.AlertFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "alert", "Count")
+	defer span.End()
+
+	q, err := alertConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM alerts`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count alerts")
+		return 0, fmt.Errorf("failed to count alerts: %w", err)
+	}
+
+	return count, nil
+}
+
+// Acknowledge acknowledges an alert that is not acknowledged yet
+func (r *alertRepository) Acknowledge(ctx context.Context, alertID string, acknowledgedBy string) error {
+	ctx, span := startSpan(ctx, "alert", "Acknowledge")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE alerts
+		SET acknowledged = 1, acknowledged_by = $2, acknowledged_at = $3
+		WHERE id = $1 AND acknowledged = 0
+	`, alertID, acknowledgedBy, time.Now())
+	if err != nil {
+		r.logger.WithFields(map[string]interface{}{
+			"alert_id":        alertID,
+			"acknowledged_by": acknowledgedBy,
+		}).WithError(err).Error("Failed to acknowledge alert")
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+
+	if err := checkAffected(result, "unacknowledged alert", alertID); err != nil {
+		return err
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"alert_id":        alertID,
+		"acknowledged_by": acknowledgedBy,
+	}).Info("Alert acknowledged")
+
+	return nil
+}
+
+// Resolve resolves an alert that is not resolved yet
+func (r *alertRepository) Resolve(ctx context.Context, alertID string) error {
+	ctx, span := startSpan(ctx, "alert", "Resolve")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE alerts SET resolved_at = $2 WHERE id = $1 AND resolved_at IS NULL`, alertID, time.Now())
+	if err != nil {
+		r.logger.WithField("alert_id", alertID).WithError(err).Error("Failed to resolve alert")
+		return fmt.Errorf("failed to resolve alert: %w", err)
+	}
+
+	if err := checkAffected(result, "unresolved alert", alertID); err != nil {
+		return err
+	}
+
+	r.logger.WithField("alert_id", alertID).Info("Alert resolved")
+	return nil
+}
+
+// GetUnacknowledged retrieves all unacknowledged alerts
+func (r *alertRepository) GetUnacknowledged(ctx context.Context) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetUnacknowledged")
+	defer span.End()
+
+	alerts, err := r.query(ctx, `
+		SELECT `+alertColumns+`
+		FROM alerts
+		WHERE acknowledged = 0
+		ORDER BY severity DESC, created_at DESC
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get unacknowledged alerts")
+		return nil, fmt.Errorf("failed to get unacknowledged alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// GetUnresolved retrieves all unresolved alerts
+func (r *alertRepository) GetUnresolved(ctx context.Context) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetUnresolved")
+	defer span.End()
+
+	alerts, err := r.query(ctx, `
+		SELECT `+alertColumns+`
+		FROM alerts
+		WHERE resolved_at IS NULL
+		ORDER BY severity DESC, created_at DESC
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get unresolved alerts")
+		return nil, fmt.Errorf("failed to get unresolved alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// GetCriticalAlerts retrieves all unresolved critical alerts
+func (r *alertRepository) GetCriticalAlerts(ctx context.Context) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetCriticalAlerts")
+	defer span.End()
+
+	alerts, err := r.query(ctx, `
+		SELECT `+alertColumns+`
+		FROM alerts
+		WHERE severity = 'critical' AND resolved_at IS NULL
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get critical alerts")
+		return nil, fmt.Errorf("failed to get critical alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// GetAlertStats retrieves the number of alerts of each severity created
+// within the time range
+func (r *alertRepository) GetAlertStats(ctx context.Context, timeRange repository.TimeRangeFilter) (map[models.AlertSeverity]int64, error) {
+	ctx, span := startSpan(ctx, "alert", "GetAlertStats")
+	defer span.End()
+
+	q := &query{}
+	createdWithin(q, timeRange)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT severity, COUNT(*) FROM alerts`+q.clause()+` GROUP BY severity`, q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get alert statistics")
+		return nil, fmt.Errorf("failed to get alert statistics: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[models.AlertSeverity]int64)
+	for rows.Next() {
+		var severity models.AlertSeverity
+		var count int64
+
+		if err := rows.Scan(&severity, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan alert stats: %w", err)
+		}
+
+		stats[severity] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert stats rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetAlertsByDevice retrieves the latest alerts of a device
+func (r *alertRepository) GetAlertsByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Alert, error) {
+	ctx, span := startSpan(ctx, "alert", "GetAlertsByDevice")
+	defer span.End()
+
+	// SQLite reads a negative limit as no limit, where PostgreSQL rejects it
+	if limit < 0 {
+		return nil, fmt.Errorf("failed to get alerts by device: LIMIT must not be negative")
+	}
+
+	alerts, err := r.query(ctx, `
+		SELECT `+alertColumns+`
+		FROM alerts
+		WHERE device_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, deviceID, limit)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get alerts by device")
+		return nil, fmt.Errorf("failed to get alerts by device: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// DeleteResolvedOlderThan removes alerts resolved before the threshold
+func (r *alertRepository) DeleteResolvedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "alert", "DeleteResolvedOlderThan")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alerts WHERE resolved_at IS NOT NULL AND resolved_at < $1`, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete old resolved alerts")
+		return 0, fmt.Errorf("failed to delete old resolved alerts: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithFields(map[string]interface{}{
+			"deleted":   rowsAffected,
+			"threshold": threshold,
+		}).Info("Old resolved alerts deleted")
+	}
+
+	return rowsAffected, nil
+}
+
+// query runs a query selecting alertColumns
+func (r *alertRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Alert, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*models.Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert rows: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// alertConditions returns the conditions of an alert filter
+func alertConditions(filter repository.AlertFilter) (*query, error) {
+	q := &query{}
+
+	if len(filter.DeviceIDs) > 0 {
+		if err := q.in("device_id", filter.DeviceIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Types) > 0 {
+		if err := q.in("type", filter.Types); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Severities) > 0 {
+		if err := q.in("severity", filter.Severities); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Acknowledged != nil {
+		q.where("acknowledged = " + q.arg(*filter.Acknowledged))
+	}
+
+	if filter.Resolved != nil {
+		if *filter.Resolved {
+			q.where("resolved_at IS NOT NULL")
+		} else {
+			q.where("resolved_at IS NULL")
+		}
+	}
+
+	if filter.Silenced != nil {
+		q.where("silenced = " + q.arg(*filter.Silenced))
+	}
+
+	createdWithin(q, filter.TimeRangeFilter)
+
+	return q, nil
+}
+
+// alertDevice returns the device of an alert for logging, empty for alerts
+// not raised by a device
+func alertDevice(alert *models.Alert) string {
+	if alert.DeviceID == nil {
+		return ""
+	}
+	return *alert.DeviceID
+}
+
+// scanAlert scans a row of alertColumns
+func scanAlert(row rowScanner) (*models.Alert, error) {
+	alert := &models.Alert{}
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&alert.ID,
+		&alert.DeviceID,
+		&alert.Type,
+		&alert.Severity,
+		&alert.Message,
+		&metadataJSON,
+		&alert.Acknowledged,
+		&alert.AcknowledgedBy,
+		&alert.AcknowledgedAt,
+		&alert.ResolvedAt,
+		&alert.CreatedAt,
+		&alert.Silenced,
+		&alert.SilencedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.DecodeJSON(metadataJSON, &alert.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return alert, nil
+}
@@ -0,0 +1,238 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// apiKeyColumns are the columns selected for an API key, in scanAPIKey
+// order
+const apiKeyColumns = `id, prefix, key_hash, name, subject, roles, device_groups, device_id, expires_at, revoked_at, last_used_at, created_by, created_at`
+
+// apiKeyRepository implements APIKeyRepository on SQLite
+type apiKeyRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create stores a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	ctx, span := startSpan(ctx, "api_key", "Create")
+	defer span.End()
+
+	key.SetDefaults()
+
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("API key validation failed: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, prefix, key_hash, name, subject, roles, device_groups, device_id, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		key.ID,
+		key.Prefix,
+		key.KeyHash,
+		key.Name,
+		key.Subject,
+		stringArray(key.Roles),
+		stringArray(key.DeviceGroups),
+		key.DeviceID,
+		key.ExpiresAt,
+		key.CreatedBy,
+		key.CreatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("key_id", key.ID).WithError(err).Error("Failed to create API key")
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"key_id":     key.ID,
+		"prefix":     key.Prefix,
+		"subject":    key.Subject,
+		"created_by": key.CreatedBy,
+	}).Info("API key created successfully")
+
+	return nil
+}
+
+// GetByID retrieves an API key by ID
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	ctx, span := startSpan(ctx, "api_key", "GetByID")
+	defer span.End()
+
+	return r.get(ctx, "id", id)
+}
+
+// GetByPrefix retrieves an API key by its public prefix
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	ctx, span := startSpan(ctx, "api_key", "GetByPrefix")
+	defer span.End()
+
+	return r.get(ctx, "prefix", prefix)
+}
+
+// get retrieves the API key whose key column has a value
+func (r *apiKeyRepository) get(ctx context.Context, key, value string) (*models.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE `+key+` = $1`, value)
+	apiKey, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: API key %s", repository.ErrNotFound, value)
+		}
+		r.logger.WithField(key, value).WithError(err).Error("Failed to get API key")
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return apiKey, nil
+}
+
+// List retrieves API keys matching the filter, newest first
+func (r *apiKeyRepository) List(ctx context.Context, filter repository.APIKeyFilter) ([]*models.APIKey, error) {
+	ctx, span := startSpan(ctx, "api_key", "List")
+	defer span.End()
+
+	q, err := apiKeyConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys`+q.clause()+` ORDER BY created_at DESC`+q.page(filter.Filter), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list API keys")
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Count returns the total number of API keys matching the filter
+func (r *apiKeyRepository) Count(ctx context.Context, filter repository.APIKeyFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "api_key", "Count")
+	defer span.End()
+
+	q, err := apiKeyConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_keys`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count API keys")
+		return 0, fmt.Errorf("failed to count API keys: %w", err)
+	}
+
+	return count, nil
+}
+
+// Revoke marks an API key as revoked
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	ctx, span := startSpan(ctx, "api_key", "Revoke")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, at)
+	if err != nil {
+		r.logger.WithField("key_id", id).WithError(err).Error("Failed to revoke API key")
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	if err := checkAffected(result, "active API key", id); err != nil {
+		return err
+	}
+
+	r.logger.WithField("key_id", id).Info("API key revoked")
+	return nil
+}
+
+// TouchLastUsed records that a key was used at the given time
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	ctx, span := startSpan(ctx, "api_key", "TouchLastUsed")
+	defer span.End()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to update API key last use: %w", err)
+	}
+
+	return nil
+}
+
+// apiKeyConditions returns the conditions of an API key filter. A key is
+// within groups if it is limited to groups and all of them are listed, as
+// the <@ array operator of PostgreSQL checks.
+func apiKeyConditions(filter repository.APIKeyFilter) (*query, error) {
+	q := &query{}
+
+	if filter.Subject != "" {
+		q.where("subject = " + q.arg(filter.Subject))
+	}
+
+	if !filter.IncludeRevoked {
+		q.where("revoked_at IS NULL")
+	}
+
+	if filter.WithinGroups != nil {
+		groups, err := encodeArray(filter.WithinGroups)
+		if err != nil {
+			return nil, err
+		}
+		q.where("json_array_length(device_groups) > 0 AND NOT EXISTS (SELECT 1 FROM json_each(device_groups) AS dg " +
+			"WHERE dg.value NOT IN (SELECT value FROM json_each(" + q.arg(groups) + ")))")
+	}
+
+	return q, nil
+}
+
+// scanAPIKey scans a row of apiKeyColumns
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var name sql.NullString
+	var roles, deviceGroups stringArray
+
+	err := row.Scan(
+		&key.ID,
+		&key.Prefix,
+		&key.KeyHash,
+		&name,
+		&key.Subject,
+		&roles,
+		&deviceGroups,
+		&key.DeviceID,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.LastUsedAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Name = name.String
+	key.Roles = []string(roles)
+	key.DeviceGroups = []string(deviceGroups)
+
+	return key, nil
+}
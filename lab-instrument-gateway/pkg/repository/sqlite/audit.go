@@ -0,0 +1,238 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// auditColumns are the columns of an audit entry, in scanAuditEntry order
+const auditColumns = `id, sequence, occurred_at, actor, auth_method, action, resource_type, resource_id, reason, outcome, before, after, correlation_id, previous_hash, hash`
+
+// auditRepository implements AuditRepository on SQLite
+type auditRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Append chains an entry to the last one and stores it. Transactions take
+// the write lock when they begin, which serializes appends the way the
+// advisory lock of the PostgreSQL implementation does.
+func (r *auditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	ctx, span := startSpan(ctx, "audit", "Append")
+	defer span.End()
+
+	entry.SetDefaults()
+
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("audit entry validation failed: %w", err)
+	}
+
+	beforeJSON, err := nullableJSON(entry.Before)
+	if err != nil {
+		return fmt.Errorf("failed to marshal before value: %w", err)
+	}
+	afterJSON, err := nullableJSON(entry.After)
+	if err != nil {
+		return fmt.Errorf("failed to marshal after value: %w", err)
+	}
+
+	tx, exec, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var lastSequence int64
+	var lastHash string
+	err = exec.QueryRowContext(ctx, `SELECT sequence, hash FROM audit_log ORDER BY sequence DESC LIMIT 1`).Scan(&lastSequence, &lastHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get last audit entry: %w", err)
+	}
+
+	entry.Sequence = lastSequence + 1
+	entry.PreviousHash = lastHash
+	entry.Hash, err = entry.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO audit_log (`+auditColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`,
+		entry.ID,
+		entry.Sequence,
+		entry.OccurredAt,
+		entry.Actor,
+		entry.AuthMethod,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.Reason,
+		entry.Outcome,
+		beforeJSON,
+		afterJSON,
+		entry.CorrelationID,
+		entry.PreviousHash,
+		entry.Hash,
+	)
+	if err != nil {
+		r.logger.WithFields(map[string]interface{}{
+			"actor":  entry.Actor,
+			"action": entry.Action,
+		}).WithError(err).Error("Failed to append audit entry")
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves audit entries with filtering and pagination, newest first
+func (r *auditRepository) List(ctx context.Context, filter repository.AuditFilter) ([]*models.AuditEntry, error) {
+	ctx, span := startSpan(ctx, "audit", "List")
+	defer span.End()
+
+	q := auditConditions(filter)
+	return r.query(ctx, `SELECT `+auditColumns+` FROM audit_log`+q.clause()+` ORDER BY sequence DESC`+q.page(filter.Filter), q.args...)
+}
+
+// Count returns the number of audit entries matching the filter
+func (r *auditRepository) Count(ctx context.Context, filter repository.AuditFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "audit", "Count")
+	defer span.End()
+
+	q := auditConditions(filter)
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count audit entries")
+		return 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListAfter lists entries following a sequence number in chain order
+func (r *auditRepository) ListAfter(ctx context.Context, sequence int64, limit int) ([]*models.AuditEntry, error) {
+	ctx, span := startSpan(ctx, "audit", "ListAfter")
+	defer span.End()
+
+	return r.query(ctx, `SELECT `+auditColumns+` FROM audit_log WHERE sequence > $1 ORDER BY sequence ASC LIMIT $2`, sequence, limit)
+}
+
+// query runs an audit entry query. Unlike other listings, a row that cannot
+// be scanned fails the query, as skipping it would hide a gap in the chain.
+func (r *auditRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list audit entries")
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entry rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// auditConditions returns the conditions of an audit filter
+func auditConditions(filter repository.AuditFilter) *query {
+	q := &query{}
+
+	if filter.Actor != "" {
+		q.where("actor = " + q.arg(filter.Actor))
+	}
+
+	if filter.Action != "" {
+		q.where("action = " + q.arg(filter.Action))
+	}
+
+	if filter.ResourceType != "" {
+		q.where("resource_type = " + q.arg(filter.ResourceType))
+	}
+
+	if filter.ResourceID != "" {
+		q.where("resource_id = " + q.arg(filter.ResourceID))
+	}
+
+	if filter.StartTime != nil {
+		q.where("occurred_at >= " + q.arg(*filter.StartTime))
+	}
+
+	if filter.EndTime != nil {
+		q.where("occurred_at <= " + q.arg(*filter.EndTime))
+	}
+
+	return q
+}
+
+// scanAuditEntry scans a row of auditColumns
+func scanAuditEntry(row rowScanner) (*models.AuditEntry, error) {
+	entry := &models.AuditEntry{}
+	var beforeJSON, afterJSON []byte
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.Sequence,
+		&entry.OccurredAt,
+		&entry.Actor,
+		&entry.AuthMethod,
+		&entry.Action,
+		&entry.ResourceType,
+		&entry.ResourceID,
+		&entry.Reason,
+		&entry.Outcome,
+		&beforeJSON,
+		&afterJSON,
+		&entry.CorrelationID,
+		&entry.PreviousHash,
+		&entry.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.DecodeJSON(beforeJSON, &entry.Before); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal before value: %w", err)
+	}
+	if err := repository.DecodeJSON(afterJSON, &entry.After); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal after value: %w", err)
+	}
+
+	return entry, nil
+}
+
+// nullableJSON marshals a value to JSON, or to NULL if it is empty. Like
+// the PostgreSQL repository, snapshots are not field encrypted.
+func nullableJSON(value map[string]interface{}) (interface{}, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,667 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// commandColumns are the columns selected for a command, in scanCommand
+// order
+const commandColumns = `id, command_id, device_id, type, parameters, status, priority, timeout_seconds,
+	result, error_message, executed_at, created_at, updated_at, expires_at`
+
+// commandSortColumns are the columns commands can be sorted by
+var commandSortColumns = map[string]string{
+	"id":              "id",
+	"command_id":      "command_id",
+	"device_id":       "device_id",
+	"type":            "type",
+	"status":          commandStatusOrder,
+	"priority":        "priority",
+	"timeout_seconds": "timeout_seconds",
+	"executed_at":     "executed_at",
+	"expires_at":      "expires_at",
+	"created_at":      "created_at",
+	"updated_at":      "updated_at",
+}
+
+// commandStatusOrder sorts command statuses as the command_status enum
+var commandStatusOrder = enumOrder("status",
+	models.CommandStatusUnknown,
+	models.CommandStatusPending,
+	models.CommandStatusExecuting,
+	models.CommandStatusCompleted,
+	models.CommandStatusFailed,
+	models.CommandStatusTimeout,
+	models.CommandStatusCancelled,
+)
+
+// expiredCommand is the condition of pending or executing commands past
+// their expiry at the time $1
+const expiredCommand = `expires_at IS NOT NULL AND expires_at <= $1 AND status IN ('pending', 'executing')`
+
+// commandRepository implements CommandRepository on SQLite
+type commandRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create creates a new command, together with the signatures it carries
+func (r *commandRepository) Create(ctx context.Context, command *models.Command) error {
+	ctx, span := startSpan(ctx, "command", "Create")
+	defer span.End()
+
+	if err := command.Validate(); err != nil {
+		return fmt.Errorf("command validation failed: %w", err)
+	}
+
+	command.SetDefaults()
+
+	for _, signature := range command.Signatures {
+		signature.CommandID = command.ID
+		signature.SetDefaults()
+		if err := signature.Validate(); err != nil {
+			return fmt.Errorf("command signature validation failed: %w", err)
+		}
+	}
+
+	parametersJSON, err := encodeJSON(command.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameters: %w", err)
+	}
+
+	tx, exec, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO commands (id, command_id, device_id, type, parameters, status, priority, timeout_seconds, created_at, updated_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		command.ID,
+		command.CommandID,
+		command.DeviceID,
+		command.Type,
+		parametersJSON,
+		command.Status,
+		command.Priority,
+		command.TimeoutSeconds,
+		command.CreatedAt,
+		command.UpdatedAt,
+		command.ExpiresAt,
+	)
+	if err != nil {
+		r.logger.WithField("device_id", command.DeviceID).WithError(err).Error("Failed to create command")
+		return fmt.Errorf("failed to create command: %w", err)
+	}
+
+	for _, signature := range command.Signatures {
+		_, err = exec.ExecContext(ctx, `
+			INSERT INTO command_signatures (id, command_id, signer, auth_method, meaning, comment, content_hash, signed_at, token_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			signature.ID,
+			signature.CommandID,
+			signature.Signer,
+			signature.AuthMethod,
+			signature.Meaning,
+			signature.Comment,
+			signature.ContentHash,
+			signature.SignedAt,
+			sql.NullString{String: signature.TokenID, Valid: signature.TokenID != ""},
+		)
+		if err != nil {
+			r.logger.WithField("device_id", command.DeviceID).WithError(err).Error("Failed to store command signature")
+			return fmt.Errorf("failed to store command signature: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit command: %w", err)
+	}
+
+	r.logger.WithField("device_id", command.DeviceID).WithFields(map[string]interface{}{
+		"command_id": command.CommandID,
+		"type":       command.Type,
+		"signatures": len(command.Signatures),
+	}).Info("Command created successfully")
+
+	return nil
+}
+
+// GetByID retrieves a command by ID
+func (r *commandRepository) GetByID(ctx context.Context, id string) (*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetByID")
+	defer span.End()
+
+	return r.get(ctx, "id", id)
+}
+
+// GetByCommandID retrieves a command by command ID
+func (r *commandRepository) GetByCommandID(ctx context.Context, commandID string) (*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetByCommandID")
+	defer span.End()
+
+	return r.get(ctx, "command_id", commandID)
+}
+
+// get retrieves the command whose key column has a value
+func (r *commandRepository) get(ctx context.Context, key, value string) (*models.Command, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+commandColumns+` FROM commands WHERE `+key+` = $1`, value)
+	command, err := scanCommand(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: command %s", repository.ErrNotFound, value)
+		}
+		r.logger.WithError(err).Error("Failed to get command")
+		return nil, fmt.Errorf("failed to get command: %w", err)
+	}
+
+	return command, nil
+}
+
+// Update updates an existing command
+func (r *commandRepository) Update(ctx context.Context, command *models.Command) error {
+	ctx, span := startSpan(ctx, "command", "Update")
+	defer span.End()
+
+	if err := command.Validate(); err != nil {
+		return fmt.Errorf("command validation failed: %w", err)
+	}
+
+	command.UpdatedAt = time.Now()
+
+	parametersJSON, err := encodeJSON(command.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameters: %w", err)
+	}
+
+	resultJSON, err := encodeJSON(command.Result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE commands
+		SET type = $2, parameters = $3, status = $4, priority = $5, timeout_seconds = $6,
+		    result = $7, error_message = $8, executed_at = $9, updated_at = $10, expires_at = $11
+		WHERE id = $1
+	`,
+		command.ID,
+		command.Type,
+		parametersJSON,
+		command.Status,
+		command.Priority,
+		command.TimeoutSeconds,
+		resultJSON,
+		command.ErrorMessage,
+		command.ExecutedAt,
+		command.UpdatedAt,
+		command.ExpiresAt,
+	)
+	if err != nil {
+		r.logger.WithField("device_id", command.DeviceID).WithError(err).Error("Failed to update command")
+		return fmt.Errorf("failed to update command: %w", err)
+	}
+
+	if err := checkAffected(result, "command", command.ID); err != nil {
+		return err
+	}
+
+	r.logger.WithField("device_id", command.DeviceID).WithFields(map[string]interface{}{
+		"command_id": command.CommandID,
+		"status":     command.Status,
+	}).Info("Command updated successfully")
+
+	return nil
+}
+
+// Delete removes a command
+func (r *commandRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "command", "Delete")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM commands WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete command")
+		return fmt.Errorf("failed to delete command: %w", err)
+	}
+
+	if err := checkAffected(result, "command", id); err != nil {
+		return err
+	}
+
+	r.logger.WithField("command_id", id).Info("Command deleted successfully")
+	return nil
+}
+
+// List retrieves commands with filtering and pagination
+func (r *commandRepository) List(ctx context.Context, filter repository.CommandFilter) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "List")
+	defer span.End()
+
+	q, err := commandConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := orderBy(filter.Filter, commandSortColumns, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := r.query(ctx, `SELECT `+commandColumns+` FROM commands`+q.clause()+order+q.page(filter.Filter), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list commands")
+		return nil, fmt.Errorf("failed to list commands: %w", err)
+	}
+
+	return commands, nil
+}
+
+// Count returns the total number of commands matching the filter
+func (r *commandRepository) Count(ctx context.Context, filter repository.CommandFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "command", "Count")
+	defer span.End()
+
+	q, err := commandConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM commands`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count commands")
+		return 0, fmt.Errorf("failed to count commands: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetPendingCommands retrieves the unexpired pending commands for a device,
+// highest priority first
+func (r *commandRepository) GetPendingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetPendingCommands")
+	defer span.End()
+
+	commands, err := r.query(ctx, `
+		SELECT `+commandColumns+`
+		FROM commands
+		WHERE device_id = $1 AND status = 'pending' AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY priority DESC, created_at ASC
+	`, deviceID, time.Now())
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get pending commands")
+		return nil, fmt.Errorf("failed to get pending commands: %w", err)
+	}
+
+	return commands, nil
+}
+
+// GetExecutingCommands retrieves executing commands for a device
+func (r *commandRepository) GetExecutingCommands(ctx context.Context, deviceID string) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetExecutingCommands")
+	defer span.End()
+
+	commands, err := r.query(ctx, `
+		SELECT `+commandColumns+`
+		FROM commands
+		WHERE device_id = $1 AND status = 'executing'
+		ORDER BY created_at ASC
+	`, deviceID)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get executing commands")
+		return nil, fmt.Errorf("failed to get executing commands: %w", err)
+	}
+
+	return commands, nil
+}
+
+// UpdateStatus updates the status of a command
+func (r *commandRepository) UpdateStatus(ctx context.Context, commandID string, status models.CommandStatus) error {
+	ctx, span := startSpan(ctx, "command", "UpdateStatus")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE commands SET status = $2, updated_at = $3 WHERE command_id = $1`, commandID, status, time.Now())
+	if err != nil {
+		r.logger.WithFields(map[string]interface{}{
+			"command_id": commandID,
+			"status":     status,
+		}).WithError(err).Error("Failed to update command status")
+		return fmt.Errorf("failed to update command status: %w", err)
+	}
+
+	if err := checkAffected(result, "command", commandID); err != nil {
+		return err
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"command_id": commandID,
+		"status":     status,
+	}).Info("Command status updated")
+
+	return nil
+}
+
+// Claim marks a pending command executing, returning false when it is no
+// longer pending
+func (r *commandRepository) Claim(ctx context.Context, command *models.Command) (bool, error) {
+	ctx, span := startSpan(ctx, "command", "Claim")
+	defer span.End()
+
+	claimed := *command
+	claimed.StartExecution()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE commands SET status = $2, executed_at = $3, updated_at = $4
+		WHERE id = $1 AND status = 'pending'
+	`, command.ID, claimed.Status, claimed.ExecutedAt, claimed.UpdatedAt)
+	if err != nil {
+		r.logger.WithField("command_id", command.CommandID).WithError(err).Error("Failed to claim command")
+		return false, fmt.Errorf("failed to claim command: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	*command = claimed
+	return true, nil
+}
+
+// GetExpiredCommands retrieves pending or executing commands past their
+// expiry, earliest expiry first
+func (r *commandRepository) GetExpiredCommands(ctx context.Context) ([]*models.Command, error) {
+	ctx, span := startSpan(ctx, "command", "GetExpiredCommands")
+	defer span.End()
+
+	commands, err := r.query(ctx, `
+		SELECT `+commandColumns+`
+		FROM commands
+		WHERE `+expiredCommand+`
+		ORDER BY expires_at ASC
+	`, time.Now())
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get expired commands")
+		return nil, fmt.Errorf("failed to get expired commands: %w", err)
+	}
+
+	return commands, nil
+}
+
+// MarkExpiredAsTimeout marks expired commands as timed out
+func (r *commandRepository) MarkExpiredAsTimeout(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "command", "MarkExpiredAsTimeout")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE commands
+		SET status = 'timeout', error_message = 'Command expired', updated_at = $1
+		WHERE `+expiredCommand, time.Now())
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to mark expired commands as timeout")
+		return 0, fmt.Errorf("failed to mark expired commands as timeout: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithField("expired_count", rowsAffected).Info("Expired commands marked as timeout")
+	}
+
+	return rowsAffected, nil
+}
+
+// DeleteCompletedOlderThan removes finished commands created before the
+// threshold
+func (r *commandRepository) DeleteCompletedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "command", "DeleteCompletedOlderThan")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM commands
+		WHERE created_at < $1 AND status IN ('completed', 'failed', 'timeout')
+	`, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete old completed commands")
+		return 0, fmt.Errorf("failed to delete old completed commands: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithFields(map[string]interface{}{
+			"deleted":   rowsAffected,
+			"threshold": threshold,
+		}).Info("Old completed commands deleted")
+	}
+
+	return rowsAffected, nil
+}
+
+// GetCommandStats retrieves the number of commands of a device in each
+// status, created within the time range
+func (r *commandRepository) GetCommandStats(ctx context.Context, deviceID string, timeRange repository.TimeRangeFilter) (map[models.CommandStatus]int64, error) {
+	ctx, span := startSpan(ctx, "command", "GetCommandStats")
+	defer span.End()
+
+	q := &query{}
+	q.where("device_id = " + q.arg(deviceID))
+	createdWithin(q, timeRange)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM commands`+q.clause()+` GROUP BY status`, q.args...)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get command statistics")
+		return nil, fmt.Errorf("failed to get command statistics: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[models.CommandStatus]int64)
+	for rows.Next() {
+		var status models.CommandStatus
+		var count int64
+
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan command stats: %w", err)
+		}
+
+		stats[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating command stats rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ListSignatures retrieves the signatures of a command, in signing order
+func (r *commandRepository) ListSignatures(ctx context.Context, id string) ([]*models.CommandSignature, error) {
+	ctx, span := startSpan(ctx, "command", "ListSignatures")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, command_id, signer, auth_method, meaning, comment, content_hash, signed_at
+		FROM command_signatures
+		WHERE command_id = $1
+		ORDER BY signed_at ASC, id ASC
+	`, id)
+	if err != nil {
+		r.logger.WithField("id", id).WithError(err).Error("Failed to list command signatures")
+		return nil, fmt.Errorf("failed to list command signatures: %w", err)
+	}
+	defer rows.Close()
+
+	var signatures []*models.CommandSignature
+	for rows.Next() {
+		signature := &models.CommandSignature{}
+		err := rows.Scan(
+			&signature.ID,
+			&signature.CommandID,
+			&signature.Signer,
+			&signature.AuthMethod,
+			&signature.Meaning,
+			&signature.Comment,
+			&signature.ContentHash,
+			&signature.SignedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command signature: %w", err)
+		}
+		signatures = append(signatures, signature)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating command signature rows: %w", err)
+	}
+
+	return signatures, nil
+}
+
+// SignatureTokenUsed reports whether a signer has already signed with the
+// credential of the given token ID
+func (r *commandRepository) SignatureTokenUsed(ctx context.Context, signer, tokenID string) (bool, error) {
+	ctx, span := startSpan(ctx, "command", "SignatureTokenUsed")
+	defer span.End()
+
+	var used bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM command_signatures WHERE signer = $1 AND token_id = $2)
+	`, signer, tokenID).Scan(&used)
+	if err != nil {
+		r.logger.WithField("signer", signer).WithError(err).Error("Failed to check signature token")
+		return false, fmt.Errorf("failed to check signature token: %w", err)
+	}
+
+	return used, nil
+}
+
+// query runs a query selecting commandColumns
+func (r *commandRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Command, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commands []*models.Command
+	for rows.Next() {
+		command, err := scanCommand(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command: %w", err)
+		}
+		commands = append(commands, command)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating command rows: %w", err)
+	}
+
+	return commands, nil
+}
+
+// commandConditions returns the conditions of a command filter
+func commandConditions(filter repository.CommandFilter) (*query, error) {
+	q := &query{}
+
+	if len(filter.DeviceIDs) > 0 {
+		if err := q.in("device_id", filter.DeviceIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Types) > 0 {
+		if err := q.in("type", filter.Types); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Statuses) > 0 {
+		if err := q.in("status", filter.Statuses); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Priorities) > 0 {
+		if err := q.in("priority", filter.Priorities); err != nil {
+			return nil, err
+		}
+	}
+
+	createdWithin(q, filter.TimeRangeFilter)
+
+	return q, nil
+}
+
+// createdWithin adds the conditions that rows were created within a time
+// range
+func createdWithin(q *query, timeRange repository.TimeRangeFilter) {
+	if timeRange.StartTime != nil {
+		q.where("created_at >= " + q.arg(*timeRange.StartTime))
+	}
+
+	if timeRange.EndTime != nil {
+		q.where("created_at <= " + q.arg(*timeRange.EndTime))
+	}
+}
+
+// scanCommand scans a row of commandColumns. A command without a result
+// reads as an empty result.
+func scanCommand(row rowScanner) (*models.Command, error) {
+	command := &models.Command{}
+	var parametersJSON, resultJSON []byte
+
+	err := row.Scan(
+		&command.ID,
+		&command.CommandID,
+		&command.DeviceID,
+		&command.Type,
+		&parametersJSON,
+		&command.Status,
+		&command.Priority,
+		&command.TimeoutSeconds,
+		&resultJSON,
+		&command.ErrorMessage,
+		&command.ExecutedAt,
+		&command.CreatedAt,
+		&command.UpdatedAt,
+		&command.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.DecodeJSON(parametersJSON, &command.Parameters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parameters: %w", err)
+	}
+
+	if len(resultJSON) > 0 {
+		if err := repository.DecodeJSON(resultJSON, &command.Result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+		}
+	}
+	if command.Result == nil {
+		command.Result = make(map[string]interface{})
+	}
+
+	return command, nil
+}
@@ -0,0 +1,606 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// deviceColumns are the columns selected for a device, in scanDevice order
+const deviceColumns = `id, name, type, version, status, metadata, capabilities, last_seen, registered_at, created_at, updated_at`
+
+// deviceSortColumns are the columns devices can be sorted by
+var deviceSortColumns = map[string]string{
+	"id":            "id",
+	"name":          "name",
+	"type":          "type",
+	"version":       "version",
+	"status":        deviceStatusOrder,
+	"last_seen":     "last_seen",
+	"registered_at": "registered_at",
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+}
+
+// deviceStatusOrder sorts device statuses as the device_status enum
+var deviceStatusOrder = enumOrder("status",
+	models.DeviceStatusUnknown,
+	models.DeviceStatusOnline,
+	models.DeviceStatusOffline,
+	models.DeviceStatusError,
+	models.DeviceStatusMaintenance,
+	models.DeviceStatusConnecting,
+)
+
+// deviceRepository implements DeviceRepository on SQLite
+type deviceRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create creates a new device
+func (r *deviceRepository) Create(ctx context.Context, device *models.Device) error {
+	ctx, span := startSpan(ctx, "device", "Create")
+	defer span.End()
+
+	if err := device.Validate(); err != nil {
+		return fmt.Errorf("device validation failed: %w", err)
+	}
+
+	now := time.Now()
+	if device.RegisteredAt.IsZero() {
+		device.RegisteredAt = now
+	}
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = now
+	}
+	device.UpdatedAt = now
+
+	if err := r.insert(ctx, r.db, device); err != nil {
+		r.logger.WithField("device_id", device.ID).WithError(err).Error("Failed to create device")
+		return fmt.Errorf("failed to create device: %w", err)
+	}
+
+	r.logger.WithField("device_id", device.ID).Info("Device created successfully")
+	return nil
+}
+
+// insert inserts a device row
+func (r *deviceRepository) insert(ctx context.Context, exec executor, device *models.Device) error {
+	metadataJSON, err := encodeJSON(device.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO devices (id, name, type, version, status, metadata, capabilities, last_seen, registered_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		device.ID,
+		device.Name,
+		device.Type,
+		device.Version,
+		device.Status,
+		metadataJSON,
+		stringArray(device.Capabilities),
+		device.LastSeen,
+		device.RegisteredAt,
+		device.CreatedAt,
+		device.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a device by ID
+func (r *deviceRepository) GetByID(ctx context.Context, id string) (*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetByID")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+deviceColumns+` FROM devices WHERE id = $1`, id)
+	device, err := scanDevice(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: device %s", repository.ErrNotFound, id)
+		}
+		r.logger.WithField("device_id", id).WithError(err).Error("Failed to get device")
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+
+	return device, nil
+}
+
+// Update updates an existing device
+func (r *deviceRepository) Update(ctx context.Context, device *models.Device) error {
+	ctx, span := startSpan(ctx, "device", "Update")
+	defer span.End()
+
+	if err := device.Validate(); err != nil {
+		return fmt.Errorf("device validation failed: %w", err)
+	}
+
+	device.UpdatedAt = time.Now()
+
+	if err := r.update(ctx, r.db, device); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		r.logger.WithField("device_id", device.ID).WithError(err).Error("Failed to update device")
+		return fmt.Errorf("failed to update device: %w", err)
+	}
+
+	r.logger.WithField("device_id", device.ID).Info("Device updated successfully")
+	return nil
+}
+
+// update updates the changeable columns of a device row
+func (r *deviceRepository) update(ctx context.Context, exec executor, device *models.Device) error {
+	metadataJSON, err := encodeJSON(device.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	result, err := exec.ExecContext(ctx, `
+		UPDATE devices
+		SET name = $2, type = $3, version = $4, status = $5, metadata = $6, capabilities = $7, last_seen = $8, updated_at = $9
+		WHERE id = $1
+	`,
+		device.ID,
+		device.Name,
+		device.Type,
+		device.Version,
+		device.Status,
+		metadataJSON,
+		stringArray(device.Capabilities),
+		device.LastSeen,
+		device.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result, "device", device.ID)
+}
+
+// Delete removes a device and, through the foreign keys, everything
+// recorded for it
+func (r *deviceRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "device", "Delete")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM devices WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("device_id", id).WithError(err).Error("Failed to delete device")
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+
+	if err := checkAffected(result, "device", id); err != nil {
+		return err
+	}
+
+	r.logger.WithField("device_id", id).Info("Device deleted successfully")
+	return nil
+}
+
+// CreateBulk creates multiple devices in a single transaction. Invalid
+// devices are skipped and reported; a failed insert fails the whole batch,
+// as it aborts the transaction of the PostgreSQL implementation.
+func (r *deviceRepository) CreateBulk(ctx context.Context, devices []*models.Device) (*repository.BulkResult, error) {
+	ctx, span := startSpan(ctx, "device", "CreateBulk")
+	defer span.End()
+
+	result := &repository.BulkResult{}
+	if len(devices) == 0 {
+		return result, nil
+	}
+
+	tx, exec, err := r.db.begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, device := range devices {
+		if err := device.Validate(); err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("device %s validation failed: %w", device.ID, err))
+			continue
+		}
+
+		if device.RegisteredAt.IsZero() {
+			device.RegisteredAt = now
+		}
+		if device.CreatedAt.IsZero() {
+			device.CreatedAt = now
+		}
+		device.UpdatedAt = now
+
+		if err := r.insert(ctx, exec, device); err != nil {
+			return failBatch(result, fmt.Errorf("device %s insert failed: %w", device.ID, err))
+		}
+		result.SuccessCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return failBatch(result, err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"success_count": result.SuccessCount,
+		"failure_count": result.FailureCount,
+	}).Info("Bulk device creation completed")
+
+	return result, nil
+}
+
+// UpdateBulk updates multiple devices in a single transaction, reporting
+// the ones that failed
+func (r *deviceRepository) UpdateBulk(ctx context.Context, devices []*models.Device) (*repository.BulkResult, error) {
+	ctx, span := startSpan(ctx, "device", "UpdateBulk")
+	defer span.End()
+
+	result := &repository.BulkResult{}
+	if len(devices) == 0 {
+		return result, nil
+	}
+
+	tx, exec, err := r.db.begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	for _, device := range devices {
+		if err := device.Validate(); err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("device %s validation failed: %w", device.ID, err))
+			continue
+		}
+
+		device.UpdatedAt = time.Now()
+
+		if err := r.update(ctx, exec, device); err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("device %s update failed: %w", device.ID, err))
+			continue
+		}
+		result.SuccessCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"success_count": result.SuccessCount,
+		"failure_count": result.FailureCount,
+	}).Info("Bulk device update completed")
+
+	return result, nil
+}
+
+// List retrieves devices with filtering and pagination
+func (r *deviceRepository) List(ctx context.Context, filter repository.DeviceFilter) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "List")
+	defer span.End()
+
+	q, err := deviceConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := orderBy(filter.Filter, deviceSortColumns, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := r.query(ctx, `SELECT `+deviceColumns+` FROM devices`+q.clause()+order+q.page(filter.Filter), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list devices")
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// Count returns the total number of devices matching the filter
+func (r *deviceRepository) Count(ctx context.Context, filter repository.DeviceFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "device", "Count")
+	defer span.End()
+
+	q, err := deviceConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM devices`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count devices")
+		return 0, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateStatus updates device status
+func (r *deviceRepository) UpdateStatus(ctx context.Context, deviceID string, status models.DeviceStatus) error {
+	ctx, span := startSpan(ctx, "device", "UpdateStatus")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE devices SET status = $2, updated_at = $3 WHERE id = $1`, deviceID, status, time.Now())
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to update device status")
+		return fmt.Errorf("failed to update device status: %w", err)
+	}
+
+	if err := checkAffected(result, "device", deviceID); err != nil {
+		return err
+	}
+
+	r.logger.WithField("device_id", deviceID).WithFields(map[string]interface{}{
+		"status": status,
+	}).Info("Device status updated")
+
+	return nil
+}
+
+// UpdateLastSeen updates the last seen timestamp
+func (r *deviceRepository) UpdateLastSeen(ctx context.Context, deviceID string, timestamp time.Time) error {
+	ctx, span := startSpan(ctx, "device", "UpdateLastSeen")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE devices SET last_seen = $2, updated_at = $3 WHERE id = $1`, deviceID, timestamp, time.Now())
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to update device last seen")
+		return fmt.Errorf("failed to update device last seen: %w", err)
+	}
+
+	return checkAffected(result, "device", deviceID)
+}
+
+// GetStatusCounts retrieves the number of devices in each status
+func (r *deviceRepository) GetStatusCounts(ctx context.Context) (map[models.DeviceStatus]int64, error) {
+	ctx, span := startSpan(ctx, "device", "GetStatusCounts")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM devices GROUP BY status`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to count devices by status")
+		return nil, fmt.Errorf("failed to count devices by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[models.DeviceStatus]int64)
+	for rows.Next() {
+		var status models.DeviceStatus
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan device status count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device status counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// SearchByMetadata searches devices by metadata fields, comparing them as
+// text
+func (r *deviceRepository) SearchByMetadata(ctx context.Context, metadata map[string]interface{}) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "SearchByMetadata")
+	defer span.End()
+
+	if len(metadata) == 0 {
+		return []*models.Device{}, nil
+	}
+
+	q := &query{}
+	for key, value := range metadata {
+		q.where(fmt.Sprintf("%s = %s", jsonText("metadata", q.arg(jsonPath(key))), q.arg(fmt.Sprint(value))))
+	}
+
+	devices, err := r.query(ctx, `SELECT `+deviceColumns+` FROM devices`+q.clause()+` ORDER BY created_at DESC`, q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to search devices by metadata")
+		return nil, fmt.Errorf("failed to search devices by metadata: %w", err)
+	}
+
+	return devices, nil
+}
+
+// GetByCapability retrieves devices with a specific capability
+func (r *deviceRepository) GetByCapability(ctx context.Context, capability string) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetByCapability")
+	defer span.End()
+
+	devices, err := r.query(ctx, `
+		SELECT `+deviceColumns+`
+		FROM devices
+		WHERE EXISTS (SELECT 1 FROM json_each(capabilities) WHERE value = $1)
+		ORDER BY created_at DESC
+	`, capability)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get devices by capability")
+		return nil, fmt.Errorf("failed to get devices by capability: %w", err)
+	}
+
+	return devices, nil
+}
+
+// GetOnlineDevices retrieves all online devices, most recently seen first
+func (r *deviceRepository) GetOnlineDevices(ctx context.Context) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetOnlineDevices")
+	defer span.End()
+
+	devices, err := r.query(ctx, `
+		SELECT `+deviceColumns+`
+		FROM devices
+		WHERE status = 'online'
+		ORDER BY last_seen DESC NULLS FIRST
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get online devices")
+		return nil, fmt.Errorf("failed to get online devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// GetOfflineDevices retrieves devices not seen within the threshold, except
+// those in maintenance, least recently seen first
+func (r *deviceRepository) GetOfflineDevices(ctx context.Context, threshold time.Duration) ([]*models.Device, error) {
+	ctx, span := startSpan(ctx, "device", "GetOfflineDevices")
+	defer span.End()
+
+	devices, err := r.query(ctx, `
+		SELECT `+deviceColumns+`
+		FROM devices
+		WHERE (last_seen IS NULL OR last_seen < $1) AND status != 'maintenance'
+		ORDER BY last_seen ASC NULLS FIRST
+	`, time.Now().Add(-threshold))
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get offline devices")
+		return nil, fmt.Errorf("failed to get offline devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// query runs a query selecting deviceColumns
+func (r *deviceRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Device, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*models.Device
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device rows: %w", err)
+	}
+
+	return devices, nil
+}
+
+// deviceConditions returns the conditions of a device filter
+func deviceConditions(filter repository.DeviceFilter) (*query, error) {
+	q := &query{}
+
+	if len(filter.DeviceIDs) > 0 {
+		if err := q.in("id", filter.DeviceIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Types) > 0 {
+		if err := q.in("type", filter.Types); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Statuses) > 0 {
+		if err := q.in("status", filter.Statuses); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, capability := range filter.Capabilities {
+		q.where(fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(capabilities) WHERE value = %s)", q.arg(capability)))
+	}
+
+	if filter.LastSeenAfter != nil {
+		q.where("last_seen > " + q.arg(*filter.LastSeenAfter))
+	}
+
+	if filter.LastSeenBefore != nil {
+		q.where("last_seen < " + q.arg(*filter.LastSeenBefore))
+	}
+
+	for key, value := range filter.MetadataFilters {
+		q.where(fmt.Sprintf("%s = %s", jsonText("metadata", q.arg(jsonPath(key))), q.arg(fmt.Sprint(value))))
+	}
+
+	if len(filter.Groups) > 0 {
+		if err := q.in(jsonText("metadata", q.arg(jsonPath(models.DeviceGroupMetadataKey))), filter.Groups); err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDevice scans a row of deviceColumns
+func scanDevice(row rowScanner) (*models.Device, error) {
+	device := &models.Device{}
+	var metadataJSON []byte
+	var capabilities stringArray
+
+	err := row.Scan(
+		&device.ID,
+		&device.Name,
+		&device.Type,
+		&device.Version,
+		&device.Status,
+		&metadataJSON,
+		&capabilities,
+		&device.LastSeen,
+		&device.RegisteredAt,
+		&device.CreatedAt,
+		&device.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	device.Capabilities = []string(capabilities)
+
+	if err := repository.DecodeJSON(metadataJSON, &device.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return device, nil
+}
+
+// checkAffected returns ErrNotFound if a statement changed no row
+func checkAffected(result sql.Result, kind, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s %s", repository.ErrNotFound, kind, id)
+	}
+
+	return nil
+}
+
+// failBatch reports a bulk insert that failed as a whole
+func failBatch(result *repository.BulkResult, err error) (*repository.BulkResult, error) {
+	result.FailureCount += result.SuccessCount
+	result.SuccessCount = 0
+	result.Errors = append(result.Errors, err)
+	return result, fmt.Errorf("failed to commit transaction: %w", err)
+}
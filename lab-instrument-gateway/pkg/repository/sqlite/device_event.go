@@ -0,0 +1,242 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// deviceEventColumns are the columns selected for a device event, in
+// scanDeviceEvent order
+const deviceEventColumns = `id, device_id, type, session_id, from_status, to_status, message, metadata, occurred_at`
+
+// deviceEventSortColumns are the columns device events can be sorted by
+var deviceEventSortColumns = map[string]string{
+	"id":          "id",
+	"device_id":   "device_id",
+	"type":        "type",
+	"session_id":  "session_id",
+	"message":     "message",
+	"occurred_at": "occurred_at",
+}
+
+// deviceEventRepository implements DeviceEventRepository on SQLite
+type deviceEventRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create appends an event to the device event log
+func (r *deviceEventRepository) Create(ctx context.Context, event *models.DeviceEvent) error {
+	ctx, span := startSpan(ctx, "device_event", "Create")
+	defer span.End()
+
+	event.SetDefaults()
+
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("device event validation failed: %w", err)
+	}
+
+	metadataJSON, err := encodeJSON(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO device_events (id, device_id, type, session_id, from_status, to_status, message, metadata, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		event.ID,
+		event.DeviceID,
+		event.Type,
+		event.SessionID,
+		event.FromStatus,
+		event.ToStatus,
+		event.Message,
+		metadataJSON,
+		event.OccurredAt,
+	)
+	if err != nil {
+		r.logger.WithFields(map[string]interface{}{
+			"device_id": event.DeviceID,
+			"type":      event.Type,
+		}).WithError(err).Error("Failed to create device event")
+		return fmt.Errorf("failed to create device event: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"event_id":  event.ID,
+		"device_id": event.DeviceID,
+		"type":      event.Type,
+	}).Debug("Device event recorded")
+
+	return nil
+}
+
+// List retrieves device events with filtering and pagination
+func (r *deviceEventRepository) List(ctx context.Context, filter repository.DeviceEventFilter) ([]*models.DeviceEvent, error) {
+	ctx, span := startSpan(ctx, "device_event", "List")
+	defer span.End()
+
+	q, err := deviceEventConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := orderBy(filter.Filter, deviceEventSortColumns, "occurred_at")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+deviceEventColumns+` FROM device_events`+q.clause()+order+q.page(filter.Filter), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list device events")
+		return nil, fmt.Errorf("failed to list device events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.DeviceEvent
+	for rows.Next() {
+		event, err := scanDeviceEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// Count returns the number of device events matching the filter
+func (r *deviceEventRepository) Count(ctx context.Context, filter repository.DeviceEventFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "device_event", "Count")
+	defer span.End()
+
+	q, err := deviceEventConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM device_events`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count device events")
+		return 0, fmt.Errorf("failed to count device events: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetLastBefore retrieves the most recent event of the given type that
+// occurred before the given time
+func (r *deviceEventRepository) GetLastBefore(ctx context.Context, deviceID string, eventType models.DeviceEventType, before time.Time) (*models.DeviceEvent, error) {
+	ctx, span := startSpan(ctx, "device_event", "GetLastBefore")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+deviceEventColumns+`
+		FROM device_events
+		WHERE device_id = $1 AND type = $2 AND occurred_at < $3
+		ORDER BY occurred_at DESC
+		LIMIT 1
+	`, deviceID, eventType, before)
+	event, err := scanDeviceEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s event for device %s", repository.ErrNotFound, eventType, deviceID)
+		}
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get last device event")
+		return nil, fmt.Errorf("failed to get last device event: %w", err)
+	}
+
+	return event, nil
+}
+
+// DeleteOlderThan removes events that occurred before the threshold
+func (r *deviceEventRepository) DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "device_event", "DeleteOlderThan")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM device_events WHERE occurred_at < $1`, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete old device events")
+		return 0, fmt.Errorf("failed to delete old device events: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithFields(map[string]interface{}{
+			"deleted":   rowsAffected,
+			"threshold": threshold,
+		}).Info("Old device events deleted")
+	}
+
+	return rowsAffected, nil
+}
+
+// deviceEventConditions returns the conditions of a device event filter
+func deviceEventConditions(filter repository.DeviceEventFilter) (*query, error) {
+	q := &query{}
+
+	if filter.DeviceID != "" {
+		q.where("device_id = " + q.arg(filter.DeviceID))
+	}
+
+	if len(filter.Types) > 0 {
+		if err := q.in("type", filter.Types); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.StartTime != nil {
+		q.where("occurred_at >= " + q.arg(*filter.StartTime))
+	}
+
+	if filter.EndTime != nil {
+		q.where("occurred_at <= " + q.arg(*filter.EndTime))
+	}
+
+	return q, nil
+}
+
+// scanDeviceEvent scans a row of deviceEventColumns
+func scanDeviceEvent(row rowScanner) (*models.DeviceEvent, error) {
+	event := &models.DeviceEvent{}
+	var message sql.NullString
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&event.ID,
+		&event.DeviceID,
+		&event.Type,
+		&event.SessionID,
+		&event.FromStatus,
+		&event.ToStatus,
+		&message,
+		&metadataJSON,
+		&event.OccurredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Message = message.String
+	if err := repository.DecodeJSON(metadataJSON, &event.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return event, nil
+}
@@ -0,0 +1,214 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// deviceSessionColumns are the columns selected for a device session, in
+// scanDeviceSession order
+const deviceSessionColumns = `id, device_id, session_id, stream_id, connected_at, last_heartbeat, metadata, is_active, disconnected_at, disconnect_reason`
+
+// deviceSessionRepository implements DeviceSessionRepository on SQLite
+type deviceSessionRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create persists a new device session. The ID is generated here, as the
+// column default does in PostgreSQL.
+func (r *deviceSessionRepository) Create(ctx context.Context, session *models.DeviceSession) error {
+	ctx, span := startSpan(ctx, "device_session", "Create")
+	defer span.End()
+
+	if session.DeviceID == "" || session.SessionID == "" {
+		return fmt.Errorf("device session requires device ID and session ID")
+	}
+
+	metadataJSON, err := encodeJSON(session.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	id := uuid.New().String()
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO device_sessions (id, device_id, session_id, stream_id, connected_at, last_heartbeat, metadata, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		id,
+		session.DeviceID,
+		session.SessionID,
+		session.StreamID,
+		session.ConnectedAt,
+		session.LastHeartbeat,
+		metadataJSON,
+		session.IsActive,
+	)
+	if err != nil {
+		r.logger.WithField("session_id", session.SessionID).WithError(err).Error("Failed to create device session")
+		return fmt.Errorf("failed to create device session: %w", err)
+	}
+
+	session.ID = id
+
+	r.logger.WithFields(map[string]interface{}{
+		"device_id":  session.DeviceID,
+		"session_id": session.SessionID,
+	}).Debug("Device session persisted")
+
+	return nil
+}
+
+// GetBySessionID retrieves a device session by its session ID
+func (r *deviceSessionRepository) GetBySessionID(ctx context.Context, sessionID string) (*models.DeviceSession, error) {
+	ctx, span := startSpan(ctx, "device_session", "GetBySessionID")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+deviceSessionColumns+` FROM device_sessions WHERE session_id = $1`, sessionID)
+	session, err := scanDeviceSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: device session %s", repository.ErrNotFound, sessionID)
+		}
+		r.logger.WithField("session_id", sessionID).WithError(err).Error("Failed to get device session")
+		return nil, fmt.Errorf("failed to get device session: %w", err)
+	}
+
+	return session, nil
+}
+
+// ListByDevice retrieves the sessions of a device that overlap the time
+// range, newest first
+func (r *deviceSessionRepository) ListByDevice(ctx context.Context, deviceID string, timeRange repository.TimeRangeFilter, limit int) ([]*models.DeviceSession, error) {
+	ctx, span := startSpan(ctx, "device_session", "ListByDevice")
+	defer span.End()
+
+	q := &query{}
+	q.where("device_id = " + q.arg(deviceID))
+
+	if timeRange.StartTime != nil {
+		q.where("(disconnected_at IS NULL OR disconnected_at >= " + q.arg(*timeRange.StartTime) + ")")
+	}
+
+	if timeRange.EndTime != nil {
+		q.where("connected_at <= " + q.arg(*timeRange.EndTime))
+	}
+
+	statement := `SELECT ` + deviceSessionColumns + ` FROM device_sessions` + q.clause() + ` ORDER BY connected_at DESC`
+	if limit > 0 {
+		statement += " LIMIT " + q.arg(limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, statement, q.args...)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to list device sessions")
+		return nil, fmt.Errorf("failed to list device sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.DeviceSession
+	for rows.Next() {
+		session, err := scanDeviceSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device session rows: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// End marks a session as ended at the given time
+func (r *deviceSessionRepository) End(ctx context.Context, sessionID string, endedAt time.Time, reason string) error {
+	ctx, span := startSpan(ctx, "device_session", "End")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE device_sessions
+		SET is_active = 0, disconnected_at = $2, disconnect_reason = $3
+		WHERE session_id = $1
+	`, sessionID, endedAt, reason)
+	if err != nil {
+		r.logger.WithField("session_id", sessionID).WithError(err).Error("Failed to end device session")
+		return fmt.Errorf("failed to end device session: %w", err)
+	}
+
+	return checkAffected(result, "device session", sessionID)
+}
+
+// Resume reactivates a session whose device came back before reconnecting
+func (r *deviceSessionRepository) Resume(ctx context.Context, sessionID string, at time.Time) error {
+	ctx, span := startSpan(ctx, "device_session", "Resume")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE device_sessions
+		SET is_active = 1, disconnected_at = NULL, disconnect_reason = NULL, last_heartbeat = $2
+		WHERE session_id = $1
+	`, sessionID, at)
+	if err != nil {
+		r.logger.WithField("session_id", sessionID).WithError(err).Error("Failed to resume device session")
+		return fmt.Errorf("failed to resume device session: %w", err)
+	}
+
+	return checkAffected(result, "device session", sessionID)
+}
+
+// DeleteEndedOlderThan removes sessions that ended before the threshold
+func (r *deviceSessionRepository) DeleteEndedOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "device_session", "DeleteEndedOlderThan")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM device_sessions WHERE is_active = 0 AND disconnected_at < $1`, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete ended device sessions")
+		return 0, fmt.Errorf("failed to delete ended device sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// scanDeviceSession scans a row of deviceSessionColumns
+func scanDeviceSession(row rowScanner) (*models.DeviceSession, error) {
+	session := &models.DeviceSession{}
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&session.ID,
+		&session.DeviceID,
+		&session.SessionID,
+		&session.StreamID,
+		&session.ConnectedAt,
+		&session.LastHeartbeat,
+		&metadataJSON,
+		&session.IsActive,
+		&session.DisconnectedAt,
+		&session.DisconnectReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.DecodeJSON(metadataJSON, &session.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,508 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// escalationStepRecord is the stored JSON form of an escalation step, the
+// same as in the PostgreSQL schema
+type escalationStepRecord struct {
+	DelaySeconds int64    `json:"delay_seconds"`
+	Recipients   []string `json:"recipients,omitempty"`
+	ScheduleID   *string  `json:"schedule_id,omitempty"`
+}
+
+// escalationRepository implements EscalationRepository on SQLite
+type escalationRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// CreatePolicy creates a new escalation policy
+func (r *escalationRepository) CreatePolicy(ctx context.Context, policy *models.EscalationPolicy) error {
+	ctx, span := startSpan(ctx, "escalation", "CreatePolicy")
+	defer span.End()
+
+	policy.SetDefaults()
+
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("escalation policy validation failed: %w", err)
+	}
+
+	stepsJSON, err := encodeSteps(policy.Steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO escalation_policies (id, name, description, steps, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		policy.ID,
+		policy.Name,
+		policy.Description,
+		stepsJSON,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("policy_id", policy.ID).WithError(err).Error("Failed to create escalation policy")
+		return fmt.Errorf("failed to create escalation policy: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"policy_id": policy.ID,
+		"name":      policy.Name,
+		"steps":     len(policy.Steps),
+	}).Info("Escalation policy created successfully")
+
+	return nil
+}
+
+// GetPolicy retrieves an escalation policy by ID
+func (r *escalationRepository) GetPolicy(ctx context.Context, id string) (*models.EscalationPolicy, error) {
+	ctx, span := startSpan(ctx, "escalation", "GetPolicy")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, name, description, steps, created_at, updated_at
+		FROM escalation_policies
+		WHERE id = $1
+	`, id)
+	policy, err := scanPolicy(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: escalation policy %s", repository.ErrNotFound, id)
+		}
+		r.logger.WithField("policy_id", id).WithError(err).Error("Failed to get escalation policy")
+		return nil, fmt.Errorf("failed to get escalation policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// UpdatePolicy updates an existing escalation policy
+func (r *escalationRepository) UpdatePolicy(ctx context.Context, policy *models.EscalationPolicy) error {
+	ctx, span := startSpan(ctx, "escalation", "UpdatePolicy")
+	defer span.End()
+
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("escalation policy validation failed: %w", err)
+	}
+
+	stepsJSON, err := encodeSteps(policy.Steps)
+	if err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE escalation_policies
+		SET name = $2, description = $3, steps = $4, updated_at = $5
+		WHERE id = $1
+	`,
+		policy.ID,
+		policy.Name,
+		policy.Description,
+		stepsJSON,
+		policy.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("policy_id", policy.ID).WithError(err).Error("Failed to update escalation policy")
+		return fmt.Errorf("failed to update escalation policy: %w", err)
+	}
+
+	return checkAffected(result, "escalation policy", policy.ID)
+}
+
+// DeletePolicy removes an escalation policy and the routes that use it
+func (r *escalationRepository) DeletePolicy(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "escalation", "DeletePolicy")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM escalation_policies WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("policy_id", id).WithError(err).Error("Failed to delete escalation policy")
+		return fmt.Errorf("failed to delete escalation policy: %w", err)
+	}
+
+	return checkAffected(result, "escalation policy", id)
+}
+
+// ListPolicies retrieves all escalation policies
+func (r *escalationRepository) ListPolicies(ctx context.Context) ([]*models.EscalationPolicy, error) {
+	ctx, span := startSpan(ctx, "escalation", "ListPolicies")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, description, steps, created_at, updated_at
+		FROM escalation_policies
+		ORDER BY name
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list escalation policies")
+		return nil, fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*models.EscalationPolicy
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan escalation policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating escalation policy rows: %w", err)
+	}
+
+	return policies, nil
+}
+
+// CreateRoute creates a new alert route
+func (r *escalationRepository) CreateRoute(ctx context.Context, route *models.AlertRoute) error {
+	ctx, span := startSpan(ctx, "escalation", "CreateRoute")
+	defer span.End()
+
+	route.SetDefaults()
+
+	if err := route.Validate(); err != nil {
+		return fmt.Errorf("alert route validation failed: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alert_routes (id, name, priority, device_type, alert_type, min_severity, policy_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		route.ID,
+		route.Name,
+		route.Priority,
+		route.DeviceType,
+		route.AlertType,
+		route.MinSeverity,
+		route.PolicyID,
+		route.CreatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("route_id", route.ID).WithError(err).Error("Failed to create alert route")
+		return fmt.Errorf("failed to create alert route: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"route_id":  route.ID,
+		"name":      route.Name,
+		"policy_id": route.PolicyID,
+	}).Info("Alert route created successfully")
+
+	return nil
+}
+
+// DeleteRoute removes an alert route
+func (r *escalationRepository) DeleteRoute(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "escalation", "DeleteRoute")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_routes WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("route_id", id).WithError(err).Error("Failed to delete alert route")
+		return fmt.Errorf("failed to delete alert route: %w", err)
+	}
+
+	return checkAffected(result, "alert route", id)
+}
+
+// ListRoutes retrieves all alert routes in ascending priority order
+func (r *escalationRepository) ListRoutes(ctx context.Context) ([]*models.AlertRoute, error) {
+	ctx, span := startSpan(ctx, "escalation", "ListRoutes")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, priority, device_type, alert_type, min_severity, policy_id, created_at
+		FROM alert_routes
+		ORDER BY priority, created_at
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list alert routes")
+		return nil, fmt.Errorf("failed to list alert routes: %w", err)
+	}
+	defer rows.Close()
+
+	var routes []*models.AlertRoute
+	for rows.Next() {
+		route := &models.AlertRoute{}
+		err := rows.Scan(
+			&route.ID,
+			&route.Name,
+			&route.Priority,
+			&route.DeviceType,
+			&route.AlertType,
+			&route.MinSeverity,
+			&route.PolicyID,
+			&route.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert route: %w", err)
+		}
+		routes = append(routes, route)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert route rows: %w", err)
+	}
+
+	return routes, nil
+}
+
+// CreateSchedule creates a new on-call schedule
+func (r *escalationRepository) CreateSchedule(ctx context.Context, schedule *models.OnCallSchedule) error {
+	ctx, span := startSpan(ctx, "escalation", "CreateSchedule")
+	defer span.End()
+
+	schedule.SetDefaults()
+
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("on-call schedule validation failed: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO on_call_schedules (id, name, rotation_start, participants, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`,
+		schedule.ID,
+		schedule.Name,
+		schedule.RotationStart,
+		stringArray(schedule.Participants),
+		schedule.CreatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("schedule_id", schedule.ID).WithError(err).Error("Failed to create on-call schedule")
+		return fmt.Errorf("failed to create on-call schedule: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"schedule_id":  schedule.ID,
+		"name":         schedule.Name,
+		"participants": len(schedule.Participants),
+	}).Info("On-call schedule created successfully")
+
+	return nil
+}
+
+// GetSchedule retrieves an on-call schedule by ID
+func (r *escalationRepository) GetSchedule(ctx context.Context, id string) (*models.OnCallSchedule, error) {
+	ctx, span := startSpan(ctx, "escalation", "GetSchedule")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, name, rotation_start, participants, created_at
+		FROM on_call_schedules
+		WHERE id = $1
+	`, id)
+	schedule, err := scanSchedule(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: on-call schedule %s", repository.ErrNotFound, id)
+		}
+		r.logger.WithField("schedule_id", id).WithError(err).Error("Failed to get on-call schedule")
+		return nil, fmt.Errorf("failed to get on-call schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule removes an on-call schedule
+func (r *escalationRepository) DeleteSchedule(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "escalation", "DeleteSchedule")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM on_call_schedules WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("schedule_id", id).WithError(err).Error("Failed to delete on-call schedule")
+		return fmt.Errorf("failed to delete on-call schedule: %w", err)
+	}
+
+	return checkAffected(result, "on-call schedule", id)
+}
+
+// ListSchedules retrieves all on-call schedules
+func (r *escalationRepository) ListSchedules(ctx context.Context) ([]*models.OnCallSchedule, error) {
+	ctx, span := startSpan(ctx, "escalation", "ListSchedules")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, rotation_start, participants, created_at
+		FROM on_call_schedules
+		ORDER BY name
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list on-call schedules")
+		return nil, fmt.Errorf("failed to list on-call schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*models.OnCallSchedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan on-call schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating on-call schedule rows: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// GetEscalations retrieves the escalation progress of the given alerts,
+// keyed by alert ID. Alerts that have never escalated are omitted.
+func (r *escalationRepository) GetEscalations(ctx context.Context, alertIDs []string) (map[string]*models.AlertEscalation, error) {
+	ctx, span := startSpan(ctx, "escalation", "GetEscalations")
+	defer span.End()
+
+	escalations := make(map[string]*models.AlertEscalation)
+	if len(alertIDs) == 0 {
+		return escalations, nil
+	}
+
+	q := &query{}
+	if err := q.in("alert_id", alertIDs); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT alert_id, policy_id, level, last_escalated_at FROM alert_escalations`+q.clause(), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get alert escalations")
+		return nil, fmt.Errorf("failed to get alert escalations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		escalation := &models.AlertEscalation{}
+		var policyID sql.NullString
+		if err := rows.Scan(&escalation.AlertID, &policyID, &escalation.Level, &escalation.LastEscalatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert escalation: %w", err)
+		}
+		escalation.PolicyID = policyID.String
+		escalations[escalation.AlertID] = escalation
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert escalation rows: %w", err)
+	}
+
+	return escalations, nil
+}
+
+// RecordEscalation creates or updates the escalation progress of an alert
+func (r *escalationRepository) RecordEscalation(ctx context.Context, escalation *models.AlertEscalation) error {
+	ctx, span := startSpan(ctx, "escalation", "RecordEscalation")
+	defer span.End()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alert_escalations (alert_id, policy_id, level, last_escalated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (alert_id) DO UPDATE
+		SET policy_id = excluded.policy_id, level = excluded.level, last_escalated_at = excluded.last_escalated_at
+	`,
+		escalation.AlertID,
+		escalation.PolicyID,
+		escalation.Level,
+		escalation.LastEscalatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("alert_id", escalation.AlertID).WithError(err).Error("Failed to record alert escalation")
+		return fmt.Errorf("failed to record alert escalation: %w", err)
+	}
+
+	return nil
+}
+
+// scanPolicy scans a row into an escalation policy
+func scanPolicy(row rowScanner) (*models.EscalationPolicy, error) {
+	policy := &models.EscalationPolicy{}
+	var description sql.NullString
+	var stepsJSON []byte
+
+	err := row.Scan(
+		&policy.ID,
+		&policy.Name,
+		&description,
+		&stepsJSON,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Description = description.String
+
+	var records []escalationStepRecord
+	if err := repository.DecodeJSON(stepsJSON, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal escalation steps: %w", err)
+	}
+
+	policy.Steps = make([]models.EscalationStep, len(records))
+	for i, record := range records {
+		policy.Steps[i] = models.EscalationStep{
+			Delay:      time.Duration(record.DelaySeconds) * time.Second,
+			Recipients: record.Recipients,
+			ScheduleID: record.ScheduleID,
+		}
+	}
+
+	return policy, nil
+}
+
+// scanSchedule scans a row into an on-call schedule
+func scanSchedule(row rowScanner) (*models.OnCallSchedule, error) {
+	schedule := &models.OnCallSchedule{}
+	var participants stringArray
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.RotationStart,
+		&participants,
+		&schedule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Participants = []string(participants)
+	return schedule, nil
+}
+
+// encodeSteps converts escalation steps to their stored JSON form
+func encodeSteps(steps []models.EscalationStep) (string, error) {
+	records := make([]escalationStepRecord, len(steps))
+	for i, step := range steps {
+		records[i] = escalationStepRecord{
+			DelaySeconds: int64(step.Delay / time.Second),
+			Recipients:   step.Recipients,
+			ScheduleID:   step.ScheduleID,
+		}
+	}
+
+	stepsJSON, err := encodeJSON(records)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal escalation steps: %w", err)
+	}
+
+	return stepsJSON, nil
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+)
+
+// timeFormat is the text timestamps are stored as: UTC with a fixed number
+// of fractional digits, so that comparing the text compares the times. It
+// keeps the microsecond precision of the PostgreSQL timestamp columns.
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+// formatTime returns the stored text of a time
+func formatTime(t time.Time) string {
+	return t.Round(time.Microsecond).UTC().Format(timeFormat)
+}
+
+// parseTime parses a stored time that the driver did not convert, such as
+// the result of an aggregate over a timestamp column
+func parseTime(text string) (time.Time, error) {
+	t, err := time.Parse(timeFormat, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", text, err)
+	}
+	return t, nil
+}
+
+// executor runs queries on the connection manager or a transaction, binding
+// time arguments as text in timeFormat. The driver binds them in a format
+// whose text does not sort in time order.
+type executor struct {
+	exec db.Executor
+}
+
+// ExecContext executes a statement
+func (e executor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return e.exec.ExecContext(ctx, query, bindArgs(args)...)
+}
+
+// QueryContext executes a query returning rows
+func (e executor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return e.exec.QueryContext(ctx, query, bindArgs(args)...)
+}
+
+// QueryRowContext executes a query returning at most one row
+func (e executor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return e.exec.QueryRowContext(ctx, query, bindArgs(args)...)
+}
+
+// begin starts a transaction, or joins the one the executor runs in, and
+// returns it with an executor running queries in it
+func (e executor) begin(ctx context.Context) (db.Tx, executor, error) {
+	tx, err := db.Begin(ctx, e.exec)
+	if err != nil {
+		return nil, executor{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, executor{tx}, nil
+}
+
+// bindArgs converts time arguments to their stored text
+func bindArgs(args []interface{}) []interface{} {
+	bound := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case time.Time:
+			bound[i] = formatTime(arg)
+		case *time.Time:
+			if arg != nil {
+				bound[i] = formatTime(*arg)
+			}
+		default:
+			bound[i] = arg
+		}
+	}
+	return bound
+}
@@ -0,0 +1,171 @@
+// Package sqlite implements the repositories on an embedded SQLite database,
+// for single-bench deployments without a database server. It runs on the
+// schema of the migrations in migrations/sqlite, which translate the
+// PostgreSQL schema: enums become checked text, JSONB documents and arrays
+// become JSON text, and timestamps become UTC text that sorts in time
+// order. Filtering, sorting, pagination and aggregation follow the
+// PostgreSQL repositories; the conformance suite in package repositorytest
+// checks both against the same expectations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// repositoryManager implements RepositoryManager on a SQLite database
+type repositoryManager struct {
+	db     *db.ConnectionManager
+	logger *logger.Logger
+
+	// tx is the transaction the repositories run in, nil outside
+	// WithTransaction
+	tx *sql.Tx
+
+	deviceRepo        repository.DeviceRepository
+	measurementRepo   repository.MeasurementRepository
+	commandRepo       repository.CommandRepository
+	alertRepo         repository.AlertRepository
+	silenceRepo       repository.SilenceRepository
+	escalationRepo    repository.EscalationRepository
+	deviceEventRepo   repository.DeviceEventRepository
+	deviceSessionRepo repository.DeviceSessionRepository
+	roleRepo          repository.RoleRepository
+	provisioningRepo  repository.ProvisioningRepository
+	apiKeyRepo        repository.APIKeyRepository
+	auditRepo         repository.AuditRepository
+}
+
+// NewRepositoryManager creates a repository manager on a connection manager
+// opened with the SQLite driver
+func NewRepositoryManager(cm *db.ConnectionManager, logger *logger.Logger) repository.RepositoryManager {
+	return newRepositoryManager(cm, cm, logger)
+}
+
+// newRepositoryManager creates a repository manager whose repositories run
+// their queries on exec
+func newRepositoryManager(cm *db.ConnectionManager, exec db.Executor, logger *logger.Logger) *repositoryManager {
+	e := executor{exec}
+	return &repositoryManager{
+		db:                cm,
+		logger:            logger,
+		deviceRepo:        &deviceRepository{db: e, logger: logger},
+		measurementRepo:   &measurementRepository{db: e, logger: logger},
+		commandRepo:       &commandRepository{db: e, logger: logger},
+		alertRepo:         &alertRepository{db: e, logger: logger},
+		silenceRepo:       &silenceRepository{db: e, logger: logger},
+		escalationRepo:    &escalationRepository{db: e, logger: logger},
+		deviceEventRepo:   &deviceEventRepository{db: e, logger: logger},
+		deviceSessionRepo: &deviceSessionRepository{db: e, logger: logger},
+		roleRepo:          &roleRepository{db: e, logger: logger},
+		provisioningRepo:  &provisioningRepository{db: e, logger: logger},
+		apiKeyRepo:        &apiKeyRepository{db: e, logger: logger},
+		auditRepo:         &auditRepository{db: e, logger: logger},
+	}
+}
+
+// Device returns the device repository
+func (rm *repositoryManager) Device() repository.DeviceRepository {
+	return rm.deviceRepo
+}
+
+// Measurement returns the measurement repository
+func (rm *repositoryManager) Measurement() repository.MeasurementRepository {
+	return rm.measurementRepo
+}
+
+// Command returns the command repository
+func (rm *repositoryManager) Command() repository.CommandRepository {
+	return rm.commandRepo
+}
+
+// Alert returns the alert repository
+func (rm *repositoryManager) Alert() repository.AlertRepository {
+	return rm.alertRepo
+}
+
+// Silence returns the silence repository
+func (rm *repositoryManager) Silence() repository.SilenceRepository {
+	return rm.silenceRepo
+}
+
+// Escalation returns the escalation repository
+func (rm *repositoryManager) Escalation() repository.EscalationRepository {
+	return rm.escalationRepo
+}
+
+// DeviceEvent returns the device event repository
+func (rm *repositoryManager) DeviceEvent() repository.DeviceEventRepository {
+	return rm.deviceEventRepo
+}
+
+// DeviceSession returns the device session repository
+func (rm *repositoryManager) DeviceSession() repository.DeviceSessionRepository {
+	return rm.deviceSessionRepo
+}
+
+// Role returns the role repository
+func (rm *repositoryManager) Role() repository.RoleRepository {
+	return rm.roleRepo
+}
+
+// Provisioning returns the provisioning repository
+func (rm *repositoryManager) Provisioning() repository.ProvisioningRepository {
+	return rm.provisioningRepo
+}
+
+// APIKey returns the API key repository
+func (rm *repositoryManager) APIKey() repository.APIKeyRepository {
+	return rm.apiKeyRepo
+}
+
+// Audit returns the audit repository
+func (rm *repositoryManager) Audit() repository.AuditRepository {
+	return rm.auditRepo
+}
+
+// WithTransaction runs fn in a transaction, which is committed if fn
+// succeeds and rolled back otherwise. Transactions take the database's
+// write lock when they begin, so they run one at a time while reads outside
+// them proceed. Nested calls join the enclosing transaction.
+func (rm *repositoryManager) WithTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.RepositoryManager) error) error {
+	if rm.tx != nil {
+		return fn(ctx, rm)
+	}
+
+	err := rm.db.RunInTx(ctx, rm.db.TxDefaults(), func(ctx context.Context, tx *sql.Tx) error {
+		repos := newRepositoryManager(rm.db, tx, rm.logger)
+		repos.tx = tx
+		return fn(ctx, repos)
+	})
+	if err != nil {
+		rm.logger.WithError(err).Debug("Transaction rolled back")
+		return err
+	}
+
+	rm.logger.Debug("Transaction committed successfully")
+	return nil
+}
+
+// HealthCheck checks that the database answers queries
+func (rm *repositoryManager) HealthCheck(ctx context.Context) error {
+	if err := rm.db.HealthCheck(ctx); err != nil {
+		rm.logger.WithError(err).Error("Database health check failed")
+		return fmt.Errorf("database health check failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (rm *repositoryManager) Close() error {
+	if err := rm.db.Close(); err != nil {
+		rm.logger.WithError(err).Error("Failed to close database connection")
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+	return nil
+}
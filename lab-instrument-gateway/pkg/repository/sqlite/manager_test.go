@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/config"
+	"github.com/yourorg/lab-gateway/pkg/db"
+	"github.com/yourorg/lab-gateway/pkg/fieldcrypt"
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+	"github.com/yourorg/lab-gateway/pkg/repository/repositorytest"
+)
+
+// newTestDatabase opens a migrated database in a fresh file
+func newTestDatabase(t *testing.T, log *logger.Logger) *db.ConnectionManager {
+	cm, err := db.NewConnectionManager(&config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "gateway.db"),
+	}, log)
+	if err != nil {
+		t.Fatalf("Failed to create connection manager: %v", err)
+	}
+	t.Cleanup(func() { cm.Close() })
+
+	ctx := context.Background()
+	migrator := db.NewSQLiteMigrationRunner(cm.GetDB(), "../../../migrations/sqlite", log)
+	if err := migrator.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize migrations: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Migration up failed: %v", err)
+	}
+	return cm
+}
+
+// TestConformance runs the conformance checks against a fresh database file
+// per manager
+func TestConformance(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	repositorytest.Run(t, func(t *testing.T) repository.RepositoryManager {
+		return NewRepositoryManager(newTestDatabase(t, log), log)
+	})
+}
+
+func TestAuditRepository_SnapshotsNotFieldEncrypted(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewDefaultLogger()
+	cm := newTestDatabase(t, log)
+	repos := NewRepositoryManager(cm, log)
+
+	key, err := fieldcrypt.GenerateKey()
+	require.NoError(t, err)
+	provider, err := fieldcrypt.NewLocalKeyProvider("k1", map[string][]byte{"k1": key})
+	require.NoError(t, err)
+	cipher, err := fieldcrypt.NewCipher(fieldcrypt.Config{Fields: []string{"operator"}}, provider)
+	require.NoError(t, err)
+	repository.SetFieldCipher(cipher)
+	defer repository.SetFieldCipher(nil)
+
+	entry := &models.AuditEntry{Actor: "admin", Action: "devices.update", ResourceType: "device", ResourceID: "hplc-1", Outcome: models.AuditOutcomeSuccess,
+		Before: map[string]interface{}{"operator": "Dana Scully"}}
+	require.NoError(t, repos.Audit().Append(ctx, entry))
+
+	var stored string
+	require.NoError(t, cm.GetDB().QueryRowContext(ctx, `SELECT before FROM audit_log WHERE id = ?`, entry.ID).Scan(&stored))
+	assert.Contains(t, stored, "Dana Scully")
+
+	// Entries stay readable once the cipher's keys are gone
+	repository.SetFieldCipher(nil)
+	entries, err := repos.Audit().List(ctx, repository.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Dana Scully", entries[0].Before["operator"])
+}
@@ -0,0 +1,576 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// measurementColumns are the columns selected for a measurement, in
+// scanMeasurement order
+const measurementColumns = `id, device_id, timestamp, type, value, unit, quality, metadata, batch_id, sequence_number, created_at`
+
+// measurementSortColumns are the columns measurements can be sorted by
+var measurementSortColumns = map[string]string{
+	"id":              "id",
+	"device_id":       "device_id",
+	"timestamp":       "timestamp",
+	"type":            "type",
+	"value":           "value",
+	"unit":            "unit",
+	"quality":         qualityOrder,
+	"batch_id":        "batch_id",
+	"sequence_number": "sequence_number",
+	"created_at":      "created_at",
+}
+
+// qualityOrder sorts quality codes as the quality_code enum
+var qualityOrder = enumOrder("quality",
+	models.QualityUnknown,
+	models.QualityGood,
+	models.QualityBad,
+	models.QualityUncertain,
+	models.QualitySubstituted,
+)
+
+// hourBucket truncates a timestamp to the hour, like date_trunc('hour')
+const hourBucket = `strftime('%Y-%m-%dT%H:00:00.000000Z', timestamp)`
+
+// measurementRepository implements MeasurementRepository on SQLite
+type measurementRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create creates a new measurement
+func (r *measurementRepository) Create(ctx context.Context, measurement *models.Measurement) error {
+	ctx, span := startSpan(ctx, "measurement", "Create")
+	defer span.End()
+
+	if err := measurement.Validate(); err != nil {
+		return fmt.Errorf("measurement validation failed: %w", err)
+	}
+
+	measurement.SetDefaults()
+
+	if err := r.insert(ctx, r.db, measurement); err != nil {
+		r.logger.WithField("device_id", measurement.DeviceID).WithError(err).Error("Failed to create measurement")
+		return fmt.Errorf("failed to create measurement: %w", err)
+	}
+
+	return nil
+}
+
+// insert inserts a measurement row
+func (r *measurementRepository) insert(ctx context.Context, exec executor, measurement *models.Measurement) error {
+	metadataJSON, err := encodeJSON(measurement.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO measurements (id, device_id, timestamp, type, value, unit, quality, metadata, batch_id, sequence_number, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		measurement.ID,
+		measurement.DeviceID,
+		measurement.Timestamp,
+		measurement.Type,
+		measurement.Value,
+		measurement.Unit,
+		measurement.Quality,
+		metadataJSON,
+		measurement.BatchID,
+		measurement.SequenceNumber,
+		measurement.CreatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a measurement by ID
+func (r *measurementRepository) GetByID(ctx context.Context, id string) (*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetByID")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+measurementColumns+` FROM measurements WHERE id = $1`, id)
+	measurement, err := scanMeasurement(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: measurement %s", repository.ErrNotFound, id)
+		}
+		r.logger.WithError(err).Error("Failed to get measurement")
+		return nil, fmt.Errorf("failed to get measurement: %w", err)
+	}
+
+	return measurement, nil
+}
+
+// Delete deletes a measurement by ID
+func (r *measurementRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "measurement", "Delete")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM measurements WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete measurement")
+		return fmt.Errorf("failed to delete measurement: %w", err)
+	}
+
+	return checkAffected(result, "measurement", id)
+}
+
+// CreateBulk creates multiple measurements in a single transaction. Invalid
+// measurements are skipped and reported; a failed insert fails the whole
+// batch, as it aborts the transaction of the PostgreSQL implementation.
+func (r *measurementRepository) CreateBulk(ctx context.Context, measurements []*models.Measurement) (*repository.BulkResult, error) {
+	ctx, span := startSpan(ctx, "measurement", "CreateBulk")
+	defer span.End()
+
+	result := &repository.BulkResult{}
+	if len(measurements) == 0 {
+		return result, nil
+	}
+
+	tx, exec, err := r.db.begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	for _, measurement := range measurements {
+		if err := measurement.Validate(); err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, fmt.Errorf("measurement validation failed: %w", err))
+			continue
+		}
+
+		measurement.SetDefaults()
+
+		if err := r.insert(ctx, exec, measurement); err != nil {
+			return failBatch(result, fmt.Errorf("measurement insert failed: %w", err))
+		}
+		result.SuccessCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return failBatch(result, err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"success_count": result.SuccessCount,
+		"failure_count": result.FailureCount,
+	}).Debug("Bulk measurement creation completed")
+
+	return result, nil
+}
+
+// CreateBatch creates a batch of measurements with shared metadata
+func (r *measurementRepository) CreateBatch(ctx context.Context, batch *models.MeasurementBatch) error {
+	ctx, span := startSpan(ctx, "measurement", "CreateBatch")
+	defer span.End()
+
+	if len(batch.Measurements) == 0 {
+		return nil
+	}
+
+	// Set batch metadata on all measurements
+	measurements := make([]*models.Measurement, len(batch.Measurements))
+	for i := range batch.Measurements {
+		batch.Measurements[i].DeviceID = batch.DeviceID
+		batch.Measurements[i].BatchID = &batch.BatchID
+		if batch.Measurements[i].Timestamp.IsZero() {
+			batch.Measurements[i].Timestamp = batch.Timestamp
+		}
+		measurements[i] = &batch.Measurements[i]
+	}
+
+	bulkResult, err := r.CreateBulk(ctx, measurements)
+	if err != nil {
+		return fmt.Errorf("failed to create measurement batch: %w", err)
+	}
+
+	if bulkResult.FailureCount > 0 {
+		r.logger.WithFields(map[string]interface{}{
+			"batch_id":      batch.BatchID,
+			"failure_count": bulkResult.FailureCount,
+			"errors":        len(bulkResult.Errors),
+		}).Warn("Some measurements in batch failed")
+	}
+
+	return nil
+}
+
+// List retrieves measurements with filtering and pagination
+func (r *measurementRepository) List(ctx context.Context, filter repository.MeasurementFilter) ([]*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "List")
+	defer span.End()
+
+	q, err := measurementConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := orderBy(filter.Filter, measurementSortColumns, "timestamp")
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := r.query(ctx, `SELECT `+measurementColumns+` FROM measurements`+q.clause()+order+q.page(filter.Filter), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list measurements")
+		return nil, fmt.Errorf("failed to list measurements: %w", err)
+	}
+
+	return measurements, nil
+}
+
+// Count returns the total number of measurements matching the filter
+func (r *measurementRepository) Count(ctx context.Context, filter repository.MeasurementFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "measurement", "Count")
+	defer span.End()
+
+	q, err := measurementConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM measurements`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count measurements")
+		return 0, fmt.Errorf("failed to count measurements: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetByTimeRange retrieves the measurements of a device within an inclusive
+// time range, oldest first
+func (r *measurementRepository) GetByTimeRange(ctx context.Context, deviceID string, startTime, endTime time.Time) ([]*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetByTimeRange")
+	defer span.End()
+
+	measurements, err := r.query(ctx, `
+		SELECT `+measurementColumns+`
+		FROM measurements
+		WHERE device_id = $1 AND timestamp >= $2 AND timestamp <= $3
+		ORDER BY timestamp ASC
+	`, deviceID, startTime, endTime)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get measurements by time range")
+		return nil, fmt.Errorf("failed to get measurements by time range: %w", err)
+	}
+
+	return measurements, nil
+}
+
+// GetLatest retrieves the latest measurement for a device and type
+func (r *measurementRepository) GetLatest(ctx context.Context, deviceID string, measurementType string) (*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetLatest")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+measurementColumns+`
+		FROM measurements
+		WHERE device_id = $1 AND type = $2
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, deviceID, measurementType)
+	measurement, err := scanMeasurement(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s measurement for device %s", repository.ErrNotFound, measurementType, deviceID)
+		}
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get latest measurement")
+		return nil, fmt.Errorf("failed to get latest measurement: %w", err)
+	}
+
+	return measurement, nil
+}
+
+// GetLatestByDevice retrieves the latest measurement of each type for a
+// device, ordered by type. A window function takes the place of DISTINCT
+// ON, which SQLite lacks.
+func (r *measurementRepository) GetLatestByDevice(ctx context.Context, deviceID string, limit int) ([]*models.Measurement, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetLatestByDevice")
+	defer span.End()
+
+	measurements, err := r.query(ctx, `
+		SELECT `+measurementColumns+`
+		FROM (
+			SELECT `+measurementColumns+`,
+			       ROW_NUMBER() OVER (PARTITION BY type ORDER BY timestamp DESC) AS recency
+			FROM measurements
+			WHERE device_id = $1
+		)
+		WHERE recency = 1
+		ORDER BY type
+		LIMIT $2
+	`, deviceID, limit)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to get latest measurements by device")
+		return nil, fmt.Errorf("failed to get latest measurements by device: %w", err)
+	}
+
+	return measurements, nil
+}
+
+// Aggregate aggregates measurements into hourly buckets per device and
+// type, newest first
+func (r *measurementRepository) Aggregate(ctx context.Context, req repository.AggregationRequest) ([]*repository.AggregationResult, error) {
+	ctx, span := startSpan(ctx, "measurement", "Aggregate")
+	defer span.End()
+
+	var aggregateFunc string
+	switch req.AggregationType {
+	case "min":
+		aggregateFunc = "MIN(value)"
+	case "max":
+		aggregateFunc = "MAX(value)"
+	case "sum":
+		aggregateFunc = "SUM(value)"
+	case "count":
+		aggregateFunc = "COUNT(*)"
+	default:
+		aggregateFunc = "AVG(value)"
+	}
+
+	q, err := statisticsConditions(req.DeviceIDs, req.Types, req.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT device_id, type, `+hourBucket+` AS hour, `+aggregateFunc+`, COUNT(*)
+		FROM measurements`+q.clause()+`
+		GROUP BY device_id, type, hour
+		ORDER BY hour DESC
+	`, q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to aggregate measurements")
+		return nil, fmt.Errorf("failed to aggregate measurements: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*repository.AggregationResult
+	for rows.Next() {
+		result := &repository.AggregationResult{Metadata: map[string]interface{}{}}
+		var hour string
+
+		if err := rows.Scan(&result.DeviceID, &result.Type, &hour, &result.Value, &result.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregation result: %w", err)
+		}
+
+		if result.Timestamp, err = parseTime(hour); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregation result: %w", err)
+		}
+
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aggregation rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// GetStatistics retrieves statistical information for the measurements of
+// the filter's devices, types and time range
+func (r *measurementRepository) GetStatistics(ctx context.Context, filter repository.MeasurementFilter) (*models.MeasurementStats, error) {
+	ctx, span := startSpan(ctx, "measurement", "GetStatistics")
+	defer span.End()
+
+	q, err := statisticsConditions(filter.DeviceIDs, filter.Types, filter.TimeRangeFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	// The aggregates are NULL when no measurements match
+	stats := &models.MeasurementStats{}
+	var minValue, maxValue, avgValue sql.NullFloat64
+	var earliestTime, latestTime sql.NullString
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			MIN(value),
+			MAX(value),
+			AVG(value),
+			MIN(timestamp),
+			MAX(timestamp),
+			COUNT(CASE WHEN quality = 'good' THEN 1 END),
+			COUNT(CASE WHEN quality = 'bad' THEN 1 END)
+		FROM measurements`+q.clause(), q.args...).Scan(
+		&stats.Count,
+		&minValue,
+		&maxValue,
+		&avgValue,
+		&earliestTime,
+		&latestTime,
+		&stats.GoodQuality,
+		&stats.BadQuality,
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get measurement statistics")
+		return nil, fmt.Errorf("failed to get measurement statistics: %w", err)
+	}
+
+	stats.MinValue = minValue.Float64
+	stats.MaxValue = maxValue.Float64
+	stats.AvgValue = avgValue.Float64
+
+	if earliestTime.Valid {
+		if stats.EarliestTime, err = parseTime(earliestTime.String); err != nil {
+			return nil, fmt.Errorf("failed to get measurement statistics: %w", err)
+		}
+	}
+	if latestTime.Valid {
+		if stats.LatestTime, err = parseTime(latestTime.String); err != nil {
+			return nil, fmt.Errorf("failed to get measurement statistics: %w", err)
+		}
+	}
+
+	return stats, nil
+}
+
+// DeleteOlderThan removes measurements older than the specified threshold
+func (r *measurementRepository) DeleteOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "measurement", "DeleteOlderThan")
+	defer span.End()
+
+	return r.delete(ctx, `DELETE FROM measurements WHERE timestamp < $1`, threshold)
+}
+
+// DeleteByDevice removes all measurements for a specific device
+func (r *measurementRepository) DeleteByDevice(ctx context.Context, deviceID string) (int64, error) {
+	ctx, span := startSpan(ctx, "measurement", "DeleteByDevice")
+	defer span.End()
+
+	return r.delete(ctx, `DELETE FROM measurements WHERE device_id = $1`, deviceID)
+}
+
+// delete runs a delete statement and returns the number of deleted rows
+func (r *measurementRepository) delete(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete measurements")
+		return 0, fmt.Errorf("failed to delete measurements: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithField("deleted", rowsAffected).Info("Measurements deleted")
+	}
+
+	return rowsAffected, nil
+}
+
+// query runs a query selecting measurementColumns
+func (r *measurementRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Measurement, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var measurements []*models.Measurement
+	for rows.Next() {
+		measurement, err := scanMeasurement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan measurement: %w", err)
+		}
+		measurements = append(measurements, measurement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating measurement rows: %w", err)
+	}
+
+	return measurements, nil
+}
+
+// measurementConditions returns the conditions of a measurement filter
+func measurementConditions(filter repository.MeasurementFilter) (*query, error) {
+	q, err := statisticsConditions(filter.DeviceIDs, filter.Types, filter.TimeRangeFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filter.Qualities) > 0 {
+		if err := q.in("quality", filter.Qualities); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.BatchID != nil {
+		q.where("batch_id = " + q.arg(*filter.BatchID))
+	}
+
+	return q, nil
+}
+
+// statisticsConditions returns the conditions of aggregations and
+// statistics, which only filter by device, type and time range
+func statisticsConditions(deviceIDs, types []string, timeRange repository.TimeRangeFilter) (*query, error) {
+	q := &query{}
+
+	if len(deviceIDs) > 0 {
+		if err := q.in("device_id", deviceIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(types) > 0 {
+		if err := q.in("type", types); err != nil {
+			return nil, err
+		}
+	}
+
+	if timeRange.StartTime != nil {
+		q.where("timestamp >= " + q.arg(*timeRange.StartTime))
+	}
+
+	if timeRange.EndTime != nil {
+		q.where("timestamp <= " + q.arg(*timeRange.EndTime))
+	}
+
+	return q, nil
+}
+
+// scanMeasurement scans a row of measurementColumns
+func scanMeasurement(row rowScanner) (*models.Measurement, error) {
+	measurement := &models.Measurement{}
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&measurement.ID,
+		&measurement.DeviceID,
+		&measurement.Timestamp,
+		&measurement.Type,
+		&measurement.Value,
+		&measurement.Unit,
+		&measurement.Quality,
+		&metadataJSON,
+		&measurement.BatchID,
+		&measurement.SequenceNumber,
+		&measurement.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.DecodeJSON(metadataJSON, &measurement.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return measurement, nil
+}
@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// provisioningRepository implements ProvisioningRepository on SQLite
+type provisioningRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// CreateEnrollmentToken stores a new enrollment token
+func (r *provisioningRepository) CreateEnrollmentToken(ctx context.Context, token *models.EnrollmentToken) error {
+	ctx, span := startSpan(ctx, "provisioning", "CreateEnrollmentToken")
+	defer span.End()
+
+	token.SetDefaults()
+
+	if err := token.Validate(); err != nil {
+		return fmt.Errorf("enrollment token validation failed: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO enrollment_tokens (id, token_hash, device_type, device_group, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		token.ID,
+		token.TokenHash,
+		token.DeviceType,
+		token.DeviceGroup,
+		token.CreatedBy,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("token_id", token.ID).WithError(err).Error("Failed to create enrollment token")
+		return fmt.Errorf("failed to create enrollment token: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"token_id":    token.ID,
+		"device_type": token.DeviceType,
+		"created_by":  token.CreatedBy,
+		"expires_at":  token.ExpiresAt,
+	}).Info("Enrollment token created successfully")
+
+	return nil
+}
+
+// ConsumeEnrollmentToken marks an unused, unexpired token as used by a
+// device. The token is read back in the same transaction rather than with
+// RETURNING, whose columns the driver does not convert to times.
+func (r *provisioningRepository) ConsumeEnrollmentToken(ctx context.Context, tokenHash string, deviceID string, at time.Time) (*models.EnrollmentToken, error) {
+	ctx, span := startSpan(ctx, "provisioning", "ConsumeEnrollmentToken")
+	defer span.End()
+
+	tx, exec, err := r.db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := exec.ExecContext(ctx, `
+		UPDATE enrollment_tokens
+		SET used_at = $3, used_by_device = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $3
+	`, tokenHash, deviceID, at)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to consume enrollment token")
+		return nil, fmt.Errorf("failed to consume enrollment token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: usable enrollment token", repository.ErrNotFound)
+	}
+
+	token := &models.EnrollmentToken{}
+	err = exec.QueryRowContext(ctx, `
+		SELECT id, token_hash, device_type, device_group, created_by, expires_at, used_at, used_by_device, created_at
+		FROM enrollment_tokens
+		WHERE token_hash = $1
+	`, tokenHash).Scan(
+		&token.ID,
+		&token.TokenHash,
+		&token.DeviceType,
+		&token.DeviceGroup,
+		&token.CreatedBy,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.UsedByDevice,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to consume enrollment token")
+		return nil, fmt.Errorf("failed to consume enrollment token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit enrollment token: %w", err)
+	}
+
+	return token, nil
+}
+
+// DeleteExpiredEnrollmentTokens removes tokens that expired before the threshold
+func (r *provisioningRepository) DeleteExpiredEnrollmentTokens(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "provisioning", "DeleteExpiredEnrollmentTokens")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM enrollment_tokens WHERE expires_at < $1`, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete expired enrollment tokens")
+		return 0, fmt.Errorf("failed to delete expired enrollment tokens: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// RecordCertificate stores a certificate issued to a device
+func (r *provisioningRepository) RecordCertificate(ctx context.Context, certificate *models.DeviceCertificate) error {
+	ctx, span := startSpan(ctx, "provisioning", "RecordCertificate")
+	defer span.End()
+
+	if certificate.IssuedAt.IsZero() {
+		certificate.IssuedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO device_certificates (serial_number, device_id, subject, not_before, not_after, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		certificate.SerialNumber,
+		certificate.DeviceID,
+		certificate.Subject,
+		certificate.NotBefore,
+		certificate.NotAfter,
+		certificate.IssuedAt,
+	)
+	if err != nil {
+		r.logger.WithField("device_id", certificate.DeviceID).WithError(err).Error("Failed to record device certificate")
+		return fmt.Errorf("failed to record device certificate: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"device_id":     certificate.DeviceID,
+		"serial_number": certificate.SerialNumber,
+		"not_after":     certificate.NotAfter,
+	}).Info("Device certificate recorded")
+
+	return nil
+}
+
+// ListCertificates retrieves the certificates issued to a device, newest first
+func (r *provisioningRepository) ListCertificates(ctx context.Context, deviceID string) ([]*models.DeviceCertificate, error) {
+	ctx, span := startSpan(ctx, "provisioning", "ListCertificates")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT serial_number, device_id, subject, not_before, not_after, issued_at
+		FROM device_certificates
+		WHERE device_id = $1
+		ORDER BY issued_at DESC
+	`, deviceID)
+	if err != nil {
+		r.logger.WithField("device_id", deviceID).WithError(err).Error("Failed to list device certificates")
+		return nil, fmt.Errorf("failed to list device certificates: %w", err)
+	}
+	defer rows.Close()
+
+	var certificates []*models.DeviceCertificate
+	for rows.Next() {
+		certificate := &models.DeviceCertificate{}
+		err := rows.Scan(
+			&certificate.SerialNumber,
+			&certificate.DeviceID,
+			&certificate.Subject,
+			&certificate.NotBefore,
+			&certificate.NotAfter,
+			&certificate.IssuedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device certificate rows: %w", err)
+	}
+
+	return certificates, nil
+}
@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// query collects the conditions of a statement and their arguments,
+// numbering the placeholders in the order the arguments are added
+type query struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg adds an argument and returns its placeholder
+func (q *query) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// where adds a condition
+func (q *query) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// in adds the condition that a column equals one of the values, passed as a
+// JSON array in place of the array parameters of PostgreSQL
+func (q *query) in(column string, values interface{}) error {
+	list, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s values: %w", column, err)
+	}
+	q.where(fmt.Sprintf("%s IN (SELECT value FROM json_each(%s))", column, q.arg(string(list))))
+	return nil
+}
+
+// clause returns the WHERE clause of the conditions
+func (q *query) clause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// orderBy returns the ORDER BY clause of a filter, sorting by the filter's
+// column or else by defaultColumn, descending unless the filter asks for
+// ascending order. columns maps the names that may be sorted by to the
+// expressions sorted on. NULLs sort as in PostgreSQL: last in ascending and
+// first in descending order.
+func orderBy(filter repository.Filter, columns map[string]string, defaultColumn string) (string, error) {
+	name := defaultColumn
+	if filter.SortBy != "" {
+		name = filter.SortBy
+	}
+
+	expression, ok := columns[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unsupported sort column: %s", name)
+	}
+
+	switch strings.ToUpper(filter.Order) {
+	case "", "DESC":
+		return fmt.Sprintf(" ORDER BY %s DESC NULLS FIRST", expression), nil
+	case "ASC":
+		return fmt.Sprintf(" ORDER BY %s ASC NULLS LAST", expression), nil
+	default:
+		return "", fmt.Errorf("unsupported sort order: %s", filter.Order)
+	}
+}
+
+// page returns the LIMIT and OFFSET clauses of a filter
+func (q *query) page(filter repository.Filter) string {
+	var clause string
+	if filter.Limit > 0 {
+		clause += " LIMIT " + q.arg(filter.Limit)
+	} else if filter.Offset > 0 {
+		// SQLite only takes an offset after a limit
+		clause += " LIMIT -1"
+	}
+
+	if filter.Offset > 0 {
+		clause += " OFFSET " + q.arg(filter.Offset)
+	}
+	return clause
+}
+
+// enumOrder returns an expression sorting a column in the declaration order
+// of its PostgreSQL enum, which is how PostgreSQL sorts enums
+func enumOrder[T ~string](column string, values ...T) string {
+	var expression strings.Builder
+	fmt.Fprintf(&expression, "CASE %s", column)
+	for i, value := range values {
+		fmt.Fprintf(&expression, " WHEN '%s' THEN %d", value, i)
+	}
+	expression.WriteString(" END")
+	return expression.String()
+}
+
+// jsonText returns an expression rendering the value of a JSON column at a
+// path as the ->> operator of PostgreSQL does: strings without quotes,
+// other values as JSON text and null or a missing value as NULL. The path
+// is a placeholder.
+func jsonText(column, path string) string {
+	return fmt.Sprintf("CASE json_type(%[1]s, %[2]s) WHEN 'text' THEN json_extract(%[1]s, %[2]s) WHEN 'null' THEN NULL ELSE %[1]s -> %[2]s END", column, path)
+}
+
+// jsonPath returns the JSON path of a top-level key
+func jsonPath(key string) string {
+	return `$."` + key + `"`
+}
+
+// encodeJSON marshals a JSON column value, encrypting designated fields as
+// the PostgreSQL repositories do. It is bound as text, since SQLite reads
+// blobs as its binary JSON format.
+func encodeJSON(v interface{}) (string, error) {
+	data, err := repository.EncodeJSON(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// encodeArray marshals an array column value, storing a nil slice as an
+// empty array
+func encodeArray[T any](values []T) (string, error) {
+	if values == nil {
+		values = []T{}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal array: %w", err)
+	}
+	return string(data), nil
+}
+
+// stringArray scans an array column, reading an empty array as nil like
+// the array type of the PostgreSQL driver does
+type stringArray []string
+
+// Scan implements sql.Scanner
+func (a *stringArray) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return fmt.Errorf("cannot scan %T into an array", src)
+	}
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("invalid array %q: %w", data, err)
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+	*a = values
+	return nil
+}
+
+// Value implements driver.Valuer
+func (a stringArray) Value() (driver.Value, error) {
+	return encodeArray([]string(a))
+}
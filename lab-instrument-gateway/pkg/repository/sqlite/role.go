@@ -0,0 +1,274 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// roleRepository implements RoleRepository on SQLite
+type roleRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// CreateRole creates a new role
+func (r *roleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	ctx, span := startSpan(ctx, "role", "CreateRole")
+	defer span.End()
+
+	role.SetDefaults()
+
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("role validation failed: %w", err)
+	}
+
+	permissions, err := encodeArray(role.Permissions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO roles (name, description, permissions, built_in, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		role.Name,
+		role.Description,
+		permissions,
+		role.BuiltIn,
+		role.CreatedAt,
+		role.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("role", role.Name).WithError(err).Error("Failed to create role")
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"role":        role.Name,
+		"permissions": len(role.Permissions),
+	}).Info("Role created successfully")
+
+	return nil
+}
+
+// GetRole retrieves a role by name
+func (r *roleRepository) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	ctx, span := startSpan(ctx, "role", "GetRole")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `
+		SELECT name, description, permissions, built_in, created_at, updated_at
+		FROM roles
+		WHERE name = $1
+	`, name)
+	role, err := scanRole(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: role %s", repository.ErrNotFound, name)
+		}
+		r.logger.WithField("role", name).WithError(err).Error("Failed to get role")
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return role, nil
+}
+
+// UpdateRole updates the description and permissions of a role
+func (r *roleRepository) UpdateRole(ctx context.Context, role *models.Role) error {
+	ctx, span := startSpan(ctx, "role", "UpdateRole")
+	defer span.End()
+
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("role validation failed: %w", err)
+	}
+
+	permissions, err := encodeArray(role.Permissions)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `UPDATE roles SET description = $2, permissions = $3 WHERE name = $1`,
+		role.Name,
+		role.Description,
+		permissions,
+	)
+	if err != nil {
+		r.logger.WithField("role", role.Name).WithError(err).Error("Failed to update role")
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return checkAffected(result, "role", role.Name)
+}
+
+// DeleteRole removes a role and its bindings. Built-in roles cannot be deleted.
+func (r *roleRepository) DeleteRole(ctx context.Context, name string) error {
+	ctx, span := startSpan(ctx, "role", "DeleteRole")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE name = $1 AND NOT built_in`, name)
+	if err != nil {
+		r.logger.WithField("role", name).WithError(err).Error("Failed to delete role")
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return checkAffected(result, "role", name)
+}
+
+// ListRoles retrieves all roles
+func (r *roleRepository) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	ctx, span := startSpan(ctx, "role", "ListRoles")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT name, description, permissions, built_in, created_at, updated_at
+		FROM roles
+		ORDER BY name
+	`)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list roles")
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role rows: %w", err)
+	}
+
+	return roles, nil
+}
+
+// CreateBinding grants a role to a subject
+func (r *roleRepository) CreateBinding(ctx context.Context, binding *models.RoleBinding) error {
+	ctx, span := startSpan(ctx, "role", "CreateBinding")
+	defer span.End()
+
+	binding.SetDefaults()
+
+	if err := binding.Validate(); err != nil {
+		return fmt.Errorf("role binding validation failed: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO role_bindings (id, subject, role, device_group, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		binding.ID,
+		binding.Subject,
+		binding.Role,
+		binding.DeviceGroup,
+		binding.CreatedBy,
+		binding.CreatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("binding_id", binding.ID).WithError(err).Error("Failed to create role binding")
+		return fmt.Errorf("failed to create role binding: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"binding_id":   binding.ID,
+		"subject":      binding.Subject,
+		"role":         binding.Role,
+		"device_group": binding.DeviceGroup,
+		"created_by":   binding.CreatedBy,
+	}).Info("Role binding created successfully")
+
+	return nil
+}
+
+// DeleteBinding removes a role binding
+func (r *roleRepository) DeleteBinding(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "role", "DeleteBinding")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM role_bindings WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("binding_id", id).WithError(err).Error("Failed to delete role binding")
+		return fmt.Errorf("failed to delete role binding: %w", err)
+	}
+
+	return checkAffected(result, "role binding", id)
+}
+
+// ListBindings retrieves the role bindings of a subject, or of every subject
+// if subject is empty
+func (r *roleRepository) ListBindings(ctx context.Context, subject string) ([]*models.RoleBinding, error) {
+	ctx, span := startSpan(ctx, "role", "ListBindings")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, subject, role, device_group, created_by, created_at
+		FROM role_bindings
+		WHERE $1 = '' OR subject = $1
+		ORDER BY subject, role
+	`, subject)
+	if err != nil {
+		r.logger.WithField("subject", subject).WithError(err).Error("Failed to list role bindings")
+		return nil, fmt.Errorf("failed to list role bindings: %w", err)
+	}
+	defer rows.Close()
+
+	var bindings []*models.RoleBinding
+	for rows.Next() {
+		binding := &models.RoleBinding{}
+		err := rows.Scan(
+			&binding.ID,
+			&binding.Subject,
+			&binding.Role,
+			&binding.DeviceGroup,
+			&binding.CreatedBy,
+			&binding.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role binding: %w", err)
+		}
+		bindings = append(bindings, binding)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role binding rows: %w", err)
+	}
+
+	return bindings, nil
+}
+
+// scanRole scans a row into a role
+func scanRole(row rowScanner) (*models.Role, error) {
+	role := &models.Role{}
+	var description sql.NullString
+	var permissions stringArray
+
+	err := row.Scan(
+		&role.Name,
+		&description,
+		&permissions,
+		&role.BuiltIn,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = description.String
+	role.Permissions = make([]models.Permission, len(permissions))
+	for i, permission := range permissions {
+		role.Permissions[i] = models.Permission(permission)
+	}
+
+	return role, nil
+}
@@ -0,0 +1,325 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lab-gateway/pkg/logger"
+	"github.com/yourorg/lab-gateway/pkg/models"
+	"github.com/yourorg/lab-gateway/pkg/repository"
+)
+
+// silenceColumns are the columns selected for a silence, in scanSilence
+// order
+const silenceColumns = `id, device_id, device_type, alert_type, starts_at, ends_at, created_by, comment, created_at`
+
+// silenceSortColumns are the columns silences can be sorted by
+var silenceSortColumns = map[string]string{
+	"id":          "id",
+	"device_id":   "device_id",
+	"device_type": "device_type",
+	"starts_at":   "starts_at",
+	"ends_at":     "ends_at",
+	"created_by":  "created_by",
+	"created_at":  "created_at",
+}
+
+// silenceRepository implements SilenceRepository on SQLite
+type silenceRepository struct {
+	db     executor
+	logger *logger.Logger
+}
+
+// Create creates a new silence
+func (r *silenceRepository) Create(ctx context.Context, silence *models.Silence) error {
+	ctx, span := startSpan(ctx, "silence", "Create")
+	defer span.End()
+
+	silence.SetDefaults()
+
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("silence validation failed: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alert_silences (id, device_id, device_type, alert_type, starts_at, ends_at, created_by, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		silence.ID,
+		silence.DeviceID,
+		silence.DeviceType,
+		silence.AlertType,
+		silence.StartsAt,
+		silence.EndsAt,
+		silence.CreatedBy,
+		silence.Comment,
+		silence.CreatedAt,
+	)
+	if err != nil {
+		r.logger.WithField("silence_id", silence.ID).WithError(err).Error("Failed to create silence")
+		return fmt.Errorf("failed to create silence: %w", err)
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"silence_id": silence.ID,
+		"created_by": silence.CreatedBy,
+		"starts_at":  silence.StartsAt,
+		"ends_at":    silence.EndsAt,
+	}).Info("Silence created successfully")
+
+	return nil
+}
+
+// GetByID retrieves a silence by ID
+func (r *silenceRepository) GetByID(ctx context.Context, id string) (*models.Silence, error) {
+	ctx, span := startSpan(ctx, "silence", "GetByID")
+	defer span.End()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+silenceColumns+` FROM alert_silences WHERE id = $1`, id)
+	silence, err := scanSilence(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: silence %s", repository.ErrNotFound, id)
+		}
+		r.logger.WithError(err).Error("Failed to get silence")
+		return nil, fmt.Errorf("failed to get silence: %w", err)
+	}
+
+	return silence, nil
+}
+
+// Update updates an existing silence
+func (r *silenceRepository) Update(ctx context.Context, silence *models.Silence) error {
+	ctx, span := startSpan(ctx, "silence", "Update")
+	defer span.End()
+
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("silence validation failed: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE alert_silences
+		SET device_id = $2, device_type = $3, alert_type = $4, starts_at = $5, ends_at = $6, comment = $7
+		WHERE id = $1
+	`,
+		silence.ID,
+		silence.DeviceID,
+		silence.DeviceType,
+		silence.AlertType,
+		silence.StartsAt,
+		silence.EndsAt,
+		silence.Comment,
+	)
+	if err != nil {
+		r.logger.WithField("silence_id", silence.ID).WithError(err).Error("Failed to update silence")
+		return fmt.Errorf("failed to update silence: %w", err)
+	}
+
+	if err := checkAffected(result, "silence", silence.ID); err != nil {
+		return err
+	}
+
+	r.logger.WithField("silence_id", silence.ID).Info("Silence updated successfully")
+	return nil
+}
+
+// Delete removes a silence
+func (r *silenceRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "silence", "Delete")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_silences WHERE id = $1`, id)
+	if err != nil {
+		r.logger.WithField("silence_id", id).WithError(err).Error("Failed to delete silence")
+		return fmt.Errorf("failed to delete silence: %w", err)
+	}
+
+	if err := checkAffected(result, "silence", id); err != nil {
+		return err
+	}
+
+	r.logger.WithField("silence_id", id).Info("Silence deleted successfully")
+	return nil
+}
+
+// List retrieves silences with filtering and pagination
+func (r *silenceRepository) List(ctx context.Context, filter repository.SilenceFilter) ([]*models.Silence, error) {
+	ctx, span := startSpan(ctx, "silence", "List")
+	defer span.End()
+
+	q, err := silenceConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := orderBy(filter.Filter, silenceSortColumns, "starts_at")
+	if err != nil {
+		return nil, err
+	}
+
+	silences, err := r.query(ctx, `SELECT `+silenceColumns+` FROM alert_silences`+q.clause()+order+q.page(filter.Filter), q.args...)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list silences")
+		return nil, fmt.Errorf("failed to list silences: %w", err)
+	}
+
+	return silences, nil
+}
+
+// Count returns the total number of silences matching the filter
+func (r *silenceRepository) Count(ctx context.Context, filter repository.SilenceFilter) (int64, error) {
+	ctx, span := startSpan(ctx, "silence", "Count")
+	defer span.End()
+
+	q, err := silenceConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM alert_silences`+q.clause(), q.args...).Scan(&count); err != nil {
+		r.logger.WithError(err).Error("Failed to count silences")
+		return 0, fmt.Errorf("failed to count silences: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetActive retrieves the silences active at the given time
+func (r *silenceRepository) GetActive(ctx context.Context, at time.Time) ([]*models.Silence, error) {
+	ctx, span := startSpan(ctx, "silence", "GetActive")
+	defer span.End()
+
+	silences, err := r.query(ctx, `
+		SELECT `+silenceColumns+`
+		FROM alert_silences
+		WHERE starts_at <= $1 AND ends_at > $1
+		ORDER BY starts_at
+	`, at)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get active silences")
+		return nil, fmt.Errorf("failed to get active silences: %w", err)
+	}
+
+	return silences, nil
+}
+
+// Expire ends a silence immediately
+func (r *silenceRepository) Expire(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "silence", "Expire")
+	defer span.End()
+
+	silence, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	silence.Expire()
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE alert_silences SET starts_at = $2, ends_at = $3 WHERE id = $1`, silence.ID, silence.StartsAt, silence.EndsAt); err != nil {
+		r.logger.WithField("silence_id", id).WithError(err).Error("Failed to expire silence")
+		return fmt.Errorf("failed to expire silence: %w", err)
+	}
+
+	r.logger.WithField("silence_id", id).Info("Silence expired")
+	return nil
+}
+
+// DeleteExpiredOlderThan removes silences that ended before the threshold
+func (r *silenceRepository) DeleteExpiredOlderThan(ctx context.Context, threshold time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "silence", "DeleteExpiredOlderThan")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_silences WHERE ends_at < $1`, threshold)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete expired silences")
+		return 0, fmt.Errorf("failed to delete expired silences: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.WithFields(map[string]interface{}{
+			"deleted":   rowsAffected,
+			"threshold": threshold,
+		}).Info("Expired silences deleted")
+	}
+
+	return rowsAffected, nil
+}
+
+// query runs a query selecting silenceColumns
+func (r *silenceRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Silence, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var silences []*models.Silence
+	for rows.Next() {
+		silence, err := scanSilence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan silence: %w", err)
+		}
+		silences = append(silences, silence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating silence rows: %w", err)
+	}
+
+	return silences, nil
+}
+
+// silenceConditions returns the conditions of a silence filter
+func silenceConditions(filter repository.SilenceFilter) (*query, error) {
+	q := &query{}
+
+	if len(filter.DeviceIDs) > 0 {
+		if err := q.in("device_id", filter.DeviceIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.CreatedBy != nil {
+		q.where("created_by = " + q.arg(*filter.CreatedBy))
+	}
+
+	if filter.ActiveAt != nil {
+		at := q.arg(*filter.ActiveAt)
+		q.where("starts_at <= " + at + " AND ends_at > " + at)
+	}
+
+	return q, nil
+}
+
+// scanSilence scans a row of silenceColumns
+func scanSilence(row rowScanner) (*models.Silence, error) {
+	silence := &models.Silence{}
+	var comment sql.NullString
+
+	err := row.Scan(
+		&silence.ID,
+		&silence.DeviceID,
+		&silence.DeviceType,
+		&silence.AlertType,
+		&silence.StartsAt,
+		&silence.EndsAt,
+		&silence.CreatedBy,
+		&comment,
+		&silence.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	silence.Comment = comment.String
+	return silence, nil
+}
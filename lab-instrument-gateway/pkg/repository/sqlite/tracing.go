@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yourorg/lab-gateway/pkg/tracing"
+)
+
+// startSpan starts the span of a repository call, named like those of the
+// PostgreSQL repositories so that traces look the same with either database
+func startSpan(ctx context.Context, repository, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "repository."+repository+"."+operation,
+		attribute.String("db.system", "sqlite"),
+		attribute.String("repository", repository),
+		attribute.String("repository.operation", operation),
+	)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yourorg/lab-gateway/pkg/tracing"
+)
+
+// startSpan starts the span of a repository call, named
+// repository.{repository}.{operation}. Database errors are recorded on it by
+// the connection manager.
+func startSpan(ctx context.Context, repository, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "repository."+repository+"."+operation,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("repository", repository),
+		attribute.String("repository.operation", operation),
+	)
+}
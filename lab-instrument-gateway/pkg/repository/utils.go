@@ -2,20 +2,82 @@ package repository
 
 import (
 	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/yourorg/lab-gateway/pkg/fieldcrypt"
 )
 
-// marshalJSON marshals a value to JSON bytes, handling nil values
+// fieldCipher encrypts designated fields of the JSON columns written by the
+// repositories; nil leaves them in plaintext
+var fieldCipher atomic.Pointer[fieldcrypt.Cipher]
+
+// SetFieldCipher enables field-level encryption of JSON columns, such as
+// device and measurement metadata, with the cipher, or disables it if the
+// cipher is nil. Encrypted values stay readable only while a cipher with
+// their keys is set, so it should be set before any repository is used.
+func SetFieldCipher(cipher *fieldcrypt.Cipher) {
+	fieldCipher.Store(cipher)
+}
+
+// FilterableMetadata returns true if queries can match a metadata key: the
+// stored values of encrypted fields differ from their plaintext
+func FilterableMetadata(key string) bool {
+	cipher := fieldCipher.Load()
+	return cipher == nil || !cipher.Encrypts(key)
+}
+
+// marshalJSON marshals a value to JSON bytes, handling nil values and
+// encrypting designated fields
 func marshalJSON(v interface{}) ([]byte, error) {
 	if v == nil {
 		return []byte("{}"), nil
 	}
+
+	if cipher := fieldCipher.Load(); cipher != nil {
+		encrypted, err := cipher.Encrypt(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt fields: %w", err)
+		}
+		v = encrypted
+	}
+
 	return json.Marshal(v)
 }
 
 // unmarshalJSON unmarshals JSON bytes to a value, handling empty/nil bytes
+// and decrypting encrypted fields
 func unmarshalJSON(data []byte, v interface{}) error {
 	if len(data) == 0 {
 		return nil
 	}
+
+	if cipher := fieldCipher.Load(); cipher != nil && fieldcrypt.ContainsEncrypted(data) {
+		var document interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return err
+		}
+
+		decrypted, err := cipher.Decrypt(document)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt fields: %w", err)
+		}
+
+		if data, err = json.Marshal(decrypted); err != nil {
+			return err
+		}
+	}
+
 	return json.Unmarshal(data, v)
-}
\ No newline at end of file
+}
+
+// EncodeJSON marshals a JSON column value as the repositories do, encrypting
+// designated fields, for repository implementations outside this package
+func EncodeJSON(v interface{}) ([]byte, error) {
+	return marshalJSON(v)
+}
+
+// DecodeJSON unmarshals a JSON column value written by EncodeJSON
+func DecodeJSON(data []byte, v interface{}) error {
+	return unmarshalJSON(data, v)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourorg/lab-gateway/pkg/fieldcrypt"
+)
+
+func TestMarshalJSON_FieldEncryption(t *testing.T) {
+	key, err := fieldcrypt.GenerateKey()
+	require.NoError(t, err)
+	provider, err := fieldcrypt.NewLocalKeyProvider("k1", map[string][]byte{"k1": key})
+	require.NoError(t, err)
+	cipher, err := fieldcrypt.NewCipher(fieldcrypt.Config{Fields: []string{"operator"}}, provider)
+	require.NoError(t, err)
+
+	SetFieldCipher(cipher)
+	defer SetFieldCipher(nil)
+
+	metadata := map[string]interface{}{"operator": "Dana Scully", "location": "lab-2"}
+	data, err := marshalJSON(metadata)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Scully")
+	assert.Contains(t, string(data), "lab-2")
+	assert.False(t, FilterableMetadata("operator"), "encrypted values cannot be matched")
+	assert.True(t, FilterableMetadata("location"))
+
+	var decoded map[string]interface{}
+	require.NoError(t, unmarshalJSON(data, &decoded))
+	assert.Equal(t, metadata, decoded)
+
+	// Without the cipher the stored value stays encrypted
+	SetFieldCipher(nil)
+	decoded = nil
+	require.NoError(t, unmarshalJSON(data, &decoded))
+	assert.NotEqual(t, "Dana Scully", decoded["operator"])
+	assert.True(t, FilterableMetadata("operator"))
+}
@@ -0,0 +1,204 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the gateway's own spans
+const TracerName = "github.com/yourorg/lab-gateway"
+
+// Samplers, named as in the OTEL_TRACES_SAMPLER specification
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// Config configures the export of traces over OTLP
+type Config struct {
+	Enabled bool
+
+	// Endpoint is the host:port of the OTLP gRPC collector
+	Endpoint string
+	Insecure bool
+
+	ServiceName string
+
+	// Sampler selects the traces recorded, SampleRatio is the fraction of
+	// traces the ratio samplers record
+	Sampler     string
+	SampleRatio float64
+
+	// ExportTimeout bounds the export of one batch of spans
+	ExportTimeout time.Duration
+}
+
+// SetDefaults sets default values for the tracing configuration
+func (c *Config) SetDefaults() {
+	if c.Endpoint == "" {
+		c.Endpoint = "localhost:4317"
+	}
+	if c.ServiceName == "" {
+		c.ServiceName = "lab-gateway"
+	}
+	if c.Sampler == "" {
+		c.Sampler = SamplerParentBasedAlwaysOn
+	}
+	if c.ExportTimeout == 0 {
+		c.ExportTimeout = 10 * time.Second
+	}
+}
+
+// NewSampler creates the configured sampler. Parent based samplers follow
+// the sampling decision of the caller when the trace context is propagated.
+func NewSampler(config Config) (sdktrace.Sampler, error) {
+	config.SetDefaults()
+
+	ratio := func() (sdktrace.Sampler, error) {
+		if config.SampleRatio < 0 || config.SampleRatio > 1 {
+			return nil, fmt.Errorf("sample ratio %v is not between 0 and 1", config.SampleRatio)
+		}
+		return sdktrace.TraceIDRatioBased(config.SampleRatio), nil
+	}
+
+	switch config.Sampler {
+	case SamplerAlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case SamplerTraceIDRatio:
+		return ratio()
+	case SamplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case SamplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case SamplerParentBasedTraceIDRatio:
+		root, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		return sdktrace.ParentBased(root), nil
+	default:
+		return nil, fmt.Errorf("unknown trace sampler %q", config.Sampler)
+	}
+}
+
+// NewTracerProvider creates a tracer provider exporting the sampled spans
+// of the service in batches
+func NewTracerProvider(config Config, exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	config.SetDefaults()
+
+	sampler, err := NewSampler(config)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", config.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithBatcher(exporter, sdktrace.WithExportTimeout(config.ExportTimeout)),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// Setup installs W3C trace context propagation and, when enabled, the
+// global tracer provider exporting to the OTLP collector. The returned
+// function flushes the pending spans and stops the export.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	config.SetDefaults()
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !config.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// Validate the sampler before connecting
+	if _, err := NewSampler(config); err != nil {
+		return nil, err
+	}
+
+	options := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(config.Endpoint),
+		otlptracegrpc.WithTimeout(config.ExportTimeout),
+	}
+	if config.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	provider, err := NewTracerProvider(config, exporter)
+	if err != nil {
+		exporter.Shutdown(ctx)
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of the gateway as a child of the span in the context
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error, if any, on the span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RecordError records an error on the span in the context, if it is being
+// recorded
+func RecordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err == nil || !span.IsRecording() {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// LogFields returns the trace and span ids of the span in the context as
+// log fields, or no fields without a valid span
+func LogFields(ctx context.Context) map[string]interface{} {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	}
+}
@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "00f067aa0ba902b7"
+)
+
+// useProvider installs a tracer provider exporting to memory for the test
+func useProvider(t *testing.T, config Config) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := NewTracerProvider(config, exporter)
+	require.NoError(t, err)
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+
+	return provider, exporter
+}
+
+// remoteParent returns a context carrying the trace context of a caller,
+// extracted from its traceparent header
+func remoteParent(t *testing.T, sampled bool) context.Context {
+	_, err := Setup(context.Background(), Config{})
+	require.NoError(t, err)
+
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	carrier := propagation.MapCarrier{"traceparent": "00-" + remoteTraceID + "-" + remoteSpanID + "-" + flags}
+	return otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+}
+
+func TestNewSampler(t *testing.T) {
+	for _, name := range []string{"", SamplerAlwaysOn, SamplerAlwaysOff, SamplerTraceIDRatio,
+		SamplerParentBasedAlwaysOn, SamplerParentBasedAlwaysOff, SamplerParentBasedTraceIDRatio} {
+		sampler, err := NewSampler(Config{Sampler: name, SampleRatio: 0.5})
+		require.NoError(t, err, name)
+		assert.NotNil(t, sampler, name)
+	}
+
+	_, err := NewSampler(Config{Sampler: SamplerTraceIDRatio, SampleRatio: 1.5})
+	assert.Error(t, err)
+	_, err = NewSampler(Config{Sampler: SamplerParentBasedTraceIDRatio, SampleRatio: -1})
+	assert.Error(t, err)
+	_, err = NewSampler(Config{Sampler: "jaeger_remote"})
+	assert.Error(t, err)
+
+	_, err = Setup(context.Background(), Config{Enabled: true, Sampler: "jaeger_remote"})
+	assert.Error(t, err)
+}
+
+func TestTracerProvider_Sampling(t *testing.T) {
+	t.Run("ratio", func(t *testing.T) {
+		provider, exporter := useProvider(t, Config{Sampler: SamplerTraceIDRatio, SampleRatio: 0})
+		_, span := Start(context.Background(), "dropped")
+		span.End()
+		require.NoError(t, provider.ForceFlush(context.Background()))
+		assert.Empty(t, exporter.GetSpans())
+
+		provider, exporter = useProvider(t, Config{Sampler: SamplerTraceIDRatio, SampleRatio: 1, ServiceName: "gateway-test"})
+		_, span = Start(context.Background(), "recorded")
+		span.End()
+		require.NoError(t, provider.ForceFlush(context.Background()))
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "recorded", spans[0].Name)
+		assert.Contains(t, spans[0].Resource.Attributes(), attribute.String("service.name", "gateway-test"))
+	})
+
+	t.Run("parent based", func(t *testing.T) {
+		// The caller's decision wins over the ratio of root spans
+		provider, exporter := useProvider(t, Config{Sampler: SamplerParentBasedTraceIDRatio, SampleRatio: 0})
+
+		_, span := Start(remoteParent(t, true), "sampled by caller")
+		assert.True(t, span.SpanContext().IsSampled())
+		assert.Equal(t, remoteTraceID, span.SpanContext().TraceID().String())
+		span.End()
+
+		_, span = Start(remoteParent(t, false), "dropped by caller")
+		assert.False(t, span.SpanContext().IsSampled())
+		span.End()
+
+		require.NoError(t, provider.ForceFlush(context.Background()))
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, remoteSpanID, spans[0].Parent.SpanID().String())
+	})
+}
+
+func TestEndAndRecordError(t *testing.T) {
+	provider, exporter := useProvider(t, Config{Sampler: SamplerAlwaysOn})
+
+	ctx, span := Start(context.Background(), "failing")
+	RecordError(ctx, errors.New("query failed"))
+	span.End()
+
+	_, span = Start(context.Background(), "ended with error")
+	End(span, errors.New("flush failed"))
+
+	_, span = Start(context.Background(), "succeeded")
+	End(span, nil)
+
+	require.NoError(t, provider.ForceFlush(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "query failed", spans[0].Status.Description)
+	assert.Len(t, spans[0].Events, 1)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, codes.Unset, spans[2].Status.Code)
+
+	// Without a span there is nothing to record on
+	RecordError(context.Background(), errors.New("ignored"))
+}
+
+func TestLogFields(t *testing.T) {
+	assert.Empty(t, LogFields(context.Background()))
+
+	ctx := remoteParent(t, true)
+	fields := LogFields(ctx)
+	assert.Equal(t, remoteTraceID, fields["trace_id"])
+	assert.Equal(t, remoteSpanID, fields["span_id"])
+
+	assert.Equal(t, trace.SpanContextFromContext(ctx).TraceID().String(), fields["trace_id"])
+}
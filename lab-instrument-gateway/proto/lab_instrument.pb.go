@@ -197,6 +197,55 @@ func (CommandStatus) EnumDescriptor() ([]byte, []int) {
 	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{2}
 }
 
+type CredentialType int32
+
+const (
+	CredentialType_CREDENTIAL_TYPE_UNSPECIFIED CredentialType = 0
+	CredentialType_CREDENTIAL_TYPE_API_KEY     CredentialType = 1
+	CredentialType_CREDENTIAL_TYPE_CERTIFICATE CredentialType = 2
+)
+
+// Enum value maps for CredentialType.
+var (
+	CredentialType_name = map[int32]string{
+		0: "CREDENTIAL_TYPE_UNSPECIFIED",
+		1: "CREDENTIAL_TYPE_API_KEY",
+		2: "CREDENTIAL_TYPE_CERTIFICATE",
+	}
+	CredentialType_value = map[string]int32{
+		"CREDENTIAL_TYPE_UNSPECIFIED": 0,
+		"CREDENTIAL_TYPE_API_KEY":     1,
+		"CREDENTIAL_TYPE_CERTIFICATE": 2,
+	}
+)
+
+func (x CredentialType) Enum() *CredentialType {
+	p := new(CredentialType)
+	*p = x
+	return p
+}
+
+func (x CredentialType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CredentialType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_lab_instrument_proto_enumTypes[3].Descriptor()
+}
+
+func (CredentialType) Type() protoreflect.EnumType {
+	return &file_proto_lab_instrument_proto_enumTypes[3]
+}
+
+func (x CredentialType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CredentialType.Descriptor instead.
+func (CredentialType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{3}
+}
+
 type HealthStatus int32
 
 const (
@@ -233,11 +282,11 @@ func (x HealthStatus) String() string {
 }
 
 func (HealthStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_lab_instrument_proto_enumTypes[3].Descriptor()
+	return file_proto_lab_instrument_proto_enumTypes[4].Descriptor()
 }
 
 func (HealthStatus) Type() protoreflect.EnumType {
-	return &file_proto_lab_instrument_proto_enumTypes[3]
+	return &file_proto_lab_instrument_proto_enumTypes[4]
 }
 
 func (x HealthStatus) Number() protoreflect.EnumNumber {
@@ -246,7 +295,7 @@ func (x HealthStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthStatus.Descriptor instead.
 func (HealthStatus) EnumDescriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{3}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{4}
 }
 
 type AggregationType int32
@@ -291,11 +340,11 @@ func (x AggregationType) String() string {
 }
 
 func (AggregationType) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_lab_instrument_proto_enumTypes[4].Descriptor()
+	return file_proto_lab_instrument_proto_enumTypes[5].Descriptor()
 }
 
 func (AggregationType) Type() protoreflect.EnumType {
-	return &file_proto_lab_instrument_proto_enumTypes[4]
+	return &file_proto_lab_instrument_proto_enumTypes[5]
 }
 
 func (x AggregationType) Number() protoreflect.EnumNumber {
@@ -304,7 +353,7 @@ func (x AggregationType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use AggregationType.Descriptor instead.
 func (AggregationType) EnumDescriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{4}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{5}
 }
 
 // Device registration messages
@@ -1033,6 +1082,7 @@ type StreamDataResponse struct {
 	//	*StreamDataResponse_Command
 	//	*StreamDataResponse_Error
 	//	*StreamDataResponse_Heartbeat
+	//	*StreamDataResponse_Reconnect
 	Message       isStreamDataResponse_Message `protobuf_oneof:"message"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -1111,6 +1161,15 @@ func (x *StreamDataResponse) GetHeartbeat() *Heartbeat {
 	return nil
 }
 
+func (x *StreamDataResponse) GetReconnect() *Reconnect {
+	if x != nil {
+		if x, ok := x.Message.(*StreamDataResponse_Reconnect); ok {
+			return x.Reconnect
+		}
+	}
+	return nil
+}
+
 type isStreamDataResponse_Message interface {
 	isStreamDataResponse_Message()
 }
@@ -1131,6 +1190,10 @@ type StreamDataResponse_Heartbeat struct {
 	Heartbeat *Heartbeat `protobuf:"bytes,4,opt,name=heartbeat,proto3,oneof"`
 }
 
+type StreamDataResponse_Reconnect struct {
+	Reconnect *Reconnect `protobuf:"bytes,5,opt,name=reconnect,proto3,oneof"`
+}
+
 func (*StreamDataResponse_Ack) isStreamDataResponse_Message() {}
 
 func (*StreamDataResponse_Command) isStreamDataResponse_Message() {}
@@ -1139,6 +1202,8 @@ func (*StreamDataResponse_Error) isStreamDataResponse_Message() {}
 
 func (*StreamDataResponse_Heartbeat) isStreamDataResponse_Message() {}
 
+func (*StreamDataResponse_Reconnect) isStreamDataResponse_Message() {}
+
 type StreamInit struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
@@ -1371,6 +1436,64 @@ func (x *StreamError) GetRecoverable() bool {
 	return false
 }
 
+// Reconnect is sent before the gateway closes a stream to hand the device
+// off, e.g. to another replica while it shuts down. The stream then ends
+// with UNAVAILABLE and the device should register and stream again.
+type Reconnect struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Reason string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// last_sequence_number is the sequence number of the last measurement
+	// message written before the hand-off; later messages must be resent.
+	// It is 0 when unknown, in which case everything still buffered should be.
+	LastSequenceNumber int32 `protobuf:"varint,2,opt,name=last_sequence_number,json=lastSequenceNumber,proto3" json:"last_sequence_number,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Reconnect) Reset() {
+	*x = Reconnect{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reconnect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reconnect) ProtoMessage() {}
+
+func (x *Reconnect) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reconnect.ProtoReflect.Descriptor instead.
+func (*Reconnect) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Reconnect) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Reconnect) GetLastSequenceNumber() int32 {
+	if x != nil {
+		return x.LastSequenceNumber
+	}
+	return 0
+}
+
 // Measurement data messages
 type MeasurementData struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
@@ -1385,7 +1508,7 @@ type MeasurementData struct {
 
 func (x *MeasurementData) Reset() {
 	*x = MeasurementData{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[14]
+	mi := &file_proto_lab_instrument_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1397,7 +1520,7 @@ func (x *MeasurementData) String() string {
 func (*MeasurementData) ProtoMessage() {}
 
 func (x *MeasurementData) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[14]
+	mi := &file_proto_lab_instrument_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1410,7 +1533,7 @@ func (x *MeasurementData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MeasurementData.ProtoReflect.Descriptor instead.
 func (*MeasurementData) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{14}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *MeasurementData) GetDeviceId() string {
@@ -1461,7 +1584,7 @@ type DataPoint struct {
 
 func (x *DataPoint) Reset() {
 	*x = DataPoint{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[15]
+	mi := &file_proto_lab_instrument_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1473,7 +1596,7 @@ func (x *DataPoint) String() string {
 func (*DataPoint) ProtoMessage() {}
 
 func (x *DataPoint) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[15]
+	mi := &file_proto_lab_instrument_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1486,7 +1609,7 @@ func (x *DataPoint) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DataPoint.ProtoReflect.Descriptor instead.
 func (*DataPoint) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{15}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *DataPoint) GetType() string {
@@ -1531,13 +1654,16 @@ type SendCommandRequest struct {
 	Command        *Command               `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
 	TimeoutSeconds int32                  `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
 	Async          bool                   `protobuf:"varint,4,opt,name=async,proto3" json:"async,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Electronic signatures, required for command types configured as
+	// critical for the device type
+	Signatures    []*CommandSignature `protobuf:"bytes,5,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SendCommandRequest) Reset() {
 	*x = SendCommandRequest{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[16]
+	mi := &file_proto_lab_instrument_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1549,7 +1675,7 @@ func (x *SendCommandRequest) String() string {
 func (*SendCommandRequest) ProtoMessage() {}
 
 func (x *SendCommandRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[16]
+	mi := &file_proto_lab_instrument_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1562,7 +1688,7 @@ func (x *SendCommandRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendCommandRequest.ProtoReflect.Descriptor instead.
 func (*SendCommandRequest) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{16}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *SendCommandRequest) GetDeviceId() string {
@@ -1593,21 +1719,29 @@ func (x *SendCommandRequest) GetAsync() bool {
 	return false
 }
 
+func (x *SendCommandRequest) GetSignatures() []*CommandSignature {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
 type SendCommandResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	CommandId     string                 `protobuf:"bytes,3,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
-	Status        CommandStatus          `protobuf:"varint,4,opt,name=status,proto3,enum=lab_instrument.CommandStatus" json:"status,omitempty"`
-	SubmittedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=submitted_at,json=submittedAt,proto3" json:"submitted_at,omitempty"`
-	Result        *CommandResult         `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Success       bool                      `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                    `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CommandId     string                    `protobuf:"bytes,3,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Status        CommandStatus             `protobuf:"varint,4,opt,name=status,proto3,enum=lab_instrument.CommandStatus" json:"status,omitempty"`
+	SubmittedAt   *timestamppb.Timestamp    `protobuf:"bytes,5,opt,name=submitted_at,json=submittedAt,proto3" json:"submitted_at,omitempty"`
+	Result        *CommandResult            `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`
+	Signatures    []*CommandSignatureRecord `protobuf:"bytes,7,rep,name=signatures,proto3" json:"signatures,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SendCommandResponse) Reset() {
 	*x = SendCommandResponse{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[17]
+	mi := &file_proto_lab_instrument_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1619,7 +1753,7 @@ func (x *SendCommandResponse) String() string {
 func (*SendCommandResponse) ProtoMessage() {}
 
 func (x *SendCommandResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[17]
+	mi := &file_proto_lab_instrument_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1632,7 +1766,7 @@ func (x *SendCommandResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SendCommandResponse.ProtoReflect.Descriptor instead.
 func (*SendCommandResponse) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{17}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *SendCommandResponse) GetSuccess() bool {
@@ -1677,6 +1811,155 @@ func (x *SendCommandResponse) GetResult() *CommandResult {
 	return nil
 }
 
+func (x *SendCommandResponse) GetSignatures() []*CommandSignatureRecord {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+// CommandSignature is a signer's approval of a command. The credential is a
+// bearer token the signer obtained by authenticating again just before
+// signing; it is verified and discarded, never stored.
+type CommandSignature struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Credential string                 `protobuf:"bytes,1,opt,name=credential,proto3" json:"credential,omitempty"`
+	// meaning is what the signature attests: authored, reviewed, approved or verified
+	Meaning       string `protobuf:"bytes,2,opt,name=meaning,proto3" json:"meaning,omitempty"`
+	Comment       string `protobuf:"bytes,3,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandSignature) Reset() {
+	*x = CommandSignature{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandSignature) ProtoMessage() {}
+
+func (x *CommandSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandSignature.ProtoReflect.Descriptor instead.
+func (*CommandSignature) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CommandSignature) GetCredential() string {
+	if x != nil {
+		return x.Credential
+	}
+	return ""
+}
+
+func (x *CommandSignature) GetMeaning() string {
+	if x != nil {
+		return x.Meaning
+	}
+	return ""
+}
+
+func (x *CommandSignature) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+// CommandSignatureRecord is a verified signature as stored with the command
+type CommandSignatureRecord struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Signer   string                 `protobuf:"bytes,1,opt,name=signer,proto3" json:"signer,omitempty"`
+	Meaning  string                 `protobuf:"bytes,2,opt,name=meaning,proto3" json:"meaning,omitempty"`
+	Comment  string                 `protobuf:"bytes,3,opt,name=comment,proto3" json:"comment,omitempty"`
+	SignedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=signed_at,json=signedAt,proto3" json:"signed_at,omitempty"`
+	// content_hash is the SHA-256 of the signed device, type and parameters
+	ContentHash   string `protobuf:"bytes,5,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandSignatureRecord) Reset() {
+	*x = CommandSignatureRecord{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandSignatureRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandSignatureRecord) ProtoMessage() {}
+
+func (x *CommandSignatureRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandSignatureRecord.ProtoReflect.Descriptor instead.
+func (*CommandSignatureRecord) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CommandSignatureRecord) GetSigner() string {
+	if x != nil {
+		return x.Signer
+	}
+	return ""
+}
+
+func (x *CommandSignatureRecord) GetMeaning() string {
+	if x != nil {
+		return x.Meaning
+	}
+	return ""
+}
+
+func (x *CommandSignatureRecord) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *CommandSignatureRecord) GetSignedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SignedAt
+	}
+	return nil
+}
+
+func (x *CommandSignatureRecord) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
 type Command struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1690,7 +1973,7 @@ type Command struct {
 
 func (x *Command) Reset() {
 	*x = Command{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[18]
+	mi := &file_proto_lab_instrument_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1702,7 +1985,7 @@ func (x *Command) String() string {
 func (*Command) ProtoMessage() {}
 
 func (x *Command) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[18]
+	mi := &file_proto_lab_instrument_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1715,7 +1998,7 @@ func (x *Command) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Command.ProtoReflect.Descriptor instead.
 func (*Command) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{18}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *Command) GetId() string {
@@ -1766,7 +2049,7 @@ type CommandResult struct {
 
 func (x *CommandResult) Reset() {
 	*x = CommandResult{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[19]
+	mi := &file_proto_lab_instrument_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1778,7 +2061,7 @@ func (x *CommandResult) String() string {
 func (*CommandResult) ProtoMessage() {}
 
 func (x *CommandResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[19]
+	mi := &file_proto_lab_instrument_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1791,7 +2074,7 @@ func (x *CommandResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommandResult.ProtoReflect.Descriptor instead.
 func (*CommandResult) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{19}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *CommandResult) GetSuccess() bool {
@@ -1846,7 +2129,7 @@ type GetMeasurementsRequest struct {
 
 func (x *GetMeasurementsRequest) Reset() {
 	*x = GetMeasurementsRequest{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[20]
+	mi := &file_proto_lab_instrument_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1858,7 +2141,7 @@ func (x *GetMeasurementsRequest) String() string {
 func (*GetMeasurementsRequest) ProtoMessage() {}
 
 func (x *GetMeasurementsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[20]
+	mi := &file_proto_lab_instrument_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1871,7 +2154,7 @@ func (x *GetMeasurementsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetMeasurementsRequest.ProtoReflect.Descriptor instead.
 func (*GetMeasurementsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{20}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *GetMeasurementsRequest) GetDeviceId() string {
@@ -1942,7 +2225,7 @@ type GetMeasurementsResponse struct {
 
 func (x *GetMeasurementsResponse) Reset() {
 	*x = GetMeasurementsResponse{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[21]
+	mi := &file_proto_lab_instrument_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1954,7 +2237,7 @@ func (x *GetMeasurementsResponse) String() string {
 func (*GetMeasurementsResponse) ProtoMessage() {}
 
 func (x *GetMeasurementsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[21]
+	mi := &file_proto_lab_instrument_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1967,7 +2250,7 @@ func (x *GetMeasurementsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetMeasurementsResponse.ProtoReflect.Descriptor instead.
 func (*GetMeasurementsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{21}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *GetMeasurementsResponse) GetMeasurements() []*MeasurementData {
@@ -2010,7 +2293,7 @@ type MeasurementStatistics struct {
 
 func (x *MeasurementStatistics) Reset() {
 	*x = MeasurementStatistics{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[22]
+	mi := &file_proto_lab_instrument_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2022,7 +2305,7 @@ func (x *MeasurementStatistics) String() string {
 func (*MeasurementStatistics) ProtoMessage() {}
 
 func (x *MeasurementStatistics) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[22]
+	mi := &file_proto_lab_instrument_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2035,7 +2318,7 @@ func (x *MeasurementStatistics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MeasurementStatistics.ProtoReflect.Descriptor instead.
 func (*MeasurementStatistics) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{22}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *MeasurementStatistics) GetTotalPoints() int32 {
@@ -2079,7 +2362,7 @@ type DataTypeStats struct {
 
 func (x *DataTypeStats) Reset() {
 	*x = DataTypeStats{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[23]
+	mi := &file_proto_lab_instrument_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2091,7 +2374,7 @@ func (x *DataTypeStats) String() string {
 func (*DataTypeStats) ProtoMessage() {}
 
 func (x *DataTypeStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[23]
+	mi := &file_proto_lab_instrument_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2104,7 +2387,7 @@ func (x *DataTypeStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DataTypeStats.ProtoReflect.Descriptor instead.
 func (*DataTypeStats) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{23}
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *DataTypeStats) GetCount() int32 {
@@ -2152,19 +2435,1614 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[24]
+	mi := &file_proto_lab_instrument_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *HealthCheckRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+type HealthCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        HealthStatus           `protobuf:"varint,1,opt,name=status,proto3,enum=lab_instrument.HealthStatus" json:"status,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Details       map[string]string      `protobuf:"bytes,3,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResponse) ProtoMessage() {}
+
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *HealthCheckResponse) GetStatus() HealthStatus {
+	if x != nil {
+		return x.Status
+	}
+	return HealthStatus_HEALTH_UNKNOWN
+}
+
+func (x *HealthCheckResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetDetails() map[string]string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *HealthCheckResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Alert silence messages
+type Silence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceType    string                 `protobuf:"bytes,3,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	AlertType     string                 `protobuf:"bytes,4,opt,name=alert_type,json=alertType,proto3" json:"alert_type,omitempty"`
+	StartsAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,7,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Comment       string                 `protobuf:"bytes,8,opt,name=comment,proto3" json:"comment,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Silence) Reset() {
+	*x = Silence{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Silence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Silence) ProtoMessage() {}
+
+func (x *Silence) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Silence.ProtoReflect.Descriptor instead.
+func (*Silence) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Silence) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Silence) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Silence) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *Silence) GetAlertType() string {
+	if x != nil {
+		return x.AlertType
+	}
+	return ""
+}
+
+func (x *Silence) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *Silence) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *Silence) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Silence) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *Silence) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateSilenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Silence       *Silence               `protobuf:"bytes,1,opt,name=silence,proto3" json:"silence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSilenceRequest) Reset() {
+	*x = CreateSilenceRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSilenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSilenceRequest) ProtoMessage() {}
+
+func (x *CreateSilenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSilenceRequest.ProtoReflect.Descriptor instead.
+func (*CreateSilenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CreateSilenceRequest) GetSilence() *Silence {
+	if x != nil {
+		return x.Silence
+	}
+	return nil
+}
+
+type CreateSilenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Silence       *Silence               `protobuf:"bytes,1,opt,name=silence,proto3" json:"silence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSilenceResponse) Reset() {
+	*x = CreateSilenceResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSilenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSilenceResponse) ProtoMessage() {}
+
+func (x *CreateSilenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSilenceResponse.ProtoReflect.Descriptor instead.
+func (*CreateSilenceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CreateSilenceResponse) GetSilence() *Silence {
+	if x != nil {
+		return x.Silence
+	}
+	return nil
+}
+
+type ListSilencesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActiveOnly    bool                   `protobuf:"varint,1,opt,name=active_only,json=activeOnly,proto3" json:"active_only,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSilencesRequest) Reset() {
+	*x = ListSilencesRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSilencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSilencesRequest) ProtoMessage() {}
+
+func (x *ListSilencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSilencesRequest.ProtoReflect.Descriptor instead.
+func (*ListSilencesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListSilencesRequest) GetActiveOnly() bool {
+	if x != nil {
+		return x.ActiveOnly
+	}
+	return false
+}
+
+func (x *ListSilencesRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ListSilencesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSilencesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListSilencesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Silences      []*Silence             `protobuf:"bytes,1,rep,name=silences,proto3" json:"silences,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSilencesResponse) Reset() {
+	*x = ListSilencesResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSilencesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSilencesResponse) ProtoMessage() {}
+
+func (x *ListSilencesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSilencesResponse.ProtoReflect.Descriptor instead.
+func (*ListSilencesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListSilencesResponse) GetSilences() []*Silence {
+	if x != nil {
+		return x.Silences
+	}
+	return nil
+}
+
+func (x *ListSilencesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListSilencesResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type ExpireSilenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SilenceId     string                 `protobuf:"bytes,1,opt,name=silence_id,json=silenceId,proto3" json:"silence_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpireSilenceRequest) Reset() {
+	*x = ExpireSilenceRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpireSilenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpireSilenceRequest) ProtoMessage() {}
+
+func (x *ExpireSilenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpireSilenceRequest.ProtoReflect.Descriptor instead.
+func (*ExpireSilenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ExpireSilenceRequest) GetSilenceId() string {
+	if x != nil {
+		return x.SilenceId
+	}
+	return ""
+}
+
+type ExpireSilenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpireSilenceResponse) Reset() {
+	*x = ExpireSilenceResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpireSilenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpireSilenceResponse) ProtoMessage() {}
+
+func (x *ExpireSilenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpireSilenceResponse.ProtoReflect.Descriptor instead.
+func (*ExpireSilenceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ExpireSilenceResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ExpireSilenceResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Device history messages
+type DeviceEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	SessionId     string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FromStatus    DeviceStatus           `protobuf:"varint,5,opt,name=from_status,json=fromStatus,proto3,enum=lab_instrument.DeviceStatus" json:"from_status,omitempty"`
+	ToStatus      DeviceStatus           `protobuf:"varint,6,opt,name=to_status,json=toStatus,proto3,enum=lab_instrument.DeviceStatus" json:"to_status,omitempty"`
+	Message       string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceEvent) Reset() {
+	*x = DeviceEvent{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceEvent) ProtoMessage() {}
+
+func (x *DeviceEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceEvent.ProtoReflect.Descriptor instead.
+func (*DeviceEvent) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DeviceEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeviceEvent) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DeviceEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DeviceEvent) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *DeviceEvent) GetFromStatus() DeviceStatus {
+	if x != nil {
+		return x.FromStatus
+	}
+	return DeviceStatus_DEVICE_STATUS_UNKNOWN
+}
+
+func (x *DeviceEvent) GetToStatus() DeviceStatus {
+	if x != nil {
+		return x.ToStatus
+	}
+	return DeviceStatus_DEVICE_STATUS_UNKNOWN
+}
+
+func (x *DeviceEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *DeviceEvent) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *DeviceEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+type GetDeviceHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	EventTypes    []string               `protobuf:"bytes,4,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceHistoryRequest) Reset() {
+	*x = GetDeviceHistoryRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceHistoryRequest) ProtoMessage() {}
+
+func (x *GetDeviceHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetDeviceHistoryRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *GetDeviceHistoryRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetDeviceHistoryRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *GetDeviceHistoryRequest) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *GetDeviceHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetDeviceHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type GetDeviceHistoryResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Events           []*DeviceEvent         `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextPageToken    string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalCount       int32                  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	UptimePercentage float64                `protobuf:"fixed64,4,opt,name=uptime_percentage,json=uptimePercentage,proto3" json:"uptime_percentage,omitempty"`
+	WindowStart      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=window_start,json=windowStart,proto3" json:"window_start,omitempty"`
+	WindowEnd        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=window_end,json=windowEnd,proto3" json:"window_end,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetDeviceHistoryResponse) Reset() {
+	*x = GetDeviceHistoryResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceHistoryResponse) ProtoMessage() {}
+
+func (x *GetDeviceHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetDeviceHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetDeviceHistoryResponse) GetEvents() []*DeviceEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetDeviceHistoryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *GetDeviceHistoryResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *GetDeviceHistoryResponse) GetUptimePercentage() float64 {
+	if x != nil {
+		return x.UptimePercentage
+	}
+	return 0
+}
+
+func (x *GetDeviceHistoryResponse) GetWindowStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WindowStart
+	}
+	return nil
+}
+
+func (x *GetDeviceHistoryResponse) GetWindowEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WindowEnd
+	}
+	return nil
+}
+
+type Heartbeat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Metrics       map[string]string      `protobuf:"bytes,3,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Heartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Heartbeat) ProtoMessage() {}
+
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *Heartbeat) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Heartbeat) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Heartbeat) GetMetrics() map[string]string {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+// Device provisioning messages
+type CreateEnrollmentTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceType    string                 `protobuf:"bytes,1,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	DeviceGroup   string                 `protobuf:"bytes,2,opt,name=device_group,json=deviceGroup,proto3" json:"device_group,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEnrollmentTokenRequest) Reset() {
+	*x = CreateEnrollmentTokenRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEnrollmentTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEnrollmentTokenRequest) ProtoMessage() {}
+
+func (x *CreateEnrollmentTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEnrollmentTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreateEnrollmentTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CreateEnrollmentTokenRequest) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *CreateEnrollmentTokenRequest) GetDeviceGroup() string {
+	if x != nil {
+		return x.DeviceGroup
+	}
+	return ""
+}
+
+func (x *CreateEnrollmentTokenRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type CreateEnrollmentTokenResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// token is returned only once and cannot be retrieved later
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	TokenId       string                 `protobuf:"bytes,2,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEnrollmentTokenResponse) Reset() {
+	*x = CreateEnrollmentTokenResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEnrollmentTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEnrollmentTokenResponse) ProtoMessage() {}
+
+func (x *CreateEnrollmentTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEnrollmentTokenResponse.ProtoReflect.Descriptor instead.
+func (*CreateEnrollmentTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *CreateEnrollmentTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateEnrollmentTokenResponse) GetTokenId() string {
+	if x != nil {
+		return x.TokenId
+	}
+	return ""
+}
+
+func (x *CreateEnrollmentTokenResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type EnrollDeviceRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EnrollmentToken string                 `protobuf:"bytes,1,opt,name=enrollment_token,json=enrollmentToken,proto3" json:"enrollment_token,omitempty"`
+	DeviceId        string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Name            string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	CredentialType  CredentialType         `protobuf:"varint,4,opt,name=credential_type,json=credentialType,proto3,enum=lab_instrument.CredentialType" json:"credential_type,omitempty"`
+	// PEM-encoded certificate signing request, for certificate credentials
+	Csr           []byte `protobuf:"bytes,5,opt,name=csr,proto3" json:"csr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnrollDeviceRequest) Reset() {
+	*x = EnrollDeviceRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrollDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrollDeviceRequest) ProtoMessage() {}
+
+func (x *EnrollDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrollDeviceRequest.ProtoReflect.Descriptor instead.
+func (*EnrollDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *EnrollDeviceRequest) GetEnrollmentToken() string {
+	if x != nil {
+		return x.EnrollmentToken
+	}
+	return ""
+}
+
+func (x *EnrollDeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *EnrollDeviceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EnrollDeviceRequest) GetCredentialType() CredentialType {
+	if x != nil {
+		return x.CredentialType
+	}
+	return CredentialType_CREDENTIAL_TYPE_UNSPECIFIED
+}
+
+func (x *EnrollDeviceRequest) GetCsr() []byte {
+	if x != nil {
+		return x.Csr
+	}
+	return nil
+}
+
+type EnrollDeviceResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId       string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	CredentialType CredentialType         `protobuf:"varint,2,opt,name=credential_type,json=credentialType,proto3,enum=lab_instrument.CredentialType" json:"credential_type,omitempty"`
+	// api_key is returned only once and cannot be retrieved later
+	ApiKey string `protobuf:"bytes,3,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	// PEM-encoded client certificate and the CA that issued it
+	Certificate   []byte                 `protobuf:"bytes,4,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	CaCertificate []byte                 `protobuf:"bytes,5,opt,name=ca_certificate,json=caCertificate,proto3" json:"ca_certificate,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnrollDeviceResponse) Reset() {
+	*x = EnrollDeviceResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrollDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrollDeviceResponse) ProtoMessage() {}
+
+func (x *EnrollDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrollDeviceResponse.ProtoReflect.Descriptor instead.
+func (*EnrollDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *EnrollDeviceResponse) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *EnrollDeviceResponse) GetCredentialType() CredentialType {
+	if x != nil {
+		return x.CredentialType
+	}
+	return CredentialType_CREDENTIAL_TYPE_UNSPECIFIED
+}
+
+func (x *EnrollDeviceResponse) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+func (x *EnrollDeviceResponse) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *EnrollDeviceResponse) GetCaCertificate() []byte {
+	if x != nil {
+		return x.CaCertificate
+	}
+	return nil
+}
+
+func (x *EnrollDeviceResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+// API key messages
+type APIKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// prefix identifies the key in logs and listings; the key itself is never stored
+	Prefix  string `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Name    string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Subject string `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	// roles granted to the key, limited to device_groups when any are listed
+	Roles        []string `protobuf:"bytes,5,rep,name=roles,proto3" json:"roles,omitempty"`
+	DeviceGroups []string `protobuf:"bytes,6,rep,name=device_groups,json=deviceGroups,proto3" json:"device_groups,omitempty"`
+	// device_id is set for keys issued to a device on enrollment
+	DeviceId      string                 `protobuf:"bytes,7,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RevokedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	LastUsedAt    *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,11,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *APIKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *APIKey) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *APIKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKey) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *APIKey) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *APIKey) GetDeviceGroups() []string {
+	if x != nil {
+		return x.DeviceGroups
+	}
+	return nil
+}
+
+func (x *APIKey) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *APIKey) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetRevokedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *APIKey) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateAPIKeyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// subject the key authenticates as, defaulting to the name
+	Subject      string   `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Roles        []string `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	DeviceGroups []string `protobuf:"bytes,4,rep,name=device_groups,json=deviceGroups,proto3" json:"device_groups,omitempty"`
+	// ttl_seconds of zero creates a key that does not expire
+	TtlSeconds    int64 `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *CreateAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyRequest) GetDeviceGroups() []string {
+	if x != nil {
+		return x.DeviceGroups
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type CreateAPIKeyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// api_key is returned only once and cannot be retrieved later
+	ApiKey        string  `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	Key           *APIKey `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *CreateAPIKeyResponse) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyResponse) GetKey() *APIKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+type ListAPIKeysRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Subject        string                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	IncludeRevoked bool                   `protobuf:"varint,2,opt,name=include_revoked,json=includeRevoked,proto3" json:"include_revoked,omitempty"`
+	PageSize       int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken      string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysRequest) ProtoMessage() {}
+
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ListAPIKeysRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *ListAPIKeysRequest) GetIncludeRevoked() bool {
+	if x != nil {
+		return x.IncludeRevoked
+	}
+	return false
+}
+
+func (x *ListAPIKeysRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAPIKeysRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListAPIKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*APIKey              `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysResponse) ProtoMessage() {}
+
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ListAPIKeysResponse) GetKeys() []*APIKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *ListAPIKeysResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListAPIKeysResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type RevokeAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyId         string                 `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyRequest) Reset() {
+	*x = RevokeAPIKeyRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyRequest) ProtoMessage() {}
+
+func (x *RevokeAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *RevokeAPIKeyRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+type RevokeAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyResponse) Reset() {
+	*x = RevokeAPIKeyResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckRequest) String() string {
+func (x *RevokeAPIKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
+func (*RevokeAPIKeyResponse) ProtoMessage() {}
 
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[24]
+func (x *RevokeAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2175,43 +4053,214 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use RevokeAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *HealthCheckRequest) GetService() string {
+func (x *RevokeAPIKeyResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Service
+		return x.Success
+	}
+	return false
+}
+
+func (x *RevokeAPIKeyResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-type HealthCheckResponse struct {
+// Audit trail messages
+type AuditEntry struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Sequence   int64                  `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Actor      string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	AuthMethod string                 `protobuf:"bytes,5,opt,name=auth_method,json=authMethod,proto3" json:"auth_method,omitempty"`
+	// action is the full gRPC method name
+	Action       string `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`
+	ResourceType string `protobuf:"bytes,7,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	ResourceId   string `protobuf:"bytes,8,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	Reason       string `protobuf:"bytes,9,opt,name=reason,proto3" json:"reason,omitempty"`
+	// outcome is OK or the gRPC status code the action failed with
+	Outcome string `protobuf:"bytes,10,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	// before and after are JSON objects, empty when not applicable
+	Before        string `protobuf:"bytes,11,opt,name=before,proto3" json:"before,omitempty"`
+	After         string `protobuf:"bytes,12,opt,name=after,proto3" json:"after,omitempty"`
+	CorrelationId string `protobuf:"bytes,13,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	PreviousHash  string `protobuf:"bytes,14,opt,name=previous_hash,json=previousHash,proto3" json:"previous_hash,omitempty"`
+	Hash          string `protobuf:"bytes,15,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *AuditEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *AuditEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAuthMethod() string {
+	if x != nil {
+		return x.AuthMethod
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetResourceId() string {
+	if x != nil {
+		return x.ResourceId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetPreviousHash() string {
+	if x != nil {
+		return x.PreviousHash
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type QueryAuditLogRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        HealthStatus           `protobuf:"varint,1,opt,name=status,proto3,enum=lab_instrument.HealthStatus" json:"status,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Details       map[string]string      `protobuf:"bytes,3,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Actor         string                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	ResourceType  string                 `protobuf:"bytes,3,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	ResourceId    string                 `protobuf:"bytes,4,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	PageSize      int32                  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,8,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[25]
+func (x *QueryAuditLogRequest) Reset() {
+	*x = QueryAuditLogRequest{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckResponse) String() string {
+func (x *QueryAuditLogRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (*QueryAuditLogRequest) ProtoMessage() {}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[25]
+func (x *QueryAuditLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2222,63 +4271,91 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use QueryAuditLogRequest.ProtoReflect.Descriptor instead.
+func (*QueryAuditLogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{52}
 }
 
-func (x *HealthCheckResponse) GetStatus() HealthStatus {
+func (x *QueryAuditLogRequest) GetActor() string {
 	if x != nil {
-		return x.Status
+		return x.Actor
 	}
-	return HealthStatus_HEALTH_UNKNOWN
+	return ""
 }
 
-func (x *HealthCheckResponse) GetMessage() string {
+func (x *QueryAuditLogRequest) GetAction() string {
 	if x != nil {
-		return x.Message
+		return x.Action
 	}
 	return ""
 }
 
-func (x *HealthCheckResponse) GetDetails() map[string]string {
+func (x *QueryAuditLogRequest) GetResourceType() string {
 	if x != nil {
-		return x.Details
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *QueryAuditLogRequest) GetResourceId() string {
+	if x != nil {
+		return x.ResourceId
+	}
+	return ""
+}
+
+func (x *QueryAuditLogRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
 	}
 	return nil
 }
 
-func (x *HealthCheckResponse) GetTimestamp() *timestamppb.Timestamp {
+func (x *QueryAuditLogRequest) GetEndTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Timestamp
+		return x.EndTime
 	}
 	return nil
 }
 
-type Heartbeat struct {
+func (x *QueryAuditLogRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *QueryAuditLogRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type QueryAuditLogResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	Metrics       map[string]string      `protobuf:"bytes,3,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Entries       []*AuditEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Heartbeat) Reset() {
-	*x = Heartbeat{}
-	mi := &file_proto_lab_instrument_proto_msgTypes[26]
+func (x *QueryAuditLogResponse) Reset() {
+	*x = QueryAuditLogResponse{}
+	mi := &file_proto_lab_instrument_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Heartbeat) String() string {
+func (x *QueryAuditLogResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Heartbeat) ProtoMessage() {}
+func (*QueryAuditLogResponse) ProtoMessage() {}
 
-func (x *Heartbeat) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_lab_instrument_proto_msgTypes[26]
+func (x *QueryAuditLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_lab_instrument_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2289,30 +4366,30 @@ func (x *Heartbeat) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
-func (*Heartbeat) Descriptor() ([]byte, []int) {
-	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use QueryAuditLogResponse.ProtoReflect.Descriptor instead.
+func (*QueryAuditLogResponse) Descriptor() ([]byte, []int) {
+	return file_proto_lab_instrument_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *Heartbeat) GetTimestamp() *timestamppb.Timestamp {
+func (x *QueryAuditLogResponse) GetEntries() []*AuditEntry {
 	if x != nil {
-		return x.Timestamp
+		return x.Entries
 	}
 	return nil
 }
 
-func (x *Heartbeat) GetDeviceId() string {
+func (x *QueryAuditLogResponse) GetNextPageToken() string {
 	if x != nil {
-		return x.DeviceId
+		return x.NextPageToken
 	}
 	return ""
 }
 
-func (x *Heartbeat) GetMetrics() map[string]string {
+func (x *QueryAuditLogResponse) GetTotalCount() int32 {
 	if x != nil {
-		return x.Metrics
+		return x.TotalCount
 	}
-	return nil
+	return 0
 }
 
 var File_proto_lab_instrument_proto protoreflect.FileDescriptor
@@ -2388,12 +4465,13 @@ const file_proto_lab_instrument_proto_rawDesc = "" +
 	"\x04data\x18\x02 \x01(\v2\x1f.lab_instrument.MeasurementDataH\x00R\x04data\x129\n" +
 	"\theartbeat\x18\x03 \x01(\v2\x19.lab_instrument.HeartbeatH\x00R\theartbeat\x123\n" +
 	"\x05close\x18\x04 \x01(\v2\x1b.lab_instrument.StreamCloseH\x00R\x05closeB\t\n" +
-	"\amessage\"\xf3\x01\n" +
+	"\amessage\"\xae\x02\n" +
 	"\x12StreamDataResponse\x12-\n" +
 	"\x03ack\x18\x01 \x01(\v2\x19.lab_instrument.StreamAckH\x00R\x03ack\x123\n" +
 	"\acommand\x18\x02 \x01(\v2\x17.lab_instrument.CommandH\x00R\acommand\x123\n" +
 	"\x05error\x18\x03 \x01(\v2\x1b.lab_instrument.StreamErrorH\x00R\x05error\x129\n" +
-	"\theartbeat\x18\x04 \x01(\v2\x19.lab_instrument.HeartbeatH\x00R\theartbeatB\t\n" +
+	"\theartbeat\x18\x04 \x01(\v2\x19.lab_instrument.HeartbeatH\x00R\theartbeat\x129\n" +
+	"\treconnect\x18\x05 \x01(\v2\x19.lab_instrument.ReconnectH\x00R\treconnectB\t\n" +
 	"\amessage\"\x88\x01\n" +
 	"\n" +
 	"StreamInit\x12\x1b\n" +
@@ -2413,7 +4491,10 @@ const file_proto_lab_instrument_proto_rawDesc = "" +
 	"\vStreamError\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12 \n" +
-	"\vrecoverable\x18\x03 \x01(\bR\vrecoverable\"\xe8\x01\n" +
+	"\vrecoverable\x18\x03 \x01(\bR\vrecoverable\"U\n" +
+	"\tReconnect\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x120\n" +
+	"\x14last_sequence_number\x18\x02 \x01(\x05R\x12lastSequenceNumber\"\xe8\x01\n" +
 	"\x0fMeasurementData\x12\x1b\n" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x128\n" +
 	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12:\n" +
@@ -2429,12 +4510,15 @@ const file_proto_lab_instrument_proto_rawDesc = "" +
 	"\bmetadata\x18\x05 \x03(\v2'.lab_instrument.DataPoint.MetadataEntryR\bmetadata\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa3\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe5\x01\n" +
 	"\x12SendCommandRequest\x12\x1b\n" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x121\n" +
 	"\acommand\x18\x02 \x01(\v2\x17.lab_instrument.CommandR\acommand\x12'\n" +
 	"\x0ftimeout_seconds\x18\x03 \x01(\x05R\x0etimeoutSeconds\x12\x14\n" +
-	"\x05async\x18\x04 \x01(\bR\x05async\"\x95\x02\n" +
+	"\x05async\x18\x04 \x01(\bR\x05async\x12@\n" +
+	"\n" +
+	"signatures\x18\x05 \x03(\v2 .lab_instrument.CommandSignatureR\n" +
+	"signatures\"\xdd\x02\n" +
 	"\x13SendCommandResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
@@ -2442,7 +4526,22 @@ const file_proto_lab_instrument_proto_rawDesc = "" +
 	"command_id\x18\x03 \x01(\tR\tcommandId\x125\n" +
 	"\x06status\x18\x04 \x01(\x0e2\x1d.lab_instrument.CommandStatusR\x06status\x12=\n" +
 	"\fsubmitted_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vsubmittedAt\x125\n" +
-	"\x06result\x18\x06 \x01(\v2\x1d.lab_instrument.CommandResultR\x06result\"\x8c\x02\n" +
+	"\x06result\x18\x06 \x01(\v2\x1d.lab_instrument.CommandResultR\x06result\x12F\n" +
+	"\n" +
+	"signatures\x18\a \x03(\v2&.lab_instrument.CommandSignatureRecordR\n" +
+	"signatures\"f\n" +
+	"\x10CommandSignature\x12\x1e\n" +
+	"\n" +
+	"credential\x18\x01 \x01(\tR\n" +
+	"credential\x12\x18\n" +
+	"\ameaning\x18\x02 \x01(\tR\ameaning\x12\x18\n" +
+	"\acomment\x18\x03 \x01(\tR\acomment\"\xc0\x01\n" +
+	"\x16CommandSignatureRecord\x12\x16\n" +
+	"\x06signer\x18\x01 \x01(\tR\x06signer\x12\x18\n" +
+	"\ameaning\x18\x02 \x01(\tR\ameaning\x12\x18\n" +
+	"\acomment\x18\x03 \x01(\tR\acomment\x127\n" +
+	"\tsigned_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bsignedAt\x12!\n" +
+	"\fcontent_hash\x18\x05 \x01(\tR\vcontentHash\"\x8c\x02\n" +
 	"\aCommand\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12G\n" +
@@ -2508,14 +4607,193 @@ const file_proto_lab_instrument_proto_rawDesc = "" +
 	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x1a:\n" +
 	"\fDetailsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe0\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd8\x02\n" +
+	"\aSilence\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vdevice_type\x18\x03 \x01(\tR\n" +
+	"deviceType\x12\x1d\n" +
+	"\n" +
+	"alert_type\x18\x04 \x01(\tR\talertType\x127\n" +
+	"\tstarts_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\a \x01(\tR\tcreatedBy\x12\x18\n" +
+	"\acomment\x18\b \x01(\tR\acomment\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"I\n" +
+	"\x14CreateSilenceRequest\x121\n" +
+	"\asilence\x18\x01 \x01(\v2\x17.lab_instrument.SilenceR\asilence\"J\n" +
+	"\x15CreateSilenceResponse\x121\n" +
+	"\asilence\x18\x01 \x01(\v2\x17.lab_instrument.SilenceR\asilence\"\x8f\x01\n" +
+	"\x13ListSilencesRequest\x12\x1f\n" +
+	"\vactive_only\x18\x01 \x01(\bR\n" +
+	"activeOnly\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"\x94\x01\n" +
+	"\x14ListSilencesResponse\x123\n" +
+	"\bsilences\x18\x01 \x03(\v2\x17.lab_instrument.SilenceR\bsilences\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\"5\n" +
+	"\x14ExpireSilenceRequest\x12\x1d\n" +
+	"\n" +
+	"silence_id\x18\x01 \x01(\tR\tsilenceId\"K\n" +
+	"\x15ExpireSilenceResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xc2\x03\n" +
+	"\vDeviceEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\x12=\n" +
+	"\vfrom_status\x18\x05 \x01(\x0e2\x1c.lab_instrument.DeviceStatusR\n" +
+	"fromStatus\x129\n" +
+	"\tto_status\x18\x06 \x01(\x0e2\x1c.lab_instrument.DeviceStatusR\btoStatus\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\x12E\n" +
+	"\bmetadata\x18\b \x03(\v2).lab_instrument.DeviceEvent.MetadataEntryR\bmetadata\x12;\n" +
+	"\voccurred_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x85\x02\n" +
+	"\x17GetDeviceHistoryRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x129\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1f\n" +
+	"\vevent_types\x18\x04 \x03(\tR\n" +
+	"eventTypes\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x06 \x01(\tR\tpageToken\"\xbf\x02\n" +
+	"\x18GetDeviceHistoryResponse\x123\n" +
+	"\x06events\x18\x01 \x03(\v2\x1b.lab_instrument.DeviceEventR\x06events\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\x12+\n" +
+	"\x11uptime_percentage\x18\x04 \x01(\x01R\x10uptimePercentage\x12=\n" +
+	"\fwindow_start\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vwindowStart\x129\n" +
+	"\n" +
+	"window_end\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\twindowEnd\"\xe0\x01\n" +
 	"\tHeartbeat\x128\n" +
 	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1b\n" +
 	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12@\n" +
 	"\ametrics\x18\x03 \x03(\v2&.lab_instrument.Heartbeat.MetricsEntryR\ametrics\x1a:\n" +
 	"\fMetricsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*\xb4\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x83\x01\n" +
+	"\x1cCreateEnrollmentTokenRequest\x12\x1f\n" +
+	"\vdevice_type\x18\x01 \x01(\tR\n" +
+	"deviceType\x12!\n" +
+	"\fdevice_group\x18\x02 \x01(\tR\vdeviceGroup\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x03R\n" +
+	"ttlSeconds\"\x8b\x01\n" +
+	"\x1dCreateEnrollmentTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x19\n" +
+	"\btoken_id\x18\x02 \x01(\tR\atokenId\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xcc\x01\n" +
+	"\x13EnrollDeviceRequest\x12)\n" +
+	"\x10enrollment_token\x18\x01 \x01(\tR\x0fenrollmentToken\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12G\n" +
+	"\x0fcredential_type\x18\x04 \x01(\x0e2\x1e.lab_instrument.CredentialTypeR\x0ecredentialType\x12\x10\n" +
+	"\x03csr\x18\x05 \x01(\fR\x03csr\"\x99\x02\n" +
+	"\x14EnrollDeviceResponse\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12G\n" +
+	"\x0fcredential_type\x18\x02 \x01(\x0e2\x1e.lab_instrument.CredentialTypeR\x0ecredentialType\x12\x17\n" +
+	"\aapi_key\x18\x03 \x01(\tR\x06apiKey\x12 \n" +
+	"\vcertificate\x18\x04 \x01(\fR\vcertificate\x12%\n" +
+	"\x0eca_certificate\x18\x05 \x01(\fR\rcaCertificate\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xc4\x03\n" +
+	"\x06APIKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x14\n" +
+	"\x05roles\x18\x05 \x03(\tR\x05roles\x12#\n" +
+	"\rdevice_groups\x18\x06 \x03(\tR\fdeviceGroups\x12\x1b\n" +
+	"\tdevice_id\x18\a \x01(\tR\bdeviceId\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x129\n" +
+	"\n" +
+	"revoked_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\trevokedAt\x12<\n" +
+	"\flast_used_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\v \x01(\tR\tcreatedBy\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9f\x01\n" +
+	"\x13CreateAPIKeyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x12\x14\n" +
+	"\x05roles\x18\x03 \x03(\tR\x05roles\x12#\n" +
+	"\rdevice_groups\x18\x04 \x03(\tR\fdeviceGroups\x12\x1f\n" +
+	"\vttl_seconds\x18\x05 \x01(\x03R\n" +
+	"ttlSeconds\"Y\n" +
+	"\x14CreateAPIKeyResponse\x12\x17\n" +
+	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\x12(\n" +
+	"\x03key\x18\x02 \x01(\v2\x16.lab_instrument.APIKeyR\x03key\"\x93\x01\n" +
+	"\x12ListAPIKeysRequest\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12'\n" +
+	"\x0finclude_revoked\x18\x02 \x01(\bR\x0eincludeRevoked\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"\x8a\x01\n" +
+	"\x13ListAPIKeysResponse\x12*\n" +
+	"\x04keys\x18\x01 \x03(\v2\x16.lab_instrument.APIKeyR\x04keys\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\",\n" +
+	"\x13RevokeAPIKeyRequest\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\"J\n" +
+	"\x14RevokeAPIKeyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xca\x03\n" +
+	"\n" +
+	"AuditEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x03R\bsequence\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x1f\n" +
+	"\vauth_method\x18\x05 \x01(\tR\n" +
+	"authMethod\x12\x16\n" +
+	"\x06action\x18\x06 \x01(\tR\x06action\x12#\n" +
+	"\rresource_type\x18\a \x01(\tR\fresourceType\x12\x1f\n" +
+	"\vresource_id\x18\b \x01(\tR\n" +
+	"resourceId\x12\x16\n" +
+	"\x06reason\x18\t \x01(\tR\x06reason\x12\x18\n" +
+	"\aoutcome\x18\n" +
+	" \x01(\tR\aoutcome\x12\x16\n" +
+	"\x06before\x18\v \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\f \x01(\tR\x05after\x12%\n" +
+	"\x0ecorrelation_id\x18\r \x01(\tR\rcorrelationId\x12#\n" +
+	"\rprevious_hash\x18\x0e \x01(\tR\fpreviousHash\x12\x12\n" +
+	"\x04hash\x18\x0f \x01(\tR\x04hash\"\xb8\x02\n" +
+	"\x14QueryAuditLogRequest\x12\x14\n" +
+	"\x05actor\x18\x01 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12#\n" +
+	"\rresource_type\x18\x03 \x01(\tR\fresourceType\x12\x1f\n" +
+	"\vresource_id\x18\x04 \x01(\tR\n" +
+	"resourceId\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1b\n" +
+	"\tpage_size\x18\a \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\b \x01(\tR\tpageToken\"\x96\x01\n" +
+	"\x15QueryAuditLogResponse\x124\n" +
+	"\aentries\x18\x01 \x03(\v2\x1a.lab_instrument.AuditEntryR\aentries\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount*\xb4\x01\n" +
 	"\fDeviceStatus\x12\x19\n" +
 	"\x15DEVICE_STATUS_UNKNOWN\x10\x00\x12\x18\n" +
 	"\x14DEVICE_STATUS_ONLINE\x10\x01\x12\x19\n" +
@@ -2536,7 +4814,11 @@ const file_proto_lab_instrument_proto_rawDesc = "" +
 	"\x18COMMAND_STATUS_COMPLETED\x10\x03\x12\x19\n" +
 	"\x15COMMAND_STATUS_FAILED\x10\x04\x12\x1a\n" +
 	"\x16COMMAND_STATUS_TIMEOUT\x10\x05\x12\x1c\n" +
-	"\x18COMMAND_STATUS_CANCELLED\x10\x06*j\n" +
+	"\x18COMMAND_STATUS_CANCELLED\x10\x06*o\n" +
+	"\x0eCredentialType\x12\x1f\n" +
+	"\x1bCREDENTIAL_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17CREDENTIAL_TYPE_API_KEY\x10\x01\x12\x1f\n" +
+	"\x1bCREDENTIAL_TYPE_CERTIFICATE\x10\x02*j\n" +
 	"\fHealthStatus\x12\x12\n" +
 	"\x0eHEALTH_UNKNOWN\x10\x00\x12\x12\n" +
 	"\x0eHEALTH_SERVING\x10\x01\x12\x16\n" +
@@ -2548,16 +4830,26 @@ const file_proto_lab_instrument_proto_rawDesc = "" +
 	"\x0fAGGREGATION_MIN\x10\x02\x12\x13\n" +
 	"\x0fAGGREGATION_MAX\x10\x03\x12\x13\n" +
 	"\x0fAGGREGATION_SUM\x10\x04\x12\x15\n" +
-	"\x11AGGREGATION_COUNT\x10\x052\xa0\x05\n" +
+	"\x11AGGREGATION_COUNT\x10\x052\xdb\f\n" +
 	"\x14LabInstrumentGateway\x12_\n" +
 	"\x0eRegisterDevice\x12%.lab_instrument.RegisterDeviceRequest\x1a&.lab_instrument.RegisterDeviceResponse\x12b\n" +
 	"\x0fGetDeviceStatus\x12&.lab_instrument.GetDeviceStatusRequest\x1a'.lab_instrument.GetDeviceStatusResponse\x12V\n" +
-	"\vListDevices\x12\".lab_instrument.ListDevicesRequest\x1a#.lab_instrument.ListDevicesResponse\x12W\n" +
+	"\vListDevices\x12\".lab_instrument.ListDevicesRequest\x1a#.lab_instrument.ListDevicesResponse\x12e\n" +
+	"\x10GetDeviceHistory\x12'.lab_instrument.GetDeviceHistoryRequest\x1a(.lab_instrument.GetDeviceHistoryResponse\x12W\n" +
 	"\n" +
 	"StreamData\x12!.lab_instrument.StreamDataRequest\x1a\".lab_instrument.StreamDataResponse(\x010\x01\x12V\n" +
 	"\vSendCommand\x12\".lab_instrument.SendCommandRequest\x1a#.lab_instrument.SendCommandResponse\x12b\n" +
 	"\x0fGetMeasurements\x12&.lab_instrument.GetMeasurementsRequest\x1a'.lab_instrument.GetMeasurementsResponse\x12V\n" +
-	"\vHealthCheck\x12\".lab_instrument.HealthCheckRequest\x1a#.lab_instrument.HealthCheckResponseB&Z$github.com/yourorg/lab-gateway/protob\x06proto3"
+	"\vHealthCheck\x12\".lab_instrument.HealthCheckRequest\x1a#.lab_instrument.HealthCheckResponse\x12\\\n" +
+	"\rCreateSilence\x12$.lab_instrument.CreateSilenceRequest\x1a%.lab_instrument.CreateSilenceResponse\x12Y\n" +
+	"\fListSilences\x12#.lab_instrument.ListSilencesRequest\x1a$.lab_instrument.ListSilencesResponse\x12\\\n" +
+	"\rExpireSilence\x12$.lab_instrument.ExpireSilenceRequest\x1a%.lab_instrument.ExpireSilenceResponse\x12t\n" +
+	"\x15CreateEnrollmentToken\x12,.lab_instrument.CreateEnrollmentTokenRequest\x1a-.lab_instrument.CreateEnrollmentTokenResponse\x12Y\n" +
+	"\fEnrollDevice\x12#.lab_instrument.EnrollDeviceRequest\x1a$.lab_instrument.EnrollDeviceResponse\x12Y\n" +
+	"\fCreateAPIKey\x12#.lab_instrument.CreateAPIKeyRequest\x1a$.lab_instrument.CreateAPIKeyResponse\x12V\n" +
+	"\vListAPIKeys\x12\".lab_instrument.ListAPIKeysRequest\x1a#.lab_instrument.ListAPIKeysResponse\x12Y\n" +
+	"\fRevokeAPIKey\x12#.lab_instrument.RevokeAPIKeyRequest\x1a$.lab_instrument.RevokeAPIKeyResponse\x12\\\n" +
+	"\rQueryAuditLog\x12$.lab_instrument.QueryAuditLogRequest\x1a%.lab_instrument.QueryAuditLogResponseB&Z$github.com/yourorg/lab-gateway/protob\x06proto3"
 
 var (
 	file_proto_lab_instrument_proto_rawDescOnce sync.Once
@@ -2571,123 +4863,205 @@ func file_proto_lab_instrument_proto_rawDescGZIP() []byte {
 	return file_proto_lab_instrument_proto_rawDescData
 }
 
-var file_proto_lab_instrument_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_proto_lab_instrument_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
+var file_proto_lab_instrument_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_proto_lab_instrument_proto_msgTypes = make([]protoimpl.MessageInfo, 65)
 var file_proto_lab_instrument_proto_goTypes = []any{
-	(DeviceStatus)(0),               // 0: lab_instrument.DeviceStatus
-	(QualityCode)(0),                // 1: lab_instrument.QualityCode
-	(CommandStatus)(0),              // 2: lab_instrument.CommandStatus
-	(HealthStatus)(0),               // 3: lab_instrument.HealthStatus
-	(AggregationType)(0),            // 4: lab_instrument.AggregationType
-	(*RegisterDeviceRequest)(nil),   // 5: lab_instrument.RegisterDeviceRequest
-	(*RegisterDeviceResponse)(nil),  // 6: lab_instrument.RegisterDeviceResponse
-	(*GetDeviceStatusRequest)(nil),  // 7: lab_instrument.GetDeviceStatusRequest
-	(*GetDeviceStatusResponse)(nil), // 8: lab_instrument.GetDeviceStatusResponse
-	(*ListDevicesRequest)(nil),      // 9: lab_instrument.ListDevicesRequest
-	(*ListDevicesResponse)(nil),     // 10: lab_instrument.ListDevicesResponse
-	(*DeviceFilter)(nil),            // 11: lab_instrument.DeviceFilter
-	(*DeviceInfo)(nil),              // 12: lab_instrument.DeviceInfo
-	(*StreamDataRequest)(nil),       // 13: lab_instrument.StreamDataRequest
-	(*StreamDataResponse)(nil),      // 14: lab_instrument.StreamDataResponse
-	(*StreamInit)(nil),              // 15: lab_instrument.StreamInit
-	(*StreamAck)(nil),               // 16: lab_instrument.StreamAck
-	(*StreamClose)(nil),             // 17: lab_instrument.StreamClose
-	(*StreamError)(nil),             // 18: lab_instrument.StreamError
-	(*MeasurementData)(nil),         // 19: lab_instrument.MeasurementData
-	(*DataPoint)(nil),               // 20: lab_instrument.DataPoint
-	(*SendCommandRequest)(nil),      // 21: lab_instrument.SendCommandRequest
-	(*SendCommandResponse)(nil),     // 22: lab_instrument.SendCommandResponse
-	(*Command)(nil),                 // 23: lab_instrument.Command
-	(*CommandResult)(nil),           // 24: lab_instrument.CommandResult
-	(*GetMeasurementsRequest)(nil),  // 25: lab_instrument.GetMeasurementsRequest
-	(*GetMeasurementsResponse)(nil), // 26: lab_instrument.GetMeasurementsResponse
-	(*MeasurementStatistics)(nil),   // 27: lab_instrument.MeasurementStatistics
-	(*DataTypeStats)(nil),           // 28: lab_instrument.DataTypeStats
-	(*HealthCheckRequest)(nil),      // 29: lab_instrument.HealthCheckRequest
-	(*HealthCheckResponse)(nil),     // 30: lab_instrument.HealthCheckResponse
-	(*Heartbeat)(nil),               // 31: lab_instrument.Heartbeat
-	nil,                             // 32: lab_instrument.RegisterDeviceRequest.MetadataEntry
-	nil,                             // 33: lab_instrument.GetDeviceStatusResponse.MetadataEntry
-	nil,                             // 34: lab_instrument.DeviceFilter.MetadataFiltersEntry
-	nil,                             // 35: lab_instrument.DeviceInfo.MetadataEntry
-	nil,                             // 36: lab_instrument.DataPoint.MetadataEntry
-	nil,                             // 37: lab_instrument.Command.ParametersEntry
-	nil,                             // 38: lab_instrument.CommandResult.DataEntry
-	nil,                             // 39: lab_instrument.MeasurementStatistics.DataTypeStatsEntry
-	nil,                             // 40: lab_instrument.HealthCheckResponse.DetailsEntry
-	nil,                             // 41: lab_instrument.Heartbeat.MetricsEntry
-	(*timestamppb.Timestamp)(nil),   // 42: google.protobuf.Timestamp
+	(DeviceStatus)(0),                     // 0: lab_instrument.DeviceStatus
+	(QualityCode)(0),                      // 1: lab_instrument.QualityCode
+	(CommandStatus)(0),                    // 2: lab_instrument.CommandStatus
+	(CredentialType)(0),                   // 3: lab_instrument.CredentialType
+	(HealthStatus)(0),                     // 4: lab_instrument.HealthStatus
+	(AggregationType)(0),                  // 5: lab_instrument.AggregationType
+	(*RegisterDeviceRequest)(nil),         // 6: lab_instrument.RegisterDeviceRequest
+	(*RegisterDeviceResponse)(nil),        // 7: lab_instrument.RegisterDeviceResponse
+	(*GetDeviceStatusRequest)(nil),        // 8: lab_instrument.GetDeviceStatusRequest
+	(*GetDeviceStatusResponse)(nil),       // 9: lab_instrument.GetDeviceStatusResponse
+	(*ListDevicesRequest)(nil),            // 10: lab_instrument.ListDevicesRequest
+	(*ListDevicesResponse)(nil),           // 11: lab_instrument.ListDevicesResponse
+	(*DeviceFilter)(nil),                  // 12: lab_instrument.DeviceFilter
+	(*DeviceInfo)(nil),                    // 13: lab_instrument.DeviceInfo
+	(*StreamDataRequest)(nil),             // 14: lab_instrument.StreamDataRequest
+	(*StreamDataResponse)(nil),            // 15: lab_instrument.StreamDataResponse
+	(*StreamInit)(nil),                    // 16: lab_instrument.StreamInit
+	(*StreamAck)(nil),                     // 17: lab_instrument.StreamAck
+	(*StreamClose)(nil),                   // 18: lab_instrument.StreamClose
+	(*StreamError)(nil),                   // 19: lab_instrument.StreamError
+	(*Reconnect)(nil),                     // 20: lab_instrument.Reconnect
+	(*MeasurementData)(nil),               // 21: lab_instrument.MeasurementData
+	(*DataPoint)(nil),                     // 22: lab_instrument.DataPoint
+	(*SendCommandRequest)(nil),            // 23: lab_instrument.SendCommandRequest
+	(*SendCommandResponse)(nil),           // 24: lab_instrument.SendCommandResponse
+	(*CommandSignature)(nil),              // 25: lab_instrument.CommandSignature
+	(*CommandSignatureRecord)(nil),        // 26: lab_instrument.CommandSignatureRecord
+	(*Command)(nil),                       // 27: lab_instrument.Command
+	(*CommandResult)(nil),                 // 28: lab_instrument.CommandResult
+	(*GetMeasurementsRequest)(nil),        // 29: lab_instrument.GetMeasurementsRequest
+	(*GetMeasurementsResponse)(nil),       // 30: lab_instrument.GetMeasurementsResponse
+	(*MeasurementStatistics)(nil),         // 31: lab_instrument.MeasurementStatistics
+	(*DataTypeStats)(nil),                 // 32: lab_instrument.DataTypeStats
+	(*HealthCheckRequest)(nil),            // 33: lab_instrument.HealthCheckRequest
+	(*HealthCheckResponse)(nil),           // 34: lab_instrument.HealthCheckResponse
+	(*Silence)(nil),                       // 35: lab_instrument.Silence
+	(*CreateSilenceRequest)(nil),          // 36: lab_instrument.CreateSilenceRequest
+	(*CreateSilenceResponse)(nil),         // 37: lab_instrument.CreateSilenceResponse
+	(*ListSilencesRequest)(nil),           // 38: lab_instrument.ListSilencesRequest
+	(*ListSilencesResponse)(nil),          // 39: lab_instrument.ListSilencesResponse
+	(*ExpireSilenceRequest)(nil),          // 40: lab_instrument.ExpireSilenceRequest
+	(*ExpireSilenceResponse)(nil),         // 41: lab_instrument.ExpireSilenceResponse
+	(*DeviceEvent)(nil),                   // 42: lab_instrument.DeviceEvent
+	(*GetDeviceHistoryRequest)(nil),       // 43: lab_instrument.GetDeviceHistoryRequest
+	(*GetDeviceHistoryResponse)(nil),      // 44: lab_instrument.GetDeviceHistoryResponse
+	(*Heartbeat)(nil),                     // 45: lab_instrument.Heartbeat
+	(*CreateEnrollmentTokenRequest)(nil),  // 46: lab_instrument.CreateEnrollmentTokenRequest
+	(*CreateEnrollmentTokenResponse)(nil), // 47: lab_instrument.CreateEnrollmentTokenResponse
+	(*EnrollDeviceRequest)(nil),           // 48: lab_instrument.EnrollDeviceRequest
+	(*EnrollDeviceResponse)(nil),          // 49: lab_instrument.EnrollDeviceResponse
+	(*APIKey)(nil),                        // 50: lab_instrument.APIKey
+	(*CreateAPIKeyRequest)(nil),           // 51: lab_instrument.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),          // 52: lab_instrument.CreateAPIKeyResponse
+	(*ListAPIKeysRequest)(nil),            // 53: lab_instrument.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),           // 54: lab_instrument.ListAPIKeysResponse
+	(*RevokeAPIKeyRequest)(nil),           // 55: lab_instrument.RevokeAPIKeyRequest
+	(*RevokeAPIKeyResponse)(nil),          // 56: lab_instrument.RevokeAPIKeyResponse
+	(*AuditEntry)(nil),                    // 57: lab_instrument.AuditEntry
+	(*QueryAuditLogRequest)(nil),          // 58: lab_instrument.QueryAuditLogRequest
+	(*QueryAuditLogResponse)(nil),         // 59: lab_instrument.QueryAuditLogResponse
+	nil,                                   // 60: lab_instrument.RegisterDeviceRequest.MetadataEntry
+	nil,                                   // 61: lab_instrument.GetDeviceStatusResponse.MetadataEntry
+	nil,                                   // 62: lab_instrument.DeviceFilter.MetadataFiltersEntry
+	nil,                                   // 63: lab_instrument.DeviceInfo.MetadataEntry
+	nil,                                   // 64: lab_instrument.DataPoint.MetadataEntry
+	nil,                                   // 65: lab_instrument.Command.ParametersEntry
+	nil,                                   // 66: lab_instrument.CommandResult.DataEntry
+	nil,                                   // 67: lab_instrument.MeasurementStatistics.DataTypeStatsEntry
+	nil,                                   // 68: lab_instrument.HealthCheckResponse.DetailsEntry
+	nil,                                   // 69: lab_instrument.DeviceEvent.MetadataEntry
+	nil,                                   // 70: lab_instrument.Heartbeat.MetricsEntry
+	(*timestamppb.Timestamp)(nil),         // 71: google.protobuf.Timestamp
 }
 var file_proto_lab_instrument_proto_depIdxs = []int32{
-	32, // 0: lab_instrument.RegisterDeviceRequest.metadata:type_name -> lab_instrument.RegisterDeviceRequest.MetadataEntry
-	42, // 1: lab_instrument.RegisterDeviceResponse.registered_at:type_name -> google.protobuf.Timestamp
-	0,  // 2: lab_instrument.GetDeviceStatusResponse.status:type_name -> lab_instrument.DeviceStatus
-	42, // 3: lab_instrument.GetDeviceStatusResponse.last_seen:type_name -> google.protobuf.Timestamp
-	33, // 4: lab_instrument.GetDeviceStatusResponse.metadata:type_name -> lab_instrument.GetDeviceStatusResponse.MetadataEntry
-	3,  // 5: lab_instrument.GetDeviceStatusResponse.health:type_name -> lab_instrument.HealthStatus
-	11, // 6: lab_instrument.ListDevicesRequest.filter:type_name -> lab_instrument.DeviceFilter
-	12, // 7: lab_instrument.ListDevicesResponse.devices:type_name -> lab_instrument.DeviceInfo
-	0,  // 8: lab_instrument.DeviceFilter.status:type_name -> lab_instrument.DeviceStatus
-	42, // 9: lab_instrument.DeviceFilter.last_seen_after:type_name -> google.protobuf.Timestamp
-	42, // 10: lab_instrument.DeviceFilter.last_seen_before:type_name -> google.protobuf.Timestamp
-	34, // 11: lab_instrument.DeviceFilter.metadata_filters:type_name -> lab_instrument.DeviceFilter.MetadataFiltersEntry
-	0,  // 12: lab_instrument.DeviceInfo.status:type_name -> lab_instrument.DeviceStatus
-	42, // 13: lab_instrument.DeviceInfo.last_seen:type_name -> google.protobuf.Timestamp
-	42, // 14: lab_instrument.DeviceInfo.registered_at:type_name -> google.protobuf.Timestamp
-	35, // 15: lab_instrument.DeviceInfo.metadata:type_name -> lab_instrument.DeviceInfo.MetadataEntry
-	15, // 16: lab_instrument.StreamDataRequest.init:type_name -> lab_instrument.StreamInit
-	19, // 17: lab_instrument.StreamDataRequest.data:type_name -> lab_instrument.MeasurementData
-	31, // 18: lab_instrument.StreamDataRequest.heartbeat:type_name -> lab_instrument.Heartbeat
-	17, // 19: lab_instrument.StreamDataRequest.close:type_name -> lab_instrument.StreamClose
-	16, // 20: lab_instrument.StreamDataResponse.ack:type_name -> lab_instrument.StreamAck
-	23, // 21: lab_instrument.StreamDataResponse.command:type_name -> lab_instrument.Command
-	18, // 22: lab_instrument.StreamDataResponse.error:type_name -> lab_instrument.StreamError
-	31, // 23: lab_instrument.StreamDataResponse.heartbeat:type_name -> lab_instrument.Heartbeat
-	42, // 24: lab_instrument.MeasurementData.timestamp:type_name -> google.protobuf.Timestamp
-	20, // 25: lab_instrument.MeasurementData.data_points:type_name -> lab_instrument.DataPoint
-	1,  // 26: lab_instrument.DataPoint.quality:type_name -> lab_instrument.QualityCode
-	36, // 27: lab_instrument.DataPoint.metadata:type_name -> lab_instrument.DataPoint.MetadataEntry
-	23, // 28: lab_instrument.SendCommandRequest.command:type_name -> lab_instrument.Command
-	2,  // 29: lab_instrument.SendCommandResponse.status:type_name -> lab_instrument.CommandStatus
-	42, // 30: lab_instrument.SendCommandResponse.submitted_at:type_name -> google.protobuf.Timestamp
-	24, // 31: lab_instrument.SendCommandResponse.result:type_name -> lab_instrument.CommandResult
-	37, // 32: lab_instrument.Command.parameters:type_name -> lab_instrument.Command.ParametersEntry
-	42, // 33: lab_instrument.Command.expires_at:type_name -> google.protobuf.Timestamp
-	38, // 34: lab_instrument.CommandResult.data:type_name -> lab_instrument.CommandResult.DataEntry
-	42, // 35: lab_instrument.CommandResult.executed_at:type_name -> google.protobuf.Timestamp
-	42, // 36: lab_instrument.GetMeasurementsRequest.start_time:type_name -> google.protobuf.Timestamp
-	42, // 37: lab_instrument.GetMeasurementsRequest.end_time:type_name -> google.protobuf.Timestamp
-	4,  // 38: lab_instrument.GetMeasurementsRequest.aggregation:type_name -> lab_instrument.AggregationType
-	19, // 39: lab_instrument.GetMeasurementsResponse.measurements:type_name -> lab_instrument.MeasurementData
-	27, // 40: lab_instrument.GetMeasurementsResponse.statistics:type_name -> lab_instrument.MeasurementStatistics
-	42, // 41: lab_instrument.MeasurementStatistics.earliest_timestamp:type_name -> google.protobuf.Timestamp
-	42, // 42: lab_instrument.MeasurementStatistics.latest_timestamp:type_name -> google.protobuf.Timestamp
-	39, // 43: lab_instrument.MeasurementStatistics.data_type_stats:type_name -> lab_instrument.MeasurementStatistics.DataTypeStatsEntry
-	3,  // 44: lab_instrument.HealthCheckResponse.status:type_name -> lab_instrument.HealthStatus
-	40, // 45: lab_instrument.HealthCheckResponse.details:type_name -> lab_instrument.HealthCheckResponse.DetailsEntry
-	42, // 46: lab_instrument.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
-	42, // 47: lab_instrument.Heartbeat.timestamp:type_name -> google.protobuf.Timestamp
-	41, // 48: lab_instrument.Heartbeat.metrics:type_name -> lab_instrument.Heartbeat.MetricsEntry
-	28, // 49: lab_instrument.MeasurementStatistics.DataTypeStatsEntry.value:type_name -> lab_instrument.DataTypeStats
-	5,  // 50: lab_instrument.LabInstrumentGateway.RegisterDevice:input_type -> lab_instrument.RegisterDeviceRequest
-	7,  // 51: lab_instrument.LabInstrumentGateway.GetDeviceStatus:input_type -> lab_instrument.GetDeviceStatusRequest
-	9,  // 52: lab_instrument.LabInstrumentGateway.ListDevices:input_type -> lab_instrument.ListDevicesRequest
-	13, // 53: lab_instrument.LabInstrumentGateway.StreamData:input_type -> lab_instrument.StreamDataRequest
-	21, // 54: lab_instrument.LabInstrumentGateway.SendCommand:input_type -> lab_instrument.SendCommandRequest
-	25, // 55: lab_instrument.LabInstrumentGateway.GetMeasurements:input_type -> lab_instrument.GetMeasurementsRequest
-	29, // 56: lab_instrument.LabInstrumentGateway.HealthCheck:input_type -> lab_instrument.HealthCheckRequest
-	6,  // 57: lab_instrument.LabInstrumentGateway.RegisterDevice:output_type -> lab_instrument.RegisterDeviceResponse
-	8,  // 58: lab_instrument.LabInstrumentGateway.GetDeviceStatus:output_type -> lab_instrument.GetDeviceStatusResponse
-	10, // 59: lab_instrument.LabInstrumentGateway.ListDevices:output_type -> lab_instrument.ListDevicesResponse
-	14, // 60: lab_instrument.LabInstrumentGateway.StreamData:output_type -> lab_instrument.StreamDataResponse
-	22, // 61: lab_instrument.LabInstrumentGateway.SendCommand:output_type -> lab_instrument.SendCommandResponse
-	26, // 62: lab_instrument.LabInstrumentGateway.GetMeasurements:output_type -> lab_instrument.GetMeasurementsResponse
-	30, // 63: lab_instrument.LabInstrumentGateway.HealthCheck:output_type -> lab_instrument.HealthCheckResponse
-	57, // [57:64] is the sub-list for method output_type
-	50, // [50:57] is the sub-list for method input_type
-	50, // [50:50] is the sub-list for extension type_name
-	50, // [50:50] is the sub-list for extension extendee
-	0,  // [0:50] is the sub-list for field type_name
+	60,  // 0: lab_instrument.RegisterDeviceRequest.metadata:type_name -> lab_instrument.RegisterDeviceRequest.MetadataEntry
+	71,  // 1: lab_instrument.RegisterDeviceResponse.registered_at:type_name -> google.protobuf.Timestamp
+	0,   // 2: lab_instrument.GetDeviceStatusResponse.status:type_name -> lab_instrument.DeviceStatus
+	71,  // 3: lab_instrument.GetDeviceStatusResponse.last_seen:type_name -> google.protobuf.Timestamp
+	61,  // 4: lab_instrument.GetDeviceStatusResponse.metadata:type_name -> lab_instrument.GetDeviceStatusResponse.MetadataEntry
+	4,   // 5: lab_instrument.GetDeviceStatusResponse.health:type_name -> lab_instrument.HealthStatus
+	12,  // 6: lab_instrument.ListDevicesRequest.filter:type_name -> lab_instrument.DeviceFilter
+	13,  // 7: lab_instrument.ListDevicesResponse.devices:type_name -> lab_instrument.DeviceInfo
+	0,   // 8: lab_instrument.DeviceFilter.status:type_name -> lab_instrument.DeviceStatus
+	71,  // 9: lab_instrument.DeviceFilter.last_seen_after:type_name -> google.protobuf.Timestamp
+	71,  // 10: lab_instrument.DeviceFilter.last_seen_before:type_name -> google.protobuf.Timestamp
+	62,  // 11: lab_instrument.DeviceFilter.metadata_filters:type_name -> lab_instrument.DeviceFilter.MetadataFiltersEntry
+	0,   // 12: lab_instrument.DeviceInfo.status:type_name -> lab_instrument.DeviceStatus
+	71,  // 13: lab_instrument.DeviceInfo.last_seen:type_name -> google.protobuf.Timestamp
+	71,  // 14: lab_instrument.DeviceInfo.registered_at:type_name -> google.protobuf.Timestamp
+	63,  // 15: lab_instrument.DeviceInfo.metadata:type_name -> lab_instrument.DeviceInfo.MetadataEntry
+	16,  // 16: lab_instrument.StreamDataRequest.init:type_name -> lab_instrument.StreamInit
+	21,  // 17: lab_instrument.StreamDataRequest.data:type_name -> lab_instrument.MeasurementData
+	45,  // 18: lab_instrument.StreamDataRequest.heartbeat:type_name -> lab_instrument.Heartbeat
+	18,  // 19: lab_instrument.StreamDataRequest.close:type_name -> lab_instrument.StreamClose
+	17,  // 20: lab_instrument.StreamDataResponse.ack:type_name -> lab_instrument.StreamAck
+	27,  // 21: lab_instrument.StreamDataResponse.command:type_name -> lab_instrument.Command
+	19,  // 22: lab_instrument.StreamDataResponse.error:type_name -> lab_instrument.StreamError
+	45,  // 23: lab_instrument.StreamDataResponse.heartbeat:type_name -> lab_instrument.Heartbeat
+	20,  // 24: lab_instrument.StreamDataResponse.reconnect:type_name -> lab_instrument.Reconnect
+	71,  // 25: lab_instrument.MeasurementData.timestamp:type_name -> google.protobuf.Timestamp
+	22,  // 26: lab_instrument.MeasurementData.data_points:type_name -> lab_instrument.DataPoint
+	1,   // 27: lab_instrument.DataPoint.quality:type_name -> lab_instrument.QualityCode
+	64,  // 28: lab_instrument.DataPoint.metadata:type_name -> lab_instrument.DataPoint.MetadataEntry
+	27,  // 29: lab_instrument.SendCommandRequest.command:type_name -> lab_instrument.Command
+	25,  // 30: lab_instrument.SendCommandRequest.signatures:type_name -> lab_instrument.CommandSignature
+	2,   // 31: lab_instrument.SendCommandResponse.status:type_name -> lab_instrument.CommandStatus
+	71,  // 32: lab_instrument.SendCommandResponse.submitted_at:type_name -> google.protobuf.Timestamp
+	28,  // 33: lab_instrument.SendCommandResponse.result:type_name -> lab_instrument.CommandResult
+	26,  // 34: lab_instrument.SendCommandResponse.signatures:type_name -> lab_instrument.CommandSignatureRecord
+	71,  // 35: lab_instrument.CommandSignatureRecord.signed_at:type_name -> google.protobuf.Timestamp
+	65,  // 36: lab_instrument.Command.parameters:type_name -> lab_instrument.Command.ParametersEntry
+	71,  // 37: lab_instrument.Command.expires_at:type_name -> google.protobuf.Timestamp
+	66,  // 38: lab_instrument.CommandResult.data:type_name -> lab_instrument.CommandResult.DataEntry
+	71,  // 39: lab_instrument.CommandResult.executed_at:type_name -> google.protobuf.Timestamp
+	71,  // 40: lab_instrument.GetMeasurementsRequest.start_time:type_name -> google.protobuf.Timestamp
+	71,  // 41: lab_instrument.GetMeasurementsRequest.end_time:type_name -> google.protobuf.Timestamp
+	5,   // 42: lab_instrument.GetMeasurementsRequest.aggregation:type_name -> lab_instrument.AggregationType
+	21,  // 43: lab_instrument.GetMeasurementsResponse.measurements:type_name -> lab_instrument.MeasurementData
+	31,  // 44: lab_instrument.GetMeasurementsResponse.statistics:type_name -> lab_instrument.MeasurementStatistics
+	71,  // 45: lab_instrument.MeasurementStatistics.earliest_timestamp:type_name -> google.protobuf.Timestamp
+	71,  // 46: lab_instrument.MeasurementStatistics.latest_timestamp:type_name -> google.protobuf.Timestamp
+	67,  // 47: lab_instrument.MeasurementStatistics.data_type_stats:type_name -> lab_instrument.MeasurementStatistics.DataTypeStatsEntry
+	4,   // 48: lab_instrument.HealthCheckResponse.status:type_name -> lab_instrument.HealthStatus
+	68,  // 49: lab_instrument.HealthCheckResponse.details:type_name -> lab_instrument.HealthCheckResponse.DetailsEntry
+	71,  // 50: lab_instrument.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
+	71,  // 51: lab_instrument.Silence.starts_at:type_name -> google.protobuf.Timestamp
+	71,  // 52: lab_instrument.Silence.ends_at:type_name -> google.protobuf.Timestamp
+	71,  // 53: lab_instrument.Silence.created_at:type_name -> google.protobuf.Timestamp
+	35,  // 54: lab_instrument.CreateSilenceRequest.silence:type_name -> lab_instrument.Silence
+	35,  // 55: lab_instrument.CreateSilenceResponse.silence:type_name -> lab_instrument.Silence
+	35,  // 56: lab_instrument.ListSilencesResponse.silences:type_name -> lab_instrument.Silence
+	0,   // 57: lab_instrument.DeviceEvent.from_status:type_name -> lab_instrument.DeviceStatus
+	0,   // 58: lab_instrument.DeviceEvent.to_status:type_name -> lab_instrument.DeviceStatus
+	69,  // 59: lab_instrument.DeviceEvent.metadata:type_name -> lab_instrument.DeviceEvent.MetadataEntry
+	71,  // 60: lab_instrument.DeviceEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	71,  // 61: lab_instrument.GetDeviceHistoryRequest.start_time:type_name -> google.protobuf.Timestamp
+	71,  // 62: lab_instrument.GetDeviceHistoryRequest.end_time:type_name -> google.protobuf.Timestamp
+	42,  // 63: lab_instrument.GetDeviceHistoryResponse.events:type_name -> lab_instrument.DeviceEvent
+	71,  // 64: lab_instrument.GetDeviceHistoryResponse.window_start:type_name -> google.protobuf.Timestamp
+	71,  // 65: lab_instrument.GetDeviceHistoryResponse.window_end:type_name -> google.protobuf.Timestamp
+	71,  // 66: lab_instrument.Heartbeat.timestamp:type_name -> google.protobuf.Timestamp
+	70,  // 67: lab_instrument.Heartbeat.metrics:type_name -> lab_instrument.Heartbeat.MetricsEntry
+	71,  // 68: lab_instrument.CreateEnrollmentTokenResponse.expires_at:type_name -> google.protobuf.Timestamp
+	3,   // 69: lab_instrument.EnrollDeviceRequest.credential_type:type_name -> lab_instrument.CredentialType
+	3,   // 70: lab_instrument.EnrollDeviceResponse.credential_type:type_name -> lab_instrument.CredentialType
+	71,  // 71: lab_instrument.EnrollDeviceResponse.expires_at:type_name -> google.protobuf.Timestamp
+	71,  // 72: lab_instrument.APIKey.expires_at:type_name -> google.protobuf.Timestamp
+	71,  // 73: lab_instrument.APIKey.revoked_at:type_name -> google.protobuf.Timestamp
+	71,  // 74: lab_instrument.APIKey.last_used_at:type_name -> google.protobuf.Timestamp
+	71,  // 75: lab_instrument.APIKey.created_at:type_name -> google.protobuf.Timestamp
+	50,  // 76: lab_instrument.CreateAPIKeyResponse.key:type_name -> lab_instrument.APIKey
+	50,  // 77: lab_instrument.ListAPIKeysResponse.keys:type_name -> lab_instrument.APIKey
+	71,  // 78: lab_instrument.AuditEntry.occurred_at:type_name -> google.protobuf.Timestamp
+	71,  // 79: lab_instrument.QueryAuditLogRequest.start_time:type_name -> google.protobuf.Timestamp
+	71,  // 80: lab_instrument.QueryAuditLogRequest.end_time:type_name -> google.protobuf.Timestamp
+	57,  // 81: lab_instrument.QueryAuditLogResponse.entries:type_name -> lab_instrument.AuditEntry
+	32,  // 82: lab_instrument.MeasurementStatistics.DataTypeStatsEntry.value:type_name -> lab_instrument.DataTypeStats
+	6,   // 83: lab_instrument.LabInstrumentGateway.RegisterDevice:input_type -> lab_instrument.RegisterDeviceRequest
+	8,   // 84: lab_instrument.LabInstrumentGateway.GetDeviceStatus:input_type -> lab_instrument.GetDeviceStatusRequest
+	10,  // 85: lab_instrument.LabInstrumentGateway.ListDevices:input_type -> lab_instrument.ListDevicesRequest
+	43,  // 86: lab_instrument.LabInstrumentGateway.GetDeviceHistory:input_type -> lab_instrument.GetDeviceHistoryRequest
+	14,  // 87: lab_instrument.LabInstrumentGateway.StreamData:input_type -> lab_instrument.StreamDataRequest
+	23,  // 88: lab_instrument.LabInstrumentGateway.SendCommand:input_type -> lab_instrument.SendCommandRequest
+	29,  // 89: lab_instrument.LabInstrumentGateway.GetMeasurements:input_type -> lab_instrument.GetMeasurementsRequest
+	33,  // 90: lab_instrument.LabInstrumentGateway.HealthCheck:input_type -> lab_instrument.HealthCheckRequest
+	36,  // 91: lab_instrument.LabInstrumentGateway.CreateSilence:input_type -> lab_instrument.CreateSilenceRequest
+	38,  // 92: lab_instrument.LabInstrumentGateway.ListSilences:input_type -> lab_instrument.ListSilencesRequest
+	40,  // 93: lab_instrument.LabInstrumentGateway.ExpireSilence:input_type -> lab_instrument.ExpireSilenceRequest
+	46,  // 94: lab_instrument.LabInstrumentGateway.CreateEnrollmentToken:input_type -> lab_instrument.CreateEnrollmentTokenRequest
+	48,  // 95: lab_instrument.LabInstrumentGateway.EnrollDevice:input_type -> lab_instrument.EnrollDeviceRequest
+	51,  // 96: lab_instrument.LabInstrumentGateway.CreateAPIKey:input_type -> lab_instrument.CreateAPIKeyRequest
+	53,  // 97: lab_instrument.LabInstrumentGateway.ListAPIKeys:input_type -> lab_instrument.ListAPIKeysRequest
+	55,  // 98: lab_instrument.LabInstrumentGateway.RevokeAPIKey:input_type -> lab_instrument.RevokeAPIKeyRequest
+	58,  // 99: lab_instrument.LabInstrumentGateway.QueryAuditLog:input_type -> lab_instrument.QueryAuditLogRequest
+	7,   // 100: lab_instrument.LabInstrumentGateway.RegisterDevice:output_type -> lab_instrument.RegisterDeviceResponse
+	9,   // 101: lab_instrument.LabInstrumentGateway.GetDeviceStatus:output_type -> lab_instrument.GetDeviceStatusResponse
+	11,  // 102: lab_instrument.LabInstrumentGateway.ListDevices:output_type -> lab_instrument.ListDevicesResponse
+	44,  // 103: lab_instrument.LabInstrumentGateway.GetDeviceHistory:output_type -> lab_instrument.GetDeviceHistoryResponse
+	15,  // 104: lab_instrument.LabInstrumentGateway.StreamData:output_type -> lab_instrument.StreamDataResponse
+	24,  // 105: lab_instrument.LabInstrumentGateway.SendCommand:output_type -> lab_instrument.SendCommandResponse
+	30,  // 106: lab_instrument.LabInstrumentGateway.GetMeasurements:output_type -> lab_instrument.GetMeasurementsResponse
+	34,  // 107: lab_instrument.LabInstrumentGateway.HealthCheck:output_type -> lab_instrument.HealthCheckResponse
+	37,  // 108: lab_instrument.LabInstrumentGateway.CreateSilence:output_type -> lab_instrument.CreateSilenceResponse
+	39,  // 109: lab_instrument.LabInstrumentGateway.ListSilences:output_type -> lab_instrument.ListSilencesResponse
+	41,  // 110: lab_instrument.LabInstrumentGateway.ExpireSilence:output_type -> lab_instrument.ExpireSilenceResponse
+	47,  // 111: lab_instrument.LabInstrumentGateway.CreateEnrollmentToken:output_type -> lab_instrument.CreateEnrollmentTokenResponse
+	49,  // 112: lab_instrument.LabInstrumentGateway.EnrollDevice:output_type -> lab_instrument.EnrollDeviceResponse
+	52,  // 113: lab_instrument.LabInstrumentGateway.CreateAPIKey:output_type -> lab_instrument.CreateAPIKeyResponse
+	54,  // 114: lab_instrument.LabInstrumentGateway.ListAPIKeys:output_type -> lab_instrument.ListAPIKeysResponse
+	56,  // 115: lab_instrument.LabInstrumentGateway.RevokeAPIKey:output_type -> lab_instrument.RevokeAPIKeyResponse
+	59,  // 116: lab_instrument.LabInstrumentGateway.QueryAuditLog:output_type -> lab_instrument.QueryAuditLogResponse
+	100, // [100:117] is the sub-list for method output_type
+	83,  // [83:100] is the sub-list for method input_type
+	83,  // [83:83] is the sub-list for extension type_name
+	83,  // [83:83] is the sub-list for extension extendee
+	0,   // [0:83] is the sub-list for field type_name
 }
 
 func init() { file_proto_lab_instrument_proto_init() }
@@ -2706,14 +5080,15 @@ func file_proto_lab_instrument_proto_init() {
 		(*StreamDataResponse_Command)(nil),
 		(*StreamDataResponse_Error)(nil),
 		(*StreamDataResponse_Heartbeat)(nil),
+		(*StreamDataResponse_Reconnect)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_lab_instrument_proto_rawDesc), len(file_proto_lab_instrument_proto_rawDesc)),
-			NumEnums:      5,
-			NumMessages:   37,
+			NumEnums:      6,
+			NumMessages:   65,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
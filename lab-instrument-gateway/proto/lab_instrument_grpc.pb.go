@@ -19,13 +19,23 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	LabInstrumentGateway_RegisterDevice_FullMethodName  = "/lab_instrument.LabInstrumentGateway/RegisterDevice"
-	LabInstrumentGateway_GetDeviceStatus_FullMethodName = "/lab_instrument.LabInstrumentGateway/GetDeviceStatus"
-	LabInstrumentGateway_ListDevices_FullMethodName     = "/lab_instrument.LabInstrumentGateway/ListDevices"
-	LabInstrumentGateway_StreamData_FullMethodName      = "/lab_instrument.LabInstrumentGateway/StreamData"
-	LabInstrumentGateway_SendCommand_FullMethodName     = "/lab_instrument.LabInstrumentGateway/SendCommand"
-	LabInstrumentGateway_GetMeasurements_FullMethodName = "/lab_instrument.LabInstrumentGateway/GetMeasurements"
-	LabInstrumentGateway_HealthCheck_FullMethodName     = "/lab_instrument.LabInstrumentGateway/HealthCheck"
+	LabInstrumentGateway_RegisterDevice_FullMethodName        = "/lab_instrument.LabInstrumentGateway/RegisterDevice"
+	LabInstrumentGateway_GetDeviceStatus_FullMethodName       = "/lab_instrument.LabInstrumentGateway/GetDeviceStatus"
+	LabInstrumentGateway_ListDevices_FullMethodName           = "/lab_instrument.LabInstrumentGateway/ListDevices"
+	LabInstrumentGateway_GetDeviceHistory_FullMethodName      = "/lab_instrument.LabInstrumentGateway/GetDeviceHistory"
+	LabInstrumentGateway_StreamData_FullMethodName            = "/lab_instrument.LabInstrumentGateway/StreamData"
+	LabInstrumentGateway_SendCommand_FullMethodName           = "/lab_instrument.LabInstrumentGateway/SendCommand"
+	LabInstrumentGateway_GetMeasurements_FullMethodName       = "/lab_instrument.LabInstrumentGateway/GetMeasurements"
+	LabInstrumentGateway_HealthCheck_FullMethodName           = "/lab_instrument.LabInstrumentGateway/HealthCheck"
+	LabInstrumentGateway_CreateSilence_FullMethodName         = "/lab_instrument.LabInstrumentGateway/CreateSilence"
+	LabInstrumentGateway_ListSilences_FullMethodName          = "/lab_instrument.LabInstrumentGateway/ListSilences"
+	LabInstrumentGateway_ExpireSilence_FullMethodName         = "/lab_instrument.LabInstrumentGateway/ExpireSilence"
+	LabInstrumentGateway_CreateEnrollmentToken_FullMethodName = "/lab_instrument.LabInstrumentGateway/CreateEnrollmentToken"
+	LabInstrumentGateway_EnrollDevice_FullMethodName          = "/lab_instrument.LabInstrumentGateway/EnrollDevice"
+	LabInstrumentGateway_CreateAPIKey_FullMethodName          = "/lab_instrument.LabInstrumentGateway/CreateAPIKey"
+	LabInstrumentGateway_ListAPIKeys_FullMethodName           = "/lab_instrument.LabInstrumentGateway/ListAPIKeys"
+	LabInstrumentGateway_RevokeAPIKey_FullMethodName          = "/lab_instrument.LabInstrumentGateway/RevokeAPIKey"
+	LabInstrumentGateway_QueryAuditLog_FullMethodName         = "/lab_instrument.LabInstrumentGateway/QueryAuditLog"
 )
 
 // LabInstrumentGatewayClient is the client API for LabInstrumentGateway service.
@@ -38,6 +48,7 @@ type LabInstrumentGatewayClient interface {
 	RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceResponse, error)
 	GetDeviceStatus(ctx context.Context, in *GetDeviceStatusRequest, opts ...grpc.CallOption) (*GetDeviceStatusResponse, error)
 	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	GetDeviceHistory(ctx context.Context, in *GetDeviceHistoryRequest, opts ...grpc.CallOption) (*GetDeviceHistoryResponse, error)
 	// Real-time streaming
 	StreamData(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamDataRequest, StreamDataResponse], error)
 	// Command execution
@@ -46,6 +57,19 @@ type LabInstrumentGatewayClient interface {
 	GetMeasurements(ctx context.Context, in *GetMeasurementsRequest, opts ...grpc.CallOption) (*GetMeasurementsResponse, error)
 	// Health and monitoring
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// Alert silences
+	CreateSilence(ctx context.Context, in *CreateSilenceRequest, opts ...grpc.CallOption) (*CreateSilenceResponse, error)
+	ListSilences(ctx context.Context, in *ListSilencesRequest, opts ...grpc.CallOption) (*ListSilencesResponse, error)
+	ExpireSilence(ctx context.Context, in *ExpireSilenceRequest, opts ...grpc.CallOption) (*ExpireSilenceResponse, error)
+	// Device provisioning
+	CreateEnrollmentToken(ctx context.Context, in *CreateEnrollmentTokenRequest, opts ...grpc.CallOption) (*CreateEnrollmentTokenResponse, error)
+	EnrollDevice(ctx context.Context, in *EnrollDeviceRequest, opts ...grpc.CallOption) (*EnrollDeviceResponse, error)
+	// API keys for service integrations
+	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error)
+	RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error)
+	// Audit trail
+	QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error)
 }
 
 type labInstrumentGatewayClient struct {
@@ -86,6 +110,16 @@ func (c *labInstrumentGatewayClient) ListDevices(ctx context.Context, in *ListDe
 	return out, nil
 }
 
+func (c *labInstrumentGatewayClient) GetDeviceHistory(ctx context.Context, in *GetDeviceHistoryRequest, opts ...grpc.CallOption) (*GetDeviceHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeviceHistoryResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_GetDeviceHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *labInstrumentGatewayClient) StreamData(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamDataRequest, StreamDataResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &LabInstrumentGateway_ServiceDesc.Streams[0], LabInstrumentGateway_StreamData_FullMethodName, cOpts...)
@@ -129,6 +163,96 @@ func (c *labInstrumentGatewayClient) HealthCheck(ctx context.Context, in *Health
 	return out, nil
 }
 
+func (c *labInstrumentGatewayClient) CreateSilence(ctx context.Context, in *CreateSilenceRequest, opts ...grpc.CallOption) (*CreateSilenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSilenceResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_CreateSilence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) ListSilences(ctx context.Context, in *ListSilencesRequest, opts ...grpc.CallOption) (*ListSilencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSilencesResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_ListSilences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) ExpireSilence(ctx context.Context, in *ExpireSilenceRequest, opts ...grpc.CallOption) (*ExpireSilenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExpireSilenceResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_ExpireSilence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) CreateEnrollmentToken(ctx context.Context, in *CreateEnrollmentTokenRequest, opts ...grpc.CallOption) (*CreateEnrollmentTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateEnrollmentTokenResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_CreateEnrollmentToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) EnrollDevice(ctx context.Context, in *EnrollDeviceRequest, opts ...grpc.CallOption) (*EnrollDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollDeviceResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_EnrollDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_CreateAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAPIKeysResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_ListAPIKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeAPIKeyResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_RevokeAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *labInstrumentGatewayClient) QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryAuditLogResponse)
+	err := c.cc.Invoke(ctx, LabInstrumentGateway_QueryAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LabInstrumentGatewayServer is the server API for LabInstrumentGateway service.
 // All implementations must embed UnimplementedLabInstrumentGatewayServer
 // for forward compatibility.
@@ -139,6 +263,7 @@ type LabInstrumentGatewayServer interface {
 	RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceResponse, error)
 	GetDeviceStatus(context.Context, *GetDeviceStatusRequest) (*GetDeviceStatusResponse, error)
 	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	GetDeviceHistory(context.Context, *GetDeviceHistoryRequest) (*GetDeviceHistoryResponse, error)
 	// Real-time streaming
 	StreamData(grpc.BidiStreamingServer[StreamDataRequest, StreamDataResponse]) error
 	// Command execution
@@ -147,6 +272,19 @@ type LabInstrumentGatewayServer interface {
 	GetMeasurements(context.Context, *GetMeasurementsRequest) (*GetMeasurementsResponse, error)
 	// Health and monitoring
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// Alert silences
+	CreateSilence(context.Context, *CreateSilenceRequest) (*CreateSilenceResponse, error)
+	ListSilences(context.Context, *ListSilencesRequest) (*ListSilencesResponse, error)
+	ExpireSilence(context.Context, *ExpireSilenceRequest) (*ExpireSilenceResponse, error)
+	// Device provisioning
+	CreateEnrollmentToken(context.Context, *CreateEnrollmentTokenRequest) (*CreateEnrollmentTokenResponse, error)
+	EnrollDevice(context.Context, *EnrollDeviceRequest) (*EnrollDeviceResponse, error)
+	// API keys for service integrations
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error)
+	RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error)
+	// Audit trail
+	QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error)
 	mustEmbedUnimplementedLabInstrumentGatewayServer()
 }
 
@@ -166,6 +304,9 @@ func (UnimplementedLabInstrumentGatewayServer) GetDeviceStatus(context.Context,
 func (UnimplementedLabInstrumentGatewayServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
 }
+func (UnimplementedLabInstrumentGatewayServer) GetDeviceHistory(context.Context, *GetDeviceHistoryRequest) (*GetDeviceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeviceHistory not implemented")
+}
 func (UnimplementedLabInstrumentGatewayServer) StreamData(grpc.BidiStreamingServer[StreamDataRequest, StreamDataResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamData not implemented")
 }
@@ -178,6 +319,33 @@ func (UnimplementedLabInstrumentGatewayServer) GetMeasurements(context.Context,
 func (UnimplementedLabInstrumentGatewayServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedLabInstrumentGatewayServer) CreateSilence(context.Context, *CreateSilenceRequest) (*CreateSilenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSilence not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) ListSilences(context.Context, *ListSilencesRequest) (*ListSilencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSilences not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) ExpireSilence(context.Context, *ExpireSilenceRequest) (*ExpireSilenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExpireSilence not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) CreateEnrollmentToken(context.Context, *CreateEnrollmentTokenRequest) (*CreateEnrollmentTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateEnrollmentToken not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) EnrollDevice(context.Context, *EnrollDeviceRequest) (*EnrollDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollDevice not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAPIKey not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAPIKeys not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAPIKey not implemented")
+}
+func (UnimplementedLabInstrumentGatewayServer) QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryAuditLog not implemented")
+}
 func (UnimplementedLabInstrumentGatewayServer) mustEmbedUnimplementedLabInstrumentGatewayServer() {}
 func (UnimplementedLabInstrumentGatewayServer) testEmbeddedByValue()                              {}
 
@@ -253,6 +421,24 @@ func _LabInstrumentGateway_ListDevices_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LabInstrumentGateway_GetDeviceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).GetDeviceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_GetDeviceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).GetDeviceHistory(ctx, req.(*GetDeviceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _LabInstrumentGateway_StreamData_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(LabInstrumentGatewayServer).StreamData(&grpc.GenericServerStream[StreamDataRequest, StreamDataResponse]{ServerStream: stream})
 }
@@ -314,6 +500,168 @@ func _LabInstrumentGateway_HealthCheck_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LabInstrumentGateway_CreateSilence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSilenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).CreateSilence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_CreateSilence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).CreateSilence(ctx, req.(*CreateSilenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_ListSilences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSilencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).ListSilences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_ListSilences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).ListSilences(ctx, req.(*ListSilencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_ExpireSilence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpireSilenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).ExpireSilence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_ExpireSilence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).ExpireSilence(ctx, req.(*ExpireSilenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_CreateEnrollmentToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateEnrollmentTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).CreateEnrollmentToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_CreateEnrollmentToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).CreateEnrollmentToken(ctx, req.(*CreateEnrollmentTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_EnrollDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).EnrollDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_EnrollDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).EnrollDevice(ctx, req.(*EnrollDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_CreateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).CreateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_CreateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).CreateAPIKey(ctx, req.(*CreateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_ListAPIKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAPIKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).ListAPIKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_ListAPIKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).ListAPIKeys(ctx, req.(*ListAPIKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_RevokeAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).RevokeAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_RevokeAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).RevokeAPIKey(ctx, req.(*RevokeAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LabInstrumentGateway_QueryAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LabInstrumentGatewayServer).QueryAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LabInstrumentGateway_QueryAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LabInstrumentGatewayServer).QueryAuditLog(ctx, req.(*QueryAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LabInstrumentGateway_ServiceDesc is the grpc.ServiceDesc for LabInstrumentGateway service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -333,6 +681,10 @@ var LabInstrumentGateway_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListDevices",
 			Handler:    _LabInstrumentGateway_ListDevices_Handler,
 		},
+		{
+			MethodName: "GetDeviceHistory",
+			Handler:    _LabInstrumentGateway_GetDeviceHistory_Handler,
+		},
 		{
 			MethodName: "SendCommand",
 			Handler:    _LabInstrumentGateway_SendCommand_Handler,
@@ -345,6 +697,42 @@ var LabInstrumentGateway_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _LabInstrumentGateway_HealthCheck_Handler,
 		},
+		{
+			MethodName: "CreateSilence",
+			Handler:    _LabInstrumentGateway_CreateSilence_Handler,
+		},
+		{
+			MethodName: "ListSilences",
+			Handler:    _LabInstrumentGateway_ListSilences_Handler,
+		},
+		{
+			MethodName: "ExpireSilence",
+			Handler:    _LabInstrumentGateway_ExpireSilence_Handler,
+		},
+		{
+			MethodName: "CreateEnrollmentToken",
+			Handler:    _LabInstrumentGateway_CreateEnrollmentToken_Handler,
+		},
+		{
+			MethodName: "EnrollDevice",
+			Handler:    _LabInstrumentGateway_EnrollDevice_Handler,
+		},
+		{
+			MethodName: "CreateAPIKey",
+			Handler:    _LabInstrumentGateway_CreateAPIKey_Handler,
+		},
+		{
+			MethodName: "ListAPIKeys",
+			Handler:    _LabInstrumentGateway_ListAPIKeys_Handler,
+		},
+		{
+			MethodName: "RevokeAPIKey",
+			Handler:    _LabInstrumentGateway_RevokeAPIKey_Handler,
+		},
+		{
+			MethodName: "QueryAuditLog",
+			Handler:    _LabInstrumentGateway_QueryAuditLog_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{